@@ -31,7 +31,10 @@ func main() {
 
 	// Start the stdio server
 	logger.Info("Starting MCP server...")
-	if err := server.ServeStdio(debugServer.Server()); err != nil {
+	err = server.ServeStdio(debugServer.Server())
+	// Don't leave launched programs behind when the client goes away
+	debugServer.CloseSessions()
+	if err != nil {
 		logger.Error("Server error", "error", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,135 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// AddressToSymbol resolves an address to the function it is code of, with the source line
+// from the line table, or else to the package variable it lies in. Delve knows no size for
+// package variables, so a data address is put in the nearest one below it, as long as
+// another one follows; any other address, such as one in the heap or on a stack, is
+// reported as unknown.
+func (c *Client) AddressToSymbol(addr uint64) types.AddressSymbolResponse {
+	if c.client == nil {
+		return c.createAddressSymbolResponse(nil, addr, types.AddressSymbolResponse{}, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createAddressSymbolResponse(nil, addr, types.AddressSymbolResponse{}, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createAddressSymbolResponse(nil, addr, types.AddressSymbolResponse{}, fmt.Errorf("cannot resolve addresses while the target is running; stop the target first"))
+	}
+
+	logger.Debug("Resolving address %#x to a symbol", addr)
+	var symbol types.AddressSymbolResponse
+	locs, _, err := c.client.FindLocation(api.EvalScope{GoroutineID: -1}, fmt.Sprintf("*%#x", addr), false, nil)
+	if err != nil {
+		logger.Debug("Warning: Failed to look up %#x in the line table: %v", addr, err)
+	}
+	if err == nil && len(locs) > 0 && locs[0].Function != nil {
+		fn := locs[0].Function
+		symbol = types.AddressSymbolResponse{
+			Known:         true,
+			Kind:          "function",
+			Symbol:        fn.Name(),
+			SymbolAddress: fmt.Sprintf("%#x", fn.Value),
+			Offset:        addr - fn.Value,
+		}
+		if locs[0].File != "" {
+			symbol.Position = &types.SourcePosition{File: locs[0].File, Line: locs[0].Line, Function: fn.Name()}
+			symbol.Location = formatPosition(symbol.Position)
+		}
+		return c.createAddressSymbolResponse(state, addr, symbol, nil)
+	}
+
+	vars, err := c.client.ListPackageVariables("", api.LoadConfig{})
+	if err != nil {
+		return c.createAddressSymbolResponse(state, addr, symbol, fmt.Errorf("failed to list package variables: %v", err))
+	}
+	if v := packageVariableAt(vars, addr); v != nil {
+		symbol = types.AddressSymbolResponse{
+			Known:         true,
+			Kind:          "variable",
+			Symbol:        v.Name,
+			SymbolAddress: fmt.Sprintf("%#x", v.Addr),
+			Offset:        addr - v.Addr,
+			Type:          v.Type,
+		}
+	}
+	return c.createAddressSymbolResponse(state, addr, symbol, nil)
+}
+
+// packageVariableAt returns the package variable nearest below addr, provided another one
+// lies above it, which bounds how far the variable can extend
+func packageVariableAt(vars []api.Variable, addr uint64) *api.Variable {
+	sorted := make([]*api.Variable, 0, len(vars))
+	for i := range vars {
+		if vars[i].Addr != 0 {
+			sorted = append(sorted, &vars[i])
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Addr < sorted[j].Addr })
+
+	// The first variable above addr; the one before it is the candidate
+	i := sort.Search(len(sorted), func(i int) bool { return sorted[i].Addr > addr })
+	if i == 0 || i == len(sorted) {
+		return nil
+	}
+	return sorted[i-1]
+}
+
+// SymbolAddress evaluates an expression to an address to resolve to a symbol: the code of a
+// function value, or else the address EvalAddress finds
+func (c *Client) SymbolAddress(expr string, frame int) (uint64, error) {
+	if c.client != nil {
+		if state, err := c.client.GetStateNonBlocking(); err == nil && !state.Running && state.SelectedGoroutine != nil {
+			scope := api.EvalScope{GoroutineID: state.SelectedGoroutine.ID, Frame: frame}
+			if v, err := c.client.EvalVariable(scope, expr, api.LoadConfig{}); err == nil && v.Kind == reflect.Func {
+				if v.Base == 0 {
+					return 0, fmt.Errorf("%q is a nil function", expr)
+				}
+				return v.Base, nil
+			}
+		}
+	}
+	return c.EvalAddress(expr, frame)
+}
+
+// createAddressSymbolResponse creates an AddressSymbolResponse from the symbol found, if any
+func (c *Client) createAddressSymbolResponse(state *api.DebuggerState, addr uint64, symbol types.AddressSymbolResponse, err error) types.AddressSymbolResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "address_to_symbol"
+
+	symbol.Context = context
+	symbol.Address = fmt.Sprintf("%#x", addr)
+	if err != nil {
+		symbol.Status = "error"
+		symbol.Context.ErrorMessage = err.Error()
+		return symbol
+	}
+
+	symbol.Status = "success"
+	switch symbol.Kind {
+	case "function":
+		symbol.Description = fmt.Sprintf("%s+%#x", symbol.Symbol, symbol.Offset)
+		if symbol.Position != nil {
+			symbol.Description += fmt.Sprintf(" at %s:%d", symbol.Position.File, symbol.Position.Line)
+		}
+	case "variable":
+		symbol.Description = fmt.Sprintf("package variable %s", symbol.Symbol)
+		if symbol.Offset > 0 {
+			symbol.Description = fmt.Sprintf("package variable %s+%#x, unless the address is past its end; its size is not known", symbol.Symbol, symbol.Offset)
+		}
+	default:
+		symbol.Description = "unknown address"
+	}
+	return symbol
+}
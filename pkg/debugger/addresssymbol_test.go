@@ -0,0 +1,149 @@
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestPackageVariableAt(t *testing.T) {
+	vars := []api.Variable{
+		{Name: "main.b", Addr: 0x5a7100},
+		{Name: "main.a", Addr: 0x5a70d0},
+		{Name: "main.constLike"},
+		{Name: "main.c", Addr: 0x5a7200},
+	}
+
+	testCases := []struct {
+		name     string
+		addr     uint64
+		expected string
+	}{
+		{name: "Start of a variable", addr: 0x5a70d0, expected: "main.a"},
+		{name: "Inside a variable", addr: 0x5a70d8, expected: "main.a"},
+		{name: "Unsorted", addr: 0x5a7108, expected: "main.b"},
+		{name: "Below every variable", addr: 0x10},
+		{name: "Past the last variable", addr: 0xc000010000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := packageVariableAt(vars, tc.addr)
+			var name string
+			if v != nil {
+				name = v.Name
+			}
+			if name != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, name)
+			}
+		})
+	}
+}
+
+func TestCreateAddressSymbolResponse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		symbol   types.AddressSymbolResponse
+		expected string
+	}{
+		{
+			name:     "Code",
+			symbol:   types.AddressSymbolResponse{Known: true, Kind: "function", Symbol: "main.run", Offset: 0x1c, Position: &types.SourcePosition{File: "/src/main.go", Line: 12}},
+			expected: "main.run+0x1c at /src/main.go:12",
+		},
+		{
+			name:     "Code without a line",
+			symbol:   types.AddressSymbolResponse{Known: true, Kind: "function", Symbol: "runtime.asmcgocall", Offset: 4},
+			expected: "runtime.asmcgocall+0x4",
+		},
+		{
+			name:     "Start of a variable",
+			symbol:   types.AddressSymbolResponse{Known: true, Kind: "variable", Symbol: "main.config"},
+			expected: "package variable main.config",
+		},
+		{
+			name:     "Inside a variable",
+			symbol:   types.AddressSymbolResponse{Known: true, Kind: "variable", Symbol: "main.config", Offset: 8},
+			expected: "package variable main.config+0x8, unless the address is past its end; its size is not known",
+		},
+		{name: "Unknown", expected: "unknown address"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := NewClient().createAddressSymbolResponse(nil, 0x4a1b2c, tc.symbol, nil)
+			if response.Description != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, response.Description)
+			}
+			if response.Address != "0x4a1b2c" {
+				t.Errorf("Expected address 0x4a1b2c, got %q", response.Address)
+			}
+		})
+	}
+}
+
+func TestAddressToSymbol(t *testing.T) {
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+		"FindLocation": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.FindLocationIn
+			decodeFakeArgs(t, raw, &args)
+			if args.Loc != "*0x4a1b2c" {
+				return nil, fmt.Errorf("could not find location %s", args.Loc)
+			}
+			return rpc2.FindLocationOut{Locations: []api.Location{{PC: 0x4a1b2c, File: "/src/main.go", Line: 12, Function: &api.Function{Name_: "main.run", Value: 0x4a1b10}}}}, nil
+		},
+		"ListPackageVars": fakeResult(rpc2.ListPackageVarsOut{Variables: []api.Variable{
+			{Name: "main.names", Type: "[]string", Addr: 0x5a0010},
+			{Name: "main.count", Type: "int", Addr: 0x5a0000},
+		}}),
+		"Eval": fakeEval(t, map[string]*api.Variable{
+			"main.run": {Name: "main.run", Type: "func()", Kind: reflect.Func, Base: 0x4a1b10},
+			"handler":  {Name: "handler", Type: "func()", Kind: reflect.Func},
+		}),
+	})
+
+	testCases := []struct {
+		name        string
+		addr        uint64
+		symbol      string
+		description string
+	}{
+		{name: "Code", addr: 0x4a1b2c, symbol: "main.run", description: "main.run+0x1c at /src/main.go:12"},
+		{name: "Start of a variable", addr: 0x5a0000, symbol: "main.count", description: "package variable main.count"},
+		{name: "Inside a variable", addr: 0x5a0008, symbol: "main.count", description: "package variable main.count+0x8, unless the address is past its end; its size is not known"},
+		{name: "Past the last variable", addr: 0x5a0018, description: "unknown address"},
+		{name: "Heap", addr: 0xc000012000, description: "unknown address"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.AddressToSymbol(tc.addr)
+			if response.Status != "success" {
+				t.Fatalf("Expected the address resolved, got %s", response.Context.ErrorMessage)
+			}
+			if response.Known != (tc.symbol != "") || response.Symbol != tc.symbol || response.Description != tc.description {
+				t.Errorf("Expected %q: %q, got %q: %q", tc.symbol, tc.description, response.Symbol, response.Description)
+			}
+		})
+	}
+
+	// A function value resolves to its code rather than to where the value is held
+	if addr, err := c.SymbolAddress("main.run", 0); err != nil || addr != 0x4a1b10 {
+		t.Errorf("Expected the code of main.run at 0x4a1b10, got %#x: %v", addr, err)
+	}
+	if _, err := c.SymbolAddress("handler", 0); err == nil || !strings.Contains(err.Error(), "nil function") {
+		t.Errorf("Expected a nil function error, got %v", err)
+	}
+
+	response := NewClient().AddressToSymbol(0x4a1b2c)
+	if response.Status != "error" || response.Context.Operation != "address_to_symbol" || !strings.Contains(response.Context.ErrorMessage, "no active debug session") {
+		t.Errorf("Expected a no active debug session error, got %+v", response.Context)
+	}
+}
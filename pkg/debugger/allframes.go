@@ -0,0 +1,132 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// defaultFrameLocalsDepth is how many frames AllFramesLocals reads when no depth is given
+const defaultFrameLocalsDepth = 10
+
+// maxFrameLocalsDepth caps the frames AllFramesLocals reads, as each costs two listings
+const maxFrameLocalsDepth = 50
+
+// AllFramesLocals returns the arguments and local variables of each of the innermost depth
+// frames of the selected goroutine, loaded as ListLocals and ListArgs load them, so a whole
+// call chain can be looked at in one go. Frames of the runtime and standard library are
+// left out unless includeRuntime is set, keeping their real index. String and Error methods
+// are never called, as that only works in the innermost frame.
+func (c *Client) AllFramesLocals(depth int, includeRuntime bool, opts VariableListOptions) types.AllFramesLocalsResponse {
+	if c.client == nil {
+		return c.createAllFramesLocalsResponse(nil, 0, nil, 0, false, 0, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createAllFramesLocalsResponse(nil, 0, nil, 0, false, 0, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createAllFramesLocalsResponse(nil, 0, nil, 0, false, 0, fmt.Errorf("cannot list variables while the target is running; stop the target first"))
+	}
+	if state.SelectedGoroutine == nil {
+		return c.createAllFramesLocalsResponse(state, 0, nil, 0, false, 0, fmt.Errorf("no goroutine selected"))
+	}
+	goroutineID := state.SelectedGoroutine.ID
+
+	if depth <= 0 {
+		depth = defaultFrameLocalsDepth
+	}
+	if depth > maxFrameLocalsDepth {
+		depth = maxFrameLocalsDepth
+	}
+
+	// Delve returns the frames down to depth, one more than asked for, which tells whether
+	// the stack goes deeper
+	stack, err := c.client.Stacktrace(goroutineID, depth, 0, nil)
+	if err != nil {
+		return c.createAllFramesLocalsResponse(state, goroutineID, nil, 0, false, 0, fmt.Errorf("failed to get stack trace for goroutine %d: %v", goroutineID, err))
+	}
+	moreFrames := len(stack) > depth
+	if moreFrames {
+		stack = stack[:depth]
+	}
+
+	cfg, loadDepth := variableLoadConfig(opts)
+	cfg.FollowPointers = c.followPointers(opts.FollowPointers)
+	logger.Debug("Listing the variables of %d frames of goroutine %d with depth %d", len(stack), goroutineID, loadDepth)
+
+	var frames []types.FrameVariables
+	var skipped int
+	for i, f := range stack {
+		frame := types.FrameVariables{StackFrame: convertStackFrame(i, f, false)}
+		if frame.IsRuntime && !includeRuntime {
+			skipped++
+			continue
+		}
+
+		scope := api.EvalScope{GoroutineID: goroutineID, Frame: i}
+		args, err := c.client.ListFunctionArgs(scope, cfg)
+		if err != nil {
+			frame.Error = joinFrameErrors(frame.Error, fmt.Sprintf("failed to list function arguments: %v", err))
+		}
+		locals, err := c.client.ListLocalVariables(scope, cfg)
+		if err != nil {
+			frame.Error = joinFrameErrors(frame.Error, fmt.Sprintf("failed to list local variables: %v", err))
+		}
+
+		frame.Arguments = convertScopeVariables(args, "argument", loadDepth, opts)
+		frame.Locals = convertScopeVariables(locals, "local", loadDepth, opts)
+		frames = append(frames, frame)
+	}
+
+	return c.createAllFramesLocalsResponse(state, goroutineID, frames, skipped, moreFrames, loadDepth, nil)
+}
+
+// joinFrameErrors adds another reason a frame could not be fully read to the ones it has
+func joinFrameErrors(errs, err string) string {
+	if errs == "" {
+		return err
+	}
+	return errs + "; " + err
+}
+
+// createAllFramesLocalsResponse creates an AllFramesLocalsResponse
+func (c *Client) createAllFramesLocalsResponse(state *api.DebuggerState, goroutineID int64, frames []types.FrameVariables, skipped int, moreFrames bool, loadDepth int, err error) types.AllFramesLocalsResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "all_frames_locals"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.AllFramesLocalsResponse{
+			Status:      "error",
+			Context:     context,
+			GoroutineID: goroutineID,
+		}
+	}
+
+	response := types.AllFramesLocalsResponse{
+		Status:         "success",
+		Context:        context,
+		GoroutineID:    goroutineID,
+		Frames:         frames,
+		SkippedRuntime: skipped,
+		MoreFrames:     moreFrames,
+		MaxDepth:       loadDepth,
+	}
+
+	var variables int
+	for _, frame := range frames {
+		variables += len(frame.Arguments) + len(frame.Locals)
+	}
+	response.Summary = fmt.Sprintf("%d variables in %d frames of goroutine %d", variables, len(frames), goroutineID)
+	if skipped > 0 {
+		response.Summary += fmt.Sprintf(", %d runtime frames left out", skipped)
+	}
+	if moreFrames {
+		response.Summary += "; the stack goes deeper than the frames read"
+	}
+	return response
+}
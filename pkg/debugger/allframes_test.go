@@ -0,0 +1,197 @@
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestConvertScopeVariables(t *testing.T) {
+	vars := []api.Variable{
+		{Name: "err", Type: "error", Kind: reflect.Interface, Flags: api.VariableShadowed},
+		{Name: "_", Type: "int", Kind: reflect.Int, Value: "3"},
+		{Name: "err", Type: "error", Kind: reflect.Interface},
+	}
+
+	testCases := []struct {
+		name     string
+		opts     VariableListOptions
+		expected int
+	}{
+		{name: "Everything", expected: 3},
+		{name: "Without shadowed", opts: VariableListOptions{HideShadowed: true}, expected: 2},
+		{name: "Without blank", opts: VariableListOptions{HideBlank: true}, expected: 2},
+		{name: "Without either", opts: VariableListOptions{HideShadowed: true, HideBlank: true}, expected: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := convertScopeVariables(vars, "local", 1, tc.opts)
+			if len(result) != tc.expected {
+				t.Errorf("Expected %d variables, got %d", tc.expected, len(result))
+			}
+			for _, v := range result {
+				if v.Scope != "local" {
+					t.Errorf("Expected scope local for %s, got %q", v.Name, v.Scope)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateAllFramesLocalsResponse(t *testing.T) {
+	frames := []types.FrameVariables{
+		{StackFrame: types.StackFrame{Index: 0, Function: "main.parse", Arguments: []types.Variable{{Name: "s"}}}, Locals: []types.Variable{{Name: "n"}}},
+		{StackFrame: types.StackFrame{Index: 2, Function: "main.main"}, Locals: []types.Variable{{Name: "k"}, {Name: "v"}}},
+	}
+
+	testCases := []struct {
+		name       string
+		skipped    int
+		moreFrames bool
+		expected   string
+	}{
+		{name: "Whole stack", expected: "4 variables in 2 frames of goroutine 1"},
+		{name: "Runtime frames left out", skipped: 1, expected: "4 variables in 2 frames of goroutine 1, 1 runtime frames left out"},
+		{name: "Stack goes deeper", moreFrames: true, expected: "4 variables in 2 frames of goroutine 1; the stack goes deeper than the frames read"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := NewClient().createAllFramesLocalsResponse(nil, 1, frames, tc.skipped, tc.moreFrames, 1, nil)
+			if response.Summary != tc.expected {
+				t.Errorf("Expected summary %q, got %q", tc.expected, response.Summary)
+			}
+			if response.SkippedRuntime != tc.skipped || response.MoreFrames != tc.moreFrames {
+				t.Errorf("Expected %d skipped and more frames %v, got %d and %v", tc.skipped, tc.moreFrames, response.SkippedRuntime, response.MoreFrames)
+			}
+		})
+	}
+}
+
+func TestJoinFrameErrors(t *testing.T) {
+	errs := joinFrameErrors("", "failed to list function arguments: x")
+	errs = joinFrameErrors(errs, "failed to list local variables: y")
+	expected := "failed to list function arguments: x; failed to list local variables: y"
+	if errs != expected {
+		t.Errorf("Expected %q, got %q", expected, errs)
+	}
+}
+
+// allFramesTarget returns a fake Delve whose selected goroutine is stopped in main.parse,
+// called by main.run through a runtime frame, with one local per frame named after it.
+// The frames the variables are listed in are recorded.
+func allFramesTarget(t *testing.T) (*Client, *fakeDelve, *[]int) {
+	t.Helper()
+	frames := []api.Stackframe{
+		{Location: api.Location{File: "/work/app/main.go", Line: 10, Function: &api.Function{Name_: "main.parse"}}},
+		{Location: api.Location{File: "/usr/local/go/src/runtime/panic.go", Line: 770, Function: &api.Function{Name_: "runtime.gopanic"}}},
+		{Location: api.Location{File: "/work/app/main.go", Line: 20, Function: &api.Function{Name_: "main.run"}}},
+		{Location: api.Location{File: "/work/app/main.go", Line: 30, Function: &api.Function{Name_: "main.main"}}},
+	}
+
+	var listed []int
+	c, f := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+		"Stacktrace": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.StacktraceIn
+			decodeFakeArgs(t, raw, &args)
+			n := args.Depth + 1
+			if n > len(frames) {
+				n = len(frames)
+			}
+			return rpc2.StacktraceOut{Locations: frames[:n]}, nil
+		},
+		"ListFunctionArgs": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.ListFunctionArgsIn
+			decodeFakeArgs(t, raw, &args)
+			listed = append(listed, args.Scope.Frame)
+			return rpc2.ListFunctionArgsOut{}, nil
+		},
+		"ListLocalVars": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.ListLocalVarsIn
+			decodeFakeArgs(t, raw, &args)
+			name := frames[args.Scope.Frame].Function.Name()
+			return rpc2.ListLocalVarsOut{Variables: []api.Variable{{Name: strings.TrimPrefix(name, "main."), Type: "int", Kind: reflect.Int, Value: "1"}}}, nil
+		},
+	})
+	return c, f, &listed
+}
+
+func TestAllFramesLocals(t *testing.T) {
+	testCases := []struct {
+		name           string
+		depth          int
+		includeRuntime bool
+		frames         []int // Indexes of the frames listed
+		skipped        int
+		moreFrames     bool
+	}{
+		{name: "Whole stack", frames: []int{0, 2, 3}, skipped: 1},
+		{name: "With runtime frames", includeRuntime: true, frames: []int{0, 1, 2, 3}},
+		{name: "Stack deeper than the depth", depth: 3, frames: []int{0, 2}, skipped: 1, moreFrames: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _, listed := allFramesTarget(t)
+			response := c.AllFramesLocals(tc.depth, tc.includeRuntime, VariableListOptions{})
+			if response.Status != "success" {
+				t.Fatalf("Expected the variables of every frame, got %s", response.Context.ErrorMessage)
+			}
+
+			// Frames keep their index in the stack, and the ones left out aren't read at all
+			var indexes []int
+			for _, frame := range response.Frames {
+				indexes = append(indexes, frame.Index)
+				if len(frame.Locals) != 1 || "main."+frame.Locals[0].Name != frame.Function && !frame.IsRuntime {
+					t.Errorf("Expected frame %d to have the local of %s, got %+v", frame.Index, frame.Function, frame.Locals)
+				}
+			}
+			// The context reads the arguments of the current frame again after the frames
+			if !reflect.DeepEqual(indexes, tc.frames) || !reflect.DeepEqual((*listed)[:len(*listed)-1], tc.frames) {
+				t.Errorf("Expected frames %v listed, got %v, read %v", tc.frames, indexes, *listed)
+			}
+			if response.SkippedRuntime != tc.skipped || response.MoreFrames != tc.moreFrames {
+				t.Errorf("Expected %d skipped and more frames %v, got %d and %v", tc.skipped, tc.moreFrames, response.SkippedRuntime, response.MoreFrames)
+			}
+		})
+	}
+}
+
+func TestAllFramesLocalsFrameErrors(t *testing.T) {
+	c, f, _ := allFramesTarget(t)
+	f.handle("ListFunctionArgs", func(raw json.RawMessage) (interface{}, error) {
+		var args rpc2.ListFunctionArgsIn
+		decodeFakeArgs(t, raw, &args)
+		if args.Scope.Frame == 2 {
+			return nil, fmt.Errorf("could not find function arguments")
+		}
+		return rpc2.ListFunctionArgsOut{}, nil
+	})
+
+	// A frame that can't be read is reported in place, with what could be read of it
+	response := c.AllFramesLocals(0, false, VariableListOptions{})
+	if response.Status != "success" || len(response.Frames) != 3 {
+		t.Fatalf("Expected a frame that can't be read not to fail the others, got %s: %+v", response.Context.ErrorMessage, response.Frames)
+	}
+	for _, frame := range response.Frames {
+		if failed := frame.Index == 2; failed != strings.Contains(frame.Error, "could not find function arguments") {
+			t.Errorf("Expected only frame 2 to fail, got frame %d error %q", frame.Index, frame.Error)
+		}
+		if len(frame.Locals) != 1 {
+			t.Errorf("Expected the locals of frame %d, got %+v", frame.Index, frame.Locals)
+		}
+	}
+
+	response = NewClient().AllFramesLocals(0, false, VariableListOptions{})
+	if response.Status != "error" || response.Context.Operation != "all_frames_locals" || !strings.Contains(response.Context.ErrorMessage, "no active debug session") {
+		t.Errorf("Expected a no active debug session error, got %+v", response)
+	}
+}
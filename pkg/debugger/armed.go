@@ -0,0 +1,190 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// armedBreakpoint keeps a breakpoint disabled until its trigger breakpoint stops the program.
+// Delve has no breakpoints that depend on others, so the hits of the trigger enable it on
+// this side, while the program is stopped there.
+type armedBreakpoint struct {
+	trigger int  // Breakpoint whose hit enables it
+	oneShot bool // Disabled again after its first hit, until the trigger is hit again
+	armed   bool // Enabled by a hit of the trigger, waiting for its own
+}
+
+// ArmBreakpointAfter sets a breakpoint at location that stays disabled until breakpoint
+// triggerID stops the program, which then enables it, so it only stops on hits that come
+// after one of the trigger. With oneShot, the breakpoint disables itself again after its
+// first hit, and is armed again by the next hit of the trigger. Like goroutine pins, the
+// trigger only arms it on a hit that stops: hits a label filter or ignore count continues
+// past don't, and neither do the ones of step commands. The trigger's hit reports the
+// breakpoints it armed.
+func (c *Client) ArmBreakpointAfter(triggerID int, location string, oneShot bool) types.BreakpointResponse {
+	const operation = "arm_breakpoint_after"
+
+	if c.client == nil {
+		return c.createBreakpointResponse(nil, operation, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createBreakpointResponse(nil, operation, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createBreakpointResponse(nil, operation, nil, fmt.Errorf("cannot set a breakpoint while the target is running; stop the target first"))
+	}
+
+	if triggerID <= 0 {
+		return c.createBreakpointResponse(state, operation, nil, fmt.Errorf("breakpoint %d is internal to Delve and cannot arm another", triggerID))
+	}
+	trigger, err := c.client.GetBreakpoint(triggerID)
+	if err != nil {
+		return c.createBreakpointResponse(state, operation, nil, fmt.Errorf("breakpoint %d not found: %v; existing breakpoints: %s", triggerID, err, c.breakpointIDs()))
+	}
+	if trigger.Tracepoint {
+		return c.createBreakpointResponse(state, operation, nil, fmt.Errorf("breakpoint %d is a tracepoint, which doesn't stop the program, so it cannot arm another", triggerID))
+	}
+
+	response := c.SetBreakpointAtLocation(location, BreakpointOptions{})
+	response.Context.Operation = operation
+	if response.Status != "success" {
+		return response
+	}
+
+	bp := response.Breakpoint.DelveBreakpoint
+	logger.Debug("Breakpoint %d at %s:%d is armed by hits of breakpoint %d", bp.ID, bp.File, bp.Line, triggerID)
+	bp.Disabled = true
+	if err := c.client.AmendBreakpoint(bp); err != nil {
+		if _, clearErr := c.client.ClearBreakpoint(bp.ID); clearErr != nil {
+			logger.Debug("Warning: Failed to clear breakpoint %d: %v", bp.ID, clearErr)
+		}
+		return c.createBreakpointResponse(state, operation, nil, fmt.Errorf("failed to disable breakpoint %d until breakpoint %d is hit: %v", bp.ID, triggerID, err))
+	}
+	c.setArmedBreakpoint(bp.ID, triggerID, oneShot)
+
+	response.Breakpoint = convertBreakpoint(bp)
+	c.annotateBreakpoint(&response.Breakpoint)
+	return response
+}
+
+// setArmedBreakpoint makes a disabled breakpoint wait for hits of trigger
+func (c *Client) setArmedBreakpoint(id int, trigger int, oneShot bool) {
+	if c.armedBreakpoints == nil {
+		c.armedBreakpoints = make(map[int]*armedBreakpoint)
+	}
+	c.armedBreakpoints[id] = &armedBreakpoint{trigger: trigger, oneShot: oneShot}
+}
+
+// triggerArmedBreakpoints enables the breakpoints armed by a breakpoint the program stopped
+// at, and disables one-shot armed breakpoints it stopped at. Like pinHitGoroutine, it is
+// called once hits that are continued past have been skipped.
+func (c *Client) triggerArmedBreakpoints(state *api.DebuggerState) {
+	if len(c.armedBreakpoints) == 0 || state == nil || state.Exited {
+		return
+	}
+
+	hit := make(map[int]bool)
+	for _, th := range state.Threads {
+		if th.Breakpoint != nil {
+			hit[th.Breakpoint.ID] = true
+		}
+	}
+
+	for _, id := range c.armedBreakpointIDs() {
+		armed := c.armedBreakpoints[id]
+		switch {
+		case hit[id] && armed.oneShot && armed.armed:
+			c.setArmed(id, armed, false)
+		case hit[armed.trigger] && !armed.armed:
+			c.setArmed(id, armed, true)
+		}
+	}
+}
+
+// setArmed enables or disables an armed breakpoint
+func (c *Client) setArmed(id int, armed *armedBreakpoint, enabled bool) {
+	bp, err := c.client.GetBreakpoint(id)
+	if err != nil {
+		logger.Debug("Warning: Failed to read armed breakpoint %d: %v", id, err)
+		return
+	}
+	bp.Disabled = !enabled
+	if err := c.client.AmendBreakpoint(bp); err != nil {
+		logger.Debug("Warning: Failed to set armed breakpoint %d enabled to %v: %v", id, enabled, err)
+		return
+	}
+	armed.armed = enabled
+	if enabled {
+		logger.Debug("Breakpoint %d armed by a hit of breakpoint %d", id, armed.trigger)
+	} else {
+		logger.Debug("One-shot breakpoint %d disarmed after its hit", id)
+	}
+}
+
+// armedBreakpointIDs returns the IDs of the armed breakpoints in order
+func (c *Client) armedBreakpointIDs() []int {
+	ids := make([]int, 0, len(c.armedBreakpoints))
+	for id := range c.armedBreakpoints {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// forgetArmedBreakpoint drops the arming of a cleared breakpoint, and of the breakpoints it
+// was the trigger of, which stay as they are, disabled until enabled by hand if not armed
+func (c *Client) forgetArmedBreakpoint(id int) {
+	delete(c.armedBreakpoints, id)
+	for other, armed := range c.armedBreakpoints {
+		if armed.trigger == id {
+			logger.Debug("Breakpoint %d is no longer armed, as its trigger %d was cleared", other, id)
+			delete(c.armedBreakpoints, other)
+		}
+	}
+}
+
+// moveArmedBreakpoint moves the arming of a breakpoint re-created with a new ID, as a
+// trigger or as a breakpoint armed by one
+func (c *Client) moveArmedBreakpoint(id int, newID int) {
+	if armed := c.armedBreakpoints[id]; armed != nil {
+		delete(c.armedBreakpoints, id)
+		c.armedBreakpoints[newID] = armed
+	}
+	for _, armed := range c.armedBreakpoints {
+		if armed.trigger == id {
+			armed.trigger = newID
+		}
+	}
+}
+
+// annotateArmedBreakpoint adds the trigger that arms a breakpoint, if any, and whether it is armed
+func (c *Client) annotateArmedBreakpoint(breakpoint *types.Breakpoint) {
+	if armed := c.armedBreakpoints[breakpoint.ID]; armed != nil {
+		breakpoint.ArmedAfter = armed.trigger
+		breakpoint.OneShot = armed.oneShot
+		breakpoint.Armed = armed.armed
+	}
+}
+
+// annotateArmingStop adds, to a stop at a breakpoint, the breakpoints it armed as a trigger,
+// or the trigger that armed it and whether it disarmed itself
+func (c *Client) annotateArmingStop(detail *types.StopDetail) {
+	if len(c.armedBreakpoints) == 0 {
+		return
+	}
+	for _, id := range c.armedBreakpointIDs() {
+		if armed := c.armedBreakpoints[id]; armed.trigger == detail.BreakpointID && armed.armed {
+			detail.Armed = append(detail.Armed, id)
+		}
+	}
+	if armed := c.armedBreakpoints[detail.BreakpointID]; armed != nil {
+		detail.ArmedBy = armed.trigger
+		detail.Disarmed = armed.oneShot && !armed.armed
+	}
+}
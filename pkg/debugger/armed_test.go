@@ -0,0 +1,189 @@
+package debugger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestAnnotateArmingStop(t *testing.T) {
+	c := &Client{}
+	c.setArmedBreakpoint(5, 2, false)
+	c.setArmedBreakpoint(6, 2, true)
+	c.setArmedBreakpoint(7, 3, true)
+	c.armedBreakpoints[5].armed = true
+	c.armedBreakpoints[6].armed = true
+
+	testCases := []struct {
+		name     string
+		id       int
+		expected types.StopDetail
+		reason   string
+	}{
+		{
+			name:     "Trigger",
+			id:       2,
+			expected: types.StopDetail{Kind: types.StopBreakpoint, BreakpointID: 2, Armed: []int{5, 6}},
+			reason:   "hit breakpoint; armed breakpoints 5, 6",
+		},
+		{
+			name:     "Armed breakpoint",
+			id:       5,
+			expected: types.StopDetail{Kind: types.StopBreakpoint, BreakpointID: 5, ArmedBy: 2},
+			reason:   "hit breakpoint armed by breakpoint 2",
+		},
+		{
+			name:     "One-shot breakpoint that disarmed itself",
+			id:       7,
+			expected: types.StopDetail{Kind: types.StopBreakpoint, BreakpointID: 7, ArmedBy: 3, Disarmed: true},
+			reason:   "hit breakpoint armed by breakpoint 3, disarmed until breakpoint 3 is hit again",
+		},
+		{
+			name:     "Other breakpoint",
+			id:       9,
+			expected: types.StopDetail{Kind: types.StopBreakpoint, BreakpointID: 9},
+			reason:   "hit breakpoint",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			detail := &types.StopDetail{Kind: types.StopBreakpoint, BreakpointID: tc.id}
+			c.annotateArmingStop(detail)
+			if !reflect.DeepEqual(*detail, tc.expected) {
+				t.Errorf("Expected %+v, got %+v", tc.expected, *detail)
+			}
+			if reason := formatStopDetail(detail); reason != tc.reason {
+				t.Errorf("Expected stop reason %q, got %q", tc.reason, reason)
+			}
+		})
+	}
+}
+
+func TestMoveAndForgetArmedBreakpoint(t *testing.T) {
+	c := &Client{}
+	c.setArmedBreakpoint(5, 2, false)
+	c.setArmedBreakpoint(6, 5, true)
+
+	// Re-creating the breakpoint moves it both as an armed breakpoint and as a trigger
+	c.moveArmedBreakpoint(5, 8)
+	breakpoint := types.Breakpoint{ID: 8}
+	c.annotateArmedBreakpoint(&breakpoint)
+	if breakpoint.ArmedAfter != 2 || c.armedBreakpoints[5] != nil {
+		t.Errorf("Expected breakpoint 8 armed after breakpoint 2, got %+v", breakpoint)
+	}
+	if trigger := c.armedBreakpoints[6].trigger; trigger != 8 {
+		t.Errorf("Expected breakpoint 6 to be armed by breakpoint 8, got %d", trigger)
+	}
+
+	// Clearing a trigger drops the arming of the breakpoints it armed
+	c.forgetArmedBreakpoint(8)
+	if len(c.armedBreakpoints) != 0 {
+		t.Errorf("Expected no armed breakpoints left, got %v", c.armedBreakpointIDs())
+	}
+}
+
+// armingTarget returns a fake Delve with breakpoint 1 at main.go:10 and tracepoint 2 at
+// main.go:30, whose continues stop at the breakpoints of hits in turn
+func armingTarget(t *testing.T, hits ...int) (*Client, *fakeBreakpoints) {
+	t.Helper()
+	bps := &fakeBreakpoints{}
+	bps.add(&api.Breakpoint{ID: 1, File: "main.go", Line: 10, FunctionName: "main.main"})
+	bps.add(&api.Breakpoint{ID: 2, File: "main.go", Line: 30, FunctionName: "main.main", Tracepoint: true})
+
+	var commands []string
+	c, _ := newFakeDelve(t, bps.serve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(5)),
+		"FindLocation": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.FindLocationIn
+			decodeFakeArgs(t, raw, &args)
+			file, line, _ := strings.Cut(args.Loc, ":")
+			n, err := strconv.Atoi(line)
+			if err != nil {
+				return nil, fmt.Errorf("location %q not found", args.Loc)
+			}
+			return rpc2.FindLocationOut{Locations: []api.Location{{File: file, Line: n, Function: &api.Function{Name_: "main.main"}}}}, nil
+		},
+		"Command": fakeCommands(t, &commands, func(api.DebuggerCommand) api.DebuggerState {
+			bp := bps.get(hits[0])
+			hits = hits[1:]
+			state := stoppedState(bp.Line)
+			state.CurrentThread.Breakpoint = bp
+			state.Threads = []*api.Thread{state.CurrentThread}
+			return *state
+		}),
+	}))
+	return c, bps
+}
+
+func TestArmBreakpointAfter(t *testing.T) {
+	c, bps := armingTarget(t, 1, 3, 1)
+
+	response := c.ArmBreakpointAfter(1, "main.go:20", true)
+	if response.Status != "success" || response.Context.Operation != "arm_breakpoint_after" {
+		t.Fatalf("Expected a breakpoint armed after breakpoint 1, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if response.Breakpoint.ID != 3 || response.Breakpoint.ArmedAfter != 1 {
+		t.Errorf("Expected breakpoint 3 armed after breakpoint 1, got %+v", response.Breakpoint)
+	}
+	disabled := func() bool {
+		bp := bps.get(3)
+		return bp == nil || bp.Disabled
+	}
+	if !disabled() {
+		t.Fatal("Expected breakpoint 3 disabled until breakpoint 1 is hit")
+	}
+
+	// A hit of the trigger arms it, and a hit of the one-shot breakpoint disarms it again,
+	// until the trigger is hit once more
+	for i, expected := range []bool{false, true, false} {
+		if stop := c.Continue(context.Background()); stop.Status != "success" {
+			t.Fatalf("Expected continue %d to stop, got %s", i, stop.Context.ErrorMessage)
+		}
+		if disabled() != expected {
+			t.Errorf("Expected breakpoint 3 disabled %v after continue %d", expected, i)
+		}
+	}
+}
+
+func TestArmBreakpointAfterErrors(t *testing.T) {
+	c, bps := armingTarget(t)
+
+	testCases := []struct {
+		name     string
+		trigger  int
+		location string
+		expected string
+	}{
+		{name: "Internal breakpoint", trigger: -1, location: "main.go:20", expected: "breakpoint -1 is internal to Delve"},
+		{name: "Missing trigger", trigger: 7, location: "main.go:20", expected: "breakpoint 7 not found"},
+		{name: "Tracepoint trigger", trigger: 2, location: "main.go:20", expected: "breakpoint 2 is a tracepoint"},
+		{name: "Unresolved location", trigger: 1, location: "nowhere", expected: "cannot resolve location"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.ArmBreakpointAfter(tc.trigger, tc.location, false)
+			if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, tc.expected) {
+				t.Errorf("Expected an error containing %q, got %+v", tc.expected, response.Context)
+			}
+		})
+	}
+	if bps.lastID != 2 || len(c.armedBreakpoints) != 0 {
+		t.Errorf("Expected no breakpoint set or armed, got last ID %d and armed %v", bps.lastID, c.armedBreakpointIDs())
+	}
+
+	response := NewClient().ArmBreakpointAfter(1, "main.go:10", false)
+	if response.Status != "error" || response.Context.Operation != "arm_breakpoint_after" || !strings.Contains(response.Context.ErrorMessage, "no active debug session") {
+		t.Errorf("Expected an arm_breakpoint_after error without a session, got %+v", response)
+	}
+}
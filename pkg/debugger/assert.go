@@ -0,0 +1,181 @@
+package debugger
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// assertLoadConfig loads enough of an assertion's operands to show why it failed
+var assertLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 1,
+	MaxStringLen:       128,
+	MaxArrayValues:     16,
+	MaxStructFields:    -1,
+}
+
+// Assert evaluates a boolean expression, such as "requestCount >= 0", in the given frame of
+// the selected goroutine and reports whether it holds. An assertion that is evaluated
+// succeeds whether it holds or not; only one that can't be checked, because it fails to
+// evaluate or isn't boolean, is an error. The operands of a top-level comparison or logical
+// operator are evaluated too, to show what made it fail.
+func (c *Client) Assert(expr string, frame int) types.AssertResponse {
+	if c.client == nil {
+		return c.createAssertResponse(nil, expr, frame, nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createAssertResponse(nil, expr, frame, nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createAssertResponse(nil, expr, frame, nil, nil, fmt.Errorf("cannot evaluate assertions while the target is running; stop the target first"))
+	}
+
+	scope, err := c.frameScope(state, frame)
+	if err != nil {
+		return c.createAssertResponse(state, expr, frame, nil, nil, err)
+	}
+
+	logger.Debug("Asserting %q in frame %d", expr, frame)
+	v, err := c.client.EvalVariable(scope, expr, assertLoadConfig)
+	if err != nil {
+		if isUnresolvedSymbol(err) {
+			return c.createAssertResponse(state, expr, frame, nil, nil, fmt.Errorf("could not resolve %q: %v; variables in scope: %s", expr, err, c.scopeVariableNames(scope)))
+		}
+		return c.createAssertResponse(state, expr, frame, nil, nil, fmt.Errorf("failed to evaluate assertion %q: %v", expr, err))
+	}
+	if v == nil {
+		return c.createAssertResponse(state, expr, frame, nil, nil, fmt.Errorf("assertion %q produced no value", expr))
+	}
+	if v.Unreadable != "" {
+		return c.createAssertResponse(state, expr, frame, v, nil, fmt.Errorf("assertion %q could not be read: %s", expr, v.Unreadable))
+	}
+	if v.Kind != reflect.Bool {
+		return c.createAssertResponse(state, expr, frame, v, nil, fmt.Errorf("assertion %q is of type %s, not bool; give a boolean expression such as x >= 0", expr, assertValueType(v)))
+	}
+
+	var operands []types.AssertOperand
+	for _, operand := range assertionOperands(expr) {
+		operands = append(operands, c.evalAssertOperand(scope, operand))
+	}
+	return c.createAssertResponse(state, expr, frame, v, operands, nil)
+}
+
+// assertionOperands returns the source of the two operands of the comparison or logical
+// operator at the top of a boolean expression, leaving out literals, whose value is plain.
+// It returns none for any other expression, or one that isn't valid Go.
+func assertionOperands(expr string) []string {
+	node, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil
+	}
+	for {
+		paren, ok := node.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		node = paren.X
+	}
+
+	binary, ok := node.(*ast.BinaryExpr)
+	if !ok {
+		return nil
+	}
+	switch binary.Op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ, token.LAND, token.LOR:
+	default:
+		return nil
+	}
+
+	var operands []string
+	for _, operand := range []ast.Expr{binary.X, binary.Y} {
+		if _, literal := operand.(*ast.BasicLit); literal {
+			continue
+		}
+		if ident, ok := operand.(*ast.Ident); ok && (ident.Name == "nil" || ident.Name == "true" || ident.Name == "false") {
+			continue
+		}
+		// Positions count from 1 within the parsed expression
+		operands = append(operands, strings.TrimSpace(expr[operand.Pos()-1:operand.End()-1]))
+	}
+	return operands
+}
+
+// evalAssertOperand evaluates an operand of an assertion, recording why it couldn't be read
+func (c *Client) evalAssertOperand(scope api.EvalScope, expr string) types.AssertOperand {
+	operand := types.AssertOperand{Expression: expr}
+	v, err := c.client.EvalVariable(scope, expr, assertLoadConfig)
+	switch {
+	case err != nil:
+		operand.Error = err.Error()
+	case v == nil:
+		operand.Error = "no value"
+	case v.Unreadable != "":
+		operand.Error = v.Unreadable
+	default:
+		operand.Value = v.SinglelineString()
+		operand.Type = assertValueType(v)
+	}
+	return operand
+}
+
+// assertValueType names the type of a value. Delve gives computed values, such as the
+// result of a comparison or of len, no type name, only a kind.
+func assertValueType(v *api.Variable) string {
+	if v.Type != "" {
+		return v.Type
+	}
+	return v.Kind.String()
+}
+
+// createAssertResponse creates an AssertResponse. An expression that evaluated but isn't
+// boolean is reported with its value, to show what it evaluates to instead.
+func (c *Client) createAssertResponse(state *api.DebuggerState, expr string, frame int, v *api.Variable, operands []types.AssertOperand, err error) types.AssertResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "assert"
+
+	response := types.AssertResponse{
+		Status:     "success",
+		Context:    context,
+		Expression: expr,
+		Frame:      frame,
+		Operands:   operands,
+	}
+	if v != nil && v.Unreadable == "" {
+		response.Value = v.SinglelineString()
+		response.Type = assertValueType(v)
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		response.Message = fmt.Sprintf("could not check %s: %v", expr, err)
+		return response
+	}
+
+	response.Passed = v.Value == "true"
+	if response.Passed {
+		response.Message = fmt.Sprintf("%s holds", expr)
+		return response
+	}
+
+	response.Message = fmt.Sprintf("%s does not hold", expr)
+	var values []string
+	for _, operand := range operands {
+		if operand.Error == "" {
+			values = append(values, fmt.Sprintf("%s = %s", operand.Expression, operand.Value))
+		}
+	}
+	if len(values) > 0 {
+		response.Message += ": " + strings.Join(values, ", ")
+	}
+	return response
+}
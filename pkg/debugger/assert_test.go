@@ -0,0 +1,138 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestAssertionOperands(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expr     string
+		expected []string
+	}{
+		{name: "Comparison", expr: "a >= b", expected: []string{"a", "b"}},
+		{name: "Literal left out", expr: "requestCount >= 0", expected: []string{"requestCount"}},
+		{name: "Nil left out", expr: "err == nil", expected: []string{"err"}},
+		{name: "Parenthesized", expr: "((len(s.data) == n))", expected: []string{"len(s.data)", "n"}},
+		{name: "Logical operator", expr: `k != "a" || len(s.data) == 2`, expected: []string{`k != "a"`, "len(s.data) == 2"}},
+		{name: "Arithmetic", expr: "a + b"},
+		{name: "Not binary", expr: "ok"},
+		{name: "Not Go", expr: "a >="},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := assertionOperands(tc.expr); !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestCreateAssertResponse(t *testing.T) {
+	operands := []types.AssertOperand{
+		{Expression: "requestCount", Value: "-1", Type: "int"},
+		{Expression: "limit", Error: "could not find symbol value for limit"},
+	}
+
+	testCases := []struct {
+		name     string
+		variable *api.Variable
+		err      error
+		status   string
+		passed   bool
+		message  string
+	}{
+		{
+			name:     "Holds",
+			variable: &api.Variable{Type: "bool", Kind: reflect.Bool, Value: "true"},
+			status:   "success", passed: true, message: "requestCount >= limit holds",
+		},
+		{
+			name:     "Does not hold",
+			variable: &api.Variable{Type: "bool", Kind: reflect.Bool, Value: "false"},
+			status:   "success", message: "requestCount >= limit does not hold: requestCount = -1",
+		},
+		{
+			name:     "Not checked",
+			variable: &api.Variable{Kind: reflect.Int, Value: "1"},
+			err:      fmt.Errorf("not bool"),
+			status:   "error", message: "could not check requestCount >= limit: not bool",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := NewClient().createAssertResponse(nil, "requestCount >= limit", 0, tc.variable, operands, tc.err)
+			if response.Status != tc.status || response.Passed != tc.passed {
+				t.Errorf("Expected status %s and passed %v, got %s and %v", tc.status, tc.passed, response.Status, response.Passed)
+			}
+			if response.Message != tc.message {
+				t.Errorf("Expected message %q, got %q", tc.message, response.Message)
+			}
+			if response.Type == "" {
+				t.Errorf("Expected the type of the value to be named")
+			}
+		})
+	}
+}
+
+func TestAssert(t *testing.T) {
+	requestCount := &api.Variable{Name: "requestCount", Type: "int", Kind: reflect.Int, Value: "-1"}
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State":      fakeState(stoppedState(10)),
+		"Stacktrace": fakeResult(rpc2.StacktraceOut{Locations: []api.Stackframe{{Location: api.Location{File: "main.go", Line: 10, Function: &api.Function{Name_: "main.main"}}}}}),
+		"Eval": fakeEval(t, map[string]*api.Variable{
+			"requestCount >= 0":     {Kind: reflect.Bool, Value: "false"},
+			"requestCount < limit":  {Kind: reflect.Bool, Value: "true"},
+			"requestCount >= limit": {Kind: reflect.Bool, Value: "false"},
+			"requestCount":          requestCount,
+			"limit":                 {Name: "limit", Type: "int", Kind: reflect.Int, Value: "0"},
+		}),
+		"ListFunctionArgs": fakeResult(rpc2.ListFunctionArgsOut{}),
+		"ListLocalVars":    fakeResult(rpc2.ListLocalVarsOut{Variables: []api.Variable{*requestCount}}),
+	})
+
+	testCases := []struct {
+		name    string
+		expr    string
+		status  string
+		passed  bool
+		message string
+	}{
+		{name: "Holds", expr: "requestCount < limit", status: "success", passed: true, message: "requestCount < limit holds"},
+		{name: "Does not hold", expr: "requestCount >= limit", status: "success", message: "requestCount >= limit does not hold: requestCount = -1, limit = 0"},
+		{name: "Literal operand left out", expr: "requestCount >= 0", status: "success", message: "requestCount >= 0 does not hold: requestCount = -1"},
+		{name: "Not boolean", expr: "requestCount", status: "error", message: "is of type int, not bool"},
+		{name: "Unresolved", expr: "count > 0", status: "error", message: "variables in scope: requestCount"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.Assert(tc.expr, 0)
+			if response.Status != tc.status || response.Passed != tc.passed {
+				t.Fatalf("Expected %s with passed %v, got %s with passed %v: %s", tc.status, tc.passed, response.Status, response.Passed, response.Context.ErrorMessage)
+			}
+			if !strings.Contains(response.Message, tc.message) {
+				t.Errorf("Expected message %q, got %q", tc.message, response.Message)
+			}
+		})
+	}
+
+	// A value that isn't boolean is shown, to tell what the expression is instead
+	if response := c.Assert("requestCount", 0); response.Value != "-1" || response.Type != "int" {
+		t.Errorf("Expected the int value -1, got %q of type %q", response.Value, response.Type)
+	}
+
+	response := NewClient().Assert("x >= 0", 0)
+	if response.Status != "error" || response.Context.Operation != "assert" || !strings.Contains(response.Context.ErrorMessage, "no active debug session") {
+		t.Errorf("Expected a no active debug session error, got %+v", response.Context)
+	}
+}
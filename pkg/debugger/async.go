@@ -0,0 +1,214 @@
+package debugger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// asyncRun is a continue started by ContinueAsync. done is closed once the program has
+// stopped, after which state and err hold how the continue ended.
+type asyncRun struct {
+	done  chan struct{}
+	state *api.DebuggerState
+	err   error
+}
+
+// ContinueAsync resumes the program and returns without waiting for it to stop. Use
+// WaitForStop to learn when and why it stops, and Halt to stop it.
+func (c *Client) ContinueAsync() types.ContinueAsyncResponse {
+	if c.client == nil {
+		return c.createContinueAsyncResponse(nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createContinueAsyncResponse(nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createContinueAsyncResponse(nil, fmt.Errorf("the target is already running; use wait_for_stop to wait for it to stop, or halt to stop it"))
+	}
+
+	if state.Exited {
+		return c.createContinueAsyncResponse(state, fmt.Errorf("the program has exited with status %d", state.ExitStatus))
+	}
+
+	c.asyncMutex.Lock()
+	defer c.asyncMutex.Unlock()
+
+	// A run that was just started may not show as running yet
+	if c.async != nil {
+		select {
+		case <-c.async.done:
+		default:
+			return c.createContinueAsyncResponse(nil, fmt.Errorf("the target is already running; use wait_for_stop to wait for it to stop, or halt to stop it"))
+		}
+	}
+
+	run := &asyncRun{done: make(chan struct{})}
+	c.async = run
+
+	logger.Debug("Continuing execution in the background")
+	go func() {
+		run.state, run.err = c.drainContinue()
+		c.recordCaptureHits(run.state)
+		c.noteExit(run.state, run.err)
+		close(run.done)
+	}()
+
+	return c.createContinueAsyncResponse(nil, nil)
+}
+
+// WaitForStop waits up to timeout for the program to stop at a breakpoint, panic or exit,
+// and reports why it stopped. A timeout of 0 only checks. Any number of callers may wait
+// at once; they all get the same stop.
+func (c *Client) WaitForStop(timeout time.Duration) types.WaitForStopResponse {
+	if c.client == nil {
+		return c.createWaitForStopResponse(nil, false, fmt.Errorf("no active debug session"))
+	}
+
+	c.asyncMutex.Lock()
+	run := c.async
+	c.asyncMutex.Unlock()
+
+	if run == nil {
+		// The program wasn't resumed by ContinueAsync, so watch its state instead
+		return c.pollForStop(timeout)
+	}
+
+	// Check first, as select picks at random when a zero timeout has also expired
+	select {
+	case <-run.done:
+	default:
+		select {
+		case <-run.done:
+		case <-time.After(timeout):
+			return c.createWaitForStopResponse(nil, false, nil)
+		}
+	}
+
+	return c.createWaitForStopResponse(run.state, true, run.err)
+}
+
+// Running reports whether a ContinueAsync run is in progress. Delve only answers commands
+// that read the target once it stops, so until then the session can only be waited on,
+// halted, or asked for its status and output.
+func (c *Client) Running() bool {
+	c.asyncMutex.Lock()
+	run := c.async
+	c.asyncMutex.Unlock()
+
+	if run == nil {
+		return false
+	}
+	select {
+	case <-run.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// haltAsyncRun halts a ContinueAsync run in progress and waits for it to end, so the
+// session can be detached from
+func (c *Client) haltAsyncRun() {
+	c.asyncMutex.Lock()
+	run := c.async
+	c.asyncMutex.Unlock()
+
+	if run == nil || !c.Running() {
+		return
+	}
+	logger.Debug("Halting the background continue to end the session")
+	if _, err := c.client.Halt(); err != nil {
+		logger.Debug("Warning: Failed to halt the background continue: %v", err)
+		return
+	}
+	select {
+	case <-run.done:
+	case <-time.After(haltTimeout):
+		logger.Debug("Warning: The background continue did not stop within %v of halting it", haltTimeout)
+	}
+}
+
+// forgetAsyncRun drops a ContinueAsync run that has ended, once the program is resumed some
+// other way, so WaitForStop reports the stops that come after it rather than the run's.
+// A run still in progress is kept; the command resuming the program fails as it is running.
+func (c *Client) forgetAsyncRun() {
+	c.asyncMutex.Lock()
+	defer c.asyncMutex.Unlock()
+
+	if c.async == nil {
+		return
+	}
+	select {
+	case <-c.async.done:
+		c.async = nil
+	default:
+	}
+}
+
+// pollForStop waits for a program that is running without a ContinueAsync to stop
+func (c *Client) pollForStop(timeout time.Duration) types.WaitForStopResponse {
+	deadline := time.Now().Add(timeout)
+	for {
+		state, err := c.client.GetStateNonBlocking()
+		if err != nil {
+			return c.createWaitForStopResponse(nil, false, fmt.Errorf("failed to get state: %v", err))
+		}
+		if !state.Running {
+			return c.createWaitForStopResponse(state, true, nil)
+		}
+		if !time.Now().Before(deadline) {
+			return c.createWaitForStopResponse(nil, false, nil)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// createContinueAsyncResponse creates a ContinueAsyncResponse
+func (c *Client) createContinueAsyncResponse(state *api.DebuggerState, err error) types.ContinueAsyncResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "continue_async"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.ContinueAsyncResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	return types.ContinueAsyncResponse{
+		Status:  "success",
+		Context: context,
+		Running: true,
+	}
+}
+
+// createWaitForStopResponse creates a WaitForStopResponse. When the program is still
+// running, the response is successful with Stopped false.
+func (c *Client) createWaitForStopResponse(state *api.DebuggerState, stopped bool, err error) types.WaitForStopResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "wait_for_stop"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.WaitForStopResponse{
+			Status:  "error",
+			Context: context,
+			Stopped: stopped,
+		}
+	}
+
+	return types.WaitForStopResponse{
+		Status:  "success",
+		Context: context,
+		Stopped: stopped,
+		Running: !stopped,
+	}
+}
@@ -0,0 +1,84 @@
+package debugger
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+func TestWaitForStopConcurrentWaiters(t *testing.T) {
+	// WaitForStop only needs a session, not a connection, while a continue is in progress
+	c := NewClient()
+	c.client = &rpc2.RPCClient{}
+	run := &asyncRun{done: make(chan struct{})}
+	c.async = run
+
+	if response := c.WaitForStop(10 * time.Millisecond); response.Stopped || !response.Running {
+		t.Errorf("Expected the program to still be running, got %+v", response)
+	}
+
+	var wg sync.WaitGroup
+	responses := make([]bool, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = c.WaitForStop(5 * time.Second).Stopped
+		}(i)
+	}
+
+	run.state = &api.DebuggerState{Exited: true}
+	close(run.done)
+	wg.Wait()
+
+	for i, stopped := range responses {
+		if !stopped {
+			t.Errorf("Expected waiter %d to see the stop", i)
+		}
+	}
+
+	if response := c.WaitForStop(0); response.Status != "success" || !response.Stopped {
+		t.Errorf("Expected a successful stop once the program exited, got %+v", response)
+	}
+}
+
+func TestForgetAsyncRun(t *testing.T) {
+	c := NewClient()
+	run := &asyncRun{done: make(chan struct{})}
+	c.async = run
+
+	// A run in progress is kept, so its stop is still reported
+	c.forgetAsyncRun()
+	if c.async != run {
+		t.Fatalf("Expected the run in progress to be kept")
+	}
+
+	// Once it has stopped, resuming the program some other way drops it
+	run.state = &api.DebuggerState{}
+	close(run.done)
+	c.forgetAsyncRun()
+	if c.async != nil {
+		t.Errorf("Expected the stopped run to be dropped")
+	}
+}
+
+func TestRunning(t *testing.T) {
+	c := NewClient()
+	if c.Running() {
+		t.Errorf("Expected a session without a background continue not to be running")
+	}
+
+	run := &asyncRun{done: make(chan struct{})}
+	c.async = run
+	if !c.Running() {
+		t.Errorf("Expected a background continue in progress to be running")
+	}
+
+	close(run.done)
+	if c.Running() {
+		t.Errorf("Expected a background continue that stopped not to be running")
+	}
+}
@@ -2,6 +2,9 @@ package debugger
 
 import (
 	"fmt"
+	"go/parser"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-delve/delve/service/api"
@@ -9,8 +12,42 @@ import (
 	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
-// SetBreakpoint sets a breakpoint at the specified file and line with optional condition
-func (c *Client) SetBreakpoint(file string, line int, condition string) types.BreakpointResponse {
+// BreakpointOptions holds the optional settings of a breakpoint set with SetBreakpoint
+type BreakpointOptions struct {
+	Condition    string // Go boolean expression the breakpoint only stops for
+	HitCondition string // Hit-count condition, e.g. "== 100" to stop on the 100th hit only
+
+	// pprof label as key=value, e.g. "handler=checkout". Only goroutines carrying it stop;
+	// hits on other goroutines are continued past but still counted, also by HitCondition.
+	GoroutineLabel string
+
+	// Number of hits to continue past before stopping. Only hits that satisfy Condition,
+	// HitCondition and GoroutineLabel are ignored.
+	IgnoreCount int
+
+	// Expressions evaluated in the hit goroutine's scope each time the breakpoint stops,
+	// reported with the stop
+	CaptureExprs []string
+
+	// Fail on a line without code, such as a comment or blank line, instead of setting the
+	// breakpoint at the next line that has code
+	Strict bool
+
+	// Stop at the returns of the function containing the line instead of at the line, with
+	// the function's results reported on each stop
+	OnReturn bool
+
+	// On the first hit that stops, pin the breakpoint to the goroutine that hit it: its
+	// condition is rewritten to match that goroutine only, so hits on others are continued
+	// past
+	PinToFirstGoroutine bool
+}
+
+// SetBreakpoint sets a breakpoint at the specified file and line. A line without code is
+// moved off to the next line that has some, unless opts.Strict is set; the response gives
+// both the requested and the actual line. With opts.OnReturn, it is set at the returns of
+// the function containing the line instead; see atReturns.
+func (c *Client) SetBreakpoint(file string, line int, opts BreakpointOptions) types.BreakpointResponse {
 	if c.client == nil {
 		return types.BreakpointResponse{
 			Status: "error",
@@ -21,23 +58,127 @@ func (c *Client) SetBreakpoint(file string, line int, condition string) types.Br
 		}
 	}
 
-	if condition != "" {
-		logger.Debug("Setting conditional breakpoint at %s:%d with condition: %s", file, line, condition)
+	if opts.Condition != "" {
+		if err := validateCondition(opts.Condition); err != nil {
+			return types.BreakpointResponse{
+				Status: "error",
+				Context: types.DebugContext{
+					ErrorMessage: err.Error(),
+					Timestamp:    getCurrentTimestamp(),
+				},
+			}
+		}
+		logger.Debug("Setting conditional breakpoint at %s:%d with condition: %s", file, line, opts.Condition)
 	} else {
 		logger.Debug("Setting breakpoint at %s:%d", file, line)
 	}
 
-	bp, err := c.client.CreateBreakpoint(&api.Breakpoint{
-		File: file,
-		Line: line,
-		Cond: condition,
-	})
+	hitCondition := opts.HitCondition
+	if hitCondition != "" {
+		var err error
+		if hitCondition, err = normalizeHitCondition(hitCondition); err != nil {
+			return types.BreakpointResponse{
+				Status: "error",
+				Context: types.DebugContext{
+					ErrorMessage: err.Error(),
+					Timestamp:    getCurrentTimestamp(),
+				},
+			}
+		}
+		logger.Debug("Breakpoint at %s:%d stops when the hit count is %s", file, line, hitCondition)
+	}
+
+	goroutineLabel := opts.GoroutineLabel
+	if goroutineLabel != "" {
+		var err error
+		if goroutineLabel, err = parseGoroutineLabel(goroutineLabel); err != nil {
+			return types.BreakpointResponse{
+				Status: "error",
+				Context: types.DebugContext{
+					ErrorMessage: err.Error(),
+					Timestamp:    getCurrentTimestamp(),
+				},
+			}
+		}
+		logger.Debug("Breakpoint at %s:%d only stops goroutines labelled %s", file, line, goroutineLabel)
+	}
+
+	if opts.IgnoreCount < 0 {
+		return types.BreakpointResponse{
+			Status: "error",
+			Context: types.DebugContext{
+				ErrorMessage: fmt.Sprintf("invalid ignore count %d: must be 0 or more", opts.IgnoreCount),
+				Timestamp:    getCurrentTimestamp(),
+			},
+		}
+	}
+
+	for _, expr := range opts.CaptureExprs {
+		if _, err := parser.ParseExpr(expr); err != nil {
+			return types.BreakpointResponse{
+				Status: "error",
+				Context: types.DebugContext{
+					ErrorMessage: fmt.Sprintf("invalid capture expression %q: %v", expr, err),
+					Timestamp:    getCurrentTimestamp(),
+				},
+			}
+		}
+	}
+	if len(opts.CaptureExprs) > 0 {
+		logger.Debug("Breakpoint at %s:%d captures %v", file, line, opts.CaptureExprs)
+	}
+
+	// Delve evaluates the breakpoint's Variables itself each time it is hit
+	request := &api.Breakpoint{
+		File:      file,
+		Line:      line,
+		Cond:      opts.Condition,
+		HitCond:   hitCondition,
+		Variables: opts.CaptureExprs,
+	}
+
+	var returnOf string
+	if opts.OnReturn {
+		function, err := c.functionAt(file, line)
+		if err == nil {
+			err = c.atReturns(request, function)
+		}
+		if err != nil {
+			return types.BreakpointResponse{
+				Status: "error",
+				Context: types.DebugContext{
+					ErrorMessage: err.Error(),
+					Timestamp:    getCurrentTimestamp(),
+				},
+			}
+		}
+		returnOf = function
+	}
+
+	bp, err := c.client.CreateBreakpoint(request)
+
+	if err != nil && !opts.Strict && !opts.OnReturn {
+		// Delve only stops at lines with code; move a comment or blank line to the next one
+		if _, _, ok := parseNoStatement(err.Error()); ok {
+			nextFile, nextLine, findErr := c.nextCodeLine(file, line)
+			if findErr != nil {
+				err = findErr
+			} else {
+				request.File, request.Line = nextFile, nextLine
+				bp, err = c.client.CreateBreakpoint(request)
+			}
+		}
+	}
 
 	if err != nil {
+		errMsg := fmt.Sprintf("failed to set breakpoint: %v", err)
+		if opts.Condition != "" {
+			errMsg = fmt.Sprintf("failed to set breakpoint with condition %q: %v", opts.Condition, err)
+		}
 		return types.BreakpointResponse{
 			Status: "error",
 			Context: types.DebugContext{
-				ErrorMessage: fmt.Sprintf("failed to set breakpoint: %v", err),
+				ErrorMessage: errMsg,
 				Timestamp:    getCurrentTimestamp(),
 			},
 		}
@@ -49,27 +190,140 @@ func (c *Client) SetBreakpoint(file string, line int, condition string) types.Br
 		logger.Debug("Warning: Failed to get state after setting breakpoint: %v", err)
 	}
 
-	breakpoint := &types.Breakpoint{
-		DelveBreakpoint: bp,
-		ID:              bp.ID,
-		Status:          getBreakpointStatus(bp),
-		Location:        getBreakpointLocation(bp),
-		Condition:       bp.Cond,
-		HitCount:        uint64(bp.TotalHitCount),
+	if goroutineLabel != "" {
+		c.setLabelFilter(bp.ID, goroutineLabel)
+	}
+	if opts.IgnoreCount > 0 {
+		logger.Debug("Breakpoint %d ignores its first %d hits", bp.ID, opts.IgnoreCount)
+		c.setIgnoreCount(bp.ID, opts.IgnoreCount)
+	}
+	if returnOf != "" {
+		c.setReturnBreakpoint(bp, returnOf)
+	}
+	if opts.PinToFirstGoroutine {
+		logger.Debug("Breakpoint %d pins itself to the goroutine of its first hit", bp.ID)
+		c.setGoroutinePin(bp.ID, opts.Condition)
 	}
 
 	context := c.createDebugContext(state)
 	context.Operation = "set_breakpoint"
 
-	return types.BreakpointResponse{
-		Status:     "success",
-		Context:    context,
-		Breakpoint: *breakpoint,
+	breakpoint := convertBreakpoint(bp)
+	c.annotateBreakpoint(&breakpoint)
+
+	response := types.BreakpointResponse{
+		Status:        "success",
+		Context:       context,
+		Breakpoint:    breakpoint,
+		RequestedLine: line,
+		ActualLine:    bp.Line,
+	}
+	if bp.Line != line && returnOf == "" {
+		response.Adjustment = describeLineShift(line, bp.Line, bp.FunctionName)
+	}
+	return response
+}
+
+// SetBreakpointAtLocation sets a breakpoint at a location spec such as webserver.go:20,
+// main.helloHandler or a line of the current file; see ParseLocation. When the spec is
+// ambiguous nothing is set and the candidates are returned to pick from. Like for
+// SetBreakpoint, a file:line without code moves to the next line with code.
+func (c *Client) SetBreakpointAtLocation(location string, opts BreakpointOptions) types.BreakpointResponse {
+	pos, candidates, err := c.ParseLocation(location)
+	if err != nil {
+		if file, line, ok := parseNoStatement(err.Error()); ok && !opts.Strict {
+			return c.SetBreakpoint(file, line, opts)
+		}
+		return types.BreakpointResponse{
+			Status: "error",
+			Context: types.DebugContext{
+				Operation:    "set_breakpoint",
+				ErrorMessage: err.Error(),
+				Timestamp:    getCurrentTimestamp(),
+			},
+			Candidates: candidates,
+		}
+	}
+
+	return c.SetBreakpoint(pos.File, pos.Line, opts)
+}
+
+// BreakpointSpec describes one breakpoint of a batch set by SetBreakpoints: either a
+// Location spec or a File and Line, with the same options as a single breakpoint
+type BreakpointSpec struct {
+	Location string
+	File     string
+	Line     int
+	BreakpointOptions
+}
+
+// SetBreakpoints sets several breakpoints in one call. Each spec is set on its own, so one
+// that fails to parse or resolve doesn't keep the others from being set; the results say
+// which ones failed and why, in the order of the specs.
+func (c *Client) SetBreakpoints(specs []BreakpointSpec) types.SetBreakpointsResponse {
+	if c.client == nil {
+		return c.createSetBreakpointsResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	if len(specs) == 0 {
+		return c.createSetBreakpointsResponse(nil, nil, fmt.Errorf("no breakpoints given"))
+	}
+
+	logger.Debug("Setting %d breakpoints", len(specs))
+
+	results := make([]types.BatchBreakpointResult, 0, len(specs))
+	for i, spec := range specs {
+		result := types.BatchBreakpointResult{Index: i, Spec: spec.describe()}
+
+		var response types.BreakpointResponse
+		switch {
+		case spec.Location != "":
+			response = c.SetBreakpointAtLocation(spec.Location, spec.BreakpointOptions)
+		case spec.File != "" && spec.Line > 0:
+			response = c.SetBreakpoint(spec.File, spec.Line, spec.BreakpointOptions)
+		default:
+			result.Status = "error"
+			result.Error = "either location or both file and line are required"
+			results = append(results, result)
+			continue
+		}
+
+		result.Status = response.Status
+		if response.Status == "success" {
+			breakpoint := response.Breakpoint
+			result.Breakpoint = &breakpoint
+			result.Adjustment = response.Adjustment
+		} else {
+			result.Error = response.Context.ErrorMessage
+			result.Candidates = response.Candidates
+		}
+		results = append(results, result)
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		logger.Debug("Warning: Failed to get state after setting breakpoints: %v", err)
+	}
+
+	return c.createSetBreakpointsResponse(state, results, nil)
+}
+
+// describe renders a spec the way it was given, to tell the results of a batch apart
+func (spec BreakpointSpec) describe() string {
+	where := spec.Location
+	if spec.Location == "" && spec.File != "" {
+		where = fmt.Sprintf("%s:%d", spec.File, spec.Line)
+	}
+	if spec.OnReturn {
+		where += " on return"
 	}
+	return where
 }
 
-// ListBreakpoints returns all currently set breakpoints
-func (c *Client) ListBreakpoints() types.BreakpointListResponse {
+// ListBreakpoints returns all currently set breakpoints sorted by ID. Delve's own
+// breakpoints, such as the ones for unrecovered panics and the ones a step in progress
+// uses, are only included when includeInternal is true; each is labelled with its kind.
+func (c *Client) ListBreakpoints(includeInternal bool) types.BreakpointListResponse {
 	if c.client == nil {
 		return types.BreakpointListResponse{
 			Status: "error",
@@ -91,16 +345,31 @@ func (c *Client) ListBreakpoints() types.BreakpointListResponse {
 		}
 	}
 
+	// Breakpoints without an ID of their own, such as the stepping ones, are only listed
+	// among the physical breakpoints
+	if includeInternal {
+		physical, err := c.client.ListBreakpoints(true)
+		if err != nil {
+			logger.Debug("Warning: Failed to list internal breakpoints: %v", err)
+		}
+		for _, bp := range physical {
+			if bp.ID == 0 {
+				c.resolveInternalBreakpoint(bp)
+				bps = append(bps, bp)
+			}
+		}
+	}
+
+	sort.SliceStable(bps, func(i, j int) bool { return bps[i].ID < bps[j].ID })
+
 	var breakpoints []types.Breakpoint
 	for _, bp := range bps {
-		breakpoints = append(breakpoints, types.Breakpoint{
-			DelveBreakpoint: bp,
-			ID:              bp.ID,
-			Status:          getBreakpointStatus(bp),
-			Location:        getBreakpointLocation(bp),
-			Condition:       bp.Cond,
-			HitCount:        uint64(bp.TotalHitCount),
-		})
+		if bp.ID <= 0 && !includeInternal {
+			continue
+		}
+		breakpoint := convertBreakpoint(bp)
+		c.annotateBreakpoint(&breakpoint)
+		breakpoints = append(breakpoints, breakpoint)
 	}
 
 	// Get current state for context
@@ -119,7 +388,8 @@ func (c *Client) ListBreakpoints() types.BreakpointListResponse {
 	}
 }
 
-// RemoveBreakpoint removes a breakpoint by its ID
+// RemoveBreakpoint removes a breakpoint by its ID. Delve's internal breakpoints cannot be
+// removed.
 func (c *Client) RemoveBreakpoint(id int) types.BreakpointResponse {
 	if c.client == nil {
 		return types.BreakpointResponse{
@@ -131,6 +401,16 @@ func (c *Client) RemoveBreakpoint(id int) types.BreakpointResponse {
 		}
 	}
 
+	if id <= 0 {
+		return types.BreakpointResponse{
+			Status: "error",
+			Context: types.DebugContext{
+				ErrorMessage: fmt.Sprintf("breakpoint %d is internal to Delve and cannot be removed; break_on_panic turns the panic breakpoints off", id),
+				Timestamp:    getCurrentTimestamp(),
+			},
+		}
+	}
+
 	// Get breakpoint info before removing
 	bps, err := c.client.ListBreakpoints(false)
 	if err != nil {
@@ -179,14 +459,10 @@ func (c *Client) RemoveBreakpoint(id int) types.BreakpointResponse {
 		logger.Debug("Warning: Failed to get state after removing breakpoint: %v", err)
 	}
 
-	breakpoint := types.Breakpoint{
-		DelveBreakpoint: targetBp,
-		ID:              targetBp.ID,
-		Status:          "removed",
-		Location:        getBreakpointLocation(targetBp),
-		Condition:       targetBp.Cond,
-		HitCount:        uint64(targetBp.TotalHitCount),
-	}
+	breakpoint := convertBreakpoint(targetBp)
+	c.annotateBreakpoint(&breakpoint)
+	breakpoint.Status = "removed"
+	c.forgetBreakpoint(id)
 
 	context := c.createDebugContext(state)
 	context.Operation = "remove_breakpoint"
@@ -198,6 +474,500 @@ func (c *Client) RemoveBreakpoint(id int) types.BreakpointResponse {
 	}
 }
 
+// forgetBreakpoint drops what is kept about a breakpoint on this side, once it is cleared
+func (c *Client) forgetBreakpoint(id int) {
+	delete(c.labelFilters, id)
+	delete(c.ignoreCounts, id)
+	delete(c.goroutinePins, id)
+	c.forgetArmedBreakpoint(id)
+	delete(c.returnBreakpoints, id)
+	delete(c.errorBreakpoints, id)
+	delete(c.captureHistories, id)
+	c.hitTimes.forget(id)
+}
+
+// ResetHitCount sets the hit counts of a breakpoint back to zero, re-arming breakpoints
+// with a hit-count condition or an ignore count. Delve cannot reset hit counts in place, so the breakpoint is
+// re-created with the same location and settings, which gives it a new ID.
+func (c *Client) ResetHitCount(id int) types.ResetHitCountResponse {
+	if c.client == nil {
+		return c.createResetHitCountResponse(nil, id, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createResetHitCountResponse(nil, id, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createResetHitCountResponse(nil, id, nil, fmt.Errorf("cannot reset hit counts while the target is running; stop the target first"))
+	}
+
+	if id <= 0 {
+		return c.createResetHitCountResponse(state, id, nil, fmt.Errorf("breakpoint %d is internal to Delve and its hit counts cannot be reset", id))
+	}
+
+	bp, err := c.client.GetBreakpoint(id)
+	if err != nil {
+		return c.createResetHitCountResponse(state, id, nil, fmt.Errorf("breakpoint %d not found: %v", id, err))
+	}
+
+	// Watchpoints are bound to a stack frame that may no longer exist
+	if bp.WatchExpr != "" {
+		return c.createResetHitCountResponse(state, id, nil, fmt.Errorf("cannot reset the hit counts of watchpoint %d", id))
+	}
+
+	spec := breakpointSpec(bp)
+	returnOf := c.returnBreakpointFunction(id)
+	if returnOf != "" {
+		if err := c.atReturns(spec, returnOf); err != nil {
+			return c.createResetHitCountResponse(state, id, nil, err)
+		}
+	}
+
+	logger.Debug("Resetting hit counts of breakpoint %d at %s:%d, hit %d times", id, bp.File, bp.Line, bp.TotalHitCount)
+	if _, err := c.client.ClearBreakpoint(id); err != nil {
+		return c.createResetHitCountResponse(state, id, nil, fmt.Errorf("failed to clear breakpoint %d: %v", id, err))
+	}
+
+	newBP, err := c.client.CreateBreakpoint(spec)
+	if err != nil {
+		return c.createResetHitCountResponse(state, id, nil, fmt.Errorf("breakpoint %d was removed but could not be re-created: %v", id, err))
+	}
+	if returnOf != "" {
+		delete(c.returnBreakpoints, id)
+		c.setReturnBreakpoint(newBP, returnOf)
+	}
+	if eb := c.errorBreakpoints[id]; eb != nil {
+		delete(c.errorBreakpoints, id)
+		c.setErrorBreakpoint(newBP.ID, eb.match, eb.re)
+	}
+
+	if bp.Disabled {
+		newBP.Disabled = true
+		if err := c.client.AmendBreakpoint(newBP); err != nil {
+			logger.Debug("Warning: Failed to disable re-created breakpoint %d: %v", newBP.ID, err)
+		}
+	}
+
+	// The label filter moves to the new ID, with its skipped hits reset like the hit counts
+	if filter := c.labelFilters[id]; filter != nil {
+		delete(c.labelFilters, id)
+		c.setLabelFilter(newBP.ID, filter.label)
+	}
+
+	// So does the ignore count, re-armed to ignore as many hits as when it was set
+	if ignore := c.ignoreCounts[id]; ignore != nil {
+		delete(c.ignoreCounts, id)
+		c.setIgnoreCount(newBP.ID, ignore.count)
+	}
+
+	// The pin too, staying on the goroutine it pinned, whose condition the new one has
+	if pin := c.goroutinePins[id]; pin != nil {
+		delete(c.goroutinePins, id)
+		c.goroutinePins[newBP.ID] = pin
+	}
+
+	// Arming goes along, whether the breakpoint is armed by another or arms others
+	c.moveArmedBreakpoint(id, newBP.ID)
+
+	// Captured values are compared with the last hit before the reset
+	if history := c.captureHistories[id]; history != nil {
+		delete(c.captureHistories, id)
+		c.captureHistories[newBP.ID] = history
+	}
+
+	// And hits are timed from the last one before the reset
+	c.hitTimes.move(id, newBP.ID)
+
+	breakpoint := convertBreakpoint(newBP)
+	c.annotateBreakpoint(&breakpoint)
+	return c.createResetHitCountResponse(state, id, &breakpoint, nil)
+}
+
+// EnableBreakpoint makes a disabled breakpoint stop the program again
+func (c *Client) EnableBreakpoint(id int) types.BreakpointResponse {
+	return c.setBreakpointEnabled(id, true)
+}
+
+// DisableBreakpoint keeps a breakpoint from stopping the program, without losing its
+// conditions and capture expressions, until it is enabled again
+func (c *Client) DisableBreakpoint(id int) types.BreakpointResponse {
+	return c.setBreakpointEnabled(id, false)
+}
+
+// setBreakpointEnabled enables or disables a breakpoint in place, keeping its ID and hit counts
+func (c *Client) setBreakpointEnabled(id int, enabled bool) types.BreakpointResponse {
+	operation := "disable_breakpoint"
+	if enabled {
+		operation = "enable_breakpoint"
+	}
+
+	if c.client == nil {
+		return c.createBreakpointResponse(nil, operation, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createBreakpointResponse(nil, operation, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createBreakpointResponse(nil, operation, nil, fmt.Errorf("cannot change a breakpoint while the target is running; stop the target first"))
+	}
+
+	if id <= 0 {
+		return c.createBreakpointResponse(state, operation, nil, fmt.Errorf("breakpoint %d is internal to Delve; use break_on_panic to change it", id))
+	}
+
+	bp, err := c.client.GetBreakpoint(id)
+	if err != nil {
+		return c.createBreakpointResponse(state, operation, nil, fmt.Errorf("breakpoint %d not found: %v", id, err))
+	}
+
+	if bp.Disabled == !enabled {
+		logger.Debug("Breakpoint %d is already %s", id, getBreakpointStatus(bp))
+	} else {
+		logger.Debug("Setting breakpoint %d at %s:%d enabled to %v", id, bp.File, bp.Line, enabled)
+		bp.Disabled = !enabled
+		if err := c.client.AmendBreakpoint(bp); err != nil {
+			return c.createBreakpointResponse(state, operation, nil, fmt.Errorf("failed to change breakpoint %d: %v", id, err))
+		}
+	}
+
+	updated, err := c.client.GetBreakpoint(id)
+	if err != nil {
+		logger.Debug("Warning: Failed to read breakpoint %d after changing it: %v", id, err)
+		updated = bp
+	}
+
+	breakpoint := convertBreakpoint(updated)
+	c.annotateBreakpoint(&breakpoint)
+	return c.createBreakpointResponse(state, operation, &breakpoint, nil)
+}
+
+// AmendBreakpointCondition replaces the condition of a breakpoint in place, or clears it
+// when condition is empty. Unlike re-creating the breakpoint, this keeps its ID, hit counts
+// and other settings.
+func (c *Client) AmendBreakpointCondition(id int, condition string) types.BreakpointResponse {
+	const operation = "amend_breakpoint_condition"
+
+	if c.client == nil {
+		return c.createBreakpointResponse(nil, operation, nil, fmt.Errorf("no active debug session"))
+	}
+
+	if condition != "" {
+		if err := validateCondition(condition); err != nil {
+			return c.createBreakpointResponse(nil, operation, nil, err)
+		}
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createBreakpointResponse(nil, operation, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createBreakpointResponse(nil, operation, nil, fmt.Errorf("cannot change a breakpoint while the target is running; stop the target first"))
+	}
+
+	if id <= 0 {
+		return c.createBreakpointResponse(state, operation, nil, fmt.Errorf("breakpoint %d is internal to Delve and its condition cannot be changed", id))
+	}
+
+	bp, err := c.client.GetBreakpoint(id)
+	if err != nil {
+		return c.createBreakpointResponse(state, operation, nil, fmt.Errorf("breakpoint %d not found: %v; existing breakpoints: %s", id, err, c.breakpointIDs()))
+	}
+
+	previous := bp.Cond
+	logger.Debug("Changing the condition of breakpoint %d at %s:%d from %q to %q", id, bp.File, bp.Line, previous, condition)
+	bp.Cond = condition
+	// A pinned breakpoint stays on its goroutine
+	if pin := c.goroutinePins[id]; pin != nil {
+		pin.condition = condition
+		if pin.goroutineID != 0 {
+			bp.Cond = pinnedCondition(condition, pin.goroutineID)
+		}
+	}
+	if err := c.client.AmendBreakpoint(bp); err != nil {
+		return c.createBreakpointResponse(state, operation, nil, fmt.Errorf("failed to change the condition of breakpoint %d: %v", id, err))
+	}
+
+	updated, err := c.client.GetBreakpoint(id)
+	if err != nil {
+		logger.Debug("Warning: Failed to read breakpoint %d after changing it: %v", id, err)
+		updated = bp
+	}
+
+	breakpoint := convertBreakpoint(updated)
+	c.annotateBreakpoint(&breakpoint)
+	response := c.createBreakpointResponse(state, operation, &breakpoint, nil)
+	response.PreviousCondition = previous
+	return response
+}
+
+// breakpointIDs lists the IDs of the user's breakpoints, for errors naming the ones that exist
+func (c *Client) breakpointIDs() string {
+	bps, err := c.client.ListBreakpoints(false)
+	if err != nil {
+		return "unknown"
+	}
+	ids := make([]int, 0, len(bps))
+	for _, bp := range bps {
+		if bp.ID > 0 {
+			ids = append(ids, bp.ID)
+		}
+	}
+	sort.Ints(ids)
+	if len(ids) == 0 {
+		return "none"
+	}
+	return fmt.Sprint(ids)
+}
+
+// SetWatchpoint sets a data breakpoint that stops when the expression's memory is accessed.
+// watchType is one of "read", "write" or "readwrite".
+func (c *Client) SetWatchpoint(expr string, watchType string) types.BreakpointResponse {
+	if c.client == nil {
+		return types.BreakpointResponse{
+			Status: "error",
+			Context: types.DebugContext{
+				ErrorMessage: "no active debug session",
+				Timestamp:    getCurrentTimestamp(),
+			},
+		}
+	}
+
+	wtype, err := parseWatchType(watchType)
+	if err != nil {
+		return types.BreakpointResponse{
+			Status: "error",
+			Context: types.DebugContext{
+				ErrorMessage: err.Error(),
+				Timestamp:    getCurrentTimestamp(),
+			},
+		}
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		return types.BreakpointResponse{
+			Status: "error",
+			Context: types.DebugContext{
+				ErrorMessage: fmt.Sprintf("failed to get state: %v", err),
+				Timestamp:    getCurrentTimestamp(),
+			},
+		}
+	}
+
+	// Watchpoints are resolved in the scope of the selected goroutine's current frame
+	scope := api.EvalScope{GoroutineID: -1}
+	if state.SelectedGoroutine != nil {
+		scope.GoroutineID = state.SelectedGoroutine.ID
+	}
+
+	logger.Debug("Setting %s watchpoint on %s", watchType, expr)
+	bp, err := c.client.CreateWatchpoint(scope, expr, wtype)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to set watchpoint on %s: %v", expr, err)
+		if strings.Contains(err.Error(), "hardware breakpoints exhausted") {
+			errMsg = fmt.Sprintf("failed to set watchpoint on %s: all hardware watchpoint slots are in use, remove an existing watchpoint first", expr)
+		} else if strings.Contains(err.Error(), "hardware breakpoints not implemented") {
+			errMsg = fmt.Sprintf("failed to set watchpoint on %s: watchpoints are not supported by this backend", expr)
+		}
+		return types.BreakpointResponse{
+			Status: "error",
+			Context: types.DebugContext{
+				ErrorMessage: errMsg,
+				Timestamp:    getCurrentTimestamp(),
+			},
+		}
+	}
+
+	context := c.createDebugContext(state)
+	context.Operation = "set_watchpoint"
+
+	return types.BreakpointResponse{
+		Status:     "success",
+		Context:    context,
+		Breakpoint: convertBreakpoint(bp),
+	}
+}
+
+// parseWatchType converts a human-readable watch type to Delve's representation
+func parseWatchType(watchType string) (api.WatchType, error) {
+	switch strings.ToLower(watchType) {
+	case "read":
+		return api.WatchRead, nil
+	case "", "write":
+		return api.WatchWrite, nil
+	case "readwrite", "read-write", "rw":
+		return api.WatchRead | api.WatchWrite, nil
+	default:
+		return 0, fmt.Errorf("invalid watch type %q, expected read, write or readwrite", watchType)
+	}
+}
+
+// getCapturedValues renders the capture expressions Delve evaluated for the breakpoint a
+// thread stopped at, keyed by expression, separating the ones that failed to evaluate
+func getCapturedValues(th *api.Thread) (map[string]string, map[string]string) {
+	if th == nil || th.Breakpoint == nil || th.Breakpoint.Tracepoint || th.BreakpointInfo == nil {
+		return nil, nil
+	}
+
+	var captured, failed map[string]string
+	for i := range th.BreakpointInfo.Variables {
+		v := &th.BreakpointInfo.Variables[i]
+		expr := v.Name
+		if i < len(th.Breakpoint.Variables) {
+			expr = th.Breakpoint.Variables[i]
+		}
+
+		if v.Unreadable != "" {
+			if failed == nil {
+				failed = make(map[string]string)
+			}
+			failed[expr] = v.Unreadable
+			continue
+		}
+		if captured == nil {
+			captured = make(map[string]string)
+		}
+		captured[expr] = formatVariableValue(v)
+	}
+	return captured, failed
+}
+
+// convertBreakpoint converts a Delve breakpoint to our type
+func convertBreakpoint(bp *api.Breakpoint) types.Breakpoint {
+	breakpoint := types.Breakpoint{
+		DelveBreakpoint: bp,
+		ID:              bp.ID,
+		Status:          getBreakpointStatus(bp),
+		Location:        getBreakpointLocation(bp),
+		Position:        getBreakpointPosition(bp),
+		Condition:       bp.Cond,
+		HitCondition:    bp.HitCond,
+		HitCount:        bp.TotalHitCount,
+		Variables:       bp.Variables,
+		Tracepoint:      bp.Tracepoint,
+		Disabled:        bp.Disabled,
+		Internal:        bp.ID <= 0,
+		Kind:            breakpointKind(bp),
+	}
+	if breakpoint.Internal {
+		breakpoint.Purpose = internalBreakpointPurposes[breakpoint.Kind]
+	}
+
+	if len(bp.HitCount) > 0 {
+		breakpoint.GoroutineHits = make(map[string]uint64, len(bp.HitCount))
+		for goroutineID, count := range bp.HitCount {
+			breakpoint.GoroutineHits[goroutineID] = count
+		}
+	}
+
+	if bp.WatchExpr != "" {
+		breakpoint.Watchpoint = true
+		breakpoint.WatchExpr = bp.WatchExpr
+		breakpoint.WatchType = getWatchTypeName(bp.WatchType)
+	}
+
+	return breakpoint
+}
+
+// annotateBreakpoint adds the settings the client keeps for a breakpoint itself, as Delve
+// has no place for them
+func (c *Client) annotateBreakpoint(breakpoint *types.Breakpoint) {
+	c.annotateLabelFilter(breakpoint)
+	c.annotateIgnoreCount(breakpoint)
+	c.annotateGoroutinePin(breakpoint)
+	c.annotateArmedBreakpoint(breakpoint)
+	c.annotateReturnBreakpoint(breakpoint)
+	c.annotateErrorBreakpoint(breakpoint)
+}
+
+// createResetHitCountResponse creates a ResetHitCountResponse
+func (c *Client) createResetHitCountResponse(state *api.DebuggerState, previousID int, breakpoint *types.Breakpoint, err error) types.ResetHitCountResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "reset_hit_count"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.ResetHitCountResponse{
+			Status:     "error",
+			Context:    context,
+			PreviousID: previousID,
+		}
+	}
+
+	return types.ResetHitCountResponse{
+		Status:     "success",
+		Context:    context,
+		PreviousID: previousID,
+		Breakpoint: *breakpoint,
+	}
+}
+
+// createBreakpointResponse creates a BreakpointResponse
+func (c *Client) createBreakpointResponse(state *api.DebuggerState, operation string, breakpoint *types.Breakpoint, err error) types.BreakpointResponse {
+	context := c.createDebugContext(state)
+	context.Operation = operation
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.BreakpointResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	return types.BreakpointResponse{
+		Status:     "success",
+		Context:    context,
+		Breakpoint: *breakpoint,
+	}
+}
+
+// createSetBreakpointsResponse creates a SetBreakpointsResponse. The status is "partial"
+// when only some of the breakpoints could be set, and "error" when none could.
+func (c *Client) createSetBreakpointsResponse(state *api.DebuggerState, results []types.BatchBreakpointResult, err error) types.SetBreakpointsResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "set_breakpoints"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.SetBreakpointsResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	response := types.SetBreakpointsResponse{
+		Context: context,
+		Results: results,
+	}
+	for _, result := range results {
+		if result.Status == "success" {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+
+	switch {
+	case response.Failed == 0:
+		response.Status = "success"
+	case response.Succeeded == 0:
+		response.Status = "error"
+		context.ErrorMessage = fmt.Sprintf("none of the %d breakpoints could be set", len(results))
+		response.Context = context
+	default:
+		response.Status = "partial"
+	}
+	return response
+}
+
 func getCurrentTimestamp() time.Time {
 	return time.Now()
 }
@@ -0,0 +1,144 @@
+package debugger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestGetCapturedValues(t *testing.T) {
+	testCases := []struct {
+		name             string
+		thread           *api.Thread
+		expectedCaptured map[string]string
+		expectedFailed   map[string]string
+	}{
+		{
+			name: "Values and failures",
+			thread: &api.Thread{
+				Breakpoint: &api.Breakpoint{ID: 1, Variables: []string{"r.URL.Path", "count", "missing"}},
+				BreakpointInfo: &api.BreakpointInfo{Variables: []api.Variable{
+					{Name: "r.URL.Path", Kind: reflect.String, Value: "/hello", Len: 6},
+					{Name: "count", Kind: reflect.Int, Value: "3"},
+					{Name: "missing", Unreadable: `eval error: could not find symbol value for missing`},
+				}},
+			},
+			expectedCaptured: map[string]string{"r.URL.Path": "/hello", "count": "3"},
+			expectedFailed:   map[string]string{"missing": "eval error: could not find symbol value for missing"},
+		},
+		{
+			name: "Tracepoint",
+			thread: &api.Thread{
+				Breakpoint:     &api.Breakpoint{ID: 2, Tracepoint: true, Variables: []string{"count"}},
+				BreakpointInfo: &api.BreakpointInfo{Variables: []api.Variable{{Name: "count", Kind: reflect.Int, Value: "3"}}},
+			},
+		},
+		{
+			name:   "Not at a breakpoint",
+			thread: &api.Thread{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			captured, failed := getCapturedValues(tc.thread)
+			if !reflect.DeepEqual(captured, tc.expectedCaptured) {
+				t.Errorf("Expected captured %v, got %v", tc.expectedCaptured, captured)
+			}
+			if !reflect.DeepEqual(failed, tc.expectedFailed) {
+				t.Errorf("Expected failed %v, got %v", tc.expectedFailed, failed)
+			}
+		})
+	}
+}
+
+func TestCreateSetBreakpointsResponse(t *testing.T) {
+	success := types.BatchBreakpointResult{Status: "success"}
+	failure := types.BatchBreakpointResult{Status: "error", Error: "could not find file"}
+
+	testCases := []struct {
+		name      string
+		results   []types.BatchBreakpointResult
+		expected  string
+		succeeded int
+		failed    int
+	}{
+		{name: "All set", results: []types.BatchBreakpointResult{success, success}, expected: "success", succeeded: 2},
+		{name: "Some set", results: []types.BatchBreakpointResult{success, failure, success}, expected: "partial", succeeded: 2, failed: 1},
+		{name: "None set", results: []types.BatchBreakpointResult{failure}, expected: "error", failed: 1},
+	}
+
+	c := NewClient()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.createSetBreakpointsResponse(nil, tc.results, nil)
+			if response.Status != tc.expected {
+				t.Errorf("Expected status %s, got %s", tc.expected, response.Status)
+			}
+			if response.Succeeded != tc.succeeded || response.Failed != tc.failed {
+				t.Errorf("Expected %d set and %d failed, got %d and %d", tc.succeeded, tc.failed, response.Succeeded, response.Failed)
+			}
+		})
+	}
+}
+
+func TestAmendBreakpointCondition(t *testing.T) {
+	bps := &fakeBreakpoints{}
+	bps.add(&api.Breakpoint{ID: 1, File: "main.go", Line: 10, Cond: "i > 3", TotalHitCount: 4, HitCount: map[string]uint64{"1": 4}})
+	bps.add(&api.Breakpoint{ID: 2, File: "main.go", Line: 20})
+	c, f := newFakeDelve(t, bps.serve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+	}))
+	c.setGoroutinePin(2, "")
+	c.goroutinePins[2].goroutineID = 5
+
+	// The breakpoint is changed in place, keeping its ID and hits
+	response := c.AmendBreakpointCondition(1, "i > 10")
+	if response.Status != "success" || response.PreviousCondition != "i > 3" {
+		t.Fatalf("Expected the condition i > 3 to be replaced, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if bp := bps.get(1); bp.Cond != "i > 10" || bp.TotalHitCount != 4 {
+		t.Errorf("Expected breakpoint 1 to keep its 4 hits under the new condition, got %+v", bp)
+	}
+	if response.Breakpoint.ID != 1 || response.Breakpoint.Condition != "i > 10" {
+		t.Errorf("Expected breakpoint 1 with the new condition, got %+v", response.Breakpoint)
+	}
+
+	if response := c.AmendBreakpointCondition(1, ""); response.Status != "success" || bps.get(1).Cond != "" {
+		t.Errorf("Expected an empty condition to clear it, got %q", bps.get(1).Cond)
+	}
+
+	// A pinned breakpoint stays on its goroutine
+	if response := c.AmendBreakpointCondition(2, "n == 1"); response.Status != "success" {
+		t.Fatalf("Expected the condition of breakpoint 2 to change, got %s", response.Context.ErrorMessage)
+	}
+	if cond := bps.get(2).Cond; cond != "(n == 1) && runtime.curg.goid == 5" {
+		t.Errorf("Expected the new condition pinned to goroutine 5, got %q", cond)
+	}
+
+	amends := f.called("AmendBreakpoint")
+	testCases := []struct {
+		name      string
+		id        int
+		condition string
+		expected  string
+	}{
+		{name: "Invalid condition", id: 1, condition: "i >", expected: `invalid condition "i >"`},
+		{name: "Internal breakpoint", id: -1, condition: "i > 1", expected: "internal to Delve"},
+		{name: "Unknown breakpoint", id: 7, condition: "i > 1", expected: "existing breakpoints: [1 2]"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.AmendBreakpointCondition(tc.id, tc.condition)
+			if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, tc.expected) {
+				t.Errorf("Expected an error containing %q, got %s: %s", tc.expected, response.Status, response.Context.ErrorMessage)
+			}
+		})
+	}
+	if f.called("AmendBreakpoint") != amends {
+		t.Errorf("Expected no breakpoint changed by a refused amend")
+	}
+}
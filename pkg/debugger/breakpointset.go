@@ -0,0 +1,306 @@
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// breakpointSetVersion is the version of the format ExportBreakpoints writes
+const breakpointSetVersion = 1
+
+// ExportBreakpoints serializes the user breakpoints of the session to JSON, with their
+// conditions, capture expressions, goroutine labels, ignore counts and enabled state, so
+// ImportBreakpoints can set them up again in a later session. Watchpoints are left out, as
+// they are bound to a stack frame of the current process.
+func (c *Client) ExportBreakpoints() ([]byte, error) {
+	set, err := c.breakpointSet()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(set, "", "  ")
+}
+
+// SaveBreakpoints exports the breakpoint set and writes it to path, or returns it in the
+// response when path is empty
+func (c *Client) SaveBreakpoints(path string) types.ExportBreakpointsResponse {
+	set, err := c.breakpointSet()
+	if err != nil {
+		return c.createExportBreakpointsResponse(nil, path, 0, nil, err)
+	}
+
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return c.createExportBreakpointsResponse(nil, path, 0, nil, fmt.Errorf("failed to encode breakpoints: %v", err))
+	}
+
+	if path != "" {
+		logger.Debug("Saving %d breakpoints to %s", len(set.Breakpoints), path)
+		if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			return c.createExportBreakpointsResponse(nil, path, 0, nil, fmt.Errorf("failed to write breakpoints to %s: %v", path, err))
+		}
+		data = nil
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		logger.Debug("Warning: Failed to get state after saving breakpoints: %v", err)
+	}
+
+	return c.createExportBreakpointsResponse(state, path, len(set.Breakpoints), data, nil)
+}
+
+// breakpointSet collects the user breakpoints of the session, sorted by ID
+func (c *Client) breakpointSet() (*types.BreakpointSet, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("no active debug session")
+	}
+
+	bps, err := c.client.ListBreakpoints(false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list breakpoints: %v", err)
+	}
+	sort.Slice(bps, func(i, j int) bool { return bps[i].ID < bps[j].ID })
+
+	set := &types.BreakpointSet{
+		Version:     breakpointSetVersion,
+		Program:     c.target,
+		Breakpoints: []types.SavedBreakpoint{},
+	}
+	for _, bp := range bps {
+		// Like on restart, leave out Delve's own breakpoints and ones that only make sense
+		// in this run
+		if bp.ID <= 0 || c.tempBreakpoints[bp.ID] || bp.ID == c.panicBreakpoint || bp.WatchExpr != "" {
+			continue
+		}
+
+		saved := types.SavedBreakpoint{
+			ID:           bp.ID,
+			File:         bp.File,
+			Line:         bp.Line,
+			Function:     getFunctionNameFromBreakpoint(bp),
+			Condition:    bp.Cond,
+			HitCondition: bp.HitCond,
+			CaptureExprs: bp.Variables,
+			Tracepoint:   bp.Tracepoint,
+			Disabled:     bp.Disabled,
+		}
+		if filter := c.labelFilters[bp.ID]; filter != nil {
+			saved.GoroutineLabel = filter.label
+		}
+		if ignore := c.ignoreCounts[bp.ID]; ignore != nil {
+			saved.IgnoreCount = ignore.count
+		}
+		if pin := c.goroutinePins[bp.ID]; pin != nil {
+			saved.Condition = pin.condition
+			saved.PinToFirstGoroutine = true
+		}
+		if function := c.returnBreakpointFunction(bp.ID); function != "" {
+			saved.Function = function
+			saved.OnReturn = true
+		}
+		if eb := c.errorBreakpoints[bp.ID]; eb != nil {
+			saved.OnError = true
+			saved.ErrorMatch = eb.match
+		}
+		set.Breakpoints = append(set.Breakpoints, saved)
+	}
+	return set, nil
+}
+
+// ImportBreakpoints sets up the breakpoints of a set written by ExportBreakpoints,
+// resolving each location against the current binary. A breakpoint whose line no longer
+// exists, or now belongs to a different function because the code moved, is not set and
+// is reported as failed with the reason; the others are set regardless.
+func (c *Client) ImportBreakpoints(data []byte) types.ImportBreakpointsResponse {
+	if c.client == nil {
+		return c.createImportBreakpointsResponse(nil, nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	var set types.BreakpointSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return c.createImportBreakpointsResponse(nil, nil, nil, fmt.Errorf("invalid breakpoint set: %v", err))
+	}
+	if set.Version != breakpointSetVersion {
+		return c.createImportBreakpointsResponse(nil, nil, nil, fmt.Errorf("unsupported breakpoint set version %d, expected %d", set.Version, breakpointSetVersion))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createImportBreakpointsResponse(nil, nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createImportBreakpointsResponse(nil, nil, nil, fmt.Errorf("cannot set breakpoints while the target is running; stop the target first"))
+	}
+
+	logger.Debug("Importing %d breakpoints", len(set.Breakpoints))
+
+	var restored []types.RestoredBreakpoint
+	var failed []types.FailedImport
+	for _, saved := range set.Breakpoints {
+		breakpoint, err := c.importBreakpoint(saved)
+		if err != nil {
+			failed = append(failed, types.FailedImport{Saved: saved, Reason: err.Error()})
+			continue
+		}
+		restored = append(restored, types.RestoredBreakpoint{PreviousID: saved.ID, Breakpoint: breakpoint})
+	}
+
+	return c.createImportBreakpointsResponse(state, restored, failed, nil)
+}
+
+// LoadBreakpoints imports the breakpoint set saved in a file by SaveBreakpoints
+func (c *Client) LoadBreakpoints(path string) types.ImportBreakpointsResponse {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c.createImportBreakpointsResponse(nil, nil, nil, fmt.Errorf("failed to read breakpoints from %s: %v", path, err))
+	}
+	return c.ImportBreakpoints(data)
+}
+
+// importBreakpoint sets one saved breakpoint, checking that its line is still in the
+// function it was in when it was saved. One saved with OnReturn is set at the returns of
+// its function, wherever they are now.
+func (c *Client) importBreakpoint(saved types.SavedBreakpoint) (types.Breakpoint, error) {
+	if saved.OnReturn && saved.Function == "" {
+		return types.Breakpoint{}, fmt.Errorf("saved return breakpoint has no function")
+	}
+	if saved.OnError && !saved.OnReturn {
+		return types.Breakpoint{}, fmt.Errorf("saved break-on-error breakpoint is not on the returns of a function")
+	}
+	var errorRe *regexp.Regexp
+	if saved.ErrorMatch != "" {
+		var err error
+		if errorRe, err = regexp.Compile(saved.ErrorMatch); err != nil {
+			return types.Breakpoint{}, fmt.Errorf("invalid error match %q: %v", saved.ErrorMatch, err)
+		}
+	}
+	if !saved.OnReturn && (saved.File == "" || saved.Line <= 0) {
+		return types.Breakpoint{}, fmt.Errorf("saved breakpoint has no file and line")
+	}
+
+	goroutineLabel := saved.GoroutineLabel
+	if goroutineLabel != "" {
+		var err error
+		if goroutineLabel, err = parseGoroutineLabel(goroutineLabel); err != nil {
+			return types.Breakpoint{}, err
+		}
+	}
+
+	request := &api.Breakpoint{
+		File:       saved.File,
+		Line:       saved.Line,
+		Cond:       saved.Condition,
+		HitCond:    saved.HitCondition,
+		Variables:  saved.CaptureExprs,
+		Tracepoint: saved.Tracepoint,
+	}
+	if saved.OnReturn {
+		if err := c.atReturns(request, saved.Function); err != nil {
+			return types.Breakpoint{}, err
+		}
+	}
+
+	bp, err := c.client.CreateBreakpoint(request)
+	if err != nil {
+		if saved.OnReturn {
+			return types.Breakpoint{}, fmt.Errorf("cannot set a breakpoint at the returns of %s: %v", saved.Function, err)
+		}
+		return types.Breakpoint{}, fmt.Errorf("cannot resolve %s:%d: %v", saved.File, saved.Line, err)
+	}
+
+	if function := getFunctionNameFromBreakpoint(bp); saved.Function != "" && function != saved.Function {
+		if _, err := c.client.ClearBreakpoint(bp.ID); err != nil {
+			logger.Debug("Warning: Failed to clear breakpoint %d at a moved location: %v", bp.ID, err)
+		}
+		return types.Breakpoint{}, fmt.Errorf("location moved: %s:%d is now in %s, not %s", saved.File, saved.Line, function, saved.Function)
+	}
+
+	if saved.Disabled {
+		bp.Disabled = true
+		if err := c.client.AmendBreakpoint(bp); err != nil {
+			logger.Debug("Warning: Failed to disable imported breakpoint %d: %v", bp.ID, err)
+		}
+	}
+
+	if goroutineLabel != "" {
+		c.setLabelFilter(bp.ID, goroutineLabel)
+	}
+	if saved.IgnoreCount > 0 {
+		c.setIgnoreCount(bp.ID, saved.IgnoreCount)
+	}
+	if saved.PinToFirstGoroutine {
+		c.setGoroutinePin(bp.ID, saved.Condition)
+	}
+	if saved.OnReturn {
+		c.setReturnBreakpoint(bp, saved.Function)
+	}
+	if saved.OnError {
+		c.setErrorBreakpoint(bp.ID, saved.ErrorMatch, errorRe)
+	}
+
+	breakpoint := convertBreakpoint(bp)
+	c.annotateBreakpoint(&breakpoint)
+	return breakpoint, nil
+}
+
+// createExportBreakpointsResponse creates an ExportBreakpointsResponse
+func (c *Client) createExportBreakpointsResponse(state *api.DebuggerState, path string, count int, data []byte, err error) types.ExportBreakpointsResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "export_breakpoints"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.ExportBreakpointsResponse{
+			Status:  "error",
+			Context: context,
+			File:    path,
+		}
+	}
+
+	return types.ExportBreakpointsResponse{
+		Status:  "success",
+		Context: context,
+		Count:   count,
+		File:    path,
+		Data:    string(data),
+	}
+}
+
+// createImportBreakpointsResponse creates an ImportBreakpointsResponse. Like for a batch
+// of breakpoints, the status is "partial" when only some of them could be set.
+func (c *Client) createImportBreakpointsResponse(state *api.DebuggerState, restored []types.RestoredBreakpoint, failed []types.FailedImport, err error) types.ImportBreakpointsResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "import_breakpoints"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.ImportBreakpointsResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	status := "success"
+	switch {
+	case len(failed) > 0 && len(restored) == 0:
+		status = "error"
+		context.ErrorMessage = fmt.Sprintf("none of the %d breakpoints could be restored", len(failed))
+	case len(failed) > 0:
+		status = "partial"
+	}
+
+	return types.ImportBreakpointsResponse{
+		Status:   status,
+		Context:  context,
+		Restored: restored,
+		Failed:   failed,
+	}
+}
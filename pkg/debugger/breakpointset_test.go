@@ -0,0 +1,104 @@
+package debugger
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestCreateImportBreakpointsResponse(t *testing.T) {
+	restored := types.RestoredBreakpoint{PreviousID: 1}
+	failed := types.FailedImport{Reason: "location moved"}
+
+	testCases := []struct {
+		name     string
+		restored []types.RestoredBreakpoint
+		failed   []types.FailedImport
+		expected string
+	}{
+		{name: "All restored", restored: []types.RestoredBreakpoint{restored, restored}, expected: "success"},
+		{name: "Some restored", restored: []types.RestoredBreakpoint{restored}, failed: []types.FailedImport{failed}, expected: "partial"},
+		{name: "None restored", failed: []types.FailedImport{failed, failed}, expected: "error"},
+		{name: "Empty set", expected: "success"},
+	}
+
+	c := NewClient()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.createImportBreakpointsResponse(nil, tc.restored, tc.failed, nil)
+			if response.Status != tc.expected {
+				t.Errorf("Expected status %s, got %s", tc.expected, response.Status)
+			}
+		})
+	}
+}
+
+func TestExportImportBreakpoints(t *testing.T) {
+	// The exporting session has a user breakpoint and tracepoint, a temporary breakpoint
+	// and Delve's panic breakpoint
+	exported := &fakeBreakpoints{}
+	exported.add(&api.Breakpoint{ID: -1, Name: "unrecovered-panic", FunctionName: "runtime.fatalpanic"})
+	exported.add(&api.Breakpoint{ID: 1, File: "main.go", Line: 10, FunctionName: "main.handle", Cond: "n > 1", Variables: []string{"n"}, Disabled: true})
+	exported.add(&api.Breakpoint{ID: 2, File: "main.go", Line: 20, FunctionName: "main.serve", Tracepoint: true})
+	exported.add(&api.Breakpoint{ID: 3, File: "main.go", Line: 30, FunctionName: "main.main"})
+	c, _ := newFakeDelve(t, exported.serve(t, map[string]fakeHandler{}))
+	c.tempBreakpoints = map[int]bool{3: true}
+	c.setLabelFilter(1, "user=alice")
+	c.setIgnoreCount(2, 5)
+
+	data, err := c.ExportBreakpoints()
+	if err != nil {
+		t.Fatalf("Failed to export breakpoints: %v", err)
+	}
+	var set types.BreakpointSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		t.Fatalf("Failed to decode the exported set: %v", err)
+	}
+	expected := []types.SavedBreakpoint{
+		{ID: 1, File: "main.go", Line: 10, Function: "main.handle", Condition: "n > 1", CaptureExprs: []string{"n"}, Disabled: true, GoroutineLabel: "user=alice"},
+		{ID: 2, File: "main.go", Line: 20, Function: "main.serve", Tracepoint: true, IgnoreCount: 5},
+	}
+	if !reflect.DeepEqual(set.Breakpoints, expected) {
+		t.Fatalf("Expected only the user breakpoints exported\n%+v, got\n%+v", expected, set.Breakpoints)
+	}
+
+	// In the new binary, line 20 moved to another function
+	imported := &fakeBreakpoints{functions: map[string]string{"main.go:10": "main.handle", "main.go:20": "main.other"}}
+	c, _ = newFakeDelve(t, imported.serve(t, map[string]fakeHandler{
+		"State": fakeState(&api.DebuggerState{}),
+	}))
+
+	response := c.ImportBreakpoints(data)
+	if response.Status != "partial" {
+		t.Fatalf("Expected a partial import, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if len(response.Restored) != 1 || response.Restored[0].PreviousID != 1 {
+		t.Fatalf("Expected breakpoint 1 restored, got %+v", response.Restored)
+	}
+	id := response.Restored[0].Breakpoint.ID
+	if bp := imported.get(id); bp == nil || !bp.Disabled || bp.Cond != "n > 1" {
+		t.Errorf("Expected breakpoint %d set disabled with its condition, got %+v", id, bp)
+	}
+	if filter := c.labelFilters[id]; filter == nil || filter.label != "user=alice" {
+		t.Errorf("Expected the label filter on breakpoint %d, got %+v", id, filter)
+	}
+
+	if len(response.Failed) != 1 || response.Failed[0].Saved.ID != 2 || !strings.Contains(response.Failed[0].Reason, "location moved") {
+		t.Errorf("Expected breakpoint 2 to fail as its location moved, got %+v", response.Failed)
+	}
+	if len(imported.bps) != 1 || len(c.ignoreCounts) != 0 {
+		t.Errorf("Expected the moved breakpoint cleared with nothing kept for it, got %d breakpoints and ignore counts %v", len(imported.bps), c.ignoreCounts)
+	}
+}
+
+func TestImportBreakpointsVersion(t *testing.T) {
+	c, _ := newFakeDelve(t, map[string]fakeHandler{})
+	response := c.ImportBreakpoints([]byte(`{"version": 2, "breakpoints": []}`))
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "unsupported breakpoint set version 2") {
+		t.Errorf("Expected an unsupported version error, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+}
@@ -0,0 +1,157 @@
+package debugger
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// BuildInfo reads the build info the Go toolchain embeds in an executable: the Go version
+// it was built with, its main module and the versions of the modules it depends on, and
+// build settings such as -trimpath, GOOS and the VCS revision. It reads the file rather
+// than the process, so it works on a program that has exited or was never run. Without a
+// program, it reads the executable of the session: the binary launched, the one of the
+// process attached to, or the one a core dump was produced by.
+func (c *Client) BuildInfo(program string) types.BuildInfoResponse {
+	executable := program
+	if executable == "" {
+		var err error
+		if executable, err = c.executablePath(); err != nil {
+			return c.createBuildInfoResponse("", nil, err)
+		}
+	} else if abs, err := filepath.Abs(executable); err == nil {
+		executable = abs
+	}
+
+	if _, err := os.Stat(executable); err != nil {
+		return c.createBuildInfoResponse(executable, nil, fmt.Errorf("executable not found: %s", executable))
+	}
+
+	logger.Debug("Reading build info of %s", executable)
+	info, err := buildinfo.ReadFile(executable)
+	if err != nil {
+		return c.createBuildInfoResponse(executable, nil, fmt.Errorf("no Go build info in %s: %v; it is not a Go executable, or was built before Go 1.18 or with its build info removed", executable, err))
+	}
+	return c.createBuildInfoResponse(executable, info, nil)
+}
+
+// executablePath returns the executable the session debugs, as far as it is on this machine
+func (c *Client) executablePath() (string, error) {
+	switch {
+	case c.client == nil:
+		return "", fmt.Errorf("no active debug session; give the program to read the build info of")
+	case c.coreExecutable != "":
+		return c.coreExecutable, nil
+	case c.target != "":
+		return c.target, nil
+	case c.remoteAddr != "":
+		return "", fmt.Errorf("the target runs on the remote server %s; give the path of a copy of its executable", c.remoteAddr)
+	}
+
+	// An attached process; Delve was given its PID rather than its executable
+	exe, err := processExecutable(c.pid)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the executable of process %d: %v; give the program to read the build info of", c.pid, err)
+	}
+	return exe, nil
+}
+
+// processExecutable returns the path of the executable a process runs
+func processExecutable(pid int) (string, error) {
+	exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if err != nil {
+		return "", err
+	}
+	if strings.HasSuffix(exe, " (deleted)") {
+		return "", fmt.Errorf("its executable %s was deleted", strings.TrimSuffix(exe, " (deleted)"))
+	}
+	return exe, nil
+}
+
+// convertModule converts a module of the build info, nil staying nil
+func convertModule(m *debug.Module) *types.ModuleVersion {
+	if m == nil {
+		return nil
+	}
+	return &types.ModuleVersion{
+		Path:    m.Path,
+		Version: m.Version,
+		Sum:     m.Sum,
+		Replace: convertModule(m.Replace),
+	}
+}
+
+// buildInfoNotes points out what the build info can't tell, or tells about file paths
+func buildInfoNotes(info *debug.BuildInfo, settings map[string]string) []string {
+	var notes []string
+	if info.Main.Path == "" {
+		notes = append(notes, "built outside of a module, e.g. from GOPATH or with go run on a file, so it has no module versions")
+	} else if info.Main.Version == "(devel)" || info.Main.Version == "" {
+		note := "the main module was built from a local checkout rather than a released version"
+		if revision := settings["vcs.revision"]; revision != "" {
+			note += fmt.Sprintf(", at revision %s", revision)
+			if settings["vcs.modified"] == "true" {
+				note += " with uncommitted changes"
+			}
+		}
+		notes = append(notes, note)
+	}
+	if settings["-trimpath"] == "true" {
+		notes = append(notes, "built with -trimpath, so source file paths in its debug info are module paths, such as example.com/app@v1.2.0/main.go, rather than where the files are on disk")
+	}
+	if strippedDWARF(settings["-ldflags"]) {
+		notes = append(notes, "built with -ldflags=-w, which drops the DWARF debug info Delve needs for source lines and variables")
+	}
+	return notes
+}
+
+// strippedDWARF reports whether linker flags include -w, which omits the DWARF debug info
+func strippedDWARF(ldflags string) bool {
+	for _, flag := range strings.Fields(ldflags) {
+		if flag == "-w" || flag == "-w=true" || flag == "--w" {
+			return true
+		}
+	}
+	return false
+}
+
+// createBuildInfoResponse creates a BuildInfoResponse from the build info of an executable
+func (c *Client) createBuildInfoResponse(executable string, info *debug.BuildInfo, err error) types.BuildInfoResponse {
+	context := c.createDebugContext(nil)
+	context.Operation = "build_info"
+
+	response := types.BuildInfoResponse{
+		Status:     "success",
+		Context:    context,
+		Executable: executable,
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	response.GoVersion = info.GoVersion
+	response.Path = info.Path
+	if info.Main.Path != "" {
+		response.Main = convertModule(&info.Main)
+	}
+	response.Deps = make([]types.ModuleVersion, 0, len(info.Deps))
+	for _, dep := range info.Deps {
+		response.Deps = append(response.Deps, *convertModule(dep))
+	}
+	if len(info.Settings) > 0 {
+		response.Settings = make(map[string]string, len(info.Settings))
+		for _, setting := range info.Settings {
+			response.Settings[setting.Key] = setting.Value
+		}
+	}
+	response.Notes = buildInfoNotes(info, response.Settings)
+	return response
+}
@@ -0,0 +1,115 @@
+package debugger
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestBuildInfo(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Failed to find the test executable: %v", err)
+	}
+	notGo := filepath.Join(t.TempDir(), "script.sh")
+	if err := os.WriteFile(notGo, []byte("#!/bin/sh\necho hello\n"), 0755); err != nil {
+		t.Fatalf("Failed to write %s: %v", notGo, err)
+	}
+
+	testCases := []struct {
+		name          string
+		program       string
+		expectedError string
+	}{
+		{name: "Go executable", program: executable},
+		{name: "Not a Go executable", program: notGo, expectedError: "no Go build info in"},
+		{name: "Missing executable", program: filepath.Join(t.TempDir(), "missing"), expectedError: "executable not found"},
+		{name: "No program without a session", expectedError: "no active debug session"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := NewClient().BuildInfo(tc.program)
+			if response.Context.Operation != "build_info" {
+				t.Errorf("Expected operation build_info, got %q", response.Context.Operation)
+			}
+			if tc.expectedError != "" {
+				if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, tc.expectedError) {
+					t.Errorf("Expected an error containing %q, got %+v", tc.expectedError, response)
+				}
+				return
+			}
+			if response.Status != "success" {
+				t.Fatalf("Expected success, got %s", response.Context.ErrorMessage)
+			}
+			if response.GoVersion != runtime.Version() {
+				t.Errorf("Expected Go version %s, got %s", runtime.Version(), response.GoVersion)
+			}
+			if response.Main == nil || response.Main.Path != "github.com/sunfmin/mcp-go-debugger" {
+				t.Errorf("Expected the main module of this repository, got %+v", response.Main)
+			}
+			found := false
+			for _, dep := range response.Deps {
+				found = found || dep.Path == "github.com/go-delve/delve"
+			}
+			if !found {
+				t.Errorf("Expected delve among the dependencies, got %+v", response.Deps)
+			}
+		})
+	}
+}
+
+func TestBuildInfoNotes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		info     debug.BuildInfo
+		settings map[string]string
+		expected []string
+	}{
+		{
+			name:     "Released version",
+			info:     debug.BuildInfo{Main: debug.Module{Path: "example.com/app", Version: "v1.2.0"}},
+			expected: nil,
+		},
+		{
+			name:     "Outside of a module",
+			info:     debug.BuildInfo{},
+			expected: []string{"built outside of a module"},
+		},
+		{
+			name:     "Local checkout with changes",
+			info:     debug.BuildInfo{Main: debug.Module{Path: "example.com/app", Version: "(devel)"}},
+			settings: map[string]string{"vcs.revision": "abc123", "vcs.modified": "true"},
+			expected: []string{"at revision abc123 with uncommitted changes"},
+		},
+		{
+			name:     "Trimmed paths and stripped DWARF",
+			info:     debug.BuildInfo{Main: debug.Module{Path: "example.com/app", Version: "v1.2.0"}},
+			settings: map[string]string{"-trimpath": "true", "-ldflags": "-s -w"},
+			expected: []string{"built with -trimpath", "built with -ldflags=-w"},
+		},
+		{
+			name:     "Flag values are not flags",
+			info:     debug.BuildInfo{Main: debug.Module{Path: "example.com/app", Version: "v1.2.0"}},
+			settings: map[string]string{"-ldflags": "-X main.version=1.0-w"},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			notes := buildInfoNotes(&tc.info, tc.settings)
+			if len(notes) != len(tc.expected) {
+				t.Fatalf("Expected %d notes, got %q", len(tc.expected), notes)
+			}
+			for i, expected := range tc.expected {
+				if !strings.Contains(notes[i], expected) {
+					t.Errorf("Expected note %d to contain %q, got %q", i, expected, notes[i])
+				}
+			}
+		})
+	}
+}
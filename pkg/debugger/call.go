@@ -0,0 +1,127 @@
+package debugger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// CallFunction injects a function call such as `fmt.Sprintf("%d", count)` into the selected
+// goroutine and returns its results. Delve evaluates injected calls in the topmost frame,
+// so only frame 0 is accepted.
+func (c *Client) CallFunction(expr string, frame int) types.CallFunctionResponse {
+	if c.client == nil {
+		return c.createCallFunctionResponse(nil, expr, fmt.Errorf("no active debug session"))
+	}
+
+	if strings.TrimSpace(expr) == "" {
+		return c.createCallFunctionResponse(nil, expr, fmt.Errorf("call expression must not be empty"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createCallFunctionResponse(nil, expr, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createCallFunctionResponse(nil, expr, fmt.Errorf("cannot call a function while the target is running; stop the target first"))
+	}
+
+	if frame != 0 {
+		return c.createCallFunctionResponse(state, expr, fmt.Errorf("function calls can only be made from frame 0, the frame the goroutine is stopped in"))
+	}
+
+	var goroutineID int64
+	if state.SelectedGoroutine != nil {
+		goroutineID = state.SelectedGoroutine.ID
+	}
+
+	// Ask Delve to load the call's return values
+	c.client.SetReturnValuesLoadConfig(&api.LoadConfig{
+		FollowPointers:     true,
+		MaxVariableRecurse: 1,
+		MaxStringLen:       256,
+		MaxArrayValues:     64,
+		MaxStructFields:    -1,
+	})
+
+	// The call runs the target, and may stop at a breakpoint in it
+	c.forgetAsyncRun()
+
+	logger.Debug("Calling %s on goroutine %d", expr, goroutineID)
+	newState, err := c.client.Call(goroutineID, expr, false)
+	if err != nil {
+		if isFunctionCallUnsupported(err) {
+			return c.createCallFunctionResponse(nil, expr, fmt.Errorf("function calls are not supported for this target: %v", err))
+		}
+		return c.createCallFunctionResponse(nil, expr, fmt.Errorf("failed to call %s: %v", expr, err))
+	}
+
+	return c.createCallFunctionResponse(newState, expr, nil)
+}
+
+// isFunctionCallUnsupported reports whether Delve refused a call because of the target,
+// backend or Go version rather than the expression
+func isFunctionCallUnsupported(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "backend does not support function calls") ||
+		strings.Contains(msg, "function calls not supported") ||
+		strings.Contains(msg, "not supported on this architecture")
+}
+
+// createCallFunctionResponse creates a CallFunctionResponse
+func (c *Client) createCallFunctionResponse(state *api.DebuggerState, expr string, err error) types.CallFunctionResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "call_function"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.CallFunctionResponse{
+			Status:     "error",
+			Context:    context,
+			Expression: expr,
+		}
+	}
+
+	response := types.CallFunctionResponse{
+		Status:     "success",
+		Context:    context,
+		Expression: expr,
+	}
+
+	if state.CurrentThread == nil {
+		return response
+	}
+
+	// A breakpoint inside the called function stops the call before it returns
+	if bp := state.CurrentThread.Breakpoint; bp != nil && !state.CurrentThread.CallReturn {
+		breakpoint := convertBreakpoint(bp)
+		response.InterruptedBy = &breakpoint
+		return response
+	}
+
+	for i := range state.CurrentThread.ReturnValues {
+		v := &state.CurrentThread.ReturnValues[i]
+		variable := types.Variable{
+			DelveVar: v,
+			Name:     v.Name,
+			Value:    formatVariableValue(v),
+			Type:     v.Type,
+			Scope:    "return",
+			Kind:     getVariableKind(v),
+		}
+
+		// Delve returns the panic value in place of the results when the call panics
+		if v.Name == "~panic" {
+			response.Panicked = true
+			response.Panic = &variable
+			continue
+		}
+		response.ReturnValues = append(response.ReturnValues, variable)
+	}
+
+	return response
+}
@@ -0,0 +1,297 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxCaptureHits caps the hits whose captured values are kept per breakpoint, dropping
+// the oldest first
+const maxCaptureHits = 20
+
+// maxCaptureChanges caps the changes reported between two hits
+const maxCaptureChanges = 100
+
+// Kinds of change between the values captured by two hits
+const (
+	captureChanged = "changed"
+	captureAdded   = "added"   // An element or map entry the previous hit did not have
+	captureRemoved = "removed" // An element or map entry the current hit no longer has
+)
+
+// captureHit is what one stop at a breakpoint with capture expressions captured
+type captureHit struct {
+	hit         uint64 // Hits of the breakpoint so far, as counted by Delve
+	goroutineID int64
+	timestamp   time.Time
+	position    *types.SourcePosition
+	exprs       []string                 // Capture expressions, in the order they were set
+	values      map[string]*api.Variable // Captured values, keyed by expression
+}
+
+// recordCaptureHits keeps the values captured by every thread stopped at a breakpoint with
+// capture expressions. A hit already recorded, e.g. seen by both a step and the continue
+// issued for it, is kept once.
+func (c *Client) recordCaptureHits(state *api.DebuggerState) {
+	if state == nil || state.Exited {
+		return
+	}
+
+	for _, th := range state.Threads {
+		bp := th.Breakpoint
+		if bp == nil || bp.Tracepoint || len(bp.Variables) == 0 || th.BreakpointInfo == nil {
+			continue
+		}
+
+		history := c.captureHistories[bp.ID]
+		if n := len(history); n > 0 && history[n-1].hit == bp.TotalHitCount && history[n-1].goroutineID == th.GoroutineID {
+			continue
+		}
+
+		hit := &captureHit{
+			hit:         bp.TotalHitCount,
+			goroutineID: th.GoroutineID,
+			timestamp:   time.Now(),
+			exprs:       bp.Variables,
+			values:      make(map[string]*api.Variable),
+		}
+		if th.File != "" {
+			hit.position = &types.SourcePosition{File: th.File, Line: th.Line, Function: getFunctionName(th)}
+		}
+		for i := range th.BreakpointInfo.Variables {
+			if i < len(bp.Variables) {
+				hit.values[bp.Variables[i]] = &th.BreakpointInfo.Variables[i]
+			}
+		}
+
+		history = append(history, hit)
+		if len(history) > maxCaptureHits {
+			history = append([]*captureHit(nil), history[len(history)-maxCaptureHits:]...)
+		}
+		if c.captureHistories == nil {
+			c.captureHistories = make(map[int][]*captureHit)
+		}
+		c.captureHistories[bp.ID] = history
+	}
+}
+
+// DiffCapture compares the values a breakpoint's capture expressions had at its last hit
+// with the ones they had at the hit before, down to the fields, elements and map entries
+// that changed, so repeated hits show what each iteration changed rather than every value
+// again. The first hit has nothing to compare against and reports its values instead.
+func (c *Client) DiffCapture(breakpointID int) types.CaptureDiffResponse {
+	if c.client == nil {
+		return c.createCaptureDiffResponse(nil, breakpointID, nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	// The captured values are kept here, so they can be compared after the target exits
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		logger.Debug("Warning: Failed to get state while diffing captures: %v", err)
+	}
+
+	history := c.captureHistories[breakpointID]
+	if len(history) == 0 {
+		bp, err := c.client.GetBreakpoint(breakpointID)
+		switch {
+		case err != nil:
+			err = fmt.Errorf("breakpoint %d not found: %v", breakpointID, err)
+		case len(bp.Variables) == 0:
+			err = fmt.Errorf("breakpoint %d has no capture expressions; set a breakpoint with captureExprs to track their values across hits", breakpointID)
+		default:
+			err = fmt.Errorf("breakpoint %d has not been hit yet; continue until it stops", breakpointID)
+		}
+		return c.createCaptureDiffResponse(state, breakpointID, nil, nil, err)
+	}
+
+	logger.Debug("Diffing captures of breakpoint %d, %d hits kept", breakpointID, len(history))
+	current := history[len(history)-1]
+	if len(history) == 1 {
+		return c.createCaptureDiffResponse(state, breakpointID, current, nil, nil)
+	}
+	return c.createCaptureDiffResponse(state, breakpointID, current, history[len(history)-2], nil)
+}
+
+// diffCaptures returns the changes from the values captured by one hit to the next, in the
+// order of the capture expressions, and the expressions whose values did not change
+func diffCaptures(previous, current *captureHit) ([]types.CaptureChange, []string) {
+	var changes []types.CaptureChange
+	var unchanged []string
+	for _, expr := range current.exprs {
+		before := len(changes)
+		diffCapturedValue(expr, previous.values[expr], current.values[expr], &changes)
+		if len(changes) == before {
+			unchanged = append(unchanged, expr)
+		}
+	}
+	return changes, unchanged
+}
+
+// diffCapturedValue appends the changes from prev to cur under path. Structs, arrays,
+// slices, maps, and the values pointers and interfaces hold are compared member by member,
+// so only the members that changed are reported.
+func diffCapturedValue(path string, prev, cur *api.Variable, changes *[]types.CaptureChange) {
+	switch {
+	case prev == nil && cur == nil:
+		return
+	case prev == nil:
+		*changes = append(*changes, types.CaptureChange{Path: path, Change: captureAdded, New: capturedValueString(cur)})
+		return
+	case cur == nil:
+		*changes = append(*changes, types.CaptureChange{Path: path, Change: captureRemoved, Old: capturedValueString(prev)})
+		return
+	}
+
+	sameType := prev.Unreadable == "" && cur.Unreadable == "" && prev.Kind == cur.Kind && prev.Type == cur.Type
+	if sameType {
+		switch prev.Kind {
+		case reflect.Struct:
+			if len(prev.Children) > 0 && len(prev.Children) == len(cur.Children) {
+				for i := range prev.Children {
+					diffCapturedValue(path+"."+prev.Children[i].Name, &prev.Children[i], &cur.Children[i], changes)
+				}
+				return
+			}
+		case reflect.Array, reflect.Slice:
+			if prev.Len != cur.Len {
+				*changes = append(*changes, types.CaptureChange{Path: "len(" + path + ")", Change: captureChanged, Old: fmt.Sprint(prev.Len), New: fmt.Sprint(cur.Len)})
+			}
+			for i := 0; i < max(len(prev.Children), len(cur.Children)); i++ {
+				diffCapturedValue(fmt.Sprintf("%s[%d]", path, i), childAt(prev, i), childAt(cur, i), changes)
+			}
+			return
+		case reflect.Map:
+			prevEntries := make(map[string]*api.Variable)
+			for _, e := range sortedMapEntries(prev) {
+				prevEntries[e.key.Value] = e.value
+			}
+			seen := make(map[string]bool)
+			for _, e := range sortedMapEntries(cur) {
+				seen[e.key.Value] = true
+				diffCapturedValue(fmt.Sprintf("%s[%s]", path, capturedValueString(e.key)), prevEntries[e.key.Value], e.value, changes)
+			}
+			for _, e := range sortedMapEntries(prev) {
+				if !seen[e.key.Value] {
+					diffCapturedValue(fmt.Sprintf("%s[%s]", path, capturedValueString(e.key)), e.value, nil, changes)
+				}
+			}
+			return
+		case reflect.Ptr, reflect.Interface:
+			// Fields are reached through a pointer with the same expression, e.g. p.count
+			if len(prev.Children) == 1 && len(cur.Children) == 1 && prev.Children[0].Addr != 0 && cur.Children[0].Addr != 0 && prev.Children[0].Type == cur.Children[0].Type {
+				diffCapturedValue(path, &prev.Children[0], &cur.Children[0], changes)
+				return
+			}
+		}
+	}
+
+	if before, after := capturedValueString(prev), capturedValueString(cur); before != after {
+		*changes = append(*changes, types.CaptureChange{Path: path, Change: captureChanged, Old: before, New: after})
+	}
+}
+
+// childAt returns the i-th loaded child of v, or nil when it has fewer
+func childAt(v *api.Variable, i int) *api.Variable {
+	if i < len(v.Children) {
+		return &v.Children[i]
+	}
+	return nil
+}
+
+// capturedValueString renders a captured value, or why it could not be read. Strings are
+// quoted, so an empty one still shows.
+func capturedValueString(v *api.Variable) string {
+	if v.Unreadable != "" {
+		return "unreadable: " + v.Unreadable
+	}
+	switch v.Kind {
+	case reflect.String:
+		return strconv.Quote(v.Value) + notShownSuffix(v.Len, int64(len(v.Value)))
+	case reflect.Ptr:
+		if len(v.Children) == 0 || v.Children[0].Addr == 0 {
+			return "nil"
+		}
+		// Past the load depth only the address is known
+		if pointee := &v.Children[0]; pointee.Value != "" || len(pointee.Children) > 0 {
+			return "&" + capturedValueString(pointee)
+		}
+		return fmt.Sprintf("%s(%#x)", v.Type, v.Children[0].Addr)
+	case reflect.Interface:
+		if len(v.Children) == 0 || v.Children[0].Kind == reflect.Invalid {
+			return "nil"
+		}
+		return fmt.Sprintf("%s(%s)", v.Children[0].Type, capturedValueString(&v.Children[0]))
+	}
+	return formatVariableValue(v)
+}
+
+// createCaptureDiffResponse creates a CaptureDiffResponse for the changes from the previous
+// hit to the current one, or the current values when there is no previous hit
+func (c *Client) createCaptureDiffResponse(state *api.DebuggerState, breakpointID int, current, previous *captureHit, err error) types.CaptureDiffResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "diff_capture"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.CaptureDiffResponse{
+			Status:       "error",
+			Context:      context,
+			BreakpointID: breakpointID,
+		}
+	}
+
+	response := types.CaptureDiffResponse{
+		Status:       "success",
+		Context:      context,
+		BreakpointID: breakpointID,
+		Hit:          current.hit,
+		GoroutineID:  current.goroutineID,
+		Timestamp:    current.timestamp,
+		Position:     current.position,
+		HitsKept:     len(c.captureHistories[breakpointID]),
+	}
+	if current.position != nil {
+		response.Location = formatPosition(current.position)
+	}
+
+	if previous == nil {
+		response.Values = make(map[string]string)
+		for expr, v := range current.values {
+			response.Values[expr] = capturedValueString(v)
+		}
+		response.Summary = fmt.Sprintf("first recorded hit of breakpoint %d, nothing to compare against yet; its %d captured values are listed", breakpointID, len(response.Values))
+		return response
+	}
+
+	response.PreviousHit = previous.hit
+	response.PreviousGoroutineID = previous.goroutineID
+	response.Changes, response.Unchanged = diffCaptures(previous, current)
+	if len(response.Changes) > maxCaptureChanges {
+		response.Changes = response.Changes[:maxCaptureChanges]
+		response.ChangesTruncated = true
+	}
+
+	switch {
+	case len(response.Changes) == 0:
+		response.Summary = fmt.Sprintf("nothing changed between hits %d and %d of breakpoint %d", previous.hit, current.hit, breakpointID)
+	default:
+		paths := make([]string, 0, len(response.Changes))
+		for _, change := range response.Changes {
+			paths = append(paths, change.Path)
+		}
+		response.Summary = fmt.Sprintf("%d changes between hits %d and %d of breakpoint %d: %s", len(response.Changes), previous.hit, current.hit, breakpointID, strings.Join(paths, ", "))
+		if response.ChangesTruncated {
+			response.Summary += fmt.Sprintf(", and more; only the first %d are listed", maxCaptureChanges)
+		}
+	}
+	if previous.goroutineID != current.goroutineID {
+		response.Summary += fmt.Sprintf("; the hits are on different goroutines, %d and %d", previous.goroutineID, current.goroutineID)
+	}
+	return response
+}
@@ -0,0 +1,198 @@
+package debugger
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestDiffCapturedValue(t *testing.T) {
+	intVar := func(value string) *api.Variable {
+		return &api.Variable{Type: "int", Kind: reflect.Int, Value: value}
+	}
+	stateVar := func(count, name string) *api.Variable {
+		return &api.Variable{Type: "main.state", Kind: reflect.Struct, Children: []api.Variable{
+			{Name: "count", Type: "int", Kind: reflect.Int, Value: count},
+			{Name: "name", Type: "string", Kind: reflect.String, Value: name, Len: int64(len(name))},
+		}}
+	}
+	sliceVar := func(values ...string) *api.Variable {
+		v := &api.Variable{Type: "[]int", Kind: reflect.Slice, Len: int64(len(values))}
+		for _, value := range values {
+			v.Children = append(v.Children, *intVar(value))
+		}
+		return v
+	}
+	mapVar := func(pairs ...string) *api.Variable {
+		v := &api.Variable{Type: "map[string]int", Kind: reflect.Map, Len: int64(len(pairs) / 2)}
+		for i := 0; i+1 < len(pairs); i += 2 {
+			v.Children = append(v.Children, api.Variable{Type: "string", Kind: reflect.String, Value: pairs[i], Len: int64(len(pairs[i]))}, *intVar(pairs[i+1]))
+		}
+		return v
+	}
+	pointerTo := func(v *api.Variable) *api.Variable {
+		v.Addr = 0xc000010000
+		return &api.Variable{Type: "*" + v.Type, Kind: reflect.Ptr, Children: []api.Variable{*v}}
+	}
+
+	testCases := []struct {
+		name     string
+		prev     *api.Variable
+		cur      *api.Variable
+		expected []types.CaptureChange
+	}{
+		{name: "Unchanged", prev: intVar("1"), cur: intVar("1")},
+		{name: "Changed", prev: intVar("1"), cur: intVar("2"), expected: []types.CaptureChange{{Path: "x", Change: "changed", Old: "1", New: "2"}}},
+		{name: "Struct field", prev: stateVar("1", ""), cur: stateVar("1", "two"), expected: []types.CaptureChange{{Path: "x.name", Change: "changed", Old: `""`, New: `"two"`}}},
+		{name: "Through a pointer", prev: pointerTo(stateVar("1", "a")), cur: pointerTo(stateVar("3", "a")), expected: []types.CaptureChange{{Path: "x.count", Change: "changed", Old: "1", New: "3"}}},
+		{
+			name: "Slice grown",
+			prev: sliceVar("0", "1"),
+			cur:  sliceVar("0", "5", "4"),
+			expected: []types.CaptureChange{
+				{Path: "len(x)", Change: "changed", Old: "2", New: "3"},
+				{Path: "x[1]", Change: "changed", Old: "1", New: "5"},
+				{Path: "x[2]", Change: "added", New: "4"},
+			},
+		},
+		{
+			name: "Map entries",
+			prev: mapVar("a", "1", "b", "2"),
+			cur:  mapVar("b", "3", "c", "1"),
+			expected: []types.CaptureChange{
+				{Path: `x["b"]`, Change: "changed", Old: "2", New: "3"},
+				{Path: `x["c"]`, Change: "added", New: "1"},
+				{Path: `x["a"]`, Change: "removed", Old: "1"},
+			},
+		},
+		{name: "Became unreadable", prev: intVar("1"), cur: &api.Variable{Unreadable: "nil pointer dereference"}, expected: []types.CaptureChange{{Path: "x", Change: "changed", Old: "1", New: "unreadable: nil pointer dereference"}}},
+		{name: "Pointer set to nil", prev: pointerTo(stateVar("1", "")), cur: &api.Variable{Type: "*main.state", Kind: reflect.Ptr, Children: []api.Variable{{Type: "main.state", Kind: reflect.Struct}}}, expected: []types.CaptureChange{{Path: "x", Change: "changed", Old: `&{count:1, name:}`, New: "nil"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var changes []types.CaptureChange
+			diffCapturedValue("x", tc.prev, tc.cur, &changes)
+			if !reflect.DeepEqual(changes, tc.expected) {
+				t.Errorf("Expected %+v, got %+v", tc.expected, changes)
+			}
+		})
+	}
+}
+
+// captureState returns a state stopped at breakpoint 1, capturing i with the given value
+func captureState(hit uint64, value string) *api.DebuggerState {
+	return &api.DebuggerState{Threads: []*api.Thread{{
+		GoroutineID:    1,
+		File:           "/tmp/main.go",
+		Line:           22,
+		Breakpoint:     &api.Breakpoint{ID: 1, Variables: []string{"i"}, TotalHitCount: hit},
+		BreakpointInfo: &api.BreakpointInfo{Variables: []api.Variable{{Name: "i", Type: "int", Kind: reflect.Int, Value: value}}},
+	}}}
+}
+
+func TestRecordCaptureHits(t *testing.T) {
+	c := NewClient()
+	for hit := uint64(1); hit <= maxCaptureHits+5; hit++ {
+		state := captureState(hit, "0")
+		c.recordCaptureHits(state)
+		c.recordCaptureHits(state)
+	}
+
+	history := c.captureHistories[1]
+	if len(history) != maxCaptureHits {
+		t.Fatalf("Expected %d hits kept, got %d", maxCaptureHits, len(history))
+	}
+	if history[0].hit != 6 || history[len(history)-1].hit != maxCaptureHits+5 {
+		t.Errorf("Expected hits 6 to %d, got %d to %d", maxCaptureHits+5, history[0].hit, history[len(history)-1].hit)
+	}
+
+	c.recordCaptureHits(&api.DebuggerState{Threads: []*api.Thread{{Breakpoint: &api.Breakpoint{ID: 2}}}})
+	if _, ok := c.captureHistories[2]; ok {
+		t.Errorf("Expected a breakpoint without capture expressions not to be recorded")
+	}
+}
+
+func TestCreateCaptureDiffResponse(t *testing.T) {
+	c := NewClient()
+	c.recordCaptureHits(captureState(1, "0"))
+	first := c.captureHistories[1][0]
+
+	response := c.createCaptureDiffResponse(nil, 1, first, nil, nil)
+	if response.Status != "success" || response.Values["i"] != "0" || len(response.Changes) != 0 {
+		t.Errorf("Expected the values of the first hit, got %+v", response)
+	}
+
+	c.recordCaptureHits(captureState(2, "1"))
+	response = c.createCaptureDiffResponse(nil, 1, c.captureHistories[1][1], first, nil)
+	expected := "1 changes between hits 1 and 2 of breakpoint 1: i"
+	if response.Summary != expected {
+		t.Errorf("Expected summary %q, got %q", expected, response.Summary)
+	}
+	if response.HitsKept != 2 || response.Values != nil {
+		t.Errorf("Expected 2 hits kept and no values, got %d and %v", response.HitsKept, response.Values)
+	}
+
+	response = c.createCaptureDiffResponse(nil, 1, first, first, nil)
+	if response.Summary != "nothing changed between hits 1 and 1 of breakpoint 1" || !reflect.DeepEqual(response.Unchanged, []string{"i"}) {
+		t.Errorf("Expected nothing changed, got %q and %v", response.Summary, response.Unchanged)
+	}
+}
+
+func TestDiffCapture(t *testing.T) {
+	bps := &fakeBreakpoints{}
+	bps.add(&api.Breakpoint{ID: 1, File: "/tmp/main.go", Line: 22, Variables: []string{"i"}})
+	bps.add(&api.Breakpoint{ID: 2, File: "/tmp/main.go", Line: 30})
+	bps.add(&api.Breakpoint{ID: 3, File: "/tmp/main.go", Line: 40, Variables: []string{"n"}})
+
+	// Each continue hits breakpoint 1 again, with i one higher
+	var commands []string
+	c, _ := newFakeDelve(t, bps.serve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(5)),
+		"Command": fakeCommands(t, &commands, func(api.DebuggerCommand) api.DebuggerState {
+			state := captureState(uint64(len(commands)), fmt.Sprint(len(commands)-1))
+			state.CurrentThread = state.Threads[0]
+			return *state
+		}),
+	}))
+
+	if response := c.DiffCapture(1); response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "not been hit yet") {
+		t.Errorf("Expected breakpoint 1 not to be hit yet, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+
+	c.Continue(context.Background())
+	response := c.DiffCapture(1)
+	if response.Status != "success" || response.Hit != 1 || response.Values["i"] != "0" || len(response.Changes) != 0 {
+		t.Errorf("Expected the values of the first hit, got %+v", response)
+	}
+
+	c.Continue(context.Background())
+	response = c.DiffCapture(1)
+	expected := []types.CaptureChange{{Path: "i", Change: captureChanged, Old: "0", New: "1"}}
+	if response.Status != "success" || response.Hit != 2 || response.PreviousHit != 1 || !reflect.DeepEqual(response.Changes, expected) {
+		t.Errorf("Expected i to change from 0 to 1 between hits 1 and 2, got %+v", response)
+	}
+
+	testCases := []struct {
+		name     string
+		id       int
+		expected string
+	}{
+		{name: "No capture expressions", id: 2, expected: "breakpoint 2 has no capture expressions"},
+		{name: "Not hit yet", id: 3, expected: "breakpoint 3 has not been hit yet"},
+		{name: "Unknown breakpoint", id: 9, expected: "breakpoint 9 not found"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.DiffCapture(tc.id)
+			if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, tc.expected) {
+				t.Errorf("Expected an error containing %q, got %s: %s", tc.expected, response.Status, response.Context.ErrorMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxChannelWaiters caps the goroutines listed per wait queue of an inspected channel
+const maxChannelWaiters = 100
+
+// States of an inspected channel
+const (
+	channelNil    = "nil"    // Sends and receives block forever
+	channelOpen   = "open"   // Not closed
+	channelClosed = "closed" // Receives drain the buffer, then return the zero value
+)
+
+// channelLoadConfig loads a channel's runtime struct, hchan, down to the heads of its wait
+// queues
+var channelLoadConfig = api.LoadConfig{
+	MaxVariableRecurse: 2,
+	MaxStructFields:    -1,
+}
+
+// channelBufferLoadConfig loads the values in a channel's buffer
+var channelBufferLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 3,
+	MaxStringLen:       256,
+	MaxArrayValues:     64,
+	MaxStructFields:    -1,
+}
+
+// sudogLoadConfig loads the fields of a runtime.sudog, the entry of a goroutine in a wait
+// queue, without following them
+var sudogLoadConfig = api.LoadConfig{
+	MaxVariableRecurse: 1,
+	MaxStructFields:    -1,
+}
+
+// channelInfo is what was read of a channel's runtime struct
+type channelInfo struct {
+	v         *api.Variable
+	state     string
+	buffered  []types.Variable
+	senders   []types.ChannelWaiter
+	receivers []types.ChannelWaiter
+	truncated bool     // Wait queues had more goroutines than are listed
+	notes     []string // What could not be read, e.g. fields this Go version lacks
+}
+
+// InspectChannel evaluates a channel expression in the given frame and reports its element
+// type, capacity, length and the values in its buffer, along with the goroutines blocked
+// sending to or receiving from it. Those are read from the runtime's hchan struct, whose
+// fields may differ between Go versions: what can't be read is noted, not an error.
+func (c *Client) InspectChannel(expr string, frame int) types.InspectChannelResponse {
+	if c.client == nil {
+		return c.createInspectChannelResponse(nil, expr, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createInspectChannelResponse(nil, expr, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createInspectChannelResponse(nil, expr, nil, fmt.Errorf("cannot inspect channels while the target is running; stop the target first"))
+	}
+	if state.SelectedGoroutine == nil {
+		return c.createInspectChannelResponse(state, expr, nil, fmt.Errorf("no goroutine selected"))
+	}
+
+	scope := api.EvalScope{
+		GoroutineID: state.SelectedGoroutine.ID,
+		Frame:       frame,
+	}
+
+	logger.Debug("Inspecting channel %q in frame %d", expr, frame)
+	v, err := c.client.EvalVariable(scope, expr, channelLoadConfig)
+	if err != nil {
+		if isUnresolvedSymbol(err) {
+			return c.createInspectChannelResponse(state, expr, nil, fmt.Errorf("could not resolve %q: %v; variables in scope: %s", expr, err, c.scopeVariableNames(scope)))
+		}
+		return c.createInspectChannelResponse(state, expr, nil, fmt.Errorf("failed to evaluate %q: %v", expr, err))
+	}
+	if v == nil {
+		return c.createInspectChannelResponse(state, expr, nil, fmt.Errorf("expression %q produced no value", expr))
+	}
+	if v.Kind != reflect.Chan {
+		return c.createInspectChannelResponse(state, expr, nil, fmt.Errorf("%q is not a channel but a %s of type %s; use eval_expression to inspect it", expr, v.Kind, v.Type))
+	}
+
+	info := &channelInfo{v: v, state: channelOpen}
+	if len(v.Children) == 0 {
+		info.state = channelNil
+		return c.createInspectChannelResponse(state, expr, info, nil)
+	}
+
+	closed := channelField(v, "closed")
+	switch {
+	case closed == nil:
+		info.notes = append(info.notes, "whether the channel is closed is unknown: the runtime's hchan has no closed field in this Go version")
+	case closed.Value != "0":
+		info.state = channelClosed
+	}
+
+	if channelUintField(v, "qcount") > 0 {
+		buf, err := c.client.EvalVariable(scope, fmt.Sprintf("(%s).buf", expr), channelBufferLoadConfig)
+		if err != nil {
+			info.notes = append(info.notes, fmt.Sprintf("the buffered values could not be read: %v", err))
+		} else if buffered, ok := channelBuffer(v, buf); ok {
+			info.buffered = buffered
+		} else {
+			info.buffered = buffered
+			info.notes = append(info.notes, fmt.Sprintf("only the first %d buffered values are shown", len(buffered)))
+		}
+	}
+
+	goroutines := make(map[int64]*api.Goroutine)
+	if gs, _, err := c.client.ListGoroutines(0, 0); err == nil {
+		for _, g := range gs {
+			goroutines[g.ID] = g
+		}
+	} else {
+		logger.Debug("Warning: Failed to list goroutines: %v", err)
+	}
+	for _, queue := range []struct {
+		field   string
+		waiters *[]types.ChannelWaiter
+	}{{"sendq", &info.senders}, {"recvq", &info.receivers}} {
+		waiters, truncated, err := c.channelWaiters(scope, v, queue.field, goroutines)
+		if err != nil {
+			info.notes = append(info.notes, fmt.Sprintf("goroutines blocked on %s could not be read: %v", queue.field, err))
+		}
+		*queue.waiters = waiters
+		info.truncated = info.truncated || truncated
+	}
+
+	return c.createInspectChannelResponse(state, expr, info, nil)
+}
+
+// channelField returns a field of a channel's hchan struct, or nil when it has none by
+// that name
+func channelField(v *api.Variable, name string) *api.Variable {
+	for i := range v.Children {
+		if v.Children[i].Name == name {
+			return &v.Children[i]
+		}
+	}
+	return nil
+}
+
+// channelBuffer returns the values in buf, the buffer of channel v, in the order they will
+// be received: the buffer is a ring starting at recvx. It reports false when not all of them
+// were loaded.
+func channelBuffer(v, buf *api.Variable) ([]types.Variable, bool) {
+	count := channelUintField(v, "qcount")
+	recvx := channelUintField(v, "recvx")
+	if count == 0 {
+		return nil, true
+	}
+	if buf == nil || len(buf.Children) == 0 || buf.Children[0].Kind != reflect.Array {
+		return nil, false
+	}
+
+	ring := buf.Children[0].Children
+	size := uint64(buf.Children[0].Len)
+	if size == 0 {
+		return nil, false
+	}
+
+	var values []types.Variable
+	for i := uint64(0); i < count; i++ {
+		index := (recvx + i) % size
+		if index >= uint64(len(ring)) {
+			return values, false
+		}
+		value := convertVariableTree(&ring[index], "", 1)
+		value.Name = fmt.Sprintf("[%d]", i)
+		values = append(values, value)
+	}
+	return values, true
+}
+
+// channelUintField returns the value of an unsigned integer field of a channel's hchan
+// struct, or 0 when it is missing
+func channelUintField(v *api.Variable, name string) uint64 {
+	field := channelField(v, name)
+	if field == nil {
+		return 0
+	}
+	n, _ := strconv.ParseUint(field.Value, 10, 64)
+	return n
+}
+
+// channelWaiters walks one of a channel's wait queues, sendq or recvq: a linked list of
+// runtime.sudog, one per blocked goroutine
+func (c *Client) channelWaiters(scope api.EvalScope, v *api.Variable, queue string, goroutines map[int64]*api.Goroutine) ([]types.ChannelWaiter, bool, error) {
+	q := channelField(v, queue)
+	if q == nil {
+		return nil, false, fmt.Errorf("the runtime's hchan has no %s field in this Go version", queue)
+	}
+	var first *api.Variable
+	for i := range q.Children {
+		if q.Children[i].Name == "first" {
+			first = &q.Children[i]
+		}
+	}
+	if first == nil {
+		return nil, false, fmt.Errorf("the wait queue has no first field in this Go version")
+	}
+	addr, _ := strconv.ParseUint(first.Value, 10, 64)
+
+	var waiters []types.ChannelWaiter
+	seen := make(map[uint64]bool)
+	for addr != 0 && !seen[addr] {
+		if len(waiters) == maxChannelWaiters {
+			return waiters, true, nil
+		}
+		seen[addr] = true
+
+		sudog, err := c.client.EvalVariable(scope, fmt.Sprintf("*(*runtime.sudog)(%#x)", addr), sudogLoadConfig)
+		if err != nil {
+			return waiters, false, fmt.Errorf("failed to read runtime.sudog at %#x: %v", addr, err)
+		}
+		goid, err := c.client.EvalVariable(scope, fmt.Sprintf("(*runtime.sudog)(%#x).g.goid", addr), sudogLoadConfig)
+		if err != nil {
+			return waiters, false, fmt.Errorf("failed to read the goroutine of runtime.sudog at %#x: %v", addr, err)
+		}
+		id, _ := strconv.ParseInt(goid.Value, 10, 64)
+
+		waiter := types.ChannelWaiter{Goroutine: types.Goroutine{ID: id}}
+		if g := goroutines[id]; g != nil {
+			waiter.Goroutine = c.convertGoroutine(g)
+		}
+		addr = 0
+		for _, field := range sudog.Children {
+			switch field.Name {
+			case "isSelect":
+				waiter.Select = field.Value == "true"
+			case "next":
+				addr, _ = strconv.ParseUint(field.Value, 10, 64)
+			}
+		}
+		waiters = append(waiters, waiter)
+	}
+	return waiters, false, nil
+}
+
+// channelDirection returns the direction of a channel type and its element type
+func channelDirection(channelType string) (string, string) {
+	switch {
+	case strings.HasPrefix(channelType, "<-chan "):
+		return "receive", strings.TrimPrefix(channelType, "<-chan ")
+	case strings.HasPrefix(channelType, "chan<- "):
+		return "send", strings.TrimPrefix(channelType, "chan<- ")
+	default:
+		return "both", strings.TrimPrefix(channelType, "chan ")
+	}
+}
+
+// channelSummary describes a channel's state in human terms
+func channelSummary(response *types.InspectChannelResponse) string {
+	if response.State == channelNil {
+		return fmt.Sprintf("nil %s: sends and receives block forever", response.Type)
+	}
+
+	var summary string
+	if response.Cap == 0 {
+		summary = fmt.Sprintf("%s %s, unbuffered", response.State, response.Type)
+	} else {
+		summary = fmt.Sprintf("%s %s, %d of %d buffered", response.State, response.Type, response.Len, response.Cap)
+	}
+	summary += fmt.Sprintf(", %d goroutines blocked sending, %d receiving", len(response.Senders), len(response.Receivers))
+	if response.State == channelClosed {
+		summary += "; receives drain the buffer, then return the zero value"
+	}
+	return summary
+}
+
+// createInspectChannelResponse creates an InspectChannelResponse
+func (c *Client) createInspectChannelResponse(state *api.DebuggerState, expr string, info *channelInfo, err error) types.InspectChannelResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "inspect_channel"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.InspectChannelResponse{
+			Status:     "error",
+			Context:    context,
+			Expression: expr,
+		}
+	}
+
+	direction, elemType := channelDirection(info.v.Type)
+	response := types.InspectChannelResponse{
+		Status:           "success",
+		Context:          context,
+		Expression:       expr,
+		Type:             info.v.Type,
+		ElemType:         elemType,
+		Direction:        direction,
+		State:            info.state,
+		Closed:           info.state == channelClosed,
+		Len:              int64(channelUintField(info.v, "qcount")),
+		Cap:              int64(channelUintField(info.v, "dataqsiz")),
+		Buffered:         info.buffered,
+		Senders:          info.senders,
+		Receivers:        info.receivers,
+		WaitersTruncated: info.truncated,
+		Notes:            info.notes,
+	}
+	if info.truncated {
+		response.Notes = append(response.Notes, fmt.Sprintf("only the first %d goroutines of each wait queue are listed", maxChannelWaiters))
+	}
+	response.Summary = channelSummary(&response)
+	return response
+}
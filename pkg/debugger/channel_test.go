@@ -0,0 +1,201 @@
+package debugger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestChannelDirection(t *testing.T) {
+	testCases := []struct {
+		channelType       string
+		expectedDirection string
+		expectedElemType  string
+	}{
+		{channelType: "chan int", expectedDirection: "both", expectedElemType: "int"},
+		{channelType: "<-chan main.job", expectedDirection: "receive", expectedElemType: "main.job"},
+		{channelType: "chan<- struct {}", expectedDirection: "send", expectedElemType: "struct {}"},
+		{channelType: "chan <-chan int", expectedDirection: "both", expectedElemType: "<-chan int"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.channelType, func(t *testing.T) {
+			direction, elemType := channelDirection(tc.channelType)
+			if direction != tc.expectedDirection || elemType != tc.expectedElemType {
+				t.Errorf("Expected %q and %q, got %q and %q", tc.expectedDirection, tc.expectedElemType, direction, elemType)
+			}
+		})
+	}
+}
+
+// testChannel returns a channel's hchan struct and buffer with 4 slots holding the values
+// 0 to 3, count of which are queued starting at recvx
+func testChannel(count, recvx string, loaded int) (*api.Variable, *api.Variable) {
+	ring := api.Variable{Kind: reflect.Array, Len: 4}
+	for i := 0; i < loaded; i++ {
+		ring.Children = append(ring.Children, api.Variable{Type: "int", Kind: reflect.Int, Value: string(rune('0' + i))})
+	}
+	v := &api.Variable{Type: "chan int", Kind: reflect.Chan, Children: []api.Variable{
+		{Name: "qcount", Value: count},
+		{Name: "dataqsiz", Value: "4"},
+		{Name: "recvx", Value: recvx},
+	}}
+	return v, &api.Variable{Kind: reflect.Ptr, Children: []api.Variable{ring}}
+}
+
+func TestChannelBuffer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		count    string
+		recvx    string
+		loaded   int
+		expected []string
+		ok       bool
+	}{
+		{name: "Empty", count: "0", recvx: "2", loaded: 4, ok: true},
+		{name: "From the start", count: "2", recvx: "0", loaded: 4, expected: []string{"0", "1"}, ok: true},
+		{name: "Wrapping around", count: "3", recvx: "3", loaded: 4, expected: []string{"3", "0", "1"}, ok: true},
+		{name: "Not all loaded", count: "4", recvx: "1", loaded: 3, expected: []string{"1", "2"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, buf := testChannel(tc.count, tc.recvx, tc.loaded)
+			values, ok := channelBuffer(v, buf)
+			if ok != tc.ok {
+				t.Errorf("Expected ok %v, got %v", tc.ok, ok)
+			}
+			var result []string
+			for _, value := range values {
+				result = append(result, value.Value)
+			}
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestCreateInspectChannelResponse(t *testing.T) {
+	waiter := types.ChannelWaiter{Goroutine: types.Goroutine{ID: 7}}
+
+	testCases := []struct {
+		name            string
+		info            *channelInfo
+		expectedSummary string
+	}{
+		{
+			name:            "Nil",
+			info:            &channelInfo{v: &api.Variable{Type: "chan int"}, state: channelNil},
+			expectedSummary: "nil chan int: sends and receives block forever",
+		},
+		{
+			name: "Buffered with blocked senders",
+			info: &channelInfo{
+				v:       &api.Variable{Type: "chan main.job", Children: []api.Variable{{Name: "qcount", Value: "1"}, {Name: "dataqsiz", Value: "1"}}},
+				state:   channelOpen,
+				senders: []types.ChannelWaiter{waiter, waiter},
+			},
+			expectedSummary: "open chan main.job, 1 of 1 buffered, 2 goroutines blocked sending, 0 receiving",
+		},
+		{
+			name:            "Closed",
+			info:            &channelInfo{v: &api.Variable{Type: "<-chan struct {}", Children: []api.Variable{{Name: "qcount", Value: "0"}, {Name: "dataqsiz", Value: "0"}}}, state: channelClosed},
+			expectedSummary: "closed <-chan struct {}, unbuffered, 0 goroutines blocked sending, 0 receiving; receives drain the buffer, then return the zero value",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := NewClient().createInspectChannelResponse(nil, "ch", tc.info, nil)
+			if response.Status != "success" {
+				t.Fatalf("Expected status success, got %q", response.Status)
+			}
+			if response.Summary != tc.expectedSummary {
+				t.Errorf("Expected summary %q, got %q", tc.expectedSummary, response.Summary)
+			}
+			if response.Closed != (tc.info.state == channelClosed) {
+				t.Errorf("Expected closed %v for state %q", !response.Closed, tc.info.state)
+			}
+		})
+	}
+}
+
+func TestInspectChannel(t *testing.T) {
+	field := func(name, value string) api.Variable {
+		return api.Variable{Name: name, Type: "uint", Kind: reflect.Uint, Value: value}
+	}
+	queue := func(name, first string) api.Variable {
+		return api.Variable{Name: name, Type: "runtime.waitq", Kind: reflect.Struct, Children: []api.Variable{
+			{Name: "first", Type: "*runtime.sudog", Kind: reflect.Ptr, Value: first},
+		}}
+	}
+	element := func(value string) api.Variable {
+		return api.Variable{Type: "string", Kind: reflect.String, Value: value, Len: int64(len(value))}
+	}
+	sudog := func(isSelect, next string) *api.Variable {
+		return &api.Variable{Type: "runtime.sudog", Kind: reflect.Struct, Children: []api.Variable{
+			{Name: "isSelect", Type: "bool", Kind: reflect.Bool, Value: isSelect},
+			{Name: "next", Type: "*runtime.sudog", Kind: reflect.Ptr, Value: next},
+		}}
+	}
+
+	// A full channel of capacity 2, whose next value is in slot 1, with two goroutines
+	// blocked sending to it: 7, then 8 in a select
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+		"Eval": fakeEval(t, map[string]*api.Variable{
+			"jobs": {Name: "jobs", Type: "chan string", Kind: reflect.Chan, Children: []api.Variable{
+				field("qcount", "2"), field("dataqsiz", "2"), field("recvx", "1"), field("closed", "0"),
+				queue("recvq", "0"), queue("sendq", "824635817984"),
+			}},
+			"(jobs).buf": {Type: "*[2]string", Kind: reflect.Ptr, Children: []api.Variable{
+				{Type: "[2]string", Kind: reflect.Array, Len: 2, Children: []api.Variable{element("second"), element("first")}},
+			}},
+			"*(*runtime.sudog)(0xc000200000)":       sudog("false", "824635818112"),
+			"(*runtime.sudog)(0xc000200000).g.goid": {Type: "int64", Kind: reflect.Int64, Value: "7"},
+			"*(*runtime.sudog)(0xc000200080)":       sudog("true", "0"),
+			"(*runtime.sudog)(0xc000200080).g.goid": {Type: "int64", Kind: reflect.Int64, Value: "8"},
+			"done":                                  {Name: "done", Type: "chan struct {}", Kind: reflect.Chan},
+			"count":                                 {Name: "count", Type: "int", Kind: reflect.Int, Value: "3"},
+		}),
+		"ListGoroutines": fakeResult(rpc2.ListGoroutinesOut{Goroutines: []*api.Goroutine{
+			{ID: 7, UserCurrentLoc: api.Location{File: "main.go", Line: 30, Function: &api.Function{Name_: "main.produce"}}},
+		}, Nextg: -1}),
+	})
+
+	response := c.InspectChannel("jobs", 0)
+	if response.Status != "success" || response.State != channelOpen || response.Len != 2 || response.Cap != 2 || response.ElemType != "string" {
+		t.Fatalf("Expected an open chan string holding 2 of 2 values, got %+v", response)
+	}
+	var buffered []string
+	for _, v := range response.Buffered {
+		buffered = append(buffered, v.Value)
+	}
+	if !reflect.DeepEqual(buffered, []string{"first", "second"}) {
+		t.Errorf("Expected the buffer in the order it is received, got %v", buffered)
+	}
+	if len(response.Senders) != 2 || response.Senders[0].Goroutine.ID != 7 || response.Senders[0].Select || response.Senders[1].Goroutine.ID != 8 || !response.Senders[1].Select {
+		t.Errorf("Expected goroutines 7, then 8 in a select, blocked sending, got %+v", response.Senders)
+	}
+	if response.Senders[0].Goroutine.Location == nil {
+		t.Errorf("Expected goroutine 7 described from the goroutine list, got %+v", response.Senders[0].Goroutine)
+	}
+	if len(response.Receivers) != 0 || len(response.Notes) != 0 {
+		t.Errorf("Expected no receivers and nothing left unread, got %+v and %v", response.Receivers, response.Notes)
+	}
+	if response.Summary != "open chan string, 2 of 2 buffered, 2 goroutines blocked sending, 0 receiving" {
+		t.Errorf("Unexpected summary %q", response.Summary)
+	}
+
+	if response := c.InspectChannel("done", 0); response.Status != "success" || response.State != channelNil {
+		t.Errorf("Expected done to be a nil channel, got %+v", response)
+	}
+	if response := c.InspectChannel("count", 0); response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "is not a channel") {
+		t.Errorf("Expected count not to be a channel, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+}
@@ -3,37 +3,99 @@ package debugger
 
 import (
 	"bufio"
-	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-delve/delve/service/api"
 	"github.com/go-delve/delve/service/rpc2"
 	"github.com/go-delve/delve/service/rpccommon"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
 	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
 // Client encapsulates the Delve debug client functionality
 type Client struct {
-	client      *rpc2.RPCClient
-	target      string
-	pid         int
-	server      *rpccommon.ServerImpl
-	stdout      bytes.Buffer       // Buffer for captured stdout
-	stderr      bytes.Buffer       // Buffer for captured stderr
-	outputChan  chan OutputMessage // Channel for captured output
-	stopOutput  chan struct{}      // Channel to signal stopping output capture
-	outputMutex sync.Mutex         // Mutex for synchronizing output buffer access
+	client         *rpc2.RPCClient
+	target         string
+	pid            int
+	server         *rpccommon.ServerImpl
+	stdout         *outputBuffer      // Buffer for captured stdout
+	stderr         *outputBuffer      // Buffer for captured stderr
+	outputCaptured bool               // Whether the target's output is redirected to the buffers
+	outputChan     chan OutputMessage // Channel for captured output
+	stopOutput     chan struct{}      // Channel to signal stopping output capture
+	outputMutex    sync.Mutex         // Mutex for synchronizing output buffer access
+	stdin          *stdinPipe         // Pipe the launched target reads its stdin from
+	trace          *traceLog          // Hits recorded by tracepoints
+
+	async      *asyncRun  // Continue started by ContinueAsync, nil when there is none
+	asyncMutex sync.Mutex // Guards async, which WaitForStop reads from other goroutines
+
+	tempBreakpoints     map[int]bool             // IDs of breakpoints set by ContinueToLine and ContinueToMain, removed once hit
+	labelFilters        map[int]*labelFilter     // Goroutine labels breakpoints are scoped to, keyed by ID
+	ignoreCounts        map[int]*ignoreCount     // Hits breakpoints continue past before stopping, keyed by ID
+	goroutinePins       map[int]*goroutinePin    // Breakpoints pinned to the goroutine of their first hit, keyed by ID
+	armedBreakpoints    map[int]*armedBreakpoint // Breakpoints enabled by hits of another, keyed by ID
+	completedStep       *stepCompletion          // Last stop, when a continue stopped to complete an interrupted step
+	coverageBreakpoints map[int]bool             // Tracepoints of a LineCoverage run, whose hits aren't logged
+
+	snapshots map[string]*variableSnapshot // Values saved by SnapshotVariable, keyed by name
+
+	returnBreakpoints map[int]*returnBreakpoint // Functions breakpoints stop at the returns of, keyed by ID
+	errorBreakpoints  map[int]*errorBreakpoint  // Return breakpoints that only stop on returned errors, keyed by ID
+	captureHistories  map[int][]*captureHit     // Values captured by the last hits of breakpoints, keyed by ID
+	hitTimes          *hitTimeLog               // When the last hits of breakpoints happened
+
+	addressOnlyPointers bool // Load pointers as their address only, as set by SetFollowPointers
+
+	waitReasons       []string // The target's waitReason strings, indexed by value, nil when unknown
+	waitReasonsLoaded bool     // Whether waitReasons was looked up for this session
+
+	// Break-on-panic mode set by SetBreakOnPanic
+	panicBreakpoint int  // ID of the runtime.gopanic breakpoint, 0 when not set
+	breakOnPanic    bool // Stop where panics start
+	breakOnFatal    bool // Stop on fatal runtime errors
+	breakOnPanicSet bool // Whether SetBreakOnPanic changed Delve's defaults
+
+	// How the current target was launched, so the session can be restarted
+	launchArgs       []string // Arguments passed to the target
+	launchEnv        []string // Extra KEY=VALUE environment variables
+	launchWorkingDir string   // Working directory of the target
+	buildPkgs        []string // Packages or files the debug binary was built from, if any
+	buildTest        bool     // Whether the debug binary is a test binary
+
+	// Remote sessions connected to a headless Delve server
+	remoteAddr        string                        // Address of the remote server, empty for local sessions
+	keepTarget        bool                          // Leave the remote target running when the session closes
+	remoteConn        atomic.Pointer[monitoredConn] // Connection to the remote server, flagged once it breaks
+	reconnectPolicy   ReconnectPolicy               // How to re-dial the remote server when the connection drops
+	remoteBreakpoints []*api.Breakpoint             // Breakpoints last seen on the remote server, to set again if it loses them
+
+	// Set once the target process exits, until the session ends or is restarted
+	processExited atomic.Bool
+	exitStatus    atomic.Int64
+
+	coreFile       string // Core dump inspected by a read-only session, empty for live sessions
+	coreExecutable string // Executable that produced the core dump
+	backend        string // Delve backend launches use, empty for the default; "rr" records the run
 }
 
 // NewClient creates a new Delve client wrapper
 func NewClient() *Client {
+	bufferSize := getOutputBufferSize()
 	return &Client{
+		stdout:     newOutputBuffer(bufferSize),
+		stderr:     newOutputBuffer(bufferSize),
 		outputChan: make(chan OutputMessage, 100), // Buffer for output messages
 		stopOutput: make(chan struct{}),
+		trace:      &traceLog{},
+		hitTimes:   &hitTimeLog{},
 	}
 }
 
@@ -47,6 +109,11 @@ func (c *Client) GetPid() int {
 	return c.pid
 }
 
+// IsActive reports whether the client has a debug session
+func (c *Client) IsActive() bool {
+	return c.client != nil
+}
+
 // Helper function to get an available port
 func getFreePort() (int, error) {
 	addr, err := net.ResolveTCPAddr("tcp", "localhost:0")
@@ -85,33 +152,49 @@ func waitForStop(c *Client, timeout time.Duration) (*api.DebuggerState, error) {
 	return nil, fmt.Errorf("timeout waiting for program to stop")
 }
 
+// maxOutputLineSize is the longest line captured as one; longer lines are split, so a
+// target that never ends a line doesn't have it held in memory whole
+const maxOutputLineSize = 64 * 1024
+
 // captureOutput reads from a reader and sends the output to the output channel and buffer
-func (c *Client) captureOutput(reader io.ReadCloser, source string) {
+func (c *Client) captureOutput(reader io.ReadCloser, source string, stop <-chan struct{}) {
 	defer func() {
 		_ = reader.Close()
 	}()
 
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		line := scanner.Text()
+	lines := bufio.NewReaderSize(reader, maxOutputLineSize)
+	for {
+		line, _, err := lines.ReadLine()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrClosed) {
+				logger.Warn("Stopped capturing output", "source", source, "error", err)
+			}
+			return
+		}
+
+		msg := OutputMessage{
+			Source:    source,
+			Content:   string(line),
+			Timestamp: time.Now(),
+		}
 
 		// Write to appropriate buffer
+		c.outputMutex.Lock()
 		switch source {
 		case "stdout":
-			c.stdout.WriteString(line + "\n")
+			c.stdout.append(msg)
 		case "stderr":
-			c.stderr.WriteString(line + "\n")
+			c.stderr.append(msg)
 		}
+		c.outputMutex.Unlock()
 
-		// Also send to channel for real-time monitoring
+		// Also send to channel for real-time monitoring, without blocking the target
+		// when nobody is listening
 		select {
-		case <-c.stopOutput:
+		case <-stop:
 			return
-		case c.outputChan <- OutputMessage{
-			Source:    source,
-			Content:   line,
-			Timestamp: time.Now(),
-		}:
+		case c.outputChan <- msg:
+		default:
 		}
 	}
 }
@@ -128,12 +211,28 @@ func (c *Client) createDebugContext(state *api.DebuggerState) types.DebugContext
 
 		// Add current position
 		if state.CurrentThread != nil {
-			loc := getCurrentLocation(state)
-			context.CurrentLocation = loc
+			context.Position = getCurrentPosition(state)
+			context.CurrentLocation = formatPosition(context.Position)
 		}
 
+		c.noteExit(state, nil)
+
 		// Add stop reason
-		context.StopReason = getStateReason(state)
+		context.Stop = getStopDetail(state)
+		if c != nil && context.Stop.Kind == types.StopBreakpoint {
+			context.Stop.PinnedGoroutine = c.pinnedGoroutine(context.Stop.BreakpointID)
+			c.annotateArmingStop(context.Stop)
+		}
+		if c != nil && context.Stop.Kind == types.StopStopped && c.completesStep(state) {
+			context.Stop = &types.StopDetail{Kind: types.StopInternal, InternalKind: stepBreakpointKind}
+		}
+		context.StopReason = formatStopDetail(context.Stop)
+
+		context.Captured, context.CaptureErrors = getCapturedValues(state.CurrentThread)
+		if c != nil {
+			context.ReturnValues = c.returnBreakpointValues(state.CurrentThread)
+			context.ReturnedError = c.returnedError(state.CurrentThread)
+		}
 
 		// Get local variables if we have a client
 		if c != nil {
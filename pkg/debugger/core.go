@@ -0,0 +1,343 @@
+package debugger
+
+import (
+	"bufio"
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/debugger"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/go-delve/delve/service/rpccommon"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// coreLoadTimeout bounds how long loading a core dump may take
+const coreLoadTimeout = 30 * time.Second
+
+// ELF note types read from executables and core files
+const (
+	ntPrstatus  = 1 // NT_PRSTATUS, the status of a thread in a core file
+	ntGoBuildID = 4 // Go build ID note of an executable
+)
+
+// OpenCore loads a core dump of executable for post-mortem inspection. The session is
+// read-only: goroutines, stacks and variables can be inspected, but the program cannot be
+// run, stepped or changed. The core is checked against the executable's Go build ID, and
+// the signal that produced it is reported as the stop reason.
+func (c *Client) OpenCore(executable, corePath string) types.CoreResponse {
+	if c.client != nil {
+		return c.createCoreResponse(nil, executable, corePath, 0, fmt.Errorf("debug session already active"))
+	}
+
+	absExecutable, err := filepath.Abs(executable)
+	if err != nil {
+		return c.createCoreResponse(nil, executable, corePath, 0, fmt.Errorf("failed to get absolute path: %v", err))
+	}
+	absCore, err := filepath.Abs(corePath)
+	if err != nil {
+		return c.createCoreResponse(nil, executable, corePath, 0, fmt.Errorf("failed to get absolute path: %v", err))
+	}
+
+	if _, err := os.Stat(absExecutable); err != nil {
+		return c.createCoreResponse(nil, executable, corePath, 0, fmt.Errorf("executable not found: %s", absExecutable))
+	}
+	if _, err := os.Stat(absCore); err != nil {
+		return c.createCoreResponse(nil, executable, corePath, 0, fmt.Errorf("core file not found: %s", absCore))
+	}
+
+	if err := checkCoreMatchesExecutable(absExecutable, absCore); err != nil {
+		return c.createCoreResponse(nil, executable, corePath, 0, err)
+	}
+
+	signal, err := coreSignal(absCore)
+	if err != nil {
+		logger.Debug("Warning: Failed to read the signal of core %s: %v", absCore, err)
+	}
+
+	logger.Debug("Opening core %s of %s", absCore, absExecutable)
+
+	port, err := getFreePort()
+	if err != nil {
+		return c.createCoreResponse(nil, executable, corePath, 0, fmt.Errorf("failed to find available port: %v", err))
+	}
+
+	// Configure Delve logging
+	_ = logflags.Setup(false, "", "")
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		return c.createCoreResponse(nil, executable, corePath, 0, fmt.Errorf("couldn't start listener: %s", err))
+	}
+
+	config := &service.Config{
+		Listener:    listener,
+		APIVersion:  2,
+		AcceptMulti: true,
+		ProcessArgs: []string{absExecutable},
+		Debugger: debugger.Config{
+			CoreFile:       absCore,
+			Backend:        "default",
+			CheckGoVersion: true,
+		},
+	}
+
+	server := rpccommon.NewServer(config)
+	if server == nil {
+		return c.createCoreResponse(nil, executable, corePath, 0, fmt.Errorf("failed to create debug server"))
+	}
+
+	// Run returns once the core is loaded and the server accepts connections, so wait for
+	// it before connecting, as a client connecting to a server that failed would hang
+	serverReady := make(chan error, 1)
+	go func() {
+		serverReady <- server.Run()
+	}()
+
+	select {
+	case err := <-serverReady:
+		if err != nil {
+			return c.createCoreResponse(nil, executable, corePath, 0, fmt.Errorf("failed to load core %s: %v", absCore, err))
+		}
+	case <-time.After(coreLoadTimeout):
+		return c.createCoreResponse(nil, executable, corePath, 0, fmt.Errorf("timed out after %v waiting for debug server to load the core", coreLoadTimeout))
+	}
+
+	client := rpc2.NewClient(listener.Addr().String())
+	state, err := client.GetState()
+	if err != nil {
+		return c.createCoreResponse(nil, executable, corePath, 0, fmt.Errorf("loaded core %s but failed to get its state: %v", absCore, err))
+	}
+
+	c.client = client
+	c.server = server
+	c.pid = state.Pid
+	c.coreFile = absCore
+	c.coreExecutable = absExecutable
+
+	logger.Debug("Loaded core %s, produced by signal %d", absCore, signal)
+	return c.createCoreResponse(state, absExecutable, absCore, signal, nil)
+}
+
+// ReadOnly reports whether the session inspects a core dump, which has no live process
+// to run or change
+func (c *Client) ReadOnly() bool {
+	return c.coreFile != ""
+}
+
+// CoreFile returns the core dump the session inspects, or "" for live sessions
+func (c *Client) CoreFile() string {
+	return c.coreFile
+}
+
+// checkCoreMatchesExecutable checks that a core was produced by a process running the
+// executable, by looking for the executable's Go build ID in the memory the core holds.
+// The kernel dumps the first page of mapped ELF files, which holds the build ID note.
+// Executables without a Go build ID, and files other than ELF such as Windows minidumps,
+// are left to Delve to check.
+func checkCoreMatchesExecutable(executable, corePath string) error {
+	exe, err := elf.Open(executable)
+	if err != nil {
+		logger.Debug("Executable %s is not an ELF file, not checking it against the core: %v", executable, err)
+		return nil
+	}
+	defer exe.Close()
+
+	core, err := elf.Open(corePath)
+	if err != nil {
+		logger.Debug("Core %s is not an ELF file, not checking it against the executable: %v", corePath, err)
+		return nil
+	}
+	defer core.Close()
+
+	if core.Type != elf.ET_CORE {
+		return fmt.Errorf("%s is not a core file", corePath)
+	}
+
+	section := exe.Section(".note.go.buildid")
+	if section == nil {
+		logger.Debug("Executable %s has no Go build ID, not checking it against the core", executable)
+		return nil
+	}
+	data, err := section.Data()
+	if err != nil {
+		return fmt.Errorf("cannot read the build ID of %s: %v", executable, err)
+	}
+
+	var buildID []byte
+	for _, note := range parseELFNotes(data, exe.ByteOrder) {
+		if note.name == "Go" && note.typ == ntGoBuildID {
+			buildID = note.desc
+		}
+	}
+	if len(buildID) == 0 {
+		return nil
+	}
+
+	f, err := os.Open(corePath)
+	if err != nil {
+		return fmt.Errorf("cannot read core file %s: %v", corePath, err)
+	}
+	defer f.Close()
+
+	found, err := readerContains(f, buildID)
+	if err != nil {
+		return fmt.Errorf("cannot read core file %s: %v", corePath, err)
+	}
+	if !found {
+		return fmt.Errorf("core file %s does not match %s: the executable's build ID %s is not in the core", corePath, executable, buildID)
+	}
+	return nil
+}
+
+// coreSignal returns the signal that made the kernel dump a core, read from the status
+// of the first thread, which is the one that received it
+func coreSignal(corePath string) (int, error) {
+	core, err := elf.Open(corePath)
+	if err != nil {
+		return 0, err
+	}
+	defer core.Close()
+
+	for _, prog := range core.Progs {
+		if prog.Type != elf.PT_NOTE {
+			continue
+		}
+		data, err := io.ReadAll(prog.Open())
+		if err != nil {
+			return 0, err
+		}
+		for _, note := range parseELFNotes(data, core.ByteOrder) {
+			if note.typ == ntPrstatus && note.name == "CORE" {
+				return prstatusSignal(note.desc, core.ByteOrder), nil
+			}
+		}
+	}
+	return 0, errors.New("no thread status in core file")
+}
+
+// prstatusSignal reads the signal from an NT_PRSTATUS note: pr_cursig follows the three
+// ints of pr_info, whose first one is si_signo
+func prstatusSignal(desc []byte, order binary.ByteOrder) int {
+	if len(desc) >= 14 {
+		if sig := int(order.Uint16(desc[12:14])); sig != 0 {
+			return sig
+		}
+	}
+	if len(desc) >= 4 {
+		return int(order.Uint32(desc[0:4]))
+	}
+	return 0
+}
+
+// elfNote is a note of an ELF note section or segment
+type elfNote struct {
+	name string
+	typ  uint32
+	desc []byte
+}
+
+// parseELFNotes splits the contents of a note section or segment into notes
+func parseELFNotes(data []byte, order binary.ByteOrder) []elfNote {
+	align4 := func(n uint32) uint32 { return (n + 3) &^ 3 }
+
+	var notes []elfNote
+	for len(data) >= 12 {
+		namesz := order.Uint32(data[0:4])
+		descsz := order.Uint32(data[4:8])
+		typ := order.Uint32(data[8:12])
+		data = data[12:]
+
+		if uint64(align4(namesz))+uint64(align4(descsz)) > uint64(len(data)) {
+			break
+		}
+		name := string(bytes.TrimRight(data[:namesz], "\x00"))
+		data = data[align4(namesz):]
+		desc := data[:descsz]
+		data = data[align4(descsz):]
+
+		notes = append(notes, elfNote{name: name, typ: typ, desc: desc})
+	}
+	return notes
+}
+
+// readerContains reports whether pattern occurs in r, reading it in chunks so large
+// core files are not loaded into memory at once
+func readerContains(r io.Reader, pattern []byte) (bool, error) {
+	if len(pattern) == 0 {
+		return true, nil
+	}
+
+	const chunkSize = 1 << 20
+	br := bufio.NewReaderSize(r, chunkSize)
+	buf := make([]byte, 0, chunkSize+len(pattern))
+	chunk := make([]byte, chunkSize)
+	for {
+		n, err := br.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+		if bytes.Contains(buf, pattern) {
+			return true, nil
+		}
+		// Keep the tail, in case the pattern spans two chunks
+		if keep := len(pattern) - 1; len(buf) > keep {
+			buf = append(buf[:0], buf[len(buf)-keep:]...)
+		}
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// formatCoreStopReason describes the signal that produced a core
+func formatCoreStopReason(signal int) string {
+	if signal == 0 {
+		return "core dump, signal unknown"
+	}
+	reason := fmt.Sprintf("core dump produced by signal %d (%s)", signal, syscall.Signal(signal))
+	if syscall.Signal(signal) == syscall.SIGABRT {
+		reason += "; Go programs abort this way after a panic or fatal error when GOTRACEBACK=crash"
+	}
+	return reason
+}
+
+// createCoreResponse creates a CoreResponse
+func (c *Client) createCoreResponse(state *api.DebuggerState, executable, corePath string, signal int, err error) types.CoreResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "open_core"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.CoreResponse{
+			Status:     "error",
+			Context:    context,
+			Executable: executable,
+			CoreFile:   corePath,
+		}
+	}
+
+	context.StopReason = formatCoreStopReason(signal)
+	return types.CoreResponse{
+		Status:     "success",
+		Context:    context,
+		Executable: executable,
+		CoreFile:   corePath,
+		Pid:        state.Pid,
+		Signal:     signal,
+		ReadOnly:   true,
+	}
+}
@@ -0,0 +1,98 @@
+package debugger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// elfNoteBytes encodes a note the way it is stored in a note section or segment
+func elfNoteBytes(name string, typ uint32, desc []byte) []byte {
+	pad := func(b []byte) []byte {
+		for len(b)%4 != 0 {
+			b = append(b, 0)
+		}
+		return b
+	}
+
+	var buf bytes.Buffer
+	nameBytes := append([]byte(name), 0)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(nameBytes)))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(desc)))
+	_ = binary.Write(&buf, binary.LittleEndian, typ)
+	buf.Write(pad(nameBytes))
+	buf.Write(pad(append([]byte(nil), desc...)))
+	return buf.Bytes()
+}
+
+func TestParseELFNotes(t *testing.T) {
+	data := append(elfNoteBytes("Go", ntGoBuildID, []byte("abc/def")), elfNoteBytes("CORE", ntPrstatus, []byte{1, 2, 3, 4, 5})...)
+
+	notes := parseELFNotes(data, binary.LittleEndian)
+	if len(notes) != 2 {
+		t.Fatalf("Expected 2 notes, got %d", len(notes))
+	}
+	if notes[0].name != "Go" || notes[0].typ != ntGoBuildID || string(notes[0].desc) != "abc/def" {
+		t.Errorf("Unexpected first note: %+v", notes[0])
+	}
+	if notes[1].name != "CORE" || notes[1].typ != ntPrstatus || len(notes[1].desc) != 5 {
+		t.Errorf("Unexpected second note: %+v", notes[1])
+	}
+
+	// A truncated note is dropped instead of read past the end
+	if notes := parseELFNotes(data[:len(data)-4], binary.LittleEndian); len(notes) != 1 {
+		t.Errorf("Expected 1 note from truncated data, got %d", len(notes))
+	}
+}
+
+func TestPrstatusSignal(t *testing.T) {
+	testCases := []struct {
+		name     string
+		signo    uint32
+		cursig   uint16
+		expected int
+	}{
+		{name: "Current signal", signo: 11, cursig: 11, expected: 11},
+		{name: "Current signal differs", signo: 6, cursig: 11, expected: 11},
+		{name: "Only si_signo", signo: 6, cursig: 0, expected: 6},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			desc := make([]byte, 32)
+			binary.LittleEndian.PutUint32(desc[0:4], tc.signo)
+			binary.LittleEndian.PutUint16(desc[12:14], tc.cursig)
+			if result := prstatusSignal(desc, binary.LittleEndian); result != tc.expected {
+				t.Errorf("Expected %d, got %d", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestReaderContains(t *testing.T) {
+	data := strings.Repeat("x", 5000) + "build-id" + strings.Repeat("y", 5000)
+
+	testCases := []struct {
+		name     string
+		pattern  string
+		expected bool
+	}{
+		{name: "Present", pattern: "build-id", expected: true},
+		{name: "Absent", pattern: "other-id", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Reading a byte at a time makes the pattern span reads
+			found, err := readerContains(iotest.OneByteReader(strings.NewReader(data)), []byte(tc.pattern))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if found != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+const (
+	deadlockStackDepth  = 30 // Frames inspected per blocked goroutine
+	contentionThreshold = 3  // Goroutines blocked on one primitive before it counts as a hotspot
+)
+
+// blockingFunctions maps runtime and sync functions a goroutine parks in to the kind of
+// operation it is blocked on
+var blockingFunctions = map[string]string{
+	"runtime.chanrecv":                "chan receive",
+	"runtime.chanrecv1":               "chan receive",
+	"runtime.chanrecv2":               "chan receive",
+	"runtime.chansend":                "chan send",
+	"runtime.chansend1":               "chan send",
+	"runtime.selectgo":                "select",
+	"runtime.block":                   "select (no cases)",
+	"internal/sync.(*Mutex).lockSlow": "mutex",
+	"internal/sync.(*Mutex).Lock":     "mutex",
+	"sync.(*Mutex).lockSlow":          "mutex",
+	"sync.(*Mutex).Lock":              "mutex",
+	"sync.(*RWMutex).Lock":            "rwmutex lock",
+	"sync.(*RWMutex).RLock":           "rwmutex rlock",
+	"sync.(*WaitGroup).Wait":          "waitgroup",
+	"sync.(*Cond).Wait":               "cond",
+}
+
+// blockedGoroutine is a goroutine parked on a channel or sync primitive
+type blockedGoroutine struct {
+	id       int64
+	kind     string          // Kind of operation, e.g. "mutex" or "chan receive"
+	addr     uint64          // Address of the primitive, 0 when unknown (e.g. select)
+	location *string         // User code location of the blocking call
+	refs     map[uint64]bool // Addresses referenced by the goroutine's own frames
+}
+
+// DetectDeadlock looks for goroutines waiting on each other in a cycle, and otherwise for
+// primitives that many goroutines are blocked on
+func (c *Client) DetectDeadlock() types.DeadlockResponse {
+	if c.client == nil {
+		return c.createDeadlockResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createDeadlockResponse(nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createDeadlockResponse(nil, nil, fmt.Errorf("cannot inspect goroutines while the target is running; stop the target first"))
+	}
+
+	gs, _, err := c.client.ListGoroutines(0, 0)
+	if err != nil {
+		return c.createDeadlockResponse(state, nil, fmt.Errorf("failed to list goroutines: %v", err))
+	}
+
+	// Arguments are needed to find the primitive's address, locals to find what each goroutine references
+	cfg := &api.LoadConfig{
+		FollowPointers:     true,
+		MaxVariableRecurse: 2,
+		MaxStringLen:       0,
+		MaxArrayValues:     0,
+		MaxStructFields:    -1,
+	}
+
+	var blocked []*blockedGoroutine
+	for _, g := range gs {
+		if getGoroutineStatus(g) != "waiting" {
+			continue
+		}
+
+		frames, err := c.client.Stacktrace(g.ID, deadlockStackDepth, 0, cfg)
+		if err != nil {
+			logger.Debug("Warning: Failed to get stack trace for goroutine %d: %v", g.ID, err)
+			continue
+		}
+
+		kind, addr, index := classifyBlockingFrames(frames)
+		if kind == "" {
+			continue
+		}
+
+		b := &blockedGoroutine{
+			id:       g.ID,
+			kind:     kind,
+			addr:     addr,
+			location: getGoroutineLocation(g),
+			refs:     make(map[uint64]bool),
+		}
+		if index+1 < len(frames) {
+			b.location = getFrameLocation(frames[index+1])
+		}
+		for _, frame := range frames[index+1:] {
+			for i := range frame.Arguments {
+				collectAddresses(&frame.Arguments[i], b.refs, cfg.MaxVariableRecurse+1)
+			}
+			for i := range frame.Locals {
+				collectAddresses(&frame.Locals[i], b.refs, cfg.MaxVariableRecurse+1)
+			}
+		}
+		blocked = append(blocked, b)
+	}
+
+	logger.Debug("Found %d blocked goroutines out of %d", len(blocked), len(gs))
+	return c.createDeadlockResponse(state, blocked, nil)
+}
+
+// classifyBlockingFrames finds what a goroutine is blocked on from its innermost frames.
+// It returns the kind of operation, the primitive's address when known, and the index of
+// the outermost frame belonging to the blocking call, or an empty kind if not blocked.
+func classifyBlockingFrames(frames []api.Stackframe) (string, uint64, int) {
+	kind := ""
+	var addr uint64
+	index := -1
+
+	for i, frame := range frames {
+		function := getFunctionNameFromLocation(frame.Location)
+		if k, ok := blockingFunctions[function]; ok {
+			kind = k
+			index = i
+			// Outer frames like sync.(*RWMutex).Lock take precedence over the Mutex they use
+			if a := primitiveAddress(frame); a != 0 {
+				addr = a
+			}
+			continue
+		}
+		if !isRuntimeFunction(function) {
+			break
+		}
+	}
+
+	return kind, addr, index
+}
+
+// primitiveAddress returns the address of the channel or sync value a blocking frame operates on
+func primitiveAddress(frame api.Stackframe) uint64 {
+	if len(frame.Arguments) == 0 {
+		return 0
+	}
+
+	arg := frame.Arguments[0]
+	switch arg.Kind {
+	case reflect.Ptr:
+		if len(arg.Children) > 0 {
+			return arg.Children[0].Addr
+		}
+	case reflect.Chan:
+		return arg.Base
+	}
+	return 0
+}
+
+// collectAddresses records the addresses of a variable and its loaded children
+func collectAddresses(v *api.Variable, refs map[uint64]bool, depth int) {
+	if v.Addr != 0 {
+		refs[v.Addr] = true
+	}
+	if v.Kind == reflect.Chan && v.Base != 0 {
+		refs[v.Base] = true
+	}
+	if depth <= 0 {
+		return
+	}
+	for i := range v.Children {
+		collectAddresses(&v.Children[i], refs, depth-1)
+	}
+}
+
+// buildWaitForGraph links each blocked goroutine to the blocked goroutines that reference its
+// primitive, which are the likely holders of the lock or the missing channel peers
+func buildWaitForGraph(blocked []*blockedGoroutine) map[int64][]int64 {
+	graph := make(map[int64][]int64)
+	for _, waiter := range blocked {
+		if waiter.addr == 0 {
+			continue
+		}
+		for _, holder := range blocked {
+			if holder.id == waiter.id || holder.addr == waiter.addr {
+				continue
+			}
+			if holder.refs[waiter.addr] {
+				graph[waiter.id] = append(graph[waiter.id], holder.id)
+			}
+		}
+	}
+	return graph
+}
+
+// findWaitCycle returns the goroutine IDs of the first cycle found in a wait-for graph, or nil
+func findWaitCycle(graph map[int64][]int64) []int64 {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	ids := make([]int64, 0, len(graph))
+	for id := range graph {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	color := make(map[int64]int)
+	var path []int64
+	var visit func(id int64) []int64
+	visit = func(id int64) []int64 {
+		color[id] = visiting
+		path = append(path, id)
+		for _, next := range graph[id] {
+			switch color[next] {
+			case visiting:
+				for i, p := range path {
+					if p == next {
+						return append([]int64(nil), path[i:]...)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		color[id] = done
+		return nil
+	}
+
+	for _, id := range ids {
+		if color[id] == unvisited {
+			if cycle := visit(id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// findContentionHotspots groups blocked goroutines by primitive and keeps the crowded ones
+func findContentionHotspots(blocked []*blockedGoroutine) []types.ContentionHotspot {
+	groups := make(map[uint64][]*blockedGoroutine)
+	for _, b := range blocked {
+		if b.addr != 0 {
+			groups[b.addr] = append(groups[b.addr], b)
+		}
+	}
+
+	var hotspots []types.ContentionHotspot
+	for addr, group := range groups {
+		if len(group) < contentionThreshold {
+			continue
+		}
+		ids := make([]int64, 0, len(group))
+		for _, b := range group {
+			ids = append(ids, b.id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		hotspots = append(hotspots, types.ContentionHotspot{
+			BlockedOn:  group[0].kind,
+			Resource:   fmt.Sprintf("%#x", addr),
+			Location:   group[0].location,
+			Count:      len(group),
+			Goroutines: ids,
+		})
+	}
+
+	sort.Slice(hotspots, func(i, j int) bool {
+		if hotspots[i].Count != hotspots[j].Count {
+			return hotspots[i].Count > hotspots[j].Count
+		}
+		return hotspots[i].Resource < hotspots[j].Resource
+	})
+	return hotspots
+}
+
+// createDeadlockResponse creates a DeadlockResponse from the blocked goroutines
+func (c *Client) createDeadlockResponse(state *api.DebuggerState, blocked []*blockedGoroutine, err error) types.DeadlockResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "detect_deadlock"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.DeadlockResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	response := types.DeadlockResponse{
+		Status:       "success",
+		Context:      context,
+		BlockedCount: len(blocked),
+	}
+
+	byID := make(map[int64]*blockedGoroutine, len(blocked))
+	for _, b := range blocked {
+		byID[b.id] = b
+	}
+
+	if cycle := findWaitCycle(buildWaitForGraph(blocked)); cycle != nil {
+		response.Deadlocked = true
+		parts := make([]string, 0, len(cycle))
+		for i, id := range cycle {
+			b := byID[id]
+			response.Cycle = append(response.Cycle, types.BlockedGoroutine{
+				ID:        id,
+				BlockedOn: b.kind,
+				Resource:  fmt.Sprintf("%#x", b.addr),
+				Location:  b.location,
+				WaitsFor:  cycle[(i+1)%len(cycle)],
+			})
+			parts = append(parts, fmt.Sprintf("goroutine %d", id))
+		}
+		parts = append(parts, fmt.Sprintf("goroutine %d", cycle[0]))
+		response.Summary = fmt.Sprintf("Suspected deadlock: %s", strings.Join(parts, " -> "))
+		return response
+	}
+
+	response.Hotspots = findContentionHotspots(blocked)
+	if len(response.Hotspots) > 0 {
+		top := response.Hotspots[0]
+		response.Summary = fmt.Sprintf("No wait cycle found; %d goroutines are blocked on %s %s", top.Count, top.BlockedOn, top.Resource)
+		if top.Location != nil {
+			response.Summary += fmt.Sprintf(" at %s", *top.Location)
+		}
+		return response
+	}
+
+	response.Summary = fmt.Sprintf("No deadlock detected; %d goroutines blocked on channels or locks", len(blocked))
+	return response
+}
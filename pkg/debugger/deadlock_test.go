@@ -0,0 +1,132 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestClassifyBlockingFrames(t *testing.T) {
+	frame := func(function string, args ...api.Variable) api.Stackframe {
+		return api.Stackframe{
+			Location:  api.Location{Function: &api.Function{Name_: function}},
+			Arguments: args,
+		}
+	}
+	mutexPtr := func(addr uint64) api.Variable {
+		return api.Variable{Kind: reflect.Ptr, Children: []api.Variable{{Addr: addr}}}
+	}
+
+	testCases := []struct {
+		name   string
+		frames []api.Stackframe
+		kind   string
+		addr   uint64
+		index  int
+	}{
+		{
+			name: "Mutex lock",
+			frames: []api.Stackframe{
+				frame("runtime.gopark"),
+				frame("internal/sync.(*Mutex).lockSlow", mutexPtr(0x10)),
+				frame("internal/sync.(*Mutex).Lock", mutexPtr(0x10)),
+				frame("sync.(*Mutex).Lock", mutexPtr(0x10)),
+				frame("main.(*Server).handle"),
+			},
+			kind:  "mutex",
+			addr:  0x10,
+			index: 3,
+		},
+		{
+			name: "RWMutex lock uses the outer primitive",
+			frames: []api.Stackframe{
+				frame("sync.(*Mutex).Lock", mutexPtr(0x20)),
+				frame("sync.(*RWMutex).Lock", mutexPtr(0x28)),
+				frame("main.update"),
+			},
+			kind:  "rwmutex lock",
+			addr:  0x28,
+			index: 1,
+		},
+		{
+			name: "Channel receive",
+			frames: []api.Stackframe{
+				frame("runtime.gopark"),
+				frame("runtime.chanrecv", mutexPtr(0x30)),
+				frame("runtime.chanrecv1", mutexPtr(0x30)),
+				frame("main.worker"),
+			},
+			kind:  "chan receive",
+			addr:  0x30,
+			index: 2,
+		},
+		{
+			name: "Not blocked on a primitive",
+			frames: []api.Stackframe{
+				frame("runtime.gopark"),
+				frame("time.Sleep"),
+				frame("main.main"),
+			},
+			kind:  "",
+			index: -1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kind, addr, index := classifyBlockingFrames(tc.frames)
+			if kind != tc.kind || addr != tc.addr || index != tc.index {
+				t.Errorf("Expected (%q, %#x, %d), got (%q, %#x, %d)", tc.kind, tc.addr, tc.index, kind, addr, index)
+			}
+		})
+	}
+}
+
+func TestFindWaitCycle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		blocked  []*blockedGoroutine
+		expected []int64
+	}{
+		{
+			name: "Lock order inversion",
+			blocked: []*blockedGoroutine{
+				{id: 1, kind: "mutex", addr: 0xb, refs: map[uint64]bool{0xa: true, 0xb: true}},
+				{id: 2, kind: "mutex", addr: 0xa, refs: map[uint64]bool{0xa: true, 0xb: true}},
+			},
+			expected: []int64{1, 2},
+		},
+		{
+			name: "Waiters on the same lock do not wait on each other",
+			blocked: []*blockedGoroutine{
+				{id: 1, kind: "mutex", addr: 0xa, refs: map[uint64]bool{0xa: true}},
+				{id: 2, kind: "mutex", addr: 0xa, refs: map[uint64]bool{0xa: true}},
+				{id: 3, kind: "mutex", addr: 0xa, refs: map[uint64]bool{0xa: true}},
+			},
+			expected: nil,
+		},
+		{
+			name: "Chain without a cycle",
+			blocked: []*blockedGoroutine{
+				{id: 1, kind: "chan receive", addr: 0xc, refs: map[uint64]bool{}},
+				{id: 2, kind: "select", refs: map[uint64]bool{0xc: true}},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cycle := findWaitCycle(buildWaitForGraph(tc.blocked))
+			if !reflect.DeepEqual(cycle, tc.expected) {
+				t.Errorf("Expected cycle %v, got %v", tc.expected, cycle)
+			}
+		})
+	}
+
+	hotspots := findContentionHotspots(testCases[1].blocked)
+	if len(hotspots) != 1 || hotspots[0].Count != 3 {
+		t.Errorf("Expected one hotspot with 3 goroutines, got %+v", hotspots)
+	}
+}
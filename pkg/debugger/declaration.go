@@ -0,0 +1,322 @@
+package debugger
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// DeclarationOf finds where the variable or struct field an expression names is declared.
+// Arguments and locals are found from the declaration line in the debug info, which Delve
+// reads relative to the file of the frame. The compiler records no declaration line for
+// package variables or struct fields, so those are found by parsing the source files of
+// the package that declares them. The declaring line is returned with the location.
+func (c *Client) DeclarationOf(expr string, frame int) types.DeclarationResponse {
+	if c.client == nil {
+		return c.createDeclarationResponse(nil, expr, frame, types.Declaration{}, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createDeclarationResponse(nil, expr, frame, types.Declaration{}, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createDeclarationResponse(nil, expr, frame, types.Declaration{}, fmt.Errorf("cannot look up declarations while the target is running; stop the target first"))
+	}
+
+	scope, err := c.frameScope(state, frame)
+	if err != nil {
+		return c.createDeclarationResponse(state, expr, frame, types.Declaration{}, err)
+	}
+
+	node, err := parser.ParseExpr(expr)
+	if err != nil {
+		return c.createDeclarationResponse(state, expr, frame, types.Declaration{}, fmt.Errorf("invalid expression %q: %v", expr, err))
+	}
+
+	logger.Debug("Looking up the declaration of %q in frame %d", expr, frame)
+	var decl types.Declaration
+	switch node := node.(type) {
+	case *ast.Ident:
+		decl, err = c.identDeclaration(scope, node.Name)
+	case *ast.SelectorExpr:
+		decl, err = c.selectorDeclaration(scope, expr, node)
+	default:
+		err = fmt.Errorf("cannot find the declaration of %q; give a variable or a field, such as x or s.items", expr)
+	}
+	if err != nil {
+		return c.createDeclarationResponse(state, expr, frame, decl, err)
+	}
+
+	decl.Location = fmt.Sprintf("%s:%d", decl.File, decl.Line)
+	if source := c.ListSource(decl.File, decl.Line, 0); source.Status == "success" {
+		decl.Text = strings.TrimSpace(source.Lines[0].Text)
+	} else {
+		logger.Debug("Warning: Failed to read the declaring line of %q: %s", expr, source.Context.ErrorMessage)
+	}
+	return c.createDeclarationResponse(state, expr, frame, decl, nil)
+}
+
+// identDeclaration finds the declaration of a name: the argument or local it refers to in
+// the scope, or else the package variable
+func (c *Client) identDeclaration(scope api.EvalScope, name string) (types.Declaration, error) {
+	args, err := c.client.ListFunctionArgs(scope, whatIsLoadConfig)
+	if err != nil {
+		return types.Declaration{}, fmt.Errorf("failed to list function arguments: %v", err)
+	}
+	locals, err := c.client.ListLocalVariables(scope, whatIsLoadConfig)
+	if err != nil {
+		return types.Declaration{}, fmt.Errorf("failed to list local variables: %v", err)
+	}
+
+	frames, err := c.client.Stacktrace(scope.GoroutineID, scope.Frame, 0, nil)
+	if err != nil || scope.Frame >= len(frames) {
+		return types.Declaration{}, fmt.Errorf("failed to get frame %d: %v", scope.Frame, err)
+	}
+	frame := frames[scope.Frame]
+
+	kind := "argument"
+	v := visibleVariable(args, name)
+	if local := visibleVariable(locals, name); local != nil {
+		kind, v = "local", local
+	}
+	if v != nil {
+		decl := types.Declaration{Kind: kind, Name: name, Type: v.Type}
+		if v.DeclLine <= 0 {
+			return decl, fmt.Errorf("the debug info of %s %s has no declaration line", kind, name)
+		}
+		decl.File, decl.Line = frame.File, int(v.DeclLine)
+		return decl, nil
+	}
+
+	// Not a local, so a package variable of the frame's package
+	global, err := c.client.EvalVariable(scope, name, whatIsLoadConfig)
+	if err != nil {
+		if isUnresolvedSymbol(err) {
+			return types.Declaration{}, fmt.Errorf("could not resolve %q: %v; variables in scope: %s", name, err, c.scopeVariableNames(scope))
+		}
+		return types.Declaration{}, fmt.Errorf("failed to evaluate %q: %v", name, err)
+	}
+	if frame.Function != nil && !strings.Contains(global.Name, ".") {
+		global.Name = functionPackage(frame.Function.Name()) + "." + global.Name
+	}
+	return c.packageVariableDeclaration(global)
+}
+
+// visibleVariable returns the variable of a name that is not shadowed by another, if any
+func visibleVariable(vars []api.Variable, name string) *api.Variable {
+	for i := range vars {
+		if vars[i].Name == name && vars[i].Flags&api.VariableShadowed == 0 {
+			return &vars[i]
+		}
+	}
+	return nil
+}
+
+// selectorDeclaration finds the declaration of x.f: the field f in the definition of the
+// type of x, or, when x is a package rather than a value, the package variable x.f. Delve
+// resolves the name of the main package to its main function, which has no fields either.
+func (c *Client) selectorDeclaration(scope api.EvalScope, expr string, node *ast.SelectorExpr) (types.Declaration, error) {
+	parentExpr := expr[node.X.Pos()-1 : node.X.End()-1]
+	parent, err := c.client.EvalVariable(scope, parentExpr, whatIsLoadConfig)
+	if err != nil || parent.Kind == reflect.Func {
+		global, globalErr := c.client.EvalVariable(scope, expr, whatIsLoadConfig)
+		if globalErr != nil {
+			if err == nil {
+				err = globalErr
+			}
+			return types.Declaration{}, fmt.Errorf("failed to evaluate %q: %v", parentExpr, err)
+		}
+		return c.packageVariableDeclaration(global)
+	}
+
+	field := node.Sel.Name
+	typeName := strings.TrimLeft(parent.Type, "*")
+	decl := types.Declaration{Kind: "field", Name: field, DeclaringType: typeName}
+	v, err := c.client.EvalVariable(scope, expr, whatIsLoadConfig)
+	if err != nil {
+		return decl, fmt.Errorf("failed to evaluate %q: %v", expr, err)
+	}
+	decl.Type = v.Type
+
+	pkg, name := splitQualifiedName(typeName)
+	if pkg == "" {
+		return decl, fmt.Errorf("%s has type %s, which is not a named type with a definition to look in", parentExpr, parent.Type)
+	}
+	file, line, err := c.findPackageDeclaration(pkg, func(spec ast.Spec) ast.Node {
+		return structFieldDeclaration(spec, name, field)
+	})
+	if err != nil {
+		return decl, err
+	}
+	if line == 0 {
+		return decl, fmt.Errorf("field %s is not declared in the definition of %s; it may be promoted from an embedded field", field, typeName)
+	}
+	decl.File, decl.Line = file, line
+	return decl, nil
+}
+
+// packageVariableDeclaration finds where a package variable Delve evaluated is declared
+func (c *Client) packageVariableDeclaration(v *api.Variable) (types.Declaration, error) {
+	decl := types.Declaration{Kind: "package variable", Name: v.Name, Type: v.Type}
+	pkg, name := splitQualifiedName(v.Name)
+	if pkg == "" {
+		return decl, fmt.Errorf("%s is not an argument, local or package variable", v.Name)
+	}
+	file, line, err := c.findPackageDeclaration(pkg, func(spec ast.Spec) ast.Node {
+		return valueDeclaration(spec, name)
+	})
+	if err != nil {
+		return decl, err
+	}
+	if line == 0 {
+		return decl, fmt.Errorf("no declaration of %s found in the source of package %s", name, pkg)
+	}
+	decl.File, decl.Line = file, line
+	return decl, nil
+}
+
+// splitQualifiedName splits a name such as "github.com/a/b.T" or "main.store[int]" into the
+// import path of the package and the name within it. A name without a package, such as a
+// builtin or unnamed type, gives an empty package.
+func splitQualifiedName(qualified string) (string, string) {
+	if i := strings.Index(qualified, "["); i >= 0 {
+		qualified = qualified[:i]
+	}
+	if strings.ContainsAny(qualified, " {(") {
+		return "", qualified
+	}
+	pkg := functionPackage(qualified)
+	if pkg == "" {
+		return "", qualified
+	}
+	return pkg, qualified[len(pkg)+1:]
+}
+
+// findPackageDeclaration parses the source files of a package and returns the position of
+// the first node that match finds among the specs of its top-level declarations. A line
+// of 0 means nothing matched.
+func (c *Client) findPackageDeclaration(pkg string, match func(ast.Spec) ast.Node) (string, int, error) {
+	packages, err := c.client.ListPackagesBuildInfo("", true)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to list packages of the target: %v", err)
+	}
+
+	var files []string
+	for _, p := range packages {
+		if p.ImportPath == pkg {
+			files = p.Files
+			break
+		}
+	}
+	if len(files) == 0 {
+		return "", 0, fmt.Errorf("the source files of package %s are not known", pkg)
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		parsed, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			logger.Debug("Warning: Failed to parse %s: %v", file, err)
+			continue
+		}
+		for _, d := range parsed.Decls {
+			gen, ok := d.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if node := match(spec); node != nil {
+					return file, fset.Position(node.Pos()).Line, nil
+				}
+			}
+		}
+	}
+	return "", 0, nil
+}
+
+// valueDeclaration returns the name a var or const spec declares name with, if it does
+func valueDeclaration(spec ast.Spec, name string) ast.Node {
+	value, ok := spec.(*ast.ValueSpec)
+	if !ok {
+		return nil
+	}
+	for _, ident := range value.Names {
+		if ident.Name == name {
+			return ident
+		}
+	}
+	return nil
+}
+
+// structFieldDeclaration returns the name field is declared with in the struct type typeName
+// that spec defines, if it does. An embedded field is named after its type.
+func structFieldDeclaration(spec ast.Spec, typeName, field string) ast.Node {
+	typeSpec, ok := spec.(*ast.TypeSpec)
+	if !ok || typeSpec.Name.Name != typeName {
+		return nil
+	}
+	structType, ok := typeSpec.Type.(*ast.StructType)
+	if !ok {
+		return nil
+	}
+	for _, f := range structType.Fields.List {
+		if len(f.Names) == 0 && embeddedFieldName(f.Type) == field {
+			return f.Type
+		}
+		for _, ident := range f.Names {
+			if ident.Name == field {
+				return ident
+			}
+		}
+	}
+	return nil
+}
+
+// embeddedFieldName returns the name of an embedded field, which is that of its type
+func embeddedFieldName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.StarExpr:
+		return embeddedFieldName(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedFieldName(expr.X)
+	case *ast.IndexListExpr:
+		return embeddedFieldName(expr.X)
+	}
+	return ""
+}
+
+// createDeclarationResponse creates a DeclarationResponse
+func (c *Client) createDeclarationResponse(state *api.DebuggerState, expr string, frame int, decl types.Declaration, err error) types.DeclarationResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "declaration_of"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.DeclarationResponse{
+			Status:     "error",
+			Context:    context,
+			Expression: expr,
+			Frame:      frame,
+		}
+	}
+
+	return types.DeclarationResponse{
+		Status:      "success",
+		Context:     context,
+		Expression:  expr,
+		Frame:       frame,
+		Declaration: decl,
+	}
+}
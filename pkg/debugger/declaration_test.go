@@ -0,0 +1,222 @@
+package debugger
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+func TestSplitQualifiedName(t *testing.T) {
+	testCases := []struct {
+		name      string
+		qualified string
+		pkg       string
+		local     string
+	}{
+		{name: "Main package", qualified: "main.store", pkg: "main", local: "store"},
+		{name: "Import path", qualified: "github.com/a/b.Config", pkg: "github.com/a/b", local: "Config"},
+		{name: "Generic", qualified: "main.list[github.com/a/b.T]", pkg: "main", local: "list"},
+		{name: "Builtin", qualified: "int", local: "int"},
+		{name: "Unnamed struct", qualified: "struct { a main.T }", local: "struct { a main.T }"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pkg, local := splitQualifiedName(tc.qualified)
+			if pkg != tc.pkg || local != tc.local {
+				t.Errorf("Expected %q and %q, got %q and %q", tc.pkg, tc.local, pkg, local)
+			}
+		})
+	}
+}
+
+func TestFindDeclarationInSpecs(t *testing.T) {
+	const source = `package main
+
+var (
+	count, limit int
+)
+
+type store struct {
+	sync.Mutex
+	*Logger
+	items []string
+	a, b  int
+}
+
+type id int
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", source, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatalf("Failed to parse source: %v", err)
+	}
+	find := func(match func(ast.Spec) ast.Node) int {
+		for _, d := range file.Decls {
+			for _, spec := range d.(*ast.GenDecl).Specs {
+				if node := match(spec); node != nil {
+					return fset.Position(node.Pos()).Line
+				}
+			}
+		}
+		return 0
+	}
+
+	testCases := []struct {
+		name     string
+		match    func(ast.Spec) ast.Node
+		expected int
+	}{
+		{name: "Variable", match: func(s ast.Spec) ast.Node { return valueDeclaration(s, "limit") }, expected: 4},
+		{name: "Field", match: func(s ast.Spec) ast.Node { return structFieldDeclaration(s, "store", "items") }, expected: 10},
+		{name: "Field declared with another", match: func(s ast.Spec) ast.Node { return structFieldDeclaration(s, "store", "b") }, expected: 11},
+		{name: "Embedded field", match: func(s ast.Spec) ast.Node { return structFieldDeclaration(s, "store", "Mutex") }, expected: 8},
+		{name: "Embedded pointer", match: func(s ast.Spec) ast.Node { return structFieldDeclaration(s, "store", "Logger") }, expected: 9},
+		{name: "Missing field", match: func(s ast.Spec) ast.Node { return structFieldDeclaration(s, "store", "nope") }},
+		{name: "Not a struct", match: func(s ast.Spec) ast.Node { return structFieldDeclaration(s, "id", "x") }},
+		{name: "Missing variable", match: func(s ast.Spec) ast.Node { return valueDeclaration(s, "store") }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if line := find(tc.match); line != tc.expected {
+				t.Errorf("Expected line %d, got %d", tc.expected, line)
+			}
+		})
+	}
+}
+
+func TestVisibleVariable(t *testing.T) {
+	vars := []api.Variable{
+		{Name: "err", DeclLine: 10, Flags: api.VariableShadowed},
+		{Name: "n", DeclLine: 11},
+		{Name: "err", DeclLine: 14},
+	}
+	if v := visibleVariable(vars, "err"); v == nil || v.DeclLine != 14 {
+		t.Errorf("Expected the err declared on line 14, got %+v", v)
+	}
+	if v := visibleVariable(vars, "missing"); v != nil {
+		t.Errorf("Expected no variable, got %+v", v)
+	}
+}
+
+// declarationSource is the source file of the main package of declarationTarget
+const declarationSource = `package main
+
+var requests int
+
+type Server struct {
+	Name  string
+	limit int
+}
+
+func handle(s *Server, n int) {
+	total := n
+	_ = total
+}
+`
+
+// declarationTarget returns a fake Delve stopped in main.handle of declarationSource, with
+// the argument n, the locals total and undeclared, and the package variable requests
+func declarationTarget(t *testing.T) *Client {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(file, []byte(declarationSource), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State":      fakeState(stoppedState(12)),
+		"Stacktrace": fakeResult(rpc2.StacktraceOut{Locations: []api.Stackframe{{Location: api.Location{File: file, Line: 12, Function: &api.Function{Name_: "main.handle"}}}}}),
+		"ListFunctionArgs": fakeResult(rpc2.ListFunctionArgsOut{Args: []api.Variable{
+			{Name: "s", Type: "*main.Server", Kind: reflect.Ptr, DeclLine: 10},
+			{Name: "n", Type: "int", Kind: reflect.Int, DeclLine: 10},
+		}}),
+		"ListLocalVars": fakeResult(rpc2.ListLocalVarsOut{Variables: []api.Variable{
+			{Name: "total", Type: "int", Kind: reflect.Int, DeclLine: 11},
+			{Name: "undeclared", Type: "int", Kind: reflect.Int},
+		}}),
+		"Eval": fakeEval(t, map[string]*api.Variable{
+			"requests": {Name: "requests", Type: "int", Kind: reflect.Int},
+			"s":        {Name: "s", Type: "*main.Server", Kind: reflect.Ptr},
+			"s.limit":  {Name: "limit", Type: "int", Kind: reflect.Int},
+			"s.Port":   {Name: "Port", Type: "int", Kind: reflect.Int},
+		}),
+		"ListPackagesBuildInfo": fakeResult(rpc2.ListPackagesBuildInfoOut{List: []api.PackageBuildInfo{
+			{ImportPath: "main", DirectoryPath: filepath.Dir(file), Files: []string{file}},
+		}}),
+	})
+	return c
+}
+
+func TestDeclarationOf(t *testing.T) {
+	c := declarationTarget(t)
+
+	testCases := []struct {
+		name string
+		expr string
+		kind string
+		line int
+		text string
+	}{
+		{name: "Argument", expr: "n", kind: "argument", line: 10, text: "func handle(s *Server, n int) {"},
+		{name: "Local", expr: "total", kind: "local", line: 11, text: "total := n"},
+		{name: "Package variable", expr: "requests", kind: "package variable", line: 3, text: "var requests int"},
+		{name: "Field", expr: "s.limit", kind: "field", line: 7, text: "limit int"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.DeclarationOf(tc.expr, 0)
+			if response.Status != "success" {
+				t.Fatalf("Expected the declaration of %s, got %s", tc.expr, response.Context.ErrorMessage)
+			}
+			decl := response.Declaration
+			if decl.Kind != tc.kind || decl.Line != tc.line || !strings.HasSuffix(decl.Location, fmt.Sprintf("main.go:%d", tc.line)) {
+				t.Errorf("Expected the %s declared at main.go:%d, got %+v", tc.kind, tc.line, decl)
+			}
+			if decl.Text != tc.text {
+				t.Errorf("Expected the declaring line %q, got %q", tc.text, decl.Text)
+			}
+		})
+	}
+}
+
+func TestDeclarationOfErrors(t *testing.T) {
+	c := declarationTarget(t)
+
+	testCases := []struct {
+		name     string
+		expr     string
+		expected string
+	}{
+		{name: "No declaration line", expr: "undeclared", expected: "has no declaration line"},
+		{name: "Field not in the definition", expr: "s.Port", expected: "field Port is not declared in the definition of main.Server"},
+		{name: "Unresolved", expr: "count", expected: "variables in scope: n, s, total, undeclared"},
+		{name: "Not a variable", expr: "n + 1", expected: "give a variable or a field"},
+		{name: "Invalid expression", expr: "n +", expected: "invalid expression"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.DeclarationOf(tc.expr, 0)
+			if response.Status != "error" || response.Context.Operation != "declaration_of" || !strings.Contains(response.Context.ErrorMessage, tc.expected) {
+				t.Errorf("Expected an error containing %q, got %+v", tc.expected, response.Context)
+			}
+		})
+	}
+
+	response := NewClient().DeclarationOf("x", 0)
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "no active debug session") {
+		t.Errorf("Expected a no active debug session error, got %q", response.Context.ErrorMessage)
+	}
+}
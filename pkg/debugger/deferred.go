@@ -0,0 +1,120 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// ListDeferred returns the calls deferred by a frame of the selected goroutine, in the
+// order they will run: the most recently deferred call first. A frame without defers
+// gives an empty list.
+func (c *Client) ListDeferred(frame int) types.DeferredCallsResponse {
+	if c.client == nil {
+		return c.createDeferredCallsResponse(nil, frame, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createDeferredCallsResponse(nil, frame, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createDeferredCallsResponse(nil, frame, nil, fmt.Errorf("cannot list deferred calls while the target is running; stop the target first"))
+	}
+
+	if state.SelectedGoroutine == nil {
+		return c.createDeferredCallsResponse(state, frame, nil, fmt.Errorf("no goroutine selected"))
+	}
+
+	if frame < 0 {
+		return c.createDeferredCallsResponse(state, frame, nil, fmt.Errorf("frame must not be negative"))
+	}
+
+	goroutineID := state.SelectedGoroutine.ID
+	logger.Debug("Listing deferred calls of frame %d of goroutine %d", frame, goroutineID)
+
+	frames, err := c.client.Stacktrace(goroutineID, frame, api.StacktraceReadDefers, nil)
+	if err != nil {
+		return c.createDeferredCallsResponse(state, frame, nil, fmt.Errorf("failed to get stack trace: %v", err))
+	}
+	if frame >= len(frames) {
+		return c.createDeferredCallsResponse(state, frame, nil, fmt.Errorf("frame %d out of range; the stack has %d frames", frame, len(frames)))
+	}
+
+	cfg := api.LoadConfig{
+		FollowPointers:     true,
+		MaxVariableRecurse: 1,
+		MaxStringLen:       64,
+		MaxArrayValues:     10,
+		MaxStructFields:    -1,
+	}
+
+	defers := frames[frame].Defers
+	calls := make([]types.DeferredCall, 0, len(defers))
+	for i, d := range defers {
+		call := convertDeferredCall(i+1, d)
+		if d.Unreadable == "" {
+			// Delve numbers the deferred calls of a frame from 1, in the order they run
+			scope := api.EvalScope{GoroutineID: goroutineID, Frame: frame, DeferredCall: i + 1}
+			args, err := c.client.ListFunctionArgs(scope, cfg)
+			if err != nil {
+				logger.Debug("Warning: Failed to read the arguments of deferred call %d: %v", i+1, err)
+			}
+			for j := range args {
+				call.Arguments = append(call.Arguments, convertVariableTree(&args[j], "argument", 1))
+			}
+		}
+		calls = append(calls, call)
+	}
+
+	return c.createDeferredCallsResponse(state, frame, calls, nil)
+}
+
+// convertDeferredCall converts a Delve deferred call to our type
+func convertDeferredCall(index int, d api.Defer) types.DeferredCall {
+	call := types.DeferredCall{
+		Index: index,
+		Error: d.Unreadable,
+	}
+	if d.Unreadable != "" {
+		return call
+	}
+
+	call.Function = getFunctionNameFromLocation(d.DeferredLoc)
+	call.Position = &types.SourcePosition{
+		File:     d.DeferredLoc.File,
+		Line:     d.DeferredLoc.Line,
+		Function: call.Function,
+	}
+	call.DeferPosition = &types.SourcePosition{
+		File:     d.DeferLoc.File,
+		Line:     d.DeferLoc.Line,
+		Function: getFunctionNameFromLocation(d.DeferLoc),
+	}
+	call.DeferredAt = formatPosition(call.DeferPosition)
+	return call
+}
+
+// createDeferredCallsResponse creates a DeferredCallsResponse
+func (c *Client) createDeferredCallsResponse(state *api.DebuggerState, frame int, calls []types.DeferredCall, err error) types.DeferredCallsResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "list_deferred"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.DeferredCallsResponse{
+			Status:  "error",
+			Context: context,
+			Frame:   frame,
+		}
+	}
+
+	return types.DeferredCallsResponse{
+		Status:  "success",
+		Context: context,
+		Frame:   frame,
+		Defers:  calls,
+	}
+}
@@ -0,0 +1,29 @@
+package debugger
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestConvertDeferredCall(t *testing.T) {
+	call := convertDeferredCall(1, api.Defer{
+		DeferredLoc: api.Location{File: "/src/main.go", Line: 30, Function: &api.Function{Name_: "main.cleanup"}},
+		DeferLoc:    api.Location{File: "/src/main.go", Line: 12, Function: &api.Function{Name_: "main.run"}},
+	})
+
+	if call.Index != 1 {
+		t.Errorf("Expected index 1, got %d", call.Index)
+	}
+	if call.Function != "main.cleanup" {
+		t.Errorf("Expected function main.cleanup, got %s", call.Function)
+	}
+	if call.DeferredAt == nil || *call.DeferredAt != "At /src/main.go:12 in main.run" {
+		t.Errorf("Expected deferred at /src/main.go:12 in main.run, got %v", call.DeferredAt)
+	}
+
+	unreadable := convertDeferredCall(2, api.Defer{Unreadable: "could not read defer"})
+	if unreadable.Error != "could not read defer" || unreadable.Position != nil {
+		t.Errorf("Expected an unreadable deferred call without a position, got %+v", unreadable)
+	}
+}
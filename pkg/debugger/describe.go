@@ -0,0 +1,102 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// DescribeOptions selects the sections Describe returns and how large they are
+type DescribeOptions struct {
+	Stack        bool // Include the innermost frames of the selected goroutine
+	StackDepth   int  // How many frames to include
+	Source       bool // Include the source lines around the current line
+	ContextLines int  // How many lines to show before and after the current line
+	Locals       bool // Include the local variables of the current frame
+	LocalsDepth  int  // How many levels of nested values to expand
+}
+
+// Describe sums up where the program is stopped in one response: the current location,
+// the top of the stack, the source around the current line and the local variables.
+// A section that can't be read is reported in SectionErrors without failing the others.
+func (c *Client) Describe(opts DescribeOptions) types.DescribeResponse {
+	if c.client == nil {
+		return c.createDescribeResponse(nil, types.DescribeResponse{}, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createDescribeResponse(nil, types.DescribeResponse{}, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createDescribeResponse(nil, types.DescribeResponse{}, fmt.Errorf("cannot describe the program while the target is running; stop the target first"))
+	}
+
+	if state.Exited {
+		return c.createDescribeResponse(state, types.DescribeResponse{}, fmt.Errorf("the program has exited with status %d", state.ExitStatus))
+	}
+
+	logger.Debug("Describing the current state")
+
+	var description types.DescribeResponse
+	sectionError := func(section, message string) {
+		if description.SectionErrors == nil {
+			description.SectionErrors = make(map[string]string)
+		}
+		description.SectionErrors[section] = message
+	}
+
+	if opts.Stack {
+		backtrace := c.Backtrace(0, opts.StackDepth, false, nil)
+		if backtrace.Status == "success" {
+			description.Frames = backtrace.Frames
+		} else {
+			sectionError("stack", backtrace.Context.ErrorMessage)
+		}
+	}
+
+	if opts.Source {
+		source := c.ListSource("", 0, opts.ContextLines)
+		if source.Status == "success" {
+			description.Source = source.Lines
+			description.Listing = source.Listing
+		} else {
+			sectionError("source", source.Context.ErrorMessage)
+		}
+	}
+
+	if opts.Locals {
+		locals := c.ListLocals(0, VariableListOptions{Depth: opts.LocalsDepth})
+		if locals.Status == "success" {
+			description.Locals = locals.Variables
+		} else {
+			sectionError("locals", locals.Context.ErrorMessage)
+		}
+	}
+
+	return c.createDescribeResponse(state, description, nil)
+}
+
+// createDescribeResponse creates a DescribeResponse from the sections that were read
+func (c *Client) createDescribeResponse(state *api.DebuggerState, description types.DescribeResponse, err error) types.DescribeResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "describe"
+	// The locals have a section of their own
+	context.LocalVariables = nil
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.DescribeResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	description.Status = "success"
+	description.Context = context
+	description.Location = getCurrentLocation(state)
+	return description
+}
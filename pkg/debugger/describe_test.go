@@ -0,0 +1,206 @@
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+// describeTarget returns a fake Delve stopped at line 10 of a 20-line source file, whose
+// stack is three frames deep and whose only local is a struct holding another. The depths
+// of the stack traces and of the locals asked for are recorded.
+func describeTarget(t *testing.T) (*Client, *fakeDelve, *[]int, *[]int) {
+	t.Helper()
+	var source strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&source, "line %d\n", i)
+	}
+	file := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(file, []byte(source.String()), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	frames := []api.Stackframe{
+		{Location: api.Location{File: file, Line: 10, Function: &api.Function{Name_: "main.handle"}}},
+		{Location: api.Location{File: file, Line: 4, Function: &api.Function{Name_: "main.serve"}}},
+		{Location: api.Location{File: file, Line: 2, Function: &api.Function{Name_: "main.main"}}},
+	}
+	locals := []api.Variable{{Name: "req", Type: "main.Request", Kind: reflect.Struct, Children: []api.Variable{
+		{Name: "Header", Type: "main.Header", Kind: reflect.Struct, Children: []api.Variable{
+			{Name: "Host", Type: "string", Kind: reflect.String, Value: "example.com", Len: 11},
+		}},
+	}}}
+
+	var stackDepths, localDepths []int
+	c, f := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(&api.DebuggerState{
+			CurrentThread:     &api.Thread{ID: 1, File: file, Line: 10, Function: &api.Function{Name_: "main.handle"}, GoroutineID: 1},
+			SelectedGoroutine: &api.Goroutine{ID: 1},
+		}),
+		"Stacktrace": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.StacktraceIn
+			decodeFakeArgs(t, raw, &args)
+			stackDepths = append(stackDepths, args.Depth)
+			n := args.Depth + 1
+			if n > len(frames) {
+				n = len(frames)
+			}
+			return rpc2.StacktraceOut{Locations: frames[:n]}, nil
+		},
+		"ListLocalVars": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.ListLocalVarsIn
+			decodeFakeArgs(t, raw, &args)
+			localDepths = append(localDepths, args.Cfg.MaxVariableRecurse)
+			return rpc2.ListLocalVarsOut{Variables: locals}, nil
+		},
+	})
+	return c, f, &stackDepths, &localDepths
+}
+
+func TestDescribeSections(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    DescribeOptions
+		stack   bool
+		source  bool
+		locals  bool
+		delveOf []string // Delve methods the sections asked for call
+	}{
+		{
+			name:    "Every section",
+			opts:    DescribeOptions{Stack: true, Source: true, Locals: true},
+			stack:   true,
+			source:  true,
+			locals:  true,
+			delveOf: []string{"Stacktrace", "ListLocalVars"},
+		},
+		{
+			name:   "Source only",
+			opts:   DescribeOptions{Source: true},
+			source: true,
+		},
+		{
+			name:    "Stack only",
+			opts:    DescribeOptions{Stack: true},
+			stack:   true,
+			delveOf: []string{"Stacktrace"},
+		},
+		{
+			name:   "Locals only",
+			opts:   DescribeOptions{Locals: true},
+			locals: true,
+			// Listing the locals checks that the current frame exists
+			delveOf: []string{"Stacktrace", "ListLocalVars"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, f, _, _ := describeTarget(t)
+			response := c.Describe(tc.opts)
+			if response.Status != "success" || len(response.SectionErrors) > 0 {
+				t.Fatalf("Expected a description, got %s: %s %v", response.Status, response.Context.ErrorMessage, response.SectionErrors)
+			}
+			if response.Location == nil || !strings.Contains(*response.Location, "main.go:10") {
+				t.Errorf("Expected the location main.go:10 in every description, got %v", response.Location)
+			}
+
+			if got := len(response.Frames) > 0; got != tc.stack {
+				t.Errorf("Expected stack section %v, got %d frames", tc.stack, len(response.Frames))
+			}
+			if got := len(response.Source) > 0; got != tc.source {
+				t.Errorf("Expected source section %v, got %d lines", tc.source, len(response.Source))
+			}
+			if got := len(response.Locals) > 0; got != tc.locals {
+				t.Errorf("Expected locals section %v, got %d locals", tc.locals, len(response.Locals))
+			}
+			if response.Context.LocalVariables != nil {
+				t.Errorf("Expected the locals only in their own section, got %v in the context", response.Context.LocalVariables)
+			}
+
+			// Sections left out aren't read from Delve at all
+			for _, method := range []string{"Stacktrace", "ListLocalVars"} {
+				wanted := false
+				for _, m := range tc.delveOf {
+					wanted = wanted || m == method
+				}
+				if called := f.called(method) > 0; called != wanted {
+					t.Errorf("Expected %s called %v, got %d calls", method, wanted, f.called(method))
+				}
+			}
+		})
+	}
+}
+
+func TestDescribeLimits(t *testing.T) {
+	c, _, stackDepths, localDepths := describeTarget(t)
+	response := c.Describe(DescribeOptions{Stack: true, StackDepth: 1, Source: true, ContextLines: 2, Locals: true, LocalsDepth: 1})
+	if response.Status != "success" {
+		t.Fatalf("Expected a description, got %s", response.Context.ErrorMessage)
+	}
+
+	// The frame check of the locals asks for the current frame only
+	if !reflect.DeepEqual(*stackDepths, []int{1, 0}) {
+		t.Errorf("Expected a stack of depth 1, then the current frame for the locals, got depths %v", *stackDepths)
+	}
+	if len(response.Frames) != 2 || response.Frames[1].Function != "main.serve" {
+		t.Errorf("Expected the two innermost frames, got %+v", response.Frames)
+	}
+
+	var numbers []int
+	for _, line := range response.Source {
+		numbers = append(numbers, line.Number)
+		if line.Current != (line.Number == 10) {
+			t.Errorf("Expected only line 10 marked current, got line %d current %v", line.Number, line.Current)
+		}
+	}
+	if !reflect.DeepEqual(numbers, []int{8, 9, 10, 11, 12}) {
+		t.Errorf("Expected 2 lines around line 10, got %v", numbers)
+	}
+	if !strings.Contains(response.Listing, "=>    10: line 10") {
+		t.Errorf("Expected the listing to mark line 10, got:\n%s", response.Listing)
+	}
+
+	if !reflect.DeepEqual(*localDepths, []int{1}) {
+		t.Errorf("Expected the locals loaded 1 level deep, got %v", *localDepths)
+	}
+	if len(response.Locals) != 1 || len(response.Locals[0].Children) != 1 || len(response.Locals[0].Children[0].Children) != 0 {
+		t.Errorf("Expected req expanded 1 level, to its Header field only, got %+v", response.Locals)
+	}
+}
+
+func TestDescribeSectionErrors(t *testing.T) {
+	c, f, _, _ := describeTarget(t)
+	f.handle("ListLocalVars", func(json.RawMessage) (interface{}, error) {
+		return nil, fmt.Errorf("could not read variables")
+	})
+
+	response := c.Describe(DescribeOptions{Stack: true, Source: true, Locals: true})
+	if response.Status != "success" {
+		t.Fatalf("Expected a section that can't be read not to fail the others, got %s", response.Context.ErrorMessage)
+	}
+	if len(response.Frames) == 0 || len(response.Source) == 0 {
+		t.Errorf("Expected the stack and source sections, got %d frames and %d lines", len(response.Frames), len(response.Source))
+	}
+	if message := response.SectionErrors["locals"]; !strings.Contains(message, "could not read variables") {
+		t.Errorf("Expected the locals error reported in its section, got %v", response.SectionErrors)
+	}
+}
+
+func TestDescribeRunningTarget(t *testing.T) {
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(&api.DebuggerState{Running: true}),
+	})
+
+	response := c.Describe(DescribeOptions{Stack: true, Source: true, Locals: true})
+	if response.Status != "error" || response.Context.Operation != "describe" || !strings.Contains(response.Context.ErrorMessage, "target is running") {
+		t.Errorf("Expected describe to refuse a running target, got %+v", response)
+	}
+}
@@ -0,0 +1,167 @@
+package debugger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// Disassemble returns the machine instructions between startPC and endPC. When startPC is 0
+// the function containing the PC of the given frame is disassembled, and when endPC is 0 the
+// whole function containing startPC is. A sourceLine above 0 keeps only the instructions
+// generated for that line.
+func (c *Client) Disassemble(frame int, startPC, endPC uint64, sourceLine int) types.DisassembleResponse {
+	if c.client == nil {
+		return c.createDisassembleResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createDisassembleResponse(nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createDisassembleResponse(nil, nil, fmt.Errorf("cannot disassemble while the target is running; stop the target first"))
+	}
+
+	scope := api.EvalScope{GoroutineID: -1, Frame: frame}
+
+	// Default to the function the selected frame is executing
+	var sourceFile string
+	if startPC == 0 {
+		if state.SelectedGoroutine == nil {
+			return c.createDisassembleResponse(state, nil, fmt.Errorf("no goroutine selected, specify a PC range"))
+		}
+		frames, err := c.client.Stacktrace(state.SelectedGoroutine.ID, frame+1, 0, nil)
+		if err != nil {
+			return c.createDisassembleResponse(state, nil, fmt.Errorf("failed to get stack trace: %v", err))
+		}
+		if frame < 0 || frame >= len(frames) {
+			return c.createDisassembleResponse(state, nil, fmt.Errorf("frame %d does not exist", frame))
+		}
+		startPC = frames[frame].PC
+		sourceFile = frames[frame].File
+		endPC = 0
+	}
+
+	logger.Debug("Disassembling %#x-%#x in frame %d", startPC, endPC, frame)
+
+	var instructions api.AsmInstructions
+	if endPC == 0 {
+		instructions, err = c.client.DisassemblePC(scope, startPC, api.IntelFlavour)
+	} else {
+		if endPC <= startPC {
+			return c.createDisassembleResponse(state, nil, fmt.Errorf("end PC %#x must be greater than start PC %#x", endPC, startPC))
+		}
+		instructions, err = c.client.DisassembleRange(scope, startPC, endPC, api.IntelFlavour)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "no function at address") || strings.Contains(err.Error(), "could not find symbol") {
+			return c.createDisassembleResponse(state, nil, fmt.Errorf("no symbol information for address %#x; the binary may be stripped of debug info", startPC))
+		}
+		return c.createDisassembleResponse(state, nil, fmt.Errorf("failed to disassemble: %v", err))
+	}
+
+	if len(instructions) > 0 && !hasSymbolInformation(instructions) {
+		return c.createDisassembleResponse(state, nil, fmt.Errorf("no symbol information for address %#x; the binary may be stripped of debug info", startPC))
+	}
+
+	result := make([]types.Instruction, 0, len(instructions))
+	for _, inst := range instructions {
+		if sourceLine > 0 && (inst.Loc.Line != sourceLine || (sourceFile != "" && inst.Loc.File != sourceFile)) {
+			continue
+		}
+		result = append(result, convertInstruction(inst))
+	}
+
+	if sourceLine > 0 && len(result) == 0 {
+		return c.createDisassembleResponse(state, nil, fmt.Errorf("no instructions for line %d in the disassembled range", sourceLine))
+	}
+
+	return c.createDisassembleResponse(state, result, nil)
+}
+
+// hasSymbolInformation reports whether any instruction maps back to a function or source line
+func hasSymbolInformation(instructions api.AsmInstructions) bool {
+	for _, inst := range instructions {
+		if inst.Loc.Function != nil || inst.Loc.File != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// convertInstruction converts a Delve instruction to our type
+func convertInstruction(inst api.AsmInstruction) types.Instruction {
+	instruction := types.Instruction{
+		Address:    fmt.Sprintf("%#x", inst.Loc.PC),
+		Opcode:     fmt.Sprintf("%x", inst.Bytes),
+		Text:       inst.Text,
+		File:       inst.Loc.File,
+		Line:       inst.Loc.Line,
+		Function:   getFunctionNameFromLocation(inst.Loc),
+		Current:    inst.AtPC,
+		Breakpoint: inst.Breakpoint,
+	}
+
+	if inst.DestLoc != nil && inst.DestLoc.Function != nil {
+		instruction.CallTarget = inst.DestLoc.Function.Name()
+	}
+
+	return instruction
+}
+
+// formatDisassembly renders instructions one per line, with the current one marked with "=>"
+func formatDisassembly(instructions []types.Instruction) string {
+	var b strings.Builder
+	lastLine, lastFile := 0, ""
+	for _, inst := range instructions {
+		// Print the source position whenever it changes, like Delve's disassemble command
+		if inst.Line != lastLine || inst.File != lastFile {
+			fmt.Fprintf(&b, "%s:%d\n", inst.File, inst.Line)
+			lastLine, lastFile = inst.Line, inst.File
+		}
+
+		marker := "  "
+		if inst.Current {
+			marker = "=>"
+		}
+		fmt.Fprintf(&b, "%s %s\t%s", marker, inst.Address, inst.Text)
+		if inst.CallTarget != "" {
+			fmt.Fprintf(&b, "\t; %s", inst.CallTarget)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// createDisassembleResponse creates a DisassembleResponse
+func (c *Client) createDisassembleResponse(state *api.DebuggerState, instructions []types.Instruction, err error) types.DisassembleResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "disassemble"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.DisassembleResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	response := types.DisassembleResponse{
+		Status:       "success",
+		Context:      context,
+		Instructions: instructions,
+		Listing:      formatDisassembly(instructions),
+	}
+
+	if len(instructions) > 0 {
+		response.Function = instructions[0].Function
+		response.StartPC = instructions[0].Address
+		response.EndPC = instructions[len(instructions)-1].Address
+	}
+
+	return response
+}
@@ -0,0 +1,25 @@
+package debugger
+
+import (
+	"testing"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestFormatDisassembly(t *testing.T) {
+	instructions := []types.Instruction{
+		{Address: "0x10", Text: "mov rax, 1", File: "main.go", Line: 5},
+		{Address: "0x17", Text: "call $main.add", File: "main.go", Line: 5, Current: true, CallTarget: "main.add"},
+		{Address: "0x1c", Text: "ret", File: "main.go", Line: 6},
+	}
+
+	expected := "main.go:5\n" +
+		"   0x10\tmov rax, 1\n" +
+		"=> 0x17\tcall $main.add\t; main.add\n" +
+		"main.go:6\n" +
+		"   0x1c\tret\n"
+
+	if listing := formatDisassembly(instructions); listing != expected {
+		t.Errorf("Expected listing:\n%s\ngot:\n%s", expected, listing)
+	}
+}
@@ -0,0 +1,178 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// containerLoadConfig loads a container's length and capacity but none of its elements
+var containerLoadConfig = api.LoadConfig{
+	MaxVariableRecurse: 0,
+	MaxStringLen:       0,
+	MaxArrayValues:     0,
+	MaxStructFields:    0,
+}
+
+// containerKinds are the kinds of value elements are evaluated in
+var containerKinds = map[reflect.Kind]bool{
+	reflect.Slice:  true,
+	reflect.Array:  true,
+	reflect.String: true,
+	reflect.Map:    true,
+}
+
+// elementSelector picks an element out of a container, returning its expression
+type elementSelector func(expr string, container *api.Variable) (string, error)
+
+// GetElement evaluates one element of a slice, array or string without loading the others,
+// which keeps huge collections inspectable: only the element at index is loaded, depth
+// levels deep. The container's length is reported along with it, to bound further indexing.
+func (c *Client) GetElement(expr string, index int, frame int, depth int) types.ElementResponse {
+	response := c.getElement(expr, frame, depth, indexSelector(index))
+	if response.Status == "success" {
+		response.Index = &index
+	}
+	return response
+}
+
+// GetMapElement evaluates the value stored under key, a Go expression such as "\"id\"" or
+// k, in a map without loading its other entries
+func (c *Client) GetMapElement(expr, key string, frame int, depth int) types.ElementResponse {
+	response := c.getElement(expr, frame, depth, keySelector(key))
+	response.Key = key
+	return response
+}
+
+// GetLength reports the length and capacity of a slice, array, string or map without
+// loading any of its elements
+func (c *Client) GetLength(expr string, frame int) types.ElementResponse {
+	return c.getElement(expr, frame, 0, nil)
+}
+
+// indexSelector picks the element at index of a slice, array or string, checked against its
+// length so an out of range index names the valid ones
+func indexSelector(index int) elementSelector {
+	return func(expr string, container *api.Variable) (string, error) {
+		if container.Kind == reflect.Map {
+			return "", fmt.Errorf("%q is a map of type %s; give a key instead of an index", expr, container.Type)
+		}
+		if index < 0 || int64(index) >= container.Len {
+			if container.Len == 0 {
+				return "", fmt.Errorf("index %d is out of range: %s is empty", index, expr)
+			}
+			return "", fmt.Errorf("index %d is out of range: %s has %d elements, indexed 0 to %d", index, expr, container.Len, container.Len-1)
+		}
+		return fmt.Sprintf("(%s)[%d]", expr, index), nil
+	}
+}
+
+// keySelector picks the entry under key of a map
+func keySelector(key string) elementSelector {
+	return func(expr string, container *api.Variable) (string, error) {
+		if container.Kind != reflect.Map {
+			return "", fmt.Errorf("%q is a %s of type %s, not a map; give an index instead of a key", expr, container.Kind, container.Type)
+		}
+		return fmt.Sprintf("(%s)[%s]", expr, key), nil
+	}
+}
+
+// getElement loads a container's length and, unless selector is nil, the element it picks
+func (c *Client) getElement(expr string, frame int, depth int, selector elementSelector) types.ElementResponse {
+	if c.client == nil {
+		return c.createElementResponse(nil, expr, nil, "", nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createElementResponse(nil, expr, nil, "", nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createElementResponse(nil, expr, nil, "", nil, fmt.Errorf("cannot evaluate elements while the target is running; stop the target first"))
+	}
+	if state.SelectedGoroutine == nil {
+		return c.createElementResponse(state, expr, nil, "", nil, fmt.Errorf("no goroutine selected"))
+	}
+
+	scope := api.EvalScope{
+		GoroutineID: state.SelectedGoroutine.ID,
+		Frame:       frame,
+	}
+
+	container, err := c.client.EvalVariable(scope, expr, containerLoadConfig)
+	if err != nil {
+		if isUnresolvedSymbol(err) {
+			return c.createElementResponse(state, expr, nil, "", nil, fmt.Errorf("could not resolve %q: %v; variables in scope: %s", expr, err, c.scopeVariableNames(scope)))
+		}
+		return c.createElementResponse(state, expr, nil, "", nil, fmt.Errorf("failed to evaluate %q: %v", expr, err))
+	}
+	if container == nil {
+		return c.createElementResponse(state, expr, nil, "", nil, fmt.Errorf("expression %q produced no value", expr))
+	}
+	if !containerKinds[container.Kind] {
+		return c.createElementResponse(state, expr, nil, "", nil, fmt.Errorf("%q is a %s of type %s, not a slice, array, string or map; use eval_expression to inspect it", expr, container.Kind, container.Type))
+	}
+	if selector == nil {
+		return c.createElementResponse(state, expr, container, "", nil, nil)
+	}
+
+	elementExpr, err := selector(expr, container)
+	if err != nil {
+		return c.createElementResponse(state, expr, container, "", nil, err)
+	}
+
+	depth = clampLoadDepth(depth)
+	loadConfig := api.LoadConfig{
+		FollowPointers:     true,
+		MaxVariableRecurse: depth,
+		MaxStringLen:       512,
+		MaxArrayValues:     64,
+		MaxStructFields:    -1,
+	}
+
+	logger.Debug("Evaluating element %s in frame %d with depth %d", elementExpr, frame, depth)
+	element, err := c.client.EvalVariable(scope, elementExpr, loadConfig)
+	if err != nil {
+		if container.Kind == reflect.Map && strings.Contains(err.Error(), "key not found") {
+			return c.createElementResponse(state, expr, container, elementExpr, nil, fmt.Errorf("%s: no such key; the map has %d entries", elementExpr, container.Len))
+		}
+		return c.createElementResponse(state, expr, container, elementExpr, nil, fmt.Errorf("failed to evaluate %s: %v", elementExpr, err))
+	}
+	return c.createElementResponse(state, expr, container, elementExpr, element, nil)
+}
+
+// createElementResponse creates an ElementResponse. The container's length is reported
+// even when the element could not be evaluated, to bound the next attempt.
+func (c *Client) createElementResponse(state *api.DebuggerState, expr string, container *api.Variable, elementExpr string, element *api.Variable, err error) types.ElementResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "get_element"
+
+	response := types.ElementResponse{
+		Status:            "success",
+		Context:           context,
+		Expression:        expr,
+		ElementExpression: elementExpr,
+	}
+	if container != nil {
+		response.Type = container.Type
+		response.Kind = container.Kind.String()
+		response.Len = container.Len
+		response.Cap = container.Cap
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	if element != nil {
+		variable := convertVariableTree(element, "", maxVariableDepth)
+		variable.Name = elementExpr
+		response.Element = &variable
+	}
+	return response
+}
@@ -0,0 +1,134 @@
+package debugger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+func TestElementSelectors(t *testing.T) {
+	slice := &api.Variable{Type: "[]int", Kind: reflect.Slice, Len: 1000000, Cap: 1000000}
+	empty := &api.Variable{Type: "[]int", Kind: reflect.Slice}
+	m := &api.Variable{Type: "map[string]int", Kind: reflect.Map, Len: 3}
+
+	testCases := []struct {
+		name          string
+		selector      elementSelector
+		container     *api.Variable
+		expected      string
+		expectedError string
+	}{
+		{name: "Last index", selector: indexSelector(999999), container: slice, expected: "(big)[999999]"},
+		{name: "Index past the end", selector: indexSelector(1000000), container: slice, expectedError: "indexed 0 to 999999"},
+		{name: "Negative index", selector: indexSelector(-1), container: slice, expectedError: "out of range"},
+		{name: "Index into an empty slice", selector: indexSelector(0), container: empty, expectedError: "is empty"},
+		{name: "Index into a map", selector: indexSelector(0), container: m, expectedError: "give a key instead"},
+		{name: "Map key", selector: keySelector(`"id"`), container: m, expected: `(big)["id"]`},
+		{name: "Key into a slice", selector: keySelector(`"id"`), container: slice, expectedError: "give an index instead"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.selector("big", tc.container)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Errorf("Expected an error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestCreateElementResponse(t *testing.T) {
+	container := &api.Variable{Type: "[]int", Kind: reflect.Slice, Len: 5, Cap: 8}
+	element := &api.Variable{Type: "int", Kind: reflect.Int, Value: "42"}
+
+	response := NewClient().createElementResponse(nil, "xs", container, "(xs)[4]", element, nil)
+	if response.Status != "success" || response.Len != 5 || response.Cap != 8 || response.Kind != "slice" {
+		t.Errorf("Expected a slice of length 5 and capacity 8, got %+v", response)
+	}
+	if response.Element == nil || response.Element.Value != "42" || response.Element.Name != "(xs)[4]" {
+		t.Errorf("Expected element (xs)[4] = 42, got %+v", response.Element)
+	}
+
+	failed := NewClient().createElementResponse(nil, "xs", container, "", nil, errors.New("index 10 is out of range"))
+	if failed.Status != "error" || failed.Len != 5 {
+		t.Errorf("Expected an error that still gives the length, got %+v", failed)
+	}
+}
+
+func TestGetElement(t *testing.T) {
+	values := map[string]*api.Variable{
+		"xs":        {Name: "xs", Type: "[]int", Kind: reflect.Slice, Len: 1000000, Cap: 1048576},
+		"(xs)[42]":  {Type: "int", Kind: reflect.Int, Value: "42"},
+		"m":         {Name: "m", Type: "map[string]int", Kind: reflect.Map, Len: 2},
+		`(m)["id"]`: {Type: "int", Kind: reflect.Int, Value: "7"},
+		"n":         {Name: "n", Type: "int", Kind: reflect.Int, Value: "3"},
+	}
+	loaded := make(map[string]api.LoadConfig)
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+		"Eval": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.EvalIn
+			decodeFakeArgs(t, raw, &args)
+			loaded[args.Expr] = *args.Cfg
+			if args.Expr == `(m)["nope"]` {
+				return nil, errors.New("key not found")
+			}
+			if v := values[args.Expr]; v != nil {
+				return rpc2.EvalOut{Variable: v}, nil
+			}
+			return nil, fmt.Errorf("could not find symbol value for %s", args.Expr)
+		},
+	})
+
+	response := c.GetElement("xs", 42, 0, 2)
+	if response.Status != "success" || response.Len != 1000000 || response.Cap != 1048576 || *response.Index != 42 {
+		t.Fatalf("Expected element 42 of a million, got %+v", response)
+	}
+	if response.ElementExpression != "(xs)[42]" || response.Element == nil || response.Element.Value != "42" {
+		t.Errorf("Expected the element (xs)[42], got %+v", response.Element)
+	}
+	// The slice is loaded without its elements, and the element to the depth asked for
+	if cfg := loaded["xs"]; cfg.MaxArrayValues != 0 || cfg.MaxVariableRecurse != 0 {
+		t.Errorf("Expected the slice loaded without elements, got %+v", cfg)
+	}
+	if cfg := loaded["(xs)[42]"]; cfg.MaxVariableRecurse != 2 {
+		t.Errorf("Expected the element loaded 2 levels deep, got %+v", cfg)
+	}
+
+	response = c.GetElement("xs", 1000000, 0, 0)
+	if response.Status != "error" || response.Len != 1000000 || !strings.Contains(response.Context.ErrorMessage, "indexed 0 to 999999") {
+		t.Errorf("Expected an out of range index to name the valid ones, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+
+	response = c.GetMapElement("m", `"id"`, 0, 0)
+	if response.Status != "success" || response.Key != `"id"` || response.Element == nil || response.Element.Value != "7" {
+		t.Errorf("Expected the entry under \"id\", got %+v", response)
+	}
+	response = c.GetMapElement("m", `"nope"`, 0, 0)
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "no such key; the map has 2 entries") {
+		t.Errorf("Expected a missing key error, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+
+	response = c.GetLength("m", 0)
+	if response.Status != "success" || response.Len != 2 || response.Element != nil || response.Kind != "map" {
+		t.Errorf("Expected the length of m only, got %+v", response)
+	}
+	if response := c.GetLength("n", 0); response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "not a slice, array, string or map") {
+		t.Errorf("Expected n not to be a container, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+}
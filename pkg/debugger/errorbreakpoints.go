@@ -0,0 +1,290 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxErrorBreakpoints caps the functions BreakOnError sets breakpoints on at once
+const maxErrorBreakpoints = 200
+
+// A break-on-error breakpoint is a breakpoint on the returns of a function that only stops
+// when the function returns a non-nil error. Delve knows nothing of a function's results
+// until it returns, and a condition can't name unnamed results, so the breakpoint stops on
+// every return and the ones without a matching error are continued past. A function found
+// to have no error result on its first return loses its breakpoint.
+
+// errorBreakpoint scopes a return breakpoint to returns of a non-nil error
+type errorBreakpoint struct {
+	match   string         // Regex the error's message must match, "" for any error
+	re      *regexp.Regexp // Compiled match, nil for any error
+	skipped uint64         // Returns without a matching error continued past
+}
+
+// BreakOnError sets a breakpoint on the returns of every function matching funcPattern, a
+// regex as for list_functions, that stops when the function returns a non-nil error. A
+// non-empty errorMatch regex narrows it to errors whose message matches; the message is read
+// from the error's fields, or else the error's rendered value is matched. Functions of the
+// runtime and internal packages are left out. On a stop, the context reports the error in
+// returnedError, along with all of the results.
+func (c *Client) BreakOnError(funcPattern, errorMatch string) types.ErrorBreakpointsResponse {
+	if c.client == nil {
+		return c.createErrorBreakpointsResponse(nil, funcPattern, errorMatch, nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	if _, err := regexp.Compile(funcPattern); err != nil {
+		return c.createErrorBreakpointsResponse(nil, funcPattern, errorMatch, nil, nil, fmt.Errorf("invalid function pattern %q: %v", funcPattern, err))
+	}
+	var re *regexp.Regexp
+	if errorMatch != "" {
+		var err error
+		if re, err = regexp.Compile(errorMatch); err != nil {
+			return c.createErrorBreakpointsResponse(nil, funcPattern, errorMatch, nil, nil, fmt.Errorf("invalid error match %q: %v", errorMatch, err))
+		}
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createErrorBreakpointsResponse(nil, funcPattern, errorMatch, nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createErrorBreakpointsResponse(nil, funcPattern, errorMatch, nil, nil, fmt.Errorf("cannot set breakpoints while the target is running; stop the target first"))
+	}
+
+	names, err := c.client.ListFunctions(funcPattern, 0)
+	if err != nil {
+		return c.createErrorBreakpointsResponse(state, funcPattern, errorMatch, nil, nil, fmt.Errorf("failed to list functions: %v", err))
+	}
+	functions := errorBreakpointCandidates(names)
+	if len(functions) == 0 {
+		return c.createErrorBreakpointsResponse(state, funcPattern, errorMatch, nil, nil, fmt.Errorf("no functions match %q outside the runtime; use list_functions to see what was compiled in", funcPattern))
+	}
+	if len(functions) > maxErrorBreakpoints {
+		return c.createErrorBreakpointsResponse(state, funcPattern, errorMatch, nil, nil, fmt.Errorf("%d functions match %q; narrow the pattern down to at most %d", len(functions), funcPattern, maxErrorBreakpoints))
+	}
+
+	logger.Debug("Setting break-on-error breakpoints on %d functions matching %q", len(functions), funcPattern)
+
+	var set, failed []types.FunctionBreakpoint
+	for _, function := range functions {
+		result := types.FunctionBreakpoint{Function: function}
+		request := &api.Breakpoint{}
+		if err := c.atReturns(request, function); err != nil {
+			result.Error = err.Error()
+			failed = append(failed, result)
+			continue
+		}
+		bp, err := c.client.CreateBreakpoint(request)
+		if err != nil {
+			result.Error = err.Error()
+			failed = append(failed, result)
+			continue
+		}
+
+		c.setReturnBreakpoint(bp, function)
+		c.setErrorBreakpoint(bp.ID, errorMatch, re)
+		breakpoint := convertBreakpoint(bp)
+		c.annotateBreakpoint(&breakpoint)
+		result.Breakpoint = &breakpoint
+		set = append(set, result)
+	}
+
+	return c.createErrorBreakpointsResponse(state, funcPattern, errorMatch, set, failed, nil)
+}
+
+// errorBreakpointCandidates leaves the functions of the runtime and internal packages, and
+// compiler-generated method value wrappers, out of function names
+func errorBreakpointCandidates(names []string) []string {
+	var functions []string
+	for _, name := range names {
+		pkg := functionPackage(name)
+		if pkg == "runtime" || strings.HasPrefix(pkg, "runtime/") || pkg == "internal" || strings.HasPrefix(pkg, "internal/") || strings.Contains(pkg, "/internal/") {
+			continue
+		}
+		if pkg == "" || strings.HasSuffix(name, "-fm") {
+			continue
+		}
+		functions = append(functions, name)
+	}
+	return functions
+}
+
+// setErrorBreakpoint scopes a return breakpoint to returns of a non-nil error matching re
+func (c *Client) setErrorBreakpoint(id int, match string, re *regexp.Regexp) {
+	if c.errorBreakpoints == nil {
+		c.errorBreakpoints = make(map[int]*errorBreakpoint)
+	}
+	c.errorBreakpoints[id] = &errorBreakpoint{match: match, re: re}
+}
+
+// skipErrorlessReturn reports whether the program stopped at a break-on-error breakpoint
+// on a return without a matching error, so it should be continued without surfacing the
+// stop. When the function has no error result at all, its breakpoint is removed.
+func (c *Client) skipErrorlessReturn(state *api.DebuggerState) bool {
+	if state == nil || state.Exited || state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil {
+		return false
+	}
+
+	th := state.CurrentThread
+	bp := th.Breakpoint
+	eb := c.errorBreakpoints[bp.ID]
+	if eb == nil || th.BreakpointInfo == nil {
+		return false
+	}
+
+	if len(errorResults(returnValues(th))) == 0 {
+		function := c.returnBreakpointFunction(bp.ID)
+		logger.Debug("Removing break-on-error breakpoint %d: %s has no error result", bp.ID, function)
+		if _, err := c.client.ClearBreakpoint(bp.ID); err != nil {
+			logger.Debug("Warning: Failed to clear breakpoint %d: %v", bp.ID, err)
+		}
+		c.forgetBreakpoint(bp.ID)
+		return true
+	}
+
+	if c.returnedError(th) != nil {
+		return false
+	}
+	eb.skipped++
+	logger.Debug("Skipping return of %s without a matching error, at breakpoint %d", c.returnBreakpointFunction(bp.ID), bp.ID)
+	return true
+}
+
+// returnedError returns the error a thread's function is about to return, when it stopped
+// at a break-on-error breakpoint and the error matches
+func (c *Client) returnedError(th *api.Thread) *types.ReturnedError {
+	if th == nil || th.Breakpoint == nil {
+		return nil
+	}
+	eb := c.errorBreakpoints[th.Breakpoint.ID]
+	if eb == nil {
+		return nil
+	}
+
+	for _, v := range errorResults(returnValues(th)) {
+		if isNilInterface(&v) {
+			continue
+		}
+		returned := &types.ReturnedError{
+			Function: c.returnBreakpointFunction(th.Breakpoint.ID),
+			Result:   v.Name,
+			Type:     v.Children[0].Type,
+			Value:    v.SinglelineString(),
+		}
+		returned.Message = errorMessage(&v)
+
+		text := returned.Message
+		if text == "" {
+			text = returned.Value
+		}
+		if eb.re == nil || eb.re.MatchString(text) {
+			return returned
+		}
+	}
+	return nil
+}
+
+// errorResults picks the results of type error out of a function's results
+func errorResults(results []api.Variable) []api.Variable {
+	var errs []api.Variable
+	for _, v := range results {
+		if v.Type == "error" {
+			errs = append(errs, v)
+		}
+	}
+	return errs
+}
+
+// isNilInterface reports whether an interface value holds nothing
+func isNilInterface(v *api.Variable) bool {
+	return len(v.Children) == 0 || v.Children[0].Kind == reflect.Invalid
+}
+
+// errorMessageFields are the fields holding the message of the standard library's common
+// error types: errors.errorString, fmt.wrapError and fmt.wrapErrors
+var errorMessageFields = []string{"s", "msg"}
+
+// errorMessage reads an error's message from its fields, or returns "" when its type doesn't
+// keep it in one
+func errorMessage(v *api.Variable) string {
+	if isNilInterface(v) {
+		return ""
+	}
+	value := &v.Children[0]
+	if value.Kind == reflect.Ptr && len(value.Children) > 0 {
+		value = &value.Children[0]
+	}
+	if value.Kind != reflect.Struct {
+		return ""
+	}
+	for _, name := range errorMessageFields {
+		for _, field := range value.Children {
+			if field.Name == name && field.Kind == reflect.String {
+				return field.Value
+			}
+		}
+	}
+	return ""
+}
+
+// annotateErrorBreakpoint marks a breakpoint that only stops on returned errors, if it is one
+func (c *Client) annotateErrorBreakpoint(breakpoint *types.Breakpoint) {
+	if eb := c.errorBreakpoints[breakpoint.ID]; eb != nil {
+		breakpoint.OnError = true
+		breakpoint.ErrorMatch = eb.match
+		breakpoint.SkippedReturns = eb.skipped
+		if breakpoint.ReturnOf != "" {
+			location := fmt.Sprintf("At the returns of %s with a non-nil error", breakpoint.ReturnOf)
+			breakpoint.Location = &location
+		}
+	}
+}
+
+// createErrorBreakpointsResponse creates an ErrorBreakpointsResponse. Like for a batch of
+// breakpoints, Status is "partial" when some of the functions could not get one.
+func (c *Client) createErrorBreakpointsResponse(state *api.DebuggerState, funcPattern, errorMatch string, set, failed []types.FunctionBreakpoint, err error) types.ErrorBreakpointsResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "break_on_error"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.ErrorBreakpointsResponse{
+			Status:          "error",
+			Context:         context,
+			FunctionPattern: funcPattern,
+			ErrorMatch:      errorMatch,
+		}
+	}
+
+	response := types.ErrorBreakpointsResponse{
+		Context:         context,
+		FunctionPattern: funcPattern,
+		ErrorMatch:      errorMatch,
+		Set:             set,
+		Failed:          failed,
+	}
+	switch {
+	case len(failed) == 0:
+		response.Status = "success"
+	case len(set) == 0:
+		response.Status = "error"
+		response.Context.ErrorMessage = fmt.Sprintf("none of the %d functions matching %q could get a breakpoint", len(failed), funcPattern)
+	default:
+		response.Status = "partial"
+	}
+
+	response.Summary = fmt.Sprintf("%d of %d functions matching %q stop when they return a non-nil error", len(set), len(set)+len(failed), funcPattern)
+	if errorMatch != "" {
+		response.Summary += fmt.Sprintf(" matching %q", errorMatch)
+	}
+	if len(set) > 0 {
+		response.Summary += "; any of them found to have no error result on its first return loses its breakpoint"
+	}
+	return response
+}
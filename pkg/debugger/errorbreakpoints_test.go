@@ -0,0 +1,236 @@
+package debugger
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// errorResult returns the error result ~r1 of a function, holding value, or nil when value is nil
+func errorResult(value *api.Variable) api.Variable {
+	v := api.Variable{Name: "~r1", Type: "error", Kind: reflect.Interface, Flags: api.VariableReturnArgument}
+	if value == nil {
+		v.Children = []api.Variable{{Kind: reflect.Invalid}}
+	} else {
+		v.Children = []api.Variable{*value}
+	}
+	return v
+}
+
+func TestErrorMessage(t *testing.T) {
+	errorString := &api.Variable{Type: "*errors.errorString", Kind: reflect.Ptr, Children: []api.Variable{
+		{Type: "errors.errorString", Kind: reflect.Struct, Children: []api.Variable{{Name: "s", Kind: reflect.String, Value: "missing key"}}},
+	}}
+	wrapError := &api.Variable{Type: "*fmt.wrapError", Kind: reflect.Ptr, Children: []api.Variable{
+		{Type: "fmt.wrapError", Kind: reflect.Struct, Children: []api.Variable{{Name: "msg", Kind: reflect.String, Value: "get: missing key"}, {Name: "err", Kind: reflect.Interface}}},
+	}}
+	pathError := &api.Variable{Type: "*io/fs.PathError", Kind: reflect.Ptr, Children: []api.Variable{
+		{Type: "io/fs.PathError", Kind: reflect.Struct, Children: []api.Variable{{Name: "Op", Kind: reflect.String, Value: "open"}}},
+	}}
+
+	testCases := []struct {
+		name     string
+		value    *api.Variable
+		expected string
+	}{
+		{name: "errors.New", value: errorString, expected: "missing key"},
+		{name: "fmt.Errorf with %w", value: wrapError, expected: "get: missing key"},
+		{name: "Message not in a field", value: pathError},
+		{name: "Nil error"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := errorResult(tc.value)
+			if result := errorMessage(&v); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestSkipErrorlessReturn(t *testing.T) {
+	missing := &api.Variable{Type: "*errors.errorString", Kind: reflect.Ptr, Children: []api.Variable{
+		{Type: "errors.errorString", Kind: reflect.Struct, Children: []api.Variable{{Name: "s", Kind: reflect.String, Value: "missing key"}}},
+	}}
+	result := api.Variable{Name: "~r0", Type: "string", Kind: reflect.String, Flags: api.VariableReturnArgument}
+
+	testCases := []struct {
+		name     string
+		match    string
+		results  []api.Variable
+		expected bool
+	}{
+		{name: "Nil error", results: []api.Variable{result, errorResult(nil)}, expected: true},
+		{name: "Any error", results: []api.Variable{result, errorResult(missing)}},
+		{name: "Matching error", match: "missing", results: []api.Variable{result, errorResult(missing)}},
+		{name: "Error not matching", match: "timeout", results: []api.Variable{result, errorResult(missing)}, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient()
+			c.setReturnBreakpoint(&api.Breakpoint{ID: 1, Addrs: []uint64{0x1000}}, "main.(*store).get")
+			var re *regexp.Regexp
+			if tc.match != "" {
+				re = regexp.MustCompile(tc.match)
+			}
+			c.setErrorBreakpoint(1, tc.match, re)
+
+			th := &api.Thread{Breakpoint: &api.Breakpoint{ID: 1}, BreakpointInfo: &api.BreakpointInfo{Arguments: tc.results}}
+			if result := c.skipErrorlessReturn(&api.DebuggerState{CurrentThread: th}); result != tc.expected {
+				t.Fatalf("Expected skip %v, got %v", tc.expected, result)
+			}
+			if tc.expected {
+				if c.errorBreakpoints[1].skipped != 1 {
+					t.Errorf("Expected 1 skipped return, got %d", c.errorBreakpoints[1].skipped)
+				}
+				return
+			}
+
+			returned := c.returnedError(th)
+			if returned == nil || returned.Function != "main.(*store).get" || returned.Result != "~r1" || returned.Message != "missing key" {
+				t.Errorf("Expected the error of main.(*store).get, got %+v", returned)
+			}
+		})
+	}
+}
+
+func TestAnnotateErrorBreakpoint(t *testing.T) {
+	c := NewClient()
+	c.setReturnBreakpoint(&api.Breakpoint{ID: 2, Addrs: []uint64{0x1000, 0x1010}}, "main.parse")
+	c.setErrorBreakpoint(2, "invalid", regexp.MustCompile("invalid"))
+
+	breakpoint := types.Breakpoint{ID: 2}
+	c.annotateBreakpoint(&breakpoint)
+	if !breakpoint.OnError || breakpoint.ErrorMatch != "invalid" {
+		t.Errorf("Expected a break-on-error breakpoint matching %q, got %+v", "invalid", breakpoint)
+	}
+	if breakpoint.Location == nil || *breakpoint.Location != "At the returns of main.parse with a non-nil error" {
+		t.Errorf("Expected the location to say it stops on errors, got %v", breakpoint.Location)
+	}
+}
+
+func TestErrorBreakpointCandidates(t *testing.T) {
+	names := []string{"main.load", "main.(*store).get", "main.(*store).get-fm", "runtime.main", "internal/poll.(*FD).Read", "github.com/x/y/internal/db.Open", "os.Open"}
+	expected := []string{"main.load", "main.(*store).get", "os.Open"}
+	if result := errorBreakpointCandidates(names); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+// errorString is an *errors.errorString holding message
+func errorString(message string) *api.Variable {
+	return &api.Variable{Type: "*errors.errorString", Kind: reflect.Ptr, Children: []api.Variable{
+		{Type: "errors.errorString", Kind: reflect.Struct, Children: []api.Variable{{Name: "s", Kind: reflect.String, Value: message}}},
+	}}
+}
+
+func TestBreakOnError(t *testing.T) {
+	bps := &fakeBreakpoints{}
+	result := api.Variable{Name: "~r0", Type: "string", Kind: reflect.String, Flags: api.VariableReturnArgument}
+
+	// main.parse turns out to have no error result, then main.load returns no error, an
+	// error that doesn't match, and the one that does
+	returns := []struct {
+		bp      int
+		results []api.Variable
+	}{
+		{bp: 2, results: []api.Variable{result}},
+		{bp: 1, results: []api.Variable{result, errorResult(nil)}},
+		{bp: 1, results: []api.Variable{result, errorResult(errorString("timeout"))}},
+		{bp: 1, results: []api.Variable{result, errorResult(errorString("missing key"))}},
+	}
+	var commands []string
+	c, _ := newFakeDelve(t, bps.serve(t, map[string]fakeHandler{
+		"State":         fakeState(stoppedState(5)),
+		"ListFunctions": fakeResult(rpc2.ListFunctionsOut{Funcs: []string{"main.load", "main.parse", "main.spin", "runtime.main", "main.load-fm"}}),
+		"FunctionReturnLocations": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.FunctionReturnLocationsIn
+			decodeFakeArgs(t, raw, &args)
+			addrs := map[string][]uint64{"main.load": {0x1000, 0x1040}, "main.parse": {0x2000}}
+			return rpc2.FunctionReturnLocationsOut{Addrs: addrs[args.FnName]}, nil
+		},
+		"Disassemble": fakeResult(rpc2.DisassembleOut{}),
+		"Command": fakeCommands(t, &commands, func(api.DebuggerCommand) api.DebuggerState {
+			r := returns[0]
+			returns = returns[1:]
+			state := stoppedState(20)
+			state.CurrentThread.Breakpoint = bps.get(r.bp)
+			state.CurrentThread.BreakpointInfo = &api.BreakpointInfo{Arguments: r.results}
+			return *state
+		}),
+	}))
+
+	response := c.BreakOnError(`^main\.`, "missing")
+	if response.Status != "partial" || len(response.Set) != 2 || len(response.Failed) != 1 {
+		t.Fatalf("Expected main.load and main.parse set and main.spin failed, got %+v", response)
+	}
+	if failed := response.Failed[0]; failed.Function != "main.spin" || !strings.Contains(failed.Error, "no return points") {
+		t.Errorf("Expected main.spin to fail for having no return points, got %+v", failed)
+	}
+	load := response.Set[0].Breakpoint
+	if load == nil || !load.OnError || load.ErrorMatch != "missing" || load.ReturnOf != "main.load" {
+		t.Errorf("Expected a break-on-error breakpoint at the returns of main.load, got %+v", load)
+	}
+	if bp := bps.get(1); bp == nil || !reflect.DeepEqual(bp.Addrs, []uint64{0x1000, 0x1040}) || bp.LoadArgs == nil {
+		t.Errorf("Expected breakpoint 1 on both returns of main.load, loading the results, got %+v", bp)
+	}
+
+	continued := c.Continue(context.Background())
+	if continued.Status != "success" {
+		t.Fatalf("Expected continue to stop at the matching error, got %s", continued.Context.ErrorMessage)
+	}
+	if len(commands) != 4 {
+		t.Errorf("Expected the 3 returns without a matching error continued past, got commands %v", commands)
+	}
+	returned := continued.Context.ReturnedError
+	if returned == nil || returned.Function != "main.load" || returned.Result != "~r1" || returned.Message != "missing key" {
+		t.Errorf("Expected the error main.load returns, got %+v", returned)
+	}
+	if bps.get(2) != nil || c.errorBreakpoints[2] != nil {
+		t.Error("Expected the breakpoint of main.parse, which has no error result, to be cleared")
+	}
+	if skipped := c.errorBreakpoints[1].skipped; skipped != 2 {
+		t.Errorf("Expected 2 returns of main.load skipped, got %d", skipped)
+	}
+}
+
+func TestBreakOnErrorErrors(t *testing.T) {
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State":         fakeState(stoppedState(5)),
+		"ListFunctions": fakeResult(rpc2.ListFunctionsOut{Funcs: []string{"runtime.main", "internal/poll.(*FD).Read"}}),
+	})
+
+	testCases := []struct {
+		name        string
+		funcPattern string
+		errorMatch  string
+		expected    string
+	}{
+		{name: "Invalid function pattern", funcPattern: "main.(", expected: "invalid function pattern"},
+		{name: "Invalid error match", funcPattern: "main", errorMatch: "(", expected: "invalid error match"},
+		{name: "Only runtime functions", funcPattern: ".", expected: "outside the runtime"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.BreakOnError(tc.funcPattern, tc.errorMatch)
+			if response.Status != "error" || response.Context.Operation != "break_on_error" || !strings.Contains(response.Context.ErrorMessage, tc.expected) {
+				t.Errorf("Expected a break_on_error error containing %q, got %+v", tc.expected, response)
+			}
+		})
+	}
+
+	response := NewClient().BreakOnError(`^main\.`, "")
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "no active debug session") {
+		t.Errorf("Expected an error without a session, got %+v", response)
+	}
+}
@@ -0,0 +1,188 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// Eval evaluates an arbitrary Go expression in the given frame of the selected goroutine,
+// loading depth levels of nested values, at most maxVariableDepth. With callStringers, a
+// result whose type has a String or Error method is rendered with it too, by calling it in
+// the target. followPointers overrides the session's SetFollowPointers setting when not nil.
+// Integers, including those in fields and elements, are rendered in base 2, 8, 10 or 16, 0
+// meaning 10.
+func (c *Client) Eval(expr string, frame int, depth int, callStringers bool, followPointers *bool, base int) types.EvalExpressionResponse {
+	if c.client == nil {
+		return c.createEvalExpressionResponse(nil, expr, nil, fmt.Errorf("no active debug session"))
+	}
+
+	base, err := checkIntegerBase(base)
+	if err != nil {
+		return c.createEvalExpressionResponse(nil, expr, nil, err)
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		return c.createEvalExpressionResponse(nil, expr, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.SelectedGoroutine == nil {
+		return c.createEvalExpressionResponse(state, expr, nil, fmt.Errorf("no goroutine selected"))
+	}
+
+	scope := api.EvalScope{
+		GoroutineID: state.SelectedGoroutine.ID,
+		Frame:       frame,
+	}
+
+	depth = clampLoadDepth(depth)
+	loadConfig := api.LoadConfig{
+		FollowPointers:     c.followPointers(followPointers),
+		MaxVariableRecurse: depth,
+		MaxStringLen:       512,
+		MaxArrayValues:     64,
+		MaxStructFields:    -1,
+	}
+
+	logger.Debug("Evaluating expression %q in frame %d with depth %d", expr, frame, depth)
+	v, err := c.client.EvalVariable(scope, expr, loadConfig)
+	if err != nil {
+		return c.createEvalExpressionResponse(state, expr, nil, fmt.Errorf("failed to evaluate expression %q: %v", expr, err))
+	}
+	if v == nil {
+		return c.createEvalExpressionResponse(state, expr, nil, fmt.Errorf("expression %q produced no value", expr))
+	}
+	rebaseIntegers(v, base)
+
+	response := c.createEvalExpressionResponse(state, expr, v, nil)
+	response.MaxDepth = depth
+	if callStringers {
+		response.StringerNote = c.callStringers(state, frame, []stringerTarget{{expr: expr, variable: &response.Variable}}, loadConfig.MaxStringLen)
+	}
+	return response
+}
+
+// renderVariableTree renders a Delve variable as an indented tree, one value per line. A
+// pointer to a value already rendered, as in a cyclic structure, names the path it was
+// rendered at instead of rendering it again.
+func renderVariableTree(v *api.Variable) string {
+	var b strings.Builder
+	writeVariableTree(&b, v, v.Name, v.Name, 0, make(shownValues))
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// writeVariableTree writes a variable found at path and its loaded children to the builder
+func writeVariableTree(b *strings.Builder, v *api.Variable, label, path string, indent int, shown shownValues) {
+	prefix := strings.Repeat("  ", indent)
+	if label != "" {
+		label += " "
+	}
+
+	if v.Unreadable != "" {
+		fmt.Fprintf(b, "%s%s%s = (unreadable: %s)\n", prefix, label, v.Type, v.Unreadable)
+		return
+	}
+	shown.record(v, path)
+
+	switch v.Kind {
+	case reflect.Struct:
+		fmt.Fprintf(b, "%s%s%s\n", prefix, label, v.Type)
+		if len(v.Children) == 0 && v.Len > 0 {
+			fmt.Fprintf(b, "%s  ... (not loaded, increase depth)\n", prefix)
+		}
+		for i := range v.Children {
+			writeVariableTree(b, &v.Children[i], v.Children[i].Name, fieldPath(path, v.Children[i].Name), indent+1, shown)
+		}
+	case reflect.Array, reflect.Slice:
+		if v.Kind == reflect.Slice {
+			fmt.Fprintf(b, "%s%s%s len: %d, cap: %d\n", prefix, label, v.Type, v.Len, v.Cap)
+		} else {
+			fmt.Fprintf(b, "%s%s%s len: %d\n", prefix, label, v.Type, v.Len)
+		}
+		for i := range v.Children {
+			index := fmt.Sprintf("[%d]", i)
+			writeVariableTree(b, &v.Children[i], index, fieldPath(path, index), indent+1, shown)
+		}
+		if loaded := int64(len(v.Children)); loaded < v.Len {
+			fmt.Fprintf(b, "%s  ... (truncated, %d more)\n", prefix, v.Len-loaded)
+		}
+	case reflect.Map:
+		fmt.Fprintf(b, "%s%s%s len: %d\n", prefix, label, v.Type, v.Len)
+		for _, e := range sortedMapEntries(v) {
+			key := fmt.Sprintf("[%s]", formatScalarValue(e.key))
+			writeVariableTree(b, e.value, key, fieldPath(path, key), indent+1, shown)
+		}
+		if loaded := int64(len(v.Children) / 2); loaded < v.Len {
+			fmt.Fprintf(b, "%s  ... (truncated, %d more)\n", prefix, v.Len-loaded)
+		}
+	case reflect.Ptr:
+		if len(v.Children) == 0 || v.Children[0].Addr == 0 {
+			fmt.Fprintf(b, "%s%s%s = nil\n", prefix, label, v.Type)
+			return
+		}
+		if shownPath, ok := shown.shownAt(&v.Children[0]); ok {
+			fmt.Fprintf(b, "%s%s%s = %#x (already shown at %s)\n", prefix, label, v.Type, v.Children[0].Addr, shownPath)
+			return
+		}
+		fmt.Fprintf(b, "%s%s%s = %#x\n", prefix, label, v.Type, v.Children[0].Addr)
+		if !v.Children[0].OnlyAddr {
+			writeVariableTree(b, &v.Children[0], "*", path, indent+1, shown)
+		}
+	case reflect.Interface:
+		if len(v.Children) == 0 || v.Children[0].Kind == reflect.Invalid {
+			fmt.Fprintf(b, "%s%s%s = nil\n", prefix, label, v.Type)
+			return
+		}
+		fmt.Fprintf(b, "%s%s%s\n", prefix, label, v.Type)
+		writeVariableTree(b, &v.Children[0], "", path, indent+1, shown)
+	default:
+		fmt.Fprintf(b, "%s%s%s = %s\n", prefix, label, v.Type, formatScalarValue(v))
+	}
+}
+
+// formatScalarValue formats a single non-composite value, marking truncated strings
+func formatScalarValue(v *api.Variable) string {
+	if v.Kind != reflect.String {
+		return v.Value
+	}
+
+	s := strconv.Quote(v.Value)
+	if loaded := int64(len(v.Value)); loaded < v.Len {
+		s += fmt.Sprintf(" (truncated, %d more)", v.Len-loaded)
+	}
+	return s
+}
+
+// createEvalExpressionResponse creates an EvalExpressionResponse
+func (c *Client) createEvalExpressionResponse(state *api.DebuggerState, expr string, v *api.Variable, err error) types.EvalExpressionResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "eval_expression"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.EvalExpressionResponse{
+			Status:     "error",
+			Context:    context,
+			Expression: expr,
+		}
+	}
+
+	return types.EvalExpressionResponse{
+		Status:     "success",
+		Context:    context,
+		Expression: expr,
+		Variable: types.Variable{
+			DelveVar: v,
+			Name:     v.Name,
+			Value:    formatVariableValue(v),
+			Type:     v.Type,
+			Kind:     getVariableKind(v),
+		},
+		Tree: renderVariableTree(v),
+	}
+}
@@ -0,0 +1,64 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestRenderVariableTree(t *testing.T) {
+	testCases := []struct {
+		name     string
+		variable *api.Variable
+		expected string
+	}{
+		{
+			name:     "Integer",
+			variable: &api.Variable{Name: "count", Type: "int", Kind: reflect.Int, Value: "10"},
+			expected: "count int = 10",
+		},
+		{
+			name:     "Truncated string",
+			variable: &api.Variable{Name: "s", Type: "string", Kind: reflect.String, Value: "abc", Len: 10},
+			expected: `s string = "abc" (truncated, 7 more)`,
+		},
+		{
+			name: "Struct",
+			variable: &api.Variable{Name: "person", Type: "main.Person", Kind: reflect.Struct, Len: 2, Children: []api.Variable{
+				{Name: "Name", Type: "string", Kind: reflect.String, Value: "Alice", Len: 5},
+				{Name: "Age", Type: "int", Kind: reflect.Int, Value: "30"},
+			}},
+			expected: "person main.Person\n  Name string = \"Alice\"\n  Age int = 30",
+		},
+		{
+			name: "Truncated slice",
+			variable: &api.Variable{Name: "nums", Type: "[]int", Kind: reflect.Slice, Len: 5, Cap: 8, Children: []api.Variable{
+				{Type: "int", Kind: reflect.Int, Value: "1"},
+				{Type: "int", Kind: reflect.Int, Value: "2"},
+			}},
+			expected: "nums []int len: 5, cap: 8\n  [0] int = 1\n  [1] int = 2\n  ... (truncated, 3 more)",
+		},
+		{
+			name: "Map",
+			variable: &api.Variable{Name: "m", Type: "map[string]int", Kind: reflect.Map, Len: 1, Children: []api.Variable{
+				{Type: "string", Kind: reflect.String, Value: "a", Len: 1},
+				{Type: "int", Kind: reflect.Int, Value: "1"},
+			}},
+			expected: "m map[string]int len: 1\n  [\"a\"] int = 1",
+		},
+		{
+			name:     "Nil pointer",
+			variable: &api.Variable{Name: "p", Type: "*main.Person", Kind: reflect.Ptr},
+			expected: "p *main.Person = nil",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tree := renderVariableTree(tc.variable); tree != tc.expected {
+				t.Errorf("Expected tree:\n%s\ngot:\n%s", tc.expected, tree)
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package debugger
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-delve/delve/service/api"
@@ -9,28 +13,301 @@ import (
 	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
-// Continue resumes program execution until next breakpoint or program termination
-func (c *Client) Continue() types.ContinueResponse {
+// haltTimeout bounds how long to wait for the target to stop after halting it
+const haltTimeout = 5 * time.Second
+
+// ErrInterrupted is reported when a command's context ended before the target stopped by
+// itself. The target has been halted and can be inspected or resumed as usual.
+var ErrInterrupted = errors.New("process halted")
+
+// Continue resumes program execution until next breakpoint or program termination.
+// When ctx is done first, the target is halted instead of waited on.
+func (c *Client) Continue(ctx context.Context) types.ContinueResponse {
 	if c.client == nil {
 		return c.createContinueResponse(nil, fmt.Errorf("no active debug session"))
 	}
 
 	logger.Debug("Continuing execution")
 
-	// Continue returns a channel that will receive state updates
-	stateChan := c.client.Continue()
+	delveState, err := c.continueExecution(ctx)
+	if err != nil {
+		if errors.Is(err, ErrInterrupted) {
+			return c.createContinueResponse(delveState, err)
+		}
+		return c.createContinueResponse(nil, err)
+	}
+
+	return c.createContinueResponse(delveState, nil)
+}
+
+// Halt stops the running target so it can be inspected
+func (c *Client) Halt() types.HaltResponse {
+	if c.client == nil {
+		return c.createHaltResponse(nil, false, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createHaltResponse(nil, false, fmt.Errorf("failed to get state: %v", err))
+	}
+	if !state.Running {
+		return c.createHaltResponse(state, false, nil)
+	}
+
+	logger.Debug("Halting target")
+	if _, err := c.client.Halt(); err != nil {
+		return c.createHaltResponse(nil, true, fmt.Errorf("failed to halt program: %v", err))
+	}
+
+	state, err = waitForStop(c, haltTimeout)
+	if err != nil {
+		return c.createHaltResponse(nil, true, fmt.Errorf("failed to halt program: %v", err))
+	}
+
+	return c.createHaltResponse(state, true, nil)
+}
+
+// interruptible runs a command that resumes the target. If ctx is done before the command
+// returns, the target is halted, any step in progress is cancelled, and the halted state
+// is returned with an error wrapping ErrInterrupted.
+func (c *Client) interruptible(ctx context.Context, command func() (*api.DebuggerState, error)) (*api.DebuggerState, error) {
+	type result struct {
+		state *api.DebuggerState
+		err   error
+	}
+	done := make(chan result, 1)
+
+	c.forgetAsyncRun()
+
+	// Steps run the target too, which counts to the time between breakpoint hits
+	c.hitTimes.resume(time.Now())
+	defer func() { c.hitTimes.stop(time.Now()) }()
+	go func() {
+		state, err := command()
+		done <- result{state, err}
+	}()
+
+	select {
+	case r := <-done:
+		c.recordCaptureHits(r.state)
+		return r.state, r.err
+	case <-ctx.Done():
+	}
+
+	reason := "timed out"
+	if errors.Is(ctx.Err(), context.Canceled) {
+		reason = "cancelled"
+	}
+
+	logger.Debug("Command %s, halting target", reason)
+	if _, err := c.client.Halt(); err != nil {
+		return nil, fmt.Errorf("%s and failed to halt the process: %v", reason, err)
+	}
+
+	// The command returns once the target has stopped
+	select {
+	case <-done:
+	case <-time.After(haltTimeout):
+		return nil, fmt.Errorf("%s and the process did not stop within %v of halting it", reason, haltTimeout)
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		return nil, fmt.Errorf("%s, %w, but failed to get its state: %v", reason, ErrInterrupted, err)
+	}
+
+	// An interrupted next, step or stepout would otherwise resume on the next continue
+	if state.NextInProgress {
+		if err := c.client.CancelNext(); err != nil {
+			logger.Debug("Warning: Failed to cancel interrupted step: %v", err)
+		}
+	}
+
+	return state, fmt.Errorf("%s, %w", reason, ErrInterrupted)
+}
+
+// interruptibleStep runs a step command of the goroutine selected in from like
+// interruptible. A step that doesn't complete in time, such as a next over a call that
+// loops or blocks, leaves the target halted wherever its threads were, which Delve reports
+// as a thread that may run another goroutine or none. That goroutine is selected again, so
+// the state and the error show where the step got stuck.
+func (c *Client) interruptibleStep(ctx context.Context, from *api.DebuggerState, command func() (*api.DebuggerState, error)) (*api.DebuggerState, error) {
+	state, err := c.interruptible(ctx, command)
+	if !errors.Is(err, ErrInterrupted) {
+		return state, err
+	}
+	if state == nil {
+		return nil, fmt.Errorf("step %w", err)
+	}
 
-	// Wait for the state update from the channel
-	delveState := <-stateChan
+	if from != nil && from.SelectedGoroutine != nil && (state.SelectedGoroutine == nil || state.SelectedGoroutine.ID != from.SelectedGoroutine.ID) {
+		id := from.SelectedGoroutine.ID
+		if switched, switchErr := c.client.SwitchGoroutine(id); switchErr != nil {
+			logger.Debug("Warning: Failed to select goroutine %d again after halting the step: %v", id, switchErr)
+		} else {
+			state = switched
+		}
+	}
+
+	return state, fmt.Errorf("step %w at %s", err, haltedStepLocation(state))
+}
+
+// haltedStepLocation describes where the selected goroutine of a halted step is. When it is
+// inside the runtime, as when blocked, the user code it is in is named too.
+func haltedStepLocation(state *api.DebuggerState) string {
+	g := state.SelectedGoroutine
+	if g == nil {
+		if pos := getCurrentPosition(state); pos != nil {
+			return fmt.Sprintf("%s:%d in %s", pos.File, pos.Line, pos.Function)
+		}
+		return "an unknown location"
+	}
+
+	location := fmt.Sprintf("%s:%d in %s", g.CurrentLoc.File, g.CurrentLoc.Line, getFunctionNameFromLocation(g.CurrentLoc))
+	if isRuntimeFunction(getFunctionNameFromLocation(g.CurrentLoc)) && g.UserCurrentLoc.File != "" && g.UserCurrentLoc.PC != g.CurrentLoc.PC {
+		location += fmt.Sprintf(", called from %s:%d in %s", g.UserCurrentLoc.File, g.UserCurrentLoc.Line, getFunctionNameFromLocation(g.UserCurrentLoc))
+	}
+	return location
+}
+
+// continueExecution resumes the program and waits until it stops, exits, or ctx is done.
+// Delve's client resumes by itself after tracepoint hits, sending a state for each, so the
+// channel is drained until the program really stops. The last state is returned even on error.
+func (c *Client) continueExecution(ctx context.Context) (*api.DebuggerState, error) {
+	return c.interruptible(ctx, c.drainContinue)
+}
+
+// drainContinue resumes the program and waits for it to stop or exit. Stops at
+// label-scoped breakpoints on goroutines without the label, at break-on-error breakpoints
+// on returns without a matching error, and at breakpoints with hits left to ignore, are
+// continued past. Exiting is a stop like any other, not an error.
+func (c *Client) drainContinue() (*api.DebuggerState, error) {
+	// A next, step or stepout a breakpoint interrupted resumes with the continue
+	var stepping bool
+	if before, err := c.client.GetStateNonBlocking(); err == nil {
+		stepping = before.NextInProgress
+	}
+
+	var delveState *api.DebuggerState
+	for {
+		delveState = nil
+		c.hitTimes.resume(time.Now())
+		for state := range c.client.Continue() {
+			c.recordTraceHits(state)
+			c.hitTimes.record(state, time.Now())
+			delveState = state
+		}
+		c.hitTimes.stop(time.Now())
+		if delveState == nil {
+			return nil, fmt.Errorf("continue command failed: no state received")
+		}
+		// A hit on a goroutine without the label, or a return without an error at a
+		// break-on-error breakpoint, doesn't use up the ignore count
+		if delveState.Err != nil || !(c.skipFilteredHit(delveState) || c.skipErrorlessReturn(delveState) || c.skipIgnoredHit(delveState)) {
+			break
+		}
+	}
+
+	c.pinHitGoroutine(delveState)
+	c.triggerArmedBreakpoints(delveState)
+	c.noteCompletedStep(stepping, delveState)
+	c.clearTemporaryBreakpoints(delveState)
+
+	// Delve's client reports the exit as an error of the state too
+	if delveState.Exited {
+		return delveState, nil
+	}
 	if delveState.Err != nil {
-		return c.createContinueResponse(nil, fmt.Errorf("continue command failed: %v", delveState.Err))
+		return delveState, fmt.Errorf("continue command failed: %v", delveState.Err)
 	}
+	return delveState, nil
+}
 
-	return c.createContinueResponse(delveState, nil)
+// ContinueToLine runs the program until it reaches file:line, using a temporary breakpoint
+// that is removed once hit or when the program exits. If another breakpoint stops the
+// program first, the temporary breakpoint stays so a later continue still reaches the line.
+func (c *Client) ContinueToLine(ctx context.Context, file string, line int) types.ContinueToLineResponse {
+	if c.client == nil {
+		return c.createContinueToLineResponse(nil, file, line, nil, false, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createContinueToLineResponse(nil, file, line, nil, false, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createContinueToLineResponse(nil, file, line, nil, false, fmt.Errorf("cannot continue to a line while the target is running; stop the target first"))
+	}
+
+	logger.Debug("Continuing to %s:%d", file, line)
+	bp, err := c.client.CreateBreakpoint(&api.Breakpoint{File: file, Line: line})
+	if err != nil {
+		// An existing breakpoint at the line stops the program there just as well
+		if !strings.Contains(err.Error(), "Breakpoint exists") {
+			return c.createContinueToLineResponse(state, file, line, nil, false, fmt.Errorf("failed to set temporary breakpoint at %s:%d: %v", file, line, err))
+		}
+		bp = nil
+	} else {
+		if c.tempBreakpoints == nil {
+			c.tempBreakpoints = make(map[int]bool)
+		}
+		c.tempBreakpoints[bp.ID] = true
+	}
+
+	delveState, err := c.continueExecution(ctx)
+	if err != nil {
+		if errors.Is(err, ErrInterrupted) {
+			return c.createContinueToLineResponse(delveState, file, line, nil, false, fmt.Errorf("%v before reaching %s:%d", err, file, line))
+		}
+		return c.createContinueToLineResponse(nil, file, line, nil, false, err)
+	}
+	if delveState.Exited {
+		return c.createContinueToLineResponse(delveState, file, line, nil, false, fmt.Errorf("process exited with status %d before reaching %s:%d", delveState.ExitStatus, file, line))
+	}
+
+	// Compare against where Delve resolved the breakpoint, which may differ from the requested line
+	targetFile, targetLine := file, line
+	if bp != nil {
+		targetFile, targetLine = bp.File, bp.Line
+	}
+	reached := delveState.CurrentThread != nil && delveState.CurrentThread.File == targetFile && delveState.CurrentThread.Line == targetLine
+
+	// Report the temporary breakpoint only while it is still set
+	var remaining *api.Breakpoint
+	if bp != nil && c.tempBreakpoints[bp.ID] {
+		remaining = bp
+	}
+
+	return c.createContinueToLineResponse(delveState, file, line, remaining, reached, nil)
 }
 
-// Step executes a single instruction, stepping into function calls
-func (c *Client) Step() types.StepResponse {
+// clearTemporaryBreakpoints removes temporary breakpoints the program stopped at, and
+// forgets all of them once the program has exited
+func (c *Client) clearTemporaryBreakpoints(state *api.DebuggerState) {
+	if len(c.tempBreakpoints) == 0 {
+		return
+	}
+
+	if state.Exited {
+		c.tempBreakpoints = nil
+		return
+	}
+
+	for _, th := range state.Threads {
+		if th.Breakpoint == nil || !c.tempBreakpoints[th.Breakpoint.ID] {
+			continue
+		}
+		if _, err := c.client.ClearBreakpoint(th.Breakpoint.ID); err != nil {
+			logger.Debug("Warning: Failed to clear temporary breakpoint %d: %v", th.Breakpoint.ID, err)
+			continue
+		}
+		delete(c.tempBreakpoints, th.Breakpoint.ID)
+	}
+}
+
+// Step executes a single instruction, stepping into function calls.
+// Like the other step commands it halts the target when ctx is done first.
+func (c *Client) Step(ctx context.Context) types.StepResponse {
 	if c.client == nil {
 		return c.createStepResponse(nil, "into", nil, fmt.Errorf("no active debug session"))
 	}
@@ -52,8 +329,11 @@ func (c *Client) Step() types.StepResponse {
 	}
 
 	logger.Debug("Stepping into")
-	nextState, err := c.client.Step()
+	nextState, err := c.interruptibleStep(ctx, delveState, c.client.Step)
 	if err != nil {
+		if errors.Is(err, ErrInterrupted) {
+			return c.createStepResponse(nextState, "into", fromLocation, err)
+		}
 		return c.createStepResponse(nil, "into", fromLocation, fmt.Errorf("step into command failed: %v", err))
 	}
 
@@ -61,7 +341,7 @@ func (c *Client) Step() types.StepResponse {
 }
 
 // StepOver executes the next instruction, stepping over function calls
-func (c *Client) StepOver() types.StepResponse {
+func (c *Client) StepOver(ctx context.Context) types.StepResponse {
 	if c.client == nil {
 		return c.createStepResponse(nil, "over", nil, fmt.Errorf("no active debug session"))
 	}
@@ -82,17 +362,72 @@ func (c *Client) StepOver() types.StepResponse {
 		}
 	}
 
+	before := c.stackFunctions(delveState)
+
 	logger.Debug("Stepping over next line")
-	nextState, err := c.client.Next()
+	nextState, err := c.interruptibleStep(ctx, delveState, c.client.Next)
 	if err != nil {
+		if errors.Is(err, ErrInterrupted) {
+			return c.createStepResponse(nextState, "over", fromLocation, err)
+		}
 		return c.createStepResponse(nil, "over", fromLocation, fmt.Errorf("step over command failed: %v", err))
 	}
 
-	return c.createStepResponse(nextState, "over", fromLocation, nil)
+	response := c.createStepResponse(nextState, "over", fromLocation, nil)
+	if response.InterruptedBy == nil {
+		response.Transition, response.TransitionNote = describeStepTransition(before, c.stackFunctions(nextState))
+	}
+	return response
+}
+
+// maxTransitionFrames bounds the stack read to work out where a step ended up
+const maxTransitionFrames = 64
+
+// stackFunctions returns the function names on the stack of the selected goroutine,
+// innermost first, or nil when the stack can't be read
+func (c *Client) stackFunctions(state *api.DebuggerState) []string {
+	if state == nil || state.SelectedGoroutine == nil || state.Exited {
+		return nil
+	}
+
+	frames, err := c.client.Stacktrace(state.SelectedGoroutine.ID, maxTransitionFrames, 0, nil)
+	if err != nil {
+		logger.Debug("Warning: Failed to get stack trace of goroutine %d: %v", state.SelectedGoroutine.ID, err)
+		return nil
+	}
+
+	functions := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		functions = append(functions, getFunctionNameFromLocation(frame.Location))
+	}
+	return functions
+}
+
+// describeStepTransition tells whether a step over left the frame it started in, given
+// the stack before and after it, and describes how. Stepping over a return ends up in the
+// caller; a panic during the step stops in the frame's deferred function instead.
+func describeStepTransition(before, after []string) (string, string) {
+	if len(before) == 0 || len(after) == 0 {
+		return "", ""
+	}
+
+	if slices.Contains(after, "runtime.gopanic") && !slices.Contains(before, "runtime.gopanic") {
+		return types.StepTransitionPanic, fmt.Sprintf("%s panicked; stopped in the deferred function %s, where the panic can be recovered", before[0], after[0])
+	}
+
+	if len(after) < len(before) {
+		return types.StepTransitionReturned, fmt.Sprintf("returned from %s to its caller %s", before[0], after[0])
+	}
+
+	if after[0] != before[0] {
+		return types.StepTransitionEntered, fmt.Sprintf("entered %s from %s", after[0], before[0])
+	}
+
+	return "", ""
 }
 
 // StepOut executes until the current function returns
-func (c *Client) StepOut() types.StepResponse {
+func (c *Client) StepOut(ctx context.Context) types.StepResponse {
 	if c.client == nil {
 		return c.createStepResponse(nil, "out", nil, fmt.Errorf("no active debug session"))
 	}
@@ -113,15 +448,59 @@ func (c *Client) StepOut() types.StepResponse {
 		}
 	}
 
+	// There is no caller to return to from the goroutine's outermost user frame
+	if delveState.SelectedGoroutine != nil {
+		frames, err := c.client.Stacktrace(delveState.SelectedGoroutine.ID, 2, 0, nil)
+		if err == nil && len(frames) > 0 && (len(frames) < 2 || isGoroutineEntryCaller(getFunctionNameFromLocation(frames[1].Location))) {
+			return c.createStepResponse(nil, "out", fromLocation, fmt.Errorf("cannot step out of %s: it is the outermost frame of the goroutine, use continue instead", getFunctionNameFromLocation(frames[0].Location)))
+		}
+	}
+
+	// Ask Delve to load the return values of the function we leave
+	c.client.SetReturnValuesLoadConfig(&api.LoadConfig{
+		FollowPointers:     true,
+		MaxVariableRecurse: 1,
+		MaxStringLen:       64,
+		MaxArrayValues:     64,
+		MaxStructFields:    -1,
+	})
+
 	logger.Debug("Stepping out")
-	nextState, err := c.client.StepOut()
+	nextState, err := c.interruptibleStep(ctx, delveState, c.client.StepOut)
 	if err != nil {
+		if errors.Is(err, ErrInterrupted) {
+			return c.createStepResponse(nextState, "out", fromLocation, err)
+		}
 		return c.createStepResponse(nil, "out", fromLocation, fmt.Errorf("step out command failed: %v", err))
 	}
 
 	return c.createStepResponse(nextState, "out", fromLocation, nil)
 }
 
+// isGoroutineEntryCaller reports whether a function only exists to start main or a goroutine
+func isGoroutineEntryCaller(function string) bool {
+	return function == "runtime.main" || function == "runtime.goexit"
+}
+
+// createHaltResponse creates a HaltResponse
+func (c *Client) createHaltResponse(state *api.DebuggerState, wasRunning bool, err error) types.HaltResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "halt"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.HaltResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	return types.HaltResponse{
+		Status:     "success",
+		Context:    context,
+		WasRunning: wasRunning,
+	}
+}
+
 // createContinueResponse creates a ContinueResponse from a DebuggerState
 func (c *Client) createContinueResponse(state *api.DebuggerState, err error) types.ContinueResponse {
 	context := c.createDebugContext(state)
@@ -139,6 +518,42 @@ func (c *Client) createContinueResponse(state *api.DebuggerState, err error) typ
 	}
 }
 
+// createContinueToLineResponse creates a ContinueToLineResponse
+func (c *Client) createContinueToLineResponse(state *api.DebuggerState, file string, line int, tempBP *api.Breakpoint, reached bool, err error) types.ContinueToLineResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "continue_to_line"
+
+	target := fmt.Sprintf("%s:%d", file, line)
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.ContinueToLineResponse{
+			Status:  "error",
+			Context: context,
+			Target:  target,
+		}
+	}
+
+	response := types.ContinueToLineResponse{
+		Status:        "success",
+		Context:       context,
+		Target:        target,
+		ReachedTarget: reached,
+	}
+
+	if tempBP != nil {
+		breakpoint := convertBreakpoint(tempBP)
+		response.TemporaryBreakpoint = &breakpoint
+	}
+
+	// Another breakpoint stopped the program before the target line
+	if !reached && state != nil && state.CurrentThread != nil && state.CurrentThread.Breakpoint != nil {
+		breakpoint := convertBreakpoint(state.CurrentThread.Breakpoint)
+		response.InterruptedBy = &breakpoint
+	}
+
+	return response
+}
+
 // createStepResponse creates a StepResponse from a DebuggerState
 func (c *Client) createStepResponse(state *api.DebuggerState, stepType string, fromLocation *string, err error) types.StepResponse {
 	context := c.createDebugContext(state)
@@ -150,10 +565,32 @@ func (c *Client) createStepResponse(state *api.DebuggerState, stepType string, f
 		}
 	}
 
-	return types.StepResponse{
+	response := types.StepResponse{
 		Status:       "success",
 		Context:      context,
 		StepType:     stepType,
 		FromLocation: fromLocation,
 	}
+
+	if state != nil && state.CurrentThread != nil {
+		for i := range state.CurrentThread.ReturnValues {
+			v := &state.CurrentThread.ReturnValues[i]
+			response.ReturnValues = append(response.ReturnValues, types.Variable{
+				DelveVar: v,
+				Name:     v.Name,
+				Value:    formatVariableValue(v),
+				Type:     v.Type,
+				Scope:    "return",
+				Kind:     getVariableKind(v),
+			})
+		}
+
+		// A breakpoint hit during the step stops it early
+		if bp := state.CurrentThread.Breakpoint; bp != nil {
+			breakpoint := convertBreakpoint(bp)
+			response.InterruptedBy = &breakpoint
+		}
+	}
+
+	return response
 }
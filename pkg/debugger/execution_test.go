@@ -0,0 +1,102 @@
+package debugger
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestDescribeStepTransition(t *testing.T) {
+	testCases := []struct {
+		name       string
+		before     []string
+		after      []string
+		transition string
+		note       string
+	}{
+		{
+			name:   "same frame",
+			before: []string{"main.handler", "main.main", "runtime.main"},
+			after:  []string{"main.handler", "main.main", "runtime.main"},
+		},
+		{
+			name:       "return to caller",
+			before:     []string{"main.handler", "main.main", "runtime.main"},
+			after:      []string{"main.main", "runtime.main"},
+			transition: types.StepTransitionReturned,
+			note:       "returned from main.handler to its caller main.main",
+		},
+		{
+			name:       "panic stops in deferred function",
+			before:     []string{"main.handler", "main.main", "runtime.main"},
+			after:      []string{"main.handler.func1", "runtime.gopanic", "main.handler", "main.main", "runtime.main"},
+			transition: types.StepTransitionPanic,
+			note:       "main.handler panicked; stopped in the deferred function main.handler.func1, where the panic can be recovered",
+		},
+		{
+			name:       "deferred call on return",
+			before:     []string{"main.handler", "main.main", "runtime.main"},
+			after:      []string{"main.handler.func1", "main.handler", "main.main", "runtime.main"},
+			transition: types.StepTransitionEntered,
+			note:       "entered main.handler.func1 from main.handler",
+		},
+		{
+			name:   "process exited",
+			before: []string{"main.main", "runtime.main"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transition, note := describeStepTransition(tc.before, tc.after)
+			if transition != tc.transition {
+				t.Errorf("Expected transition %q, got %q", tc.transition, transition)
+			}
+			if note != tc.note {
+				t.Errorf("Expected note %q, got %q", tc.note, note)
+			}
+		})
+	}
+}
+
+func TestHaltedStepLocation(t *testing.T) {
+	spin := api.Location{PC: 0x4b659b, File: "/app/main.go", Line: 12, Function: &api.Function{Name_: "main.spin"}}
+	gopark := api.Location{PC: 0x484a91, File: "/usr/local/go/src/runtime/proc.go", Line: 475, Function: &api.Function{Name_: "runtime.gopark"}}
+	sleep := api.Location{PC: 0x48729c, File: "/usr/local/go/src/runtime/time.go", Line: 368, Function: &api.Function{Name_: "time.Sleep"}}
+
+	testCases := []struct {
+		name     string
+		state    *api.DebuggerState
+		expected string
+	}{
+		{
+			name:     "Looping in user code",
+			state:    &api.DebuggerState{SelectedGoroutine: &api.Goroutine{ID: 1, CurrentLoc: spin, UserCurrentLoc: spin}},
+			expected: "/app/main.go:12 in main.spin",
+		},
+		{
+			name:     "Blocked in the runtime",
+			state:    &api.DebuggerState{SelectedGoroutine: &api.Goroutine{ID: 1, CurrentLoc: gopark, UserCurrentLoc: sleep}},
+			expected: "/usr/local/go/src/runtime/proc.go:475 in runtime.gopark, called from /usr/local/go/src/runtime/time.go:368 in time.Sleep",
+		},
+		{
+			name:     "No goroutine",
+			state:    &api.DebuggerState{CurrentThread: &api.Thread{File: "/app/main.go", Line: 12, Function: &api.Function{Name_: "main.spin"}}},
+			expected: "/app/main.go:12 in main.spin",
+		},
+		{
+			name:     "Nowhere known",
+			state:    &api.DebuggerState{},
+			expected: "an unknown location",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := haltedStepLocation(tc.state); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
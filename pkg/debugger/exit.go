@@ -0,0 +1,56 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+)
+
+// ErrProcessExited is reported for commands that need a live process once the target has exited
+var ErrProcessExited = errors.New("process has exited")
+
+// exitedPattern matches the error Delve returns for commands on a process that has exited,
+// such as "Process 1234 has exited with status 0"
+var exitedPattern = regexp.MustCompile(`Process \d+ has exited with status (-?\d+)`)
+
+// noteExit records that the target has exited when a state or a Delve error says so, so
+// later commands can report that instead of failing on the missing process
+func (c *Client) noteExit(state *api.DebuggerState, err error) {
+	var status int
+	switch {
+	case state != nil && state.Exited:
+		status = state.ExitStatus
+	case err != nil && exitedPattern.MatchString(err.Error()):
+		status, _ = strconv.Atoi(exitedPattern.FindStringSubmatch(err.Error())[1])
+	default:
+		return
+	}
+
+	if c.processExited.Load() {
+		return
+	}
+	logger.Debug("Target process exited with status %d", status)
+	c.exitStatus.Store(int64(status))
+	c.processExited.Store(true)
+}
+
+// Exited reports whether the target process has exited, and with which status. The session
+// stays open so its output, trace and breakpoints can still be read, until it is restarted
+// or closed.
+func (c *Client) Exited() (bool, int) {
+	if !c.processExited.Load() {
+		return false, 0
+	}
+	return true, int(c.exitStatus.Load())
+}
+
+// ExitedError describes why a command that needs a live process cannot run once the target
+// has exited
+func (c *Client) ExitedError() error {
+	_, status := c.Exited()
+	return fmt.Errorf("%w with status %d, restart required: use restart to run it again, or close to end the session", ErrProcessExited, status)
+}
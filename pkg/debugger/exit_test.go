@@ -0,0 +1,63 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestNoteExit(t *testing.T) {
+	testCases := []struct {
+		name           string
+		state          *api.DebuggerState
+		err            error
+		expectedExited bool
+		expectedStatus int
+	}{
+		{name: "Stopped", state: &api.DebuggerState{}, expectedExited: false},
+		{name: "Exited state", state: &api.DebuggerState{Exited: true, ExitStatus: 3}, expectedExited: true, expectedStatus: 3},
+		{name: "Exited error", err: errors.New("Process 1234 has exited with status 2"), expectedExited: true, expectedStatus: 2},
+		{name: "Wrapped exited error", err: fmt.Errorf("failed to get state: %w", errors.New("Process 1234 has exited with status -1")), expectedExited: true, expectedStatus: -1},
+		{name: "Other error", err: errors.New("connection refused"), expectedExited: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Client{}
+			c.noteExit(tc.state, tc.err)
+			exited, status := c.Exited()
+			if exited != tc.expectedExited {
+				t.Fatalf("Expected exited %v, got %v", tc.expectedExited, exited)
+			}
+			if status != tc.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tc.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestNoteExitKeepsFirstStatus(t *testing.T) {
+	c := &Client{}
+	c.noteExit(&api.DebuggerState{Exited: true, ExitStatus: 1}, nil)
+	c.noteExit(nil, errors.New("Process 1234 has exited with status 0"))
+
+	if _, status := c.Exited(); status != 1 {
+		t.Errorf("Expected status 1, got %d", status)
+	}
+}
+
+func TestExitedError(t *testing.T) {
+	c := &Client{}
+	c.noteExit(&api.DebuggerState{Exited: true, ExitStatus: 7}, nil)
+
+	err := c.ExitedError()
+	if !errors.Is(err, ErrProcessExited) {
+		t.Errorf("Expected error to wrap ErrProcessExited, got %v", err)
+	}
+	expected := "process has exited with status 7, restart required: use restart to run it again, or close to end the session"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
@@ -0,0 +1,285 @@
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+// fakeHandler answers a Delve API call, given the JSON of its argument
+type fakeHandler func(args json.RawMessage) (interface{}, error)
+
+// fakeDelve answers Delve's JSON-RPC API over an in-memory connection with the handlers
+// a test gives it, keyed by method name, so Client methods can be tested against a target
+// in a known state without running one. Calls without a handler fail, naming the method.
+type fakeDelve struct {
+	mu       sync.Mutex
+	handlers map[string]fakeHandler
+	calls    []string
+}
+
+// newFakeDelve returns a client whose session is connected to a fakeDelve
+func newFakeDelve(t *testing.T, handlers map[string]fakeHandler) (*Client, *fakeDelve) {
+	t.Helper()
+	f := &fakeDelve{handlers: handlers}
+	serverConn, clientConn := net.Pipe()
+	go f.serve(serverConn)
+	t.Cleanup(func() {
+		_ = clientConn.Close()
+	})
+
+	c := NewClient()
+	c.client = rpc2.NewClientFromConn(clientConn)
+	return c, f
+}
+
+// serve answers the requests of a connection until it is closed. Each request is answered
+// on its own goroutine, as Delve does, so a halt gets through while a continue waits.
+func (f *fakeDelve) serve(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	var writing sync.Mutex
+	for {
+		var request struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+			ID     json.RawMessage   `json:"id"`
+		}
+		if err := decoder.Decode(&request); err != nil {
+			return
+		}
+
+		go func() {
+			var args json.RawMessage
+			if len(request.Params) > 0 {
+				args = request.Params[0]
+			}
+			result, err := f.call(strings.TrimPrefix(request.Method, "RPCServer."), args)
+
+			response := struct {
+				ID     json.RawMessage `json:"id"`
+				Result interface{}     `json:"result"`
+				Error  interface{}     `json:"error"`
+			}{ID: request.ID, Result: result}
+			if err != nil {
+				response.Result, response.Error = nil, err.Error()
+			}
+
+			writing.Lock()
+			defer writing.Unlock()
+			_ = encoder.Encode(response)
+		}()
+	}
+}
+
+// call records a call and answers it with its handler
+func (f *fakeDelve) call(method string, args json.RawMessage) (interface{}, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, method)
+	handler := f.handlers[method]
+	f.mu.Unlock()
+
+	if handler != nil {
+		return handler(args)
+	}
+	if method == "SetApiVersion" {
+		return api.SetAPIVersionOut{}, nil
+	}
+	return nil, fmt.Errorf("fake Delve has no handler for %s", method)
+}
+
+// handle sets the handler of a method, replacing any it had
+func (f *fakeDelve) handle(method string, handler fakeHandler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.handlers[method] = handler
+}
+
+// called returns how many times a method was called
+func (f *fakeDelve) called(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := 0
+	for _, call := range f.calls {
+		if call == method {
+			n++
+		}
+	}
+	return n
+}
+
+// fakeState answers State with state, whether the call blocks or not
+func fakeState(state *api.DebuggerState) fakeHandler {
+	return func(json.RawMessage) (interface{}, error) {
+		return rpc2.StateOut{State: state}, nil
+	}
+}
+
+// fakeResult answers a call with the same result every time
+func fakeResult(result interface{}) fakeHandler {
+	return func(json.RawMessage) (interface{}, error) {
+		return result, nil
+	}
+}
+
+// decodeFakeArgs decodes the argument of a call into args
+func decodeFakeArgs(t *testing.T, raw json.RawMessage, args interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(raw, args); err != nil {
+		t.Errorf("Failed to decode call arguments %s: %v", raw, err)
+	}
+}
+
+// fakeCommands answers Command, which runs the target, with the state next returns for
+// each command, and records the names of the commands run
+func fakeCommands(t *testing.T, names *[]string, next func(command api.DebuggerCommand) api.DebuggerState) fakeHandler {
+	var mu sync.Mutex
+	return func(raw json.RawMessage) (interface{}, error) {
+		var command api.DebuggerCommand
+		decodeFakeArgs(t, raw, &command)
+		mu.Lock()
+		*names = append(*names, command.Name)
+		mu.Unlock()
+		return rpc2.CommandOut{State: next(command)}, nil
+	}
+}
+
+// fakeBreakpoints keeps the breakpoints of a fake Delve, numbering new ones from 1 as
+// Delve does. functions maps file:line locations to the function they are in; other
+// locations can't be resolved, unless functions is nil, which puts every line in main.main.
+// Breakpoints on a function or on addresses are created as they are given.
+type fakeBreakpoints struct {
+	mu        sync.Mutex
+	bps       map[int]*api.Breakpoint
+	lastID    int
+	functions map[string]string
+}
+
+// add keeps a breakpoint that already exists, as when setting up a test's target
+func (b *fakeBreakpoints) add(bp *api.Breakpoint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.bps == nil {
+		b.bps = make(map[int]*api.Breakpoint)
+	}
+	b.bps[bp.ID] = bp
+	if bp.ID > b.lastID {
+		b.lastID = bp.ID
+	}
+}
+
+// get returns a copy of a breakpoint, or nil when there is none with the ID
+func (b *fakeBreakpoints) get(id int) *api.Breakpoint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bp := b.bps[id]
+	if bp == nil {
+		return nil
+	}
+	copied := *bp
+	return &copied
+}
+
+// serve adds the handlers of the breakpoint methods to handlers
+func (b *fakeBreakpoints) serve(t *testing.T, handlers map[string]fakeHandler) map[string]fakeHandler {
+	handlers["CreateBreakpoint"] = func(raw json.RawMessage) (interface{}, error) {
+		var args rpc2.CreateBreakpointIn
+		decodeFakeArgs(t, raw, &args)
+		bp := args.Breakpoint
+		if len(bp.Addrs) == 0 && bp.File != "" {
+			function, ok := "main.main", b.functions == nil
+			if !ok {
+				function, ok = b.functions[fmt.Sprintf("%s:%d", bp.File, bp.Line)]
+			}
+			if !ok {
+				return nil, fmt.Errorf("could not find statement at %s:%d", bp.File, bp.Line)
+			}
+			bp.FunctionName = function
+		}
+
+		b.mu.Lock()
+		b.lastID++
+		bp.ID = b.lastID
+		b.mu.Unlock()
+		b.add(&bp)
+		return rpc2.CreateBreakpointOut{Breakpoint: bp}, nil
+	}
+	handlers["ListBreakpoints"] = func(json.RawMessage) (interface{}, error) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		bps := make([]*api.Breakpoint, 0, len(b.bps))
+		for _, bp := range b.bps {
+			bps = append(bps, bp)
+		}
+		return rpc2.ListBreakpointsOut{Breakpoints: bps}, nil
+	}
+	handlers["GetBreakpoint"] = func(raw json.RawMessage) (interface{}, error) {
+		var args rpc2.GetBreakpointIn
+		decodeFakeArgs(t, raw, &args)
+		bp := b.get(args.Id)
+		if bp == nil {
+			return nil, fmt.Errorf("no breakpoint with id %d", args.Id)
+		}
+		return rpc2.GetBreakpointOut{Breakpoint: *bp}, nil
+	}
+	handlers["AmendBreakpoint"] = func(raw json.RawMessage) (interface{}, error) {
+		var args rpc2.AmendBreakpointIn
+		decodeFakeArgs(t, raw, &args)
+		if b.get(args.Breakpoint.ID) == nil {
+			return nil, fmt.Errorf("no breakpoint with id %d", args.Breakpoint.ID)
+		}
+		b.add(&args.Breakpoint)
+		return rpc2.AmendBreakpointOut{}, nil
+	}
+	handlers["ClearBreakpoint"] = func(raw json.RawMessage) (interface{}, error) {
+		var args rpc2.ClearBreakpointIn
+		decodeFakeArgs(t, raw, &args)
+		bp := b.get(args.Id)
+		if bp == nil {
+			return nil, fmt.Errorf("no breakpoint with id %d", args.Id)
+		}
+		b.mu.Lock()
+		delete(b.bps, args.Id)
+		b.mu.Unlock()
+		return rpc2.ClearBreakpointOut{Breakpoint: bp}, nil
+	}
+	return handlers
+}
+
+// fakeEval answers Eval with the value of each expression in values, failing like Delve
+// on a name it can't find for the others
+func fakeEval(t *testing.T, values map[string]*api.Variable) fakeHandler {
+	return func(raw json.RawMessage) (interface{}, error) {
+		var args rpc2.EvalIn
+		decodeFakeArgs(t, raw, &args)
+		v := values[args.Expr]
+		if v == nil {
+			return nil, fmt.Errorf("could not find symbol value for %s", args.Expr)
+		}
+		return rpc2.EvalOut{Variable: v}, nil
+	}
+}
+
+// stoppedState is the state of a target stopped at line of main.go in main.main, on
+// goroutine 1
+func stoppedState(line int) *api.DebuggerState {
+	return &api.DebuggerState{
+		CurrentThread:     &api.Thread{ID: 1, File: "main.go", Line: line, Function: &api.Function{Name_: "main.main"}, GoroutineID: 1},
+		SelectedGoroutine: &api.Goroutine{ID: 1},
+	}
+}
@@ -0,0 +1,39 @@
+package debugger
+
+import (
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// SetFollowPointers sets whether evaluations and variable listings of the session load what
+// pointers point to, as they do by default, or only the pointers' addresses. Following
+// pointers shows the values behind them but pulls in whole graphs of linked structures;
+// without it, each pointer renders as its type and address, or nil, which keeps the output
+// of pointer-heavy values small. Each eval and listing can override the setting.
+func (c *Client) SetFollowPointers(follow bool) types.FollowPointersResponse {
+	logger.Debug("Setting follow pointers to %v", follow)
+	c.addressOnlyPointers = !follow
+
+	context := c.createDebugContext(nil)
+	context.Operation = "set_follow_pointers"
+
+	response := types.FollowPointersResponse{
+		Status:         "success",
+		Context:        context,
+		FollowPointers: follow,
+		Message:        "pointers are followed and the values they point to loaded",
+	}
+	if !follow {
+		response.Message = "pointers are loaded as their type and address only; dereference one, e.g. *p, to load what it points to"
+	}
+	return response
+}
+
+// followPointers reports whether a load follows pointers: as override says, or else as the
+// session is set to
+func (c *Client) followPointers(override *bool) bool {
+	if override != nil {
+		return *override
+	}
+	return !c.addressOnlyPointers
+}
@@ -0,0 +1,85 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestFollowPointers(t *testing.T) {
+	follow, addressOnly := true, false
+	c := NewClient()
+	if !c.followPointers(nil) {
+		t.Errorf("Expected pointers to be followed by default")
+	}
+
+	response := c.SetFollowPointers(false)
+	if response.Status != "success" || response.FollowPointers || response.Context.Operation != "set_follow_pointers" {
+		t.Errorf("Expected set_follow_pointers to succeed with followPointers false, got %+v", response)
+	}
+	if c.followPointers(nil) {
+		t.Errorf("Expected pointers not to be followed once turned off")
+	}
+	if !c.followPointers(&follow) {
+		t.Errorf("Expected a call to override the session's setting")
+	}
+
+	c.SetFollowPointers(true)
+	if c.followPointers(&addressOnly) {
+		t.Errorf("Expected a call to override the session's setting")
+	}
+}
+
+func TestFormatAddressOnlyPointer(t *testing.T) {
+	pointer := func(addr uint64, onlyAddr bool) api.Variable {
+		return api.Variable{
+			Name:     "next",
+			Type:     "*main.node",
+			Kind:     reflect.Ptr,
+			Value:    "824634330816",
+			Children: []api.Variable{{Type: "main.node", Kind: reflect.Struct, Addr: addr, OnlyAddr: onlyAddr}},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		variable api.Variable
+		expected string
+	}{
+		{name: "Address only", variable: pointer(0xc000012340, true), expected: "(*main.node)(0xc000012340)"},
+		{name: "Nil", variable: pointer(0, true), expected: "nil"},
+		{name: "Followed", variable: pointer(0xc000012340, false), expected: "824634330816"},
+		{
+			name:     "Struct field",
+			variable: api.Variable{Kind: reflect.Struct, Children: []api.Variable{{Name: "val", Kind: reflect.Int, Value: "1"}, pointer(0xc000012340, true)}},
+			expected: "{val:1, next:(*main.node)(0xc000012340)}",
+		},
+		{
+			name:     "Slice element",
+			variable: api.Variable{Kind: reflect.Slice, Len: 1, Children: []api.Variable{pointer(0, true)}},
+			expected: "[nil]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := formatVariableValue(&tc.variable); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestConvertAddressOnlyPointer(t *testing.T) {
+	v := api.Variable{
+		Name:     "head",
+		Type:     "*main.node",
+		Kind:     reflect.Ptr,
+		Children: []api.Variable{{Type: "main.node", Kind: reflect.Struct, Addr: 0xc000012340, OnlyAddr: true}},
+	}
+	result := convertVariableTree(&v, "local", 2)
+	if result.Value != "(*main.node)(0xc000012340)" || len(result.Children) != 0 {
+		t.Errorf("Expected the pointer as its address without children, got %q with %d children", result.Value, len(result.Children))
+	}
+}
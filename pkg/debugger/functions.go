@@ -0,0 +1,125 @@
+package debugger
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxFunctionResults caps how many functions a single listing returns, since each one
+// needs a lookup of its definition
+const maxFunctionResults = 200
+
+// ListFunctions returns the functions of the target whose names match the filter regex,
+// sorted by name, with the file and line defining each. An empty filter matches every
+// function; pkg, when set, keeps only functions of packages with that import path prefix.
+// Total counts every match, also when the page is cut short by limit.
+func (c *Client) ListFunctions(filter, pkg string, limit, offset int) types.FunctionListResponse {
+	if c.client == nil {
+		return c.createFunctionListResponse(nil, nil, 0, limit, offset, fmt.Errorf("no active debug session"))
+	}
+
+	if _, err := regexp.Compile(filter); err != nil {
+		return c.createFunctionListResponse(nil, nil, 0, limit, offset, fmt.Errorf("invalid filter %q: %v", filter, err))
+	}
+
+	if limit <= 0 || limit > maxFunctionResults {
+		limit = maxFunctionResults
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createFunctionListResponse(nil, nil, 0, limit, offset, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createFunctionListResponse(nil, nil, 0, limit, offset, fmt.Errorf("cannot list functions while the target is running; stop the target first"))
+	}
+
+	logger.Debug("Listing functions matching %q in package %q, limit %d, offset %d", filter, pkg, limit, offset)
+
+	names, err := c.client.ListFunctions(filter, 0)
+	if err != nil {
+		return c.createFunctionListResponse(state, nil, 0, limit, offset, fmt.Errorf("failed to list functions: %v", err))
+	}
+
+	var matched []string
+	for _, name := range names {
+		if pkg == "" || strings.HasPrefix(functionPackage(name), pkg) {
+			matched = append(matched, name)
+		}
+	}
+	sort.Strings(matched)
+
+	start, end := pageBounds(len(matched), limit, offset)
+
+	functions := make([]types.FunctionInfo, 0, end-start)
+	for _, name := range matched[start:end] {
+		functions = append(functions, c.describeFunction(name))
+	}
+
+	return c.createFunctionListResponse(state, functions, len(matched), limit, offset, nil)
+}
+
+// describeFunction looks up where a function is defined. Functions without line
+// information, such as compiler-generated ones, are returned without a file.
+func (c *Client) describeFunction(name string) types.FunctionInfo {
+	info := types.FunctionInfo{
+		Name:    name,
+		Package: functionPackage(name),
+	}
+
+	locs, _, err := c.client.FindLocation(api.EvalScope{GoroutineID: -1}, name, false, nil)
+	if err != nil {
+		logger.Debug("Warning: Failed to find location of %s: %v", name, err)
+		return info
+	}
+	if len(locs) > 0 {
+		info.File = locs[0].File
+		info.Line = locs[0].Line
+	}
+	return info
+}
+
+// functionPackage returns the import path of the package a function belongs to, e.g.
+// "net/http" for "net/http.(*Server).Serve"
+func functionPackage(name string) string {
+	// Only the last path element can contain the dot separating the package from the function
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return ""
+	}
+	return name[:slash+1+dot]
+}
+
+// createFunctionListResponse creates a FunctionListResponse
+func (c *Client) createFunctionListResponse(state *api.DebuggerState, functions []types.FunctionInfo, total, limit, offset int, err error) types.FunctionListResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "list_functions"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.FunctionListResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	return types.FunctionListResponse{
+		Status:    "success",
+		Context:   context,
+		Functions: functions,
+		Total:     total,
+		Truncated: offset+len(functions) < total,
+		Limit:     limit,
+		Offset:    offset,
+	}
+}
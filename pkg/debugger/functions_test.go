@@ -0,0 +1,23 @@
+package debugger
+
+import "testing"
+
+func TestFunctionPackage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "main.main", expected: "main"},
+		{name: "main.(*Server).handle", expected: "main"},
+		{name: "net/http.(*Server).Serve", expected: "net/http"},
+		{name: "github.com/sunfmin/mcp-go-debugger/pkg/debugger.NewClient", expected: "github.com/sunfmin/mcp-go-debugger/pkg/debugger"},
+		{name: "github.com/example/app.init.0.func1", expected: "github.com/example/app"},
+		{name: "noPackage", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		if pkg := functionPackage(tc.name); pkg != tc.expected {
+			t.Errorf("Expected package %q for %s, got %q", tc.expected, tc.name, pkg)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package debugger
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxPackageVariables caps how many package variables a single listing returns
+const maxPackageVariables = 200
+
+// PackageVariableOptions controls which package variables ListPackageVariables returns
+// and how much of each is loaded
+type PackageVariableOptions struct {
+	Package        string // Only variables of packages with this import path prefix
+	IncludeRuntime bool   // Include variables of the runtime and standard library
+
+	Depth          int // How many levels of nested values to expand
+	MaxStringLen   int // Longest string value loaded, 0 for the default
+	MaxArrayValues int // Most slice, array or map elements loaded, 0 for the default
+}
+
+// ListPackageVariables returns the package-level variables whose fully-qualified names
+// match the filter regex, sorted by name. Variables of the runtime and standard library
+// are left out unless opts.IncludeRuntime is set. Total counts every match, also when
+// the listing is cut short.
+func (c *Client) ListPackageVariables(filter string, opts PackageVariableOptions) types.PackageVariablesResponse {
+	if c.client == nil {
+		return c.createPackageVariablesResponse(nil, filter, nil, 0, fmt.Errorf("no active debug session"))
+	}
+
+	if _, err := regexp.Compile(filter); err != nil {
+		return c.createPackageVariablesResponse(nil, filter, nil, 0, fmt.Errorf("invalid filter %q: %v", filter, err))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createPackageVariablesResponse(nil, filter, nil, 0, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createPackageVariablesResponse(nil, filter, nil, 0, fmt.Errorf("cannot list variables while the target is running; stop the target first"))
+	}
+
+	cfg, depth := variableLoadConfig(VariableListOptions{
+		Depth:          opts.Depth,
+		MaxStringLen:   opts.MaxStringLen,
+		MaxArrayValues: opts.MaxArrayValues,
+	})
+	cfg.FollowPointers = c.followPointers(nil)
+
+	logger.Debug("Listing package variables matching %q in package %q with depth %d", filter, opts.Package, depth)
+
+	vars, err := c.client.ListPackageVariables(filter, cfg)
+	if err != nil {
+		return c.createPackageVariablesResponse(state, filter, nil, 0, fmt.Errorf("failed to list package variables: %v", err))
+	}
+
+	var matched []*api.Variable
+	for i := range vars {
+		v := &vars[i]
+		if !opts.IncludeRuntime && isRuntimeFunction(v.Name) {
+			continue
+		}
+		if opts.Package != "" && !strings.HasPrefix(functionPackage(v.Name), opts.Package) {
+			continue
+		}
+		matched = append(matched, v)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Name < matched[j].Name })
+
+	variables := make([]types.Variable, 0, min(len(matched), maxPackageVariables))
+	for _, v := range matched[:min(len(matched), maxPackageVariables)] {
+		variables = append(variables, convertVariableTree(v, "package", depth))
+	}
+
+	return c.createPackageVariablesResponse(state, filter, variables, len(matched), nil)
+}
+
+// createPackageVariablesResponse creates a PackageVariablesResponse
+func (c *Client) createPackageVariablesResponse(state *api.DebuggerState, filter string, variables []types.Variable, total int, err error) types.PackageVariablesResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "list_package_variables"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.PackageVariablesResponse{
+			Status:  "error",
+			Context: context,
+			Filter:  filter,
+		}
+	}
+
+	return types.PackageVariablesResponse{
+		Status:    "success",
+		Context:   context,
+		Filter:    filter,
+		Variables: variables,
+		Total:     total,
+		Truncated: total > len(variables),
+	}
+}
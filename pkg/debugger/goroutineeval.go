@@ -0,0 +1,158 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+const (
+	defaultGoroutineEvals   = 100  // Goroutines EvalAcrossGoroutines tries when not told otherwise
+	maxGoroutineEvals       = 1000 // Most goroutines EvalAcrossGoroutines tries
+	goroutineEvalStackDepth = 50   // Frames searched for a goroutine's topmost frame in user code
+)
+
+// goroutineEvalLoadConfig keeps each result to a summary, as there may be many of them
+var goroutineEvalLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 1,
+	MaxStringLen:       256,
+	MaxArrayValues:     16,
+	MaxStructFields:    -1,
+}
+
+// EvalAcrossGoroutines evaluates an expression in each goroutine matching the filter, e.g.
+// to find the one holding a lock or a request. Parked goroutines stop inside the runtime,
+// so the expression is evaluated in each one's topmost frame outside the runtime and
+// standard library. Goroutines where a name in the expression is not in scope are left out
+// of the results, and at most limit goroutines are tried.
+func (c *Client) EvalAcrossGoroutines(expr string, filter GoroutineFilter, limit int) types.GoroutineEvalResponse {
+	if c.client == nil {
+		return c.createGoroutineEvalResponse(nil, expr, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createGoroutineEvalResponse(nil, expr, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createGoroutineEvalResponse(nil, expr, nil, fmt.Errorf("cannot evaluate expressions while the target is running; stop the target first"))
+	}
+
+	gs, _, err := c.client.ListGoroutines(0, 0)
+	if err != nil {
+		return c.createGoroutineEvalResponse(state, expr, nil, fmt.Errorf("failed to list goroutines: %v", err))
+	}
+
+	var matched []*api.Goroutine
+	for _, g := range gs {
+		if matchGoroutine(g, filter) {
+			matched = append(matched, g)
+		}
+	}
+
+	limit = clampGoroutineEvals(limit)
+	eval := &goroutineEval{total: len(matched), results: make(map[int64]types.GoroutineEvalResult)}
+	if len(matched) > limit {
+		matched = matched[:limit]
+		eval.truncated = true
+	}
+
+	logger.Debug("Evaluating %q in %d of %d goroutines", expr, len(matched), eval.total)
+	for _, g := range matched {
+		eval.evaluated++
+		frame, location := c.userFrame(g)
+		v, err := c.client.EvalVariable(api.EvalScope{GoroutineID: g.ID, Frame: frame}, expr, goroutineEvalLoadConfig)
+		if err != nil && isUnresolvedSymbol(err) {
+			eval.notInScope++
+			continue
+		}
+
+		result := types.GoroutineEvalResult{Frame: frame, Location: formatPosition(location), Position: location}
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case v == nil:
+			result.Error = fmt.Sprintf("expression %q produced no value", expr)
+		default:
+			variable := convertVariableTree(v, "", 0)
+			result.Variable = &variable
+		}
+		eval.results[g.ID] = result
+	}
+
+	return c.createGoroutineEvalResponse(state, expr, eval, nil)
+}
+
+// goroutineEval is the outcome of evaluating an expression across goroutines
+type goroutineEval struct {
+	results    map[int64]types.GoroutineEvalResult
+	total      int
+	evaluated  int
+	notInScope int
+	truncated  bool
+}
+
+// clampGoroutineEvals applies the default to a limit of 0 or less, and the ceiling
+func clampGoroutineEvals(limit int) int {
+	if limit <= 0 {
+		return defaultGoroutineEvals
+	}
+	return min(limit, maxGoroutineEvals)
+}
+
+// userFrame returns the index and position of a goroutine's topmost frame outside the
+// runtime and standard library, or of its top frame when it has none. Delve's own notion of
+// user code takes in the standard library, which a goroutine blocked on a mutex is inside.
+func (c *Client) userFrame(g *api.Goroutine) (int, *types.SourcePosition) {
+	top := getLocationPosition(g.CurrentLoc)
+	if !isRuntimeFunction(getFunctionNameFromLocation(g.CurrentLoc)) {
+		return 0, top
+	}
+
+	frames, err := c.client.Stacktrace(g.ID, goroutineEvalStackDepth, 0, nil)
+	if err != nil {
+		logger.Debug("Warning: Failed to get stack trace for goroutine %d: %v", g.ID, err)
+		return 0, top
+	}
+	for i, frame := range frames {
+		if function := getFunctionNameFromLocation(frame.Location); function != "unknown" && !isRuntimeFunction(function) {
+			return i, getFramePosition(frame)
+		}
+	}
+	return 0, top
+}
+
+// createGoroutineEvalResponse creates a GoroutineEvalResponse
+func (c *Client) createGoroutineEvalResponse(state *api.DebuggerState, expr string, eval *goroutineEval, err error) types.GoroutineEvalResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "eval_goroutines"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.GoroutineEvalResponse{
+			Status:     "error",
+			Context:    context,
+			Expression: expr,
+		}
+	}
+
+	response := types.GoroutineEvalResponse{
+		Status:     "success",
+		Context:    context,
+		Expression: expr,
+		Results:    eval.results,
+		Total:      eval.total,
+		Evaluated:  eval.evaluated,
+		NotInScope: eval.notInScope,
+		Truncated:  eval.truncated,
+	}
+
+	response.Summary = fmt.Sprintf("%s is in scope in %d of %d goroutines", expr, len(eval.results), eval.evaluated)
+	if eval.truncated {
+		response.Summary += fmt.Sprintf("; only the first %d of %d matching goroutines were tried, narrow them down with status or raise limit", eval.evaluated, eval.total)
+	}
+	return response
+}
@@ -0,0 +1,131 @@
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestClampGoroutineEvals(t *testing.T) {
+	testCases := []struct {
+		name     string
+		limit    int
+		expected int
+	}{
+		{name: "Default", limit: 0, expected: defaultGoroutineEvals},
+		{name: "Negative", limit: -5, expected: defaultGoroutineEvals},
+		{name: "Within bounds", limit: 20, expected: 20},
+		{name: "Above the ceiling", limit: 5000, expected: maxGoroutineEvals},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := clampGoroutineEvals(tc.limit); result != tc.expected {
+				t.Errorf("Expected %d, got %d", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestCreateGoroutineEvalResponse(t *testing.T) {
+	results := map[int64]types.GoroutineEvalResult{
+		7:  {Variable: &types.Variable{Value: "42"}},
+		12: {Error: "nil pointer dereference"},
+	}
+
+	testCases := []struct {
+		name            string
+		eval            *goroutineEval
+		expectedSummary string
+	}{
+		{name: "All tried", eval: &goroutineEval{results: results, total: 10, evaluated: 10, notInScope: 8}, expectedSummary: "req.ID is in scope in 2 of 10 goroutines"},
+		{name: "Truncated", eval: &goroutineEval{results: results, total: 250, evaluated: 100, notInScope: 98, truncated: true}, expectedSummary: "req.ID is in scope in 2 of 100 goroutines; only the first 100 of 250 matching goroutines were tried, narrow them down with status or raise limit"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := NewClient().createGoroutineEvalResponse(nil, "req.ID", tc.eval, nil)
+			if response.Status != "success" {
+				t.Errorf("Expected success, got %q", response.Status)
+			}
+			if response.Summary != tc.expectedSummary {
+				t.Errorf("Expected summary %q, got %q", tc.expectedSummary, response.Summary)
+			}
+			if response.Truncated != tc.eval.truncated || len(response.Results) != 2 {
+				t.Errorf("Expected truncated %v with 2 results, got %v with %d", tc.eval.truncated, response.Truncated, len(response.Results))
+			}
+		})
+	}
+}
+
+// lockingTarget returns a fake Delve with three goroutines: 1 running main.main, 2 parked
+// in the runtime while locking a mutex in main.worker, and 3 in main.other, which has no
+// mu in scope. mu holds the ID of the goroutine it is read on.
+func lockingTarget(t *testing.T) *Client {
+	t.Helper()
+	location := func(file string, line int, function string) api.Location {
+		return api.Location{File: file, Line: line, Function: &api.Function{Name_: function}}
+	}
+	gs := []*api.Goroutine{
+		{ID: 1, Status: proc.Grunning, CurrentLoc: location("/src/app/main.go", 12, "main.main"), UserCurrentLoc: location("/src/app/main.go", 12, "main.main")},
+		{ID: 2, Status: proc.Gwaiting, CurrentLoc: location("/usr/local/go/src/runtime/proc.go", 425, "runtime.gopark"), UserCurrentLoc: location("/usr/local/go/src/sync/mutex.go", 171, "sync.(*Mutex).lockSlow")},
+		{ID: 3, Status: proc.Grunning, CurrentLoc: location("/src/app/main.go", 40, "main.other"), UserCurrentLoc: location("/src/app/main.go", 40, "main.other")},
+	}
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State":          fakeState(stoppedState(12)),
+		"ListGoroutines": fakeResult(rpc2.ListGoroutinesOut{Goroutines: gs, Nextg: -1}),
+		"Stacktrace": fakeResult(rpc2.StacktraceOut{Locations: []api.Stackframe{
+			{Location: location("/usr/local/go/src/runtime/proc.go", 425, "runtime.gopark")},
+			{Location: location("/usr/local/go/src/sync/mutex.go", 171, "sync.(*Mutex).lockSlow")},
+			{Location: location("/src/app/main.go", 30, "main.worker")},
+		}}),
+		"Eval": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.EvalIn
+			decodeFakeArgs(t, raw, &args)
+			if args.Expr != "mu" || args.Scope.GoroutineID == 3 {
+				return nil, fmt.Errorf("could not find symbol value for %s", args.Expr)
+			}
+			value := fmt.Sprintf("%d/%d", args.Scope.GoroutineID, args.Scope.Frame)
+			return rpc2.EvalOut{Variable: &api.Variable{Name: "mu", Type: "string", Kind: reflect.String, Value: value, Len: int64(len(value))}}, nil
+		},
+	})
+	return c
+}
+
+func TestEvalAcrossGoroutines(t *testing.T) {
+	c := lockingTarget(t)
+
+	response := c.EvalAcrossGoroutines("mu", GoroutineFilter{}, 0)
+	if response.Status != "success" || response.Total != 3 || response.Evaluated != 3 || response.NotInScope != 1 {
+		t.Fatalf("Expected mu in scope in 2 of 3 goroutines, got %+v", response)
+	}
+	if result := response.Results[1]; result.Frame != 0 || result.Variable == nil || result.Variable.Value != "1/0" {
+		t.Errorf("Expected mu read in the top frame of goroutine 1, got %+v", result)
+	}
+	// The parked goroutine is read in its topmost frame outside the runtime and standard library
+	if result := response.Results[2]; result.Frame != 2 || result.Variable == nil || result.Variable.Value != "2/2" || result.Position == nil || result.Position.Function != "main.worker" {
+		t.Errorf("Expected mu read in frame 2 of goroutine 2, in main.worker, got %+v", result)
+	}
+	if _, ok := response.Results[3]; ok {
+		t.Errorf("Expected goroutine 3 left out, got %+v", response.Results[3])
+	}
+	if response.Summary != "mu is in scope in 2 of 3 goroutines" {
+		t.Errorf("Unexpected summary %q", response.Summary)
+	}
+
+	response = c.EvalAcrossGoroutines("mu", GoroutineFilter{}, 1)
+	if !response.Truncated || response.Evaluated != 1 || response.Total != 3 {
+		t.Errorf("Expected only 1 of 3 goroutines tried, got %+v", response)
+	}
+
+	response = c.EvalAcrossGoroutines("mu", GoroutineFilter{Status: "waiting"}, 0)
+	if response.Total != 1 || len(response.Results) != 1 || response.Results[2].Variable == nil {
+		t.Errorf("Expected only the waiting goroutine 2 tried, got %+v", response)
+	}
+}
@@ -0,0 +1,79 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// goroutinePin pins a breakpoint to the first goroutine that stops at it. Once it is
+// pinned, the breakpoint's condition is rewritten to match that goroutine only, so Delve
+// continues past hits on any other goroutine by itself.
+type goroutinePin struct {
+	goroutineID int64  // Goroutine the breakpoint is pinned to, 0 until its first hit
+	condition   string // Condition the breakpoint was set with, before it was pinned
+}
+
+// setGoroutinePin arms a breakpoint set with condition to pin itself to the goroutine of
+// its first hit
+func (c *Client) setGoroutinePin(id int, condition string) {
+	if c.goroutinePins == nil {
+		c.goroutinePins = make(map[int]*goroutinePin)
+	}
+	c.goroutinePins[id] = &goroutinePin{condition: condition}
+}
+
+// pinnedCondition returns a breakpoint condition that holds for goroutineID only, on top of
+// the condition the breakpoint had
+func pinnedCondition(condition string, goroutineID int64) string {
+	pin := fmt.Sprintf("runtime.curg.goid == %d", goroutineID)
+	if condition == "" {
+		return pin
+	}
+	return fmt.Sprintf("(%s) && %s", condition, pin)
+}
+
+// pinHitGoroutine pins the breakpoint the program stopped at to the goroutine that hit it,
+// if the breakpoint is armed to pin and is not pinned yet. It is called once hits that are
+// continued past have been skipped, so a hit a label filter or ignore count continues past
+// does not pin it.
+func (c *Client) pinHitGoroutine(state *api.DebuggerState) {
+	if state == nil || state.Exited || state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil {
+		return
+	}
+
+	bp := state.CurrentThread.Breakpoint
+	pin := c.goroutinePins[bp.ID]
+	if pin == nil || pin.goroutineID != 0 {
+		return
+	}
+
+	goroutineID := state.CurrentThread.GoroutineID
+	amended := *bp
+	amended.Cond = pinnedCondition(pin.condition, goroutineID)
+	if err := c.client.AmendBreakpoint(&amended); err != nil {
+		logger.Debug("Warning: Failed to pin breakpoint %d to goroutine %d: %v", bp.ID, goroutineID, err)
+		return
+	}
+	pin.goroutineID = goroutineID
+	bp.Cond = amended.Cond
+	logger.Debug("Pinned breakpoint %d to goroutine %d", bp.ID, goroutineID)
+}
+
+// pinnedGoroutine returns the goroutine a breakpoint is pinned to, 0 when it is not
+func (c *Client) pinnedGoroutine(id int) int64 {
+	if pin := c.goroutinePins[id]; pin != nil {
+		return pin.goroutineID
+	}
+	return 0
+}
+
+// annotateGoroutinePin adds whether a breakpoint pins itself to a goroutine, and to which
+func (c *Client) annotateGoroutinePin(breakpoint *types.Breakpoint) {
+	if pin := c.goroutinePins[breakpoint.ID]; pin != nil {
+		breakpoint.PinToFirstGoroutine = true
+		breakpoint.PinnedGoroutine = pin.goroutineID
+	}
+}
@@ -0,0 +1,85 @@
+package debugger
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestPinnedCondition(t *testing.T) {
+	testCases := []struct {
+		name        string
+		condition   string
+		goroutineID int64
+		expected    string
+	}{
+		{name: "No condition", goroutineID: 7, expected: "runtime.curg.goid == 7"},
+		{name: "With condition", condition: "i > 3 || done", goroutineID: 12, expected: "(i > 3 || done) && runtime.curg.goid == 12"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := pinnedCondition(tc.condition, tc.goroutineID); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestPinHitGoroutineSkipsUnpinned(t *testing.T) {
+	c := &Client{}
+	c.setGoroutinePin(1, "")
+	c.goroutinePins[1].goroutineID = 5
+
+	// None of these may reach Delve, which this client has no connection to
+	testCases := []struct {
+		name  string
+		state *api.DebuggerState
+	}{
+		{name: "No state"},
+		{name: "Exited", state: &api.DebuggerState{Exited: true}},
+		{name: "Not at a breakpoint", state: &api.DebuggerState{CurrentThread: &api.Thread{}}},
+		{name: "Breakpoint without pin", state: &api.DebuggerState{CurrentThread: &api.Thread{GoroutineID: 9, Breakpoint: &api.Breakpoint{ID: 2}}}},
+		{name: "Already pinned", state: &api.DebuggerState{CurrentThread: &api.Thread{GoroutineID: 9, Breakpoint: &api.Breakpoint{ID: 1}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c.pinHitGoroutine(tc.state)
+			if id := c.pinnedGoroutine(1); id != 5 {
+				t.Errorf("Expected the breakpoint to stay pinned to goroutine 5, got %d", id)
+			}
+		})
+	}
+}
+
+func TestAnnotateGoroutinePin(t *testing.T) {
+	c := &Client{}
+	c.setGoroutinePin(1, "x > 0")
+
+	breakpoint := types.Breakpoint{ID: 1}
+	c.annotateGoroutinePin(&breakpoint)
+	if !breakpoint.PinToFirstGoroutine || breakpoint.PinnedGoroutine != 0 {
+		t.Errorf("Expected an armed pin without a goroutine, got %+v", breakpoint)
+	}
+
+	c.goroutinePins[1].goroutineID = 3
+	c.annotateGoroutinePin(&breakpoint)
+	if breakpoint.PinnedGoroutine != 3 {
+		t.Errorf("Expected the breakpoint pinned to goroutine 3, got %d", breakpoint.PinnedGoroutine)
+	}
+
+	other := types.Breakpoint{ID: 2}
+	c.annotateGoroutinePin(&other)
+	if other.PinToFirstGoroutine {
+		t.Error("Expected a breakpoint without pin not to be annotated")
+	}
+}
+
+func TestFormatPinnedStop(t *testing.T) {
+	detail := &types.StopDetail{Kind: types.StopBreakpoint, BreakpointID: 1, PinnedGoroutine: 4}
+	if reason := formatStopDetail(detail); reason != "hit breakpoint pinned to goroutine 4" {
+		t.Errorf("Expected the pinned goroutine in the stop reason, got %q", reason)
+	}
+}
@@ -0,0 +1,273 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// GoroutineFilter narrows down the goroutines returned by ListGoroutines
+type GoroutineFilter struct {
+	Status   string // Goroutine status, e.g. "running", "waiting", "syscall"
+	Function string // Substring of the goroutine's current function name
+	Label    string // pprof label as "key=value", or "key" to match any value
+}
+
+// ListGoroutines returns the goroutines of the debugged program matching the filter
+func (c *Client) ListGoroutines(filter GoroutineFilter, limit, offset int) types.GoroutineListResponse {
+	if c.client == nil {
+		return c.createGoroutineListResponse(nil, nil, 0, nil, limit, offset, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		return c.createGoroutineListResponse(nil, nil, 0, nil, limit, offset, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	logger.Debug("Listing goroutines with filter %+v, limit %d, offset %d", filter, limit, offset)
+
+	// A count of 0 asks Delve for every goroutine
+	gs, _, err := c.client.ListGoroutines(0, 0)
+	if err != nil {
+		return c.createGoroutineListResponse(state, nil, 0, nil, limit, offset, fmt.Errorf("failed to list goroutines: %v", err))
+	}
+
+	var matched []*api.Goroutine
+	counts := make(map[string]int)
+	for _, g := range gs {
+		if !matchGoroutine(g, filter) {
+			continue
+		}
+		matched = append(matched, g)
+		counts[c.getGoroutineSummaryKey(g)]++
+	}
+
+	// Apply pagination after filtering so counts reflect every match
+	start, end := pageBounds(len(matched), limit, offset)
+
+	goroutines := make([]types.Goroutine, 0, end-start)
+	for _, g := range matched[start:end] {
+		goroutines = append(goroutines, c.convertGoroutine(g))
+	}
+
+	return c.createGoroutineListResponse(state, goroutines, len(matched), counts, limit, offset, nil)
+}
+
+// SwitchGoroutine makes the given goroutine the selected one for subsequent commands
+func (c *Client) SwitchGoroutine(id int64) types.SwitchGoroutineResponse {
+	if c.client == nil {
+		return c.createSwitchGoroutineResponse(nil, nil, 0, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		return c.createSwitchGoroutineResponse(nil, nil, 0, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	var previousID int64
+	if state.SelectedGoroutine != nil {
+		previousID = state.SelectedGoroutine.ID
+	}
+
+	// Make sure the goroutine exists and is still alive before switching
+	gs, _, err := c.client.ListGoroutines(0, 0)
+	if err != nil {
+		return c.createSwitchGoroutineResponse(state, nil, previousID, fmt.Errorf("failed to list goroutines: %v", err))
+	}
+
+	var target *api.Goroutine
+	for _, g := range gs {
+		if g.ID == id {
+			target = g
+			break
+		}
+	}
+
+	if target == nil {
+		return c.createSwitchGoroutineResponse(state, nil, previousID, fmt.Errorf("goroutine %d not found", id))
+	}
+	if getGoroutineStatus(target) == "dead" {
+		return c.createSwitchGoroutineResponse(state, nil, previousID, fmt.Errorf("goroutine %d has exited", id))
+	}
+
+	logger.Debug("Switching from goroutine %d to goroutine %d", previousID, id)
+	newState, err := c.client.SwitchGoroutine(id)
+	if err != nil {
+		return c.createSwitchGoroutineResponse(state, nil, previousID, fmt.Errorf("failed to switch to goroutine %d: %v", id, err))
+	}
+
+	goroutine := c.convertGoroutine(target)
+	if newState.SelectedGoroutine != nil {
+		goroutine = c.convertGoroutine(newState.SelectedGoroutine)
+	}
+
+	return c.createSwitchGoroutineResponse(newState, &goroutine, previousID, nil)
+}
+
+// CurrentGoroutine returns the goroutine subsequent commands operate on, with where it is
+// executing, the go statement that created it and the thread running it, if any
+func (c *Client) CurrentGoroutine() types.CurrentGoroutineResponse {
+	if c.client == nil {
+		return c.createCurrentGoroutineResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createCurrentGoroutineResponse(nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createCurrentGoroutineResponse(nil, nil, fmt.Errorf("cannot inspect the current goroutine while the target is running; stop the target first"))
+	}
+	if state.SelectedGoroutine == nil {
+		return c.createCurrentGoroutineResponse(state, nil, fmt.Errorf("no goroutine selected; the current thread is not running a goroutine"))
+	}
+
+	logger.Debug("Getting details of goroutine %d", state.SelectedGoroutine.ID)
+	details := c.convertGoroutineDetails(state.SelectedGoroutine)
+	return c.createCurrentGoroutineResponse(state, &details, nil)
+}
+
+// convertGoroutineDetails converts a Delve goroutine to our type, with all of its locations
+func (c *Client) convertGoroutineDetails(g *api.Goroutine) types.GoroutineDetails {
+	details := types.GoroutineDetails{
+		Goroutine:           c.convertGoroutine(g),
+		CurrentPosition:     getLocationPosition(g.CurrentLoc),
+		GoStatementPosition: getLocationPosition(g.GoStatementLoc),
+		StartPosition:       getLocationPosition(g.StartLoc),
+	}
+	details.CurrentLocation = formatPosition(details.CurrentPosition)
+	details.GoStatementLocation = formatPosition(details.GoStatementPosition)
+	details.StartLocation = formatPosition(details.StartPosition)
+	return details
+}
+
+// matchGoroutine reports whether a goroutine satisfies every set field of the filter
+func matchGoroutine(g *api.Goroutine, filter GoroutineFilter) bool {
+	if filter.Status != "" && !strings.EqualFold(getGoroutineStatus(g), filter.Status) {
+		return false
+	}
+
+	if filter.Function != "" {
+		fn := getFunctionNameFromLocation(g.UserCurrentLoc)
+		if !strings.Contains(fn, filter.Function) && !strings.Contains(getFunctionNameFromLocation(g.CurrentLoc), filter.Function) {
+			return false
+		}
+	}
+
+	if filter.Label != "" && !goroutineHasLabel(g, filter.Label) {
+		return false
+	}
+
+	return true
+}
+
+// getGoroutineSummaryKey groups goroutines by status and, when blocked, by wait reason
+func (c *Client) getGoroutineSummaryKey(g *api.Goroutine) string {
+	status := getGoroutineStatus(g)
+	if reason := c.getGoroutineWaitReason(g); reason != "" {
+		return fmt.Sprintf("%s (%s)", status, reason)
+	}
+	return status
+}
+
+// convertGoroutine converts a Delve goroutine to our type
+func (c *Client) convertGoroutine(g *api.Goroutine) types.Goroutine {
+	return types.Goroutine{
+		DelveGoroutine: g,
+		ID:             g.ID,
+		Status:         getGoroutineStatus(g),
+		WaitReason:     c.getGoroutineWaitReason(g),
+		Location:       getGoroutineLocation(g),
+		Position:       getGoroutinePosition(g),
+		ThreadID:       g.ThreadID,
+		Labels:         g.Labels,
+	}
+}
+
+// formatGoroutineCounts renders status counts as a stable, human-readable summary
+func formatGoroutineCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[k], k))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// createGoroutineListResponse creates a GoroutineListResponse
+func (c *Client) createGoroutineListResponse(state *api.DebuggerState, goroutines []types.Goroutine, total int, counts map[string]int, limit, offset int, err error) types.GoroutineListResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "list_goroutines"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.GoroutineListResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	return types.GoroutineListResponse{
+		Status:     "success",
+		Context:    context,
+		Goroutines: goroutines,
+		Total:      total,
+		Counts:     counts,
+		Summary:    formatGoroutineCounts(counts),
+		Limit:      limit,
+		Offset:     offset,
+	}
+}
+
+// createCurrentGoroutineResponse creates a CurrentGoroutineResponse
+func (c *Client) createCurrentGoroutineResponse(state *api.DebuggerState, goroutine *types.GoroutineDetails, err error) types.CurrentGoroutineResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "current_goroutine"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.CurrentGoroutineResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	return types.CurrentGoroutineResponse{
+		Status:    "success",
+		Context:   context,
+		Goroutine: *goroutine,
+	}
+}
+
+// createSwitchGoroutineResponse creates a SwitchGoroutineResponse
+func (c *Client) createSwitchGoroutineResponse(state *api.DebuggerState, goroutine *types.Goroutine, previousID int64, err error) types.SwitchGoroutineResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "switch_goroutine"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.SwitchGoroutineResponse{
+			Status:              "error",
+			Context:             context,
+			PreviousGoroutineID: previousID,
+		}
+	}
+
+	return types.SwitchGoroutineResponse{
+		Status:              "success",
+		Context:             context,
+		Goroutine:           *goroutine,
+		PreviousGoroutineID: previousID,
+	}
+}
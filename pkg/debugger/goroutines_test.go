@@ -0,0 +1,273 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+func TestConvertGoroutineDetails(t *testing.T) {
+	g := &api.Goroutine{
+		ID:             7,
+		CurrentLoc:     api.Location{File: "/usr/local/go/src/runtime/proc.go", Line: 402, Function: &api.Function{Name_: "runtime.gopark"}},
+		UserCurrentLoc: api.Location{File: "/app/worker.go", Line: 21, Function: &api.Function{Name_: "main.worker"}},
+		GoStatementLoc: api.Location{File: "/app/main.go", Line: 12, Function: &api.Function{Name_: "main.main"}},
+		StartLoc:       api.Location{File: "/app/worker.go", Line: 18, Function: &api.Function{Name_: "main.worker"}},
+		ThreadID:       3,
+		Labels:         map[string]string{"job": "sync"},
+	}
+
+	details := NewClient().convertGoroutineDetails(g)
+	if details.ID != 7 || details.ThreadID != 3 || details.Labels["job"] != "sync" {
+		t.Errorf("Expected goroutine 7 on thread 3 with label job=sync, got %+v", details.Goroutine)
+	}
+
+	locations := []struct {
+		name     string
+		location *string
+		expected string
+	}{
+		{name: "Current", location: details.CurrentLocation, expected: "At /usr/local/go/src/runtime/proc.go:402 in runtime.gopark"},
+		{name: "User", location: details.Location, expected: "At /app/worker.go:21 in main.worker"},
+		{name: "Go statement", location: details.GoStatementLocation, expected: "At /app/main.go:12 in main.main"},
+		{name: "Start", location: details.StartLocation, expected: "At /app/worker.go:18 in main.worker"},
+	}
+	for _, tc := range locations {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.location == nil || *tc.location != tc.expected {
+				t.Errorf("Expected %s, got %v", tc.expected, tc.location)
+			}
+		})
+	}
+
+	if main := NewClient().convertGoroutineDetails(&api.Goroutine{ID: 1}); main.GoStatementLocation != nil {
+		t.Errorf("Expected no go statement location, got %s", *main.GoStatementLocation)
+	}
+}
+
+// goroutineAt builds a goroutine whose user code is at function and whose runtime location
+// is at runtimeFunction
+func goroutineAt(id int64, status uint64, waitReason int64, function, runtimeFunction string, labels map[string]string) *api.Goroutine {
+	return &api.Goroutine{
+		ID:             id,
+		Status:         status,
+		WaitReason:     waitReason,
+		CurrentLoc:     api.Location{File: "/usr/local/go/src/runtime/proc.go", Line: 402, Function: &api.Function{Name_: runtimeFunction}},
+		UserCurrentLoc: api.Location{File: "/app/main.go", Line: int(id), Function: &api.Function{Name_: function}},
+		Labels:         labels,
+	}
+}
+
+func TestMatchGoroutine(t *testing.T) {
+	g := goroutineAt(7, proc.Gwaiting, 19, "main.worker", "runtime.gopark", map[string]string{"job": "sync"})
+
+	testCases := []struct {
+		name     string
+		filter   GoroutineFilter
+		expected bool
+	}{
+		{name: "No filter", filter: GoroutineFilter{}, expected: true},
+		{name: "Status", filter: GoroutineFilter{Status: "waiting"}, expected: true},
+		{name: "Status in another case", filter: GoroutineFilter{Status: "Waiting"}, expected: true},
+		{name: "Other status", filter: GoroutineFilter{Status: "running"}, expected: false},
+		{name: "User function", filter: GoroutineFilter{Function: "worker"}, expected: true},
+		{name: "Runtime function", filter: GoroutineFilter{Function: "gopark"}, expected: true},
+		{name: "Other function", filter: GoroutineFilter{Function: "main.serve"}, expected: false},
+		{name: "Label key", filter: GoroutineFilter{Label: "job"}, expected: true},
+		{name: "Label key and value", filter: GoroutineFilter{Label: "job=sync"}, expected: true},
+		{name: "Other label value", filter: GoroutineFilter{Label: "job=async"}, expected: false},
+		{name: "Missing label", filter: GoroutineFilter{Label: "tenant"}, expected: false},
+		{name: "Every field", filter: GoroutineFilter{Status: "waiting", Function: "worker", Label: "job=sync"}, expected: true},
+		{name: "One field not matching", filter: GoroutineFilter{Status: "waiting", Function: "worker", Label: "tenant"}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if matched := matchGoroutine(g, tc.filter); matched != tc.expected {
+				t.Errorf("Expected filter %+v to match %v, got %v", tc.filter, tc.expected, matched)
+			}
+		})
+	}
+}
+
+// goroutinesTarget returns a fake Delve whose target has four goroutines blocked on a
+// channel receive in main.worker, one sleeping and one running main.main, and whose runtime
+// has the wait reasons of reasons
+func goroutinesTarget(t *testing.T, reasons *api.Variable) (*Client, *fakeDelve) {
+	t.Helper()
+	gs := []*api.Goroutine{goroutineAt(1, proc.Grunning, 0, "main.main", "main.main", nil)}
+	for id := int64(2); id <= 5; id++ {
+		gs = append(gs, goroutineAt(id, proc.Gwaiting, 19, "main.worker", "runtime.gopark", map[string]string{"job": "sync"}))
+	}
+	gs = append(gs, goroutineAt(6, proc.Gwaiting, 14, "main.tick", "runtime.gopark", nil))
+
+	return newFakeDelve(t, map[string]fakeHandler{
+		"State":          fakeState(stoppedState(5)),
+		"ListGoroutines": fakeResult(rpc2.ListGoroutinesOut{Goroutines: gs, Nextg: -1}),
+		"Eval":           fakeEval(t, map[string]*api.Variable{"runtime.waitReasonStrings": reasons}),
+	})
+}
+
+// waitReasonStrings is the runtime's waitReasonStrings array holding reasons
+func waitReasonStrings(reasons []string) *api.Variable {
+	v := &api.Variable{Name: "runtime.waitReasonStrings", Kind: reflect.Array, Len: int64(len(reasons))}
+	for _, reason := range reasons {
+		v.Children = append(v.Children, api.Variable{Kind: reflect.String, Value: reason, Len: int64(len(reason))})
+	}
+	return v
+}
+
+func TestListGoroutines(t *testing.T) {
+	// The wait reasons of the target, where 14 is sleep and 19 a channel receive
+	reasons := make([]string, 20)
+	reasons[14], reasons[19] = "sleep", "chan receive"
+
+	testCases := []struct {
+		name    string
+		filter  GoroutineFilter
+		limit   int
+		offset  int
+		ids     []int64
+		total   int
+		counts  map[string]int
+		summary string
+	}{
+		{
+			name:    "Every goroutine",
+			ids:     []int64{1, 2, 3, 4, 5, 6},
+			total:   6,
+			counts:  map[string]int{"running": 1, "waiting (chan receive)": 4, "waiting (sleep)": 1},
+			summary: "4 waiting (chan receive), 1 running, 1 waiting (sleep)",
+		},
+		{
+			name:    "Page",
+			limit:   2,
+			offset:  1,
+			ids:     []int64{2, 3},
+			total:   6,
+			counts:  map[string]int{"running": 1, "waiting (chan receive)": 4, "waiting (sleep)": 1},
+			summary: "4 waiting (chan receive), 1 running, 1 waiting (sleep)",
+		},
+		{
+			name:    "Last page",
+			limit:   4,
+			offset:  4,
+			ids:     []int64{5, 6},
+			total:   6,
+			counts:  map[string]int{"running": 1, "waiting (chan receive)": 4, "waiting (sleep)": 1},
+			summary: "4 waiting (chan receive), 1 running, 1 waiting (sleep)",
+		},
+		{
+			name:    "Offset past the end",
+			offset:  10,
+			total:   6,
+			counts:  map[string]int{"running": 1, "waiting (chan receive)": 4, "waiting (sleep)": 1},
+			summary: "4 waiting (chan receive), 1 running, 1 waiting (sleep)",
+		},
+		{
+			name:    "Negative offset",
+			limit:   1,
+			offset:  -3,
+			ids:     []int64{1},
+			total:   6,
+			counts:  map[string]int{"running": 1, "waiting (chan receive)": 4, "waiting (sleep)": 1},
+			summary: "4 waiting (chan receive), 1 running, 1 waiting (sleep)",
+		},
+		{
+			name:    "Filtered page",
+			filter:  GoroutineFilter{Status: "waiting", Label: "job=sync"},
+			limit:   3,
+			ids:     []int64{2, 3, 4},
+			total:   4,
+			counts:  map[string]int{"waiting (chan receive)": 4},
+			summary: "4 waiting (chan receive)",
+		},
+		{
+			name:    "No match",
+			filter:  GoroutineFilter{Function: "main.serve"},
+			counts:  map[string]int{},
+			summary: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := goroutinesTarget(t, waitReasonStrings(reasons))
+			response := c.ListGoroutines(tc.filter, tc.limit, tc.offset)
+			if response.Status != "success" {
+				t.Fatalf("Expected goroutines listed, got %s", response.Context.ErrorMessage)
+			}
+
+			var ids []int64
+			for _, g := range response.Goroutines {
+				ids = append(ids, g.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.ids) {
+				t.Errorf("Expected goroutines %v, got %v", tc.ids, ids)
+			}
+			// Counts are of every match, not only of the page
+			if response.Total != tc.total || !reflect.DeepEqual(response.Counts, tc.counts) {
+				t.Errorf("Expected %d matches counted as %v, got %d counted as %v", tc.total, tc.counts, response.Total, response.Counts)
+			}
+			if response.Summary != tc.summary {
+				t.Errorf("Expected summary %q, got %q", tc.summary, response.Summary)
+			}
+			if response.Limit != tc.limit || response.Offset != tc.offset {
+				t.Errorf("Expected limit %d and offset %d echoed, got %d and %d", tc.limit, tc.offset, response.Limit, response.Offset)
+			}
+		})
+	}
+}
+
+func TestGoroutineWaitReasons(t *testing.T) {
+	// The order of an older release, where 14 is a channel receive and 19 sleep
+	older := make([]string, 20)
+	older[14], older[19] = "chan receive", "sleep"
+
+	testCases := []struct {
+		name    string
+		reasons *api.Variable
+		summary string
+		reason  string
+	}{
+		{
+			name:    "Read from the target",
+			reasons: waitReasonStrings(older),
+			summary: "4 waiting (sleep), 1 running, 1 waiting (chan receive)",
+			reason:  "sleep",
+		},
+		{
+			name:    "Not readable from the target",
+			summary: "5 waiting, 1 running",
+		},
+		{
+			name:    "Reason past the end of the target's",
+			reasons: waitReasonStrings(older[:15]),
+			summary: "4 waiting (unknown wait reason 19), 1 running, 1 waiting (chan receive)",
+			reason:  "unknown wait reason 19",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, f := goroutinesTarget(t, tc.reasons)
+
+			response := c.ListGoroutines(GoroutineFilter{}, 0, 0)
+			if response.Summary != tc.summary {
+				t.Errorf("Expected summary %q, got %q", tc.summary, response.Summary)
+			}
+			if len(response.Goroutines) != 6 || response.Goroutines[1].WaitReason != tc.reason {
+				t.Errorf("Expected goroutine 2 waiting for %q, got %+v", tc.reason, response.Goroutines)
+			}
+
+			// The reasons are looked up once per session
+			c.ListGoroutines(GoroutineFilter{}, 0, 0)
+			if f.called("Eval") != 1 {
+				t.Errorf("Expected the target's wait reasons read once, got %d reads", f.called("Eval"))
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+const (
+	goroutineWatchInterval   = 250 * time.Millisecond // How long the program runs between counts
+	defaultGoroutineWatch    = 30 * time.Second       // How long to watch when the caller gives no limit
+	maxGoroutineWatch        = 5 * time.Minute        // Longest a watch may run
+	maxNewGoroutinesReported = 20                     // New goroutines listed in full when the watch ends
+)
+
+// Directions of a goroutine count watch. Above and below compare the count with the
+// threshold; grow, shrink and change compare its difference from the starting count.
+const (
+	watchAbove  = "above"
+	watchBelow  = "below"
+	watchGrow   = "grow"
+	watchShrink = "shrink"
+	watchChange = "change"
+)
+
+// WatchGoroutineCount continues the program, halting it every goroutineWatchInterval to
+// count its goroutines, until the count crosses threshold in direction: above or below it,
+// or, for grow, shrink and change, away from the starting count by at least threshold.
+// It gives up after maxWait, 0 meaning the default, and stops early if the program stops
+// by itself or ctx is done. The goroutines that are new since the start are reported, by
+// the go statement that started them, so a leak can be traced to its source.
+func (c *Client) WatchGoroutineCount(ctx context.Context, threshold int, direction string, maxWait time.Duration) types.GoroutineWatchResponse {
+	response := types.GoroutineWatchResponse{Threshold: threshold, Direction: direction}
+
+	if c.client == nil {
+		return c.finishGoroutineWatch(response, nil, fmt.Errorf("no active debug session"))
+	}
+
+	if err := validateGoroutineWatch(threshold, direction); err != nil {
+		return c.finishGoroutineWatch(response, nil, err)
+	}
+
+	if maxWait <= 0 {
+		maxWait = defaultGoroutineWatch
+	}
+	if maxWait > maxGoroutineWatch {
+		maxWait = maxGoroutineWatch
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.finishGoroutineWatch(response, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.finishGoroutineWatch(response, nil, fmt.Errorf("cannot watch the goroutine count while the target is running; stop the target first"))
+	}
+
+	before, _, err := c.client.ListGoroutines(0, 0)
+	if err != nil {
+		return c.finishGoroutineWatch(response, state, fmt.Errorf("failed to list goroutines: %v", err))
+	}
+	response.StartCount = len(before)
+	response.FinalCount = len(before)
+	if goroutineCountCrossed(response.StartCount, response.StartCount, threshold, direction) {
+		return c.finishGoroutineWatch(response, state, fmt.Errorf("the program already has %d goroutines, %s %d; pick a threshold it has yet to cross", response.StartCount, direction, threshold))
+	}
+
+	started := time.Now()
+	recordGoroutineCount(&response, 0, response.StartCount)
+
+	logger.Debug("Watching for the goroutine count of %d to go %s %d, for at most %v", response.StartCount, direction, threshold, maxWait)
+	deadline := started.Add(maxWait)
+	stoppedEarly := false
+	for remaining := maxWait; remaining > 0; remaining = time.Until(deadline) {
+		runCtx, cancel := context.WithTimeout(ctx, min(goroutineWatchInterval, remaining))
+		delveState, err := c.continueExecution(runCtx)
+		cancel()
+		halted := errors.Is(err, ErrInterrupted)
+		if err != nil && !halted {
+			return c.finishGoroutineWatch(response, nil, err)
+		}
+		if halted && ctx.Err() != nil {
+			c.reportNewGoroutines(&response, before)
+			return c.finishGoroutineWatch(response, delveState, fmt.Errorf("%v before the goroutine count went %s %d", err, direction, threshold))
+		}
+		if delveState.Exited {
+			return c.finishGoroutineWatch(response, delveState, fmt.Errorf("process exited with status %d before the goroutine count went %s %d", delveState.ExitStatus, direction, threshold))
+		}
+		state = delveState
+
+		gs, _, err := c.client.ListGoroutines(0, 0)
+		if err != nil {
+			return c.finishGoroutineWatch(response, state, fmt.Errorf("failed to list goroutines: %v", err))
+		}
+		response.Halts++
+		recordGoroutineCount(&response, time.Since(started), len(gs))
+
+		if goroutineCountCrossed(response.StartCount, len(gs), threshold, direction) {
+			response.Matched = true
+			break
+		}
+		if !halted {
+			stoppedEarly = true
+			// Stopped by something else, such as a user breakpoint or a panic
+			if thread := state.CurrentThread; thread != nil && thread.Breakpoint != nil {
+				interruptedBy := convertBreakpoint(thread.Breakpoint)
+				response.InterruptedBy = &interruptedBy
+			}
+			break
+		}
+	}
+
+	c.reportNewGoroutines(&response, before)
+	if !response.Matched && !stoppedEarly {
+		return c.finishGoroutineWatch(response, state, fmt.Errorf("timed out: the goroutine count stayed between %d and %d for %v without going %s %d", response.MinCount, response.MaxCount, maxWait, direction, threshold))
+	}
+	return c.finishGoroutineWatch(response, state, nil)
+}
+
+// validateGoroutineWatch checks that a direction is known and its threshold makes sense
+func validateGoroutineWatch(threshold int, direction string) error {
+	switch direction {
+	case watchAbove, watchBelow:
+		if threshold < 0 {
+			return fmt.Errorf("threshold must not be negative")
+		}
+	case watchGrow, watchShrink, watchChange:
+		if threshold <= 0 {
+			return fmt.Errorf("a %s watch needs a threshold of at least 1, the change from the starting count to wait for", direction)
+		}
+	default:
+		return fmt.Errorf("unknown direction %q; use %s, %s, %s, %s or %s", direction, watchAbove, watchBelow, watchGrow, watchShrink, watchChange)
+	}
+	return nil
+}
+
+// goroutineCountCrossed reports whether count, up from start, has crossed threshold in direction
+func goroutineCountCrossed(start, count, threshold int, direction string) bool {
+	switch direction {
+	case watchAbove:
+		return count > threshold
+	case watchBelow:
+		return count < threshold
+	case watchGrow:
+		return count-start >= threshold
+	case watchShrink:
+		return start-count >= threshold
+	case watchChange:
+		return count-start >= threshold || start-count >= threshold
+	}
+	return false
+}
+
+// recordGoroutineCount adds a count to the trajectory of a watch. Only counts that differ
+// from the last recorded one are kept, so a long, steady watch stays short.
+func recordGoroutineCount(response *types.GoroutineWatchResponse, elapsed time.Duration, count int) {
+	if len(response.Samples) == 0 || count < response.MinCount {
+		response.MinCount = count
+	}
+	if count > response.MaxCount {
+		response.MaxCount = count
+	}
+	response.FinalCount = count
+
+	if n := len(response.Samples); n > 0 && response.Samples[n-1].Count == count {
+		return
+	}
+	response.Samples = append(response.Samples, types.GoroutineCountSample{
+		Elapsed: elapsed.Round(time.Millisecond).String(),
+		Count:   count,
+	})
+}
+
+// reportNewGoroutines adds the goroutines that did not exist when the watch started to the
+// response, grouped by the go statement that started them
+func (c *Client) reportNewGoroutines(response *types.GoroutineWatchResponse, before []*api.Goroutine) {
+	gs, _, err := c.client.ListGoroutines(0, 0)
+	if err != nil {
+		logger.Debug("Warning: Failed to list goroutines at the end of the watch: %v", err)
+		return
+	}
+
+	fresh := newGoroutines(before, gs)
+	response.NewGoroutineCount = len(fresh)
+	response.NewGoroutineOrigins = goroutineOrigins(fresh)
+	for _, g := range fresh[:min(len(fresh), maxNewGoroutinesReported)] {
+		response.NewGoroutines = append(response.NewGoroutines, c.convertGoroutine(g))
+	}
+}
+
+// newGoroutines returns the goroutines of after that are not in before, sorted by ID
+func newGoroutines(before, after []*api.Goroutine) []*api.Goroutine {
+	seen := make(map[int64]bool, len(before))
+	for _, g := range before {
+		seen[g.ID] = true
+	}
+
+	var fresh []*api.Goroutine
+	for _, g := range after {
+		if !seen[g.ID] {
+			fresh = append(fresh, g)
+		}
+	}
+	sort.Slice(fresh, func(i, j int) bool { return fresh[i].ID < fresh[j].ID })
+	return fresh
+}
+
+// goroutineOrigins counts goroutines by the go statement that started them, most common first
+func goroutineOrigins(gs []*api.Goroutine) []types.GoroutineOrigin {
+	counts := make(map[string]int)
+	for _, g := range gs {
+		origin := "unknown go statement"
+		if loc := formatPosition(getLocationPosition(g.GoStatementLoc)); loc != nil {
+			origin = *loc
+		}
+		counts[origin]++
+	}
+
+	origins := make([]types.GoroutineOrigin, 0, len(counts))
+	for origin, count := range counts {
+		origins = append(origins, types.GoroutineOrigin{GoStatement: origin, Count: count})
+	}
+	sort.Slice(origins, func(i, j int) bool {
+		if origins[i].Count != origins[j].Count {
+			return origins[i].Count > origins[j].Count
+		}
+		return origins[i].GoStatement < origins[j].GoStatement
+	})
+	return origins
+}
+
+// finishGoroutineWatch completes a GoroutineWatchResponse. The trajectory and new
+// goroutines seen so far are kept on error.
+func (c *Client) finishGoroutineWatch(response types.GoroutineWatchResponse, state *api.DebuggerState, err error) types.GoroutineWatchResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "watch_goroutine_count"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		response.Status = "error"
+		response.Context = context
+		return response
+	}
+
+	response.Status = "success"
+	response.Context = context
+	if state != nil {
+		response.Location = getCurrentLocation(state)
+	}
+	return response
+}
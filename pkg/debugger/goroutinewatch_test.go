@@ -0,0 +1,199 @@
+package debugger
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestValidateGoroutineWatch(t *testing.T) {
+	testCases := []struct {
+		name      string
+		threshold int
+		direction string
+		expectErr bool
+	}{
+		{name: "Above", threshold: 100, direction: watchAbove},
+		{name: "Below zero", threshold: 0, direction: watchBelow},
+		{name: "Negative threshold", threshold: -1, direction: watchAbove, expectErr: true},
+		{name: "Grow", threshold: 10, direction: watchGrow},
+		{name: "Zero delta", threshold: 0, direction: watchChange, expectErr: true},
+		{name: "Unknown direction", threshold: 10, direction: "up", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateGoroutineWatch(tc.threshold, tc.direction)
+			if (err != nil) != tc.expectErr {
+				t.Errorf("Expected error %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestGoroutineCountCrossed(t *testing.T) {
+	testCases := []struct {
+		name      string
+		start     int
+		count     int
+		threshold int
+		direction string
+		expected  bool
+	}{
+		{name: "Above reached", start: 5, count: 101, threshold: 100, direction: watchAbove, expected: true},
+		{name: "At the threshold", start: 5, count: 100, threshold: 100, direction: watchAbove},
+		{name: "Below reached", start: 50, count: 9, threshold: 10, direction: watchBelow, expected: true},
+		{name: "Grown by delta", start: 5, count: 15, threshold: 10, direction: watchGrow, expected: true},
+		{name: "Not grown enough", start: 5, count: 14, threshold: 10, direction: watchGrow},
+		{name: "Shrunk by delta", start: 20, count: 10, threshold: 10, direction: watchShrink, expected: true},
+		{name: "Grow ignores shrinking", start: 20, count: 5, threshold: 10, direction: watchGrow},
+		{name: "Change either way", start: 20, count: 5, threshold: 10, direction: watchChange, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := goroutineCountCrossed(tc.start, tc.count, tc.threshold, tc.direction); result != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestRecordGoroutineCount(t *testing.T) {
+	var response types.GoroutineWatchResponse
+	for i, count := range []int{5, 5, 8, 8, 3, 6} {
+		recordGoroutineCount(&response, 0, count)
+		if i == 1 && len(response.Samples) != 1 {
+			t.Fatalf("Expected an unchanged count not to be recorded, got %+v", response.Samples)
+		}
+	}
+
+	if len(response.Samples) != 4 {
+		t.Errorf("Expected 4 samples, got %+v", response.Samples)
+	}
+	if response.MinCount != 3 || response.MaxCount != 8 || response.FinalCount != 6 {
+		t.Errorf("Expected min 3, max 8 and final 6, got %d, %d and %d", response.MinCount, response.MaxCount, response.FinalCount)
+	}
+}
+
+func TestNewGoroutineOrigins(t *testing.T) {
+	worker := api.Location{File: "/src/main.go", Line: 12, Function: &api.Function{Name_: "main.main"}}
+	before := []*api.Goroutine{{ID: 1}, {ID: 2}}
+	after := []*api.Goroutine{
+		{ID: 9, GoStatementLoc: worker},
+		{ID: 1},
+		{ID: 7, GoStatementLoc: worker},
+		{ID: 8},
+	}
+
+	fresh := newGoroutines(before, after)
+	if len(fresh) != 3 || fresh[0].ID != 7 || fresh[2].ID != 9 {
+		t.Fatalf("Expected goroutines 7, 8 and 9 sorted by ID, got %+v", fresh)
+	}
+
+	origins := goroutineOrigins(fresh)
+	if len(origins) != 2 {
+		t.Fatalf("Expected 2 origins, got %+v", origins)
+	}
+	if origins[0].GoStatement != "At /src/main.go:12 in main.main" || origins[0].Count != 2 {
+		t.Errorf("Expected the go statement at main.go:12 to head the list, got %+v", origins[0])
+	}
+	if origins[1].GoStatement != "unknown go statement" {
+		t.Errorf("Expected a goroutine without a go statement to be grouped as unknown, got %+v", origins[1])
+	}
+}
+
+// leakingTarget returns a fake Delve whose program starts with 2 goroutines and leaks one
+// each time it runs until halted, from a go statement at main.go:30. With a breakpoint
+// given, the first continue stops at it instead. The names of the commands run are recorded.
+func leakingTarget(t *testing.T, breakpoint *api.Breakpoint) (*Client, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	gs := []*api.Goroutine{{ID: 1}, {ID: 2}}
+	halts := make(chan struct{}, 1)
+
+	var commands []string
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(5)),
+		"ListGoroutines": func(json.RawMessage) (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return rpc2.ListGoroutinesOut{Goroutines: append([]*api.Goroutine(nil), gs...), Nextg: -1}, nil
+		},
+		"Command": fakeCommands(t, &commands, func(command api.DebuggerCommand) api.DebuggerState {
+			if command.Name == api.Halt {
+				halts <- struct{}{}
+				return *stoppedState(5)
+			}
+			if breakpoint != nil {
+				state := stoppedState(breakpoint.Line)
+				state.CurrentThread.Breakpoint = breakpoint
+				breakpoint = nil
+				return *state
+			}
+			<-halts
+			mu.Lock()
+			gs = append(gs, &api.Goroutine{ID: int64(len(gs) + 1), GoStatementLoc: api.Location{File: "/src/main.go", Line: 30, Function: &api.Function{Name_: "main.serve"}}})
+			mu.Unlock()
+			return *stoppedState(5)
+		}),
+	})
+	return c, &commands
+}
+
+func TestWatchGoroutineCount(t *testing.T) {
+	c, commands := leakingTarget(t, nil)
+
+	response := c.WatchGoroutineCount(context.Background(), 2, watchGrow, time.Minute)
+	if response.Status != "success" || !response.Matched {
+		t.Fatalf("Expected the count to grow by 2, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if response.StartCount != 2 || response.FinalCount != 4 || response.Halts != 2 {
+		t.Errorf("Expected 2 halts to count from 2 to 4 goroutines, got %+v", response)
+	}
+	if !reflect.DeepEqual(*commands, []string{api.Continue, api.Halt, api.Continue, api.Halt}) {
+		t.Errorf("Expected the program run and halted twice, got %v", *commands)
+	}
+
+	var counts []int
+	for _, sample := range response.Samples {
+		counts = append(counts, sample.Count)
+	}
+	if !reflect.DeepEqual(counts, []int{2, 3, 4}) {
+		t.Errorf("Expected a sample for each count, got %v", counts)
+	}
+	if response.NewGoroutineCount != 2 || len(response.NewGoroutines) != 2 || response.NewGoroutines[0].ID != 3 {
+		t.Errorf("Expected goroutines 3 and 4 to be new, got %+v", response.NewGoroutines)
+	}
+	expected := []types.GoroutineOrigin{{GoStatement: "At /src/main.go:30 in main.serve", Count: 2}}
+	if !reflect.DeepEqual(response.NewGoroutineOrigins, expected) {
+		t.Errorf("Expected the new goroutines traced to main.go:30, got %+v", response.NewGoroutineOrigins)
+	}
+}
+
+func TestWatchGoroutineCountStops(t *testing.T) {
+	// A count already past the threshold is refused without running the program
+	c, commands := leakingTarget(t, nil)
+	response := c.WatchGoroutineCount(context.Background(), 1, watchAbove, time.Minute)
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "already has 2 goroutines") || len(*commands) != 0 {
+		t.Errorf("Expected a threshold already crossed to be refused, got %q after %v", response.Context.ErrorMessage, *commands)
+	}
+
+	// A breakpoint that stops the program first ends the watch there
+	c, commands = leakingTarget(t, &api.Breakpoint{ID: 3, File: "main.go", Line: 18})
+	response = c.WatchGoroutineCount(context.Background(), 5, watchGrow, time.Minute)
+	if response.Status != "success" || response.Matched {
+		t.Fatalf("Expected the watch to end unmatched at the breakpoint, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if response.InterruptedBy == nil || response.InterruptedBy.ID != 3 || len(*commands) != 1 {
+		t.Errorf("Expected breakpoint 3 to stop the watch after one continue, got %+v after %v", response.InterruptedBy, *commands)
+	}
+}
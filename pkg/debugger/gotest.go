@@ -0,0 +1,201 @@
+package debugger
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/gobuild"
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// testBinaryFlags are the go test flags a compiled test binary takes with a "test." prefix
+var testBinaryFlags = map[string]bool{
+	"bench": true, "benchmem": true, "benchtime": true, "count": true, "cpu": true,
+	"failfast": true, "fullpath": true, "list": true, "parallel": true, "run": true,
+	"short": true, "shuffle": true, "skip": true, "timeout": true, "v": true,
+}
+
+// buildErrorPattern matches compiler errors such as "./foo_test.go:12:5: undefined: bar"
+var buildErrorPattern = regexp.MustCompile(`^(\S+\.go):(\d+)(?::(\d+))?: (.+)$`)
+
+// LaunchTest compiles the tests of a package with go test -c and launches the test binary
+// stopped at program start. pkg is a directory, an import path or a file of the package,
+// "" meaning the current directory. testPattern is a -test.run regular expression selecting
+// the tests to run, all of them when empty. flags are passed to the test binary; go test
+// flags such as -v or -count=1 may be given without their "test." prefix. Like go test,
+// the binary runs in the package directory.
+func (c *Client) LaunchTest(pkg, testPattern string, flags []string) types.LaunchTestResponse {
+	response := types.LaunchTestResponse{
+		Package:     pkg,
+		TestPattern: testPattern,
+	}
+	if c.client != nil {
+		return c.createLaunchTestResponse(nil, &response, fmt.Errorf("debug session already active"))
+	}
+
+	if testPattern != "" {
+		if _, err := regexp.Compile(testPattern); err != nil {
+			return c.createLaunchTestResponse(nil, &response, fmt.Errorf("invalid test pattern %q: %v", testPattern, err))
+		}
+	}
+
+	dir, err := resolvePackageDir(pkg)
+	if err != nil {
+		return c.createLaunchTestResponse(nil, &response, err)
+	}
+	response.PackageDir = dir
+
+	debugBinary := gobuild.DefaultDebugBinaryPath("debug.test")
+
+	logger.Debug("Compiling tests of %s to %s", dir, debugBinary)
+	cmd, output, err := buildTestBinary(debugBinary, dir)
+	response.BuildCommand = cmd
+	response.BuildOutput = string(output)
+	if err != nil {
+		gobuild.Remove(debugBinary)
+		response.BuildErrors = parseBuildErrors(string(output))
+		return c.createLaunchTestResponse(nil, &response, formatTestBuildError(dir, response.BuildErrors, err, output))
+	}
+
+	args := []string{"-test.v"}
+	if testPattern != "" {
+		args = append(args, "-test.run="+testPattern)
+	}
+	for _, flag := range flags {
+		args = append(args, normalizeTestFlag(flag))
+	}
+	response.Args = args
+
+	logger.Debug("Launching test binary %s in %s with args %v", debugBinary, dir, args)
+	launch := c.launchProgram(debugBinary, args, nil, dir)
+	if launch.Context.ErrorMessage != "" {
+		gobuild.Remove(debugBinary)
+		return c.createLaunchTestResponse(nil, &response, fmt.Errorf("%s", launch.Context.ErrorMessage))
+	}
+
+	// Store the binary path for cleanup, and the package so restart can rebuild it
+	c.target = debugBinary
+	c.buildPkgs = []string{dir}
+	c.buildTest = true
+
+	response.DebugBinary = debugBinary
+	response.Pid = launch.Pid
+	return c.createLaunchTestResponse(launch.Context.DelveState, &response, nil)
+}
+
+// resolvePackageDir returns the directory of a package given as a directory, a file in it
+// or an import path
+func resolvePackageDir(pkg string) (string, error) {
+	if pkg == "" {
+		pkg = "."
+	}
+
+	if info, err := os.Stat(pkg); err == nil {
+		dir := pkg
+		if !info.IsDir() {
+			dir = filepath.Dir(pkg)
+		}
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to get absolute path: %v", err)
+		}
+		return absDir, nil
+	}
+
+	output, err := exec.Command("go", "list", "-f", "{{.Dir}}", pkg).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("cannot find package %s: %s", pkg, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// buildTestBinary compiles the tests of the package in dir. The build runs from dir, so the
+// package is resolved in its own module.
+func buildTestBinary(debugBinary, dir string) (string, []byte, error) {
+	// Debug binary paths are relative to the current directory, which the build leaves
+	output, err := filepath.Abs(debugBinary)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get current directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		return "", nil, fmt.Errorf("failed to change to test directory: %v", err)
+	}
+	defer func() {
+		if err := os.Chdir(currentDir); err != nil {
+			logger.Error("Failed to restore original directory: %v", err)
+		}
+	}()
+
+	return gobuild.GoTestBuildCombinedOutput(output, []string{dir}, "-gcflags all=-N")
+}
+
+// normalizeTestFlag adds the "test." prefix a test binary expects to go test flags given
+// without it, so "-count=1" becomes "-test.count=1"
+func normalizeTestFlag(flag string) string {
+	name, hasDash := strings.CutPrefix(flag, "-")
+	if !hasDash {
+		return flag
+	}
+	name = strings.TrimPrefix(name, "-")
+	key, _, _ := strings.Cut(name, "=")
+	if !testBinaryFlags[key] {
+		return flag
+	}
+	return "-test." + name
+}
+
+// parseBuildErrors extracts the compiler errors from go build output
+func parseBuildErrors(output string) []types.BuildError {
+	var errs []types.BuildError
+	for _, line := range strings.Split(output, "\n") {
+		m := buildErrorPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		buildErr := types.BuildError{File: m[1], Message: m[4]}
+		buildErr.Line, _ = strconv.Atoi(m[2])
+		buildErr.Column, _ = strconv.Atoi(m[3])
+		errs = append(errs, buildErr)
+	}
+	return errs
+}
+
+// formatTestBuildError describes a failed test build, leading with the compiler errors
+func formatTestBuildError(dir string, errs []types.BuildError, err error, output []byte) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("failed to compile tests of %s: %v\nOutput: %s", dir, err, string(output))
+	}
+
+	lines := make([]string, 0, len(errs))
+	for _, e := range errs {
+		lines = append(lines, fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message))
+	}
+	return fmt.Errorf("tests of %s do not compile, fix these errors first:\n%s", dir, strings.Join(lines, "\n"))
+}
+
+// createLaunchTestResponse creates a LaunchTestResponse
+func (c *Client) createLaunchTestResponse(state *api.DebuggerState, response *types.LaunchTestResponse, err error) types.LaunchTestResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "launch_test"
+	response.Context = &context
+	response.Status = "success"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		response.Status = "error"
+	}
+
+	return *response
+}
@@ -0,0 +1,62 @@
+package debugger
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeTestFlag(t *testing.T) {
+	testCases := []struct {
+		flag     string
+		expected string
+	}{
+		{flag: "-count=1", expected: "-test.count=1"},
+		{flag: "--short", expected: "-test.short"},
+		{flag: "-v", expected: "-test.v"},
+		{flag: "-test.timeout=30s", expected: "-test.timeout=30s"},
+		{flag: "-config=dev.yaml", expected: "-config=dev.yaml"},
+		{flag: "positional", expected: "positional"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.flag, func(t *testing.T) {
+			if got := normalizeTestFlag(tc.flag); got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseBuildErrors(t *testing.T) {
+	output := "# example.com/calc [example.com/calc.test]\n" +
+		"./calc_test.go:6:7: undefined: Sub\n" +
+		"./calc_test.go:9: missing return\n" +
+		"FAIL\texample.com/calc [build failed]\n"
+
+	errs := parseBuildErrors(output)
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 build errors, got %d: %+v", len(errs), errs)
+	}
+	if errs[0].File != "./calc_test.go" || errs[0].Line != 6 || errs[0].Column != 7 || errs[0].Message != "undefined: Sub" {
+		t.Errorf("Unexpected first error %+v", errs[0])
+	}
+	if errs[1].Line != 9 || errs[1].Column != 0 || errs[1].Message != "missing return" {
+		t.Errorf("Unexpected second error %+v", errs[1])
+	}
+}
+
+func TestResolvePackageDir(t *testing.T) {
+	absDir, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pkg := range []string{"", ".", "gotest.go"} {
+		dir, err := resolvePackageDir(pkg)
+		if err != nil {
+			t.Errorf("Expected %q to resolve, got %v", pkg, err)
+		} else if dir != absDir {
+			t.Errorf("Expected %q to resolve to %s, got %s", pkg, absDir, dir)
+		}
+	}
+}
@@ -2,7 +2,15 @@ package debugger
 
 import (
 	"fmt"
+	"go/parser"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
 // getFunctionName extracts a human-readable function name from various Delve types
@@ -23,50 +31,364 @@ func getFunctionNameFromBreakpoint(bp *api.Breakpoint) string {
 
 // getBreakpointStatus returns a human-readable breakpoint status
 func getBreakpointStatus(bp *api.Breakpoint) string {
+	status := "enabled"
 	if bp.Disabled {
-		return "disabled"
+		status = "disabled"
+	} else if bp.TotalHitCount > 0 {
+		status = "hit"
+	}
+	if bp.Cond != "" {
+		status = fmt.Sprintf("%s (cond: %s)", status, bp.Cond)
 	}
-	if bp.TotalHitCount > 0 {
-		return "hit"
+	if bp.HitCond != "" {
+		status = fmt.Sprintf("%s (hit count %s)", status, bp.HitCond)
 	}
-	return "enabled"
+	return status
+}
+
+// getWatchTypeName returns a human-readable watchpoint type
+func getWatchTypeName(wtype api.WatchType) string {
+	switch {
+	case wtype&api.WatchRead != 0 && wtype&api.WatchWrite != 0:
+		return "readwrite"
+	case wtype&api.WatchRead != 0:
+		return "read"
+	case wtype&api.WatchWrite != 0:
+		return "write"
+	default:
+		return "unknown"
+	}
+}
+
+// validateCondition checks that a breakpoint condition parses as a Go expression
+func validateCondition(condition string) error {
+	if strings.TrimSpace(condition) == "" {
+		return fmt.Errorf("condition must be a non-empty Go boolean expression")
+	}
+	if _, err := parser.ParseExpr(condition); err != nil {
+		return fmt.Errorf("invalid condition %q: %v", condition, err)
+	}
+	return nil
+}
+
+// hitConditionPattern matches the hit conditions Delve understands: a count, optionally
+// after a comparison operator or % for every Nth hit
+var hitConditionPattern = regexp.MustCompile(`^(==|!=|>=|<=|>|<|%)?\s*(\d+)$`)
+
+// everyNthHitPattern matches the "% N == 0" spelling of Delve's "% N"
+var everyNthHitPattern = regexp.MustCompile(`^%\s*(\d+)\s*==\s*0$`)
+
+// normalizeHitCondition checks a hit-count condition such as "== 100", ">= 5" or
+// "% 10 == 0" and returns it in the form Delve expects, e.g. "% 10"
+func normalizeHitCondition(hitCond string) (string, error) {
+	hitCond = strings.TrimSpace(hitCond)
+
+	if m := everyNthHitPattern.FindStringSubmatch(hitCond); m != nil {
+		hitCond = "% " + m[1]
+	}
+
+	m := hitConditionPattern.FindStringSubmatch(hitCond)
+	if m == nil {
+		return "", fmt.Errorf("invalid hit condition %q: expected a count after one of ==, !=, <, <=, >, >= or %%, e.g. \"== 100\" or \"%% 10\"", hitCond)
+	}
+
+	op, count := m[1], m[2]
+	if op == "" {
+		op = "=="
+	}
+	if op == "%" && strings.Trim(count, "0") == "" {
+		return "", fmt.Errorf("invalid hit condition %q: cannot stop every 0th hit", hitCond)
+	}
+	return op + " " + count, nil
 }
 
 // getStateReason returns a human-readable reason for the current state
 func getStateReason(state *api.DebuggerState) string {
+	return formatStopDetail(getStopDetail(state))
+}
+
+// getStopDetail describes why the program is in its current state, as separate fields
+func getStopDetail(state *api.DebuggerState) *types.StopDetail {
 	if state == nil {
-		return "unknown"
+		return &types.StopDetail{Kind: types.StopUnknown}
 	}
 
 	if state.Exited {
-		return fmt.Sprintf("process exited with status %d", state.ExitStatus)
+		status := state.ExitStatus
+		return &types.StopDetail{Kind: types.StopExited, ExitStatus: &status}
 	}
 
 	if state.Running {
-		return "process is running"
+		return &types.StopDetail{Kind: types.StopRunning}
+	}
+
+	if detail := getPanicStop(state.CurrentThread); detail != nil {
+		return detail
 	}
 
 	if state.CurrentThread != nil && state.CurrentThread.Breakpoint != nil {
-		return "hit breakpoint"
+		bp := state.CurrentThread.Breakpoint
+		if bp.WatchExpr != "" {
+			return &types.StopDetail{Kind: types.StopWatchpoint, BreakpointID: bp.ID, WatchExpr: bp.WatchExpr}
+		}
+		if bp.ID <= 0 {
+			return &types.StopDetail{Kind: types.StopInternal, BreakpointID: bp.ID, InternalKind: breakpointKind(bp)}
+		}
+		return &types.StopDetail{Kind: types.StopBreakpoint, BreakpointID: bp.ID}
 	}
 
-	return "process is stopped"
+	return &types.StopDetail{Kind: types.StopStopped}
+}
+
+// formatStopDetail renders a stop detail in human terms
+func formatStopDetail(detail *types.StopDetail) string {
+	switch detail.Kind {
+	case types.StopExited:
+		return fmt.Sprintf("process exited with status %d", *detail.ExitStatus)
+	case types.StopRunning:
+		return "process is running"
+	case types.StopPanic, types.StopFatal:
+		kind := "panic"
+		if detail.Kind == types.StopFatal {
+			kind = "fatal error"
+		}
+		if detail.Message == "" {
+			return fmt.Sprintf("stopped at %s", kind)
+		}
+		return fmt.Sprintf("stopped at %s: %s", kind, detail.Message)
+	case types.StopWatchpoint:
+		return fmt.Sprintf("watchpoint on `%s` triggered", detail.WatchExpr)
+	case types.StopBreakpoint:
+		reason := "hit breakpoint"
+		if detail.PinnedGoroutine != 0 {
+			reason = fmt.Sprintf("hit breakpoint pinned to goroutine %d", detail.PinnedGoroutine)
+		}
+		if detail.ArmedBy != 0 {
+			reason += fmt.Sprintf(" armed by breakpoint %d", detail.ArmedBy)
+			if detail.Disarmed {
+				reason += fmt.Sprintf(", disarmed until breakpoint %d is hit again", detail.ArmedBy)
+			}
+		}
+		if len(detail.Armed) > 0 {
+			reason += "; armed " + formatBreakpointList(detail.Armed)
+		}
+		return reason
+	case types.StopInternal:
+		reason := fmt.Sprintf("stopped by internal %s breakpoint", detail.InternalKind)
+		if purpose := internalBreakpointPurposes[detail.InternalKind]; purpose != "" {
+			reason += ": " + purpose
+		}
+		return reason
+	case types.StopStopped:
+		return "process is stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// formatBreakpointList renders breakpoint IDs as "breakpoint 3" or "breakpoints 3, 5"
+func formatBreakpointList(ids []int) string {
+	if len(ids) == 1 {
+		return fmt.Sprintf("breakpoint %d", ids[0])
+	}
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = strconv.Itoa(id)
+	}
+	return "breakpoints " + strings.Join(names, ", ")
 }
 
 // getCurrentLocation gets the current location from a DebuggerState
 func getCurrentLocation(state *api.DebuggerState) *string {
-	if state == nil || state.CurrentThread == nil {
+	return formatPosition(getCurrentPosition(state))
+}
+
+// getCurrentPosition gets the current position from a DebuggerState as separate fields
+func getCurrentPosition(state *api.DebuggerState) *types.SourcePosition {
+	if state == nil {
 		return nil
 	}
-	if state.CurrentThread.File == "" || state.CurrentThread.Function == nil {
+	return getThreadPosition(state.CurrentThread)
+}
+
+// getThreadPosition returns where a thread is in Go source, or nil when it is outside of
+// it, such as in C code or without debug information
+func getThreadPosition(thread *api.Thread) *types.SourcePosition {
+	if thread == nil || thread.File == "" || thread.Function == nil {
 		return nil
 	}
 
-	r := fmt.Sprintf("At %s:%d in %s", state.CurrentThread.File, state.CurrentThread.Line, getFunctionName(state.CurrentThread))
+	return &types.SourcePosition{
+		File:     thread.File,
+		Line:     thread.Line,
+		Function: getFunctionName(thread),
+	}
+}
+
+// getThreadLocation renders where a thread is, falling back on its PC outside Go source
+func getThreadLocation(thread *api.Thread) *string {
+	if pos := getThreadPosition(thread); pos != nil {
+		return formatPosition(pos)
+	}
+	r := fmt.Sprintf("At PC %#x, outside Go source", thread.PC)
 	return &r
 }
 
-func getBreakpointLocation(bp *api.Breakpoint) *string {
-	r := fmt.Sprintf("At %s:%d in %s", bp.File, bp.Line, getFunctionNameFromBreakpoint(bp))
+// formatPosition renders a position as "At file:line in function"
+func formatPosition(pos *types.SourcePosition) *string {
+	if pos == nil {
+		return nil
+	}
+	r := fmt.Sprintf("At %s:%d in %s", pos.File, pos.Line, pos.Function)
 	return &r
 }
+
+func getBreakpointLocation(bp *api.Breakpoint) *string {
+	if bp.WatchExpr != "" {
+		r := fmt.Sprintf("Watching %s (%s)", bp.WatchExpr, getWatchTypeName(bp.WatchType))
+		return &r
+	}
+	return formatPosition(getBreakpointPosition(bp))
+}
+
+// getBreakpointPosition gets the code position of a breakpoint, or nil for watchpoints
+func getBreakpointPosition(bp *api.Breakpoint) *types.SourcePosition {
+	if bp.WatchExpr != "" {
+		return nil
+	}
+	return &types.SourcePosition{
+		File:     bp.File,
+		Line:     bp.Line,
+		Function: getFunctionNameFromBreakpoint(bp),
+	}
+}
+
+// getGoroutineLocation gets the current user-code location of a goroutine, falling back to its runtime location
+func getGoroutineLocation(g *api.Goroutine) *string {
+	return formatPosition(getGoroutinePosition(g))
+}
+
+// getGoroutinePosition gets the current user-code position of a goroutine as separate fields
+func getGoroutinePosition(g *api.Goroutine) *types.SourcePosition {
+	if g == nil {
+		return nil
+	}
+	loc := g.UserCurrentLoc
+	if loc.File == "" {
+		loc = g.CurrentLoc
+	}
+	return getLocationPosition(loc)
+}
+
+// getLocationPosition gets a Delve location as separate fields, or nil when it has no source file
+func getLocationPosition(loc api.Location) *types.SourcePosition {
+	if loc.File == "" {
+		return nil
+	}
+	return &types.SourcePosition{
+		File:     loc.File,
+		Line:     loc.Line,
+		Function: getFunctionNameFromLocation(loc),
+	}
+}
+
+// getFunctionNameFromLocation extracts a human-readable function name from a location
+func getFunctionNameFromLocation(loc api.Location) string {
+	if loc.Function == nil {
+		return "unknown"
+	}
+	return loc.Function.Name()
+}
+
+// getGoroutineStatus returns a human-readable goroutine status
+func getGoroutineStatus(g *api.Goroutine) string {
+	switch g.Status {
+	case proc.Gidle:
+		return "idle"
+	case proc.Grunnable:
+		return "runnable"
+	case proc.Grunning:
+		return "running"
+	case proc.Gsyscall:
+		return "syscall"
+	case proc.Gwaiting:
+		return "waiting"
+	case proc.Gdead:
+		return "dead"
+	case proc.Gcopystack:
+		return "copystack"
+	default:
+		return "unknown"
+	}
+}
+
+// waitReasonsLoadConfig loads every string of the runtime's waitReasonStrings array
+var waitReasonsLoadConfig = api.LoadConfig{
+	MaxStringLen:   64,
+	MaxArrayValues: 256,
+}
+
+// goroutineWaitReasons returns the target's waitReason strings, indexed by value, or nil
+// when they can't be known. They are looked up once per session.
+func (c *Client) goroutineWaitReasons() []string {
+	if !c.waitReasonsLoaded {
+		c.waitReasons = c.loadWaitReasons()
+		c.waitReasonsLoaded = true
+	}
+	return c.waitReasons
+}
+
+// loadWaitReasons reads the waitReason strings from the target's runtime. Their order
+// changes from one Go release to the next, so there is nothing to fall back on: reasons
+// are only known when runtime.waitReasonStrings can be read.
+func (c *Client) loadWaitReasons() []string {
+	if c.client == nil {
+		return nil
+	}
+	v, err := c.client.EvalVariable(api.EvalScope{GoroutineID: -1}, "runtime.waitReasonStrings", waitReasonsLoadConfig)
+	if err != nil || len(v.Children) == 0 {
+		logger.Debug("Warning: Failed to read the target's wait reasons: %v", err)
+		return nil
+	}
+	reasons := make([]string, len(v.Children))
+	for i, child := range v.Children {
+		reasons[i] = child.Value
+	}
+	return reasons
+}
+
+// getGoroutineWaitReason returns a human-readable reason a goroutine is blocked, if any.
+// Reasons are left out when the target's wait reasons aren't known.
+func (c *Client) getGoroutineWaitReason(g *api.Goroutine) string {
+	if g.Status != proc.Gwaiting && g.Status != proc.Gsyscall {
+		return ""
+	}
+	if g.WaitReason <= 0 {
+		return ""
+	}
+	reasons := c.goroutineWaitReasons()
+	if reasons == nil {
+		return ""
+	}
+	if g.WaitReason < int64(len(reasons)) {
+		return reasons[g.WaitReason]
+	}
+	return fmt.Sprintf("unknown wait reason %d", g.WaitReason)
+}
+
+// pageBounds returns the slice bounds of the page of n items starting at offset, with at
+// most limit items; a limit of 0 or less means no limit
+func pageBounds(n, limit, offset int) (int, int) {
+	start := offset
+	if start < 0 {
+		start = 0
+	}
+	if start > n {
+		start = n
+	}
+	end := n
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	return start, end
+}
@@ -0,0 +1,127 @@
+package debugger
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestGetBreakpointStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bp       *api.Breakpoint
+		expected string
+	}{
+		{
+			name:     "Enabled breakpoint",
+			bp:       &api.Breakpoint{},
+			expected: "enabled",
+		},
+		{
+			name:     "Hit breakpoint",
+			bp:       &api.Breakpoint{TotalHitCount: 2},
+			expected: "hit",
+		},
+		{
+			name:     "Disabled breakpoint",
+			bp:       &api.Breakpoint{Disabled: true, TotalHitCount: 2},
+			expected: "disabled",
+		},
+		{
+			name:     "Conditional breakpoint",
+			bp:       &api.Breakpoint{Cond: `name == "Alice"`},
+			expected: `enabled (cond: name == "Alice")`,
+		},
+		{
+			name:     "Hit count breakpoint",
+			bp:       &api.Breakpoint{HitCond: "== 100", TotalHitCount: 3},
+			expected: "hit (hit count == 100)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if status := getBreakpointStatus(tc.bp); status != tc.expected {
+				t.Errorf("Expected status %q, got %q", tc.expected, status)
+			}
+		})
+	}
+}
+
+func TestValidateCondition(t *testing.T) {
+	testCases := []struct {
+		condition string
+		valid     bool
+	}{
+		{condition: `name == "Alice"`, valid: true},
+		{condition: "count > 5 && enabled", valid: true},
+		{condition: "", valid: false},
+		{condition: "   ", valid: false},
+		{condition: "count >", valid: false},
+	}
+
+	for _, tc := range testCases {
+		err := validateCondition(tc.condition)
+		if tc.valid && err != nil {
+			t.Errorf("Expected condition %q to be valid, got error: %v", tc.condition, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Expected condition %q to be rejected", tc.condition)
+		}
+	}
+}
+
+func TestPageBounds(t *testing.T) {
+	testCases := []struct {
+		n, limit, offset int
+		start, end       int
+	}{
+		{n: 10, limit: 0, offset: 0, start: 0, end: 10},
+		{n: 10, limit: 3, offset: 0, start: 0, end: 3},
+		{n: 10, limit: 3, offset: 8, start: 8, end: 10},
+		{n: 10, limit: 3, offset: 20, start: 10, end: 10},
+		{n: 10, limit: 3, offset: -1, start: 0, end: 3},
+	}
+
+	for _, tc := range testCases {
+		start, end := pageBounds(tc.n, tc.limit, tc.offset)
+		if start != tc.start || end != tc.end {
+			t.Errorf("pageBounds(%d, %d, %d) = %d, %d; expected %d, %d", tc.n, tc.limit, tc.offset, start, end, tc.start, tc.end)
+		}
+	}
+}
+
+func TestNormalizeHitCondition(t *testing.T) {
+	testCases := []struct {
+		hitCond  string
+		expected string
+		valid    bool
+	}{
+		{hitCond: "== 100", expected: "== 100", valid: true},
+		{hitCond: ">=5", expected: ">= 5", valid: true},
+		{hitCond: "  100 ", expected: "== 100", valid: true},
+		{hitCond: "% 10", expected: "% 10", valid: true},
+		{hitCond: "% 10 == 0", expected: "% 10", valid: true},
+		{hitCond: "!= 3", expected: "!= 3", valid: true},
+		{hitCond: "% 0", valid: false},
+		{hitCond: "=> 5", valid: false},
+		{hitCond: "== x", valid: false},
+		{hitCond: "% 10 == 1", valid: false},
+		{hitCond: "", valid: false},
+	}
+
+	for _, tc := range testCases {
+		hitCond, err := normalizeHitCondition(tc.hitCond)
+		if !tc.valid {
+			if err == nil {
+				t.Errorf("Expected hit condition %q to be rejected, got %q", tc.hitCond, hitCond)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Expected hit condition %q to be valid, got error: %v", tc.hitCond, err)
+		} else if hitCond != tc.expected {
+			t.Errorf("Expected hit condition %q to become %q, got %q", tc.hitCond, tc.expected, hitCond)
+		}
+	}
+}
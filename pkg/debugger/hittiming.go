@@ -0,0 +1,232 @@
+package debugger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxTimedHits caps the hits whose times are kept per breakpoint, dropping the oldest first
+const maxTimedHits = 1000
+
+// hitTimingNote is returned with every timing, since stopping at a breakpoint and resuming
+// past it costs far more than most of the code between hits
+const hitTimingNote = "times include the debugger stopping at and resuming past every hit, so treat them as relative indicators: compare intervals with each other rather than reading them as the program's own speed"
+
+// timedHit is when one hit of a breakpoint was seen
+type timedHit struct {
+	hit         uint64 // Hits of the breakpoint so far, as counted by Delve
+	goroutineID int64
+	timestamp   time.Time     // Wall-clock time of the hit
+	running     time.Duration // How long the target had run for by then
+}
+
+// hitTimeLog keeps the times of the last hits of every breakpoint. It also adds up how
+// long the target runs, so an interval that includes a stop, e.g. one spent inspecting the
+// program at a breakpoint, can be told apart from the time the program took.
+type hitTimeLog struct {
+	mu      sync.Mutex
+	hits    map[int][]timedHit // Keyed by breakpoint ID
+	running time.Duration      // Run time of the runs that ended
+	resumed time.Time          // When the current run started, zero while stopped
+}
+
+// resume notes that the target starts running, unless it already is
+func (l *hitTimeLog) resume(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.resumed.IsZero() {
+		l.resumed = now
+	}
+}
+
+// stop notes that the target stopped, adding the run to the run time
+func (l *hitTimeLog) stop(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.resumed.IsZero() {
+		l.running += now.Sub(l.resumed)
+		l.resumed = time.Time{}
+	}
+}
+
+// record keeps the time of every breakpoint hit a state shows. A hit already recorded,
+// e.g. seen again after it was continued past, is kept once.
+func (l *hitTimeLog) record(state *api.DebuggerState, now time.Time) {
+	if state == nil || state.Exited {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	running := l.running
+	if !l.resumed.IsZero() {
+		running += now.Sub(l.resumed)
+	}
+	for _, th := range state.Threads {
+		bp := th.Breakpoint
+		// Negative IDs are Delve's internal breakpoints
+		if bp == nil || bp.ID <= 0 {
+			continue
+		}
+
+		hits := l.hits[bp.ID]
+		if n := len(hits); n > 0 && hits[n-1].hit == bp.TotalHitCount && hits[n-1].goroutineID == th.GoroutineID {
+			continue
+		}
+		hits = append(hits, timedHit{hit: bp.TotalHitCount, goroutineID: th.GoroutineID, timestamp: now, running: running})
+		if len(hits) > maxTimedHits {
+			hits = append([]timedHit(nil), hits[len(hits)-maxTimedHits:]...)
+		}
+		if l.hits == nil {
+			l.hits = make(map[int][]timedHit)
+		}
+		l.hits[bp.ID] = hits
+	}
+}
+
+// get returns the recorded hits of a breakpoint, oldest first
+func (l *hitTimeLog) get(id int) []timedHit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]timedHit(nil), l.hits[id]...)
+}
+
+// move hands the recorded hits of a breakpoint to the breakpoint that replaces it
+func (l *hitTimeLog) move(from, to int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if hits := l.hits[from]; hits != nil {
+		delete(l.hits, from)
+		l.hits[to] = hits
+	}
+}
+
+// forget drops the recorded hits of a breakpoint
+func (l *hitTimeLog) forget(id int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.hits, id)
+}
+
+// reset drops every recorded hit and the run time, for a new run of the target
+func (l *hitTimeLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hits = nil
+	l.running = 0
+	l.resumed = time.Time{}
+}
+
+// InterHitTimings returns the time between consecutive recorded hits of a breakpoint, with
+// the shortest, longest and average interval. Each interval is given in wall-clock time
+// and in the time the target ran for, which leaves out the time it was stopped in between.
+// Every hit Delve counts is recorded, including ones continued past because of a
+// condition on this side such as an ignore count; the last 1000 hits of each breakpoint
+// are kept.
+func (c *Client) InterHitTimings(breakpointID int) types.InterHitTimingResponse {
+	if c.client == nil {
+		return c.createInterHitTimingResponse(nil, breakpointID, nil, fmt.Errorf("no active debug session"))
+	}
+
+	// The times are kept here, so they can be read after the target exits
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		logger.Debug("Warning: Failed to get state while reading hit timings: %v", err)
+	}
+
+	hits := c.hitTimes.get(breakpointID)
+	if len(hits) < 2 {
+		if len(hits) == 1 {
+			err = fmt.Errorf("breakpoint %d has been hit once; continue until it is hit again to time the interval between hits", breakpointID)
+		} else if _, bpErr := c.client.GetBreakpoint(breakpointID); bpErr != nil {
+			err = fmt.Errorf("breakpoint %d not found: %v", breakpointID, bpErr)
+		} else {
+			err = fmt.Errorf("breakpoint %d has not been hit yet; continue until it is hit at least twice", breakpointID)
+		}
+		return c.createInterHitTimingResponse(state, breakpointID, hits, err)
+	}
+
+	logger.Debug("Timing %d hits of breakpoint %d", len(hits), breakpointID)
+	return c.createInterHitTimingResponse(state, breakpointID, hits, nil)
+}
+
+// hitIntervalStats returns the shortest, longest and average of a set of intervals
+func hitIntervalStats(intervals []time.Duration) *types.HitIntervalStats {
+	if len(intervals) == 0 {
+		return nil
+	}
+
+	shortest, longest, total := intervals[0], intervals[0], time.Duration(0)
+	for _, d := range intervals {
+		shortest = min(shortest, d)
+		longest = max(longest, d)
+		total += d
+	}
+	return &types.HitIntervalStats{
+		Min: formatHitInterval(shortest),
+		Max: formatHitInterval(longest),
+		Avg: formatHitInterval(total / time.Duration(len(intervals))),
+	}
+}
+
+// formatHitInterval renders an interval to the microsecond, e.g. "1.234ms"
+func formatHitInterval(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
+
+// createInterHitTimingResponse creates an InterHitTimingResponse from the recorded hits
+func (c *Client) createInterHitTimingResponse(state *api.DebuggerState, breakpointID int, hits []timedHit, err error) types.InterHitTimingResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "inter_hit_timings"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.InterHitTimingResponse{
+			Status:       "error",
+			Context:      context,
+			BreakpointID: breakpointID,
+			HitsKept:     len(hits),
+		}
+	}
+
+	response := types.InterHitTimingResponse{
+		Status:       "success",
+		Context:      context,
+		BreakpointID: breakpointID,
+		HitsKept:     len(hits),
+		Intervals:    make([]types.HitInterval, 0, len(hits)-1),
+		Note:         hitTimingNote,
+	}
+
+	var wall, running []time.Duration
+	for i := 1; i < len(hits); i++ {
+		from, to := hits[i-1], hits[i]
+		w, r := to.timestamp.Sub(from.timestamp), to.running-from.running
+		wall = append(wall, w)
+		running = append(running, r)
+		response.Intervals = append(response.Intervals, types.HitInterval{
+			FromHit:     from.hit,
+			ToHit:       to.hit,
+			GoroutineID: to.goroutineID,
+			Wall:        formatHitInterval(w),
+			Running:     formatHitInterval(r),
+		})
+	}
+	response.Wall = hitIntervalStats(wall)
+	response.Running = hitIntervalStats(running)
+	response.Summary = fmt.Sprintf("%d intervals between the last %d hits of breakpoint %d: %s on average while running, from %s to %s; %s on average in wall-clock time",
+		len(response.Intervals), len(hits), breakpointID, response.Running.Avg, response.Running.Min, response.Running.Max, response.Wall.Avg)
+	return response
+}
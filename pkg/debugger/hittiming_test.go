@@ -0,0 +1,131 @@
+package debugger
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestHitTimeLog(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	hitState := func(hit uint64) *api.DebuggerState {
+		return &api.DebuggerState{Threads: []*api.Thread{
+			{GoroutineID: 1, Breakpoint: &api.Breakpoint{ID: 1, TotalHitCount: hit}},
+			{GoroutineID: 2},
+			{GoroutineID: 3, Breakpoint: &api.Breakpoint{ID: -1, TotalHitCount: 1}},
+		}}
+	}
+
+	log := &hitTimeLog{}
+	log.resume(at(0))
+	log.record(hitState(1), at(10*time.Millisecond))
+	log.stop(at(10 * time.Millisecond))
+
+	// Stopped for a second at the breakpoint, then the same hit is seen again on resuming
+	log.resume(at(time.Second))
+	log.record(hitState(1), at(time.Second))
+	log.record(hitState(2), at(time.Second+30*time.Millisecond))
+	log.stop(at(time.Second + 30*time.Millisecond))
+
+	hits := log.get(1)
+	if len(hits) != 2 {
+		t.Fatalf("Expected 2 hits of breakpoint 1, got %+v", hits)
+	}
+	if wall := hits[1].timestamp.Sub(hits[0].timestamp); wall != 1020*time.Millisecond {
+		t.Errorf("Expected 1.02s between the hits, got %v", wall)
+	}
+	if running := hits[1].running - hits[0].running; running != 30*time.Millisecond {
+		t.Errorf("Expected the target to run for 30ms between the hits, got %v", running)
+	}
+	if len(log.get(-1)) != 0 {
+		t.Errorf("Expected Delve's internal breakpoints not to be timed")
+	}
+
+	log.move(1, 5)
+	if len(log.get(1)) != 0 || len(log.get(5)) != 2 {
+		t.Errorf("Expected the hits of breakpoint 1 to move to breakpoint 5")
+	}
+	log.forget(5)
+	if len(log.get(5)) != 0 {
+		t.Errorf("Expected the hits of breakpoint 5 to be forgotten")
+	}
+
+	for i := 1; i <= maxTimedHits+5; i++ {
+		log.record(hitState(uint64(i)), at(time.Duration(i)*time.Millisecond))
+	}
+	if hits := log.get(1); len(hits) != maxTimedHits || hits[0].hit != 6 {
+		t.Errorf("Expected the oldest hits to be dropped, got %d hits from hit %d", len(hits), hits[0].hit)
+	}
+	log.reset()
+	if len(log.get(1)) != 0 || log.running != 0 {
+		t.Errorf("Expected reset to drop the hits and the run time")
+	}
+}
+
+func TestCreateInterHitTimingResponse(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hits := []timedHit{
+		{hit: 1, goroutineID: 1, timestamp: start, running: 0},
+		{hit: 2, goroutineID: 1, timestamp: start.Add(2 * time.Second), running: 10 * time.Millisecond},
+		{hit: 3, goroutineID: 7, timestamp: start.Add(3 * time.Second), running: 40 * time.Millisecond},
+	}
+
+	c := NewClient()
+	response := c.createInterHitTimingResponse(nil, 1, hits, nil)
+	if response.Status != "success" || response.HitsKept != 3 || len(response.Intervals) != 2 {
+		t.Fatalf("Expected 2 intervals between 3 hits, got %+v", response)
+	}
+	if interval := response.Intervals[1]; interval.FromHit != 2 || interval.ToHit != 3 || interval.GoroutineID != 7 || interval.Wall != "1s" || interval.Running != "30ms" {
+		t.Errorf("Unexpected second interval %+v", interval)
+	}
+	if response.Running.Min != "10ms" || response.Running.Max != "30ms" || response.Running.Avg != "20ms" {
+		t.Errorf("Unexpected run time stats %+v", response.Running)
+	}
+	if response.Wall.Min != "1s" || response.Wall.Max != "2s" || response.Wall.Avg != "1.5s" {
+		t.Errorf("Unexpected wall-clock stats %+v", response.Wall)
+	}
+	if response.Note == "" {
+		t.Errorf("Expected the timings to say they are relative indicators")
+	}
+}
+
+func TestInterHitTimings(t *testing.T) {
+	c, _ := hittingTarget(t)
+
+	if response := c.InterHitTimings(1); response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "has not been hit yet") {
+		t.Errorf("Expected breakpoint 1 not hit yet, got %+v", response.Context)
+	}
+	if response := c.InterHitTimings(7); response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "breakpoint 7 not found") {
+		t.Errorf("Expected breakpoint 7 not found, got %+v", response.Context)
+	}
+
+	c.Continue(context.Background())
+	if response := c.InterHitTimings(1); response.Status != "error" || response.HitsKept != 1 || !strings.Contains(response.Context.ErrorMessage, "has been hit once") {
+		t.Errorf("Expected breakpoint 1 hit once, got %+v", response.Context)
+	}
+
+	// Hits continued past for an ignore count are timed too
+	c.SetIgnoreCount(1, 2)
+	c.Continue(context.Background())
+	response := c.InterHitTimings(1)
+	if response.Status != "success" || response.HitsKept != 4 || len(response.Intervals) != 3 {
+		t.Fatalf("Expected 3 intervals between 4 hits, got %s %d hits: %s", response.Status, response.HitsKept, response.Context.ErrorMessage)
+	}
+	for i, interval := range response.Intervals {
+		if interval.FromHit != uint64(i+1) || interval.ToHit != uint64(i+2) || interval.GoroutineID != 1 {
+			t.Errorf("Expected interval %d from hit %d to %d on goroutine 1, got %+v", i, i+1, i+2, interval)
+		}
+	}
+	if response.Wall == nil || response.Running == nil || response.Note != hitTimingNote {
+		t.Errorf("Expected wall and run time stats with the note, got %+v", response)
+	}
+
+	response = NewClient().InterHitTimings(1)
+	if response.Status != "error" || response.Context.Operation != "inter_hit_timings" {
+		t.Errorf("Expected an inter_hit_timings error without a session, got %+v", response)
+	}
+}
@@ -0,0 +1,93 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// ignoreCount makes a breakpoint continue past its next hits before it stops. Delve has no
+// such setting, so the hits stop the program and are skipped by continuing again. Delve
+// only stops for hits its condition and hit condition let through, so only those use up
+// the count.
+type ignoreCount struct {
+	count     int // Hits to continue past, as set
+	remaining int // Hits still to continue past
+}
+
+// setIgnoreCount makes a breakpoint continue past its next count hits; 0 clears the count
+func (c *Client) setIgnoreCount(id int, count int) {
+	if count == 0 {
+		delete(c.ignoreCounts, id)
+		return
+	}
+	if c.ignoreCounts == nil {
+		c.ignoreCounts = make(map[int]*ignoreCount)
+	}
+	c.ignoreCounts[id] = &ignoreCount{count: count, remaining: count}
+}
+
+// skipIgnoredHit reports whether the program stopped at a breakpoint that still has hits
+// to ignore, using one of them up, so it should be continued without surfacing the stop
+func (c *Client) skipIgnoredHit(state *api.DebuggerState) bool {
+	if state == nil || state.Exited || state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil {
+		return false
+	}
+
+	bp := state.CurrentThread.Breakpoint
+	ignore := c.ignoreCounts[bp.ID]
+	if ignore == nil || ignore.remaining == 0 {
+		return false
+	}
+
+	ignore.remaining--
+	logger.Debug("Ignoring hit of breakpoint %d, %d more to ignore", bp.ID, ignore.remaining)
+	return true
+}
+
+// annotateIgnoreCount adds the hits a breakpoint still ignores, if any
+func (c *Client) annotateIgnoreCount(breakpoint *types.Breakpoint) {
+	if ignore := c.ignoreCounts[breakpoint.ID]; ignore != nil {
+		breakpoint.IgnoreCount = ignore.remaining
+	}
+}
+
+// SetIgnoreCount makes an existing breakpoint continue past its next count hits before it
+// stops, replacing any ignore count it had; 0 makes it stop on the next hit again. Like
+// for a new breakpoint, only hits that satisfy its condition are ignored.
+func (c *Client) SetIgnoreCount(id int, count int) types.BreakpointResponse {
+	if c.client == nil {
+		return c.createBreakpointResponse(nil, "set_ignore_count", nil, fmt.Errorf("no active debug session"))
+	}
+
+	if count < 0 {
+		return c.createBreakpointResponse(nil, "set_ignore_count", nil, fmt.Errorf("invalid ignore count %d: must be 0 or more", count))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createBreakpointResponse(nil, "set_ignore_count", nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createBreakpointResponse(nil, "set_ignore_count", nil, fmt.Errorf("cannot change a breakpoint while the target is running; stop the target first"))
+	}
+
+	if id <= 0 {
+		return c.createBreakpointResponse(state, "set_ignore_count", nil, fmt.Errorf("breakpoint %d is internal to Delve and cannot ignore hits", id))
+	}
+
+	bp, err := c.client.GetBreakpoint(id)
+	if err != nil {
+		return c.createBreakpointResponse(state, "set_ignore_count", nil, fmt.Errorf("breakpoint %d not found: %v", id, err))
+	}
+
+	logger.Debug("Breakpoint %d at %s:%d ignores its next %d hits", id, bp.File, bp.Line, count)
+	c.setIgnoreCount(id, count)
+
+	breakpoint := convertBreakpoint(bp)
+	c.annotateBreakpoint(&breakpoint)
+	return c.createBreakpointResponse(state, "set_ignore_count", &breakpoint, nil)
+}
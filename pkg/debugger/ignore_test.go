@@ -0,0 +1,136 @@
+package debugger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestSkipIgnoredHit(t *testing.T) {
+	c := &Client{}
+	c.setIgnoreCount(1, 2)
+
+	stopAt := func(bpID int) *api.DebuggerState {
+		return &api.DebuggerState{
+			CurrentThread: &api.Thread{Breakpoint: &api.Breakpoint{ID: bpID}},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		state    *api.DebuggerState
+		expected bool
+	}{
+		{name: "First ignored hit", state: stopAt(1), expected: true},
+		{name: "Breakpoint without ignore count", state: stopAt(2), expected: false},
+		{name: "Second ignored hit", state: stopAt(1), expected: true},
+		{name: "Ignore count used up", state: stopAt(1), expected: false},
+		{name: "Not at a breakpoint", state: &api.DebuggerState{CurrentThread: &api.Thread{}}, expected: false},
+		{name: "Exited", state: &api.DebuggerState{Exited: true}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := c.skipIgnoredHit(tc.state); result != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+
+	breakpoint := types.Breakpoint{ID: 1}
+	c.annotateIgnoreCount(&breakpoint)
+	if breakpoint.IgnoreCount != 0 {
+		t.Errorf("Expected no hits left to ignore, got %d", breakpoint.IgnoreCount)
+	}
+}
+
+func TestSetIgnoreCountClears(t *testing.T) {
+	c := &Client{}
+	c.setIgnoreCount(1, 50)
+
+	breakpoint := types.Breakpoint{ID: 1}
+	c.annotateIgnoreCount(&breakpoint)
+	if breakpoint.IgnoreCount != 50 {
+		t.Errorf("Expected 50 hits left to ignore, got %d", breakpoint.IgnoreCount)
+	}
+
+	c.setIgnoreCount(1, 0)
+	if _, ok := c.ignoreCounts[1]; ok {
+		t.Error("Expected an ignore count of 0 to clear it")
+	}
+}
+
+// hittingTarget returns a fake Delve with breakpoint 1 at main.go:10, which every continue
+// stops at again, counting its hits. The names of the commands run are recorded.
+func hittingTarget(t *testing.T) (*Client, *[]string) {
+	t.Helper()
+	bps := &fakeBreakpoints{}
+	bps.add(&api.Breakpoint{ID: 1, File: "main.go", Line: 10, FunctionName: "main.handle"})
+
+	var commands []string
+	var hits uint64
+	c, _ := newFakeDelve(t, bps.serve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(5)),
+		"Command": fakeCommands(t, &commands, func(api.DebuggerCommand) api.DebuggerState {
+			hits++
+			state := stoppedState(10)
+			state.CurrentThread.Breakpoint = &api.Breakpoint{ID: 1, File: "main.go", Line: 10, TotalHitCount: hits}
+			state.Threads = []*api.Thread{state.CurrentThread}
+			return *state
+		}),
+	}))
+	return c, &commands
+}
+
+func TestSetIgnoreCountSkipsHits(t *testing.T) {
+	c, commands := hittingTarget(t)
+
+	response := c.SetIgnoreCount(1, 2)
+	if response.Status != "success" || response.Breakpoint.IgnoreCount != 2 {
+		t.Fatalf("Expected breakpoint 1 to ignore 2 hits, got %s %+v: %s", response.Status, response.Breakpoint, response.Context.ErrorMessage)
+	}
+
+	// The first two hits are continued past, the third stops
+	stop := c.Continue(context.Background())
+	if stop.Status != "success" || len(*commands) != 3 {
+		t.Fatalf("Expected the continue to run 3 times, got %d: %s", len(*commands), stop.Context.ErrorMessage)
+	}
+	if hit := stop.Context.DelveState.CurrentThread.Breakpoint; hit == nil || hit.TotalHitCount != 3 {
+		t.Errorf("Expected the stop at the third hit, got %+v", hit)
+	}
+
+	// With the count used up, the next hit stops right away
+	c.Continue(context.Background())
+	if len(*commands) != 4 {
+		t.Errorf("Expected the next continue to stop at the next hit, got %d commands", len(*commands))
+	}
+}
+
+func TestSetIgnoreCountErrors(t *testing.T) {
+	c, _ := hittingTarget(t)
+
+	testCases := []struct {
+		name     string
+		id       int
+		count    int
+		expected string
+	}{
+		{name: "Negative count", id: 1, count: -1, expected: "must be 0 or more"},
+		{name: "Internal breakpoint", id: -1, count: 1, expected: "internal to Delve"},
+		{name: "Unknown breakpoint", id: 7, count: 1, expected: "breakpoint 7 not found"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.SetIgnoreCount(tc.id, tc.count)
+			if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, tc.expected) {
+				t.Errorf("Expected an error containing %q, got %s: %s", tc.expected, response.Status, response.Context.ErrorMessage)
+			}
+		})
+	}
+	if len(c.ignoreCounts) != 0 {
+		t.Errorf("Expected no ignore count set, got %v", c.ignoreCounts)
+	}
+}
@@ -0,0 +1,156 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxInstructionLength is the longest machine instruction of the supported architectures,
+// 15 bytes on amd64, so disassembling that many bytes from a PC decodes the instruction there
+const maxInstructionLength = 15
+
+// StepInstruction executes a single machine instruction on the current thread, entering
+// calls. With reverse it steps back one instruction instead, which needs an rr recording.
+// The response holds the instruction at the new PC, the source line it maps to and the
+// general-purpose registers, with the ones the instruction changed listed separately.
+func (c *Client) StepInstruction(ctx context.Context, reverse bool) types.StepInstructionResponse {
+	if c.client == nil {
+		return c.createStepInstructionResponse(nil, reverse, "", nil, nil, nil, fmt.Errorf("no active debug session"))
+	}
+	if reverse {
+		if err := c.requireRecording(); err != nil {
+			return c.createStepInstructionResponse(nil, reverse, "", nil, nil, nil, err)
+		}
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createStepInstructionResponse(nil, reverse, "", nil, nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createStepInstructionResponse(nil, reverse, "", nil, nil, nil, fmt.Errorf("cannot step an instruction while the target is running; stop the target first"))
+	}
+	if state.CurrentThread == nil {
+		return c.createStepInstructionResponse(state, reverse, "", nil, nil, nil, fmt.Errorf("no current thread"))
+	}
+
+	fromPC := fmt.Sprintf("%#x", state.CurrentThread.PC)
+	before, err := c.client.ListThreadRegisters(state.CurrentThread.ID, false)
+	if err != nil {
+		logger.Debug("Warning: Failed to read registers before stepping: %v", err)
+	}
+
+	logger.Debug("Stepping one instruction from %s, reverse: %v", fromPC, reverse)
+	nextState, err := c.interruptibleStep(ctx, state, func() (*api.DebuggerState, error) {
+		if reverse {
+			return c.client.ReverseStepInstruction(false)
+		}
+		return c.client.StepInstruction(false)
+	})
+	if err != nil {
+		if errors.Is(err, ErrInterrupted) {
+			return c.createStepInstructionResponse(nextState, reverse, fromPC, nil, nil, nil, err)
+		}
+		return c.createStepInstructionResponse(nil, reverse, fromPC, nil, nil, nil, fmt.Errorf("step instruction command failed: %v", err))
+	}
+
+	if nextState.Exited || nextState.CurrentThread == nil {
+		return c.createStepInstructionResponse(nextState, reverse, fromPC, nil, nil, nil, nil)
+	}
+
+	thread := nextState.CurrentThread
+	instruction := c.instructionAt(thread.PC)
+
+	after, err := c.client.ListThreadRegisters(thread.ID, false)
+	if err != nil {
+		logger.Debug("Warning: Failed to read registers after stepping: %v", err)
+	}
+	registers := make([]types.Register, 0, len(after))
+	for _, reg := range after {
+		registers = append(registers, convertRegister(reg))
+	}
+
+	var changed []types.RegisterChange
+	// Registers of a different thread can't be compared
+	if thread.ID == state.CurrentThread.ID {
+		changed = changedRegisters(before, after)
+	}
+
+	return c.createStepInstructionResponse(nextState, reverse, fromPC, instruction, registers, changed, nil)
+}
+
+// instructionAt decodes the instruction at pc, or returns nil when it can't be disassembled
+func (c *Client) instructionAt(pc uint64) *types.Instruction {
+	inst := c.asmInstructionAt(pc)
+	if inst == nil {
+		return nil
+	}
+	instruction := convertInstruction(*inst)
+	return &instruction
+}
+
+// asmInstructionAt decodes the instruction at pc as Delve reports it, or returns nil when it
+// can't be disassembled
+func (c *Client) asmInstructionAt(pc uint64) *api.AsmInstruction {
+	scope := api.EvalScope{GoroutineID: -1}
+	instructions, err := c.client.DisassembleRange(scope, pc, pc+maxInstructionLength, api.IntelFlavour)
+	if err != nil {
+		logger.Debug("Warning: Failed to disassemble at %#x: %v", pc, err)
+		return nil
+	}
+	for i := range instructions {
+		if instructions[i].Loc.PC == pc {
+			return &instructions[i]
+		}
+	}
+	return nil
+}
+
+// changedRegisters lists the registers whose value differs between two reads, in the order
+// of the later one
+func changedRegisters(before, after api.Registers) []types.RegisterChange {
+	previous := make(map[string]string, len(before))
+	for _, reg := range before {
+		previous[reg.Name] = reg.Value
+	}
+
+	var changed []types.RegisterChange
+	for _, reg := range after {
+		value, ok := previous[reg.Name]
+		if ok && value != reg.Value {
+			changed = append(changed, types.RegisterChange{Name: reg.Name, Before: value, After: reg.Value})
+		}
+	}
+	return changed
+}
+
+// createStepInstructionResponse creates a StepInstructionResponse
+func (c *Client) createStepInstructionResponse(state *api.DebuggerState, reverse bool, fromPC string, instruction *types.Instruction, registers []types.Register, changed []types.RegisterChange, err error) types.StepInstructionResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "step_instruction"
+
+	response := types.StepInstructionResponse{
+		Status:  "success",
+		Context: context,
+		Reverse: reverse,
+		FromPC:  fromPC,
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	if state != nil && state.CurrentThread != nil && !state.Exited {
+		response.PC = fmt.Sprintf("%#x", state.CurrentThread.PC)
+	}
+	response.Instruction = instruction
+	response.Registers = registers
+	response.ChangedRegisters = changed
+	return response
+}
@@ -0,0 +1,96 @@
+package debugger
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestChangedRegisters(t *testing.T) {
+	before := api.Registers{{Name: "Rip", Value: "0x10"}, {Name: "Rax", Value: "0x1"}, {Name: "Rbx", Value: "0x2"}}
+
+	testCases := []struct {
+		name     string
+		after    api.Registers
+		expected []string
+	}{
+		{name: "Nothing changed", after: before, expected: nil},
+		{name: "Some changed", after: api.Registers{{Name: "Rip", Value: "0x14"}, {Name: "Rax", Value: "0x1"}, {Name: "Rbx", Value: "0x3"}}, expected: []string{"Rip", "Rbx"}},
+		{name: "Not read before", after: api.Registers{{Name: "Rcx", Value: "0x5"}}, expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			changed := changedRegisters(before, tc.after)
+			if len(changed) != len(tc.expected) {
+				t.Fatalf("Expected %d changed registers, got %d", len(tc.expected), len(changed))
+			}
+			for i, name := range tc.expected {
+				if changed[i].Name != name {
+					t.Errorf("Expected register %s, got %s", name, changed[i].Name)
+				}
+			}
+		})
+	}
+}
+
+func TestStepInstruction(t *testing.T) {
+	thread := func(pc uint64) *api.Thread {
+		return &api.Thread{ID: 1, PC: pc, File: "main.go", Line: 7, Function: &api.Function{Name_: "main.main"}, GoroutineID: 1}
+	}
+	registers := []api.Registers{
+		{{Name: "Rip", Value: "0x1000"}, {Name: "Rax", Value: "0x1"}, {Name: "Rbx", Value: "0x5"}},
+		{{Name: "Rip", Value: "0x1003"}, {Name: "Rax", Value: "0x2"}, {Name: "Rbx", Value: "0x5"}},
+	}
+	var commands []string
+	c, f := newFakeDelve(t, map[string]fakeHandler{
+		"State":    fakeState(&api.DebuggerState{CurrentThread: thread(0x1000), SelectedGoroutine: &api.Goroutine{ID: 1}}),
+		"Recorded": fakeResult(rpc2.RecordedOut{}),
+		"Command": fakeCommands(t, &commands, func(api.DebuggerCommand) api.DebuggerState {
+			return api.DebuggerState{CurrentThread: thread(0x1003), SelectedGoroutine: &api.Goroutine{ID: 1}}
+		}),
+		"ListRegisters": func(json.RawMessage) (interface{}, error) {
+			regs := registers[0]
+			registers = registers[1:]
+			return rpc2.ListRegistersOut{Regs: regs}, nil
+		},
+		"Disassemble": fakeResult(rpc2.DisassembleOut{Disassemble: api.AsmInstructions{
+			{Loc: api.Location{PC: 0x1003, File: "main.go", Line: 7}, Text: "add rax, 0x1", Bytes: []byte{0x48, 0x83, 0xc0}},
+		}}),
+	})
+
+	// Stepping back needs a recording, so nothing runs
+	if response := c.StepInstruction(context.Background(), true); response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "needs the rr backend") {
+		t.Errorf("Expected a reverse step to need a recording, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if f.called("Command") != 0 {
+		t.Fatalf("Expected no command run for a reverse step without a recording")
+	}
+
+	response := c.StepInstruction(context.Background(), false)
+	if response.Status != "success" {
+		t.Fatalf("Expected a step, got %s", response.Context.ErrorMessage)
+	}
+	if !reflect.DeepEqual(commands, []string{api.StepInstruction}) {
+		t.Errorf("Expected a single stepInstruction command, got %v", commands)
+	}
+	if response.FromPC != "0x1000" || response.PC != "0x1003" {
+		t.Errorf("Expected a step from 0x1000 to 0x1003, got %s to %s", response.FromPC, response.PC)
+	}
+	if response.Instruction == nil || response.Instruction.Text != "add rax, 0x1" || response.Instruction.Opcode != "4883c0" {
+		t.Errorf("Expected the instruction at the new PC, got %+v", response.Instruction)
+	}
+	if len(response.Registers) != 3 {
+		t.Errorf("Expected the registers after the step, got %+v", response.Registers)
+	}
+	expected := []types.RegisterChange{{Name: "Rip", Before: "0x1000", After: "0x1003"}, {Name: "Rax", Before: "0x1", After: "0x2"}}
+	if !reflect.DeepEqual(response.ChangedRegisters, expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, response.ChangedRegisters)
+	}
+}
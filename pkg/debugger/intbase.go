@@ -0,0 +1,76 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// integerBasePrefixes are the bases integers can be rendered in, with the prefix Go writes
+// their literals with
+var integerBasePrefixes = map[int]string{2: "0b", 8: "0o", 10: "", 16: "0x"}
+
+// checkIntegerBase returns the base to render integers in, 10 for 0, or an error for a
+// base other than 2, 8, 10 and 16
+func checkIntegerBase(base int) (int, error) {
+	if base == 0 {
+		return 10, nil
+	}
+	if _, ok := integerBasePrefixes[base]; !ok {
+		return 0, fmt.Errorf("unsupported base %d; use 2, 8, 10 or 16", base)
+	}
+	return base, nil
+}
+
+// rebaseIntegers rewrites the value of every integer in a loaded variable, its fields and
+// elements in the given base. A negative value keeps its sign in front of the prefix, as
+// in -0x1f4, rather than being shown in two's complement. The value of a named constant,
+// which Delve renders as "name (value)", keeps the name.
+func rebaseIntegers(v *api.Variable, base int) {
+	if v == nil || base == 10 {
+		return
+	}
+	if isIntegerKind(v.Kind) {
+		v.Value = rebaseIntegerValue(v.Value, base)
+	}
+	for i := range v.Children {
+		rebaseIntegers(&v.Children[i], base)
+	}
+}
+
+// rebaseIntegerValue renders a decimal integer as Delve gives it in base, leaving any value
+// that is not one as it is
+func rebaseIntegerValue(value string, base int) string {
+	// Named constants, e.g. "ModeRead|ModeWrite (3)"
+	if open := strings.LastIndex(value, " ("); open >= 0 && strings.HasSuffix(value, ")") {
+		return value[:open+2] + rebaseIntegerValue(value[open+2:len(value)-1], base) + ")"
+	}
+
+	prefix := integerBasePrefixes[base]
+	if digits, ok := strings.CutPrefix(value, "-"); ok {
+		n, err := strconv.ParseUint(digits, 10, 64)
+		if err != nil {
+			return value
+		}
+		return "-" + prefix + strconv.FormatUint(n, base)
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return value
+	}
+	return prefix + strconv.FormatUint(n, base)
+}
+
+// isIntegerKind reports whether a kind holds an integer
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
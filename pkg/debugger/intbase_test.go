@@ -0,0 +1,75 @@
+package debugger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestRebaseIntegerValue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		base     int
+		expected string
+	}{
+		{name: "Hexadecimal", value: "500", base: 16, expected: "0x1f4"},
+		{name: "Binary", value: "500", base: 2, expected: "0b111110100"},
+		{name: "Octal", value: "500", base: 8, expected: "0o764"},
+		{name: "Zero", value: "0", base: 16, expected: "0x0"},
+		{name: "Negative", value: "-500", base: 16, expected: "-0x1f4"},
+		{name: "Smallest int64", value: "-9223372036854775808", base: 16, expected: "-0x8000000000000000"},
+		{name: "Largest uint64", value: "18446744073709551615", base: 16, expected: "0xffffffffffffffff"},
+		{name: "Named constant", value: "ModeRead|ModeWrite (3)", base: 2, expected: "ModeRead|ModeWrite (0b11)"},
+		{name: "Not a number", value: "unreadable", base: 16, expected: "unreadable"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := rebaseIntegerValue(tc.value, tc.base); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestRebaseIntegers(t *testing.T) {
+	v := &api.Variable{
+		Kind: reflect.Struct,
+		Type: "main.request",
+		Children: []api.Variable{
+			{Name: "flags", Kind: reflect.Uint32, Value: "31"},
+			{Name: "delta", Kind: reflect.Int, Value: "-2"},
+			{Name: "name", Kind: reflect.String, Value: "42", Len: 2},
+			{Name: "ids", Kind: reflect.Slice, Len: 2, Children: []api.Variable{
+				{Kind: reflect.Int64, Value: "255"},
+				{Kind: reflect.Int64, Value: "16"},
+			}},
+		},
+	}
+
+	rebaseIntegers(v, 16)
+	if value := formatVariableValue(v); !strings.HasPrefix(value, "{flags:0x1f, delta:-0x2, name:42") {
+		t.Errorf("Expected the integers in hexadecimal, got %q", value)
+	}
+	if v.Children[2].Value != "42" {
+		t.Errorf("Expected the string left alone, got %q", v.Children[2].Value)
+	}
+	if v.Children[3].Children[0].Value != "0xff" || v.Children[3].Children[1].Value != "0x10" {
+		t.Errorf("Expected the elements in hexadecimal, got %q and %q", v.Children[3].Children[0].Value, v.Children[3].Children[1].Value)
+	}
+	if v.Children[1].Value != "-0x2" {
+		t.Errorf("Expected the sign kept, got %q", v.Children[1].Value)
+	}
+}
+
+func TestCheckIntegerBase(t *testing.T) {
+	if base, err := checkIntegerBase(0); err != nil || base != 10 {
+		t.Errorf("Expected base 0 to mean 10, got %d, %v", base, err)
+	}
+	if _, err := checkIntegerBase(7); err == nil || !strings.Contains(err.Error(), "unsupported base 7") {
+		t.Errorf("Expected an unsupported base error, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package debugger
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"reflect"
+	"regexp"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// interfaceLoadConfig loads the value an interface holds, following a pointer to it, with
+// the values of its fields
+var interfaceLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 2,
+	MaxStringLen:       512,
+	MaxArrayValues:     64,
+	MaxStructFields:    -1,
+}
+
+// importPathPrefix matches the import path before a package name in a type name, such as
+// "compress/" in "*compress/gzip.Writer"
+var importPathPrefix = regexp.MustCompile(`[\w.\-~]+(?:/[\w.\-~]+)*/`)
+
+// States of an inspected interface
+const (
+	interfaceNil        = "nil interface"                  // No dynamic type or value
+	interfaceNilPointer = "non-nil interface, nil pointer" // A typed nil pointer; == nil is false
+	interfaceValue      = "non-nil interface"
+)
+
+// InspectInterface evaluates an interface expression in the given frame and reports the
+// dynamic type it holds with the fields of the value, along with a type assertion that
+// evaluates to the concrete value. A nil interface is told apart from an interface holding
+// a nil pointer, which compares unequal to nil.
+func (c *Client) InspectInterface(expr string, frame int) types.InspectInterfaceResponse {
+	if c.client == nil {
+		return c.createInspectInterfaceResponse(nil, expr, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createInspectInterfaceResponse(nil, expr, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createInspectInterfaceResponse(nil, expr, nil, fmt.Errorf("cannot inspect interfaces while the target is running; stop the target first"))
+	}
+	if state.SelectedGoroutine == nil {
+		return c.createInspectInterfaceResponse(state, expr, nil, fmt.Errorf("no goroutine selected"))
+	}
+
+	scope := api.EvalScope{
+		GoroutineID: state.SelectedGoroutine.ID,
+		Frame:       frame,
+	}
+
+	logger.Debug("Inspecting interface %q in frame %d", expr, frame)
+	v, err := c.client.EvalVariable(scope, expr, interfaceLoadConfig)
+	if err != nil {
+		if isUnresolvedSymbol(err) {
+			return c.createInspectInterfaceResponse(state, expr, nil, fmt.Errorf("could not resolve %q: %v; variables in scope: %s", expr, err, c.scopeVariableNames(scope)))
+		}
+		return c.createInspectInterfaceResponse(state, expr, nil, fmt.Errorf("failed to evaluate %q: %v", expr, err))
+	}
+	if v == nil {
+		return c.createInspectInterfaceResponse(state, expr, nil, fmt.Errorf("expression %q produced no value", expr))
+	}
+	if v.Kind != reflect.Interface {
+		return c.createInspectInterfaceResponse(state, expr, nil, fmt.Errorf("%q is not an interface but a %s of type %s; use eval_expression to inspect it", expr, v.Kind, v.Type))
+	}
+
+	return c.createInspectInterfaceResponse(state, expr, v, nil)
+}
+
+// interfaceState classifies the value an interface holds
+func interfaceState(v *api.Variable) string {
+	if len(v.Children) == 0 || v.Children[0].Kind == reflect.Invalid {
+		return interfaceNil
+	}
+	concrete := &v.Children[0]
+	if concrete.Kind == reflect.Ptr && (len(concrete.Children) == 0 || concrete.Children[0].Addr == 0) {
+		return interfaceNilPointer
+	}
+	return interfaceValue
+}
+
+// assertExpression builds a type assertion of expr to a concrete type. Delve names types by
+// import path but parses assertions as Go, so the path is cut down to the package name.
+func assertExpression(expr, concreteType string) string {
+	typeName := importPathPrefix.ReplaceAllString(concreteType, "")
+	if !isOperand(expr) {
+		expr = "(" + expr + ")"
+	}
+	return fmt.Sprintf("%s.(%s)", expr, typeName)
+}
+
+// isOperand reports whether a type assertion can follow expr without parentheses
+func isOperand(expr string) bool {
+	node, err := parser.ParseExpr(expr)
+	if err != nil {
+		return false
+	}
+	switch node.(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.CallExpr, *ast.ParenExpr, *ast.TypeAssertExpr:
+		return true
+	}
+	return false
+}
+
+// interfaceFields returns the fields of the value an interface holds, looking through a pointer
+func interfaceFields(concrete *api.Variable) []types.Variable {
+	value := concrete
+	if value.Kind == reflect.Ptr && len(value.Children) > 0 {
+		value = &value.Children[0]
+	}
+	if value.Kind != reflect.Struct {
+		return nil
+	}
+
+	fields := make([]types.Variable, 0, len(value.Children))
+	for i := range value.Children {
+		fields = append(fields, convertVariableTree(&value.Children[i], "field", 0))
+	}
+	return fields
+}
+
+// createInspectInterfaceResponse creates an InspectInterfaceResponse
+func (c *Client) createInspectInterfaceResponse(state *api.DebuggerState, expr string, v *api.Variable, err error) types.InspectInterfaceResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "inspect_interface"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.InspectInterfaceResponse{
+			Status:     "error",
+			Context:    context,
+			Expression: expr,
+		}
+	}
+
+	response := types.InspectInterfaceResponse{
+		Status:        "success",
+		Context:       context,
+		Expression:    expr,
+		InterfaceType: v.Type,
+		State:         interfaceState(v),
+		Tree:          renderVariableTree(v),
+	}
+	if response.State == interfaceNil {
+		response.IsNil = true
+		return response
+	}
+
+	concrete := &v.Children[0]
+	response.ConcreteType = concrete.Type
+	response.ConcreteKind = concrete.Kind.String()
+	response.AssertExpression = assertExpression(expr, concrete.Type)
+	response.Value = formatVariableValue(concrete)
+	if response.State == interfaceNilPointer {
+		response.HoldsNilPointer = true
+		return response
+	}
+	response.Fields = interfaceFields(concrete)
+	return response
+}
@@ -0,0 +1,94 @@
+package debugger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestAssertExpression(t *testing.T) {
+	testCases := []struct {
+		name         string
+		expr         string
+		concreteType string
+		expected     string
+	}{
+		{name: "Local type", expr: "err", concreteType: "*main.myErr", expected: "err.(*main.myErr)"},
+		{name: "Standard library path", expr: "w", concreteType: "*compress/gzip.Writer", expected: "w.(*gzip.Writer)"},
+		{name: "Module path", expr: "v", concreteType: "github.com/a/b.T", expected: "v.(b.T)"},
+		{name: "Field selector", expr: "s.w", concreteType: "*bytes.Buffer", expected: "s.w.(*bytes.Buffer)"},
+		{name: "Dereference", expr: "*p", concreteType: "main.point", expected: "(*p).(main.point)"},
+		{name: "Map of paths", expr: "m[0]", concreteType: "map[string]*net/http.Request", expected: "m[0].(map[string]*http.Request)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := assertExpression(tc.expr, tc.concreteType); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestInterfaceState(t *testing.T) {
+	testCases := []struct {
+		name     string
+		variable api.Variable
+		expected string
+	}{
+		{name: "Nil interface", variable: api.Variable{Kind: reflect.Interface, Children: []api.Variable{{Kind: reflect.Invalid}}}, expected: interfaceNil},
+		{name: "No children", variable: api.Variable{Kind: reflect.Interface}, expected: interfaceNil},
+		{name: "Nil pointer", variable: api.Variable{Kind: reflect.Interface, Children: []api.Variable{{Kind: reflect.Ptr, Children: []api.Variable{{Kind: reflect.Struct}}}}}, expected: interfaceNilPointer},
+		{name: "Pointer", variable: api.Variable{Kind: reflect.Interface, Children: []api.Variable{{Kind: reflect.Ptr, Children: []api.Variable{{Kind: reflect.Struct, Addr: 0xc000010000}}}}}, expected: interfaceValue},
+		{name: "Value", variable: api.Variable{Kind: reflect.Interface, Children: []api.Variable{{Kind: reflect.Struct}}}, expected: interfaceValue},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := interfaceState(&tc.variable); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestInspectInterface(t *testing.T) {
+	pathError := &api.Variable{Name: "err", Type: "error", Kind: reflect.Interface, Children: []api.Variable{
+		{Type: "*io/fs.PathError", Kind: reflect.Ptr, Children: []api.Variable{
+			{Type: "io/fs.PathError", Kind: reflect.Struct, Addr: 0xc000010000, Children: []api.Variable{
+				{Name: "Op", Type: "string", Kind: reflect.String, Value: "open", Len: 4},
+				{Name: "Path", Type: "string", Kind: reflect.String, Value: "/missing", Len: 8},
+			}},
+		}},
+	}}
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(12)),
+		"Eval": fakeEval(t, map[string]*api.Variable{
+			"err": pathError,
+			"n":   {Name: "n", Type: "int", Kind: reflect.Int, Value: "3"},
+		}),
+	})
+
+	response := c.InspectInterface("err", 0)
+	if response.Status != "success" {
+		t.Fatalf("Expected err inspected, got %s", response.Context.ErrorMessage)
+	}
+	if response.State != interfaceValue || response.IsNil || response.HoldsNilPointer {
+		t.Errorf("Expected a non-nil interface, got %+v", response)
+	}
+	if response.InterfaceType != "error" || response.ConcreteType != "*io/fs.PathError" || response.AssertExpression != "err.(*fs.PathError)" {
+		t.Errorf("Expected err to hold a *fs.PathError, got %s holding %s, asserted with %s", response.InterfaceType, response.ConcreteType, response.AssertExpression)
+	}
+	if len(response.Fields) != 2 || response.Fields[0].Name != "Op" || response.Fields[1].Value != "/missing" {
+		t.Errorf("Expected the fields of the PathError, got %+v", response.Fields)
+	}
+
+	if response := c.InspectInterface("n", 0); response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "is not an interface") {
+		t.Errorf("Expected an int to be refused, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if response := c.InspectInterface("missing", 0); response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, `could not resolve "missing"`) {
+		t.Errorf("Expected an unknown name to be reported, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+}
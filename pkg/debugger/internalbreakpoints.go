@@ -0,0 +1,118 @@
+package debugger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+)
+
+// Kinds of breakpoints, as reported by ListBreakpoints and stops
+const (
+	userBreakpointKind = "user"
+	stepBreakpointKind = "step" // Stepping breakpoint of a next, step or stepout
+)
+
+// internalBreakpointKinds are Delve's internal breakpoints that have an ID of their own
+var internalBreakpointKinds = map[int]string{
+	unrecoveredPanicBreakpointID: "unrecovered-panic",
+	fatalThrowBreakpointID:       "fatal-throw",
+}
+
+// breakletKinds names the kinds of the internal breakpoints without an ID, keyed by the
+// name Delve describes their breaklets with
+var breakletKinds = map[string]string{
+	"Next":                                "step-over",
+	"NextDefer":                           "step-over-defer",
+	"NextInactivatedBreakpoint":           "step-over-inactive",
+	"Step":                                "step-into",
+	"StepIntoNewProcBreakpoint":           "step-into-goroutine",
+	"StepIntoRangeOverFuncBodyBreakpoint": "step-into-range-func",
+	"WatchOutOfScope":                     "watchpoint-scope",
+	"StackResizeBreakpoint":               "stack-resize",
+	"PluginOpenBreakpoint":                "plugin-open",
+}
+
+// internalBreakpointPurposes explains what each kind of internal breakpoint is for
+var internalBreakpointPurposes = map[string]string{
+	"unrecovered-panic":    "stops when a panic is not recovered and is about to crash the program",
+	"fatal-throw":          "stops on fatal runtime errors, such as concurrent map writes or all goroutines being asleep",
+	"hardcoded":            "a runtime.Breakpoint call in the program",
+	stepBreakpointKind:     "set by next, step and stepout to stop where the step ends",
+	"step-over":            "set by next, step and stepout to stop at the next line of the goroutine that is stepping",
+	"step-over-defer":      "set by next to stop in the deferred calls that run when the function returns",
+	"step-over-inactive":   "a step-over breakpoint turned off, as the goroutine that is stepping left its frame",
+	"step-into":            "set by step to stop in the function being called",
+	"step-into-goroutine":  "set by step to stop in the goroutine a go statement starts",
+	"step-into-range-func": "set by step to stop in the body of a range-over-func loop",
+	"watchpoint-scope":     "removes a watchpoint when the frame of the variable it watches returns",
+	"stack-resize":         "moves watchpoints on a goroutine's stack when the stack is moved",
+	"plugin-open":          "sets breakpoints in plugins as the program opens them",
+}
+
+// breakpointKind labels a breakpoint with the user kind, or with its purpose for one Delve
+// set itself. Delve gives its stepping and other purely internal breakpoints ID 0, and
+// describes what they are for in VerboseDescr; a stop at a runtime.Breakpoint call has ID
+// 0 too.
+func breakpointKind(bp *api.Breakpoint) string {
+	if bp.ID > 0 {
+		return userBreakpointKind
+	}
+	if bp.Name == proc.HardcodedBreakpoint {
+		return "hardcoded"
+	}
+	if kind, ok := internalBreakpointKinds[bp.ID]; ok {
+		return kind
+	}
+	for _, descr := range bp.VerboseDescr {
+		name, _, _ := strings.Cut(descr, " ")
+		if kind, ok := breakletKinds[name]; ok {
+			return kind
+		}
+	}
+	return "internal"
+}
+
+// resolveInternalBreakpoint fills in the source position of an internal breakpoint, which
+// Delve only gives the address of
+func (c *Client) resolveInternalBreakpoint(bp *api.Breakpoint) {
+	if bp.File != "" || bp.Addr == 0 {
+		return
+	}
+	locs, _, err := c.client.FindLocation(api.EvalScope{GoroutineID: -1}, fmt.Sprintf("*%#x", bp.Addr), false, nil)
+	if err != nil || len(locs) == 0 {
+		logger.Debug("Warning: Failed to resolve internal breakpoint at %#x: %v", bp.Addr, err)
+		return
+	}
+	bp.File, bp.Line = locs[0].File, locs[0].Line
+	if locs[0].Function != nil {
+		bp.FunctionName = locs[0].Function.Name()
+	}
+}
+
+// stepCompletion is a stop that completed a next, step or stepout a breakpoint interrupted
+type stepCompletion struct {
+	goroutineID int64
+	pc          uint64
+}
+
+// noteCompletedStep remembers whether a continue stopped because it completed a step that
+// was in progress: Delve resumes the step with the continue, and a goroutine reaching the
+// end of the step stops at an internal breakpoint that the state does not report.
+func (c *Client) noteCompletedStep(stepping bool, state *api.DebuggerState) {
+	c.completedStep = nil
+	if !stepping || state == nil || state.Exited || state.NextInProgress || state.CurrentThread == nil || state.CurrentThread.Breakpoint != nil {
+		return
+	}
+	c.completedStep = &stepCompletion{goroutineID: state.CurrentThread.GoroutineID, pc: state.CurrentThread.PC}
+}
+
+// completesStep reports whether a state is the stop noteCompletedStep remembered
+func (c *Client) completesStep(state *api.DebuggerState) bool {
+	if c.completedStep == nil || state.CurrentThread == nil {
+		return false
+	}
+	return state.CurrentThread.GoroutineID == c.completedStep.goroutineID && state.CurrentThread.PC == c.completedStep.pc
+}
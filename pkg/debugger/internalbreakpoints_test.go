@@ -0,0 +1,96 @@
+package debugger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestBreakpointKind(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bp       *api.Breakpoint
+		expected string
+	}{
+		{name: "User breakpoint", bp: &api.Breakpoint{ID: 3}, expected: "user"},
+		{name: "Unrecovered panic", bp: &api.Breakpoint{ID: -1, Name: proc.UnrecoveredPanic}, expected: "unrecovered-panic"},
+		{name: "Fatal throw", bp: &api.Breakpoint{ID: -2, Name: proc.FatalThrow}, expected: "fatal-throw"},
+		{name: "Hardcoded", bp: &api.Breakpoint{Name: proc.HardcodedBreakpoint}, expected: "hardcoded"},
+		{name: "Step over", bp: &api.Breakpoint{VerboseDescr: []string{"OriginalData=0x0", `Next Cond="runtime.curg.goid == 6"`}}, expected: "step-over"},
+		{name: "Step over defer", bp: &api.Breakpoint{VerboseDescr: []string{"OriginalData=0x0", `NextDefer Cond="" DeferReturns=[]`}}, expected: "step-over-defer"},
+		{name: "Step into", bp: &api.Breakpoint{VerboseDescr: []string{"OriginalData=0x0", `Step Cond=""`}}, expected: "step-into"},
+		{name: "Unknown", bp: &api.Breakpoint{VerboseDescr: []string{"OriginalData=0x0", "Unknown 4096"}}, expected: "internal"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if kind := breakpointKind(tc.bp); kind != tc.expected {
+				t.Errorf("Expected kind %q, got %q", tc.expected, kind)
+			}
+		})
+	}
+}
+
+func TestConvertInternalBreakpoint(t *testing.T) {
+	breakpoint := convertBreakpoint(&api.Breakpoint{ID: -1, Name: proc.UnrecoveredPanic})
+	if !breakpoint.Internal || breakpoint.Kind != "unrecovered-panic" || breakpoint.Purpose == "" {
+		t.Errorf("Expected an internal unrecovered-panic breakpoint with a purpose, got %+v", breakpoint)
+	}
+
+	breakpoint = convertBreakpoint(&api.Breakpoint{ID: 1})
+	if breakpoint.Internal || breakpoint.Kind != "user" || breakpoint.Purpose != "" {
+		t.Errorf("Expected a user breakpoint without a purpose, got %+v", breakpoint)
+	}
+}
+
+func TestInternalStopDetail(t *testing.T) {
+	state := &api.DebuggerState{CurrentThread: &api.Thread{Breakpoint: &api.Breakpoint{Name: proc.HardcodedBreakpoint}}}
+	detail := getStopDetail(state)
+	if detail.Kind != types.StopInternal || detail.InternalKind != "hardcoded" {
+		t.Fatalf("Expected a stop at an internal hardcoded breakpoint, got %+v", detail)
+	}
+	if reason := formatStopDetail(detail); !strings.HasPrefix(reason, "stopped by internal hardcoded breakpoint") {
+		t.Errorf("Expected the stop reason to name the hardcoded breakpoint, got %q", reason)
+	}
+}
+
+func TestNoteCompletedStep(t *testing.T) {
+	stopAt := func(goroutineID int64, pc uint64) *api.DebuggerState {
+		return &api.DebuggerState{CurrentThread: &api.Thread{GoroutineID: goroutineID, PC: pc}}
+	}
+
+	c := &Client{}
+	c.noteCompletedStep(true, stopAt(6, 0x1000))
+	if !c.completesStep(stopAt(6, 0x1000)) {
+		t.Error("Expected the stop after an interrupted step to complete it")
+	}
+	if c.completesStep(stopAt(6, 0x2000)) || c.completesStep(stopAt(7, 0x1000)) {
+		t.Error("Expected only the stop that completed the step to be reported as such")
+	}
+	if reason := c.createDebugContext(stopAt(6, 0x1000)).StopReason; !strings.HasPrefix(reason, "stopped by internal step breakpoint") {
+		t.Errorf("Expected the stop reason to name the step breakpoint, got %q", reason)
+	}
+
+	testCases := []struct {
+		name     string
+		stepping bool
+		state    *api.DebuggerState
+	}{
+		{name: "No step in progress", state: stopAt(6, 0x1000)},
+		{name: "Stopped at a breakpoint", stepping: true, state: &api.DebuggerState{CurrentThread: &api.Thread{GoroutineID: 6, PC: 0x1000, Breakpoint: &api.Breakpoint{ID: 1}}}},
+		{name: "Step still in progress", stepping: true, state: &api.DebuggerState{NextInProgress: true, CurrentThread: &api.Thread{GoroutineID: 6, PC: 0x1000}}},
+		{name: "Exited", stepping: true, state: &api.DebuggerState{Exited: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c.noteCompletedStep(tc.stepping, tc.state)
+			if c.completedStep != nil {
+				t.Errorf("Expected no completed step, got %+v", c.completedStep)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// SetNextStatement moves execution of the current goroutine to another line of the
+// function it is stopped in, to skip a line or run a block again. An empty file means the
+// current file. Jumps out of the current function are refused.
+//
+// Delve's API has no call for writing the program counter, so after validating the target
+// and working out what the jump would skip or re-run this reports that the move cannot be
+// made, like SetRegister.
+func (c *Client) SetNextStatement(file string, line int) types.SetNextStatementResponse {
+	if c.client == nil {
+		return c.createSetNextStatementResponse(nil, nil, nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createSetNextStatementResponse(nil, nil, nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createSetNextStatementResponse(nil, nil, nil, nil, fmt.Errorf("cannot set the next statement while the target is running; stop the target first"))
+	}
+
+	from := getCurrentPosition(state)
+	if from == nil {
+		return c.createSetNextStatementResponse(state, nil, nil, nil, fmt.Errorf("no current function to jump within"))
+	}
+
+	if file == "" {
+		file = from.File
+	}
+
+	scope := api.EvalScope{GoroutineID: state.CurrentThread.GoroutineID}
+
+	locs, _, err := c.client.FindLocation(scope, fmt.Sprintf("%s:%d", file, line), false, nil)
+	if err != nil {
+		return c.createSetNextStatementResponse(state, from, nil, nil, fmt.Errorf("failed to find %s:%d: %v", file, line, err))
+	}
+	if len(locs) == 0 {
+		return c.createSetNextStatementResponse(state, from, nil, nil, fmt.Errorf("no code at %s:%d", file, line))
+	}
+
+	loc := locs[0]
+	target := &types.SourcePosition{File: loc.File, Line: loc.Line, Function: getFunctionNameFromLocation(loc)}
+
+	if target.Function != from.Function {
+		return c.createSetNextStatementResponse(state, from, target, nil, fmt.Errorf("refusing to jump to %s:%d: it is in %s, outside the current function %s", file, line, target.Function, from.Function))
+	}
+
+	if target.Line == from.Line {
+		return c.createSetNextStatementResponse(state, from, target, nil, fmt.Errorf("already at line %d", from.Line))
+	}
+
+	locals, err := c.client.ListLocalVariables(scope, api.LoadConfig{})
+	if err != nil {
+		logger.Debug("Warning: Failed to list locals for jump warnings: %v", err)
+	}
+	warnings := jumpWarnings(from.Line, target.Line, locals)
+
+	logger.Debug("Requested jump from %s:%d to %s:%d (%#x)", from.File, from.Line, target.File, target.Line, loc.PC)
+
+	return c.createSetNextStatementResponse(state, from, target, warnings, fmt.Errorf("cannot jump to %s:%d: writing the program counter is not supported by the Delve API", target.File, target.Line))
+}
+
+// jumpWarnings describes what jumping from one line to another in the same function
+// would skip or run again. locals are the variables in scope at the current line.
+func jumpWarnings(fromLine, toLine int, locals []api.Variable) []string {
+	if toLine > fromLine {
+		return []string{fmt.Sprintf("jumping forward skips %s; variables declared there keep whatever is in their memory, usually zero or stale values", formatLineRange(fromLine, toLine-1))}
+	}
+
+	warnings := []string{fmt.Sprintf("jumping backward runs %s again, repeating any side effects", formatLineRange(toLine, fromLine-1))}
+
+	var redeclared []string
+	for _, v := range locals {
+		if v.DeclLine >= int64(toLine) && v.DeclLine < int64(fromLine) {
+			redeclared = append(redeclared, v.Name)
+		}
+	}
+	if len(redeclared) > 0 {
+		sort.Strings(redeclared)
+		warnings = append(warnings, fmt.Sprintf("jumping backward over the declarations of %s re-initializes them and can corrupt state that still refers to their old values", strings.Join(redeclared, ", ")))
+	}
+	return warnings
+}
+
+// formatLineRange renders an inclusive range of lines, e.g. "lines 10-12" or "line 10"
+func formatLineRange(first, last int) string {
+	if first == last {
+		return fmt.Sprintf("line %d", first)
+	}
+	return fmt.Sprintf("lines %d-%d", first, last)
+}
+
+// createSetNextStatementResponse creates a SetNextStatementResponse
+func (c *Client) createSetNextStatementResponse(state *api.DebuggerState, from, target *types.SourcePosition, warnings []string, err error) types.SetNextStatementResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "set_next_statement"
+
+	response := types.SetNextStatementResponse{
+		Status:   "success",
+		Context:  context,
+		From:     from,
+		Target:   target,
+		Warnings: warnings,
+	}
+	if state != nil {
+		response.Location = getCurrentLocation(state)
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+	}
+	return response
+}
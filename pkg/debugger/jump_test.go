@@ -0,0 +1,58 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestJumpWarnings(t *testing.T) {
+	locals := []api.Variable{
+		{Name: "total", DeclLine: 10},
+		{Name: "i", DeclLine: 12},
+		{Name: "err", DeclLine: 14},
+	}
+
+	testCases := []struct {
+		name     string
+		from     int
+		to       int
+		expected []string
+	}{
+		{
+			name: "forward",
+			from: 14,
+			to:   17,
+			expected: []string{
+				"jumping forward skips lines 14-16; variables declared there keep whatever is in their memory, usually zero or stale values",
+			},
+		},
+		{
+			name: "backward over declarations",
+			from: 15,
+			to:   11,
+			expected: []string{
+				"jumping backward runs lines 11-14 again, repeating any side effects",
+				"jumping backward over the declarations of err, i re-initializes them and can corrupt state that still refers to their old values",
+			},
+		},
+		{
+			name: "backward without declarations",
+			from: 12,
+			to:   11,
+			expected: []string{
+				"jumping backward runs line 11 again, repeating any side effects",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			warnings := jumpWarnings(tc.from, tc.to, locals)
+			if !reflect.DeepEqual(warnings, tc.expected) {
+				t.Errorf("Expected %q, got %q", tc.expected, warnings)
+			}
+		})
+	}
+}
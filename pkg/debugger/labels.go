@@ -0,0 +1,72 @@
+package debugger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// labelFilter scopes a breakpoint to goroutines carrying a pprof label. Delve has no such
+// filter, so the breakpoint stops every goroutine and hits on other goroutines are skipped
+// by continuing again.
+type labelFilter struct {
+	label   string // key=value the goroutine must carry
+	skipped uint64 // Hits on other goroutines continued past
+}
+
+// parseGoroutineLabel checks a key=value goroutine label
+func parseGoroutineLabel(label string) (string, error) {
+	key, value, ok := strings.Cut(strings.TrimSpace(label), "=")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if !ok || key == "" {
+		return "", fmt.Errorf("invalid goroutine label %q: expected key=value, e.g. \"handler=checkout\"", label)
+	}
+	return key + "=" + value, nil
+}
+
+// goroutineHasLabel reports whether a goroutine carries a label given as key or key=value
+func goroutineHasLabel(g *api.Goroutine, label string) bool {
+	if g == nil {
+		return false
+	}
+	key, value, hasValue := strings.Cut(label, "=")
+	labelValue, ok := g.Labels[key]
+	return ok && (!hasValue || labelValue == value)
+}
+
+// setLabelFilter scopes a breakpoint to goroutines carrying label
+func (c *Client) setLabelFilter(id int, label string) {
+	if c.labelFilters == nil {
+		c.labelFilters = make(map[int]*labelFilter)
+	}
+	c.labelFilters[id] = &labelFilter{label: label}
+}
+
+// skipFilteredHit reports whether the program stopped at a label-scoped breakpoint on a
+// goroutine without the label, so it should be continued without surfacing the stop
+func (c *Client) skipFilteredHit(state *api.DebuggerState) bool {
+	if state == nil || state.Exited || state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil {
+		return false
+	}
+
+	bp := state.CurrentThread.Breakpoint
+	filter := c.labelFilters[bp.ID]
+	if filter == nil || goroutineHasLabel(state.SelectedGoroutine, filter.label) {
+		return false
+	}
+
+	filter.skipped++
+	logger.Debug("Skipping hit of breakpoint %d on goroutine %d without label %s", bp.ID, state.CurrentThread.GoroutineID, filter.label)
+	return true
+}
+
+// annotateLabelFilter adds the goroutine label a breakpoint is scoped to, if any
+func (c *Client) annotateLabelFilter(breakpoint *types.Breakpoint) {
+	if filter := c.labelFilters[breakpoint.ID]; filter != nil {
+		breakpoint.GoroutineLabel = filter.label
+		breakpoint.SkippedHits = filter.skipped
+	}
+}
@@ -0,0 +1,95 @@
+package debugger
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestParseGoroutineLabel(t *testing.T) {
+	testCases := []struct {
+		label    string
+		expected string
+		wantErr  bool
+	}{
+		{label: "handler=checkout", expected: "handler=checkout"},
+		{label: " handler = checkout ", expected: "handler=checkout"},
+		{label: "handler=", expected: "handler="},
+		{label: "handler", wantErr: true},
+		{label: "=checkout", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			result, err := parseGoroutineLabel(tc.label)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error for %q, got %q", tc.label, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestSkipFilteredHit(t *testing.T) {
+	c := &Client{}
+	c.setLabelFilter(1, "handler=checkout")
+
+	stopAt := func(bpID int, labels map[string]string) *api.DebuggerState {
+		return &api.DebuggerState{
+			CurrentThread:     &api.Thread{GoroutineID: 7, Breakpoint: &api.Breakpoint{ID: bpID}},
+			SelectedGoroutine: &api.Goroutine{ID: 7, Labels: labels},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		state    *api.DebuggerState
+		expected bool
+	}{
+		{
+			name:     "Matching label",
+			state:    stopAt(1, map[string]string{"handler": "checkout"}),
+			expected: false,
+		},
+		{
+			name:     "Other label value",
+			state:    stopAt(1, map[string]string{"handler": "cart"}),
+			expected: true,
+		},
+		{
+			name:     "No labels",
+			state:    stopAt(1, nil),
+			expected: true,
+		},
+		{
+			name:     "Breakpoint without filter",
+			state:    stopAt(2, nil),
+			expected: false,
+		},
+		{
+			name:     "Not at a breakpoint",
+			state:    &api.DebuggerState{CurrentThread: &api.Thread{}},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := c.skipFilteredHit(tc.state); result != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+
+	if skipped := c.labelFilters[1].skipped; skipped != 2 {
+		t.Errorf("Expected 2 skipped hits, got %d", skipped)
+	}
+}
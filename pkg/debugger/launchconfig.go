@@ -0,0 +1,97 @@
+package debugger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// SetLaunchEnv replaces the extra KEY=VALUE environment variables the program is launched
+// with, starting from the next restart. An empty env launches it with this server's
+// environment alone. The running process keeps the environment it started with.
+func (c *Client) SetLaunchEnv(env []string) types.LaunchConfigResponse {
+	if err := c.requireLaunched(); err != nil {
+		return c.createLaunchConfigResponse("set_launch_env", err)
+	}
+	if err := validateLaunchEnv(env); err != nil {
+		return c.createLaunchConfigResponse("set_launch_env", err)
+	}
+
+	logger.Debug("Setting launch environment to %v", env)
+	c.launchEnv = env
+	return c.createLaunchConfigResponse("set_launch_env", nil)
+}
+
+// SetLaunchArgs replaces the arguments the program is launched with, starting from the
+// next restart. For a test binary these are its flags, such as -test.run, which replace
+// the ones it was launched with.
+func (c *Client) SetLaunchArgs(args []string) types.LaunchConfigResponse {
+	if err := c.requireLaunched(); err != nil {
+		return c.createLaunchConfigResponse("set_launch_args", err)
+	}
+
+	logger.Debug("Setting launch arguments to %v", args)
+	c.launchArgs = args
+	return c.createLaunchConfigResponse("set_launch_args", nil)
+}
+
+// requireLaunched checks that the session launched its program, so restarting it launches
+// it again with the stored configuration
+func (c *Client) requireLaunched() error {
+	switch {
+	case c.client == nil:
+		return fmt.Errorf("no active debug session")
+	case c.coreFile != "":
+		return fmt.Errorf("a core dump has no launch configuration; only launched programs can be reconfigured")
+	case c.remoteAddr != "":
+		return fmt.Errorf("the remote Delve server launched the program; only programs launched by this server can be reconfigured")
+	case c.target == "":
+		return fmt.Errorf("an attached process has no launch configuration; only launched programs can be reconfigured")
+	}
+	return nil
+}
+
+// validateLaunchEnv checks that each environment variable is a KEY=VALUE pair
+func validateLaunchEnv(env []string) error {
+	for _, kv := range env {
+		if key, _, ok := strings.Cut(kv, "="); !ok || key == "" {
+			return fmt.Errorf("invalid environment variable %q, expected KEY=VALUE", kv)
+		}
+	}
+	return nil
+}
+
+// createLaunchConfigResponse creates a LaunchConfigResponse with the configuration the
+// next restart uses
+func (c *Client) createLaunchConfigResponse(operation string, err error) types.LaunchConfigResponse {
+	var state *api.DebuggerState
+	if c.client != nil {
+		var stateErr error
+		if state, stateErr = c.client.GetStateNonBlocking(); stateErr != nil {
+			logger.Debug("Warning: Failed to get state for launch configuration: %v", stateErr)
+		}
+	}
+
+	context := c.createDebugContext(state)
+	context.Operation = operation
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.LaunchConfigResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	return types.LaunchConfigResponse{
+		Status:     "success",
+		Context:    context,
+		Program:    c.target,
+		Args:       append([]string{}, c.launchArgs...),
+		Env:        append([]string{}, c.launchEnv...),
+		WorkingDir: c.launchWorkingDir,
+		Note:       "the running process is unchanged; restart to launch it with this configuration",
+	}
+}
@@ -0,0 +1,107 @@
+package debugger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+func TestRequireLaunched(t *testing.T) {
+	testCases := []struct {
+		name          string
+		setup         func(c *Client)
+		expectedError string
+	}{
+		{name: "No session", setup: func(c *Client) { c.client = nil }, expectedError: "no active debug session"},
+		{name: "Launched", setup: func(c *Client) { c.target = "/tmp/app" }},
+		{name: "Attached", expectedError: "an attached process"},
+		{name: "Core dump", setup: func(c *Client) { c.coreFile = "/tmp/core" }, expectedError: "a core dump"},
+		{name: "Remote", setup: func(c *Client) { c.remoteAddr = "localhost:2345" }, expectedError: "the remote Delve server"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient()
+			c.client = &rpc2.RPCClient{}
+			if tc.setup != nil {
+				tc.setup(c)
+			}
+			err := c.requireLaunched()
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("Expected an error containing %q, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestValidateLaunchEnv(t *testing.T) {
+	testCases := []struct {
+		name        string
+		env         []string
+		expectError bool
+	}{
+		{name: "Empty"},
+		{name: "Pairs", env: []string{"MODE=debug", "EMPTY="}},
+		{name: "Value with equals sign", env: []string{"DSN=user=app"}},
+		{name: "Missing equals sign", env: []string{"MODE"}, expectError: true},
+		{name: "Missing key", env: []string{"=debug"}, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateLaunchEnv(tc.env)
+			if (err != nil) != tc.expectError {
+				t.Errorf("Expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestSetLaunchConfig(t *testing.T) {
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+	})
+	c.target = "/tmp/app"
+	c.launchArgs = []string{"-port", "8080"}
+	c.launchWorkingDir = "/src/app"
+
+	response := c.SetLaunchEnv([]string{"MODE=debug", "DSN=user=app"})
+	if response.Status != "success" || response.Program != "/tmp/app" || response.WorkingDir != "/src/app" {
+		t.Fatalf("Expected the configuration of /tmp/app, got %s: %+v", response.Status, response)
+	}
+	if !reflect.DeepEqual(response.Env, []string{"MODE=debug", "DSN=user=app"}) || !reflect.DeepEqual(response.Args, []string{"-port", "8080"}) {
+		t.Errorf("Expected the new environment alongside the unchanged arguments, got %v and %v", response.Env, response.Args)
+	}
+	if response.Context.CurrentLocation == nil {
+		t.Errorf("Expected the response to say where the running process is stopped")
+	}
+
+	// An invalid environment leaves the one set before in place
+	response = c.SetLaunchEnv([]string{"MODE=release", "=oops"})
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, `invalid environment variable "=oops"`) {
+		t.Errorf("Expected the variable without a key to be refused, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if !reflect.DeepEqual(c.launchEnv, []string{"MODE=debug", "DSN=user=app"}) {
+		t.Errorf("Expected the previous environment kept, got %v", c.launchEnv)
+	}
+
+	response = c.SetLaunchArgs([]string{"-test.run", "TestAdd"})
+	if response.Status != "success" || !reflect.DeepEqual(c.launchArgs, []string{"-test.run", "TestAdd"}) || len(response.Env) != 2 {
+		t.Errorf("Expected the arguments replaced and the environment kept, got %v and %v", c.launchArgs, response.Env)
+	}
+
+	// An empty configuration launches with this server's environment and no arguments
+	c.SetLaunchEnv(nil)
+	response = c.SetLaunchArgs(nil)
+	if response.Env == nil || len(response.Env) != 0 || response.Args == nil || len(response.Args) != 0 {
+		t.Errorf("Expected an empty environment and arguments, listed as empty, got %v and %v", response.Env, response.Args)
+	}
+}
@@ -0,0 +1,238 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// lineProbe is the breakpoint whose hits are counted for one line of a LineCoverage run
+type lineProbe struct {
+	line     int
+	bp       *api.Breakpoint // Tracepoint set for the run, or a breakpoint already at the line
+	owned    bool            // Whether bp was set for the run and is removed after it
+	baseline *api.Breakpoint // Hit counts of a breakpoint already at the line, before the run
+	err      error
+}
+
+// LineCoverage sets a tracepoint on each of the given lines of file, continues the program
+// and reports how often each line was hit, removing the tracepoints afterwards. The run
+// ends when the program exits, stops at another breakpoint, or is halted because ctx is
+// done. A line that already has a breakpoint is counted with the hits it gets during the
+// run, and the breakpoint stays. Every hit stops the program for Delve to count it, so
+// lines in hot loops slow the run down.
+func (c *Client) LineCoverage(ctx context.Context, file string, lines []int) types.LineCoverageResponse {
+	if c.client == nil {
+		return c.createLineCoverageResponse(nil, file, nil, fmt.Errorf("no active debug session"))
+	}
+	if len(lines) == 0 {
+		return c.createLineCoverageResponse(nil, file, nil, fmt.Errorf("no lines given to cover"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createLineCoverageResponse(nil, file, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createLineCoverageResponse(nil, file, nil, fmt.Errorf("cannot cover lines while the target is running; stop the target first"))
+	}
+
+	probes := c.setLineProbes(file, lines)
+	defer c.clearLineProbes(probes)
+
+	var traced int
+	for _, p := range probes {
+		if p.err == nil {
+			traced++
+		}
+	}
+	if traced == 0 {
+		return c.createLineCoverageResponse(state, file, c.lineHits(probes), fmt.Errorf("none of the lines of %s could be traced: %v", file, probes[0].err))
+	}
+
+	logger.Debug("Covering %d lines of %s", traced, file)
+	delveState, err := c.continueExecution(ctx)
+	if err != nil && !errors.Is(err, ErrInterrupted) {
+		return c.createLineCoverageResponse(nil, file, c.lineHits(probes), err)
+	}
+	// A halted run still reports the hits so far, with the error saying where it was halted
+	return c.createLineCoverageResponse(delveState, file, c.lineHits(probes), err)
+}
+
+// setLineProbes sets a tracepoint on every distinct line, in line order. A line without
+// code, or one whose breakpoint can't be set, keeps the error.
+func (c *Client) setLineProbes(file string, lines []int) []*lineProbe {
+	sorted := append([]int(nil), lines...)
+	sort.Ints(sorted)
+
+	var probes []*lineProbe
+	for i, line := range sorted {
+		if i > 0 && line == sorted[i-1] {
+			continue
+		}
+		p := &lineProbe{line: line}
+		probes = append(probes, p)
+
+		bp, err := c.client.CreateBreakpoint(&api.Breakpoint{File: file, Line: line, Tracepoint: true})
+		if err == nil {
+			p.bp, p.owned = bp, true
+			if c.coverageBreakpoints == nil {
+				c.coverageBreakpoints = make(map[int]bool)
+			}
+			c.coverageBreakpoints[bp.ID] = true
+			continue
+		}
+		if !strings.Contains(err.Error(), "Breakpoint exists") {
+			p.err = err
+			continue
+		}
+		// The breakpoint already there counts the line's hits just as well
+		if p.bp = c.breakpointAtLine(file, line); p.bp == nil {
+			p.err = err
+			continue
+		}
+		p.baseline = p.bp
+	}
+	return probes
+}
+
+// breakpointAtLine returns the user breakpoint set at file:line, if any
+func (c *Client) breakpointAtLine(file string, line int) *api.Breakpoint {
+	bps, err := c.client.ListBreakpoints(false)
+	if err != nil {
+		logger.Debug("Warning: Failed to list breakpoints: %v", err)
+		return nil
+	}
+	for _, bp := range bps {
+		if bp.ID > 0 && bp.File == file && bp.Line == line {
+			return bp
+		}
+	}
+	return nil
+}
+
+// lineHits reads the hits every line got since its probe was set
+func (c *Client) lineHits(probes []*lineProbe) []types.LineHit {
+	hits := make([]types.LineHit, 0, len(probes))
+	for _, p := range probes {
+		hit := types.LineHit{Line: p.line}
+		if p.err != nil {
+			hit.Error = p.err.Error()
+			hits = append(hits, hit)
+			continue
+		}
+
+		bp, err := c.client.GetBreakpoint(p.bp.ID)
+		if err != nil {
+			hit.Error = fmt.Sprintf("failed to read the hits of breakpoint %d: %v", p.bp.ID, err)
+			hits = append(hits, hit)
+			continue
+		}
+		hit.Function = getFunctionNameFromBreakpoint(bp)
+		hit.HitCount, hit.Goroutines = hitsSince(p.baseline, bp)
+		hit.Hit = hit.HitCount > 0
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// hitsSince returns the hits a breakpoint got since baseline, which is nil for one set for
+// the run, and how many goroutines they were on
+func hitsSince(baseline, bp *api.Breakpoint) (uint64, int) {
+	total := bp.TotalHitCount
+	var goroutines int
+	for goroutineID, count := range bp.HitCount {
+		if baseline == nil || count > baseline.HitCount[goroutineID] {
+			goroutines++
+		}
+	}
+	if baseline != nil {
+		total -= baseline.TotalHitCount
+	}
+	return total, goroutines
+}
+
+// clearLineProbes removes the tracepoints set for a LineCoverage run
+func (c *Client) clearLineProbes(probes []*lineProbe) {
+	for _, p := range probes {
+		if !p.owned {
+			continue
+		}
+		if _, err := c.client.ClearBreakpoint(p.bp.ID); err != nil {
+			logger.Debug("Warning: Failed to clear coverage tracepoint %d at line %d: %v", p.bp.ID, p.line, err)
+		}
+		delete(c.coverageBreakpoints, p.bp.ID)
+		c.forgetBreakpoint(p.bp.ID)
+	}
+}
+
+// createLineCoverageResponse creates a LineCoverageResponse from the hits of every line
+func (c *Client) createLineCoverageResponse(state *api.DebuggerState, file string, lines []types.LineHit, err error) types.LineCoverageResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "line_coverage"
+
+	response := types.LineCoverageResponse{
+		Status:  "success",
+		Context: context,
+		File:    file,
+		Lines:   lines,
+	}
+	if state != nil {
+		response.Exited = state.Exited
+		response.ExitStatus = state.ExitStatus
+		if !state.Exited {
+			response.Location = getCurrentLocation(state)
+		}
+	}
+	if lines != nil {
+		response.Summary = summarizeLineHits(lines)
+		switch {
+		case response.Exited:
+			response.Summary += fmt.Sprintf("; the process exited with status %d", response.ExitStatus)
+		case response.Location != nil:
+			response.Summary += "; stopped at " + strings.TrimPrefix(*response.Location, "At ")
+		}
+	}
+
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+	}
+	return response
+}
+
+// summarizeLineHits describes which lines were hit and how often, e.g.
+// "2 of 3 lines hit: 12 (4 times), 15 (once); not hit: 20"
+func summarizeLineHits(lines []types.LineHit) string {
+	var hit, missed, untraced []string
+	for _, l := range lines {
+		switch {
+		case l.Error != "":
+			untraced = append(untraced, fmt.Sprint(l.Line))
+		case l.Hit && l.HitCount == 1:
+			hit = append(hit, fmt.Sprintf("%d (once)", l.Line))
+		case l.Hit:
+			hit = append(hit, fmt.Sprintf("%d (%d times)", l.Line, l.HitCount))
+		default:
+			missed = append(missed, fmt.Sprint(l.Line))
+		}
+	}
+
+	summary := fmt.Sprintf("%d of %d lines hit", len(hit), len(hit)+len(missed))
+	if len(hit) > 0 {
+		summary += ": " + strings.Join(hit, ", ")
+	}
+	if len(missed) > 0 {
+		summary += "; not hit: " + strings.Join(missed, ", ")
+	}
+	if len(untraced) > 0 {
+		summary += "; could not be traced: " + strings.Join(untraced, ", ")
+	}
+	return summary
+}
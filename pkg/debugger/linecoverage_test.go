@@ -0,0 +1,139 @@
+package debugger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestHitsSince(t *testing.T) {
+	testCases := []struct {
+		name               string
+		baseline           *api.Breakpoint
+		bp                 *api.Breakpoint
+		expectedHits       uint64
+		expectedGoroutines int
+	}{
+		{
+			name:               "Set for the run",
+			bp:                 &api.Breakpoint{TotalHitCount: 5, HitCount: map[string]uint64{"1": 3, "7": 2}},
+			expectedHits:       5,
+			expectedGoroutines: 2,
+		},
+		{
+			name:               "Existing breakpoint",
+			baseline:           &api.Breakpoint{TotalHitCount: 4, HitCount: map[string]uint64{"1": 4}},
+			bp:                 &api.Breakpoint{TotalHitCount: 6, HitCount: map[string]uint64{"1": 4, "9": 2}},
+			expectedHits:       2,
+			expectedGoroutines: 1,
+		},
+		{
+			name:     "Not hit",
+			baseline: &api.Breakpoint{TotalHitCount: 4, HitCount: map[string]uint64{"1": 4}},
+			bp:       &api.Breakpoint{TotalHitCount: 4, HitCount: map[string]uint64{"1": 4}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hits, goroutines := hitsSince(tc.baseline, tc.bp)
+			if hits != tc.expectedHits || goroutines != tc.expectedGoroutines {
+				t.Errorf("Expected %d hits on %d goroutines, got %d on %d", tc.expectedHits, tc.expectedGoroutines, hits, goroutines)
+			}
+		})
+	}
+}
+
+func TestSummarizeLineHits(t *testing.T) {
+	lines := []types.LineHit{
+		{Line: 7, Hit: true, HitCount: 3},
+		{Line: 9, Hit: true, HitCount: 1},
+		{Line: 11, Error: "could not find statement"},
+		{Line: 14},
+	}
+
+	expected := "2 of 3 lines hit: 7 (3 times), 9 (once); not hit: 14; could not be traced: 11"
+	if summary := summarizeLineHits(lines); summary != expected {
+		t.Errorf("Expected %q, got %q", expected, summary)
+	}
+}
+
+func TestLineCoverage(t *testing.T) {
+	bps := &fakeBreakpoints{functions: map[string]string{"main.go:10": "main.loop", "main.go:11": "main.loop", "main.go:12": "main.loop"}}
+	bps.add(&api.Breakpoint{ID: 1, File: "main.go", Line: 12, FunctionName: "main.loop", TotalHitCount: 5, HitCount: map[string]uint64{"1": 5}})
+	handlers := bps.serve(t, map[string]fakeHandler{"State": fakeState(stoppedState(5))})
+
+	// Delve refuses a second breakpoint on a line
+	create := handlers["CreateBreakpoint"]
+	handlers["CreateBreakpoint"] = func(raw json.RawMessage) (interface{}, error) {
+		var args rpc2.CreateBreakpointIn
+		decodeFakeArgs(t, raw, &args)
+		if atLine(bps, args.Breakpoint.File, args.Breakpoint.Line) != nil {
+			return nil, fmt.Errorf("Breakpoint exists at %s:%d", args.Breakpoint.File, args.Breakpoint.Line)
+		}
+		return create(raw)
+	}
+
+	// The run hits line 10 three times on two goroutines and line 12 twice, then exits
+	var commands []string
+	handlers["Command"] = fakeCommands(t, &commands, func(api.DebuggerCommand) api.DebuggerState {
+		line10, line12 := atLine(bps, "main.go", 10), atLine(bps, "main.go", 12)
+		line10.TotalHitCount, line10.HitCount = 3, map[string]uint64{"1": 2, "2": 1}
+		line12.TotalHitCount, line12.HitCount = 7, map[string]uint64{"1": 7}
+		bps.add(line10)
+		bps.add(line12)
+		return api.DebuggerState{Exited: true, ExitStatus: 0}
+	})
+	c, _ := newFakeDelve(t, handlers)
+
+	response := c.LineCoverage(context.Background(), "main.go", []int{12, 10, 13, 11, 10})
+	if response.Status != "success" || !response.Exited {
+		t.Fatalf("Expected the run to exit, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+
+	expected := []types.LineHit{
+		{Line: 10, Hit: true, HitCount: 3, Goroutines: 2, Function: "main.loop"},
+		{Line: 11, Function: "main.loop"},
+		{Line: 12, Hit: true, HitCount: 2, Goroutines: 1, Function: "main.loop"},
+		{Line: 13, Error: "could not find statement at main.go:13"},
+	}
+	if !reflect.DeepEqual(response.Lines, expected) {
+		t.Errorf("Expected lines %+v, got %+v", expected, response.Lines)
+	}
+	if !strings.HasSuffix(response.Summary, "; the process exited with status 0") {
+		t.Errorf("Expected the summary to report the exit, got %q", response.Summary)
+	}
+
+	// The tracepoints set for the run are removed, and the breakpoint that was there stays
+	if atLine(bps, "main.go", 10) != nil || atLine(bps, "main.go", 11) != nil || bps.get(1) == nil {
+		t.Errorf("Expected only breakpoint 1 left, got %+v", bps.bps)
+	}
+	if len(c.coverageBreakpoints) != 0 {
+		t.Errorf("Expected no coverage tracepoints left, got %v", c.coverageBreakpoints)
+	}
+
+	response = NewClient().LineCoverage(context.Background(), "main.go", []int{10})
+	if response.Status != "error" || response.Context.Operation != "line_coverage" || !strings.Contains(response.Context.ErrorMessage, "no active debug session") {
+		t.Errorf("Expected a no active debug session error, got %+v", response.Context)
+	}
+}
+
+// atLine returns a copy of the breakpoint of a fake Delve at file:line, if any
+func atLine(bps *fakeBreakpoints, file string, line int) *api.Breakpoint {
+	bps.mu.Lock()
+	var id int
+	for _, bp := range bps.bps {
+		if bp.File == file && bp.Line == line {
+			id = bp.ID
+		}
+	}
+	bps.mu.Unlock()
+	return bps.get(id)
+}
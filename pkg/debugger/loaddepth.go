@@ -0,0 +1,61 @@
+package debugger
+
+import (
+	"reflect"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// clampLoadDepth keeps the number of levels of nested fields, elements and pointees a
+// call loads between 0 and maxVariableDepth
+func clampLoadDepth(depth int) int {
+	if depth < 0 {
+		return 0
+	}
+	if depth > maxVariableDepth {
+		return maxVariableDepth
+	}
+	return depth
+}
+
+// shownKey identifies a value in memory. A struct shares its address with its first
+// field, so the type tells them apart.
+type shownKey struct {
+	addr uint64
+	typ  string
+}
+
+// shownValues remembers where each struct and array was rendered while a variable is
+// walked, so a pointer back to one of them, as in a cyclic list, refers to that path
+// instead of rendering the value again
+type shownValues map[shownKey]string
+
+// record notes that v is rendered at path, unless it was rendered before. Values whose
+// contents were not loaded are not shown, so a pointer to one still renders it.
+func (s shownValues) record(v *api.Variable, path string) {
+	if v.Addr == 0 || len(v.Children) == 0 || (v.Kind != reflect.Struct && v.Kind != reflect.Array) {
+		return
+	}
+	key := shownKey{addr: v.Addr, typ: v.Type}
+	if _, ok := s[key]; !ok {
+		s[key] = path
+	}
+}
+
+// shownAt returns the path a pointer's target was already rendered at, if it was
+func (s shownValues) shownAt(pointee *api.Variable) (string, bool) {
+	if pointee.Addr == 0 {
+		return "", false
+	}
+	path, ok := s[shownKey{addr: pointee.Addr, typ: pointee.Type}]
+	return path, ok
+}
+
+// fieldPath returns the path of a struct field, element or map entry below parent. label
+// is the field name, or an index or key in brackets.
+func fieldPath(parent, label string) string {
+	if parent == "" || (len(label) > 0 && label[0] == '[') {
+		return parent + label
+	}
+	return parent + "." + label
+}
@@ -0,0 +1,112 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestClampLoadDepth(t *testing.T) {
+	testCases := []struct {
+		name     string
+		depth    int
+		expected int
+	}{
+		{name: "Within range", depth: 3, expected: 3},
+		{name: "Top level only", depth: 0, expected: 0},
+		{name: "Negative", depth: -2, expected: 0},
+		{name: "Above the ceiling", depth: 50, expected: maxVariableDepth},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := clampLoadDepth(tc.depth); result != tc.expected {
+				t.Errorf("Expected %d, got %d", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestFieldPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		parent   string
+		label    string
+		expected string
+	}{
+		{name: "Field", parent: "req", label: "Header", expected: "req.Header"},
+		{name: "Index", parent: "items", label: "[2]", expected: "items[2]"},
+		{name: "Map key", parent: "m", label: `["a"]`, expected: `m["a"]`},
+		{name: "Unnamed root", parent: "", label: "next", expected: "next"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := fieldPath(tc.parent, tc.label); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+// cyclicList returns a two-node list in which the second node points back to the first
+func cyclicList() *api.Variable {
+	first := &api.Variable{Name: "head", Type: "main.Node", Kind: reflect.Struct, Addr: 0x100, Len: 2}
+	second := api.Variable{Type: "main.Node", Kind: reflect.Struct, Addr: 0x200, Len: 2, Children: []api.Variable{
+		{Name: "Val", Type: "int", Kind: reflect.Int, Value: "2"},
+		{Name: "Next", Type: "*main.Node", Kind: reflect.Ptr, Children: []api.Variable{
+			{Type: "main.Node", Kind: reflect.Struct, Addr: 0x100, Len: 2, Children: []api.Variable{
+				{Name: "Val", Type: "int", Kind: reflect.Int, Value: "1"},
+				{Name: "Next", Type: "*main.Node", Kind: reflect.Ptr},
+			}},
+		}},
+	}}
+	first.Children = []api.Variable{
+		{Name: "Val", Type: "int", Kind: reflect.Int, Value: "1"},
+		{Name: "Next", Type: "*main.Node", Kind: reflect.Ptr, Children: []api.Variable{second}},
+	}
+	return first
+}
+
+func TestRenderVariableTreeCycle(t *testing.T) {
+	expected := "head main.Node\n" +
+		"  Val int = 1\n" +
+		"  Next *main.Node = 0x200\n" +
+		"    * main.Node\n" +
+		"      Val int = 2\n" +
+		"      Next *main.Node = 0x100 (already shown at head)"
+
+	if tree := renderVariableTree(cyclicList()); tree != expected {
+		t.Errorf("Expected tree:\n%s\ngot:\n%s", expected, tree)
+	}
+}
+
+func TestConvertVariableTreeCycle(t *testing.T) {
+	v := convertVariableTree(cyclicList(), "local", 3)
+
+	if len(v.Children) != 2 || len(v.Children[1].Children) != 2 {
+		t.Fatalf("Expected head and its next node to be expanded, got %+v", v)
+	}
+	back := v.Children[1].Children[1]
+	if back.Value != "0x100 (already shown at head)" {
+		t.Errorf("Expected the pointer back to head to refer to it, got %q", back.Value)
+	}
+	if len(back.Children) != 0 {
+		t.Errorf("Expected the pointer back to head not to be expanded, got %d children", len(back.Children))
+	}
+}
+
+func TestShownValuesFirstField(t *testing.T) {
+	shown := make(shownValues)
+	outer := &api.Variable{Type: "main.Outer", Kind: reflect.Struct, Addr: 0x100, Children: []api.Variable{{Name: "Inner"}}}
+	shown.record(outer, "o")
+
+	// The first field shares the struct's address but is a different value
+	if _, ok := shown.shownAt(&api.Variable{Type: "main.Inner", Kind: reflect.Struct, Addr: 0x100}); ok {
+		t.Error("Expected a value of another type at the same address not to count as shown")
+	}
+	if path, ok := shown.shownAt(&api.Variable{Type: "main.Outer", Kind: reflect.Struct, Addr: 0x100}); !ok || path != "o" {
+		t.Errorf("Expected the struct to be shown at o, got %q, %v", path, ok)
+	}
+}
@@ -0,0 +1,125 @@
+package debugger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// ambiguousLocationPattern matches the error Delve returns when a spec matches several
+// functions or files, e.g. `Location "Serve" ambiguous: a.Serve, b.Serve…`
+var ambiguousLocationPattern = regexp.MustCompile(`^Location ".*" ambiguous: (.*?)…?$`)
+
+// ParseLocation resolves a location spec to a single source position. It accepts
+// file:line (webserver.go:20), package.function (main.helloHandler) and a bare line
+// number in the file the selected goroutine is stopped in. When the spec matches more
+// than one location, such as a method defined on several types, the candidates are
+// returned along with an error so one of them can be picked.
+func (c *Client) ParseLocation(spec string) (*types.SourcePosition, []types.SourcePosition, error) {
+	if c.client == nil {
+		return nil, nil, fmt.Errorf("no active debug session")
+	}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil, fmt.Errorf("location must be file:line, package.function or a line number in the current file")
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get state: %v", err)
+	}
+
+	scope := api.EvalScope{GoroutineID: -1}
+	if !state.Running && state.SelectedGoroutine != nil {
+		scope.GoroutineID = state.SelectedGoroutine.ID
+	}
+
+	if line, err := strconv.Atoi(spec); err == nil {
+		// Delve resolves bare lines against the current frame; doing it here gives a clearer
+		// error when there is none
+		pos := getCurrentPosition(state)
+		if state.Running || pos == nil {
+			return nil, nil, fmt.Errorf("cannot resolve line %d: the program is not stopped in a source file; use file:line instead", line)
+		}
+		spec = fmt.Sprintf("%s:%d", pos.File, line)
+	}
+
+	logger.Debug("Resolving location %q", spec)
+
+	locs, _, err := c.client.FindLocation(scope, spec, false, nil)
+	if err != nil {
+		if names := parseAmbiguousCandidates(err.Error()); names != nil {
+			candidates := c.resolveCandidates(scope, names)
+			return nil, candidates, fmt.Errorf("location %q is ambiguous: it matches %s; use one of the candidates", spec, strings.Join(names, ", "))
+		}
+		return nil, nil, fmt.Errorf("cannot resolve location %q: %v", spec, err)
+	}
+
+	positions := distinctPositions(locs)
+	switch len(positions) {
+	case 0:
+		return nil, nil, fmt.Errorf("no code at location %q", spec)
+	case 1:
+		return &positions[0], nil, nil
+	default:
+		return nil, positions, fmt.Errorf("location %q is ambiguous: it matches %d locations; use one of the candidates", spec, len(positions))
+	}
+}
+
+// resolveCandidates looks up where each candidate of an ambiguous spec is, leaving out
+// ones that can't be resolved on their own
+func (c *Client) resolveCandidates(scope api.EvalScope, names []string) []types.SourcePosition {
+	var candidates []types.SourcePosition
+	for _, name := range names {
+		locs, _, err := c.client.FindLocation(scope, name, false, nil)
+		if err != nil {
+			logger.Debug("Warning: Failed to resolve location candidate %s: %v", name, err)
+			continue
+		}
+		candidates = append(candidates, distinctPositions(locs)...)
+	}
+	return candidates
+}
+
+// parseAmbiguousCandidates extracts the candidates from Delve's error for an ambiguous
+// location spec, or returns nil for any other error
+func parseAmbiguousCandidates(msg string) []string {
+	m := ambiguousLocationPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+
+	var names []string
+	for _, name := range strings.Split(m[1], ", ") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// distinctPositions converts locations to source positions, dropping locations at the
+// same file and line, such as the instantiations of a generic function
+func distinctPositions(locs []api.Location) []types.SourcePosition {
+	var positions []types.SourcePosition
+	seen := make(map[string]bool)
+	for _, loc := range locs {
+		key := fmt.Sprintf("%s:%d", loc.File, loc.Line)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		positions = append(positions, types.SourcePosition{
+			File:     loc.File,
+			Line:     loc.Line,
+			Function: getFunctionNameFromLocation(loc),
+		})
+	}
+	return positions
+}
@@ -0,0 +1,61 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestParseAmbiguousCandidates(t *testing.T) {
+	testCases := []struct {
+		name     string
+		msg      string
+		expected []string
+	}{
+		{
+			name:     "Methods on several types",
+			msg:      `Location "Serve" ambiguous: main.(*A).Serve, main.(*B).Serve…`,
+			expected: []string{"main.(*A).Serve", "main.(*B).Serve"},
+		},
+		{
+			name:     "Without ellipsis",
+			msg:      `Location "util.go" ambiguous: a/util.go, b/util.go`,
+			expected: []string{"a/util.go", "b/util.go"},
+		},
+		{
+			name:     "Other error",
+			msg:      `location "nope" not found`,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := parseAmbiguousCandidates(tc.msg)
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestDistinctPositions(t *testing.T) {
+	fn := &api.Function{Name_: "main.Map[...]"}
+	locs := []api.Location{
+		{PC: 0x10, File: "/src/main.go", Line: 12, Function: fn},
+		{PC: 0x20, File: "/src/main.go", Line: 12, Function: fn},
+		{PC: 0x30, File: "/src/other.go", Line: 3},
+	}
+
+	expected := []types.SourcePosition{
+		{File: "/src/main.go", Line: 12, Function: "main.Map[...]"},
+		{File: "/src/other.go", Line: 3, Function: "unknown"},
+	}
+
+	result := distinctPositions(locs)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
@@ -0,0 +1,186 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxExamineLength caps how many bytes a single ExamineMemory call may read
+const maxExamineLength = 4096
+
+// memoryRowWidth is the number of bytes shown per row of a memory dump
+const memoryRowWidth = 16
+
+// Formats accepted by ExamineMemory
+const (
+	MemoryFormatHex   = "hex"
+	MemoryFormatASCII = "ascii"
+	MemoryFormatBoth  = "both"
+)
+
+// ExamineMemory reads length bytes at addr and renders them as hex, ASCII, or both side
+// by side like a hex editor
+func (c *Client) ExamineMemory(addr uint64, length int, format string) types.MemoryResponse {
+	if c.client == nil {
+		return c.createMemoryResponse(nil, addr, format, nil, fmt.Errorf("no active debug session"))
+	}
+
+	if format == "" {
+		format = MemoryFormatBoth
+	}
+	if format != MemoryFormatHex && format != MemoryFormatASCII && format != MemoryFormatBoth {
+		return c.createMemoryResponse(nil, addr, format, nil, fmt.Errorf("unknown format %q, expected %s, %s or %s", format, MemoryFormatHex, MemoryFormatASCII, MemoryFormatBoth))
+	}
+	if length <= 0 {
+		return c.createMemoryResponse(nil, addr, format, nil, fmt.Errorf("length must be positive"))
+	}
+	if length > maxExamineLength {
+		return c.createMemoryResponse(nil, addr, format, nil, fmt.Errorf("length must not exceed %d bytes", maxExamineLength))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createMemoryResponse(nil, addr, format, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createMemoryResponse(nil, addr, format, nil, fmt.Errorf("cannot read memory while the target is running; stop the target first"))
+	}
+
+	logger.Debug("Reading %d bytes at %#x", length, addr)
+	mem, _, err := c.client.ExamineMemory(addr, length)
+	if err != nil {
+		return c.createMemoryResponse(state, addr, format, nil, fmt.Errorf("cannot read %d bytes at %#x, the range is unmapped or unreadable: %v", length, addr, err))
+	}
+
+	return c.createMemoryResponse(state, addr, format, mem, nil)
+}
+
+// EvalAddress evaluates an expression to the address it refers to: the target of a
+// pointer, the backing array of a slice or string, or else the variable's own address
+func (c *Client) EvalAddress(expr string, frame int) (uint64, error) {
+	if c.client == nil {
+		return 0, fmt.Errorf("no active debug session")
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get state: %v", err)
+	}
+	if state.Running {
+		return 0, fmt.Errorf("cannot evaluate expressions while the target is running; stop the target first")
+	}
+	if state.SelectedGoroutine == nil {
+		return 0, fmt.Errorf("no goroutine selected")
+	}
+
+	scope := api.EvalScope{
+		GoroutineID: state.SelectedGoroutine.ID,
+		Frame:       frame,
+	}
+
+	v, err := c.client.EvalVariable(scope, expr, api.LoadConfig{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to evaluate %q: %v", expr, err)
+	}
+
+	switch v.Kind {
+	case reflect.Ptr, reflect.UnsafePointer:
+		if len(v.Children) == 0 || v.Children[0].Addr == 0 {
+			return 0, fmt.Errorf("%q is a nil pointer", expr)
+		}
+		return v.Children[0].Addr, nil
+	case reflect.Slice, reflect.String:
+		if v.Base == 0 {
+			return 0, fmt.Errorf("%q has no backing array", expr)
+		}
+		return v.Base, nil
+	case reflect.Uintptr:
+		addr, err := strconv.ParseUint(v.Value, 0, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse %q as an address: %v", v.Value, err)
+		}
+		return addr, nil
+	}
+
+	if v.Addr == 0 {
+		return 0, fmt.Errorf("%q has no address in memory", expr)
+	}
+	return v.Addr, nil
+}
+
+// formatMemoryDump renders bytes in rows of memoryRowWidth, each labelled with its
+// offset from the start of the dump
+func formatMemoryDump(mem []byte, format string) string {
+	var b strings.Builder
+	for offset := 0; offset < len(mem); offset += memoryRowWidth {
+		end := offset + memoryRowWidth
+		if end > len(mem) {
+			end = len(mem)
+		}
+		row := mem[offset:end]
+
+		var line strings.Builder
+		fmt.Fprintf(&line, "%08x ", offset)
+		if format != MemoryFormatASCII {
+			for i := 0; i < memoryRowWidth; i++ {
+				if i == memoryRowWidth/2 {
+					line.WriteByte(' ')
+				}
+				if i < len(row) {
+					fmt.Fprintf(&line, " %02x", row[i])
+				} else {
+					line.WriteString("   ")
+				}
+			}
+		}
+		if format != MemoryFormatHex {
+			if format == MemoryFormatBoth {
+				line.WriteByte(' ')
+			}
+			line.WriteString(" |")
+			for _, c := range row {
+				if c >= 0x20 && c < 0x7f {
+					line.WriteByte(c)
+				} else {
+					line.WriteByte('.')
+				}
+			}
+			line.WriteByte('|')
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// createMemoryResponse creates a MemoryResponse
+func (c *Client) createMemoryResponse(state *api.DebuggerState, addr uint64, format string, mem []byte, err error) types.MemoryResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "examine_memory"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.MemoryResponse{
+			Status:  "error",
+			Context: context,
+			Address: fmt.Sprintf("%#x", addr),
+			Format:  format,
+		}
+	}
+
+	return types.MemoryResponse{
+		Status:  "success",
+		Context: context,
+		Address: fmt.Sprintf("%#x", addr),
+		Length:  len(mem),
+		Format:  format,
+		Dump:    formatMemoryDump(mem, format),
+	}
+}
@@ -0,0 +1,40 @@
+package debugger
+
+import "testing"
+
+func TestFormatMemoryDump(t *testing.T) {
+	mem := []byte("Hello, memory!\x00\x01GET /")
+
+	testCases := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{
+			name:   "hex",
+			format: MemoryFormatHex,
+			expected: "00000000  48 65 6c 6c 6f 2c 20 6d  65 6d 6f 72 79 21 00 01\n" +
+				"00000010  47 45 54 20 2f\n",
+		},
+		{
+			name:   "ascii",
+			format: MemoryFormatASCII,
+			expected: "00000000  |Hello, memory!..|\n" +
+				"00000010  |GET /|\n",
+		},
+		{
+			name:   "both",
+			format: MemoryFormatBoth,
+			expected: "00000000  48 65 6c 6c 6f 2c 20 6d  65 6d 6f 72 79 21 00 01  |Hello, memory!..|\n" +
+				"00000010  47 45 54 20 2f                                    |GET /|\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if dump := formatMemoryDump(mem, tc.format); dump != tc.expected {
+				t.Errorf("Expected dump:\n%s\ngot:\n%s", tc.expected, dump)
+			}
+		})
+	}
+}
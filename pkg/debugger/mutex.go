@@ -0,0 +1,406 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// Bits of the state word of a sync.Mutex, which every Go version since 1.9 shares. The
+// count of goroutines waiting to lock takes the bits above them.
+const (
+	mutexLocked      = 1 << iota // Held
+	mutexWoken                   // A waiter has been woken to try to lock
+	mutexStarving                // Handed straight to waiters, oldest first
+	mutexWaiterShift = iota
+)
+
+// rwmutexMaxReaders is what a writer takes off a sync.RWMutex's reader count to keep out
+// new readers
+const rwmutexMaxReaders = 1 << 30
+
+// maxMutexGoroutines caps the goroutines whose stacks are searched for the mutex
+const maxMutexGoroutines = 1000
+
+// States of an inspected mutex
+const (
+	mutexUnlocked      = "unlocked"
+	mutexHeld          = "locked"
+	mutexReadLocked    = "read locked"   // RWMutex held by readers only
+	mutexWriteLocked   = "write locked"  // RWMutex held by a writer
+	mutexWritePending  = "write pending" // RWMutex held by readers a writer waits for
+	mutexUnknownLayout = "unknown lock layout"
+)
+
+// mutexLoadConfig loads a sync.RWMutex down to the state word of the Mutex inside it
+var mutexLoadConfig = api.LoadConfig{
+	MaxVariableRecurse: 3,
+	MaxStructFields:    -1,
+}
+
+// lockOperations maps the kinds of blocking of classifyBlockingFrames that wait for a mutex
+// to the operation waited for
+var lockOperations = map[string]string{
+	"mutex":         "lock",
+	"rwmutex lock":  "lock",
+	"rwmutex rlock": "rlock",
+}
+
+// mutexInfo is what was decoded of a mutex
+type mutexInfo struct {
+	v       *api.Variable // The mutex
+	layout  string
+	state   string
+	word    int64 // Mutex state word
+	readers int64 // Read locks held, for a RWMutex
+	blocked []types.MutexGoroutine
+	holders []types.MutexGoroutine
+	notes   []string
+}
+
+// InspectMutex evaluates a sync.Mutex or sync.RWMutex expression in the given frame and
+// decodes its state word: whether it is locked, in starvation mode, and how many goroutines
+// wait for it. The fields it is read from differ between Go versions; a layout not known
+// here is reported as such rather than guessed at. Go does not record which goroutine holds
+// a lock, so the stacks of the other goroutines are searched instead: those blocked locking
+// it are its waiters, and those whose frames reference it are its likely holders.
+func (c *Client) InspectMutex(expr string, frame int) types.InspectMutexResponse {
+	if c.client == nil {
+		return c.createInspectMutexResponse(nil, expr, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createInspectMutexResponse(nil, expr, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createInspectMutexResponse(nil, expr, nil, fmt.Errorf("cannot inspect mutexes while the target is running; stop the target first"))
+	}
+
+	scope, err := c.frameScope(state, frame)
+	if err != nil {
+		return c.createInspectMutexResponse(state, expr, nil, err)
+	}
+
+	logger.Debug("Inspecting mutex %q in frame %d", expr, frame)
+	v, err := c.client.EvalVariable(scope, expr, mutexLoadConfig)
+	if err == nil && v != nil && v.Kind == reflect.Ptr {
+		if len(v.Children) == 0 || v.Children[0].Addr == 0 {
+			return c.createInspectMutexResponse(state, expr, nil, fmt.Errorf("%q is a nil %s", expr, v.Type))
+		}
+		v, err = c.client.EvalVariable(scope, fmt.Sprintf("*(%s)", expr), mutexLoadConfig)
+	}
+	if err != nil {
+		if isUnresolvedSymbol(err) {
+			return c.createInspectMutexResponse(state, expr, nil, fmt.Errorf("could not resolve %q: %v; variables in scope: %s", expr, err, c.scopeVariableNames(scope)))
+		}
+		return c.createInspectMutexResponse(state, expr, nil, fmt.Errorf("failed to evaluate %q: %v", expr, err))
+	}
+	if v == nil {
+		return c.createInspectMutexResponse(state, expr, nil, fmt.Errorf("expression %q produced no value", expr))
+	}
+	if v.Type != "sync.Mutex" && v.Type != "sync.RWMutex" {
+		return c.createInspectMutexResponse(state, expr, nil, fmt.Errorf("%q is a %s, not a sync.Mutex or sync.RWMutex; give the mutex itself, such as s.mu", expr, v.Type))
+	}
+
+	info := decodeMutex(v)
+	c.findMutexGoroutines(info)
+	return c.createInspectMutexResponse(state, expr, info, nil)
+}
+
+// decodeMutex decodes the state of a sync.Mutex or sync.RWMutex
+func decodeMutex(v *api.Variable) *mutexInfo {
+	info := &mutexInfo{v: v, state: mutexUnknownLayout}
+
+	m := v
+	if v.Type == "sync.RWMutex" {
+		m = channelField(v, "w")
+	}
+	word, layout, ok := mutexStateWord(m)
+	if !ok {
+		info.layout = mutexUnknownLayout
+		info.notes = append(info.notes, fmt.Sprintf("the fields of this Go version's %s are not known here, so its state can't be decoded", v.Type))
+		return info
+	}
+	info.word, info.layout = word, layout
+	info.state = mutexUnlocked
+	if word&mutexLocked != 0 {
+		info.state = mutexHeld
+	}
+	if v.Type != "sync.RWMutex" {
+		return info
+	}
+
+	readerCount, countOK := atomicInt32Field(v, "readerCount")
+	readerWait, waitOK := atomicInt32Field(v, "readerWait")
+	if !countOK || !waitOK {
+		info.state = mutexUnknownLayout
+		info.layout = mutexUnknownLayout
+		info.notes = append(info.notes, "the reader counts of this Go version's sync.RWMutex are not known here, so its state can't be decoded")
+		return info
+	}
+
+	// A writer that locked w takes rwmutexMaxReaders off the reader count, so readers that
+	// come after it block, then waits for the readers counted in readerWait to unlock
+	switch {
+	case readerCount < 0 && readerWait > 0:
+		info.readers = readerWait
+		info.state = mutexWritePending
+	case readerCount < 0:
+		info.state = mutexWriteLocked
+	case readerCount > 0:
+		info.readers = readerCount
+		info.state = mutexReadLocked
+	default:
+		info.state = mutexUnlocked
+	}
+	return info
+}
+
+// mutexStateWord returns the state word of a sync.Mutex and the layout it was found in:
+// the fields of the Mutex itself up to Go 1.23, those of the internal/sync.Mutex it wraps
+// from Go 1.24
+func mutexStateWord(m *api.Variable) (int64, string, bool) {
+	if m == nil {
+		return 0, "", false
+	}
+	if inner := channelField(m, "mu"); inner != nil && inner.Type == "internal/sync.Mutex" {
+		word, _, ok := mutexStateWord(inner)
+		return word, "sync.Mutex{mu internal/sync.Mutex{state int32; sema uint32}}", ok
+	}
+	state, sema := channelField(m, "state"), channelField(m, "sema")
+	if state == nil || sema == nil || !isSignedInt(state.Kind) {
+		return 0, "", false
+	}
+	word, err := strconv.ParseInt(state.Value, 10, 32)
+	if err != nil {
+		return 0, "", false
+	}
+	return word, "sync.Mutex{state int32; sema uint32}", true
+}
+
+// atomicInt32Field returns an int32 counter of a sync.RWMutex: a plain int32 up to Go 1.19,
+// an atomic.Int32 from Go 1.20
+func atomicInt32Field(v *api.Variable, name string) (int64, bool) {
+	field := channelField(v, name)
+	if field != nil && field.Type == "sync/atomic.Int32" {
+		field = channelField(field, "v")
+	}
+	if field == nil || !isSignedInt(field.Kind) {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(field.Value, 10, 32)
+	return n, err == nil
+}
+
+// isSignedInt reports whether a kind is a signed integer. Delve gives some int32 values the
+// kind of int.
+func isSignedInt(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+// findMutexGoroutines searches the stacks of the goroutines for the ones blocked locking the
+// mutex and the ones whose frames reference it, which likely hold it. Nothing holds an
+// unlocked mutex, so holders are only looked for while it is locked, or when the state is
+// unknown.
+func (c *Client) findMutexGoroutines(info *mutexInfo) {
+	addr := info.v.Addr
+	if addr == 0 {
+		info.notes = append(info.notes, "the mutex has no address, so the goroutines using it can't be found")
+		return
+	}
+
+	gs, next, err := c.client.ListGoroutines(0, maxMutexGoroutines)
+	if err != nil {
+		info.notes = append(info.notes, fmt.Sprintf("the goroutines could not be listed: %v", err))
+		return
+	}
+	if next > 0 {
+		info.notes = append(info.notes, fmt.Sprintf("only the first %d goroutines were searched", maxMutexGoroutines))
+	}
+
+	// Arguments are needed to find the mutex a goroutine is blocked on, locals to find what
+	// each goroutine references
+	cfg := &api.LoadConfig{
+		FollowPointers:     true,
+		MaxVariableRecurse: 2,
+		MaxStructFields:    -1,
+	}
+	wantHolders := info.state != mutexUnlocked
+	for _, g := range gs {
+		frames, err := c.client.Stacktrace(g.ID, deadlockStackDepth, api.StacktraceReadDefers, cfg)
+		if err != nil {
+			logger.Debug("Warning: Failed to get stack trace for goroutine %d: %v", g.ID, err)
+			continue
+		}
+
+		kind, blockedOn, index := classifyBlockingFrames(frames)
+		if operation, ok := lockOperations[kind]; ok && blockedOn == addr {
+			waiter := types.MutexGoroutine{Goroutine: c.convertGoroutine(g), Operation: operation, Location: getGoroutineLocation(g)}
+			if index+1 < len(frames) {
+				waiter.Location = getFrameLocation(frames[index+1])
+			}
+			info.blocked = append(info.blocked, waiter)
+			continue
+		}
+		if !wantHolders {
+			continue
+		}
+		if i, unlock := holdingFrame(frames[index+1:], info.v); i >= 0 {
+			holder := types.MutexGoroutine{Goroutine: c.convertGoroutine(g), Location: getFrameLocation(frames[index+1+i]), DeferredUnlock: unlock}
+			info.holders = append(info.holders, holder)
+		}
+	}
+
+	// A pending deferred unlock is the strongest sign of holding the mutex
+	sort.SliceStable(info.holders, func(i, j int) bool {
+		return info.holders[i].DeferredUnlock != "" && info.holders[j].DeferredUnlock == ""
+	})
+}
+
+// holdingFrame returns the index of the frame that most likely holds the mutex m: the
+// innermost frame that references it and has deferred a call that unlocks a mutex of its
+// type, or else the innermost frame that references it, -1 when none does. The deferred
+// unlock is returned too. Delve lists no defers the compiler open-coded, which it does for
+// most in optimized builds.
+func holdingFrame(frames []api.Stackframe, m *api.Variable) (int, string) {
+	referencing := -1
+	for i, frame := range frames {
+		if !frameReferencesMutex(frame, m) {
+			continue
+		}
+		if unlock := deferredUnlock(frame, m.Type); unlock != "" {
+			return i, unlock
+		}
+		if referencing < 0 {
+			referencing = i
+		}
+	}
+	return referencing, ""
+}
+
+// frameReferencesMutex reports whether the arguments or locals of a frame reference m
+func frameReferencesMutex(frame api.Stackframe, m *api.Variable) bool {
+	for _, vars := range [][]api.Variable{frame.Arguments, frame.Locals} {
+		for i := range vars {
+			if referencesMutex(&vars[i], m, 3) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deferredUnlock returns the first call a frame deferred that unlocks a mutex of the given
+// type, or "" when there is none
+func deferredUnlock(frame api.Stackframe, mutexType string) string {
+	for _, d := range frame.Defers {
+		if d.DeferredLoc.Function == nil {
+			continue
+		}
+		function := d.DeferredLoc.Function.Name()
+		switch {
+		case mutexType == "sync.Mutex" && function == "sync.(*Mutex).Unlock",
+			mutexType == "sync.RWMutex" && (function == "sync.(*RWMutex).Unlock" || function == "sync.(*RWMutex).RUnlock"):
+			return function
+		}
+	}
+	return ""
+}
+
+// referencesMutex reports whether v is the mutex m, points to it, or has it among its
+// loaded fields and elements. The type is compared along with the address, since a struct
+// has the address of its first field.
+func referencesMutex(v, m *api.Variable, depth int) bool {
+	if v.Addr == m.Addr && v.Type == m.Type {
+		return true
+	}
+	if depth <= 0 {
+		return false
+	}
+	for i := range v.Children {
+		if referencesMutex(&v.Children[i], m, depth-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// mutexSummary describes a mutex's state in human terms
+func mutexSummary(response *types.InspectMutexResponse) string {
+	var summary string
+	switch response.State {
+	case mutexUnknownLayout:
+		summary = fmt.Sprintf("%s in an unknown lock layout", response.Type)
+	case mutexReadLocked:
+		summary = fmt.Sprintf("%s read locked by %d readers", response.Type, response.Readers)
+	case mutexWritePending:
+		summary = fmt.Sprintf("%s read locked by %d readers, with a writer waiting for them to unlock", response.Type, response.Readers)
+	default:
+		summary = fmt.Sprintf("%s %s", response.Type, response.State)
+	}
+	if response.Starving {
+		summary += ", in starvation mode"
+	}
+	summary += fmt.Sprintf(", %d goroutines blocked on it", len(response.Blocked))
+
+	if len(response.LikelyHolders) > 0 {
+		if top := response.LikelyHolders[0]; top.DeferredUnlock != "" {
+			summary += fmt.Sprintf("; likely held by goroutine %d, which has %s deferred", top.Goroutine.ID, top.DeferredUnlock)
+			if top.Location != nil {
+				summary += " at " + strings.TrimPrefix(*top.Location, "At ")
+			}
+			return summary
+		}
+		ids := make([]string, 0, len(response.LikelyHolders))
+		for _, holder := range response.LikelyHolders {
+			ids = append(ids, strconv.FormatInt(holder.Goroutine.ID, 10))
+		}
+		summary += fmt.Sprintf("; likely held by goroutine %s, whose stack references it", strings.Join(ids, " or "))
+	} else if response.Locked {
+		summary += "; no other goroutine's stack references it, so the holder is not known"
+	}
+	return summary
+}
+
+// createInspectMutexResponse creates an InspectMutexResponse
+func (c *Client) createInspectMutexResponse(state *api.DebuggerState, expr string, info *mutexInfo, err error) types.InspectMutexResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "inspect_mutex"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.InspectMutexResponse{
+			Status:     "error",
+			Context:    context,
+			Expression: expr,
+		}
+	}
+
+	response := types.InspectMutexResponse{
+		Status:        "success",
+		Context:       context,
+		Expression:    expr,
+		Type:          info.v.Type,
+		Address:       fmt.Sprintf("%#x", info.v.Addr),
+		Layout:        info.layout,
+		State:         info.state,
+		Blocked:       info.blocked,
+		LikelyHolders: info.holders,
+		Notes:         info.notes,
+	}
+	if info.layout != mutexUnknownLayout {
+		response.Locked = info.state != mutexUnlocked
+		response.Starving = info.word&mutexStarving != 0
+		response.Woken = info.word&mutexWoken != 0
+		response.Waiters = info.word >> mutexWaiterShift
+		response.Readers = info.readers
+	}
+	response.Summary = mutexSummary(&response)
+	return response
+}
@@ -0,0 +1,226 @@
+package debugger
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+// mutexVar builds a sync.Mutex in the layout of Go 1.24 and later, or in the older one
+func mutexVar(state int64, wrapped bool) api.Variable {
+	fields := []api.Variable{
+		{Name: "state", Type: "int32", Kind: reflect.Int32, Value: strconv.FormatInt(state, 10)},
+		{Name: "sema", Type: "uint32", Kind: reflect.Uint32, Value: "0"},
+	}
+	if wrapped {
+		fields = []api.Variable{
+			{Name: "_", Type: "sync.noCopy", Kind: reflect.Struct},
+			{Name: "mu", Type: "internal/sync.Mutex", Kind: reflect.Struct, Children: fields},
+		}
+	}
+	return api.Variable{Name: "w", Addr: 0x1000, Type: "sync.Mutex", Kind: reflect.Struct, Children: fields}
+}
+
+// rwMutexVar builds a sync.RWMutex, with atomic.Int32 counters as since Go 1.20 or plain ones
+func rwMutexVar(state, readerCount, readerWait int64, atomicCounters bool) *api.Variable {
+	counter := func(name string, n int64) api.Variable {
+		v := api.Variable{Name: name, Type: "int32", Kind: reflect.Int, Value: strconv.FormatInt(n, 10)}
+		if !atomicCounters {
+			return v
+		}
+		v.Name = "v"
+		return api.Variable{Name: name, Type: "sync/atomic.Int32", Kind: reflect.Struct, Children: []api.Variable{{Name: "_"}, v}}
+	}
+	return &api.Variable{Addr: 0x1000, Type: "sync.RWMutex", Kind: reflect.Struct, Children: []api.Variable{
+		mutexVar(state, atomicCounters),
+		{Name: "writerSem", Kind: reflect.Uint32, Value: "0"},
+		{Name: "readerSem", Kind: reflect.Uint32, Value: "0"},
+		counter("readerCount", readerCount),
+		counter("readerWait", readerWait),
+	}}
+}
+
+func TestDecodeMutex(t *testing.T) {
+	wrapped, plain := mutexVar(mutexLocked|mutexStarving|2<<mutexWaiterShift, true), mutexVar(0, false)
+	unknown := api.Variable{Type: "sync.Mutex", Kind: reflect.Struct, Children: []api.Variable{{Name: "key", Kind: reflect.Uint32}}}
+
+	testCases := []struct {
+		name    string
+		mutex   *api.Variable
+		state   string
+		word    int64
+		readers int64
+	}{
+		{name: "Locked, Go 1.24 layout", mutex: &wrapped, state: mutexHeld, word: mutexLocked | mutexStarving | 2<<mutexWaiterShift},
+		{name: "Unlocked, older layout", mutex: &plain, state: mutexUnlocked},
+		{name: "Unknown layout", mutex: &unknown, state: mutexUnknownLayout},
+		{name: "Read locked", mutex: rwMutexVar(0, 3, 0, true), state: mutexReadLocked, readers: 3},
+		{name: "Write pending", mutex: rwMutexVar(mutexLocked, 2-rwmutexMaxReaders, 2, true), state: mutexWritePending, word: mutexLocked, readers: 2},
+		{name: "Write locked, plain counters", mutex: rwMutexVar(mutexLocked, -rwmutexMaxReaders, 0, false), state: mutexWriteLocked, word: mutexLocked},
+		{name: "RWMutex unlocked", mutex: rwMutexVar(0, 0, 0, true), state: mutexUnlocked},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := decodeMutex(tc.mutex)
+			if info.state != tc.state || info.word != tc.word || info.readers != tc.readers {
+				t.Errorf("Expected state %q, word %d and %d readers, got %q, %d and %d", tc.state, tc.word, tc.readers, info.state, info.word, info.readers)
+			}
+			if (info.layout == mutexUnknownLayout) != (tc.state == mutexUnknownLayout) {
+				t.Errorf("Unexpected layout %q for state %q", info.layout, info.state)
+			}
+		})
+	}
+}
+
+func TestHoldingFrame(t *testing.T) {
+	m := mutexVar(mutexLocked, true)
+	server := api.Variable{Name: "s", Type: "*main.server", Kind: reflect.Ptr, Children: []api.Variable{
+		{Addr: 0x1000, Type: "main.server", Kind: reflect.Struct, Children: []api.Variable{{Name: "mu", Addr: 0x1000, Type: "sync.Mutex", Kind: reflect.Struct}}},
+	}}
+	// A struct has the address of its first field, but is not the mutex
+	other := api.Variable{Name: "o", Type: "*main.other", Kind: reflect.Ptr, Children: []api.Variable{{Addr: 0x1000, Type: "main.other", Kind: reflect.Struct}}}
+	unlock := api.Defer{DeferredLoc: api.Location{Function: &api.Function{Name_: "sync.(*Mutex).Unlock"}}}
+	runlock := api.Defer{DeferredLoc: api.Location{Function: &api.Function{Name_: "sync.(*RWMutex).RUnlock"}}}
+
+	testCases := []struct {
+		name   string
+		frames []api.Stackframe
+		index  int
+		unlock string
+	}{
+		{name: "No reference", frames: []api.Stackframe{{Locals: []api.Variable{other}}}, index: -1},
+		{name: "Reference", frames: []api.Stackframe{{Locals: []api.Variable{other}}, {Arguments: []api.Variable{server}}}, index: 1},
+		{
+			name:   "Deferred unlock",
+			frames: []api.Stackframe{{Arguments: []api.Variable{server}}, {Arguments: []api.Variable{server}, Defers: []api.Defer{unlock}}},
+			index:  1,
+			unlock: "sync.(*Mutex).Unlock",
+		},
+		{name: "Unlock of another type", frames: []api.Stackframe{{Arguments: []api.Variable{server}, Defers: []api.Defer{runlock}}}, index: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			index, unlock := holdingFrame(tc.frames, &m)
+			if index != tc.index || unlock != tc.unlock {
+				t.Errorf("Expected frame %d with unlock %q, got %d with %q", tc.index, tc.unlock, index, unlock)
+			}
+		})
+	}
+}
+
+// mutexTarget returns a fake Delve stopped on goroutine 1 in main.handle, which holds the
+// locked mutex s.mu at 0x1000 with its unlock deferred. Goroutine 2 is blocked locking it
+// from main.worker, and goroutine 3 has nothing to do with it. The unlocked mutex idle,
+// the pointer p to s.mu, the nil pointer nilp and the int s.count can be evaluated too.
+func mutexTarget(t *testing.T) *Client {
+	t.Helper()
+	mu := mutexVar(mutexLocked|1<<mutexWaiterShift, true)
+	idle := mutexVar(0, true)
+	idle.Addr = 0x2000
+	server := api.Variable{Name: "s", Type: "*main.server", Kind: reflect.Ptr, Children: []api.Variable{
+		{Addr: 0x1000, Type: "main.server", Kind: reflect.Struct, Children: []api.Variable{{Name: "mu", Addr: 0x1000, Type: "sync.Mutex", Kind: reflect.Struct}}},
+	}}
+	mutexPtr := api.Variable{Name: "m", Type: "*sync.Mutex", Kind: reflect.Ptr, Children: []api.Variable{{Addr: 0x1000, Type: "sync.Mutex", Kind: reflect.Struct}}}
+	unlock := api.Defer{DeferredLoc: api.Location{Function: &api.Function{Name_: "sync.(*Mutex).Unlock"}}}
+
+	frame := func(file string, line int, function string) api.Stackframe {
+		return api.Stackframe{Location: api.Location{File: file, Line: line, Function: &api.Function{Name_: function}}}
+	}
+	handle := frame("main.go", 10, "main.handle")
+	handle.Arguments, handle.Defers = []api.Variable{server}, []api.Defer{unlock}
+	lock := frame("/usr/local/go/src/sync/mutex.go", 46, "sync.(*Mutex).Lock")
+	lock.Arguments = []api.Variable{mutexPtr}
+	stacks := map[int64][]api.Stackframe{
+		1: {handle, frame("main.go", 40, "main.main")},
+		2: {frame("/usr/local/go/src/runtime/proc.go", 435, "runtime.gopark"), lock, frame("main.go", 30, "main.worker")},
+		3: {frame("main.go", 50, "main.idle")},
+	}
+
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+		"Stacktrace": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.StacktraceIn
+			decodeFakeArgs(t, raw, &args)
+			return rpc2.StacktraceOut{Locations: stacks[args.Id]}, nil
+		},
+		"ListGoroutines": fakeResult(rpc2.ListGoroutinesOut{Goroutines: []*api.Goroutine{{ID: 1}, {ID: 2}, {ID: 3}}}),
+		"Eval": fakeEval(t, map[string]*api.Variable{
+			"s.mu":    &mu,
+			"idle":    &idle,
+			"p":       &mutexPtr,
+			"*(p)":    &mu,
+			"nilp":    {Name: "nilp", Type: "*sync.Mutex", Kind: reflect.Ptr, Children: []api.Variable{{Type: "sync.Mutex", Kind: reflect.Struct}}},
+			"s.count": {Name: "count", Type: "int", Kind: reflect.Int, Value: "3"},
+		}),
+	})
+	return c
+}
+
+func TestInspectMutex(t *testing.T) {
+	c := mutexTarget(t)
+
+	for _, expr := range []string{"s.mu", "p"} {
+		t.Run(expr, func(t *testing.T) {
+			response := c.InspectMutex(expr, 0)
+			if response.Status != "success" {
+				t.Fatalf("Expected the mutex inspected, got %s", response.Context.ErrorMessage)
+			}
+			if response.State != mutexHeld || !response.Locked || response.Waiters != 1 || response.Address != "0x1000" {
+				t.Errorf("Expected a locked mutex at 0x1000 with 1 waiter, got %+v", response)
+			}
+			if len(response.Blocked) != 1 || response.Blocked[0].Goroutine.ID != 2 || response.Blocked[0].Operation != "lock" {
+				t.Fatalf("Expected goroutine 2 blocked locking it, got %+v", response.Blocked)
+			}
+			if location := response.Blocked[0].Location; location == nil || !strings.Contains(*location, "main.go:30") {
+				t.Errorf("Expected the waiter at its lock call in main.go:30, got %v", location)
+			}
+			if len(response.LikelyHolders) != 1 || response.LikelyHolders[0].Goroutine.ID != 1 || response.LikelyHolders[0].DeferredUnlock != "sync.(*Mutex).Unlock" {
+				t.Errorf("Expected goroutine 1 to hold it with its unlock deferred, got %+v", response.LikelyHolders)
+			}
+			if !strings.Contains(response.Summary, "likely held by goroutine 1, which has sync.(*Mutex).Unlock deferred") {
+				t.Errorf("Expected the summary to name the holder, got %q", response.Summary)
+			}
+		})
+	}
+
+	// Nothing holds or waits for an unlocked mutex
+	response := c.InspectMutex("idle", 0)
+	if response.Status != "success" || response.Locked || len(response.Blocked) != 0 || len(response.LikelyHolders) != 0 {
+		t.Errorf("Expected an unlocked mutex without waiters or holders, got %+v", response)
+	}
+}
+
+func TestInspectMutexErrors(t *testing.T) {
+	c := mutexTarget(t)
+
+	testCases := []struct {
+		name     string
+		expr     string
+		expected string
+	}{
+		{name: "Nil pointer", expr: "nilp", expected: `"nilp" is a nil *sync.Mutex`},
+		{name: "Not a mutex", expr: "s.count", expected: "not a sync.Mutex or sync.RWMutex"},
+		{name: "Unresolved", expr: "missing", expected: "could not find symbol value for missing"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.InspectMutex(tc.expr, 0)
+			if response.Status != "error" || response.Context.Operation != "inspect_mutex" || !strings.Contains(response.Context.ErrorMessage, tc.expected) {
+				t.Errorf("Expected an error containing %q, got %+v", tc.expected, response.Context)
+			}
+		})
+	}
+
+	response := NewClient().InspectMutex("s.mu", 0)
+	if response.Status != "error" || response.Context.Operation != "inspect_mutex" {
+		t.Errorf("Expected an inspect_mutex error without a session, got %+v", response)
+	}
+}
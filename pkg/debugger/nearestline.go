@@ -0,0 +1,65 @@
+package debugger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+)
+
+// maxBreakpointLineShift caps how many lines past a line without code a breakpoint moves
+const maxBreakpointLineShift = 20
+
+// noStatementPattern matches the error Delve returns for a line without code, such as a
+// comment or blank line: "could not find statement at /src/main.go:12, please use a line
+// with a statement"
+var noStatementPattern = regexp.MustCompile(`could not find statement at (.+):(\d+), please use a line with a statement`)
+
+// parseNoStatement extracts the file, as Delve resolved it, and the line from the error
+// for a line without code, or returns ok false for any other error
+func parseNoStatement(msg string) (string, int, bool) {
+	m := noStatementPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return m[1], line, true
+}
+
+// nextCodeLine finds the first line after line in file that has code, looking at most
+// maxBreakpointLineShift lines ahead. It returns the file as Delve resolved it, since the
+// request may name it by a partial path.
+func (c *Client) nextCodeLine(file string, line int) (string, int, error) {
+	scope := api.EvalScope{GoroutineID: -1}
+	for next := line + 1; next <= line+maxBreakpointLineShift; next++ {
+		locs, _, err := c.client.FindLocation(scope, fmt.Sprintf("%s:%d", file, next), false, nil)
+		if err != nil {
+			if _, _, ok := parseNoStatement(err.Error()); ok {
+				continue
+			}
+			return "", 0, fmt.Errorf("failed to look for code after %s:%d: %v", file, line, err)
+		}
+		// Delve resolves a line without code too, but to a location with no instructions
+		for _, loc := range locs {
+			if len(loc.PCs) > 0 {
+				logger.Debug("Line %s:%d has no code, the next line with code is %d", file, line, loc.Line)
+				return loc.File, loc.Line, nil
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("%s:%d has no code, nor do the %d lines after it; pick a line with a statement", file, line, maxBreakpointLineShift)
+}
+
+// describeLineShift explains why a breakpoint is not on the line it was asked for
+func describeLineShift(requested, actual int, function string) string {
+	note := fmt.Sprintf("line %d has no code; the breakpoint was set at line %d, the next line with code", requested, actual)
+	if function != "" {
+		note += ", in " + function
+	}
+	return note
+}
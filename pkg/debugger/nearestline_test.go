@@ -0,0 +1,61 @@
+package debugger
+
+import "testing"
+
+func TestParseNoStatement(t *testing.T) {
+	testCases := []struct {
+		name         string
+		msg          string
+		expectedOk   bool
+		expectedFile string
+		expectedLine int
+	}{
+		{
+			name:         "Line without code",
+			msg:          "could not find statement at /src/app/main.go:12, please use a line with a statement",
+			expectedOk:   true,
+			expectedFile: "/src/app/main.go",
+			expectedLine: 12,
+		},
+		{
+			name:         "Wrapped",
+			msg:          `cannot resolve location "main.go:4": could not find statement at /src/app/main.go:4, please use a line with a statement`,
+			expectedOk:   true,
+			expectedFile: "/src/app/main.go",
+			expectedLine: 4,
+		},
+		{name: "Stripped binary", msg: "could not find statement at /src/app/main.go:12, binary is stripped", expectedOk: false},
+		{name: "Unknown file", msg: "could not find file main.go", expectedOk: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file, line, ok := parseNoStatement(tc.msg)
+			if ok != tc.expectedOk {
+				t.Fatalf("Expected ok %v, got %v", tc.expectedOk, ok)
+			}
+			if file != tc.expectedFile || line != tc.expectedLine {
+				t.Errorf("Expected %s:%d, got %s:%d", tc.expectedFile, tc.expectedLine, file, line)
+			}
+		})
+	}
+}
+
+func TestDescribeLineShift(t *testing.T) {
+	testCases := []struct {
+		name     string
+		function string
+		expected string
+	}{
+		{name: "With function", function: "main.main", expected: "line 9 has no code; the breakpoint was set at line 10, the next line with code, in main.main"},
+		{name: "Without function", expected: "line 9 has no code; the breakpoint was set at line 10, the next line with code"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if note := describeLineShift(9, 10, tc.function); note != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, note)
+			}
+		})
+	}
+}
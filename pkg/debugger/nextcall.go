@@ -0,0 +1,200 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+const (
+	defaultMaxCallSearchSteps = 1000   // Instructions stepped when the caller gives no limit
+	maxCallSearchSteps        = 100000 // Most instructions a search may step
+)
+
+// callMnemonics are the call instructions of the supported architectures: CALL on amd64,
+// BL and BLR on arm64
+var callMnemonics = map[string]bool{
+	"call": true,
+	"bl":   true,
+	"blr":  true,
+}
+
+// StepToNextCall single-steps the current thread until the instruction at its PC is a
+// call, and stops there before executing it, reporting the function it calls. A call at
+// the starting PC is stepped over first. At most maxSteps instructions are stepped, 0
+// meaning the default, so a loop without calls can't spin forever.
+func (c *Client) StepToNextCall(ctx context.Context, maxSteps int) types.StepToNextCallResponse {
+	var response types.StepToNextCallResponse
+
+	if c.client == nil {
+		return c.finishStepToNextCall(response, nil, fmt.Errorf("no active debug session"))
+	}
+
+	if maxSteps <= 0 {
+		maxSteps = defaultMaxCallSearchSteps
+	}
+	if maxSteps > maxCallSearchSteps {
+		maxSteps = maxCallSearchSteps
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.finishStepToNextCall(response, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.finishStepToNextCall(response, nil, fmt.Errorf("cannot step to the next call while the target is running; stop the target first"))
+	}
+	if state.CurrentThread == nil {
+		return c.finishStepToNextCall(response, state, fmt.Errorf("no current thread"))
+	}
+	response.FromPC = fmt.Sprintf("%#x", state.CurrentThread.PC)
+
+	logger.Debug("Stepping from %s to the next call, at most %d instructions", response.FromPC, maxSteps)
+	for response.Steps < maxSteps {
+		// Stepping over calls only matters for one at the starting PC, as the search
+		// stops before any other
+		nextState, err := c.interruptibleStep(ctx, state, func() (*api.DebuggerState, error) {
+			return c.client.StepInstruction(true)
+		})
+		if err != nil {
+			if errors.Is(err, ErrInterrupted) {
+				return c.finishStepToNextCall(response, nextState, fmt.Errorf("%v after %d instructions without reaching a call", err, response.Steps))
+			}
+			return c.finishStepToNextCall(response, nil, fmt.Errorf("step instruction command failed: %v", err))
+		}
+		response.Steps++
+		state = nextState
+
+		if state.Exited {
+			return c.finishStepToNextCall(response, state, fmt.Errorf("process exited with status %d after %d instructions without reaching a call", state.ExitStatus, response.Steps))
+		}
+		if state.CurrentThread == nil {
+			return c.finishStepToNextCall(response, state, fmt.Errorf("no current thread after stepping"))
+		}
+
+		inst := c.asmInstructionAt(state.CurrentThread.PC)
+		if inst == nil {
+			return c.finishStepToNextCall(response, state, fmt.Errorf("failed to decode the instruction at %#x", state.CurrentThread.PC))
+		}
+		if isCallInstruction(inst.Text) {
+			c.describeCall(&response, state.CurrentThread, inst)
+			return c.finishStepToNextCall(response, state, nil)
+		}
+	}
+
+	return c.finishStepToNextCall(response, state, fmt.Errorf("no call instruction in the next %d instructions; the thread may be in a loop without calls, or raise maxSteps", maxSteps))
+}
+
+// isCallInstruction reports whether the assembly text of an instruction is a call
+func isCallInstruction(text string) bool {
+	fields := strings.Fields(text)
+	return len(fields) > 0 && callMnemonics[strings.ToLower(fields[0])]
+}
+
+// isIndirectCall reports whether a call's target is computed at run time, from a register
+// or memory, rather than encoded in the instruction
+func isIndirectCall(text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return false
+	}
+	// On arm64 the mnemonic tells: BL branches to a fixed address, BLR to a register
+	switch strings.ToLower(fields[0]) {
+	case "bl":
+		return false
+	case "blr":
+		return true
+	}
+	operand := strings.ToLower(fields[len(fields)-1])
+	return !strings.HasPrefix(operand, "$") && !strings.HasPrefix(operand, "0x")
+}
+
+// callRegister returns the register an indirect call jumps through, as in "call rdx", or ""
+// when the call goes through memory or to a fixed address
+func callRegister(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) != 2 || !isIndirectCall(text) || strings.Contains(fields[1], "[") {
+		return ""
+	}
+	return strings.ToLower(fields[1])
+}
+
+// describeCall fills in the call about to be made. Delve resolves the target of a call at
+// the PC, even an indirect one; when it can't, a call through a register is resolved from
+// the register's current value.
+func (c *Client) describeCall(response *types.StepToNextCallResponse, thread *api.Thread, inst *api.AsmInstruction) {
+	response.Found = true
+	instruction := convertInstruction(*inst)
+	response.Call = &instruction
+	response.Indirect = isIndirectCall(inst.Text)
+
+	if inst.DestLoc != nil {
+		response.TargetAddress = fmt.Sprintf("%#x", inst.DestLoc.PC)
+		response.Target = getFunctionNameFromLocation(*inst.DestLoc)
+		return
+	}
+
+	reg := callRegister(inst.Text)
+	if reg == "" {
+		response.Message = "the call target could not be resolved; use step_instruction to enter the call and see where it lands"
+		return
+	}
+
+	addr, err := c.registerValue(thread.ID, reg)
+	if err != nil {
+		logger.Debug("Warning: Failed to read call register %s: %v", reg, err)
+		response.Message = fmt.Sprintf("the call target is in register %s, which could not be read; use step_instruction to enter the call", reg)
+		return
+	}
+	response.TargetAddress = fmt.Sprintf("%#x", addr)
+
+	locs, _, err := c.client.FindLocation(api.EvalScope{GoroutineID: -1}, fmt.Sprintf("*%#x", addr), false, nil)
+	if err == nil && len(locs) > 0 {
+		response.Target = getFunctionNameFromLocation(locs[0])
+	}
+	if response.Target == "" {
+		response.Message = fmt.Sprintf("the call goes through register %s to %#x, which is not in a known function", reg, addr)
+	}
+}
+
+// registerValue reads a general-purpose register of a thread by its name in assembly, such as "rdx"
+func (c *Client) registerValue(threadID int, name string) (uint64, error) {
+	regs, err := c.client.ListThreadRegisters(threadID, false)
+	if err != nil {
+		return 0, err
+	}
+	for _, reg := range regs {
+		if strings.EqualFold(reg.Name, name) {
+			return strconv.ParseUint(reg.Value, 0, 64)
+		}
+	}
+	return 0, fmt.Errorf("no register %s", name)
+}
+
+// finishStepToNextCall completes a StepToNextCallResponse. How far the search got is kept
+// on error.
+func (c *Client) finishStepToNextCall(response types.StepToNextCallResponse, state *api.DebuggerState, err error) types.StepToNextCallResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "step_to_next_call"
+
+	if state != nil && state.CurrentThread != nil && !state.Exited {
+		response.PC = fmt.Sprintf("%#x", state.CurrentThread.PC)
+	}
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		response.Status = "error"
+		response.Context = context
+		return response
+	}
+
+	response.Status = "success"
+	response.Context = context
+	return response
+}
@@ -0,0 +1,147 @@
+package debugger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+func TestClassifyCallInstruction(t *testing.T) {
+	testCases := []struct {
+		name             string
+		text             string
+		expectedCall     bool
+		expectedIndirect bool
+		expectedRegister string
+	}{
+		{name: "Direct call", text: "call $main.add", expectedCall: true},
+		{name: "Call to an address", text: "call 0x4924fd", expectedCall: true},
+		{name: "Call through a register", text: "call rcx", expectedCall: true, expectedIndirect: true, expectedRegister: "rcx"},
+		{name: "Call through memory", text: "call qword ptr [rdx]", expectedCall: true, expectedIndirect: true},
+		{name: "arm64 branch with link", text: "BL main.add(SB)", expectedCall: true},
+		{name: "arm64 branch with link to a register", text: "BLR R3", expectedCall: true, expectedIndirect: true, expectedRegister: "r3"},
+		{name: "Move", text: "mov rax, qword ptr [rsp+0x10]"},
+		{name: "Jump", text: "jmp 0x492500"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := isCallInstruction(tc.text); result != tc.expectedCall {
+				t.Errorf("Expected call %v, got %v", tc.expectedCall, result)
+			}
+			if !tc.expectedCall {
+				return
+			}
+			if result := isIndirectCall(tc.text); result != tc.expectedIndirect {
+				t.Errorf("Expected indirect %v, got %v", tc.expectedIndirect, result)
+			}
+			if result := callRegister(tc.text); result != tc.expectedRegister {
+				t.Errorf("Expected register %q, got %q", tc.expectedRegister, result)
+			}
+		})
+	}
+}
+
+// callingTarget returns a fake Delve stopped at the first instruction of program, that
+// steps through it in order, one instruction per step. Register rdx holds 0x2000, the
+// address of main.handler. The names of the commands run are recorded.
+func callingTarget(t *testing.T, program []api.AsmInstruction) (*Client, *[]string) {
+	t.Helper()
+	thread := func(pc uint64) *api.Thread {
+		return &api.Thread{ID: 1, PC: pc, File: "main.go", Line: 7, Function: &api.Function{Name_: "main.main"}, GoroutineID: 1}
+	}
+	next := 0
+
+	var commands []string
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(&api.DebuggerState{CurrentThread: thread(program[0].Loc.PC), SelectedGoroutine: &api.Goroutine{ID: 1}}),
+		"Command": fakeCommands(t, &commands, func(api.DebuggerCommand) api.DebuggerState {
+			next++
+			return api.DebuggerState{CurrentThread: thread(program[next].Loc.PC), SelectedGoroutine: &api.Goroutine{ID: 1}}
+		}),
+		"Disassemble": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.DisassembleIn
+			decodeFakeArgs(t, raw, &args)
+			var instructions api.AsmInstructions
+			for _, inst := range program {
+				if inst.Loc.PC >= args.StartPC && inst.Loc.PC < args.EndPC {
+					instructions = append(instructions, inst)
+				}
+			}
+			return rpc2.DisassembleOut{Disassemble: instructions}, nil
+		},
+		"ListRegisters": fakeResult(rpc2.ListRegistersOut{Regs: api.Registers{{Name: "Rax", Value: "0x1"}, {Name: "Rdx", Value: "0x2000"}}}),
+		"FindLocation": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.FindLocationIn
+			decodeFakeArgs(t, raw, &args)
+			if args.Loc != "*0x2000" {
+				return nil, fmt.Errorf("location %s not found", args.Loc)
+			}
+			return rpc2.FindLocationOut{Locations: []api.Location{{PC: 0x2000, Function: &api.Function{Name_: "main.handler"}}}}, nil
+		},
+	})
+	return c, &commands
+}
+
+func TestStepToNextCall(t *testing.T) {
+	program := []api.AsmInstruction{
+		{Loc: api.Location{PC: 0x1000}, Text: "call $main.setup", DestLoc: &api.Location{PC: 0x3000, Function: &api.Function{Name_: "main.setup"}}},
+		{Loc: api.Location{PC: 0x1005}, Text: "mov rax, 0x1"},
+		{Loc: api.Location{PC: 0x1009}, Text: "call $main.log", DestLoc: &api.Location{PC: 0x3100, Function: &api.Function{Name_: "main.log"}}},
+		{Loc: api.Location{PC: 0x100e}, Text: "call rdx"},
+	}
+
+	testCases := []struct {
+		name     string
+		program  []api.AsmInstruction
+		target   string
+		address  string
+		indirect bool
+	}{
+		{
+			// The call at the starting PC is stepped over
+			name:    "Direct call",
+			program: program,
+			target:  "main.log",
+			address: "0x3100",
+		},
+		{
+			name:     "Call through a register",
+			program:  append([]api.AsmInstruction{program[0], program[1]}, program[3]),
+			target:   "main.handler",
+			address:  "0x2000",
+			indirect: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, commands := callingTarget(t, tc.program)
+			response := c.StepToNextCall(context.Background(), 0)
+			if response.Status != "success" || !response.Found {
+				t.Fatalf("Expected to stop at a call, got %s: %s", response.Status, response.Context.ErrorMessage)
+			}
+			if response.Steps != 2 || len(*commands) != 2 || response.FromPC != "0x1000" {
+				t.Errorf("Expected 2 steps from 0x1000, got %d from %s, running %v", response.Steps, response.FromPC, *commands)
+			}
+			if response.Target != tc.target || response.TargetAddress != tc.address || response.Indirect != tc.indirect {
+				t.Errorf("Expected a call to %s at %s, indirect %v, got %s at %s, indirect %v", tc.target, tc.address, tc.indirect, response.Target, response.TargetAddress, response.Indirect)
+			}
+		})
+	}
+
+	// A search that runs out of steps reports how far it got
+	c, _ := callingTarget(t, program)
+	response := c.StepToNextCall(context.Background(), 1)
+	if response.Status != "error" || response.Found || response.Steps != 1 || response.PC != "0x1005" {
+		t.Errorf("Expected the search to stop at 0x1005 after 1 step, got %+v", response)
+	}
+	if !strings.Contains(response.Context.ErrorMessage, "no call instruction in the next 1 instructions") {
+		t.Errorf("Expected the limit to be named, got %q", response.Context.ErrorMessage)
+	}
+}
@@ -2,9 +2,14 @@ package debugger
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
 	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
@@ -15,6 +20,167 @@ type OutputMessage struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// defaultOutputBufferSize is how many bytes of each stream are kept unless MCP_OUTPUT_BUFFER_SIZE says otherwise
+const defaultOutputBufferSize = 1024 * 1024
+
+// getOutputBufferSize reads the capture buffer size from the MCP_OUTPUT_BUFFER_SIZE environment variable
+func getOutputBufferSize() int {
+	if env := os.Getenv("MCP_OUTPUT_BUFFER_SIZE"); env != "" {
+		size, err := strconv.Atoi(env)
+		if err == nil && size > 0 {
+			return size
+		}
+		logger.Warn("Ignoring invalid MCP_OUTPUT_BUFFER_SIZE", "value", env)
+	}
+	return defaultOutputBufferSize
+}
+
+// outputLine is a captured line along with its absolute offset in the stream
+type outputLine struct {
+	offset int64
+	msg    OutputMessage
+}
+
+// outputBuffer keeps the most recent output of a stream up to a byte limit, dropping
+// the oldest lines when full. Offsets count every byte ever written, so they stay
+// valid for polling after data has been dropped.
+type outputBuffer struct {
+	lines   []outputLine
+	size    int   // Bytes currently held
+	maxSize int   // Byte limit
+	end     int64 // Offset just past the last written byte
+	dropped int64 // Bytes dropped so far
+}
+
+func newOutputBuffer(maxSize int) *outputBuffer {
+	return &outputBuffer{maxSize: maxSize}
+}
+
+// append adds a line, dropping the oldest lines if the buffer is over its limit. A line
+// over the limit on its own is cut down to its last bytes that fit.
+func (b *outputBuffer) append(msg OutputMessage) {
+	n := len(msg.Content) + 1 // Account for the newline
+	b.lines = append(b.lines, outputLine{offset: b.end, msg: msg})
+	b.size += n
+	b.end += int64(n)
+
+	for b.size > b.maxSize && len(b.lines) > 1 {
+		dropped := len(b.lines[0].msg.Content) + 1
+		b.lines = b.lines[1:]
+		b.size -= dropped
+		b.dropped += int64(dropped)
+	}
+
+	if b.size > b.maxSize {
+		cut := b.size - b.maxSize
+		line := &b.lines[0]
+		line.msg.Content = line.msg.Content[cut:]
+		line.offset += int64(cut)
+		b.size -= cut
+		b.dropped += int64(cut)
+	}
+}
+
+// String returns the buffered output as text
+func (b *outputBuffer) String() string {
+	var sb strings.Builder
+	for _, l := range b.lines {
+		sb.WriteString(l.msg.Content)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// since returns the lines starting at or after offset, and how many bytes of the
+// requested range were already dropped
+func (b *outputBuffer) since(offset int64) ([]outputLine, int64) {
+	var missed int64
+	if len(b.lines) > 0 && offset < b.lines[0].offset {
+		missed = b.lines[0].offset - offset
+	}
+
+	i := sort.Search(len(b.lines), func(i int) bool {
+		return b.lines[i].offset >= offset
+	})
+	return b.lines[i:], missed
+}
+
+// ReadOutput returns the output written to stream ("stdout" or "stderr") at or after sinceOffset.
+// Pass the returned NextOffset on the next call to receive only new output.
+func (c *Client) ReadOutput(stream string, sinceOffset int64) types.OutputReadResponse {
+	if stream == "" {
+		stream = "stdout"
+	}
+
+	var buffer *outputBuffer
+	switch stream {
+	case "stdout":
+		buffer = c.stdout
+	case "stderr":
+		buffer = c.stderr
+	default:
+		return c.createOutputReadResponse(nil, stream, nil, sinceOffset, 0, fmt.Errorf("invalid stream %q, expected stdout or stderr", stream))
+	}
+
+	c.outputMutex.Lock()
+	lines, missed := buffer.since(sinceOffset)
+	result := make([]types.OutputLine, 0, len(lines))
+	for _, l := range lines {
+		result = append(result, types.OutputLine{
+			Offset:    l.offset,
+			Content:   l.msg.Content,
+			Timestamp: l.msg.Timestamp,
+		})
+	}
+	nextOffset := buffer.end
+	c.outputMutex.Unlock()
+
+	var state *api.DebuggerState
+	if c.client != nil {
+		var err error
+		state, err = c.client.GetStateNonBlocking()
+		if err != nil {
+			logger.Debug("Warning: Failed to get state while reading output: %v", err)
+		}
+	}
+
+	response := c.createOutputReadResponse(state, stream, result, nextOffset, missed, nil)
+	if c.client != nil && !c.outputCaptured {
+		response.Note = "output of attached processes cannot be captured, only launched programs are redirected"
+	}
+	return response
+}
+
+// createOutputReadResponse creates an OutputReadResponse
+func (c *Client) createOutputReadResponse(state *api.DebuggerState, stream string, lines []types.OutputLine, nextOffset, droppedBytes int64, err error) types.OutputReadResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "read_output"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.OutputReadResponse{
+			Status:  "error",
+			Context: context,
+			Stream:  stream,
+		}
+	}
+
+	var text strings.Builder
+	for _, l := range lines {
+		text.WriteString(l.Content)
+		text.WriteString("\n")
+	}
+
+	return types.OutputReadResponse{
+		Status:       "success",
+		Context:      context,
+		Stream:       stream,
+		Lines:        lines,
+		Output:       text.String(),
+		NextOffset:   nextOffset,
+		DroppedBytes: droppedBytes,
+	}
+}
+
 // GetDebuggerOutput returns the captured stdout and stderr from the debugged program
 func (c *Client) GetDebuggerOutput() types.DebuggerOutputResponse {
 	if c.client == nil {
@@ -37,17 +203,23 @@ func (c *Client) GetDebuggerOutput() types.DebuggerOutputResponse {
 	// Create a summary of the output for LLM
 	outputSummary := generateOutputSummary(stdout, stderr)
 
-	// Try to get state, but don't fail if unable
-	state, err := c.client.GetState()
+	// Try to get state, but don't fail if unable, nor wait for a running target to stop
+	state, err := c.client.GetStateNonBlocking()
 	if err != nil {
 		// Process might have exited, but we still want to return the captured output
+		context := types.DebugContext{
+			Timestamp: time.Now(),
+			Operation: "get_output",
+		}
+		c.noteExit(nil, err)
+		if exited, status := c.Exited(); exited {
+			context.StopReason = fmt.Sprintf("process exited with status %d", status)
+		} else {
+			context.ErrorMessage = fmt.Sprintf("state unavailable: %v", err)
+		}
 		return types.DebuggerOutputResponse{
-			Status: "success",
-			Context: types.DebugContext{
-				Timestamp:    time.Now(),
-				Operation:    "get_output",
-				ErrorMessage: fmt.Sprintf("state unavailable: %v", err),
-			},
+			Status:        "success",
+			Context:       context,
 			Stdout:        stdout,
 			Stderr:        stderr,
 			OutputSummary: outputSummary,
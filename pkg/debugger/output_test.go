@@ -0,0 +1,79 @@
+package debugger
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutputBuffer(t *testing.T) {
+	buffer := newOutputBuffer(9)
+
+	for _, line := range []string{"one", "two", "three"} {
+		buffer.append(OutputMessage{Source: "stdout", Content: line, Timestamp: time.Now()})
+	}
+
+	// "one\n" and "two\n" no longer fit next to "three\n", so they are dropped
+	if got := buffer.String(); got != "three\n" {
+		t.Errorf("Expected buffer to hold only the newest line, got %q", got)
+	}
+	if buffer.dropped != 8 {
+		t.Errorf("Expected 8 dropped bytes, got %d", buffer.dropped)
+	}
+	if buffer.end != 14 {
+		t.Errorf("Expected end offset 14, got %d", buffer.end)
+	}
+
+	lines, missed := buffer.since(0)
+	if len(lines) != 1 || lines[0].msg.Content != "three" || lines[0].offset != 8 {
+		t.Errorf("Expected only line %q at offset 8, got %+v", "three", lines)
+	}
+	if missed != 8 {
+		t.Errorf("Expected 8 missed bytes, got %d", missed)
+	}
+
+	lines, missed = buffer.since(buffer.end)
+	if len(lines) != 0 || missed != 0 {
+		t.Errorf("Expected no new output at the end offset, got %d lines and %d missed bytes", len(lines), missed)
+	}
+}
+
+func TestOutputBufferOversizedLine(t *testing.T) {
+	buffer := newOutputBuffer(9)
+	buffer.append(OutputMessage{Source: "stdout", Content: "one", Timestamp: time.Now()})
+	buffer.append(OutputMessage{Source: "stdout", Content: "abcdefghijkl", Timestamp: time.Now()})
+
+	// "one\n" is dropped, and the new line keeps only its last 8 bytes and the newline
+	if got := buffer.String(); got != "efghijkl\n" {
+		t.Errorf("Expected buffer to hold the end of the oversized line, got %q", got)
+	}
+	if buffer.size != 9 || buffer.dropped != 8 || buffer.end != 17 {
+		t.Errorf("Expected 9 bytes held, 8 dropped and end offset 17, got %d, %d and %d", buffer.size, buffer.dropped, buffer.end)
+	}
+
+	lines, missed := buffer.since(4)
+	if len(lines) != 1 || lines[0].offset != 8 || missed != 4 {
+		t.Errorf("Expected the line's kept bytes at offset 8 with 4 bytes missed, got %+v and %d missed", lines, missed)
+	}
+}
+
+func TestCaptureOutput(t *testing.T) {
+	long := strings.Repeat("x", maxOutputLineSize+10)
+	output := io.NopCloser(strings.NewReader("first\r\n" + long + "\nlast"))
+
+	c := NewClient()
+	c.captureOutput(output, "stdout", make(chan struct{}))
+
+	// The long line is split at the line size limit, and the last line needs no newline
+	lines, _ := c.stdout.since(0)
+	expected := []string{"first", long[:maxOutputLineSize], long[maxOutputLineSize:], "last"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d", len(expected), len(lines))
+	}
+	for i, line := range lines {
+		if line.msg.Content != expected[i] || line.msg.Source != "stdout" {
+			t.Errorf("Expected line %d to be %d bytes of stdout, got %d bytes of %s", i, len(expected[i]), len(line.msg.Content), line.msg.Source)
+		}
+	}
+}
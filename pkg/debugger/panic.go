@@ -0,0 +1,204 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// breakOnPanicName names the runtime.gopanic breakpoint installed by SetBreakOnPanic
+const breakOnPanicName = "break-on-panic"
+
+// Delve installs these breakpoints itself on every target, with negative IDs
+const (
+	unrecoveredPanicBreakpointID = -1 // runtime.fatalpanic, see proc.UnrecoveredPanic
+	fatalThrowBreakpointID       = -2 // runtime.throw and runtime.fatal, see proc.FatalThrow
+)
+
+// SetBreakOnPanic makes the target stop where a panic starts, including panics that are
+// later recovered, such as the ones net/http recovers in handlers. When fatal is true it
+// also stops on fatal runtime errors like concurrent map writes or "all goroutines are
+// asleep". Calling it with both false turns the mode off again.
+func (c *Client) SetBreakOnPanic(enabled bool, fatal bool) types.BreakOnPanicResponse {
+	if c.client == nil {
+		return c.createBreakOnPanicResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createBreakOnPanicResponse(nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createBreakOnPanicResponse(nil, nil, fmt.Errorf("cannot change panic breakpoints while the target is running; stop the target first"))
+	}
+
+	if err := c.applyBreakOnPanic(enabled, fatal); err != nil {
+		return c.createBreakOnPanicResponse(state, nil, err)
+	}
+
+	var bps []types.Breakpoint
+	for _, id := range []int{c.panicBreakpoint, unrecoveredPanicBreakpointID, fatalThrowBreakpointID} {
+		if id == 0 {
+			continue
+		}
+		if bp, err := c.client.GetBreakpoint(id); err == nil {
+			bps = append(bps, convertBreakpoint(bp))
+		}
+	}
+
+	return c.createBreakOnPanicResponse(state, bps, nil)
+}
+
+// applyBreakOnPanic installs or removes the panic breakpoints and remembers the settings,
+// so Restart can apply them to the new process
+func (c *Client) applyBreakOnPanic(enabled bool, fatal bool) error {
+	if enabled && c.panicBreakpoint == 0 {
+		logger.Debug("Setting breakpoint on runtime.gopanic")
+		bp, err := c.client.CreateBreakpoint(&api.Breakpoint{
+			Name:         breakOnPanicName,
+			FunctionName: "runtime.gopanic",
+			Variables:    []string{"e"},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set breakpoint on runtime.gopanic: %v", err)
+		}
+		c.panicBreakpoint = bp.ID
+	}
+
+	if !enabled && c.panicBreakpoint != 0 {
+		logger.Debug("Clearing breakpoint %d on runtime.gopanic", c.panicBreakpoint)
+		if _, err := c.client.ClearBreakpoint(c.panicBreakpoint); err != nil {
+			return fmt.Errorf("failed to clear breakpoint on runtime.gopanic: %v", err)
+		}
+		c.panicBreakpoint = 0
+	}
+
+	if err := c.setInternalBreakpointEnabled(unrecoveredPanicBreakpointID, enabled, nil); err != nil {
+		return err
+	}
+
+	// runtime.throw and runtime.fatal take the error message as their s argument
+	if err := c.setInternalBreakpointEnabled(fatalThrowBreakpointID, fatal, []string{"s"}); err != nil {
+		return err
+	}
+
+	c.breakOnPanic = enabled
+	c.breakOnFatal = fatal
+	c.breakOnPanicSet = true
+	return nil
+}
+
+// setInternalBreakpointEnabled enables or disables one of Delve's own breakpoints.
+// Targets without the runtime functions, e.g. stripped binaries, don't have them.
+func (c *Client) setInternalBreakpointEnabled(id int, enabled bool, variables []string) error {
+	bp, err := c.client.GetBreakpoint(id)
+	if err != nil {
+		if enabled {
+			return fmt.Errorf("the target has no %s breakpoint: %v", internalBreakpointName(id), err)
+		}
+		return nil
+	}
+
+	if bp.Disabled == !enabled && (len(variables) == 0 || len(bp.Variables) > 0) {
+		return nil
+	}
+
+	bp.Disabled = !enabled
+	if len(bp.Variables) == 0 {
+		bp.Variables = variables
+	}
+	if err := c.client.AmendBreakpoint(bp); err != nil {
+		return fmt.Errorf("failed to update the %s breakpoint: %v", internalBreakpointName(id), err)
+	}
+	return nil
+}
+
+// internalBreakpointName returns the name Delve gives its breakpoint with the given ID
+func internalBreakpointName(id int) string {
+	switch id {
+	case unrecoveredPanicBreakpointID:
+		return proc.UnrecoveredPanic
+	case fatalThrowBreakpointID:
+		return proc.FatalThrow
+	default:
+		return fmt.Sprintf("breakpoint %d", id)
+	}
+}
+
+// getPanicStop returns the stop detail for a thread stopped at one of the panic
+// breakpoints, or nil for any other stop
+func getPanicStop(thread *api.Thread) *types.StopDetail {
+	if thread == nil || thread.Breakpoint == nil {
+		return nil
+	}
+
+	detail := &types.StopDetail{BreakpointID: thread.Breakpoint.ID}
+	switch thread.Breakpoint.Name {
+	case breakOnPanicName, proc.UnrecoveredPanic:
+		detail.Kind = types.StopPanic
+	case proc.FatalThrow:
+		detail.Kind = types.StopFatal
+	default:
+		return nil
+	}
+
+	if thread.BreakpointInfo != nil && len(thread.BreakpointInfo.Variables) > 0 {
+		detail.Message = panicMessage(&thread.BreakpointInfo.Variables[0])
+	}
+	return detail
+}
+
+// panicMessage formats a panic value the way the runtime prints it where possible,
+// unwrapping the interface and the common error types
+func panicMessage(v *api.Variable) string {
+	if v.Unreadable != "" {
+		return fmt.Sprintf("<unreadable: %s>", v.Unreadable)
+	}
+
+	for (v.Kind == reflect.Interface || v.Kind == reflect.Ptr) && len(v.Children) > 0 {
+		v = &v.Children[0]
+	}
+
+	switch v.Kind {
+	case reflect.String:
+		return v.Value
+	case reflect.Struct:
+		// errors.New and fmt.Errorf keep their message in a string field
+		for i := range v.Children {
+			child := &v.Children[i]
+			if child.Kind == reflect.String && (child.Name == "s" || child.Name == "msg") {
+				return child.Value
+			}
+		}
+	}
+
+	return strings.TrimSpace(formatVariableValue(v))
+}
+
+// createBreakOnPanicResponse creates a BreakOnPanicResponse
+func (c *Client) createBreakOnPanicResponse(state *api.DebuggerState, bps []types.Breakpoint, err error) types.BreakOnPanicResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "break_on_panic"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.BreakOnPanicResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	return types.BreakOnPanicResponse{
+		Status:      "success",
+		Context:     context,
+		Panics:      c.breakOnPanic,
+		Fatal:       c.breakOnFatal,
+		Breakpoints: bps,
+	}
+}
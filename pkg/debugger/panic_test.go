@@ -0,0 +1,94 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestGetPanicStopReason(t *testing.T) {
+	stringPanic := api.Variable{
+		Name: "e",
+		Kind: reflect.Interface,
+		Children: []api.Variable{
+			{Type: "string", Kind: reflect.String, Value: "index out of range"},
+		},
+	}
+	errorPanic := api.Variable{
+		Name: "runtime.curg._panic.arg",
+		Kind: reflect.Interface,
+		Children: []api.Variable{{
+			Type: "*errors.errorString",
+			Kind: reflect.Ptr,
+			Children: []api.Variable{{
+				Type: "errors.errorString",
+				Kind: reflect.Struct,
+				Children: []api.Variable{
+					{Name: "s", Type: "string", Kind: reflect.String, Value: "boom"},
+				},
+			}},
+		}},
+	}
+
+	testCases := []struct {
+		name     string
+		thread   *api.Thread
+		expected string
+	}{
+		{
+			name:     "no breakpoint",
+			thread:   &api.Thread{},
+			expected: "",
+		},
+		{
+			name:     "user breakpoint",
+			thread:   &api.Thread{Breakpoint: &api.Breakpoint{ID: 1}},
+			expected: "",
+		},
+		{
+			name: "recovered panic with string value",
+			thread: &api.Thread{
+				Breakpoint:     &api.Breakpoint{ID: 3, Name: breakOnPanicName},
+				BreakpointInfo: &api.BreakpointInfo{Variables: []api.Variable{stringPanic}},
+			},
+			expected: "stopped at panic: index out of range",
+		},
+		{
+			name: "unrecovered panic with error value",
+			thread: &api.Thread{
+				Breakpoint:     &api.Breakpoint{ID: -1, Name: proc.UnrecoveredPanic},
+				BreakpointInfo: &api.BreakpointInfo{Variables: []api.Variable{errorPanic}},
+			},
+			expected: "stopped at panic: boom",
+		},
+		{
+			name: "fatal error",
+			thread: &api.Thread{
+				Breakpoint: &api.Breakpoint{ID: -2, Name: proc.FatalThrow},
+				BreakpointInfo: &api.BreakpointInfo{Variables: []api.Variable{
+					{Name: "s", Type: "string", Kind: reflect.String, Value: "concurrent map writes"},
+				}},
+			},
+			expected: "stopped at fatal error: concurrent map writes",
+		},
+		{
+			name:     "fatal error without message",
+			thread:   &api.Thread{Breakpoint: &api.Breakpoint{ID: -2, Name: proc.FatalThrow}},
+			expected: "stopped at fatal error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var reason string
+			if detail := getPanicStop(tc.thread); detail != nil {
+				reason = formatStopDetail(detail)
+			}
+			if reason != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// pointerLoadConfig loads a pointer's target one level deep: its fields and elements, and
+// where pointers among them lead, but not what nested values hold
+var pointerLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 0,
+	MaxStringLen:       256,
+	MaxArrayValues:     64,
+	MaxStructFields:    -1,
+}
+
+// States of a followed pointer
+const (
+	pointerNil        = "nil pointer"
+	pointerUnreadable = "unreadable memory"
+	pointerValid      = "pointer"
+)
+
+// FollowPointer evaluates a pointer expression in the given frame and reports the address
+// it holds and, when it is not nil, one level of the value it points to, along with the
+// expression of that value and of the pointers in it, to be followed in turn. A nil
+// pointer is told apart from one into memory that cannot be read.
+func (c *Client) FollowPointer(expr string, frame int) types.FollowPointerResponse {
+	if c.client == nil {
+		return c.createFollowPointerResponse(nil, expr, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createFollowPointerResponse(nil, expr, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createFollowPointerResponse(nil, expr, nil, fmt.Errorf("cannot follow pointers while the target is running; stop the target first"))
+	}
+	if state.SelectedGoroutine == nil {
+		return c.createFollowPointerResponse(state, expr, nil, fmt.Errorf("no goroutine selected"))
+	}
+
+	scope := api.EvalScope{
+		GoroutineID: state.SelectedGoroutine.ID,
+		Frame:       frame,
+	}
+
+	logger.Debug("Following pointer %q in frame %d", expr, frame)
+	v, err := c.client.EvalVariable(scope, expr, pointerLoadConfig)
+	if err != nil {
+		if isUnresolvedSymbol(err) {
+			return c.createFollowPointerResponse(state, expr, nil, fmt.Errorf("could not resolve %q: %v; variables in scope: %s", expr, err, c.scopeVariableNames(scope)))
+		}
+		return c.createFollowPointerResponse(state, expr, nil, fmt.Errorf("failed to evaluate %q: %v", expr, err))
+	}
+	if v == nil {
+		return c.createFollowPointerResponse(state, expr, nil, fmt.Errorf("expression %q produced no value", expr))
+	}
+	if v.Kind != reflect.Ptr {
+		return c.createFollowPointerResponse(state, expr, nil, fmt.Errorf("%q is not a pointer but a %s of type %s; use eval_expression to inspect it", expr, v.Kind, v.Type))
+	}
+	if isNilPointer(v) {
+		return c.createFollowPointerResponse(state, expr, v, nil)
+	}
+
+	// Delve only loads the target of a pointer the expression names directly, such as a
+	// variable, so the target is loaded on its own
+	pointee, err := c.client.EvalVariable(scope, derefExpression(expr), pointerLoadConfig)
+	if err != nil {
+		pointee = &api.Variable{Addr: v.Children[0].Addr, Type: v.Children[0].Type, Unreadable: err.Error()}
+	}
+	v.Children[0] = *pointee
+
+	return c.createFollowPointerResponse(state, expr, v, nil)
+}
+
+// isNilPointer reports whether a loaded pointer is nil
+func isNilPointer(v *api.Variable) bool {
+	return len(v.Children) == 0 || v.Children[0].Addr == 0
+}
+
+// pointerState classifies where a loaded pointer leads
+func pointerState(v *api.Variable) string {
+	if isNilPointer(v) {
+		return pointerNil
+	}
+	if unreadableReason(&v.Children[0]) != "" {
+		return pointerUnreadable
+	}
+	return pointerValid
+}
+
+// unreadableReason tells why the memory of a value cannot be read, or returns "" when it
+// can. Delve reads a struct or array field by field, so one in unreadable memory is one
+// whose every field is unreadable.
+func unreadableReason(v *api.Variable) string {
+	if v.Unreadable != "" {
+		return v.Unreadable
+	}
+	if (v.Kind != reflect.Struct && v.Kind != reflect.Array) || len(v.Children) == 0 {
+		return ""
+	}
+	for i := range v.Children {
+		if v.Children[i].Unreadable == "" {
+			return ""
+		}
+	}
+	return v.Children[0].Unreadable
+}
+
+// derefExpression returns the expression of the value a pointer expression points to
+func derefExpression(expr string) string {
+	return "(*" + expr + ")"
+}
+
+// pointerLinks returns the pointers among the fields and elements of a pointee, with the
+// expression to follow each of them
+func pointerLinks(pointee *api.Variable, pointeeExpr string) []types.PointerLink {
+	var links []types.PointerLink
+	for i := range pointee.Children {
+		child := &pointee.Children[i]
+		if child.Kind != reflect.Ptr {
+			continue
+		}
+
+		var childExpr string
+		switch pointee.Kind {
+		case reflect.Struct:
+			childExpr = pointeeExpr + "." + child.Name
+		case reflect.Array, reflect.Slice:
+			childExpr = fmt.Sprintf("%s[%d]", pointeeExpr, i)
+		default:
+			continue
+		}
+
+		link := types.PointerLink{
+			Name:       child.Name,
+			Expression: childExpr,
+			Type:       child.Type,
+			IsNil:      isNilPointer(child),
+		}
+		if !link.IsNil {
+			link.Address = fmt.Sprintf("%#x", child.Children[0].Addr)
+		}
+		if link.Name == "" {
+			link.Name = fmt.Sprintf("[%d]", i)
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// createFollowPointerResponse creates a FollowPointerResponse
+func (c *Client) createFollowPointerResponse(state *api.DebuggerState, expr string, v *api.Variable, err error) types.FollowPointerResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "follow_pointer"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.FollowPointerResponse{
+			Status:     "error",
+			Context:    context,
+			Expression: expr,
+		}
+	}
+
+	response := types.FollowPointerResponse{
+		Status:     "success",
+		Context:    context,
+		Expression: expr,
+		Type:       v.Type,
+		State:      pointerState(v),
+	}
+
+	switch response.State {
+	case pointerNil:
+		response.IsNil = true
+		response.Address = "0x0"
+		response.Message = fmt.Sprintf("%s is a nil pointer; there is nothing to follow", expr)
+		return response
+	case pointerUnreadable:
+		pointee := &v.Children[0]
+		response.Address = fmt.Sprintf("%#x", pointee.Addr)
+		response.PointeeType = pointee.Type
+		response.Message = fmt.Sprintf("%s points to %#x, which cannot be read: %s", expr, pointee.Addr, unreadableReason(pointee))
+		return response
+	}
+
+	pointee := &v.Children[0]
+	next := derefExpression(expr)
+	response.Address = fmt.Sprintf("%#x", pointee.Addr)
+	response.PointeeType = pointee.Type
+	response.PointeeKind = pointee.Kind.String()
+	response.Value = formatVariableValue(pointee)
+	response.Next = next
+	response.Links = pointerLinks(pointee, next)
+	switch pointee.Kind {
+	case reflect.Struct, reflect.Array, reflect.Slice:
+		for i := range pointee.Children {
+			field := convertVariableTree(&pointee.Children[i], "field", 0)
+			if pointee.Kind != reflect.Struct {
+				field.Name = fmt.Sprintf("[%d]", i)
+			}
+			response.Fields = append(response.Fields, field)
+		}
+	case reflect.Ptr:
+		response.Message = fmt.Sprintf("%s points to another pointer; follow %s to go on", expr, next)
+	}
+	return response
+}
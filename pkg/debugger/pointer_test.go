@@ -0,0 +1,132 @@
+package debugger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+func TestPointerState(t *testing.T) {
+	testCases := []struct {
+		name     string
+		variable api.Variable
+		expected string
+	}{
+		{name: "No children", variable: api.Variable{Kind: reflect.Ptr}, expected: pointerNil},
+		{name: "Nil", variable: api.Variable{Kind: reflect.Ptr, Children: []api.Variable{{Kind: reflect.Struct}}}, expected: pointerNil},
+		{name: "Valid", variable: api.Variable{Kind: reflect.Ptr, Children: []api.Variable{{Kind: reflect.Struct, Addr: 0xc000010000}}}, expected: pointerValid},
+		{name: "Unreadable value", variable: api.Variable{Kind: reflect.Ptr, Children: []api.Variable{{Kind: reflect.Int, Addr: 0x10, Unreadable: "input/output error"}}}, expected: pointerUnreadable},
+		{
+			name: "Unreadable struct",
+			variable: api.Variable{Kind: reflect.Ptr, Children: []api.Variable{{Kind: reflect.Struct, Addr: 0x10, Children: []api.Variable{
+				{Name: "Val", Kind: reflect.Int, Unreadable: "input/output error"},
+				{Name: "Next", Kind: reflect.Ptr, Unreadable: "input/output error"},
+			}}}},
+			expected: pointerUnreadable,
+		},
+		{
+			name: "Partly unreadable struct",
+			variable: api.Variable{Kind: reflect.Ptr, Children: []api.Variable{{Kind: reflect.Struct, Addr: 0xc000010000, Children: []api.Variable{
+				{Name: "Val", Kind: reflect.Int, Value: "1"},
+				{Name: "Next", Kind: reflect.Ptr, Unreadable: "input/output error"},
+			}}}},
+			expected: pointerValid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pointerState(&tc.variable); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPointerLinks(t *testing.T) {
+	node := &api.Variable{Type: "main.Node", Kind: reflect.Struct, Children: []api.Variable{
+		{Name: "Val", Type: "int", Kind: reflect.Int, Value: "1"},
+		{Name: "Next", Type: "*main.Node", Kind: reflect.Ptr, Children: []api.Variable{{Type: "main.Node", Kind: reflect.Struct, Addr: 0xc000010080}}},
+		{Name: "Prev", Type: "*main.Node", Kind: reflect.Ptr, Children: []api.Variable{{Type: "main.Node", Kind: reflect.Struct}}},
+	}}
+
+	links := pointerLinks(node, derefExpression("head"))
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d: %+v", len(links), links)
+	}
+	if links[0].Expression != "(*head).Next" || links[0].Address != "0xc000010080" || links[0].IsNil {
+		t.Errorf("Expected a link to follow (*head).Next at 0xc000010080, got %+v", links[0])
+	}
+	if links[1].Expression != "(*head).Prev" || !links[1].IsNil {
+		t.Errorf("Expected a nil link for (*head).Prev, got %+v", links[1])
+	}
+
+	elements := &api.Variable{Type: "[2]*int", Kind: reflect.Array, Children: []api.Variable{
+		{Type: "*int", Kind: reflect.Ptr, Children: []api.Variable{{Type: "int", Kind: reflect.Int, Addr: 0xc000012000}}},
+		{Type: "*int", Kind: reflect.Ptr},
+	}}
+	links = pointerLinks(elements, "(*p)")
+	if len(links) != 2 || links[1].Name != "[1]" || links[1].Expression != "(*p)[1]" {
+		t.Errorf("Expected links to follow each element by index, got %+v", links)
+	}
+}
+
+func TestFollowPointer(t *testing.T) {
+	node := &api.Variable{Name: "node", Type: "*main.Node", Kind: reflect.Ptr, Children: []api.Variable{{Type: "main.Node", Kind: reflect.Struct, Addr: 0xc000010000}}}
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+		"Eval": fakeEval(t, map[string]*api.Variable{
+			"node": node,
+			"(*node)": {Type: "main.Node", Kind: reflect.Struct, Addr: 0xc000010000, Children: []api.Variable{
+				{Name: "Value", Type: "int", Kind: reflect.Int, Value: "7"},
+				{Name: "Next", Type: "*main.Node", Kind: reflect.Ptr, Children: []api.Variable{{Type: "main.Node", Kind: reflect.Struct, Addr: 0xc000010080}}},
+			}},
+			"empty": {Name: "empty", Type: "*main.Node", Kind: reflect.Ptr, Children: []api.Variable{{Type: "main.Node", Kind: reflect.Struct}}},
+			// (*stale) is left out, so loading what it points to fails
+			"stale": {Name: "stale", Type: "*main.Node", Kind: reflect.Ptr, Children: []api.Variable{{Type: "main.Node", Kind: reflect.Struct, Addr: 0xdead}}},
+			"count": {Name: "count", Type: "int", Kind: reflect.Int, Value: "3"},
+		}),
+		"ListFunctionArgs": fakeResult(rpc2.ListFunctionArgsOut{Args: []api.Variable{{Name: "node"}}}),
+		"ListLocalVars":    fakeResult(rpc2.ListLocalVarsOut{Variables: []api.Variable{{Name: "count"}}}),
+	})
+
+	response := c.FollowPointer("node", 0)
+	if response.Status != "success" || response.State != pointerValid || response.Address != "0xc000010000" {
+		t.Fatalf("Expected node to point to 0xc000010000, got %+v", response)
+	}
+	if response.Next != "(*node)" || len(response.Fields) != 2 || response.Fields[0].Value != "7" {
+		t.Errorf("Expected the fields of (*node), got next %q and %+v", response.Next, response.Fields)
+	}
+	if len(response.Links) != 1 || response.Links[0].Expression != "(*node).Next" || response.Links[0].Address != "0xc000010080" {
+		t.Errorf("Expected a link to follow (*node).Next, got %+v", response.Links)
+	}
+
+	response = c.FollowPointer("empty", 0)
+	if response.Status != "success" || !response.IsNil || response.Address != "0x0" || len(response.Fields) != 0 {
+		t.Errorf("Expected empty to be a nil pointer, got %+v", response)
+	}
+
+	response = c.FollowPointer("stale", 0)
+	if response.Status != "success" || response.State != pointerUnreadable || response.Address != "0xdead" {
+		t.Errorf("Expected stale to point to unreadable memory at 0xdead, got %+v", response)
+	}
+
+	testCases := []struct {
+		expr     string
+		expected string
+	}{
+		{expr: "count", expected: `"count" is not a pointer but a int`},
+		{expr: "missing", expected: "variables in scope: count, node"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			response := c.FollowPointer(tc.expr, 0)
+			if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, tc.expected) {
+				t.Errorf("Expected an error containing %q, got %s: %s", tc.expected, response.Status, response.Context.ErrorMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package debugger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// processInfo describes a process found in the process table
+type processInfo struct {
+	Pid     int
+	Name    string   // Executable name
+	CmdLine []string // Command line arguments
+}
+
+// AttachByName attaches to the single running process whose executable matches name.
+// With exact set the name must match in full, otherwise a substring match is used.
+func (c *Client) AttachByName(name string, exact bool) types.AttachResponse {
+	if c.client != nil {
+		return c.createAttachResponse(nil, 0, name, nil, fmt.Errorf("debug session already active"))
+	}
+
+	if name == "" {
+		return c.createAttachResponse(nil, 0, name, nil, fmt.Errorf("process name must not be empty"))
+	}
+
+	processes, err := listProcesses()
+	if err != nil {
+		return c.createAttachResponse(nil, 0, name, nil, fmt.Errorf("failed to list processes: %v", err))
+	}
+
+	candidates := processCandidates(processes, name, exact, os.Getpid())
+
+	logger.Debug("Found %d processes matching %q", len(candidates), name)
+
+	switch len(candidates) {
+	case 0:
+		return c.createAttachResponse(nil, 0, name, nil, fmt.Errorf("no process found matching %q", name))
+	case 1:
+		response := c.AttachToProcess(candidates[0].Pid)
+		response.Target = name
+		response.Process = &candidates[0]
+		return response
+	default:
+		pids := make([]string, 0, len(candidates))
+		for _, p := range candidates {
+			pids = append(pids, fmt.Sprintf("%d (%s)", p.Pid, p.Name))
+		}
+		response := c.createAttachResponse(nil, 0, name, nil, fmt.Errorf("process name %q is ambiguous, candidates: %s", name, strings.Join(pids, ", ")))
+		response.Candidates = candidates
+		return response
+	}
+}
+
+// processCandidates returns the processes whose name matches, leaving out the process
+// with PID self, so the debugger never attaches to itself
+func processCandidates(processes []processInfo, name string, exact bool, self int) []types.Process {
+	var candidates []types.Process
+	for _, p := range processes {
+		if p.Pid == self {
+			continue
+		}
+		if matchProcessName(p.Name, name, exact) {
+			candidates = append(candidates, types.Process{
+				Pid:     p.Pid,
+				Name:    p.Name,
+				CmdLine: p.CmdLine,
+				Status:  "running",
+				Summary: fmt.Sprintf("%s (PID %d)", p.Name, p.Pid),
+			})
+		}
+	}
+	return candidates
+}
+
+// matchProcessName compares a process name against the requested name
+func matchProcessName(processName, name string, exact bool) bool {
+	if exact {
+		return processName == name
+	}
+	return strings.Contains(processName, name)
+}
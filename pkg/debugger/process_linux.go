@@ -0,0 +1,52 @@
+//go:build linux
+
+package debugger
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// listProcesses reads the process table from /proc
+func listProcesses() ([]processInfo, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []processInfo
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join("/proc", entry.Name())
+
+		// Processes can exit while we scan, so skip any we can't read
+		var cmdLine []string
+		if data, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+			cmdLine = strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+		}
+
+		// Prefer the executable path, comm is truncated to 15 characters
+		var name string
+		if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+			name = filepath.Base(strings.TrimSuffix(exe, " (deleted)"))
+		} else if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+			name = strings.TrimSpace(string(comm))
+		} else {
+			continue
+		}
+
+		processes = append(processes, processInfo{
+			Pid:     pid,
+			Name:    name,
+			CmdLine: cmdLine,
+		})
+	}
+
+	return processes, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package debugger
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// listProcesses is not implemented on this platform yet
+func listProcesses() ([]processInfo, error) {
+	return nil, fmt.Errorf("listing processes is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,93 @@
+package debugger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestMatchProcessName(t *testing.T) {
+	testCases := []struct {
+		processName string
+		name        string
+		exact       bool
+		expected    bool
+	}{
+		{processName: "server", name: "server", exact: true, expected: true},
+		{processName: "server", name: "server", exact: false, expected: true},
+		{processName: "api-server", name: "server", exact: true, expected: false},
+		{processName: "api-server", name: "server", exact: false, expected: true},
+		{processName: "Server", name: "server", exact: false, expected: false},
+		{processName: "serve", name: "server", exact: false, expected: false},
+	}
+
+	for _, tc := range testCases {
+		if got := matchProcessName(tc.processName, tc.name, tc.exact); got != tc.expected {
+			t.Errorf("matchProcessName(%q, %q, %v) = %v; expected %v", tc.processName, tc.name, tc.exact, got, tc.expected)
+		}
+	}
+}
+
+func TestProcessCandidates(t *testing.T) {
+	processes := []processInfo{
+		{Pid: 10, Name: "server", CmdLine: []string{"/bin/server", "-port", "80"}},
+		{Pid: 11, Name: "api-server"},
+		{Pid: 12, Name: "worker"},
+		{Pid: 13, Name: "mcp-go-debugger"},
+	}
+
+	testCases := []struct {
+		name     string
+		search   string
+		exact    bool
+		self     int
+		expected []int
+	}{
+		{name: "Exact", search: "server", exact: true, self: 13, expected: []int{10}},
+		{name: "Substring is ambiguous", search: "server", self: 13, expected: []int{10, 11}},
+		{name: "Substring of one", search: "work", self: 13, expected: []int{12}},
+		{name: "No match", search: "database", self: 13},
+		{name: "Exact match on a substring", search: "serv", exact: true, self: 13},
+		{name: "The debugger itself", search: "mcp-go-debugger", exact: true, self: 13},
+		{name: "Another debugger", search: "mcp-go-debugger", exact: true, self: 99, expected: []int{13}},
+		{name: "Ambiguous without the debugger", search: "server", self: 11, expected: []int{10}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			candidates := processCandidates(processes, tc.search, tc.exact, tc.self)
+			var pids []int
+			for _, p := range candidates {
+				pids = append(pids, p.Pid)
+			}
+			if !reflect.DeepEqual(pids, tc.expected) {
+				t.Errorf("Expected PIDs %v, got %v", tc.expected, pids)
+			}
+		})
+	}
+
+	first := processCandidates(processes, "server", true, 0)[0]
+	if first.Summary != "server (PID 10)" || first.Status != "running" || !reflect.DeepEqual(first.CmdLine, processes[0].CmdLine) {
+		t.Errorf("Expected the process described with its command line, got %+v", first)
+	}
+}
+
+func TestAttachByNameSkipsSelf(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("listing processes is only supported on Linux")
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Failed to find the test binary: %v", err)
+	}
+
+	// The test binary is the only process with its name, and it must not attach to itself
+	name := filepath.Base(executable)
+	response := NewClient().AttachByName(name, true)
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "no process found") {
+		t.Errorf("Expected no process found for %s, got %s: %s", name, response.Status, response.Context.ErrorMessage)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/go-delve/delve/pkg/gobuild"
@@ -21,8 +22,102 @@ import (
 	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
+// launchTimeout bounds how long Delve may take to start the program, which recording it
+// with rr makes slower
+const launchTimeout = 30 * time.Second
+
 // LaunchProgram starts a new program with debugging enabled
 func (c *Client) LaunchProgram(program string, args []string) types.LaunchResponse {
+	return c.launchProgram(program, args, nil, "")
+}
+
+// Launch starts a program with arguments, environment variables and a working directory.
+// If path is a Go package or source file rather than a binary, it is built first.
+func (c *Client) Launch(path string, args []string, env []string, workingDir string) types.LaunchResponse {
+	if c.client != nil {
+		return c.createLaunchResponse(nil, path, args, fmt.Errorf("debug session already active"))
+	}
+
+	if !isExecutableFile(path) {
+		debugBinary := gobuild.DefaultDebugBinaryPath("debug_binary")
+
+		logger.Debug("Building package %s to %s", path, debugBinary)
+		cmd, output, err := gobuild.GoBuildCombinedOutput(debugBinary, []string{path}, "-gcflags all=-N")
+		if err != nil {
+			logger.Debug("Build command: %s", cmd)
+			gobuild.Remove(debugBinary)
+			response := c.createLaunchResponse(nil, path, args, fmt.Errorf("failed to build %s: %v\nOutput: %s", path, err, string(output)))
+			response.BuildOutput = string(output)
+			return response
+		}
+
+		response := c.launchProgram(debugBinary, args, env, workingDir)
+		if response.Context.ErrorMessage != "" {
+			gobuild.Remove(debugBinary)
+			return response
+		}
+
+		// Store the binary path for cleanup
+		c.target = debugBinary
+		c.buildPkgs = []string{path}
+		response.Program = path
+		response.DebugBinary = debugBinary
+		response.BuildOutput = string(output)
+		return response
+	}
+
+	return c.launchProgram(path, args, env, workingDir)
+}
+
+// isExecutableFile reports whether path is an existing executable file rather than a package or source file
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || strings.HasSuffix(path, ".go") {
+		return false
+	}
+	return info.Mode()&0111 != 0
+}
+
+// setLaunchEnv applies KEY=VALUE pairs to this process's environment, which Delve
+// passes on to the launched target, and returns a function restoring the previous values
+func setLaunchEnv(env []string) (func(), error) {
+	type savedVar struct {
+		key   string
+		value string
+		set   bool
+	}
+
+	var previous []savedVar
+	restore := func() {
+		for i := len(previous) - 1; i >= 0; i-- {
+			if previous[i].set {
+				_ = os.Setenv(previous[i].key, previous[i].value)
+			} else {
+				_ = os.Unsetenv(previous[i].key)
+			}
+		}
+	}
+
+	for _, kv := range env {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			restore()
+			return nil, fmt.Errorf("invalid environment variable %q, expected KEY=VALUE", kv)
+		}
+
+		old, set := os.LookupEnv(key)
+		previous = append(previous, savedVar{key: key, value: old, set: set})
+		if err := os.Setenv(key, value); err != nil {
+			restore()
+			return nil, fmt.Errorf("failed to set environment variable %s: %v", key, err)
+		}
+	}
+
+	return restore, nil
+}
+
+// launchProgram starts a compiled program under the debugger
+func (c *Client) launchProgram(program string, args []string, env []string, workingDir string) types.LaunchResponse {
 	if c.client != nil {
 		return c.createLaunchResponse(nil, program, args, fmt.Errorf("debug session already active"))
 	}
@@ -66,6 +161,28 @@ func (c *Client) LaunchProgram(program string, args []string) types.LaunchRespon
 		return c.createLaunchResponse(nil, program, args, fmt.Errorf("failed to create stderr redirector: %v", err))
 	}
 
+	// The target inherits our environment, so apply the requested variables until it has started
+	restoreEnv, err := setLaunchEnv(env)
+	if err != nil {
+		_ = listener.Close()
+		return c.createLaunchResponse(nil, program, args, err)
+	}
+	defer restoreEnv()
+
+	backend := c.backend
+	if backend == "" {
+		backend = "default"
+	}
+
+	// Give the target a stdin that WriteStdin can feed
+	var stdinPath string
+	stdin, err := newStdinPipe()
+	if err != nil {
+		logger.Debug("Warning: Failed to create stdin pipe, the program will have no stdin: %v", err)
+	} else {
+		stdinPath = stdin.path
+	}
+
 	// Create Delve config
 	config := &service.Config{
 		Listener:    listener,
@@ -73,66 +190,77 @@ func (c *Client) LaunchProgram(program string, args []string) types.LaunchRespon
 		AcceptMulti: true,
 		ProcessArgs: append([]string{absPath}, args...),
 		Debugger: debugger.Config{
-			WorkingDir:     "",
-			Backend:        "default",
+			WorkingDir:     workingDir,
+			Backend:        backend,
 			CheckGoVersion: true,
 			DisableASLR:    true,
+			Stdin:          stdinPath,
 			Stdout:         stdoutRedirect,
 			Stderr:         stderrRedirect,
 		},
 	}
+	closeStdin := func() {
+		if stdin != nil {
+			stdin.close()
+		}
+	}
 
 	// Start goroutines to capture output
-	go c.captureOutput(stdoutReader, "stdout")
-	go c.captureOutput(stderrReader, "stderr")
+	go c.captureOutput(stdoutReader, "stdout", c.stopOutput)
+	go c.captureOutput(stderrReader, "stderr", c.stopOutput)
+	c.outputCaptured = true
 
 	// Create and start the debugging server
 	server := rpccommon.NewServer(config)
 	if server == nil {
+		closeStdin()
 		return c.createLaunchResponse(nil, program, args, fmt.Errorf("failed to create debug server"))
 	}
 
 	c.server = server
 
-	// Create a channel to signal when the server is ready or fails
+	// Run returns once the program is launched and the server accepts connections, so wait
+	// for it before connecting, as a client connecting to a server that failed, e.g. on a Go
+	// version Delve doesn't support, would hang
 	serverReady := make(chan error, 1)
-
-	// Start server in a goroutine
 	go func() {
-		err := server.Run()
+		serverReady <- server.Run()
+	}()
+
+	select {
+	case err := <-serverReady:
 		if err != nil {
 			logger.Debug("Debug server error: %v", err)
-			serverReady <- err
+			closeStdin()
+			return c.createLaunchResponse(nil, program, args, fmt.Errorf("debug server failed to start: %v", err))
 		}
-	}()
+	case <-time.After(launchTimeout):
+		closeStdin()
+		return c.createLaunchResponse(nil, program, args, fmt.Errorf("timed out after %v waiting for debug server to start", launchTimeout))
+	}
 
-	// Try to connect to the server with a timeout
-	addr := listener.Addr().String()
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	client := rpc2.NewClient(listener.Addr().String())
+	state, err := client.GetState()
+	if err != nil {
+		closeStdin()
+		return c.createLaunchResponse(nil, program, args, fmt.Errorf("launched %s but failed to get its state: %v", program, err))
+	}
 
-	// Try to connect repeatedly until timeout
-	var connected bool
-	for !connected {
-		select {
-		case <-ctx.Done():
-			return c.createLaunchResponse(nil, program, args, fmt.Errorf("timed out waiting for debug server to start"))
-		case err := <-serverReady:
-			return c.createLaunchResponse(nil, program, args, fmt.Errorf("debug server failed to start: %v", err))
-		default:
-			client := rpc2.NewClient(addr)
-			state, err := client.GetState()
-			if err == nil && state != nil {
-				c.client = client
-				c.target = absPath
-
-				return c.createLaunchResponse(state, program, args, nil)
-			}
-			time.Sleep(100 * time.Millisecond)
-		}
+	c.client = client
+	c.target = absPath
+	c.pid = state.Pid
+	c.launchArgs = args
+	c.launchEnv = env
+	c.launchWorkingDir = workingDir
+	if stdin != nil {
+		stdin.started()
+		c.stdin = stdin
 	}
 
-	return c.createLaunchResponse(nil, program, args, fmt.Errorf("failed to launch program"))
+	response := c.createLaunchResponse(state, program, args, nil)
+	response.Env = env
+	response.WorkingDir = workingDir
+	return response
 }
 
 // AttachToProcess attaches to an existing process with the given PID
@@ -188,61 +316,32 @@ func (c *Client) AttachToProcess(pid int) types.AttachResponse {
 
 	c.server = server
 
-	// Create a channel to signal when the server is ready or fails
+	// As for a launch, wait for the server to attach before connecting to it
 	serverReady := make(chan error, 1)
-
-	logger.Debug("Starting debug server in goroutine")
-	// Start server in a goroutine
 	go func() {
-		logger.Debug("Running server")
-		err := server.Run()
-		if err != nil {
-			logger.Debug("Debug server error: %v", err)
-			serverReady <- err
-		}
-		logger.Debug("Server run completed")
+		serverReady <- server.Run()
 	}()
 
-	logger.Debug("Waiting for server to start")
-
-	// Try to connect to the server with a timeout
-	addr := listener.Addr().String()
-
-	// Wait up to 3 seconds for server to be available
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	// Try to connect repeatedly until timeout
-	connected := false
-	for !connected {
-		select {
-		case <-ctx.Done():
-			// Timeout reached
-			return c.createAttachResponse(nil, pid, "", nil, fmt.Errorf("timed out waiting for debug server to start"))
-		case err := <-serverReady:
-			// Server reported an error
+	select {
+	case err := <-serverReady:
+		if err != nil {
+			logger.Debug("Debug server error: %v", err)
 			return c.createAttachResponse(nil, pid, "", nil, fmt.Errorf("debug server failed to start: %v", err))
-		default:
-			// Try to connect
-			client := rpc2.NewClient(addr)
-			// Make a simple API call to test connection
-			state, err := client.GetState()
-			if err == nil && state != nil {
-				// Connection successful
-				c.client = client
-				c.pid = pid
-				logger.Debug("Successfully attached to process with PID: %d", pid)
-
-				// Get initial state
-				return c.createAttachResponse(state, pid, "", nil, nil)
-			} else {
-				// Failed, wait briefly and retry
-				time.Sleep(100 * time.Millisecond)
-			}
 		}
+	case <-time.After(launchTimeout):
+		return c.createAttachResponse(nil, pid, "", nil, fmt.Errorf("timed out after %v waiting for debug server to start", launchTimeout))
 	}
 
-	return c.createAttachResponse(nil, pid, "", nil, fmt.Errorf("failed to attach to process"))
+	client := rpc2.NewClient(listener.Addr().String())
+	state, err := client.GetState()
+	if err != nil {
+		return c.createAttachResponse(nil, pid, "", nil, fmt.Errorf("attached to process %d but failed to get its state: %v", pid, err))
+	}
+
+	c.client = client
+	c.pid = pid
+	logger.Debug("Successfully attached to process with PID: %d", pid)
+	return c.createAttachResponse(state, pid, "", nil, nil)
 }
 
 // Close terminates the debug session
@@ -258,8 +357,153 @@ func (c *Client) Close() (*types.CloseResponse, error) {
 		}, nil
 	}
 
-	// Signal to stop output capturing goroutines
+	detachErr := c.endSession(!c.keepTarget)
+	c.resetSession()
+
+	// Create debug context
+	debugContext := types.DebugContext{
+		Timestamp: time.Now(),
+		Operation: "close",
+	}
+
+	// Get exit code
+	exitCode := 0
+	if detachErr != nil {
+		exitCode = 1
+	}
+
+	// Create close response
+	response := &types.CloseResponse{
+		Status:   "success",
+		Context:  debugContext,
+		ExitCode: exitCode,
+		Summary:  fmt.Sprintf("Debug session closed with exit code %d", exitCode),
+	}
+
+	logger.Debug("Close response: %+v", response)
+	return response, detachErr
+}
+
+// Detach ends the debug session, killing the target when kill is true and otherwise
+// leaving it running without the debugger. Use DetachKillsByDefault for the safe choice.
+func (c *Client) Detach(kill bool) types.DetachResponse {
+	if c.client == nil {
+		return c.createDetachResponse(0, false, false, fmt.Errorf("no active debug session"))
+	}
+
+	attached := c.client.AttachedToExistingProcess()
+	pid := c.client.ProcessPid()
+
+	logger.Debug("Detaching from process %d, kill: %v", pid, kill)
+	err := c.endSession(kill)
+	c.resetSession()
+	if err != nil {
+		return c.createDetachResponse(pid, attached, kill, fmt.Errorf("failed to detach from process %d: %v; the session was closed anyway", pid, err))
+	}
+
+	return c.createDetachResponse(pid, attached, kill, nil)
+}
+
+// DetachKillsByDefault reports whether detaching should kill the target when the caller
+// doesn't say: programs the session launched are killed, while processes it attached to
+// and remote targets connected with keepTarget are left running
+func (c *Client) DetachKillsByDefault() bool {
+	if c.client == nil {
+		return false
+	}
+	return !c.client.AttachedToExistingProcess() && !c.keepTarget
+}
+
+// resetSession forgets everything about the ended session and removes its debug binary
+func (c *Client) resetSession() {
+	if c.target != "" {
+		gobuild.Remove(c.target)
+		c.target = ""
+	}
+	c.pid = 0
+	c.launchArgs = nil
+	c.launchEnv = nil
+	c.launchWorkingDir = ""
+	c.buildPkgs = nil
+	c.buildTest = false
+	c.remoteAddr = ""
+	c.keepTarget = false
+	c.remoteConn.Store(nil)
+	c.reconnectPolicy = ReconnectPolicy{}
+	c.remoteBreakpoints = nil
+	c.coreFile = ""
+	c.coreExecutable = ""
+	c.backend = ""
+	c.tempBreakpoints = nil
+	c.labelFilters = nil
+	c.ignoreCounts = nil
+	c.goroutinePins = nil
+	c.armedBreakpoints = nil
+	c.snapshots = nil
+	c.completedStep = nil
+	c.returnBreakpoints = nil
+	c.errorBreakpoints = nil
+	c.captureHistories = nil
+	c.hitTimes.reset()
+	c.panicBreakpoint = 0
+	c.breakOnPanic = false
+	c.breakOnFatal = false
+	c.breakOnPanicSet = false
+	c.waitReasons = nil
+	c.waitReasonsLoaded = false
+}
+
+// createDetachResponse creates a DetachResponse
+func (c *Client) createDetachResponse(pid int, attached, killed bool, err error) types.DetachResponse {
+	context := types.DebugContext{
+		Timestamp: time.Now(),
+		Operation: "detach",
+	}
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.DetachResponse{
+			Status:   "error",
+			Context:  context,
+			Pid:      pid,
+			Attached: attached,
+		}
+	}
+
+	summary := fmt.Sprintf("Detached from process %d and killed it", pid)
+	if !killed {
+		summary = fmt.Sprintf("Detached from process %d and left it running", pid)
+	}
+
+	return types.DetachResponse{
+		Status:   "success",
+		Context:  context,
+		Pid:      pid,
+		Attached: attached,
+		Killed:   killed,
+		Running:  !killed,
+		Summary:  summary,
+	}
+}
+
+// endSession detaches from the target, killing it when kill is true, and stops the
+// debug server, leaving the debug binary in place
+func (c *Client) endSession(kill bool) error {
+	// Signal to stop output capturing goroutines, and prepare a fresh channel for the next session
 	close(c.stopOutput)
+	c.stopOutput = make(chan struct{})
+
+	if c.stdin != nil {
+		c.stdin.close()
+		c.stdin = nil
+	}
+
+	c.haltAsyncRun()
+	c.asyncMutex.Lock()
+	c.async = nil
+	c.asyncMutex.Unlock()
+
+	c.processExited.Store(false)
 
 	// Create a context with timeout to prevent indefinite hanging
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -270,7 +514,7 @@ func (c *Client) Close() (*types.CloseResponse, error) {
 
 	// Attempt to detach from the debugger in a separate goroutine
 	go func() {
-		err := c.client.Detach(true)
+		err := c.client.Detach(kill)
 		if err != nil {
 			logger.Debug("Warning: Failed to detach from debugged process: %v", err)
 		}
@@ -290,12 +534,6 @@ func (c *Client) Close() (*types.CloseResponse, error) {
 	// Reset the client
 	c.client = nil
 
-	// Clean up the debug binary if it exists
-	if c.target != "" {
-		gobuild.Remove(c.target)
-		c.target = ""
-	}
-
 	// Create a new channel for server stop operations
 	stopChan := make(chan error, 1)
 
@@ -317,29 +555,9 @@ func (c *Client) Close() (*types.CloseResponse, error) {
 			logger.Debug("Warning: Server stop operation timed out after 5 seconds")
 		}
 	}
+	c.server = nil
 
-	// Create debug context
-	debugContext := types.DebugContext{
-		Timestamp: time.Now(),
-		Operation: "close",
-	}
-
-	// Get exit code
-	exitCode := 0
-	if detachErr != nil {
-		exitCode = 1
-	}
-
-	// Create close response
-	response := &types.CloseResponse{
-		Status:   "success",
-		Context:  debugContext,
-		ExitCode: exitCode,
-		Summary:  fmt.Sprintf("Debug session closed with exit code %d", exitCode),
-	}
-
-	logger.Debug("Close response: %+v", response)
-	return response, detachErr
+	return detachErr
 }
 
 // DebugSourceFile compiles and debugs a Go source file
@@ -381,6 +599,7 @@ func (c *Client) DebugSourceFile(sourceFile string, args []string) types.DebugSo
 
 	// Store the binary path for cleanup
 	c.target = debugBinary
+	c.buildPkgs = []string{absPath}
 
 	return c.createDebugSourceResponse(response.Context.DelveState, sourceFile, debugBinary, args, nil)
 }
@@ -415,26 +634,8 @@ func (c *Client) DebugTest(testFilePath string, testName string, testFlags []str
 
 	logger.Debug("Compiling test package in %s to %s", testDir, debugBinary)
 
-	// Save current directory
-	currentDir, err := os.Getwd()
-	if err != nil {
-		return c.createDebugTestResponse(nil, &response, fmt.Errorf("failed to get current directory: %v", err))
-	}
-
-	// Change to test directory
-	if err := os.Chdir(testDir); err != nil {
-		return c.createDebugTestResponse(nil, &response, fmt.Errorf("failed to change to test directory: %v", err))
-	}
-
-	// Ensure we change back to original directory
-	defer func() {
-		if err := os.Chdir(currentDir); err != nil {
-			logger.Error("Failed to restore original directory: %v", err)
-		}
-	}()
-
 	// Compile the test package with output capture using test-specific build flags
-	cmd, output, err := gobuild.GoTestBuildCombinedOutput(debugBinary, []string{testDir}, "-gcflags all=-N")
+	cmd, output, err := buildTestBinary(debugBinary, testDir)
 	response.BuildCommand = cmd
 	response.BuildOutput = string(output)
 	if err != nil {
@@ -459,8 +660,8 @@ func (c *Client) DebugTest(testFilePath string, testName string, testFlags []str
 	args = append(args, testFlags...)
 
 	logger.Debug("Launching test binary with debugger, test name: %s, args: %v", testName, args)
-	// Launch the compiled test binary with the debugger
-	response2 := c.LaunchProgram(debugBinary, args)
+	// Launch the compiled test binary with the debugger, in the package directory like go test
+	response2 := c.launchProgram(debugBinary, args, nil, testDir)
 	if response2.Context.ErrorMessage != "" {
 		gobuild.Remove(debugBinary)
 		return c.createDebugTestResponse(nil, &response, fmt.Errorf(response.Context.ErrorMessage))
@@ -468,6 +669,8 @@ func (c *Client) DebugTest(testFilePath string, testName string, testFlags []str
 
 	// Store the binary path for cleanup
 	c.target = debugBinary
+	c.buildPkgs = []string{testDir}
+	c.buildTest = true
 
 	return c.createDebugTestResponse(response2.Context.DelveState, &response, nil)
 }
@@ -481,10 +684,22 @@ func (c *Client) createLaunchResponse(state *api.DebuggerState, program string,
 		context.ErrorMessage = err.Error()
 	}
 
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+
+	pid := 0
+	if state != nil {
+		pid = state.Pid
+	}
+
 	return types.LaunchResponse{
+		Status:   status,
 		Context:  &context,
 		Program:  program,
 		Args:     args,
+		Pid:      pid,
 		ExitCode: 0,
 	}
 }
@@ -494,12 +709,14 @@ func (c *Client) createAttachResponse(state *api.DebuggerState, pid int, target
 	context := c.createDebugContext(state)
 	context.Operation = "attach"
 
+	status := "success"
 	if err != nil {
 		context.ErrorMessage = err.Error()
+		status = "error"
 	}
 
 	return types.AttachResponse{
-		Status:  "success",
+		Status:  status,
 		Context: &context,
 		Pid:     pid,
 		Target:  target,
@@ -0,0 +1,128 @@
+package debugger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetLaunchEnv(t *testing.T) {
+	const (
+		existing = "MCP_DEBUGGER_TEST_EXISTING"
+		added    = "MCP_DEBUGGER_TEST_ADDED"
+	)
+
+	// lookup describes a variable of this process's environment
+	lookup := func(key string) string {
+		value, set := os.LookupEnv(key)
+		if !set {
+			return "unset"
+		}
+		return "=" + value
+	}
+
+	testCases := []struct {
+		name     string
+		env      []string
+		err      string
+		expected map[string]string // Environment while the target launches
+	}{
+		{
+			name:     "Add and override",
+			env:      []string{added + "=1", existing + "=new"},
+			expected: map[string]string{added: "=1", existing: "=new"},
+		},
+		{
+			name:     "Value with an equals sign",
+			env:      []string{added + "=a=b"},
+			expected: map[string]string{added: "=a=b", existing: "=old"},
+		},
+		{
+			name:     "Empty value",
+			env:      []string{existing + "="},
+			expected: map[string]string{added: "unset", existing: "="},
+		},
+		{
+			name:     "Same key twice",
+			env:      []string{existing + "=first", existing + "=second"},
+			expected: map[string]string{added: "unset", existing: "=second"},
+		},
+		{
+			name: "No equals sign",
+			env:  []string{added + "=1", existing + "=new", "NOVALUE"},
+			err:  `invalid environment variable "NOVALUE", expected KEY=VALUE`,
+		},
+		{
+			name: "Empty key",
+			env:  []string{existing + "=new", "=value"},
+			err:  `invalid environment variable "=value", expected KEY=VALUE`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(existing, "old")
+			t.Setenv(added, "")
+			_ = os.Unsetenv(added)
+
+			restore, err := setLaunchEnv(tc.env)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) || restore != nil {
+					t.Fatalf("Expected error %q and no restore function, got %v", tc.err, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected the environment set, got %v", err)
+				}
+				for key, value := range tc.expected {
+					if got := lookup(key); got != value {
+						t.Errorf("Expected %s %s while launching, got %s", key, value, got)
+					}
+				}
+				restore()
+			}
+
+			// The variables set before a malformed entry are restored too
+			if got := lookup(existing); got != "=old" {
+				t.Errorf("Expected %s restored to old, got %s", existing, got)
+			}
+			if got := lookup(added); got != "unset" {
+				t.Errorf("Expected %s unset again, got %s", added, got)
+			}
+		})
+	}
+}
+
+func TestIsExecutableFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("content"), mode); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected bool
+	}{
+		{name: "Executable", path: write("server", 0755), expected: true},
+		{name: "Executable by its owner only", path: write("tool", 0700), expected: true},
+		{name: "Not executable", path: write("notes", 0644), expected: false},
+		{name: "Source file with the executable bit", path: write("main.go", 0755), expected: false},
+		{name: "Directory", path: dir, expected: false},
+		{name: "Missing", path: filepath.Join(dir, "missing"), expected: false},
+		{name: "Package path", path: "./cmd/server", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isExecutableFile(tc.path); got != tc.expected {
+				t.Errorf("isExecutableFile(%q) = %v; expected %v", tc.path, got, tc.expected)
+			}
+		})
+	}
+}
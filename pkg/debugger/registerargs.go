@@ -0,0 +1,327 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// In optimized code an argument's location changes as the function runs: it arrives in a
+// register, may be spilled to the stack, and is dropped once it is no longer needed. The
+// debug info describes this with a location expression per PC range, made of one piece per
+// word for values like strings and slices. Delve gives up on the whole value when any piece
+// is gone, so the pieces still held in registers are read here. A frame other than the
+// innermost only has the registers Delve recovers while unwinding: Go preserves none across
+// calls, so the pieces held in others are lost.
+
+// Where an argument's value came from
+const (
+	argSourceDebugInfo    = "debug_info"
+	argSourceRegister     = "register"
+	argSourceOptimizedOut = "optimized_out"
+)
+
+// How far an argument's value can be trusted
+const (
+	argConfidenceExact   = "exact"   // Read by Delve through the debug info
+	argConfidenceHigh    = "high"    // Every piece read from the registers the debug info names
+	argConfidencePartial = "partial" // Some pieces read from registers, the others gone
+	argConfidenceNone    = "none"    // Nothing left to read
+)
+
+// optimizedOut is the value shown for an argument, or a piece of one, the compiler dropped
+const optimizedOut = "<optimized out>"
+
+// locationPiece is one piece of a location expression
+type locationPiece struct {
+	register string // Register the piece is held in, "" for one in memory or gone
+	empty    bool   // The piece has no location: the compiler dropped it
+}
+
+// registerOpPattern matches a DWARF operation naming the register a value is held in, such
+// as "DW_OP_reg0(Rax)" or "DW_OP_regx 0x11 (X0)"
+var registerOpPattern = regexp.MustCompile(`^DW_OP_reg(?:\d+|x \S+ )\((\w+)\)`)
+
+// ArgsWithRegisters returns the arguments of a frame of the selected goroutine, like
+// ListArgs, and recovers the ones Delve can't read from the CPU registers the debug info
+// says they are in. Each argument is marked with where its value came from and how far it
+// can be trusted; one the compiler dropped at this point is reported as optimized out,
+// never with a made-up value.
+func (c *Client) ArgsWithRegisters(frame int) types.RegisterArgsResponse {
+	if c.client == nil {
+		return c.createRegisterArgsResponse(nil, frame, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createRegisterArgsResponse(nil, frame, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createRegisterArgsResponse(nil, frame, nil, fmt.Errorf("cannot list variables while the target is running; stop the target first"))
+	}
+
+	scope, err := c.frameScope(state, frame)
+	if err != nil {
+		return c.createRegisterArgsResponse(state, frame, nil, err)
+	}
+
+	cfg, _ := variableLoadConfig(VariableListOptions{Depth: 1})
+	args, err := c.client.ListFunctionArgs(scope, cfg)
+	if err != nil {
+		return c.createRegisterArgsResponse(state, frame, nil, fmt.Errorf("failed to list function arguments: %v", err))
+	}
+
+	// Without registers, argument pieces held in them are reported as gone
+	regs, err := c.client.ListScopeRegisters(scope, true)
+	if err != nil {
+		logger.Debug("Warning: Failed to read the registers of frame %d: %v", frame, err)
+	}
+
+	logger.Debug("Listing %d arguments of frame %d with register fallback", len(args), frame)
+
+	arguments := make([]types.RegisterArgument, 0, len(args))
+	for i := range args {
+		if args[i].Flags&api.VariableReturnArgument != 0 {
+			continue
+		}
+		arguments = append(arguments, registerArgument(&args[i], regs))
+	}
+	return c.createRegisterArgsResponse(state, frame, arguments, nil)
+}
+
+// registerArgument converts an argument, recovering what it can from regs when Delve could
+// not read it
+func registerArgument(v *api.Variable, regs api.Registers) types.RegisterArgument {
+	argument := types.RegisterArgument{
+		Name:     v.Name,
+		Type:     v.Type,
+		Location: strings.TrimSpace(v.LocationExpr),
+	}
+	if v.Unreadable == "" {
+		argument.Value = formatVariableValue(v)
+		argument.Source = argSourceDebugInfo
+		argument.Confidence = argConfidenceExact
+		return argument
+	}
+
+	pieces := parseLocationPieces(v.LocationExpr)
+	if len(pieces) == 0 {
+		argument.Value = optimizedOut
+		argument.Source = argSourceOptimizedOut
+		argument.Confidence = argConfidenceNone
+		argument.Note = "the debug info gives no location at this point: the compiler dropped the value once it was no longer needed"
+		return argument
+	}
+
+	names := pieceNames(v.Kind, len(pieces))
+	values := make([]string, len(pieces))
+	var read, inMemory int
+	for i, piece := range pieces {
+		values[i] = optimizedOut
+		switch {
+		case piece.empty:
+		case piece.register == "":
+			inMemory++
+		default:
+			reg, err := findRegister(regs, piece.register)
+			if err != nil {
+				continue
+			}
+			values[i] = formatRegisterPiece(pieceKind(v.Kind, names[i]), reg.Value)
+			argument.Registers = append(argument.Registers, reg.Name)
+			read++
+		}
+	}
+
+	if read == 0 {
+		argument.Value = optimizedOut
+		argument.Source = argSourceOptimizedOut
+		argument.Confidence = argConfidenceNone
+		argument.Note = fmt.Sprintf("Delve could not read it (%s), and none of its pieces is in a register that still holds it", v.Unreadable)
+		if inMemory > 0 {
+			argument.Note += fmt.Sprintf("; %d of them are in memory and the rest optimized out", inMemory)
+		}
+		return argument
+	}
+
+	argument.Source = argSourceRegister
+	if len(pieces) == 1 {
+		argument.Value = values[0]
+	} else {
+		fields := make([]string, len(pieces))
+		for i := range pieces {
+			fields[i] = names[i] + ": " + values[i]
+		}
+		argument.Value = "{" + strings.Join(fields, ", ") + "}"
+	}
+
+	if read == len(pieces) {
+		argument.Confidence = argConfidenceHigh
+		argument.Note = fmt.Sprintf("Delve could not read it (%s); read from %s, where the debug info says it is at this point", v.Unreadable, strings.Join(argument.Registers, ", "))
+		return argument
+	}
+	argument.Confidence = argConfidencePartial
+	argument.Note = fmt.Sprintf("only %d of its %d pieces are still in registers; the others are optimized out", read, len(pieces))
+	if inMemory > 0 {
+		argument.Note += fmt.Sprintf(", or in memory (%d) Delve could not read", inMemory)
+	}
+	return argument
+}
+
+// parseLocationPieces splits a location expression, as Delve prints it, into its pieces. A
+// value in a single place is one piece. No pieces means the value has no location at all.
+func parseLocationPieces(expr string) []locationPiece {
+	expr = strings.TrimSpace(expr)
+	// Drop the "[block] " or "[0xdb0:0x499e0e] " prefix and the " (escaped)" suffix
+	if strings.HasPrefix(expr, "[") {
+		if end := strings.Index(expr, "] "); end >= 0 {
+			expr = expr[end+2:]
+		} else {
+			expr = ""
+		}
+	}
+	expr = strings.TrimSpace(strings.TrimSuffix(expr, "(escaped)"))
+	if expr == "" {
+		return nil
+	}
+
+	parts := strings.Split(expr, "DW_OP_piece")
+	if len(parts) == 1 {
+		return []locationPiece{parseLocationPiece(expr)}
+	}
+
+	// Each DW_OP_piece closes the piece before it and is followed by that piece's size
+	pieces := make([]locationPiece, 0, len(parts)-1)
+	for i, part := range parts[:len(parts)-1] {
+		if i > 0 {
+			if fields := strings.Fields(part); len(fields) > 0 {
+				part = strings.TrimPrefix(strings.TrimSpace(part), fields[0])
+			}
+		}
+		pieces = append(pieces, parseLocationPiece(strings.TrimSpace(part)))
+	}
+	return pieces
+}
+
+// parseLocationPiece reads where a single piece of a location expression is
+func parseLocationPiece(ops string) locationPiece {
+	if ops == "" {
+		return locationPiece{empty: true}
+	}
+	if m := registerOpPattern.FindStringSubmatch(ops); m != nil {
+		return locationPiece{register: m[1]}
+	}
+	return locationPiece{}
+}
+
+// pieceNames names the pieces of a value of kind split into n words
+func pieceNames(kind reflect.Kind, n int) []string {
+	var names []string
+	switch {
+	case kind == reflect.String && n == 2:
+		names = []string{"ptr", "len"}
+	case kind == reflect.Slice && n == 3:
+		names = []string{"ptr", "len", "cap"}
+	case kind == reflect.Interface && n == 2:
+		names = []string{"type", "data"}
+	case (kind == reflect.Complex64 || kind == reflect.Complex128) && n == 2:
+		names = []string{"real", "imag"}
+	default:
+		names = make([]string, n)
+		for i := range names {
+			names[i] = fmt.Sprintf("piece%d", i)
+		}
+	}
+	return names
+}
+
+// pieceKind returns the kind of a named piece of a value of kind
+func pieceKind(kind reflect.Kind, name string) reflect.Kind {
+	switch name {
+	case "ptr", "type", "data":
+		return reflect.Uintptr
+	case "len", "cap":
+		return reflect.Int
+	case "real", "imag":
+		return reflect.Invalid
+	}
+	return kind
+}
+
+// formatRegisterPiece renders a register's contents as a value of kind. Registers are read
+// whole, so a value narrower than the register is cut down to its size first. Values that
+// aren't integers are shown as the raw register.
+func formatRegisterPiece(kind reflect.Kind, raw string) string {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 {
+		return raw
+	}
+	n, err := strconv.ParseUint(fields[0], 0, 64)
+	if err != nil {
+		return raw
+	}
+
+	switch kind {
+	case reflect.Bool:
+		return strconv.FormatBool(n&0xff != 0)
+	case reflect.Int8:
+		return strconv.FormatInt(int64(int8(n)), 10)
+	case reflect.Int16:
+		return strconv.FormatInt(int64(int16(n)), 10)
+	case reflect.Int32:
+		return strconv.FormatInt(int64(int32(n)), 10)
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(int64(n), 10)
+	case reflect.Uint8:
+		return strconv.FormatUint(uint64(uint8(n)), 10)
+	case reflect.Uint16:
+		return strconv.FormatUint(uint64(uint16(n)), 10)
+	case reflect.Uint32:
+		return strconv.FormatUint(uint64(uint32(n)), 10)
+	case reflect.Uint, reflect.Uint64:
+		return strconv.FormatUint(n, 10)
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Uintptr, reflect.Map, reflect.Chan, reflect.Func:
+		return fmt.Sprintf("%#x", n)
+	}
+	return fields[0]
+}
+
+// createRegisterArgsResponse creates a RegisterArgsResponse
+func (c *Client) createRegisterArgsResponse(state *api.DebuggerState, frame int, arguments []types.RegisterArgument, err error) types.RegisterArgsResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "args_with_registers"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.RegisterArgsResponse{
+			Status:  "error",
+			Context: context,
+			Frame:   frame,
+		}
+	}
+
+	response := types.RegisterArgsResponse{
+		Status:    "success",
+		Context:   context,
+		Frame:     frame,
+		Arguments: arguments,
+	}
+	for _, argument := range arguments {
+		switch argument.Source {
+		case argSourceRegister:
+			response.Recovered++
+		case argSourceOptimizedOut:
+			response.OptimizedOut++
+		}
+	}
+
+	response.Summary = fmt.Sprintf("%d arguments: %d read from the debug info, %d recovered from registers, %d optimized out",
+		len(arguments), len(arguments)-response.Recovered-response.OptimizedOut, response.Recovered, response.OptimizedOut)
+	return response
+}
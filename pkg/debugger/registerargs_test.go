@@ -0,0 +1,220 @@
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestParseLocationPieces(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expr     string
+		expected []locationPiece
+	}{
+		{name: "Register", expr: "[0xdb0:0x499e0e] DW_OP_reg0(Rax) ", expected: []locationPiece{{register: "Rax"}}},
+		{name: "Register by number", expr: "[block] DW_OP_regx 0x11 (X0) ", expected: []locationPiece{{register: "X0"}}},
+		{name: "Stack", expr: "[0xdb8:0x499e2b] DW_OP_fbreg 0x8 ", expected: []locationPiece{{}}},
+		{name: "Registers in pieces", expr: "[0xdc7:0x499e0e] DW_OP_reg2(Rcx) DW_OP_piece 0x8 DW_OP_reg5(Rdi) DW_OP_piece 0x8 ", expected: []locationPiece{{register: "Rcx"}, {register: "Rdi"}}},
+		{name: "Piece optimized out", expr: "[0xdc7:0x499e2b] DW_OP_piece 0x8 DW_OP_fbreg 0x18 DW_OP_piece 0x8 ", expected: []locationPiece{{empty: true}, {}}},
+		{name: "Escaped", expr: "[block] DW_OP_fbreg 0x10 (escaped)", expected: []locationPiece{{}}},
+		{name: "No location"},
+		{name: "Empty block", expr: "[block] "},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := parseLocationPieces(tc.expr); !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected %+v, got %+v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestFormatRegisterPiece(t *testing.T) {
+	testCases := []struct {
+		name     string
+		kind     reflect.Kind
+		raw      string
+		expected string
+	}{
+		{name: "Int", kind: reflect.Int, raw: "0x0000000000000005", expected: "5"},
+		{name: "Negative int", kind: reflect.Int64, raw: "0xfffffffffffffffe", expected: "-2"},
+		{name: "Int32 with garbage above it", kind: reflect.Int32, raw: "0xdeadbeefffffffff", expected: "-1"},
+		{name: "Uint8", kind: reflect.Uint8, raw: "0x0000000000000141", expected: "65"},
+		{name: "Bool", kind: reflect.Bool, raw: "0x0000000000000001", expected: "true"},
+		{name: "Pointer", kind: reflect.Ptr, raw: "0x000000c000012345", expected: "0xc000012345"},
+		{name: "Float left raw", kind: reflect.Float64, raw: "0x4000000000000000", expected: "0x4000000000000000"},
+		{name: "Not a number", kind: reflect.Int, raw: "n/a", expected: "n/a"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := formatRegisterPiece(tc.kind, tc.raw); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestRegisterArgument(t *testing.T) {
+	regs := api.Registers{
+		{Name: "Rax", Value: "0x0000000000000002"},
+		{Name: "Rcx", Value: "0x00000000004c1a20"},
+		{Name: "Rdi", Value: "0x0000000000000005"},
+	}
+
+	testCases := []struct {
+		name       string
+		variable   api.Variable
+		value      string
+		source     string
+		confidence string
+	}{
+		{
+			name:     "Read by Delve",
+			variable: api.Variable{Name: "a", Type: "int", Kind: reflect.Int, Value: "2", LocationExpr: "[0xdb0:0x499e0e] DW_OP_reg0(Rax) "},
+			value:    "2", source: argSourceDebugInfo, confidence: argConfidenceExact,
+		},
+		{
+			name:     "No location",
+			variable: api.Variable{Name: "a", Type: "int", Kind: reflect.Int, Unreadable: "could not find loclist entry at 0xdb0 for address 0x499e2b"},
+			value:    optimizedOut, source: argSourceOptimizedOut, confidence: argConfidenceNone,
+		},
+		{
+			name:     "Every piece in a register",
+			variable: api.Variable{Name: "s", Type: "string", Kind: reflect.String, Unreadable: "could not read string at 0x4c1a20 due to input/output error", LocationExpr: "[0xdc7:0x499e0e] DW_OP_reg2(Rcx) DW_OP_piece 0x8 DW_OP_reg5(Rdi) DW_OP_piece 0x8 "},
+			value:    "{ptr: 0x4c1a20, len: 5}", source: argSourceRegister, confidence: argConfidenceHigh,
+		},
+		{
+			name:     "Pointer optimized out",
+			variable: api.Variable{Name: "s", Type: "string", Kind: reflect.String, Unreadable: "could not read string at 0x0 due to input/output error", LocationExpr: "[0xdc7:0x499e2b] DW_OP_piece 0x8 DW_OP_reg5(Rdi) DW_OP_piece 0x8 "},
+			value:    "{ptr: <optimized out>, len: 5}", source: argSourceRegister, confidence: argConfidencePartial,
+		},
+		{
+			name:     "Register not recovered in an outer frame",
+			variable: api.Variable{Name: "n", Type: "int", Kind: reflect.Int, Unreadable: "register 3 not available", LocationExpr: "[0xdb8:0x499e2b] DW_OP_reg3(Rbx) "},
+			value:    optimizedOut, source: argSourceOptimizedOut, confidence: argConfidenceNone,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := registerArgument(&tc.variable, regs)
+			if result.Value != tc.value || result.Source != tc.source || result.Confidence != tc.confidence {
+				t.Errorf("Expected %q from %s with confidence %s, got %q from %s with confidence %s", tc.value, tc.source, tc.confidence, result.Value, result.Source, result.Confidence)
+			}
+			if tc.source != argSourceDebugInfo && result.Note == "" {
+				t.Errorf("Expected a note on why the debug info was not used")
+			}
+		})
+	}
+}
+
+func TestCreateRegisterArgsResponse(t *testing.T) {
+	arguments := []types.RegisterArgument{
+		{Name: "a", Source: argSourceDebugInfo},
+		{Name: "s", Source: argSourceRegister},
+		{Name: "b", Source: argSourceOptimizedOut},
+		{Name: "p", Source: argSourceOptimizedOut},
+	}
+	response := NewClient().createRegisterArgsResponse(nil, 1, arguments, nil)
+	if response.Recovered != 1 || response.OptimizedOut != 2 {
+		t.Errorf("Expected 1 recovered and 2 optimized out, got %d and %d", response.Recovered, response.OptimizedOut)
+	}
+	expected := "4 arguments: 1 read from the debug info, 1 recovered from registers, 2 optimized out"
+	if response.Summary != expected {
+		t.Errorf("Expected summary %q, got %q", expected, response.Summary)
+	}
+}
+
+// registerArgsTarget returns a fake Delve stopped two frames deep, whose frames have the
+// arguments a, read by Delve, and s, a string Delve can't read that is held in registers.
+// The frames the registers are read in are recorded.
+func registerArgsTarget(t *testing.T) (*Client, *fakeDelve, *[]int) {
+	t.Helper()
+	args := []api.Variable{
+		{Name: "a", Type: "int", Kind: reflect.Int, Value: "2", LocationExpr: "[0xdb0:0x499e0e] DW_OP_reg0(Rax) "},
+		{Name: "s", Type: "string", Kind: reflect.String, Unreadable: "could not read string at 0x4c1a20 due to input/output error", LocationExpr: "[0xdc7:0x499e0e] DW_OP_reg2(Rcx) DW_OP_piece 0x8 DW_OP_reg5(Rdi) DW_OP_piece 0x8 "},
+		{Name: "~r0", Type: "error", Kind: reflect.Interface, Flags: api.VariableReturnArgument},
+	}
+
+	var frames []int
+	c, f := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+		"Stacktrace": fakeResult(rpc2.StacktraceOut{Locations: []api.Stackframe{
+			{Location: api.Location{File: "main.go", Line: 10, Function: &api.Function{Name_: "main.parse"}}},
+			{Location: api.Location{File: "main.go", Line: 20, Function: &api.Function{Name_: "main.main"}}},
+		}}),
+		"ListFunctionArgs": fakeResult(rpc2.ListFunctionArgsOut{Args: args}),
+		"ListRegisters": func(raw json.RawMessage) (interface{}, error) {
+			var in rpc2.ListRegistersIn
+			decodeFakeArgs(t, raw, &in)
+			frames = append(frames, in.Scope.Frame)
+			return rpc2.ListRegistersOut{Regs: api.Registers{
+				{Name: "Rax", Value: "0x0000000000000002"},
+				{Name: "Rcx", Value: "0x00000000004c1a20"},
+				{Name: "Rdi", Value: "0x0000000000000005"},
+			}}, nil
+		},
+	})
+	return c, f, &frames
+}
+
+func TestArgsWithRegisters(t *testing.T) {
+	c, _, frames := registerArgsTarget(t)
+	response := c.ArgsWithRegisters(1)
+	if response.Status != "success" || response.Frame != 1 {
+		t.Fatalf("Expected the arguments of frame 1, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if !reflect.DeepEqual(*frames, []int{1}) {
+		t.Errorf("Expected the registers read in frame 1, got frames %v", *frames)
+	}
+
+	// The result is left out, and s is pieced together from Rcx and Rdi
+	if len(response.Arguments) != 2 {
+		t.Fatalf("Expected a and s without the result, got %+v", response.Arguments)
+	}
+	if a := response.Arguments[0]; a.Name != "a" || a.Value != "2" || a.Source != argSourceDebugInfo {
+		t.Errorf("Expected a read by Delve, got %+v", a)
+	}
+	s := response.Arguments[1]
+	if s.Value != "{ptr: 0x4c1a20, len: 5}" || s.Confidence != argConfidenceHigh || !reflect.DeepEqual(s.Registers, []string{"Rcx", "Rdi"}) {
+		t.Errorf("Expected s recovered from Rcx and Rdi, got %+v", s)
+	}
+	if response.Recovered != 1 || response.OptimizedOut != 0 {
+		t.Errorf("Expected 1 argument recovered, got %d recovered and %d optimized out", response.Recovered, response.OptimizedOut)
+	}
+}
+
+func TestArgsWithRegistersUnreadable(t *testing.T) {
+	c, f, _ := registerArgsTarget(t)
+	f.handle("ListRegisters", func(json.RawMessage) (interface{}, error) {
+		return nil, fmt.Errorf("registers not available")
+	})
+
+	// Without the registers, s is reported as optimized out rather than failing the call
+	response := c.ArgsWithRegisters(0)
+	if response.Status != "success" || len(response.Arguments) != 2 {
+		t.Fatalf("Expected the arguments without registers, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if s := response.Arguments[1]; s.Value != optimizedOut || s.Source != argSourceOptimizedOut {
+		t.Errorf("Expected s optimized out, got %+v", s)
+	}
+
+	response = c.ArgsWithRegisters(2)
+	if response.Status != "error" || response.Context.Operation != "args_with_registers" || !strings.Contains(response.Context.ErrorMessage, "frame 2 out of range") {
+		t.Errorf("Expected frame 2 out of range, got %+v", response)
+	}
+
+	response = NewClient().ArgsWithRegisters(0)
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "no active debug session") {
+		t.Errorf("Expected a no active debug session error, got %q", response.Context.ErrorMessage)
+	}
+}
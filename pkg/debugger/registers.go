@@ -0,0 +1,202 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// ReadRegisters returns the CPU registers of a thread, split into general-purpose and
+// floating-point registers. A threadID of 0 uses the current thread.
+func (c *Client) ReadRegisters(threadID int, includeFloating bool) types.RegistersResponse {
+	if c.client == nil {
+		return c.createRegistersResponse(nil, 0, nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, threadID, err := c.resolveThread(threadID)
+	if err != nil {
+		return c.createRegistersResponse(state, threadID, nil, nil, err)
+	}
+
+	logger.Debug("Reading registers of thread %d, floating point: %v", threadID, includeFloating)
+
+	general, err := c.client.ListThreadRegisters(threadID, false)
+	if err != nil {
+		return c.createRegistersResponse(state, threadID, nil, nil, fmt.Errorf("failed to read registers of thread %d: %v", threadID, err))
+	}
+
+	var floating []types.Register
+	if includeFloating {
+		all, err := c.client.ListThreadRegisters(threadID, true)
+		if err != nil {
+			return c.createRegistersResponse(state, threadID, nil, nil, fmt.Errorf("failed to read floating point registers of thread %d: %v", threadID, err))
+		}
+
+		// Delve only tells floating point registers apart by leaving them out of the short list
+		isGeneral := make(map[string]bool, len(general))
+		for _, reg := range general {
+			isGeneral[reg.Name] = true
+		}
+		for _, reg := range all {
+			if !isGeneral[reg.Name] {
+				floating = append(floating, convertRegister(reg))
+			}
+		}
+	}
+
+	converted := make([]types.Register, 0, len(general))
+	for _, reg := range general {
+		converted = append(converted, convertRegister(reg))
+	}
+
+	return c.createRegistersResponse(state, threadID, converted, floating, nil)
+}
+
+// SetRegister changes a register of a thread. A threadID of 0 uses the current thread.
+//
+// Delve's API has no call for writing registers, so after validating the register name
+// against the target architecture this reports that the change cannot be made.
+func (c *Client) SetRegister(threadID int, name, value string) types.SetRegisterResponse {
+	if c.client == nil {
+		return c.createSetRegisterResponse(nil, 0, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, threadID, err := c.resolveThread(threadID)
+	if err != nil {
+		return c.createSetRegisterResponse(state, threadID, nil, err)
+	}
+
+	if _, err := strconv.ParseInt(value, 0, 64); err != nil && !isUintLiteral(value) {
+		return c.createSetRegisterResponse(state, threadID, nil, fmt.Errorf("invalid register value %q: expected an integer such as 42 or 0x2a", value))
+	}
+
+	regs, err := c.client.ListThreadRegisters(threadID, true)
+	if err != nil {
+		return c.createSetRegisterResponse(state, threadID, nil, fmt.Errorf("failed to read registers of thread %d: %v", threadID, err))
+	}
+
+	reg, err := findRegister(regs, name)
+	if err != nil {
+		return c.createSetRegisterResponse(state, threadID, nil, err)
+	}
+
+	current := convertRegister(*reg)
+	return c.createSetRegisterResponse(state, threadID, &current, fmt.Errorf("cannot set register %s: writing registers is not supported by the Delve API", reg.Name))
+}
+
+// isUintLiteral reports whether s is an unsigned integer literal, possibly too large for an int64
+func isUintLiteral(s string) bool {
+	_, err := strconv.ParseUint(s, 0, 64)
+	return err == nil
+}
+
+// resolveThread returns the stopped debugger state and the thread to use, defaulting to the current thread
+func (c *Client) resolveThread(threadID int) (*api.DebuggerState, int, error) {
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return nil, threadID, fmt.Errorf("failed to get state: %v", err)
+	}
+
+	if state.Running {
+		return nil, threadID, fmt.Errorf("cannot access registers while the target is running; stop the target first")
+	}
+
+	if threadID == 0 {
+		if state.CurrentThread == nil {
+			return state, 0, fmt.Errorf("no current thread")
+		}
+		return state, state.CurrentThread.ID, nil
+	}
+
+	for _, th := range state.Threads {
+		if th.ID == threadID {
+			return state, threadID, nil
+		}
+	}
+	return state, threadID, fmt.Errorf("thread %d not found", threadID)
+}
+
+// findRegister looks up a register by case-insensitive name, listing the valid names when it is missing
+func findRegister(regs api.Registers, name string) (*api.Register, error) {
+	for i := range regs {
+		if strings.EqualFold(regs[i].Name, name) {
+			return &regs[i], nil
+		}
+	}
+
+	names := make([]string, 0, len(regs))
+	for _, reg := range regs {
+		names = append(names, reg.Name)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown register %q; available registers: %s", name, strings.Join(names, ", "))
+}
+
+// convertRegister converts a Delve register to our type, adding hex and decimal forms
+// when the value is a plain integer
+func convertRegister(reg api.Register) types.Register {
+	register := types.Register{
+		Name:  reg.Name,
+		Value: reg.Value,
+	}
+
+	// Values like Rflags carry a description after the number, e.g. "0x246\t[IF ZF PF]"
+	fields := strings.Fields(reg.Value)
+	if len(fields) == 0 {
+		return register
+	}
+	if n, err := strconv.ParseUint(fields[0], 0, 64); err == nil {
+		register.Hex = fmt.Sprintf("%#x", n)
+		register.Decimal = strconv.FormatUint(n, 10)
+	}
+
+	return register
+}
+
+// createRegistersResponse creates a RegistersResponse
+func (c *Client) createRegistersResponse(state *api.DebuggerState, threadID int, general, floating []types.Register, err error) types.RegistersResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "read_registers"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.RegistersResponse{
+			Status:   "error",
+			Context:  context,
+			ThreadID: threadID,
+		}
+	}
+
+	return types.RegistersResponse{
+		Status:   "success",
+		Context:  context,
+		ThreadID: threadID,
+		General:  general,
+		Floating: floating,
+	}
+}
+
+// createSetRegisterResponse creates a SetRegisterResponse
+func (c *Client) createSetRegisterResponse(state *api.DebuggerState, threadID int, register *types.Register, err error) types.SetRegisterResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "set_register"
+
+	response := types.SetRegisterResponse{
+		Status:   "success",
+		Context:  context,
+		ThreadID: threadID,
+	}
+	if register != nil {
+		response.Register = *register
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+	}
+
+	return response
+}
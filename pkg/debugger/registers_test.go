@@ -0,0 +1,56 @@
+package debugger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestConvertRegister(t *testing.T) {
+	testCases := []struct {
+		name    string
+		reg     api.Register
+		hex     string
+		decimal string
+	}{
+		{
+			name:    "General purpose register",
+			reg:     api.Register{Name: "Rax", Value: "0x000000000000002a"},
+			hex:     "0x2a",
+			decimal: "42",
+		},
+		{
+			name:    "Flags with description",
+			reg:     api.Register{Name: "Rflags", Value: "0x246\t[IF ZF PF]"},
+			hex:     "0x246",
+			decimal: "582",
+		},
+		{
+			name: "Vector register",
+			reg:  api.Register{Name: "XMM0", Value: "{ _ = {0x0, 0x0} }"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reg := convertRegister(tc.reg)
+			if reg.Hex != tc.hex || reg.Decimal != tc.decimal {
+				t.Errorf("Expected hex %q and decimal %q, got %q and %q", tc.hex, tc.decimal, reg.Hex, reg.Decimal)
+			}
+		})
+	}
+}
+
+func TestFindRegister(t *testing.T) {
+	regs := api.Registers{{Name: "Rip"}, {Name: "Rax"}}
+
+	if reg, err := findRegister(regs, "rax"); err != nil || reg.Name != "Rax" {
+		t.Errorf("Expected case-insensitive match for rax, got %v, %v", reg, err)
+	}
+
+	_, err := findRegister(regs, "rxa")
+	if err == nil || !strings.Contains(err.Error(), "Rax, Rip") {
+		t.Errorf("Expected error listing available registers, got %v", err)
+	}
+}
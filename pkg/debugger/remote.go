@@ -0,0 +1,336 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// remoteDialTimeout bounds how long connecting to a remote Delve server may take
+const remoteDialTimeout = 5 * time.Second
+
+// maxReconnectBackoff caps the wait between two dials while reconnecting
+const maxReconnectBackoff = 30 * time.Second
+
+// ErrRemoteConnectionLost is reported once the connection to a remote Delve server breaks
+var ErrRemoteConnectionLost = errors.New("lost connection to remote dlv")
+
+// ReconnectPolicy is how a remote session re-dials its Delve server when the connection
+// drops, e.g. when a port-forward or tunnel flaps
+type ReconnectPolicy struct {
+	Attempts int           // Dials to try before giving up, 0 to never reconnect
+	Backoff  time.Duration // Wait before the first retry, doubled after each failed one
+}
+
+// DefaultReconnectPolicy is used when connect_remote is given no policy
+var DefaultReconnectPolicy = ReconnectPolicy{Attempts: 3, Backoff: 500 * time.Millisecond}
+
+// monitoredConn flags a remote connection as lost when reading or writing fails. The flag
+// belongs to the connection, so an old one failing after a reconnect goes unnoticed.
+type monitoredConn struct {
+	net.Conn
+	lost atomic.Bool
+}
+
+func (m *monitoredConn) Read(b []byte) (int, error) {
+	n, err := m.Conn.Read(b)
+	if err != nil {
+		m.lost.Store(true)
+	}
+	return n, err
+}
+
+func (m *monitoredConn) Write(b []byte) (int, error) {
+	n, err := m.Conn.Write(b)
+	if err != nil {
+		m.lost.Store(true)
+	}
+	return n, err
+}
+
+// dialRemote connects to the Delve server at addr and returns a client for it along with
+// the debugger state
+func dialRemote(addr string) (*rpc2.RPCClient, *monitoredConn, *api.DebuggerState, error) {
+	// rpc2.NewClient exits the process when dialing fails, so dial here and hand over the connection
+	conn, err := net.DialTimeout("tcp", addr, remoteDialTimeout)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect to remote dlv at %s: %v", addr, err)
+	}
+
+	monitored := &monitoredConn{Conn: conn}
+	client := rpc2.NewClientFromConn(monitored)
+
+	// The remote target may be running, so don't wait for it to stop
+	state, err := client.GetStateNonBlocking()
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, nil, fmt.Errorf("connected to %s but failed to get debugger state, is it a Delve server? %v", addr, err)
+	}
+	return client, monitored, state, nil
+}
+
+// ConnectRemote connects to a Delve server started elsewhere with `dlv --headless`.
+// When keepTarget is true, closing the session detaches without killing the remote target.
+// If the connection drops later, Reconnect re-dials following policy.
+func (c *Client) ConnectRemote(addr string, keepTarget bool, policy ReconnectPolicy) types.RemoteConnectResponse {
+	if c.client != nil {
+		return c.createRemoteConnectResponse(nil, addr, keepTarget, fmt.Errorf("debug session already active"))
+	}
+
+	logger.Debug("Connecting to remote Delve server at %s", addr)
+
+	client, conn, state, err := dialRemote(addr)
+	if err != nil {
+		return c.createRemoteConnectResponse(nil, addr, keepTarget, err)
+	}
+
+	c.client = client
+	c.remoteConn.Store(conn)
+	c.pid = state.Pid
+	c.remoteAddr = addr
+	c.keepTarget = keepTarget
+	c.reconnectPolicy = policy
+	c.remoteBreakpoints = nil
+
+	logger.Debug("Connected to remote Delve server at %s, target PID %d", addr, state.Pid)
+	return c.createRemoteConnectResponse(state, addr, keepTarget, nil)
+}
+
+// SnapshotBreakpoints records the breakpoints of a remote session, so they can be set
+// again if the connection drops and the server comes back without them. Breakpoints can
+// only be listed while the target is stopped, so the last snapshot is kept otherwise.
+func (c *Client) SnapshotBreakpoints() {
+	if c.client == nil || c.remoteAddr == "" || c.ConnectionLost() {
+		return
+	}
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil || state.Running {
+		return
+	}
+	bps, err := c.client.ListBreakpoints(false)
+	if err != nil {
+		logger.Debug("Warning: Failed to snapshot remote breakpoints: %v", err)
+		return
+	}
+	c.remoteBreakpoints = bps
+}
+
+// Reconnect re-dials the remote Delve server of a session whose connection dropped,
+// following the session's ReconnectPolicy. A server that kept its state, as when only the
+// network flapped, is picked up as it is. One that lost the breakpoints, as when it was
+// restarted, gets them set again from the last snapshot; a running target is halted for
+// that and left stopped.
+func (c *Client) Reconnect() types.ReconnectResponse {
+	if !c.ConnectionLost() {
+		return c.createReconnectResponse(nil, 0, fmt.Errorf("the connection to remote dlv has not been lost"))
+	}
+	policy := c.reconnectPolicy
+	if policy.Attempts <= 0 {
+		return c.createReconnectResponse(nil, 0, fmt.Errorf("%v at %s and reconnecting is turned off", ErrRemoteConnectionLost, c.remoteAddr))
+	}
+
+	var client *rpc2.RPCClient
+	var conn *monitoredConn
+	var state *api.DebuggerState
+	var err error
+	backoff := policy.Backoff
+	attempts := 0
+	for attempts < policy.Attempts {
+		if attempts > 0 {
+			time.Sleep(backoff)
+			backoff = min(backoff*2, maxReconnectBackoff)
+		}
+		attempts++
+
+		logger.Debug("Reconnecting to remote Delve server at %s, attempt %d of %d", c.remoteAddr, attempts, policy.Attempts)
+		if client, conn, state, err = dialRemote(c.remoteAddr); err == nil {
+			break
+		}
+		logger.Debug("Reconnect attempt %d failed: %v", attempts, err)
+	}
+	if err != nil {
+		return c.createReconnectResponse(nil, attempts, fmt.Errorf("%v at %s and could not reconnect after %d attempts: %v", ErrRemoteConnectionLost, c.remoteAddr, attempts, err))
+	}
+
+	if old := c.remoteConn.Swap(conn); old != nil {
+		_ = old.Close()
+	}
+	c.client = client
+	newTarget := state.Pid != c.pid
+	c.pid = state.Pid
+	// A new target has not exited, whatever the previous one did
+	if newTarget {
+		c.processExited.Store(false)
+	}
+
+	logger.Debug("Reconnected to remote Delve server at %s, target PID %d", c.remoteAddr, state.Pid)
+
+	restored, failed, halted, err := c.resyncBreakpoints(state)
+	if err != nil {
+		response := c.createReconnectResponse(state, attempts, nil)
+		response.NewTarget = newTarget
+		response.Message = fmt.Sprintf("reconnected to %s, but the breakpoints could not be checked: %v; list them with list_breakpoints", c.remoteAddr, err)
+		return response
+	}
+	if halted {
+		if state, err = c.client.GetStateNonBlocking(); err != nil {
+			state = nil
+		}
+	}
+
+	response := c.createReconnectResponse(state, attempts, nil)
+	response.NewTarget = newTarget
+	response.Restored = restored
+	response.Failed = failed
+	response.Message = reconnectMessage(c.remoteAddr, attempts, restored, failed, halted)
+	return response
+}
+
+// resyncBreakpoints sets the snapshot's breakpoints again when the server has none of
+// them anymore. It reports whether the target had to be halted for that.
+func (c *Client) resyncBreakpoints(state *api.DebuggerState) ([]types.RestoredBreakpoint, []types.FailedBreakpoint, bool, error) {
+	var snapshot []*api.Breakpoint
+	for _, bp := range c.remoteBreakpoints {
+		if bp.ID > 0 && !c.tempBreakpoints[bp.ID] && bp.ID != c.panicBreakpoint {
+			snapshot = append(snapshot, bp)
+		}
+	}
+	if len(snapshot) == 0 {
+		return nil, nil, false, nil
+	}
+
+	// Breakpoints can only be listed and set while the target is stopped
+	halted := false
+	if state.Running {
+		if _, err := c.client.Halt(); err != nil {
+			return nil, nil, false, fmt.Errorf("failed to halt the target: %v", err)
+		}
+		halted = true
+	}
+
+	current, err := c.client.ListBreakpoints(false)
+	if err != nil {
+		return nil, nil, halted, fmt.Errorf("failed to list breakpoints: %v", err)
+	}
+	if keptBreakpoints(snapshot, current) {
+		return nil, nil, halted, nil
+	}
+
+	logger.Debug("Remote Delve server lost its breakpoints, setting %d again", len(snapshot))
+	restored, failed := c.restoreBreakpoints(snapshot)
+	c.tempBreakpoints = nil
+	c.panicBreakpoint = 0
+	if c.breakOnPanicSet {
+		if err := c.applyBreakOnPanic(c.breakOnPanic, c.breakOnFatal); err != nil {
+			logger.Debug("Warning: Failed to restore break-on-panic mode: %v", err)
+		}
+	}
+	c.SnapshotBreakpoints()
+	return restored, failed, halted, nil
+}
+
+// keptBreakpoints reports whether a server still has any of the snapshot's breakpoints,
+// by ID and location. A server that kept some has not lost its state; the others were
+// removed on purpose, e.g. by another client.
+func keptBreakpoints(snapshot, current []*api.Breakpoint) bool {
+	byID := make(map[int]*api.Breakpoint, len(current))
+	for _, bp := range current {
+		byID[bp.ID] = bp
+	}
+	for _, bp := range snapshot {
+		if kept := byID[bp.ID]; kept != nil && kept.File == bp.File && kept.Line == bp.Line {
+			return true
+		}
+	}
+	return false
+}
+
+// reconnectMessage summarizes a successful reconnect
+func reconnectMessage(addr string, attempts int, restored []types.RestoredBreakpoint, failed []types.FailedBreakpoint, halted bool) string {
+	message := fmt.Sprintf("reconnected to remote dlv at %s", addr)
+	if attempts > 1 {
+		message += fmt.Sprintf(" after %d attempts", attempts)
+	}
+	if len(restored) > 0 || len(failed) > 0 {
+		message += fmt.Sprintf("; the server had lost its breakpoints, %d of %d were set again", len(restored), len(restored)+len(failed))
+	}
+	if halted {
+		message += "; the target was halted to check the breakpoints, continue it to resume"
+	}
+	return message
+}
+
+// ReconnectPolicy returns how the session reconnects to its remote Delve server
+func (c *Client) ReconnectPolicy() ReconnectPolicy {
+	return c.reconnectPolicy
+}
+
+// RemoteAddress returns the address of the remote Delve server, or "" for local sessions
+func (c *Client) RemoteAddress() string {
+	return c.remoteAddr
+}
+
+// ConnectionLost reports whether the connection to a remote Delve server has broken
+func (c *Client) ConnectionLost() bool {
+	conn := c.remoteConn.Load()
+	return c.remoteAddr != "" && conn != nil && conn.lost.Load()
+}
+
+// createRemoteConnectResponse creates a RemoteConnectResponse
+func (c *Client) createRemoteConnectResponse(state *api.DebuggerState, addr string, keepTarget bool, err error) types.RemoteConnectResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "connect_remote"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.RemoteConnectResponse{
+			Status:  "error",
+			Context: context,
+			Address: addr,
+		}
+	}
+
+	return types.RemoteConnectResponse{
+		Status:     "success",
+		Context:    context,
+		Address:    addr,
+		Pid:        state.Pid,
+		KeepTarget: keepTarget,
+		Reconnect: types.Reconnect{
+			Attempts:  c.reconnectPolicy.Attempts,
+			BackoffMs: int(c.reconnectPolicy.Backoff / time.Millisecond),
+		},
+	}
+}
+
+// createReconnectResponse creates a ReconnectResponse
+func (c *Client) createReconnectResponse(state *api.DebuggerState, attempts int, err error) types.ReconnectResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "reconnect"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.ReconnectResponse{
+			Status:   "error",
+			Context:  context,
+			Address:  c.remoteAddr,
+			Attempts: attempts,
+			Message:  err.Error(),
+		}
+	}
+
+	return types.ReconnectResponse{
+		Status:   "success",
+		Context:  context,
+		Address:  c.remoteAddr,
+		Attempts: attempts,
+		Pid:      c.pid,
+	}
+}
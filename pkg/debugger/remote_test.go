@@ -0,0 +1,145 @@
+package debugger
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestConnectRemoteNotDelve(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	// Accept and hang up immediately, like a server that is not speaking Delve's protocol
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}()
+
+	client := NewClient()
+	response := client.ConnectRemote(listener.Addr().String(), false, DefaultReconnectPolicy)
+	if response.Status != "error" {
+		t.Fatalf("Expected connecting to a non-Delve server to fail, got %+v", response)
+	}
+	if client.client != nil || client.RemoteAddress() != "" {
+		t.Errorf("Expected no session after a failed connection")
+	}
+}
+
+func TestConnectRemoteUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	response := NewClient().ConnectRemote(addr, false, DefaultReconnectPolicy)
+	if response.Status != "error" {
+		t.Errorf("Expected connecting to a closed port to fail, got %+v", response)
+	}
+}
+
+// lostRemoteClient returns a client whose connection to the remote server at addr broke
+func lostRemoteClient(addr string, policy ReconnectPolicy) *Client {
+	client := NewClient()
+	conn := &monitoredConn{}
+	conn.lost.Store(true)
+	client.remoteConn.Store(conn)
+	client.remoteAddr = addr
+	client.reconnectPolicy = policy
+	return client
+}
+
+func TestReconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	testCases := []struct {
+		name            string
+		client          *Client
+		expectedAttempt int
+		expectedError   string
+	}{
+		{name: "Connection not lost", client: NewClient(), expectedError: "has not been lost"},
+		{name: "Reconnecting turned off", client: lostRemoteClient(addr, ReconnectPolicy{}), expectedError: "reconnecting is turned off"},
+		{name: "Server gone", client: lostRemoteClient(addr, ReconnectPolicy{Attempts: 3, Backoff: time.Millisecond}), expectedAttempt: 3, expectedError: "could not reconnect after 3 attempts"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := tc.client.Reconnect()
+			if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, tc.expectedError) {
+				t.Errorf("Expected an error containing %q, got %q", tc.expectedError, response.Context.ErrorMessage)
+			}
+			if response.Attempts != tc.expectedAttempt {
+				t.Errorf("Expected %d attempts, got %d", tc.expectedAttempt, response.Attempts)
+			}
+			if response.Context.Operation != "reconnect" {
+				t.Errorf("Expected operation reconnect, got %q", response.Context.Operation)
+			}
+		})
+	}
+}
+
+func TestKeptBreakpoints(t *testing.T) {
+	snapshot := []*api.Breakpoint{{ID: 1, File: "/app/main.go", Line: 12}, {ID: 2, File: "/app/main.go", Line: 20}}
+
+	testCases := []struct {
+		name     string
+		current  []*api.Breakpoint
+		expected bool
+	}{
+		{name: "All kept", current: snapshot, expected: true},
+		{name: "One removed by another client", current: []*api.Breakpoint{{ID: 2, File: "/app/main.go", Line: 20}}, expected: true},
+		{name: "Server restarted", expected: false},
+		{name: "Same ID elsewhere on a new server", current: []*api.Breakpoint{{ID: 1, File: "/app/other.go", Line: 5}}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := keptBreakpoints(snapshot, tc.current); result != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestReconnectMessage(t *testing.T) {
+	restored := []types.RestoredBreakpoint{{PreviousID: 1}}
+	failed := []types.FailedBreakpoint{{Reason: "could not find statement"}}
+
+	testCases := []struct {
+		name     string
+		attempts int
+		restored []types.RestoredBreakpoint
+		failed   []types.FailedBreakpoint
+		halted   bool
+		expected string
+	}{
+		{name: "First attempt", attempts: 1, expected: "reconnected to remote dlv at :4040"},
+		{name: "After retries", attempts: 3, expected: "reconnected to remote dlv at :4040 after 3 attempts"},
+		{name: "Breakpoints set again", attempts: 1, restored: restored, failed: failed, halted: true, expected: "reconnected to remote dlv at :4040; the server had lost its breakpoints, 1 of 2 were set again; the target was halted to check the breakpoints, continue it to resume"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := reconnectMessage(":4040", tc.attempts, tc.restored, tc.failed, tc.halted); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
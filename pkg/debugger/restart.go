@@ -0,0 +1,298 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/pkg/gobuild"
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// Restart relaunches the debugged program and re-applies its breakpoints.
+// When rebuild is true the debug binary is rebuilt from source first.
+//
+// Delve's own Restart refuses to restart a target whose output is redirected
+// to pipes, which is how output is captured here, so the session is torn down
+// and launched again with the stored arguments, environment and working
+// directory instead: the original ones unless SetLaunchArgs or SetLaunchEnv changed them.
+func (c *Client) Restart(rebuild bool) types.RestartResponse {
+	if c.client == nil {
+		return c.createRestartResponse(nil, fmt.Errorf("no active debug session"))
+	}
+	if c.target == "" {
+		return c.createRestartResponse(nil, fmt.Errorf("restart is only supported for launched programs, not attached processes"))
+	}
+	if rebuild && len(c.buildPkgs) == 0 {
+		return c.createRestartResponse(nil, fmt.Errorf("cannot rebuild: the session was launched from a prebuilt binary"))
+	}
+
+	// Breakpoints can only be listed while the target is stopped
+	state, err := c.client.GetStateNonBlocking()
+	if err == nil && state.Running {
+		if _, err := c.client.Halt(); err != nil {
+			return c.createRestartResponse(nil, fmt.Errorf("failed to halt program: %v", err))
+		}
+	}
+
+	bps, err := c.client.ListBreakpoints(false)
+	if err != nil {
+		return c.createRestartResponse(nil, fmt.Errorf("failed to list breakpoints: %v", err))
+	}
+
+	// Build into a fresh binary so a compile error leaves the current session intact
+	var buildOutput string
+	binary := c.target
+	if rebuild {
+		binary, buildOutput, err = c.rebuildTarget()
+		if err != nil {
+			response := c.createRestartResponse(nil, err)
+			response.BuildOutput = buildOutput
+			return response
+		}
+	}
+
+	args, env, workingDir := c.launchArgs, c.launchEnv, c.launchWorkingDir
+	buildPkgs, buildTest := c.buildPkgs, c.buildTest
+
+	logger.Debug("Restarting %s with args %v", binary, args)
+	_ = c.endSession(true)
+	if binary != c.target {
+		gobuild.Remove(c.target)
+	}
+
+	launch := c.launchProgram(binary, args, env, workingDir)
+	if launch.Context.ErrorMessage != "" {
+		gobuild.Remove(binary)
+		c.target = ""
+		response := c.createRestartResponse(nil, fmt.Errorf("failed to relaunch program: %s", launch.Context.ErrorMessage))
+		response.BuildOutput = buildOutput
+		return response
+	}
+	c.buildPkgs = buildPkgs
+	c.buildTest = buildTest
+
+	// A rebuilt binary may have been built with another Go release
+	c.waitReasons = nil
+	c.waitReasonsLoaded = false
+
+	restored, failed := c.restoreBreakpoints(bps)
+	c.tempBreakpoints = nil
+
+	// The new process starts with Delve's default panic breakpoints
+	c.panicBreakpoint = 0
+	if c.breakOnPanicSet {
+		if err := c.applyBreakOnPanic(c.breakOnPanic, c.breakOnFatal); err != nil {
+			logger.Debug("Warning: Failed to restore break-on-panic mode: %v", err)
+		}
+	}
+
+	state, err = c.client.GetState()
+	if err != nil {
+		return c.createRestartResponse(nil, fmt.Errorf("failed to get state after restart: %v", err))
+	}
+
+	response := c.createRestartResponse(state, nil)
+	response.Rebuilt = rebuild
+	response.BuildOutput = buildOutput
+	response.Restored = restored
+	response.Failed = failed
+	return response
+}
+
+// rebuildTarget builds the current session's sources into a new debug binary
+func (c *Client) rebuildTarget() (string, string, error) {
+	if c.buildTest {
+		debugBinary := gobuild.DefaultDebugBinaryPath("debug.test")
+
+		logger.Debug("Rebuilding test package %v to %s", c.buildPkgs, debugBinary)
+		_, output, err := buildTestBinary(debugBinary, c.buildPkgs[0])
+		if err != nil {
+			gobuild.Remove(debugBinary)
+			return "", string(output), fmt.Errorf("failed to rebuild test package: %v\nOutput: %s", err, string(output))
+		}
+		return debugBinary, string(output), nil
+	}
+
+	debugBinary := gobuild.DefaultDebugBinaryPath("debug_binary")
+
+	logger.Debug("Rebuilding %v to %s", c.buildPkgs, debugBinary)
+	_, output, err := gobuild.GoBuildCombinedOutput(debugBinary, c.buildPkgs, "-gcflags all=-N")
+	if err != nil {
+		gobuild.Remove(debugBinary)
+		return "", string(output), fmt.Errorf("failed to rebuild: %v\nOutput: %s", err, string(output))
+	}
+	return debugBinary, string(output), nil
+}
+
+// restoreBreakpoints re-creates user breakpoints from a previous session, keeping
+// their conditions, hit conditions and enabled state
+func (c *Client) restoreBreakpoints(bps []*api.Breakpoint) ([]types.RestoredBreakpoint, []types.FailedBreakpoint) {
+	var restored []types.RestoredBreakpoint
+	var failed []types.FailedBreakpoint
+
+	// Label filters, ignore counts, goroutine pins, return, error and armed breakpoints are
+	// keyed by the old IDs
+	filters := c.labelFilters
+	c.labelFilters = nil
+	ignoreCounts := c.ignoreCounts
+	c.ignoreCounts = nil
+	pins := c.goroutinePins
+	c.goroutinePins = nil
+	returnBreakpoints := c.returnBreakpoints
+	c.returnBreakpoints = nil
+	errorBreakpoints := c.errorBreakpoints
+	c.errorBreakpoints = nil
+	armedBreakpoints := c.armedBreakpoints
+	c.armedBreakpoints = nil
+	newIDs := make(map[int]int)
+
+	// Values captured in the old run would be compared with hits of whichever breakpoint
+	// gets the same ID in the new one, and hits would be timed against them
+	c.captureHistories = nil
+	c.hitTimes.reset()
+
+	for _, bp := range bps {
+		// Negative IDs are Delve's internal breakpoints, e.g. for unrecovered panics, and
+		// temporary breakpoints from ContinueToLine belong to the old run. The
+		// break-on-panic breakpoint is set up again separately.
+		if bp.ID <= 0 || c.tempBreakpoints[bp.ID] || bp.ID == c.panicBreakpoint {
+			continue
+		}
+
+		// Watchpoints are bound to a stack frame of the old process
+		if bp.WatchExpr != "" {
+			failed = append(failed, types.FailedBreakpoint{
+				Breakpoint: convertBreakpoint(bp),
+				Reason:     "watchpoints cannot be restored across restarts",
+			})
+			continue
+		}
+
+		spec := breakpointSpec(bp)
+		// Goroutine IDs of the old run mean nothing in the new one, so a pinned breakpoint
+		// gets its own condition back and pins itself again
+		pin := pins[bp.ID]
+		if pin != nil {
+			spec.Cond = pin.condition
+		}
+		// The returns are looked up again, as the addresses change when the code does
+		var returnOf string
+		if ret := returnBreakpoints[bp.ID]; ret != nil {
+			returnOf = ret.function
+		}
+		if returnOf != "" {
+			if err := c.atReturns(spec, returnOf); err != nil {
+				failed = append(failed, types.FailedBreakpoint{
+					Breakpoint: convertBreakpoint(bp),
+					Reason:     err.Error(),
+				})
+				continue
+			}
+		}
+
+		newBP, err := c.client.CreateBreakpoint(spec)
+		if err != nil {
+			failed = append(failed, types.FailedBreakpoint{
+				Breakpoint: convertBreakpoint(bp),
+				Reason:     err.Error(),
+			})
+			continue
+		}
+
+		// The new run has not hit the triggers yet, so armed breakpoints wait for them again
+		if bp.Disabled || armedBreakpoints[bp.ID] != nil {
+			newBP.Disabled = true
+			if err := c.client.AmendBreakpoint(newBP); err != nil {
+				logger.Debug("Warning: Failed to disable restored breakpoint %d: %v", newBP.ID, err)
+			}
+		}
+
+		if filter := filters[bp.ID]; filter != nil {
+			c.setLabelFilter(newBP.ID, filter.label)
+		}
+		if ignore := ignoreCounts[bp.ID]; ignore != nil {
+			c.setIgnoreCount(newBP.ID, ignore.count)
+		}
+		if pin != nil {
+			c.setGoroutinePin(newBP.ID, pin.condition)
+		}
+		if returnOf != "" {
+			c.setReturnBreakpoint(newBP, returnOf)
+		}
+		if eb := errorBreakpoints[bp.ID]; eb != nil {
+			c.setErrorBreakpoint(newBP.ID, eb.match, eb.re)
+		}
+
+		newIDs[bp.ID] = newBP.ID
+
+		breakpoint := convertBreakpoint(newBP)
+		c.annotateBreakpoint(&breakpoint)
+		restored = append(restored, types.RestoredBreakpoint{
+			PreviousID: bp.ID,
+			Breakpoint: breakpoint,
+		})
+	}
+
+	// Arming is restored once the triggers have their new IDs too, and annotated on the
+	// restored breakpoints it applies to
+	for oldID, armed := range armedBreakpoints {
+		id, trigger := newIDs[oldID], newIDs[armed.trigger]
+		if id == 0 || trigger == 0 {
+			logger.Debug("Breakpoint %d is no longer armed, as it or its trigger %d was not restored", oldID, armed.trigger)
+			continue
+		}
+		c.setArmedBreakpoint(id, trigger, armed.oneShot)
+	}
+	for i := range restored {
+		c.annotateArmedBreakpoint(&restored[i].Breakpoint)
+	}
+
+	return restored, failed
+}
+
+// breakpointSpec returns a request for a new breakpoint with the location and settings of
+// an existing one, but none of its hit counts
+func breakpointSpec(bp *api.Breakpoint) *api.Breakpoint {
+	spec := &api.Breakpoint{
+		Name:        bp.Name,
+		File:        bp.File,
+		Line:        bp.Line,
+		Cond:        bp.Cond,
+		HitCond:     bp.HitCond,
+		HitCondPerG: bp.HitCondPerG,
+		Tracepoint:  bp.Tracepoint,
+		TraceReturn: bp.TraceReturn,
+		Goroutine:   bp.Goroutine,
+		Stacktrace:  bp.Stacktrace,
+		Variables:   bp.Variables,
+		LoadArgs:    bp.LoadArgs,
+		LoadLocals:  bp.LoadLocals,
+	}
+	if bp.File == "" {
+		spec.FunctionName = bp.FunctionName
+	}
+	return spec
+}
+
+// createRestartResponse creates a RestartResponse
+func (c *Client) createRestartResponse(state *api.DebuggerState, err error) types.RestartResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "restart"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.RestartResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	return types.RestartResponse{
+		Status:  "success",
+		Context: context,
+		Pid:     state.Pid,
+		Args:    c.launchArgs,
+		Env:     c.launchEnv,
+	}
+}
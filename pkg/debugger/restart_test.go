@@ -0,0 +1,235 @@
+package debugger
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestBreakpointSpec(t *testing.T) {
+	bp := &api.Breakpoint{
+		ID:            4,
+		Name:          "retry",
+		File:          "main.go",
+		Line:          12,
+		FunctionName:  "main.retry",
+		Addrs:         []uint64{0x4a10},
+		Cond:          "n > 3",
+		HitCond:       "% 2",
+		HitCondPerG:   true,
+		Tracepoint:    true,
+		Variables:     []string{"n"},
+		LoadArgs:      &api.LoadConfig{MaxStringLen: 64},
+		TotalHitCount: 9,
+		HitCount:      map[string]uint64{"1": 9},
+		Disabled:      true,
+	}
+
+	expected := &api.Breakpoint{
+		Name:        "retry",
+		File:        "main.go",
+		Line:        12,
+		Cond:        "n > 3",
+		HitCond:     "% 2",
+		HitCondPerG: true,
+		Tracepoint:  true,
+		Variables:   []string{"n"},
+		LoadArgs:    &api.LoadConfig{MaxStringLen: 64},
+	}
+	if spec := breakpointSpec(bp); !reflect.DeepEqual(spec, expected) {
+		t.Errorf("Expected the location and settings without the ID, addresses or hits, got %+v", spec)
+	}
+
+	// A breakpoint on a function is set on the function again
+	fn := breakpointSpec(&api.Breakpoint{ID: 5, FunctionName: "main.load", Addrs: []uint64{0x4b00}})
+	if fn.FunctionName != "main.load" || fn.Addrs != nil {
+		t.Errorf("Expected a breakpoint on main.load, got %+v", fn)
+	}
+}
+
+// restartedTarget returns a fake Delve for a relaunched target with no user breakpoints,
+// whose next breakpoint gets ID 21, and where main.load returns at two addresses and
+// main.spin never does
+func restartedTarget(t *testing.T) (*Client, *fakeBreakpoints) {
+	t.Helper()
+	bps := &fakeBreakpoints{lastID: 20, functions: map[string]string{
+		"main.go:10": "main.main",
+		"main.go:20": "main.worker",
+		"main.go:30": "main.main",
+	}}
+	addrs := map[string][]uint64{"main.load": {0x4c10, 0x4c48}}
+	c, _ := newFakeDelve(t, bps.serve(t, map[string]fakeHandler{
+		"FunctionReturnLocations": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.FunctionReturnLocationsIn
+			decodeFakeArgs(t, raw, &args)
+			return rpc2.FunctionReturnLocationsOut{Addrs: addrs[args.FnName]}, nil
+		},
+		"Disassemble": fakeResult(rpc2.DisassembleOut{}),
+	}))
+	return c, bps
+}
+
+func TestRestoreBreakpoints(t *testing.T) {
+	c, bps := restartedTarget(t)
+
+	// The breakpoints of the old run, and what this side kept about them by their IDs
+	old := []*api.Breakpoint{
+		{ID: 1, File: "main.go", Line: 10, FunctionName: "main.main", Cond: "n > 0", HitCond: ">= 2", TotalHitCount: 5},
+		{ID: 2, File: "main.go", Line: 20, FunctionName: "main.worker", Cond: pinnedCondition("job != nil", 7)},
+		{ID: 3, FunctionName: "main.load", Addrs: []uint64{0x4a10, 0x4a48}},
+		{ID: 4, File: "main.go", Line: 30, FunctionName: "main.main", Disabled: true},
+		{ID: 5, File: "main.go", Line: 10, FunctionName: "main.main", Disabled: true},
+		{ID: 6, File: "main.go", Line: 14, FunctionName: "main.main"},
+		{ID: 7, FunctionName: "runtime.gopanic", Addrs: []uint64{0x1000}},
+		{ID: -1, FunctionName: "runtime.fatalpanic", Addrs: []uint64{0x2000}},
+	}
+	c.setLabelFilter(1, "job=sync")
+	c.setIgnoreCount(1, 3)
+	c.ignoreCounts[1].remaining = 1
+	c.setGoroutinePin(2, "job != nil")
+	c.goroutinePins[2].goroutineID = 7
+	c.setReturnBreakpoint(old[2], "main.load")
+	c.setErrorBreakpoint(3, "missing", regexp.MustCompile("missing"))
+	c.setArmedBreakpoint(4, 1, true)
+	c.armedBreakpoints[4].armed = true
+	c.setArmedBreakpoint(1, 3, false)
+	c.tempBreakpoints = map[int]bool{6: true}
+	c.panicBreakpoint = 7
+	c.captureHistories = map[int][]*captureHit{1: {{hit: 5}}}
+	c.hitTimes.hits = map[int][]timedHit{1: make([]timedHit, 5)}
+
+	restored, failed := c.restoreBreakpoints(old)
+	if len(failed) != 0 {
+		t.Fatalf("Expected every breakpoint restored, got failures %+v", failed)
+	}
+
+	// The temporary, panic and internal breakpoints belong to the old run
+	newIDs := make(map[int]int)
+	byID := make(map[int]types.Breakpoint)
+	for _, r := range restored {
+		newIDs[r.PreviousID] = r.Breakpoint.ID
+		byID[r.Breakpoint.ID] = r.Breakpoint
+	}
+	if expected := map[int]int{1: 21, 2: 22, 3: 23, 4: 24, 5: 25}; !reflect.DeepEqual(newIDs, expected) {
+		t.Fatalf("Expected old IDs mapped to %v, got %v", expected, newIDs)
+	}
+
+	first := bps.get(21)
+	if first.Cond != "n > 0" || first.HitCond != ">= 2" || first.TotalHitCount != 0 {
+		t.Errorf("Expected breakpoint 21 with the conditions of 1 and no hits, got %+v", first)
+	}
+	if filter := c.labelFilters[21]; filter == nil || filter.label != "job=sync" || c.labelFilters[1] != nil {
+		t.Errorf("Expected the label filter moved to 21, got %v", c.labelFilters)
+	}
+	// The ignore count starts over
+	if ignore := c.ignoreCounts[21]; ignore == nil || ignore.count != 3 || ignore.remaining != 3 || c.ignoreCounts[1] != nil {
+		t.Errorf("Expected an ignore count of 3 on 21, got %v", c.ignoreCounts)
+	}
+
+	// The pinned breakpoint gets its own condition back and pins itself again
+	if pinned := bps.get(22); pinned.Cond != "job != nil" {
+		t.Errorf("Expected breakpoint 22 unpinned, got condition %q", pinned.Cond)
+	}
+	if pin := c.goroutinePins[22]; pin == nil || pin.goroutineID != 0 || pin.condition != "job != nil" || c.goroutinePins[2] != nil {
+		t.Errorf("Expected the pin moved to 22 and waiting for a first hit, got %v", c.goroutinePins)
+	}
+
+	// The returns are set at the addresses of the new run
+	if ret := bps.get(23); !reflect.DeepEqual(ret.Addrs, []uint64{0x4c10, 0x4c48}) || ret.FunctionName != "" {
+		t.Errorf("Expected breakpoint 23 on the new returns of main.load, got %+v", ret)
+	}
+	if ret := c.returnBreakpoints[23]; ret == nil || ret.function != "main.load" || ret.sites != 2 || c.returnBreakpoints[3] != nil {
+		t.Errorf("Expected 23 to stop at the 2 returns of main.load, got %v", c.returnBreakpoints)
+	}
+	if eb := c.errorBreakpoints[23]; eb == nil || eb.match != "missing" || c.errorBreakpoints[3] != nil {
+		t.Errorf("Expected 23 to stop on errors matching missing, got %v", c.errorBreakpoints)
+	}
+	if !byID[23].OnError || byID[23].ReturnOf != "main.load" {
+		t.Errorf("Expected the restored breakpoint annotated as break-on-error, got %+v", byID[23])
+	}
+
+	// Arming moves to the new IDs of both breakpoints, and waits for the trigger again
+	if armed := c.armedBreakpoints[24]; armed == nil || armed.trigger != 21 || !armed.oneShot || armed.armed {
+		t.Errorf("Expected 24 armed after 21 again, got %+v", armed)
+	}
+	if armed := c.armedBreakpoints[21]; armed == nil || armed.trigger != 23 {
+		t.Errorf("Expected 21 armed after 23, got %+v", armed)
+	}
+	// A trigger that is armed itself is disabled until its own trigger is hit
+	if !first.Disabled {
+		t.Errorf("Expected breakpoint 21 disabled until 23 is hit, got %+v", first)
+	}
+	if !bps.get(24).Disabled || byID[24].ArmedAfter != 21 || !byID[24].OneShot {
+		t.Errorf("Expected breakpoint 24 disabled and annotated as armed after 21, got %+v", byID[24])
+	}
+	if len(c.armedBreakpoints) != 2 {
+		t.Errorf("Expected only 21 and 24 armed, got %v", c.armedBreakpointIDs())
+	}
+
+	// A disabled breakpoint stays disabled
+	if !bps.get(25).Disabled {
+		t.Errorf("Expected breakpoint 25 disabled like 5, got %+v", bps.get(25))
+	}
+
+	if c.captureHistories != nil || len(c.hitTimes.get(1)) != 0 || len(c.hitTimes.get(21)) != 0 {
+		t.Errorf("Expected the captures and hit times of the old run dropped, got %v and %v", c.captureHistories, c.hitTimes.hits)
+	}
+}
+
+func TestRestoreBreakpointsFailures(t *testing.T) {
+	c, bps := restartedTarget(t)
+
+	old := []*api.Breakpoint{
+		{ID: 1, File: "main.go", Line: 10, FunctionName: "main.main"},
+		{ID: 2, WatchExpr: "total", WatchType: api.WatchWrite, Addrs: []uint64{0xc000010000}},
+		{ID: 3, File: "main.go", Line: 99, FunctionName: "main.main"},
+		{ID: 4, FunctionName: "main.spin", Addrs: []uint64{0x4d00}},
+		{ID: 5, File: "main.go", Line: 30, FunctionName: "main.main", Disabled: true},
+	}
+	c.setReturnBreakpoint(old[3], "main.spin")
+	c.setErrorBreakpoint(4, "", nil)
+	c.setArmedBreakpoint(5, 3, false)
+	c.setIgnoreCount(3, 2)
+
+	restored, failed := c.restoreBreakpoints(old)
+
+	reasons := make(map[int]string)
+	for _, f := range failed {
+		reasons[f.Breakpoint.ID] = f.Reason
+	}
+	expected := map[int]string{
+		2: "watchpoints cannot be restored",
+		3: "could not find statement at main.go:99",
+		4: "function main.spin has no return points",
+	}
+	if len(reasons) != len(expected) {
+		t.Errorf("Expected failures for %v, got %v", expected, reasons)
+	}
+	for id, reason := range expected {
+		if !strings.Contains(reasons[id], reason) {
+			t.Errorf("Expected breakpoint %d to fail with %q, got %q", id, reason, reasons[id])
+		}
+	}
+
+	// The failed breakpoints leave nothing behind
+	if len(c.ignoreCounts) != 0 || len(c.returnBreakpoints) != 0 || len(c.errorBreakpoints) != 0 {
+		t.Errorf("Expected nothing kept for the failed breakpoints, got %v, %v and %v", c.ignoreCounts, c.returnBreakpoints, c.errorBreakpoints)
+	}
+
+	// Breakpoint 5 is restored, but not armed, as its trigger failed
+	if len(restored) != 2 || restored[0].Breakpoint.ID != 21 || restored[1].PreviousID != 5 || restored[1].Breakpoint.ID != 22 {
+		t.Fatalf("Expected breakpoints 1 and 5 restored as 21 and 22, got %+v", restored)
+	}
+	if len(c.armedBreakpoints) != 0 || restored[1].Breakpoint.ArmedAfter != 0 {
+		t.Errorf("Expected no breakpoint armed without its trigger, got %v", c.armedBreakpointIDs())
+	}
+	if bps.lastID != 22 {
+		t.Errorf("Expected only the two restored breakpoints created, got last ID %d", bps.lastID)
+	}
+}
@@ -0,0 +1,108 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// A breakpoint set with OnReturn stops at every return instruction of a function, as one
+// breakpoint with an address per instruction. Go has no single epilogue: each return
+// statement may get its own, and a deferred call that recovers a panic returns through
+// yet another, so all of them are covered. It stops once deferred calls have run, so the
+// results are final. Delve knows such a breakpoint only by its addresses, so the function
+// is remembered here to look the returns up again when the breakpoint is re-created, and
+// to report the function's results when it stops.
+
+// returnBreakpoint is the function a breakpoint stops at the returns of
+type returnBreakpoint struct {
+	function string
+	sites    int // Return instructions the breakpoint is set on
+}
+
+// functionAt returns the name of the function containing file:line
+func (c *Client) functionAt(file string, line int) (string, error) {
+	pos, _, err := c.ParseLocation(fmt.Sprintf("%s:%d", file, line))
+	if err != nil {
+		return "", err
+	}
+	if pos.Function == "" {
+		return "", fmt.Errorf("%s:%d is not in a function", file, line)
+	}
+	return pos.Function, nil
+}
+
+// atReturns points a breakpoint request at the return instructions of function, in place
+// of its file and line, and has the arguments loaded so the results can be reported
+func (c *Client) atReturns(request *api.Breakpoint, function string) error {
+	addrs, err := c.client.FunctionReturnLocations(function)
+	if err != nil {
+		return fmt.Errorf("failed to find return points of %s: %v", function, err)
+	}
+	addrs = c.returnInstructions(addrs)
+	if len(addrs) == 0 {
+		return fmt.Errorf("function %s has no return points; it may never return, e.g. loop forever or only panic", function)
+	}
+
+	loadArgs := returnValueLoadConfig
+	request.File, request.Line, request.FunctionName = "", 0, ""
+	request.Addrs = addrs
+	request.LoadArgs = &loadArgs
+	return nil
+}
+
+// returnInstructions leaves out the addresses of calls to runtime.deferreturn, which Delve
+// counts as return points too: a function with defers calls it ahead of each return, before
+// its deferred calls have run and possibly changed the results
+func (c *Client) returnInstructions(addrs []uint64) []uint64 {
+	var returns []uint64
+	for _, addr := range addrs {
+		if inst := c.asmInstructionAt(addr); inst != nil && isCallInstruction(inst.Text) {
+			continue
+		}
+		returns = append(returns, addr)
+	}
+	return returns
+}
+
+// setReturnBreakpoint records that a breakpoint stops at the returns of function
+func (c *Client) setReturnBreakpoint(bp *api.Breakpoint, function string) {
+	if c.returnBreakpoints == nil {
+		c.returnBreakpoints = make(map[int]*returnBreakpoint)
+	}
+	c.returnBreakpoints[bp.ID] = &returnBreakpoint{function: function, sites: len(bp.Addrs)}
+}
+
+// returnBreakpointFunction returns the function a breakpoint stops at the returns of, or ""
+// for a breakpoint on a line
+func (c *Client) returnBreakpointFunction(id int) string {
+	if ret := c.returnBreakpoints[id]; ret != nil {
+		return ret.function
+	}
+	return ""
+}
+
+// annotateReturnBreakpoint adds the function a breakpoint stops at the returns of, if any
+func (c *Client) annotateReturnBreakpoint(breakpoint *types.Breakpoint) {
+	ret := c.returnBreakpoints[breakpoint.ID]
+	if ret == nil {
+		return
+	}
+	breakpoint.ReturnOf = ret.function
+	breakpoint.ReturnSites = ret.sites
+
+	// Delve gives a breakpoint on several lines no single location
+	location := fmt.Sprintf("At the returns of %s", ret.function)
+	breakpoint.Location = &location
+	breakpoint.Position = nil
+}
+
+// returnBreakpointValues returns the results of the function a thread is about to return
+// from, when it stopped at a breakpoint on the function's returns
+func (c *Client) returnBreakpointValues(th *api.Thread) []types.Variable {
+	if th == nil || th.Breakpoint == nil || c.returnBreakpoints[th.Breakpoint.ID] == nil {
+		return nil
+	}
+	return convertReturnValues(returnValues(th))
+}
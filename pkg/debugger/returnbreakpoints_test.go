@@ -0,0 +1,86 @@
+package debugger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestAnnotateReturnBreakpoint(t *testing.T) {
+	c := NewClient()
+	c.setReturnBreakpoint(&api.Breakpoint{ID: 3, Addrs: []uint64{0x4bb571, 0x4bb5ad, 0x4bb5dc}}, "main.classify")
+
+	location := "At <multiple locations>:0 in main.classify"
+	breakpoint := types.Breakpoint{ID: 3, Location: &location, Position: &types.SourcePosition{File: "<multiple locations>", Function: "main.classify"}}
+	c.annotateBreakpoint(&breakpoint)
+
+	if breakpoint.ReturnOf != "main.classify" || breakpoint.ReturnSites != 3 {
+		t.Errorf("Expected the returns of main.classify on 3 sites, got %q on %d", breakpoint.ReturnOf, breakpoint.ReturnSites)
+	}
+	if breakpoint.Location == nil || *breakpoint.Location != "At the returns of main.classify" {
+		t.Errorf("Expected the location to name the function, got %v", breakpoint.Location)
+	}
+	if breakpoint.Position != nil {
+		t.Errorf("Expected no single position, got %+v", breakpoint.Position)
+	}
+
+	line := types.Breakpoint{ID: 4, Location: &location}
+	c.annotateBreakpoint(&line)
+	if line.ReturnOf != "" || *line.Location != location {
+		t.Errorf("Expected a breakpoint on a line to be left alone, got %q at %q", line.ReturnOf, *line.Location)
+	}
+}
+
+func TestReturnBreakpointValues(t *testing.T) {
+	c := NewClient()
+	c.setReturnBreakpoint(&api.Breakpoint{ID: 1, Addrs: []uint64{0x4bb6b9}}, "main.safeDiv")
+
+	info := &api.BreakpointInfo{Arguments: []api.Variable{
+		{Name: "a", Type: "int", Kind: reflect.Int, Value: "6"},
+		{Name: "q", Type: "int", Kind: reflect.Int, Value: "2", Flags: api.VariableReturnArgument},
+		{Name: "~r1", Type: "bool", Kind: reflect.Bool, Value: "true", Flags: api.VariableReturnArgument},
+	}}
+
+	testCases := []struct {
+		name     string
+		thread   *api.Thread
+		expected []string
+	}{
+		{name: "Return breakpoint", thread: &api.Thread{Breakpoint: &api.Breakpoint{ID: 1}, BreakpointInfo: info}, expected: []string{"q=2", "~r1=true"}},
+		{name: "Line breakpoint", thread: &api.Thread{Breakpoint: &api.Breakpoint{ID: 2}, BreakpointInfo: info}},
+		{name: "Not at a breakpoint", thread: &api.Thread{}},
+		{name: "No thread"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var result []string
+			for _, v := range c.returnBreakpointValues(tc.thread) {
+				if v.Scope != "return" {
+					t.Errorf("Expected scope return for %s, got %q", v.Name, v.Scope)
+				}
+				result = append(result, v.Name+"="+v.Value)
+			}
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestDescribeReturnBreakpointSpec(t *testing.T) {
+	spec := BreakpointSpec{Location: "main.cleanup", BreakpointOptions: BreakpointOptions{OnReturn: true}}
+	if result := spec.describe(); result != "main.cleanup on return" {
+		t.Errorf("Expected %q, got %q", "main.cleanup on return", result)
+	}
+}
+
+func TestImportReturnBreakpointWithoutFunction(t *testing.T) {
+	_, err := NewClient().importBreakpoint(types.SavedBreakpoint{ID: 1, OnReturn: true})
+	if err == nil || !strings.Contains(err.Error(), "no function") {
+		t.Errorf("Expected a missing function error, got %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// defaultMaxReturnEvaluations bounds RunUntilReturns when the caller gives no limit
+const defaultMaxReturnEvaluations = 100
+
+// returnValueLoadConfig is used to load the results of the function at each return
+var returnValueLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 1,
+	MaxStringLen:       256,
+	MaxArrayValues:     64,
+	MaxStructFields:    -1,
+}
+
+// RunUntilReturns continues the program until funcName returns with results for which
+// condition is true. Named results can be used by name; unnamed ones as r0, r1 and so
+// on by position. The condition is checked at most maxEvaluations times before giving
+// up, 0 meaning the default. When ctx is done first, the target is halted.
+func (c *Client) RunUntilReturns(ctx context.Context, funcName, condition string, maxEvaluations int) types.RunUntilReturnsResponse {
+	response := types.RunUntilReturnsResponse{Function: funcName, Condition: condition}
+
+	if c.client == nil {
+		return c.finishRunUntilReturns(response, nil, fmt.Errorf("no active debug session"))
+	}
+
+	if err := validateCondition(condition); err != nil {
+		return c.finishRunUntilReturns(response, nil, err)
+	}
+
+	if maxEvaluations <= 0 {
+		maxEvaluations = defaultMaxReturnEvaluations
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.finishRunUntilReturns(response, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.finishRunUntilReturns(response, nil, fmt.Errorf("cannot run until a function returns while the target is running; stop the target first"))
+	}
+
+	addrs, err := c.client.FunctionReturnLocations(funcName)
+	if err != nil {
+		return c.finishRunUntilReturns(response, state, fmt.Errorf("failed to find return points of %s: %v", funcName, err))
+	}
+	if len(addrs) == 0 {
+		return c.finishRunUntilReturns(response, state, fmt.Errorf("function %s has no return points", funcName))
+	}
+
+	// A plain breakpoint rather than a TraceReturn one, which Delve's client would resume
+	// from by itself before the condition is checked
+	loadArgs := returnValueLoadConfig
+	bp, err := c.client.CreateBreakpoint(&api.Breakpoint{
+		Addrs:    addrs,
+		LoadArgs: &loadArgs,
+	})
+	if err != nil {
+		return c.finishRunUntilReturns(response, state, fmt.Errorf("failed to set breakpoints at the returns of %s: %v", funcName, err))
+	}
+	defer func() {
+		if _, err := c.client.ClearBreakpoint(bp.ID); err != nil {
+			logger.Debug("Warning: Failed to clear return breakpoint %d: %v", bp.ID, err)
+		}
+	}()
+
+	logger.Debug("Running until %s returns with %s, at most %d evaluations", funcName, condition, maxEvaluations)
+	for response.Evaluations < maxEvaluations {
+		delveState, err := c.continueExecution(ctx)
+		if err != nil {
+			if errors.Is(err, ErrInterrupted) {
+				return c.finishRunUntilReturns(response, delveState, fmt.Errorf("%v before %s returned with %s", err, funcName, condition))
+			}
+			return c.finishRunUntilReturns(response, nil, err)
+		}
+		if delveState.Exited {
+			return c.finishRunUntilReturns(response, delveState, fmt.Errorf("process exited with status %d before %s returned with %s", delveState.ExitStatus, funcName, condition))
+		}
+
+		thread := delveState.CurrentThread
+		if thread == nil || thread.Breakpoint == nil || thread.Breakpoint.ID != bp.ID {
+			// Stopped by something else, such as a user breakpoint or a panic
+			if thread != nil && thread.Breakpoint != nil {
+				interruptedBy := convertBreakpoint(thread.Breakpoint)
+				response.InterruptedBy = &interruptedBy
+			}
+			return c.finishRunUntilReturns(response, delveState, nil)
+		}
+
+		response.Evaluations++
+		results := returnValues(thread)
+
+		expr, err := substituteReturnValues(condition, results)
+		if err != nil {
+			return c.finishRunUntilReturns(response, delveState, err)
+		}
+
+		scope := api.EvalScope{GoroutineID: thread.GoroutineID}
+		v, err := c.client.EvalVariable(scope, expr, api.LoadConfig{})
+		if err != nil {
+			return c.finishRunUntilReturns(response, delveState, fmt.Errorf("failed to evaluate condition %q: %v", condition, err))
+		}
+		if v.Kind != reflect.Bool {
+			return c.finishRunUntilReturns(response, delveState, fmt.Errorf("condition %q is of type %s, not bool", condition, v.Type))
+		}
+
+		if v.Value == "true" {
+			response.Matched = true
+			response.ReturnValues = convertReturnValues(results)
+			return c.finishRunUntilReturns(response, delveState, nil)
+		}
+	}
+
+	state, _ = c.client.GetStateNonBlocking()
+	return c.finishRunUntilReturns(response, state, fmt.Errorf("timed out: %s returned %d times without %s being true", funcName, response.Evaluations, condition))
+}
+
+// returnValues picks the function results out of the arguments loaded at a return breakpoint
+func returnValues(thread *api.Thread) []api.Variable {
+	if thread.BreakpointInfo == nil {
+		return nil
+	}
+
+	var results []api.Variable
+	for _, v := range thread.BreakpointInfo.Arguments {
+		if v.Flags&api.VariableReturnArgument != 0 {
+			results = append(results, v)
+		}
+	}
+	return results
+}
+
+// convertReturnValues converts the results of a function to our type
+func convertReturnValues(results []api.Variable) []types.Variable {
+	var variables []types.Variable
+	for i := range results {
+		variables = append(variables, types.Variable{
+			DelveVar: &results[i],
+			Name:     results[i].Name,
+			Value:    formatVariableValue(&results[i]),
+			Type:     results[i].Type,
+			Scope:    "return",
+			Kind:     getVariableKind(&results[i]),
+		})
+	}
+	return variables
+}
+
+// substituteReturnValues rewrites the positional names r0, r1, ... (or Delve's ~r0, ~r1)
+// in a condition into expressions Delve can evaluate, since unnamed results can't be
+// referred to by name. Results in memory become a typed dereference of their address,
+// scalars in registers become literals.
+func substituteReturnValues(condition string, results []api.Variable) (string, error) {
+	condition = strings.ReplaceAll(condition, "~r", "r")
+
+	named := make(map[string]bool, len(results))
+	for _, v := range results {
+		named[v.Name] = true
+	}
+
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(condition))
+	s.Init(file, []byte(condition), nil, 0)
+
+	var b strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.IDENT || named[lit] || !isPositionalResult(lit) {
+			continue
+		}
+
+		index, _ := strconv.Atoi(lit[1:])
+		if index >= len(results) {
+			return "", fmt.Errorf("condition refers to %s, but the function has %d results", lit, len(results))
+		}
+
+		replacement, err := returnValueExpression(&results[index])
+		if err != nil {
+			return "", fmt.Errorf("cannot use %s in the condition: %v", lit, err)
+		}
+
+		offset := file.Offset(pos)
+		b.WriteString(condition[last:offset])
+		b.WriteString(replacement)
+		last = offset + len(lit)
+	}
+	b.WriteString(condition[last:])
+
+	return b.String(), nil
+}
+
+// isPositionalResult reports whether an identifier has the form r0, r1, ...
+func isPositionalResult(ident string) bool {
+	if len(ident) < 2 || ident[0] != 'r' {
+		return false
+	}
+	_, err := strconv.Atoi(ident[1:])
+	return err == nil
+}
+
+// returnValueExpression returns an expression that evaluates to a result's value
+func returnValueExpression(v *api.Variable) (string, error) {
+	if v.Unreadable != "" {
+		return "", fmt.Errorf("the value is unreadable: %s", v.Unreadable)
+	}
+
+	if v.Addr != 0 && v.Type != "" {
+		return fmt.Sprintf("(*(*%s)(%#x))", v.Type, v.Addr), nil
+	}
+
+	switch v.Kind {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return v.Value, nil
+	case reflect.String:
+		return strconv.Quote(v.Value), nil
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		if len(v.Children) == 0 || v.Children[0].Addr == 0 {
+			return "nil", nil
+		}
+	}
+
+	return "", fmt.Errorf("the %s value is not in memory; give the result a name", v.Type)
+}
+
+// finishRunUntilReturns fills in the context and status of a RunUntilReturnsResponse
+func (c *Client) finishRunUntilReturns(response types.RunUntilReturnsResponse, state *api.DebuggerState, err error) types.RunUntilReturnsResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "run_until_returns"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		response.Status = "error"
+		response.Context = context
+		return response
+	}
+
+	response.Status = "success"
+	response.Context = context
+	if state != nil {
+		response.Location = getCurrentLocation(state)
+	}
+	return response
+}
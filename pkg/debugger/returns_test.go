@@ -0,0 +1,73 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestSubstituteReturnValues(t *testing.T) {
+	results := []api.Variable{
+		{Name: "~r0", Type: "*main.Response", Kind: reflect.Ptr, Addr: 0xc000010000},
+		{Name: "~r1", Type: "error", Kind: reflect.Interface, Children: []api.Variable{{}}},
+		{Name: "~r2", Type: "string", Kind: reflect.String, Value: "ok"},
+	}
+
+	testCases := []struct {
+		name      string
+		condition string
+		results   []api.Variable
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:      "result in memory",
+			condition: "r0.Status == 500",
+			results:   results,
+			expected:  "(*(**main.Response)(0xc000010000)).Status == 500",
+		},
+		{
+			name:      "nil interface in registers",
+			condition: "~r1 != nil",
+			results:   results,
+			expected:  "nil != nil",
+		},
+		{
+			name:      "string literal",
+			condition: `r2 == "ok" && count > 0`,
+			results:   results,
+			expected:  `"ok" == "ok" && count > 0`,
+		},
+		{
+			name:      "named results are left alone",
+			condition: "r0 > 1",
+			results:   []api.Variable{{Name: "r0", Type: "int", Kind: reflect.Int, Value: "3"}},
+			expected:  "r0 > 1",
+		},
+		{
+			name:      "out of range",
+			condition: "r3 == 0",
+			results:   results,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := substituteReturnValues(tc.condition, tc.results)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %q", expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if expr != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, expr)
+			}
+		})
+	}
+}
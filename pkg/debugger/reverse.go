@@ -0,0 +1,144 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// rrBackend is Delve's record-and-replay backend, which can run the target backward
+const rrBackend = "rr"
+
+// perfEventParanoidPath limits which performance counters unprivileged processes may use;
+// rr needs them
+const perfEventParanoidPath = "/proc/sys/kernel/perf_event_paranoid"
+
+// LaunchRecording records a run of a program or package with rr and starts replaying it
+// from the beginning, so it can be stepped and continued both forward and backward.
+// Recording runs the program to completion before the session starts.
+func (c *Client) LaunchRecording(path string, args []string) types.LaunchResponse {
+	if c.client != nil {
+		return c.createLaunchResponse(nil, path, args, fmt.Errorf("debug session already active"))
+	}
+
+	if err := checkRRAvailable(); err != nil {
+		return c.createLaunchResponse(nil, path, args, err)
+	}
+
+	logger.Debug("Recording %s with rr, args %v", path, args)
+
+	c.backend = rrBackend
+	response := c.Launch(path, args, nil, "")
+	if response.Context.ErrorMessage != "" {
+		c.backend = ""
+	}
+	return response
+}
+
+// checkRRAvailable reports what to do when rr is missing or can't record on this machine
+func checkRRAvailable() error {
+	if _, err := exec.LookPath("rr"); err != nil {
+		return fmt.Errorf("rr is not installed: reverse debugging records the program with rr (https://rr-project.org); install it, e.g. with 'apt install rr' or 'dnf install rr', and make sure it is on PATH")
+	}
+
+	if data, err := os.ReadFile(perfEventParanoidPath); err == nil {
+		if level, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && level > 1 {
+			return fmt.Errorf("rr cannot record: %s is %d but must be 1 or lower; run 'sudo sysctl kernel.perf_event_paranoid=1'", perfEventParanoidPath, level)
+		}
+	}
+	return nil
+}
+
+// requireRecording checks that the session replays a recording, which reverse execution needs
+func (c *Client) requireRecording() error {
+	if c.client == nil {
+		return fmt.Errorf("no active debug session")
+	}
+	// Delve reports core dumps as recorded too, but they can't be run in either direction
+	if c.coreFile != "" || !c.client.Recorded() {
+		return fmt.Errorf("reverse execution needs the rr backend: start the session with launch_recording, or connect to a Delve server started with --backend=rr")
+	}
+	return nil
+}
+
+// ReverseStep steps backward to the previous line, entering the calls made on it.
+// Like the other step commands it halts the target when ctx is done first.
+func (c *Client) ReverseStep(ctx context.Context) types.StepResponse {
+	return c.reverseStep(ctx, "reverse_into", func() (*api.DebuggerState, error) { return c.client.ReverseStep() })
+}
+
+// ReverseNext steps backward to the previous line of the current function, without
+// entering calls
+func (c *Client) ReverseNext(ctx context.Context) types.StepResponse {
+	return c.reverseStep(ctx, "reverse_over", func() (*api.DebuggerState, error) { return c.client.ReverseNext() })
+}
+
+// reverseStep runs a reverse step command after checking the session can run backward
+func (c *Client) reverseStep(ctx context.Context, stepType string, command func() (*api.DebuggerState, error)) types.StepResponse {
+	if err := c.requireRecording(); err != nil {
+		return c.createStepResponse(nil, stepType, nil, err)
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createStepResponse(nil, stepType, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createStepResponse(nil, stepType, nil, fmt.Errorf("cannot step backward while the target is running; stop the target first"))
+	}
+
+	fromLocation := getCurrentLocation(state)
+
+	logger.Debug("Stepping backward (%s)", stepType)
+	nextState, err := c.interruptible(ctx, command)
+	if err != nil {
+		if errors.Is(err, ErrInterrupted) {
+			return c.createStepResponse(nextState, stepType, fromLocation, err)
+		}
+		return c.createStepResponse(nil, stepType, fromLocation, fmt.Errorf("%s command failed: %v", stepType, err))
+	}
+
+	return c.createStepResponse(nextState, stepType, fromLocation, nil)
+}
+
+// ReverseContinue runs the program backward until the most recent earlier breakpoint hit,
+// or to the start of the recording when there is none
+func (c *Client) ReverseContinue(ctx context.Context) types.ContinueResponse {
+	if err := c.requireRecording(); err != nil {
+		return c.createContinueResponse(nil, err)
+	}
+
+	logger.Debug("Continuing backward")
+
+	state, err := c.interruptible(ctx, func() (*api.DebuggerState, error) {
+		var last *api.DebuggerState
+		for state := range c.client.Rewind() {
+			last = state
+		}
+		if last == nil {
+			return nil, fmt.Errorf("reverse continue command failed: no state received")
+		}
+		if last.Err != nil {
+			return last, fmt.Errorf("reverse continue command failed: %v", last.Err)
+		}
+		return last, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrInterrupted) {
+			return c.createContinueResponse(state, err)
+		}
+		return c.createContinueResponse(nil, err)
+	}
+
+	response := c.createContinueResponse(state, nil)
+	response.Context.Operation = "reverse_continue"
+	return response
+}
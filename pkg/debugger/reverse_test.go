@@ -0,0 +1,98 @@
+package debugger
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+// recordedTarget returns a fake Delve replaying a recording, or not, stopped at line 12
+// of main.go, whose commands step back to line 11
+func recordedTarget(t *testing.T, recorded bool) (*Client, *fakeDelve, *[]string) {
+	t.Helper()
+	at := func(line int) *api.Thread {
+		return &api.Thread{ID: 1, File: "main.go", Line: line, Function: &api.Function{Name_: "main.main"}, GoroutineID: 1}
+	}
+	var commands []string
+	c, f := newFakeDelve(t, map[string]fakeHandler{
+		"State":    fakeState(&api.DebuggerState{CurrentThread: at(12), SelectedGoroutine: &api.Goroutine{ID: 1}}),
+		"Recorded": fakeResult(rpc2.RecordedOut{Recorded: recorded}),
+		"Command": fakeCommands(t, &commands, func(command api.DebuggerCommand) api.DebuggerState {
+			state := api.DebuggerState{CurrentThread: at(11), SelectedGoroutine: &api.Goroutine{ID: 1}}
+			if command.Name == api.Rewind {
+				state.CurrentThread.Breakpoint = &api.Breakpoint{ID: 1, File: "main.go", Line: 11}
+			}
+			return state
+		}),
+	})
+	return c, f, &commands
+}
+
+func TestReverseNeedsRecording(t *testing.T) {
+	c, _, commands := recordedTarget(t, false)
+
+	stepResponse := c.ReverseStep(context.Background())
+	if stepResponse.Status != "error" || !strings.Contains(stepResponse.Context.ErrorMessage, "needs the rr backend") {
+		t.Errorf("Expected reverse_step to need a recording, got %q", stepResponse.Context.ErrorMessage)
+	}
+	continueResponse := c.ReverseContinue(context.Background())
+	if continueResponse.Status != "error" || !strings.Contains(continueResponse.Context.ErrorMessage, "needs the rr backend") {
+		t.Errorf("Expected reverse_continue to need a recording, got %q", continueResponse.Context.ErrorMessage)
+	}
+
+	// Delve reports core dumps as recorded, but they can't run backward either
+	core, _, coreCommands := recordedTarget(t, true)
+	core.coreFile = "core"
+	if response := core.ReverseNext(context.Background()); response.Status != "error" {
+		t.Errorf("Expected reverse_next to refuse a core dump, got %s", response.Status)
+	}
+
+	if len(*commands) != 0 || len(*coreCommands) != 0 {
+		t.Errorf("Expected nothing to be run without a recording, got %v and %v", *commands, *coreCommands)
+	}
+}
+
+func TestReverseStepAndContinue(t *testing.T) {
+	c, _, commands := recordedTarget(t, true)
+
+	step := c.ReverseStep(context.Background())
+	if step.Status != "success" || step.StepType != "reverse_into" {
+		t.Fatalf("Expected a reverse step, got %s %q: %s", step.Status, step.StepType, step.Context.ErrorMessage)
+	}
+	if step.FromLocation == nil || !strings.Contains(*step.FromLocation, "main.go:12") {
+		t.Errorf("Expected the step to start at main.go:12, got %v", step.FromLocation)
+	}
+	if step.Context.Position == nil || step.Context.Position.Line != 11 {
+		t.Errorf("Expected the step to end on line 11, got %+v", step.Context.Position)
+	}
+
+	if next := c.ReverseNext(context.Background()); next.Status != "success" || next.StepType != "reverse_over" {
+		t.Errorf("Expected a reverse next, got %s %q", next.Status, next.StepType)
+	}
+
+	back := c.ReverseContinue(context.Background())
+	if back.Status != "success" || back.Context.Stop == nil || back.Context.Stop.BreakpointID != 1 {
+		t.Errorf("Expected the reverse continue to stop at breakpoint 1, got %s %+v", back.Status, back.Context.Stop)
+	}
+
+	if expected := []string{api.ReverseStep, api.ReverseNext, api.Rewind}; !reflect.DeepEqual(*commands, expected) {
+		t.Errorf("Expected commands %v, got %v", expected, *commands)
+	}
+}
+
+func TestLaunchRecordingWithoutRR(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	c := NewClient()
+	response := c.LaunchRecording("./main.go", nil)
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "rr is not installed") {
+		t.Errorf("Expected an rr is not installed error, got %q", response.Context.ErrorMessage)
+	}
+	if c.backend != "" {
+		t.Errorf("Expected backend to stay the default, got %q", c.backend)
+	}
+}
@@ -0,0 +1,125 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// defaultRunHits is how many hits RunToCompletion keeps when no limit is given
+const defaultRunHits = 1000
+
+// RunToCompletion continues the program until it exits, logging every breakpoint and
+// tracepoint hit on the way with the values of its capture expressions. With autoContinue
+// the program is resumed after each breakpoint stop; without it the run ends at the first
+// breakpoint that isn't a tracepoint, leaving the program stopped there. Only the first
+// maxHits hits are kept, 0 meaning the default; later ones are counted. When ctx is done
+// first, the target is halted and the hits so far are returned.
+func (c *Client) RunToCompletion(ctx context.Context, autoContinue bool, maxHits int) types.RunToCompletionResponse {
+	if maxHits <= 0 {
+		maxHits = defaultRunHits
+	}
+	if maxHits > maxTraceHits {
+		maxHits = maxTraceHits
+	}
+	response := types.RunToCompletionResponse{Hits: make([]types.TraceHit, 0), MaxHits: maxHits}
+
+	if c.client == nil {
+		return c.finishRunToCompletion(response, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.finishRunToCompletion(response, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.finishRunToCompletion(response, nil, fmt.Errorf("cannot run to completion while the target is running; stop the target first"))
+	}
+
+	record := func(hit types.TraceHit) {
+		response.TotalHits++
+		if len(response.Hits) < maxHits {
+			hit.Seq = int64(response.TotalHits)
+			response.Hits = append(response.Hits, hit)
+		}
+	}
+
+	// Tracepoint hits are picked up from the trace log, which Delve's client fills while it
+	// resumes past them by itself
+	traceSeq, _ := c.trace.latest()
+	recordTraceHits := func() {
+		hits, lastSeq, _ := c.trace.since(traceSeq, 0)
+		// Hits dropped from the log before they could be read are still counted
+		response.TotalHits += int(lastSeq-traceSeq) - len(hits)
+		for _, hit := range hits {
+			record(hit)
+		}
+		traceSeq = lastSeq
+	}
+
+	logger.Debug("Running to completion, auto continue %v, keeping %d hits", autoContinue, maxHits)
+	for {
+		delveState, err := c.continueExecution(ctx)
+		recordTraceHits()
+		if err != nil {
+			if errors.Is(err, ErrInterrupted) {
+				return c.finishRunToCompletion(response, delveState, fmt.Errorf("%v before the process exited, after %d hits", err, response.TotalHits))
+			}
+			return c.finishRunToCompletion(response, nil, err)
+		}
+		if delveState.Exited {
+			response.Exited = true
+			response.ExitStatus = delveState.ExitStatus
+			return c.finishRunToCompletion(response, delveState, nil)
+		}
+
+		var hit bool
+		for _, th := range delveState.Threads {
+			if th.Breakpoint != nil && !th.Breakpoint.Tracepoint {
+				record(breakpointHit(th))
+				hit = true
+			}
+		}
+		// A stop that is no breakpoint hit, such as a halt from elsewhere, isn't continued past
+		if !hit || !autoContinue {
+			return c.finishRunToCompletion(response, delveState, nil)
+		}
+	}
+}
+
+// finishRunToCompletion completes a RunToCompletionResponse with the context and outcome
+func (c *Client) finishRunToCompletion(response types.RunToCompletionResponse, state *api.DebuggerState, err error) types.RunToCompletionResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "run_to_completion"
+	response.Context = context
+	response.OmittedHits = response.TotalHits - len(response.Hits)
+
+	response.Summary = fmt.Sprintf("%d breakpoint hits", response.TotalHits)
+	if response.OmittedHits > 0 {
+		response.Summary += fmt.Sprintf(", only the first %d kept", len(response.Hits))
+	}
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		response.Status = "error"
+		response.Context = context
+		return response
+	}
+
+	response.Status = "success"
+	switch {
+	case response.Exited:
+		response.Summary += fmt.Sprintf("; the process exited with status %d", response.ExitStatus)
+	case state != nil:
+		response.Location = getCurrentLocation(state)
+		if response.Location != nil {
+			response.Summary += "; stopped at " + strings.TrimPrefix(*response.Location, "At ")
+		}
+	}
+	return response
+}
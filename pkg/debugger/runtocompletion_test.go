@@ -0,0 +1,148 @@
+package debugger
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestFinishRunToCompletion(t *testing.T) {
+	stopped := &api.DebuggerState{CurrentThread: &api.Thread{File: "/src/main.go", Line: 16, Function: &api.Function{Name_: "main.get"}}}
+
+	testCases := []struct {
+		name     string
+		response types.RunToCompletionResponse
+		state    *api.DebuggerState
+		omitted  int
+		summary  string
+	}{
+		{
+			name:     "Exited",
+			response: types.RunToCompletionResponse{Hits: make([]types.TraceHit, 2), TotalHits: 2, Exited: true, ExitStatus: 3},
+			summary:  "2 breakpoint hits; the process exited with status 3",
+		},
+		{
+			name:     "Hits past the limit",
+			response: types.RunToCompletionResponse{Hits: make([]types.TraceHit, 3), TotalHits: 5, Exited: true},
+			omitted:  2,
+			summary:  "5 breakpoint hits, only the first 3 kept; the process exited with status 0",
+		},
+		{
+			name:     "Stopped at a breakpoint",
+			response: types.RunToCompletionResponse{Hits: make([]types.TraceHit, 1), TotalHits: 1},
+			state:    stopped,
+			summary:  "1 breakpoint hits; stopped at /src/main.go:16 in main.get",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := NewClient().finishRunToCompletion(tc.response, tc.state, nil)
+			if response.Status != "success" || response.OmittedHits != tc.omitted {
+				t.Errorf("Expected success with %d hits omitted, got %s with %d", tc.omitted, response.Status, response.OmittedHits)
+			}
+			if response.Summary != tc.summary {
+				t.Errorf("Expected summary %q, got %q", tc.summary, response.Summary)
+			}
+		})
+	}
+}
+
+// completingTarget returns a fake Delve whose program passes tracepoint 2, stops at
+// breakpoint 1, passes tracepoint 2 again, stops at breakpoint 1 again and then exits with
+// status 3. The names of the commands run are recorded.
+func completingTarget(t *testing.T) (*Client, *[]string) {
+	t.Helper()
+	stopAt := func(bp *api.Breakpoint, line int) api.DebuggerState {
+		state := stoppedState(line)
+		state.CurrentThread.Breakpoint = bp
+		state.Threads = []*api.Thread{state.CurrentThread}
+		return *state
+	}
+	breakpoint := &api.Breakpoint{ID: 1, File: "main.go", Line: 10}
+	tracepoint := &api.Breakpoint{ID: 2, File: "main.go", Line: 20, Tracepoint: true}
+	states := []api.DebuggerState{
+		stopAt(tracepoint, 20),
+		stopAt(breakpoint, 10),
+		stopAt(tracepoint, 20),
+		stopAt(breakpoint, 10),
+		{Exited: true, ExitStatus: 3},
+	}
+
+	var commands []string
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(5)),
+		"Command": fakeCommands(t, &commands, func(api.DebuggerCommand) api.DebuggerState {
+			state := states[0]
+			states = states[1:]
+			return state
+		}),
+	})
+	return c, &commands
+}
+
+func TestRunToCompletion(t *testing.T) {
+	testCases := []struct {
+		name         string
+		autoContinue bool
+		maxHits      int
+		breakpoints  []int // Breakpoints of the hits kept, in order
+		totalHits    int
+		exited       bool
+	}{
+		{name: "Auto continue", autoContinue: true, breakpoints: []int{2, 1, 2, 1}, totalHits: 4, exited: true},
+		{name: "Stop at the first breakpoint", breakpoints: []int{2, 1}, totalHits: 2},
+		{name: "Hits over the limit counted", autoContinue: true, maxHits: 3, breakpoints: []int{2, 1, 2}, totalHits: 4, exited: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := completingTarget(t)
+			response := c.RunToCompletion(context.Background(), tc.autoContinue, tc.maxHits)
+			if response.Status != "success" {
+				t.Fatalf("Expected the run to succeed, got %s", response.Context.ErrorMessage)
+			}
+
+			var breakpoints []int
+			for i, hit := range response.Hits {
+				breakpoints = append(breakpoints, hit.BreakpointID)
+				if hit.Seq != int64(i+1) {
+					t.Errorf("Expected hit %d numbered %d, got %d", i, i+1, hit.Seq)
+				}
+			}
+			if !reflect.DeepEqual(breakpoints, tc.breakpoints) || response.TotalHits != tc.totalHits {
+				t.Errorf("Expected hits of breakpoints %v of %d, got %v of %d", tc.breakpoints, tc.totalHits, breakpoints, response.TotalHits)
+			}
+			if response.OmittedHits != tc.totalHits-len(tc.breakpoints) {
+				t.Errorf("Expected %d hits omitted, got %d", tc.totalHits-len(tc.breakpoints), response.OmittedHits)
+			}
+
+			if response.Exited != tc.exited {
+				t.Fatalf("Expected exited %v, got %v: %s", tc.exited, response.Exited, response.Summary)
+			}
+			if tc.exited && (response.ExitStatus != 3 || !strings.HasSuffix(response.Summary, "the process exited with status 3")) {
+				t.Errorf("Expected exit status 3 in the summary, got %d: %s", response.ExitStatus, response.Summary)
+			}
+			if !tc.exited && (response.Location == nil || !strings.Contains(response.Summary, "stopped at main.go:10")) {
+				t.Errorf("Expected the run to stay stopped at main.go:10, got %s", response.Summary)
+			}
+		})
+	}
+}
+
+func TestRunToCompletionWithoutSession(t *testing.T) {
+	response := NewClient().RunToCompletion(context.Background(), true, 0)
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "no active debug session") {
+		t.Errorf("Expected a no active debug session error, got %q", response.Context.ErrorMessage)
+	}
+	if response.Context.Operation != "run_to_completion" {
+		t.Errorf("Expected operation run_to_completion, got %q", response.Context.Operation)
+	}
+	if response.MaxHits != defaultRunHits {
+		t.Errorf("Expected the default of %d hits kept, got %d", defaultRunHits, response.MaxHits)
+	}
+}
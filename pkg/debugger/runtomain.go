@@ -0,0 +1,139 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// mainFunction is where ContinueToMain stops, and fallbackMainFunction where it stops in a
+// program without one: package initialization has not run yet there, so breakpoints in
+// init functions are still hit
+const (
+	mainFunction         = "main.main"
+	fallbackMainFunction = "runtime.main"
+)
+
+// ContinueToMain runs a freshly launched program, which Delve stops at the entry point in
+// runtime code, to the first line of main.main, with a temporary breakpoint that is removed
+// again whether or not it was reached. Package initialization runs on the way, so a
+// breakpoint in an init function stops the program first.
+func (c *Client) ContinueToMain(ctx context.Context) types.ContinueToMainResponse {
+	if c.client == nil {
+		return c.createContinueToMainResponse(nil, "", false, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createContinueToMainResponse(nil, "", false, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createContinueToMainResponse(nil, "", false, fmt.Errorf("cannot continue to main while the target is running; stop the target first"))
+	}
+
+	function := mainFunction
+	if names, err := c.client.ListFunctions("^"+regexp.QuoteMeta(mainFunction)+"$", 0); err != nil || len(names) == 0 {
+		function = fallbackMainFunction
+	}
+
+	logger.Debug("Continuing to %s", function)
+	bp, err := c.client.CreateBreakpoint(&api.Breakpoint{FunctionName: function})
+	if err != nil {
+		// An existing breakpoint on the function stops the program there just as well
+		if !strings.Contains(err.Error(), "Breakpoint exists") {
+			return c.createContinueToMainResponse(state, function, false, fmt.Errorf("failed to set temporary breakpoint on %s: %v", function, err))
+		}
+		bp = nil
+	} else {
+		if c.tempBreakpoints == nil {
+			c.tempBreakpoints = make(map[int]bool)
+		}
+		c.tempBreakpoints[bp.ID] = true
+	}
+
+	delveState, err := c.continueExecution(ctx)
+
+	// Unlike ContinueToLine, don't leave the breakpoint for a later continue to stop at
+	if bp != nil && c.tempBreakpoints[bp.ID] {
+		if _, clearErr := c.client.ClearBreakpoint(bp.ID); clearErr != nil {
+			logger.Debug("Warning: Failed to clear temporary breakpoint %d: %v", bp.ID, clearErr)
+		} else {
+			delete(c.tempBreakpoints, bp.ID)
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrInterrupted) {
+			return c.createContinueToMainResponse(delveState, function, false, fmt.Errorf("%v before reaching %s", err, function))
+		}
+		return c.createContinueToMainResponse(nil, function, false, err)
+	}
+	if delveState.Exited {
+		return c.createContinueToMainResponse(delveState, function, false, fmt.Errorf("process exited with status %d before reaching %s", delveState.ExitStatus, function))
+	}
+
+	reached := delveState.CurrentThread != nil && delveState.CurrentThread.Function != nil && delveState.CurrentThread.Function.Name() == function
+	return c.createContinueToMainResponse(delveState, function, reached, nil)
+}
+
+// RunLaunchToMain runs a program just launched with response to main.main, and updates the
+// response with where it stopped. The launch still succeeded when the program could not be
+// run to main.main, so that is only noted.
+func (c *Client) RunLaunchToMain(ctx context.Context, response types.LaunchResponse) types.LaunchResponse {
+	start := c.ContinueToMain(ctx)
+
+	context := start.Context
+	context.Operation = response.Context.Operation
+	context.ErrorMessage = ""
+	response.Context = &context
+
+	if start.Status != "success" {
+		response.StartNote = fmt.Sprintf("launched, but not run to %s: %s", mainFunction, start.Context.ErrorMessage)
+		return response
+	}
+	if start.Reached {
+		response.StoppedAt = start.Function
+	}
+	response.StartNote = start.Note
+	return response
+}
+
+// createContinueToMainResponse creates a ContinueToMainResponse
+func (c *Client) createContinueToMainResponse(state *api.DebuggerState, function string, reached bool, err error) types.ContinueToMainResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "continue_to_main"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.ContinueToMainResponse{
+			Status:   "error",
+			Context:  context,
+			Function: function,
+		}
+	}
+
+	response := types.ContinueToMainResponse{
+		Status:   "success",
+		Context:  context,
+		Function: function,
+		Reached:  reached,
+	}
+
+	switch {
+	case !reached && state != nil && state.CurrentThread != nil && state.CurrentThread.Breakpoint != nil:
+		breakpoint := convertBreakpoint(state.CurrentThread.Breakpoint)
+		response.InterruptedBy = &breakpoint
+		response.Note = fmt.Sprintf("stopped at breakpoint %d before reaching %s", breakpoint.ID, function)
+	case !reached:
+		response.Note = fmt.Sprintf("stopped before reaching %s", function)
+	case function == fallbackMainFunction:
+		response.Note = fmt.Sprintf("the program has no %s; stopped at %s, before package initialization", mainFunction, fallbackMainFunction)
+	}
+	return response
+}
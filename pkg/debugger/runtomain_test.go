@@ -0,0 +1,140 @@
+package debugger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestCreateContinueToMainResponse(t *testing.T) {
+	atInit := &api.DebuggerState{CurrentThread: &api.Thread{File: "/app/main.go", Line: 12, Breakpoint: &api.Breakpoint{ID: 2}}}
+
+	testCases := []struct {
+		name                string
+		state               *api.DebuggerState
+		function            string
+		reached             bool
+		expectedNote        string
+		expectedInterrupted bool
+	}{
+		{name: "Reached main.main", function: mainFunction, reached: true},
+		{name: "Program without main.main", function: fallbackMainFunction, reached: true, expectedNote: "the program has no main.main; stopped at runtime.main, before package initialization"},
+		{name: "Breakpoint in an init function", state: atInit, function: mainFunction, expectedNote: "stopped at breakpoint 2 before reaching main.main", expectedInterrupted: true},
+		{name: "Stopped elsewhere", state: &api.DebuggerState{}, function: mainFunction, expectedNote: "stopped before reaching main.main"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := NewClient().createContinueToMainResponse(tc.state, tc.function, tc.reached, nil)
+			if response.Status != "success" || response.Reached != tc.reached {
+				t.Errorf("Expected success with reached %v, got %q with %v", tc.reached, response.Status, response.Reached)
+			}
+			if response.Note != tc.expectedNote {
+				t.Errorf("Expected note %q, got %q", tc.expectedNote, response.Note)
+			}
+			if (response.InterruptedBy != nil) != tc.expectedInterrupted {
+				t.Errorf("Expected interrupted %v, got %+v", tc.expectedInterrupted, response.InterruptedBy)
+			}
+		})
+	}
+}
+
+// launchedTarget returns a fake Delve stopped at the entry point of a program, which has a
+// main.main unless noMain, and whose continues stop at the breakpoint with the lowest ID
+func launchedTarget(t *testing.T, bps *fakeBreakpoints, noMain bool) *Client {
+	t.Helper()
+	functions := []string{mainFunction}
+	if noMain {
+		functions = nil
+	}
+	var commands []string
+	c, _ := newFakeDelve(t, bps.serve(t, map[string]fakeHandler{
+		"State":         fakeState(&api.DebuggerState{CurrentThread: &api.Thread{ID: 1, PC: 0x401000, Function: &api.Function{Name_: "_rt0_amd64_linux"}}}),
+		"ListFunctions": fakeResult(rpc2.ListFunctionsOut{Funcs: functions}),
+		"Command": fakeCommands(t, &commands, func(api.DebuggerCommand) api.DebuggerState {
+			var hit *api.Breakpoint
+			for id := 1; id <= bps.lastID && hit == nil; id++ {
+				hit = bps.get(id)
+			}
+			return api.DebuggerState{
+				CurrentThread:     &api.Thread{ID: 1, File: "main.go", Line: 3, Function: &api.Function{Name_: hit.FunctionName}, GoroutineID: 1, Breakpoint: hit},
+				SelectedGoroutine: &api.Goroutine{ID: 1},
+			}
+		}),
+	}))
+	return c
+}
+
+func TestContinueToMain(t *testing.T) {
+	testCases := []struct {
+		name        string
+		noMain      bool
+		initBP      bool
+		function    string
+		reached     bool
+		interrupted int
+	}{
+		{name: "Reaches main.main", function: mainFunction, reached: true},
+		{name: "Program without main.main", noMain: true, function: fallbackMainFunction, reached: true},
+		{name: "Breakpoint in an init function", initBP: true, function: mainFunction, interrupted: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bps := &fakeBreakpoints{}
+			if tc.initBP {
+				bps.add(&api.Breakpoint{ID: 1, File: "config.go", Line: 8, FunctionName: "main.init.0"})
+			}
+			c := launchedTarget(t, bps, tc.noMain)
+
+			response := c.ContinueToMain(context.Background())
+			if response.Status != "success" || response.Function != tc.function || response.Reached != tc.reached {
+				t.Fatalf("Expected a run to %s, reached %v, got %s, %v: %s", tc.function, tc.reached, response.Status, response.Reached, response.Context.ErrorMessage)
+			}
+			if by := response.InterruptedBy; (by == nil) != (tc.interrupted == 0) || (by != nil && by.ID != tc.interrupted) {
+				t.Errorf("Expected to be interrupted by breakpoint %d, got %+v", tc.interrupted, by)
+			}
+
+			// Only the temporary breakpoint is removed, whether or not it was reached
+			for id := 1; id <= bps.lastID; id++ {
+				if bp := bps.get(id); bp != nil && bp.FunctionName == tc.function {
+					t.Errorf("Expected the temporary breakpoint on %s to be removed, got %+v", tc.function, bp)
+				}
+			}
+			if tc.initBP && bps.get(1) == nil {
+				t.Errorf("Expected the breakpoint in the init function to stay")
+			}
+			if len(c.tempBreakpoints) != 0 {
+				t.Errorf("Expected no temporary breakpoints left, got %v", c.tempBreakpoints)
+			}
+		})
+	}
+}
+
+func TestRunLaunchToMain(t *testing.T) {
+	c := launchedTarget(t, &fakeBreakpoints{}, false)
+	launch := types.LaunchResponse{Status: "success", Context: &types.DebugContext{Operation: "launch"}, Program: "./app"}
+
+	response := c.RunLaunchToMain(context.Background(), launch)
+	if response.Status != "success" || response.Context.Operation != "launch" || response.StoppedAt != mainFunction {
+		t.Errorf("Expected the launch stopped at main.main, got %q at %q", response.Status, response.StoppedAt)
+	}
+	if response.Context.CurrentLocation == nil || !strings.Contains(*response.Context.CurrentLocation, "main.go:3") {
+		t.Errorf("Expected the launch to report where main.main starts, got %v", response.Context.CurrentLocation)
+	}
+}
+
+func TestRunLaunchToMainNotesFailure(t *testing.T) {
+	launch := types.LaunchResponse{Status: "success", Context: &types.DebugContext{Operation: "launch"}, Program: "./app"}
+	response := NewClient().RunLaunchToMain(context.Background(), launch)
+	if response.Status != "success" || response.Context.Operation != "launch" || response.Context.ErrorMessage != "" {
+		t.Errorf("Expected the launch to stay successful, got %q with %+v", response.Status, response.Context)
+	}
+	if response.StoppedAt != "" || !strings.Contains(response.StartNote, "not run to main.main: no active debug session") {
+		t.Errorf("Expected a note on why main.main was not reached, got %q at %q", response.StartNote, response.StoppedAt)
+	}
+}
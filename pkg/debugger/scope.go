@@ -0,0 +1,254 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxVariableDepth caps how deep nested values are expanded when listing variables
+const maxVariableDepth = 5
+
+// VariableListOptions controls how ListLocals and ListArgs load and filter variables
+type VariableListOptions struct {
+	Depth        int  // How many levels of nested values to expand
+	HideShadowed bool // Leave out variables shadowed by an inner declaration
+	HideBlank    bool // Leave out blank (_) identifiers
+
+	MaxStringLen   int // Longest string value loaded, 0 for the default
+	MaxArrayValues int // Most slice, array or map elements loaded, 0 for the default
+
+	// Render values whose type has a String or Error method with it too. The methods are
+	// called in the target, so this runs code there.
+	CallStringers bool
+
+	// Load what pointers point to, or only their address; nil for the session's setting
+	FollowPointers *bool
+}
+
+// ListLocals returns the local variables of a frame of the selected goroutine
+func (c *Client) ListLocals(frame int, opts VariableListOptions) types.VariableListResponse {
+	return c.listScopeVariables("local", frame, opts)
+}
+
+// ListArgs returns the function arguments of a frame of the selected goroutine
+func (c *Client) ListArgs(frame int, opts VariableListOptions) types.VariableListResponse {
+	return c.listScopeVariables("argument", frame, opts)
+}
+
+// listScopeVariables loads either the locals or the arguments of a frame
+func (c *Client) listScopeVariables(kind string, frame int, opts VariableListOptions) types.VariableListResponse {
+	operation := "list_locals"
+	if kind == "argument" {
+		operation = "list_args"
+	}
+
+	if c.client == nil {
+		return c.createVariableListResponse(nil, operation, frame, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createVariableListResponse(nil, operation, frame, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createVariableListResponse(nil, operation, frame, nil, fmt.Errorf("cannot list variables while the target is running; stop the target first"))
+	}
+
+	scope, err := c.frameScope(state, frame)
+	if err != nil {
+		return c.createVariableListResponse(state, operation, frame, nil, err)
+	}
+
+	cfg, depth := variableLoadConfig(opts)
+	cfg.FollowPointers = c.followPointers(opts.FollowPointers)
+
+	logger.Debug("Listing %s variables of frame %d with depth %d", kind, frame, depth)
+
+	var vars []api.Variable
+	if kind == "argument" {
+		vars, err = c.client.ListFunctionArgs(scope, cfg)
+		if err != nil {
+			return c.createVariableListResponse(state, operation, frame, nil, fmt.Errorf("failed to list function arguments: %v", err))
+		}
+	} else {
+		vars, err = c.client.ListLocalVariables(scope, cfg)
+		if err != nil {
+			return c.createVariableListResponse(state, operation, frame, nil, fmt.Errorf("failed to list local variables: %v", err))
+		}
+	}
+
+	variables := convertScopeVariables(vars, kind, depth, opts)
+
+	var stringerNote string
+	if opts.CallStringers {
+		targets := make([]stringerTarget, 0, len(variables))
+		for i := range variables {
+			// A shadowed variable can't be named in an expression
+			if !variables[i].Shadowed {
+				targets = append(targets, stringerTarget{expr: variables[i].Name, variable: &variables[i]})
+			}
+		}
+		stringerNote = c.callStringers(state, frame, targets, cfg.MaxStringLen)
+	}
+
+	response := c.createVariableListResponse(state, operation, frame, variables, nil)
+	response.MaxDepth = depth
+	response.StringerNote = stringerNote
+	return response
+}
+
+// convertScopeVariables converts the variables of a scope listing to our type, leaving out
+// the ones opts hides
+func convertScopeVariables(vars []api.Variable, kind string, depth int, opts VariableListOptions) []types.Variable {
+	variables := make([]types.Variable, 0, len(vars))
+	for i := range vars {
+		v := &vars[i]
+		if opts.HideShadowed && v.Flags&api.VariableShadowed != 0 {
+			continue
+		}
+		if opts.HideBlank && v.Name == "_" {
+			continue
+		}
+		variables = append(variables, convertVariableTree(v, kind, depth))
+	}
+	return variables
+}
+
+// variableLoadConfig returns how much of each variable to load for a listing, and how
+// many levels of nested values to expand
+func variableLoadConfig(opts VariableListOptions) (api.LoadConfig, int) {
+	depth := clampLoadDepth(opts.Depth)
+
+	cfg := api.LoadConfig{
+		FollowPointers:     true,
+		MaxVariableRecurse: depth,
+		MaxStringLen:       256,
+		MaxArrayValues:     64,
+		MaxStructFields:    -1,
+	}
+	if opts.MaxStringLen > 0 {
+		cfg.MaxStringLen = opts.MaxStringLen
+	}
+	if opts.MaxArrayValues > 0 {
+		cfg.MaxArrayValues = opts.MaxArrayValues
+	}
+	return cfg, depth
+}
+
+// frameScope returns the scope of a frame of the selected goroutine, making sure the frame exists
+func (c *Client) frameScope(state *api.DebuggerState, frame int) (api.EvalScope, error) {
+	if state.SelectedGoroutine == nil {
+		return api.EvalScope{}, fmt.Errorf("no goroutine selected")
+	}
+
+	if frame < 0 {
+		return api.EvalScope{}, fmt.Errorf("frame must not be negative")
+	}
+
+	frames, err := c.client.Stacktrace(state.SelectedGoroutine.ID, frame, 0, nil)
+	if err != nil {
+		return api.EvalScope{}, fmt.Errorf("failed to get stack trace: %v", err)
+	}
+	if frame >= len(frames) {
+		return api.EvalScope{}, fmt.Errorf("frame %d out of range; the stack has %d frames", frame, len(frames))
+	}
+
+	return api.EvalScope{
+		GoroutineID: state.SelectedGoroutine.ID,
+		Frame:       frame,
+	}, nil
+}
+
+// convertVariableTree converts a Delve variable to our type, expanding nested values up to
+// depth levels. A pointer to a value already expanded higher up, as in a cyclic structure,
+// refers to where it was shown instead of expanding it again.
+func convertVariableTree(v *api.Variable, scope string, depth int) types.Variable {
+	return convertVariableNode(v, v.Name, scope, depth, make(shownValues))
+}
+
+// convertVariableNode converts a variable found at path within the variable being converted
+func convertVariableNode(v *api.Variable, path, scope string, depth int, shown shownValues) types.Variable {
+	variable := types.Variable{
+		DelveVar: v,
+		Name:     v.Name,
+		Value:    formatVariableValue(v),
+		Type:     v.Type,
+		Scope:    scope,
+		Kind:     getVariableKind(v),
+	}
+
+	if v.Unreadable != "" {
+		variable.Value = fmt.Sprintf("<unreadable: %s>", v.Unreadable)
+		return variable
+	}
+	if v.Flags&api.VariableShadowed != 0 {
+		variable.Shadowed = true
+	}
+	if depth <= 0 {
+		return variable
+	}
+	shown.record(v, path)
+
+	switch v.Kind {
+	case reflect.Struct, reflect.Array, reflect.Slice:
+		for i := range v.Children {
+			child := &v.Children[i]
+			label := child.Name
+			if v.Kind != reflect.Struct {
+				label = fmt.Sprintf("[%d]", i)
+			}
+			childVar := convertVariableNode(child, fieldPath(path, label), scope, depth-1, shown)
+			childVar.Name = label
+			variable.Children = append(variable.Children, childVar)
+		}
+	case reflect.Map:
+		for _, e := range sortedMapEntries(v) {
+			label := fmt.Sprintf("[%s]", formatScalarValue(e.key))
+			childVar := convertVariableNode(e.value, fieldPath(path, label), scope, depth-1, shown)
+			childVar.Name = label
+			variable.Children = append(variable.Children, childVar)
+		}
+	case reflect.Ptr, reflect.Interface:
+		// Show what a non-nil pointer or interface holds in place of the wrapper, unless only
+		// the pointer's address was loaded
+		if len(v.Children) > 0 && v.Children[0].Kind != reflect.Invalid && !v.Children[0].OnlyAddr {
+			if shownPath, ok := shown.shownAt(&v.Children[0]); ok && v.Kind == reflect.Ptr {
+				variable.Value = fmt.Sprintf("%#x (already shown at %s)", v.Children[0].Addr, shownPath)
+				return variable
+			}
+			inner := convertVariableNode(&v.Children[0], path, scope, depth, shown)
+			variable.Children = inner.Children
+			if variable.Value == "" {
+				variable.Value = inner.Value
+			}
+		}
+	}
+
+	return variable
+}
+
+// createVariableListResponse creates a VariableListResponse
+func (c *Client) createVariableListResponse(state *api.DebuggerState, operation string, frame int, variables []types.Variable, err error) types.VariableListResponse {
+	context := c.createDebugContext(state)
+	context.Operation = operation
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.VariableListResponse{
+			Status:  "error",
+			Context: context,
+			Frame:   frame,
+		}
+	}
+
+	return types.VariableListResponse{
+		Status:    "success",
+		Context:   context,
+		Frame:     frame,
+		Variables: variables,
+	}
+}
@@ -0,0 +1,148 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestConvertVariableTree(t *testing.T) {
+	inner := api.Variable{
+		Name: "Inner",
+		Type: "main.Inner",
+		Kind: reflect.Struct,
+		Children: []api.Variable{
+			{Name: "ID", Type: "int", Kind: reflect.Int, Value: "7"},
+		},
+	}
+	outer := &api.Variable{
+		Name: "cfg",
+		Type: "*main.Config",
+		Kind: reflect.Ptr,
+		Children: []api.Variable{
+			{
+				Type: "main.Config",
+				Kind: reflect.Struct,
+				Children: []api.Variable{
+					{Name: "Name", Type: "string", Kind: reflect.String, Value: "demo", Len: 4},
+					inner,
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		depth         int
+		childCount    int
+		grandchildren int
+	}{
+		{name: "depth 0 keeps only the top level", depth: 0, childCount: 0},
+		{name: "depth 1 expands the pointee's fields", depth: 1, childCount: 2, grandchildren: 0},
+		{name: "depth 2 expands nested structs", depth: 2, childCount: 2, grandchildren: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := convertVariableTree(outer, "local", tc.depth)
+			if v.Name != "cfg" || v.Scope != "local" {
+				t.Errorf("Expected local variable cfg, got %s variable %s", v.Scope, v.Name)
+			}
+			if len(v.Children) != tc.childCount {
+				t.Fatalf("Expected %d children, got %d", tc.childCount, len(v.Children))
+			}
+			if tc.childCount == 0 {
+				return
+			}
+			if v.Children[0].Name != "Name" || v.Children[1].Name != "Inner" {
+				t.Errorf("Expected fields Name and Inner, got %s and %s", v.Children[0].Name, v.Children[1].Name)
+			}
+			if got := len(v.Children[1].Children); got != tc.grandchildren {
+				t.Errorf("Expected %d nested fields, got %d", tc.grandchildren, got)
+			}
+		})
+	}
+}
+
+func TestConvertVariableTreeNames(t *testing.T) {
+	slice := &api.Variable{
+		Name: "items",
+		Type: "[]int",
+		Kind: reflect.Slice,
+		Children: []api.Variable{
+			{Type: "int", Kind: reflect.Int, Value: "1"},
+			{Type: "int", Kind: reflect.Int, Value: "2"},
+		},
+	}
+	m := &api.Variable{
+		Name: "counts",
+		Type: "map[string]int",
+		Kind: reflect.Map,
+		Children: []api.Variable{
+			{Type: "string", Kind: reflect.String, Value: "a", Len: 1},
+			{Type: "int", Kind: reflect.Int, Value: "3"},
+		},
+	}
+
+	if v := convertVariableTree(slice, "local", 1); len(v.Children) != 2 || v.Children[1].Name != "[1]" {
+		t.Errorf("Expected slice elements named by index, got %+v", v.Children)
+	}
+	if v := convertVariableTree(m, "local", 1); len(v.Children) != 1 || v.Children[0].Name != `["a"]` {
+		t.Errorf("Expected map entry named by key, got %+v", v.Children)
+	}
+}
+
+func TestVariableLoadConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		opts          VariableListOptions
+		expectedDepth int
+		expectedStr   int
+		expectedArray int
+	}{
+		{
+			name:          "Defaults",
+			opts:          VariableListOptions{Depth: 1},
+			expectedDepth: 1,
+			expectedStr:   256,
+			expectedArray: 64,
+		},
+		{
+			name:          "Depth capped",
+			opts:          VariableListOptions{Depth: 12},
+			expectedDepth: maxVariableDepth,
+			expectedStr:   256,
+			expectedArray: 64,
+		},
+		{
+			name:          "Negative depth",
+			opts:          VariableListOptions{Depth: -1},
+			expectedDepth: 0,
+			expectedStr:   256,
+			expectedArray: 64,
+		},
+		{
+			name:          "Custom limits",
+			opts:          VariableListOptions{Depth: 2, MaxStringLen: 16, MaxArrayValues: 4},
+			expectedDepth: 2,
+			expectedStr:   16,
+			expectedArray: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, depth := variableLoadConfig(tc.opts)
+			if depth != tc.expectedDepth || cfg.MaxVariableRecurse != tc.expectedDepth {
+				t.Errorf("Expected depth %d, got %d (recurse %d)", tc.expectedDepth, depth, cfg.MaxVariableRecurse)
+			}
+			if cfg.MaxStringLen != tc.expectedStr {
+				t.Errorf("Expected max string length %d, got %d", tc.expectedStr, cfg.MaxStringLen)
+			}
+			if cfg.MaxArrayValues != tc.expectedArray {
+				t.Errorf("Expected max array values %d, got %d", tc.expectedArray, cfg.MaxArrayValues)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// Bounds on FindVariables, so cyclic or huge values can't make a search run away
+const (
+	maxSearchMatches = 100   // Matches returned before the search stops
+	maxSearchNodes   = 10000 // Values visited before the search stops
+)
+
+// searchLoadConfig loads values deep enough to search nested structs, maps and slices
+var searchLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: maxVariableDepth,
+	MaxStringLen:       256,
+	MaxArrayValues:     64,
+	MaxStructFields:    -1,
+}
+
+// FindVariables searches the locals and arguments of a frame of the selected goroutine,
+// and the values nested in them, for names matching the pattern regex. With searchValues
+// the formatted values of strings, numbers and booleans are matched too. Each match
+// carries a path such as req.Header["Content-Type"] that can be evaluated directly.
+func (c *Client) FindVariables(pattern string, frame int, searchValues bool) types.VariableSearchResponse {
+	if c.client == nil {
+		return c.createVariableSearchResponse(nil, pattern, frame, nil, fmt.Errorf("no active debug session"))
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return c.createVariableSearchResponse(nil, pattern, frame, nil, fmt.Errorf("invalid pattern %q: %v", pattern, err))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createVariableSearchResponse(nil, pattern, frame, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createVariableSearchResponse(nil, pattern, frame, nil, fmt.Errorf("cannot search variables while the target is running; stop the target first"))
+	}
+
+	scope, err := c.frameScope(state, frame)
+	if err != nil {
+		return c.createVariableSearchResponse(state, pattern, frame, nil, err)
+	}
+
+	args, err := c.client.ListFunctionArgs(scope, searchLoadConfig)
+	if err != nil {
+		return c.createVariableSearchResponse(state, pattern, frame, nil, fmt.Errorf("failed to list function arguments: %v", err))
+	}
+
+	locals, err := c.client.ListLocalVariables(scope, searchLoadConfig)
+	if err != nil {
+		return c.createVariableSearchResponse(state, pattern, frame, nil, fmt.Errorf("failed to list local variables: %v", err))
+	}
+
+	logger.Debug("Searching %d arguments and %d locals of frame %d for %q, values: %v", len(args), len(locals), frame, pattern, searchValues)
+
+	search := newVariableSearch(re, searchValues)
+	search.searchAll("argument", args)
+	search.searchAll("local", locals)
+
+	return c.createVariableSearchResponse(state, pattern, frame, search, nil)
+}
+
+// variableSearch holds the progress of a FindVariables walk
+type variableSearch struct {
+	re           *regexp.Regexp
+	searchValues bool
+
+	scope   string          // Scope of the variable being walked
+	visited map[string]bool // Pointees already walked, to break cycles
+	nodes   int             // Values visited so far
+
+	matches   []types.VariableMatch
+	truncated bool // Whether a bound stopped the search early
+}
+
+func newVariableSearch(re *regexp.Regexp, searchValues bool) *variableSearch {
+	return &variableSearch{
+		re:           re,
+		searchValues: searchValues,
+		visited:      make(map[string]bool),
+	}
+}
+
+// searchAll walks the top-level variables of one scope, skipping shadowed ones whose
+// names would evaluate to the inner declaration
+func (s *variableSearch) searchAll(scope string, vars []api.Variable) {
+	s.scope = scope
+	for i := range vars {
+		if vars[i].Flags&api.VariableShadowed != 0 || vars[i].Name == "_" {
+			continue
+		}
+		s.walk(&vars[i], vars[i].Name, vars[i].Name, 0)
+	}
+}
+
+// walk checks a value and its children. name is what the pattern is matched against,
+// empty for slice and array elements, and path is the expression evaluating to the value.
+func (s *variableSearch) walk(v *api.Variable, name, path string, depth int) {
+	if s.truncated {
+		return
+	}
+	if s.nodes >= maxSearchNodes || len(s.matches) >= maxSearchMatches {
+		s.truncated = true
+		return
+	}
+	s.nodes++
+
+	if name != "" && s.re.MatchString(name) {
+		s.addMatch(v, path, "name")
+	} else if s.searchValues && isScalarKind(v.Kind) && v.Unreadable == "" && s.re.MatchString(v.Value) {
+		s.addMatch(v, path, "value")
+	}
+
+	if v.Unreadable != "" || depth >= maxVariableDepth {
+		return
+	}
+
+	switch v.Kind {
+	case reflect.Struct:
+		for i := range v.Children {
+			child := &v.Children[i]
+			s.walk(child, child.Name, path+"."+child.Name, depth+1)
+		}
+	case reflect.Array, reflect.Slice:
+		for i := range v.Children {
+			s.walk(&v.Children[i], "", fmt.Sprintf("%s[%d]", path, i), depth+1)
+		}
+	case reflect.Map:
+		// Map children alternate between keys and values
+		for i := 0; i+1 < len(v.Children); i += 2 {
+			key := &v.Children[i]
+			s.walk(&v.Children[i+1], key.Value, fmt.Sprintf("%s[%s]", path, formatScalarValue(key)), depth+1)
+		}
+	case reflect.Ptr, reflect.Interface:
+		if len(v.Children) == 0 {
+			return
+		}
+		inner := &v.Children[0]
+		// Nil interfaces hold an invalid value and nil pointers point to address 0
+		if inner.Kind == reflect.Invalid || (v.Kind == reflect.Ptr && inner.Addr == 0) {
+			return
+		}
+
+		// Pointers and interfaces can lead back to a value already walked
+		key := fmt.Sprintf("%#x %s", inner.Addr, inner.Type)
+		if inner.Addr != 0 {
+			if s.visited[key] {
+				return
+			}
+			s.visited[key] = true
+		}
+
+		s.walk(inner, "", indirectPath(v, inner, path), depth)
+	}
+}
+
+// indirectPath returns the expression for what a pointer or interface holds. Delve
+// follows both for field access, so only other kinds need an explicit dereference or
+// type assertion.
+func indirectPath(v, inner *api.Variable, path string) string {
+	if inner.Kind == reflect.Struct || (v.Kind == reflect.Interface && inner.Kind == reflect.Ptr) {
+		return path
+	}
+	if v.Kind == reflect.Interface {
+		return fmt.Sprintf("%s.(%s)", path, inner.Type)
+	}
+	return fmt.Sprintf("(*%s)", path)
+}
+
+// addMatch records a match of the current scope
+func (s *variableSearch) addMatch(v *api.Variable, path, matchedOn string) {
+	value := formatVariableValue(v)
+	if v.Unreadable != "" {
+		value = fmt.Sprintf("<unreadable: %s>", v.Unreadable)
+	}
+
+	s.matches = append(s.matches, types.VariableMatch{
+		Path:      path,
+		Value:     value,
+		Type:      v.Type,
+		Scope:     s.scope,
+		MatchedOn: matchedOn,
+	})
+}
+
+// isScalarKind reports whether values of a kind are matched as text when searching values
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	default:
+		return false
+	}
+}
+
+// createVariableSearchResponse creates a VariableSearchResponse
+func (c *Client) createVariableSearchResponse(state *api.DebuggerState, pattern string, frame int, search *variableSearch, err error) types.VariableSearchResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "find_variables"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.VariableSearchResponse{
+			Status:  "error",
+			Context: context,
+			Pattern: pattern,
+			Frame:   frame,
+		}
+	}
+
+	return types.VariableSearchResponse{
+		Status:    "success",
+		Context:   context,
+		Pattern:   pattern,
+		Frame:     frame,
+		Matches:   search.matches,
+		Truncated: search.truncated,
+	}
+}
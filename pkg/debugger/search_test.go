@@ -0,0 +1,115 @@
+package debugger
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestVariableSearch(t *testing.T) {
+	node := api.Variable{Name: "next", Type: "*main.node", Kind: reflect.Ptr}
+	node.Children = []api.Variable{{
+		Type: "main.node",
+		Kind: reflect.Struct,
+		Addr: 0xc000010000,
+		Children: []api.Variable{
+			{Name: "ID", Type: "int", Kind: reflect.Int, Value: "7"},
+			// The pointer back to the same node makes a cycle
+			{Name: "next", Type: "*main.node", Kind: reflect.Ptr, Children: []api.Variable{
+				{Type: "main.node", Kind: reflect.Struct, Addr: 0xc000010000},
+			}},
+		},
+	}}
+
+	req := api.Variable{
+		Name: "req",
+		Type: "*net/http.Request",
+		Kind: reflect.Ptr,
+		Children: []api.Variable{{
+			Type: "net/http.Request",
+			Kind: reflect.Struct,
+			Addr: 0xc000020000,
+			Children: []api.Variable{
+				{Name: "Method", Type: "string", Kind: reflect.String, Value: "GET"},
+				{Name: "Header", Type: "net/http.Header", Kind: reflect.Map, Children: []api.Variable{
+					{Type: "string", Kind: reflect.String, Value: "Content-Type"},
+					{Type: "[]string", Kind: reflect.Slice, Children: []api.Variable{
+						{Type: "string", Kind: reflect.String, Value: "application/json"},
+					}},
+				}},
+				{Name: "Body", Type: "io.ReadCloser", Kind: reflect.Interface, Children: []api.Variable{
+					{Type: "*bytes.Reader", Kind: reflect.Ptr, Children: []api.Variable{
+						{Type: "bytes.Reader", Kind: reflect.Struct, Addr: 0xc000030000},
+					}},
+				}},
+				{Name: "ctx", Type: "interface {}", Kind: reflect.Interface, Children: []api.Variable{
+					{Type: "[]int", Kind: reflect.Slice, Children: []api.Variable{
+						{Type: "int", Kind: reflect.Int, Value: "42"},
+					}},
+				}},
+			},
+		}},
+	}
+	count := api.Variable{Name: "count", Type: "*int", Kind: reflect.Ptr, Children: []api.Variable{
+		{Type: "int", Kind: reflect.Int, Value: "42", Addr: 0xc000040000},
+	}}
+
+	testCases := []struct {
+		name         string
+		pattern      string
+		searchValues bool
+		expected     []types.VariableMatch
+	}{
+		{
+			name:    "struct fields and map keys by name",
+			pattern: "(?i)content|method",
+			expected: []types.VariableMatch{
+				{Path: "req.Method", Value: "GET", Type: "string", Scope: "local", MatchedOn: "name"},
+				{Path: `req.Header["Content-Type"]`, Type: "[]string", Scope: "local", MatchedOn: "name"},
+			},
+		},
+		{
+			name:         "values through pointers and interfaces",
+			pattern:      "^(42|application/json)$",
+			searchValues: true,
+			expected: []types.VariableMatch{
+				{Path: `req.Header["Content-Type"][0]`, Value: "application/json", Type: "string", Scope: "local", MatchedOn: "value"},
+				{Path: "req.ctx.([]int)[0]", Value: "42", Type: "int", Scope: "local", MatchedOn: "value"},
+				{Path: "(*count)", Value: "42", Type: "int", Scope: "local", MatchedOn: "value"},
+			},
+		},
+		{
+			name:    "cycles are walked once",
+			pattern: "^ID$",
+			expected: []types.VariableMatch{
+				{Path: "next.ID", Value: "7", Type: "int", Scope: "local", MatchedOn: "name"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			search := newVariableSearch(regexp.MustCompile(tc.pattern), tc.searchValues)
+			search.searchAll("local", []api.Variable{req, count, node})
+
+			if len(search.matches) != len(tc.expected) {
+				t.Fatalf("Expected %d matches, got %d: %+v", len(tc.expected), len(search.matches), search.matches)
+			}
+			for i, expected := range tc.expected {
+				match := search.matches[i]
+				if expected.Value == "" {
+					match.Value = ""
+				}
+				if match != expected {
+					t.Errorf("Expected match %d to be %+v, got %+v", i, expected, match)
+				}
+			}
+			if search.truncated {
+				t.Errorf("Expected the search not to be truncated")
+			}
+		})
+	}
+}
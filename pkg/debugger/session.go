@@ -0,0 +1,263 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// DefaultSessionID names the session used when no session ID is given
+const DefaultSessionID = "default"
+
+// ErrShutDown is returned for every session once the session manager was shut down
+var ErrShutDown = errors.New("debugger shut down")
+
+// SessionManager holds independent debug sessions keyed by ID, so several programs can be
+// debugged at once. Each session has its own Client, and with it its own breakpoints,
+// state and output buffers. The default session always exists.
+type SessionManager struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+	nextID   int  // Used to name sessions created without an ID
+	shutDown bool // Set by Shutdown, after which no session can be used
+}
+
+type session struct {
+	client  *Client
+	created time.Time
+}
+
+// NewSessionManager creates a session manager holding only the default session
+func NewSessionManager() *SessionManager {
+	return &SessionManager{
+		sessions: map[string]*session{
+			DefaultSessionID: {client: NewClient(), created: time.Now()},
+		},
+	}
+}
+
+// Get returns the client of a session, the default session when id is empty
+func (m *SessionManager) Get(id string) (*Client, error) {
+	if id == "" {
+		id = DefaultSessionID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shutDown {
+		return nil, errShutDown()
+	}
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown session %q; create it with create_session or use one of: %s", id, m.idsLocked())
+	}
+	return s.client, nil
+}
+
+// Create adds a new session without a debug target yet. An empty id picks a fresh one.
+func (m *SessionManager) Create(id string) (types.SessionInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shutDown {
+		return types.SessionInfo{}, errShutDown()
+	}
+	if id == "" {
+		for {
+			m.nextID++
+			id = fmt.Sprintf("session-%d", m.nextID)
+			if _, ok := m.sessions[id]; !ok {
+				break
+			}
+		}
+	} else if _, ok := m.sessions[id]; ok {
+		return types.SessionInfo{}, fmt.Errorf("session %q already exists", id)
+	}
+
+	logger.Debug("Creating debug session %s", id)
+	s := &session{client: NewClient(), created: time.Now()}
+	m.sessions[id] = s
+	return s.info(id), nil
+}
+
+// Reset replaces the client of a session with a fresh one, after its debug session ended
+func (m *SessionManager) Reset(id string) {
+	if id == "" {
+		id = DefaultSessionID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.sessions[id]; ok {
+		s.client = NewClient()
+	}
+}
+
+// Close ends the debug session of a session and removes it. The default session is
+// only reset, so tool calls without a session ID keep working.
+func (m *SessionManager) Close(id string) (types.SessionInfo, error) {
+	if id == "" {
+		id = DefaultSessionID
+	}
+
+	m.mu.Lock()
+	if m.shutDown {
+		m.mu.Unlock()
+		return types.SessionInfo{ID: id}, errShutDown()
+	}
+	s, ok := m.sessions[id]
+	if ok && id != DefaultSessionID {
+		delete(m.sessions, id)
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return types.SessionInfo{ID: id}, fmt.Errorf("unknown session %q", id)
+	}
+
+	info := s.info(id)
+	logger.Debug("Closing debug session %s", id)
+	if _, err := s.client.Close(); err != nil {
+		logger.Debug("Warning: Failed to close debug session %s cleanly: %v", id, err)
+	}
+
+	if id == DefaultSessionID {
+		m.Reset(id)
+	}
+	return info, nil
+}
+
+// Shutdown ends every session, as the server is done with them: launched programs are
+// killed, while processes the sessions attached to and remote targets connected with
+// keepTarget are left running, as with detach. Each session's Delve server, output
+// capture and debug binary go with it. Afterwards every session call fails with
+// ErrShutDown; shutting down again only reports that it already happened.
+func (m *SessionManager) Shutdown() types.ShutdownResponse {
+	m.mu.Lock()
+	if m.shutDown {
+		m.mu.Unlock()
+		return types.ShutdownResponse{
+			Status:          "success",
+			Context:         types.DebugContext{Timestamp: time.Now(), Operation: "shutdown"},
+			Sessions:        make([]types.ShutdownSession, 0),
+			AlreadyShutDown: true,
+			Summary:         "the debugger was already shut down",
+		}
+	}
+	m.shutDown = true
+	sessions := m.sessions
+	m.sessions = nil
+	m.mu.Unlock()
+
+	ids := make([]string, 0, len(sessions))
+	for id := range sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	response := types.ShutdownResponse{
+		Status:   "success",
+		Context:  types.DebugContext{Timestamp: time.Now(), Operation: "shutdown"},
+		Sessions: make([]types.ShutdownSession, 0, len(ids)),
+	}
+	for _, id := range ids {
+		closed := sessions[id].shutdown()
+		closed.SessionID = id
+		switch closed.Action {
+		case types.ShutdownKilled:
+			response.ProcessesKilled++
+		case types.ShutdownDetached:
+			response.ProcessesDetached++
+		}
+		response.Sessions = append(response.Sessions, closed)
+	}
+	response.SessionsClosed = len(response.Sessions)
+	response.Summary = fmt.Sprintf("closed %d sessions: killed %d processes and left %d running", response.SessionsClosed, response.ProcessesKilled, response.ProcessesDetached)
+	logger.Debug("Shut down: %s", response.Summary)
+	return response
+}
+
+// shutdown ends the debug session of a session, if any, the way detach does by default
+func (s *session) shutdown() types.ShutdownSession {
+	c := s.client
+	if !c.IsActive() {
+		return types.ShutdownSession{Action: types.ShutdownIdle}
+	}
+
+	// A core dump has no process to kill or leave running
+	action := types.ShutdownKilled
+	kill := c.DetachKillsByDefault()
+	switch {
+	case c.CoreFile() != "":
+		action = types.ShutdownClosed
+	case !kill:
+		action = types.ShutdownDetached
+	}
+
+	response := c.Detach(kill)
+	closed := types.ShutdownSession{Pid: response.Pid, Action: action}
+	if response.Status != "success" {
+		closed.Error = response.Context.ErrorMessage
+	}
+	return closed
+}
+
+// errShutDown tells a caller the session manager was shut down
+func errShutDown() error {
+	return fmt.Errorf("%w; restart the MCP server to debug again", ErrShutDown)
+}
+
+// List describes every session, the default session first and the others by ID
+func (m *SessionManager) List() []types.SessionInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	infos := make([]types.SessionInfo, 0, len(m.sessions))
+	for id, s := range m.sessions {
+		infos = append(infos, s.info(id))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if (infos[i].ID == DefaultSessionID) != (infos[j].ID == DefaultSessionID) {
+			return infos[i].ID == DefaultSessionID
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// idsLocked lists the session IDs; m.mu must be held
+func (m *SessionManager) idsLocked() string {
+	ids := make([]string, 0, len(m.sessions))
+	for id := range m.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ", ")
+}
+
+// info describes a session
+func (s *session) info(id string) types.SessionInfo {
+	info := types.SessionInfo{
+		ID:      id,
+		Active:  s.client.IsActive(),
+		Created: s.created,
+		Remote:  s.client.RemoteAddress(),
+		Core:    s.client.CoreFile(),
+	}
+	if info.Active {
+		info.Pid = s.client.GetPid()
+		if exited, status := s.client.Exited(); exited {
+			info.Exited = true
+			info.ExitStatus = &status
+		}
+	}
+	return info
+}
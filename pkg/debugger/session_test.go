@@ -0,0 +1,103 @@
+package debugger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestSessionManager(t *testing.T) {
+	m := NewSessionManager()
+
+	defaultClient, err := m.Get("")
+	if err != nil {
+		t.Fatalf("Expected the default session to exist, got error: %v", err)
+	}
+
+	info, err := m.Create("server")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if info.ID != "server" || info.Active {
+		t.Errorf("Expected an inactive session named server, got %+v", info)
+	}
+	if _, err := m.Create("server"); err == nil {
+		t.Errorf("Expected creating a duplicate session to fail")
+	}
+
+	generated, err := m.Create("")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if generated.ID != "session-1" {
+		t.Errorf("Expected a generated ID of session-1, got %q", generated.ID)
+	}
+
+	serverClient, err := m.Get("server")
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if serverClient == defaultClient {
+		t.Errorf("Expected sessions to have separate clients")
+	}
+
+	var ids []string
+	for _, s := range m.List() {
+		ids = append(ids, s.ID)
+	}
+	if len(ids) != 3 || ids[0] != DefaultSessionID || ids[1] != "server" || ids[2] != "session-1" {
+		t.Errorf("Expected sessions [default server session-1], got %v", ids)
+	}
+
+	if _, err := m.Close("server"); err != nil {
+		t.Fatalf("Failed to close session: %v", err)
+	}
+	if _, err := m.Get("server"); err == nil {
+		t.Errorf("Expected the closed session to be gone")
+	}
+	if client, _ := m.Get(DefaultSessionID); client != defaultClient {
+		t.Errorf("Expected closing a session to leave the default session alone")
+	}
+
+	if _, err := m.Close(DefaultSessionID); err != nil {
+		t.Fatalf("Failed to close the default session: %v", err)
+	}
+	if _, err := m.Get(DefaultSessionID); err != nil {
+		t.Errorf("Expected the default session to be reset rather than removed, got error: %v", err)
+	}
+}
+
+func TestSessionManagerShutdown(t *testing.T) {
+	m := NewSessionManager()
+	if _, err := m.Create("server"); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	response := m.Shutdown()
+	if response.Status != "success" || response.AlreadyShutDown {
+		t.Fatalf("Expected a first shutdown to succeed, got %+v", response)
+	}
+	if response.SessionsClosed != 2 || response.ProcessesKilled != 0 || response.ProcessesDetached != 0 {
+		t.Errorf("Expected two idle sessions closed, got %+v", response)
+	}
+	for i, id := range []string{DefaultSessionID, "server"} {
+		if closed := response.Sessions[i]; closed.SessionID != id || closed.Action != types.ShutdownIdle {
+			t.Errorf("Expected session %s closed while idle, got %+v", id, closed)
+		}
+	}
+
+	if _, err := m.Get(""); !errors.Is(err, ErrShutDown) {
+		t.Errorf("Expected getting a session after shutdown to fail with ErrShutDown, got %v", err)
+	}
+	if _, err := m.Create("other"); !errors.Is(err, ErrShutDown) {
+		t.Errorf("Expected creating a session after shutdown to fail with ErrShutDown, got %v", err)
+	}
+	if infos := m.List(); len(infos) != 0 {
+		t.Errorf("Expected no sessions after shutdown, got %+v", infos)
+	}
+
+	if again := m.Shutdown(); again.Status != "success" || !again.AlreadyShutDown || again.SessionsClosed != 0 {
+		t.Errorf("Expected a second shutdown to report that it already happened, got %+v", again)
+	}
+}
@@ -0,0 +1,309 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// snapshotLoadConfig loads a snapshotted value three levels deep, following pointers, so
+// the fields of the structs it holds can be compared one by one
+var snapshotLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 3,
+	MaxStringLen:       defaultEvalMaxStringLen,
+	MaxArrayValues:     defaultEvalMaxElements,
+	MaxStructFields:    -1,
+}
+
+// variableSnapshot is the value of an expression saved under a name, to compare later values
+// of it with
+type variableSnapshot struct {
+	name        string
+	expr        string
+	frame       int
+	goroutineID int64
+	position    *types.SourcePosition
+	timestamp   time.Time
+	value       *api.Variable
+}
+
+// SnapshotVariable evaluates expr in the given frame of the selected goroutine and saves
+// its value under name, replacing any snapshot with that name, for CompareSnapshot to
+// compare later values with. Snapshots are kept until they are cleared or the session ends,
+// restarts included, so values can be compared across runs.
+func (c *Client) SnapshotVariable(name string, expr string, frame int) types.SnapshotResponse {
+	if c.client == nil {
+		return c.createSnapshotResponse(nil, nil, false, fmt.Errorf("no active debug session"))
+	}
+	if name == "" {
+		return c.createSnapshotResponse(nil, nil, false, fmt.Errorf("a snapshot needs a name to compare against it by"))
+	}
+
+	state, v, err := c.evalSnapshotExpr(expr, frame)
+	if err != nil {
+		return c.createSnapshotResponse(state, nil, false, err)
+	}
+
+	snapshot := &variableSnapshot{
+		name:        name,
+		expr:        expr,
+		frame:       frame,
+		goroutineID: state.SelectedGoroutine.ID,
+		position:    getCurrentPosition(state),
+		timestamp:   time.Now(),
+		value:       v,
+	}
+	_, replaced := c.snapshots[name]
+	if c.snapshots == nil {
+		c.snapshots = make(map[string]*variableSnapshot)
+	}
+	c.snapshots[name] = snapshot
+	logger.Debug("Saved snapshot %q of %s, of type %s", name, expr, v.Type)
+
+	return c.createSnapshotResponse(state, snapshot, replaced, nil)
+}
+
+// CompareSnapshot evaluates expr again, or the expression of the snapshot when expr is
+// empty, and compares its value with the one saved under name, down to the fields,
+// elements and map entries that changed. A value whose type changed since the snapshot, as
+// when the expression names another variable now, is compared as a whole.
+func (c *Client) CompareSnapshot(name string, expr string, frame int) types.SnapshotDiffResponse {
+	if c.client == nil {
+		return c.createSnapshotDiffResponse(nil, nil, "", nil, fmt.Errorf("no active debug session"))
+	}
+
+	snapshot := c.snapshots[name]
+	if snapshot == nil {
+		return c.createSnapshotDiffResponse(nil, nil, expr, nil, fmt.Errorf("no snapshot named %q; snapshots: %s", name, c.snapshotNames()))
+	}
+	if expr == "" {
+		expr = snapshot.expr
+	}
+
+	state, v, err := c.evalSnapshotExpr(expr, frame)
+	if err != nil {
+		return c.createSnapshotDiffResponse(state, snapshot, expr, nil, err)
+	}
+
+	logger.Debug("Comparing %s with snapshot %q of %s", expr, name, snapshot.expr)
+	return c.createSnapshotDiffResponse(state, snapshot, expr, v, nil)
+}
+
+// ListSnapshots lists the saved snapshots, oldest first
+func (c *Client) ListSnapshots() types.SnapshotListResponse {
+	if c.client == nil {
+		return c.createSnapshotListResponse(nil, "list_snapshots", nil, fmt.Errorf("no active debug session"))
+	}
+	return c.createSnapshotListResponse(c.snapshotState(), "list_snapshots", nil, nil)
+}
+
+// ClearSnapshots drops the snapshot saved under name, or every snapshot when name is empty
+func (c *Client) ClearSnapshots(name string) types.SnapshotListResponse {
+	if c.client == nil {
+		return c.createSnapshotListResponse(nil, "clear_snapshots", nil, fmt.Errorf("no active debug session"))
+	}
+
+	state := c.snapshotState()
+	var cleared []string
+	switch {
+	case name == "":
+		for _, snapshot := range c.sortedSnapshots() {
+			cleared = append(cleared, snapshot.name)
+		}
+		c.snapshots = nil
+	case c.snapshots[name] == nil:
+		return c.createSnapshotListResponse(state, "clear_snapshots", nil, fmt.Errorf("no snapshot named %q; snapshots: %s", name, c.snapshotNames()))
+	default:
+		cleared = []string{name}
+		delete(c.snapshots, name)
+	}
+	logger.Debug("Cleared snapshots %v", cleared)
+	return c.createSnapshotListResponse(state, "clear_snapshots", cleared, nil)
+}
+
+// evalSnapshotExpr evaluates an expression to take a snapshot of or compare with one
+func (c *Client) evalSnapshotExpr(expr string, frame int) (*api.DebuggerState, *api.Variable, error) {
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get state: %v", err)
+	}
+	if state.Running {
+		return nil, nil, fmt.Errorf("cannot evaluate %s while the target is running; stop the target first", expr)
+	}
+	if state.SelectedGoroutine == nil {
+		return state, nil, fmt.Errorf("no goroutine selected")
+	}
+
+	scope := api.EvalScope{GoroutineID: state.SelectedGoroutine.ID, Frame: frame}
+	v, err := c.client.EvalVariable(scope, expr, snapshotLoadConfig)
+	if err != nil {
+		if isUnresolvedSymbol(err) {
+			return state, nil, fmt.Errorf("could not resolve %q: %v; variables in scope: %s", expr, err, c.scopeVariableNames(scope))
+		}
+		return state, nil, fmt.Errorf("failed to evaluate %q: %v", expr, err)
+	}
+	return state, v, nil
+}
+
+// snapshotState returns the state to report with snapshots, which are kept on this side
+// and so are listed even when it can't be read
+func (c *Client) snapshotState() *api.DebuggerState {
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		logger.Debug("Warning: Failed to get state while listing snapshots: %v", err)
+		return nil
+	}
+	return state
+}
+
+// sortedSnapshots returns the snapshots, oldest first
+func (c *Client) sortedSnapshots() []*variableSnapshot {
+	snapshots := make([]*variableSnapshot, 0, len(c.snapshots))
+	for _, snapshot := range c.snapshots {
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].timestamp.Before(snapshots[j].timestamp)
+	})
+	return snapshots
+}
+
+// snapshotNames lists the names of the snapshots, for errors naming the ones that exist
+func (c *Client) snapshotNames() string {
+	if len(c.snapshots) == 0 {
+		return "none; take one with snapshot_variable"
+	}
+	names := make([]string, 0, len(c.snapshots))
+	for _, snapshot := range c.sortedSnapshots() {
+		names = append(names, snapshot.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// convertSnapshot converts a snapshot for responses
+func convertSnapshot(snapshot *variableSnapshot) types.VariableSnapshot {
+	return types.VariableSnapshot{
+		Name:        snapshot.name,
+		Expression:  snapshot.expr,
+		Type:        snapshot.value.Type,
+		Value:       capturedValueString(snapshot.value),
+		Frame:       snapshot.frame,
+		GoroutineID: snapshot.goroutineID,
+		Location:    formatPosition(snapshot.position),
+		Position:    snapshot.position,
+		Timestamp:   snapshot.timestamp,
+	}
+}
+
+// createSnapshotResponse creates a SnapshotResponse for a snapshot just taken
+func (c *Client) createSnapshotResponse(state *api.DebuggerState, snapshot *variableSnapshot, replaced bool, err error) types.SnapshotResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "snapshot_variable"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.SnapshotResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	return types.SnapshotResponse{
+		Status:   "success",
+		Context:  context,
+		Snapshot: convertSnapshot(snapshot),
+		Replaced: replaced,
+	}
+}
+
+// createSnapshotDiffResponse creates a SnapshotDiffResponse for the changes from the value
+// of a snapshot to the current value v of expr
+func (c *Client) createSnapshotDiffResponse(state *api.DebuggerState, snapshot *variableSnapshot, expr string, v *api.Variable, err error) types.SnapshotDiffResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "compare_snapshot"
+
+	response := types.SnapshotDiffResponse{
+		Status:     "success",
+		Context:    context,
+		Expression: expr,
+	}
+	if snapshot != nil {
+		taken := convertSnapshot(snapshot)
+		response.Name = snapshot.name
+		response.Snapshot = &taken
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	response.Type = v.Type
+	response.Value = capturedValueString(v)
+	response.TypeChanged = snapshot.value.Type != v.Type
+	diffCapturedValue(expr, snapshot.value, v, &response.Changes)
+	response.Changed = len(response.Changes) > 0
+	if len(response.Changes) > maxCaptureChanges {
+		response.Changes = response.Changes[:maxCaptureChanges]
+		response.ChangesTruncated = true
+	}
+
+	since := fmt.Sprintf("snapshot %q", snapshot.name)
+	if snapshot.position != nil {
+		since += fmt.Sprintf(" taken at %s:%d", snapshot.position.File, snapshot.position.Line)
+	}
+	switch {
+	case response.TypeChanged:
+		response.Summary = fmt.Sprintf("%s is a %s, but was a %s at %s, so the values are compared as a whole", expr, v.Type, snapshot.value.Type, since)
+	case !response.Changed:
+		response.Summary = fmt.Sprintf("%s is unchanged since %s", expr, since)
+	default:
+		paths := make([]string, 0, len(response.Changes))
+		for _, change := range response.Changes {
+			paths = append(paths, change.Path)
+		}
+		response.Summary = fmt.Sprintf("%d changes since %s: %s", len(response.Changes), since, strings.Join(paths, ", "))
+		if response.ChangesTruncated {
+			response.Summary += fmt.Sprintf(", and more; only the first %d are listed", maxCaptureChanges)
+		}
+	}
+	if expr != snapshot.expr {
+		response.Summary += fmt.Sprintf("; the snapshot is of %s", snapshot.expr)
+	}
+	if state != nil && state.SelectedGoroutine != nil && state.SelectedGoroutine.ID != snapshot.goroutineID {
+		response.Summary += fmt.Sprintf("; the snapshot was taken on goroutine %d, this is goroutine %d", snapshot.goroutineID, state.SelectedGoroutine.ID)
+	}
+	return response
+}
+
+// createSnapshotListResponse creates a SnapshotListResponse of the snapshots left, after
+// the ones in cleared were dropped
+func (c *Client) createSnapshotListResponse(state *api.DebuggerState, operation string, cleared []string, err error) types.SnapshotListResponse {
+	context := c.createDebugContext(state)
+	context.Operation = operation
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.SnapshotListResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	snapshots := make([]types.VariableSnapshot, 0, len(c.snapshots))
+	for _, snapshot := range c.sortedSnapshots() {
+		snapshots = append(snapshots, convertSnapshot(snapshot))
+	}
+	return types.SnapshotListResponse{
+		Status:    "success",
+		Context:   context,
+		Snapshots: snapshots,
+		Cleared:   cleared,
+	}
+}
@@ -0,0 +1,203 @@
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// config builds a loaded main.Config with a name and port
+func config(name, port string) *api.Variable {
+	return &api.Variable{Name: "cfg", Type: "main.Config", Kind: reflect.Struct, Children: []api.Variable{
+		{Name: "Name", Type: "string", Kind: reflect.String, Value: name, Len: int64(len(name))},
+		{Name: "Port", Type: "int", Kind: reflect.Int, Value: port},
+	}}
+}
+
+func TestCreateSnapshotDiffResponse(t *testing.T) {
+	snapshot := &variableSnapshot{
+		name:     "before",
+		expr:     "cfg",
+		value:    config("api", "80"),
+		position: &types.SourcePosition{File: "main.go", Line: 12, Function: "main.main"},
+	}
+
+	testCases := []struct {
+		name            string
+		expr            string
+		v               *api.Variable
+		expectedChanges []types.CaptureChange
+		typeChanged     bool
+		summary         string
+	}{
+		{
+			name:    "Unchanged",
+			expr:    "cfg",
+			v:       config("api", "80"),
+			summary: `cfg is unchanged since snapshot "before" taken at main.go:12`,
+		},
+		{
+			name:            "Field changed",
+			expr:            "cfg",
+			v:               config("api", "8080"),
+			expectedChanges: []types.CaptureChange{{Path: "cfg.Port", Change: captureChanged, Old: "80", New: "8080"}},
+			summary:         `1 changes since snapshot "before" taken at main.go:12: cfg.Port`,
+		},
+		{
+			name:            "Type changed",
+			expr:            "cfg",
+			v:               &api.Variable{Type: "string", Kind: reflect.String, Value: "api", Len: 3},
+			expectedChanges: []types.CaptureChange{{Path: "cfg", Change: captureChanged, Old: `{Name:"api", Port:80}`, New: `"api"`}},
+			typeChanged:     true,
+			summary:         `cfg is a string, but was a main.Config at snapshot "before" taken at main.go:12, so the values are compared as a whole`,
+		},
+		{
+			name:    "Other expression",
+			expr:    "other",
+			v:       config("api", "80"),
+			summary: `other is unchanged since snapshot "before" taken at main.go:12; the snapshot is of cfg`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := NewClient().createSnapshotDiffResponse(nil, snapshot, tc.expr, tc.v, nil)
+			if response.TypeChanged != tc.typeChanged {
+				t.Errorf("Expected typeChanged %v, got %v", tc.typeChanged, response.TypeChanged)
+			}
+			if response.Changed != (len(tc.expectedChanges) > 0) {
+				t.Errorf("Expected changed %v, got %v", len(tc.expectedChanges) > 0, response.Changed)
+			}
+			if tc.typeChanged {
+				// The value of a struct in another type is rendered in full; only its path and kind matter
+				if len(response.Changes) != 1 || response.Changes[0].Path != "cfg" {
+					t.Errorf("Expected cfg to change as a whole, got %+v", response.Changes)
+				}
+			} else if !reflect.DeepEqual(response.Changes, tc.expectedChanges) {
+				t.Errorf("Expected changes %+v, got %+v", tc.expectedChanges, response.Changes)
+			}
+			if response.Summary != tc.summary {
+				t.Errorf("Expected summary %q, got %q", tc.summary, response.Summary)
+			}
+		})
+	}
+}
+
+func TestSortedSnapshots(t *testing.T) {
+	now := time.Now()
+	c := &Client{snapshots: map[string]*variableSnapshot{
+		"later":   {name: "later", timestamp: now.Add(time.Second), value: config("a", "1")},
+		"earlier": {name: "earlier", timestamp: now, value: config("a", "1")},
+	}}
+	if names := c.snapshotNames(); names != "earlier, later" {
+		t.Errorf("Expected the snapshots oldest first, got %q", names)
+	}
+
+	c.snapshots = nil
+	if names := c.snapshotNames(); !strings.HasPrefix(names, "none") {
+		t.Errorf("Expected no snapshots, got %q", names)
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		cfg     = config("api", "80")
+		configs []*api.LoadConfig
+		frames  []int
+	)
+	c, f := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(12)),
+		"Eval": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.EvalIn
+			decodeFakeArgs(t, raw, &args)
+			mu.Lock()
+			defer mu.Unlock()
+			configs, frames = append(configs, args.Cfg), append(frames, args.Scope.Frame)
+			if args.Expr != "cfg" {
+				return nil, fmt.Errorf("could not find symbol value for %s", args.Expr)
+			}
+			return rpc2.EvalOut{Variable: cfg}, nil
+		},
+	})
+
+	taken := c.SnapshotVariable("before", "cfg", 1)
+	if taken.Status != "success" || taken.Replaced || taken.Snapshot.Value != "{Name:api, Port:80}" || taken.Snapshot.Position == nil || taken.Snapshot.Position.Line != 12 {
+		t.Fatalf("Expected a snapshot of cfg at main.go:12, got %+v", taken)
+	}
+	if len(configs) != 1 || !reflect.DeepEqual(*configs[0], snapshotLoadConfig) || frames[0] != 1 {
+		t.Errorf("Expected cfg evaluated in frame 1 with the snapshot load config, got %+v in frames %v", configs, frames)
+	}
+	if again := c.SnapshotVariable("before", "cfg", 1); !again.Replaced {
+		t.Error("Expected a second snapshot under the same name to replace the first")
+	}
+	c.SnapshotVariable("other", "cfg", 0)
+
+	// The snapshot is compared with the value the expression has now, in the frame given
+	mu.Lock()
+	cfg = config("api", "8080")
+	mu.Unlock()
+	diff := c.CompareSnapshot("before", "", 1)
+	if diff.Status != "success" || !diff.Changed || diff.Expression != "cfg" {
+		t.Fatalf("Expected cfg changed since the snapshot, got %+v", diff)
+	}
+	if expected := []types.CaptureChange{{Path: "cfg.Port", Change: captureChanged, Old: "80", New: "8080"}}; !reflect.DeepEqual(diff.Changes, expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, diff.Changes)
+	}
+
+	// The snapshot is kept when comparing with it fails
+	if failed := c.CompareSnapshot("before", "missing", 0); failed.Status != "error" || failed.Snapshot == nil || c.snapshots["before"] == nil {
+		t.Errorf("Expected an error against the kept snapshot, got %+v", failed)
+	}
+	if missing := c.CompareSnapshot("after", "", 0); missing.Status != "error" || !strings.Contains(missing.Context.ErrorMessage, "snapshots: before, other") {
+		t.Errorf("Expected the snapshots named in the error, got %q", missing.Context.ErrorMessage)
+	}
+
+	list := c.ListSnapshots()
+	if list.Status != "success" || len(list.Snapshots) != 2 || list.Snapshots[0].Name != "before" || list.Snapshots[1].Name != "other" {
+		t.Errorf("Expected the snapshots before and other, oldest first, got %+v", list.Snapshots)
+	}
+
+	cleared := c.ClearSnapshots("before")
+	if cleared.Status != "success" || !reflect.DeepEqual(cleared.Cleared, []string{"before"}) || len(cleared.Snapshots) != 1 {
+		t.Errorf("Expected only before cleared, got %+v", cleared)
+	}
+	if cleared := c.ClearSnapshots("before"); cleared.Status != "error" {
+		t.Errorf("Expected clearing a missing snapshot to fail, got %+v", cleared)
+	}
+	cleared = c.ClearSnapshots("")
+	if cleared.Status != "success" || !reflect.DeepEqual(cleared.Cleared, []string{"other"}) || len(cleared.Snapshots) != 0 {
+		t.Errorf("Expected the rest cleared, got %+v", cleared)
+	}
+
+	// Snapshots are listed even when the state can't be read
+	f.handle("State", func(json.RawMessage) (interface{}, error) {
+		return nil, fmt.Errorf("connection reset")
+	})
+	if list := c.ListSnapshots(); list.Status != "success" {
+		t.Errorf("Expected the snapshots listed without a state, got %s", list.Context.ErrorMessage)
+	}
+}
+
+func TestSnapshotsWithoutSession(t *testing.T) {
+	c := NewClient()
+	responses := map[string]types.DebugContext{
+		"snapshot_variable": c.SnapshotVariable("before", "cfg", 0).Context,
+		"compare_snapshot":  c.CompareSnapshot("before", "", 0).Context,
+		"list_snapshots":    c.ListSnapshots().Context,
+		"clear_snapshots":   c.ClearSnapshots("").Context,
+	}
+	for operation, context := range responses {
+		if context.Operation != operation || !strings.Contains(context.ErrorMessage, "no active debug session") {
+			t.Errorf("Expected a %s error without a session, got %+v", operation, context)
+		}
+	}
+}
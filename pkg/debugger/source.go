@@ -0,0 +1,133 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxSourceLines caps how many lines a single listing returns
+const maxSourceLines = 200
+
+// ListSource returns the lines around a source location. When file is empty the
+// current execution location is used.
+func (c *Client) ListSource(file string, line, contextLines int) types.SourceResponse {
+	var state *api.DebuggerState
+	currentFile, currentLine := "", 0
+
+	if c.client != nil {
+		var err error
+		state, err = c.client.GetState()
+		if err != nil {
+			logger.Debug("Warning: Failed to get state while listing source: %v", err)
+		}
+		if state != nil && state.CurrentThread != nil {
+			currentFile, currentLine = state.CurrentThread.File, state.CurrentThread.Line
+		}
+	}
+
+	if file == "" {
+		if c.client == nil {
+			return c.createSourceResponse(nil, file, line, nil, fmt.Errorf("no active debug session and no file given"))
+		}
+		if currentFile == "" {
+			return c.createSourceResponse(state, file, line, nil, fmt.Errorf("no current location, specify a file and line"))
+		}
+		file = currentFile
+		if line <= 0 {
+			line = currentLine
+		}
+	}
+
+	if line <= 0 {
+		line = 1
+	}
+	if contextLines < 0 {
+		contextLines = 0
+	}
+	if 2*contextLines+1 > maxSourceLines {
+		contextLines = (maxSourceLines - 1) / 2
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c.createSourceResponse(state, file, line, nil, fmt.Errorf("source file %s is not available on disk", file))
+		}
+		return c.createSourceResponse(state, file, line, nil, fmt.Errorf("failed to open source file: %v", err))
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	start, end := line-contextLines, line+contextLines
+	if start < 1 {
+		start = 1
+	}
+
+	var lines []types.SourceLine
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan() && n <= end; n++ {
+		if n < start {
+			continue
+		}
+		lines = append(lines, types.SourceLine{
+			Number:  n,
+			Text:    scanner.Text(),
+			Current: file == currentFile && n == currentLine,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return c.createSourceResponse(state, file, line, nil, fmt.Errorf("failed to read source file: %v", err))
+	}
+
+	if len(lines) == 0 {
+		return c.createSourceResponse(state, file, line, nil, fmt.Errorf("line %d is past the end of %s", line, file))
+	}
+
+	return c.createSourceResponse(state, file, line, lines, nil)
+}
+
+// formatSourceListing renders source lines with line numbers, marking the current line with "=>"
+func formatSourceListing(lines []types.SourceLine) string {
+	var b strings.Builder
+	for _, l := range lines {
+		marker := "  "
+		if l.Current {
+			marker = "=>"
+		}
+		fmt.Fprintf(&b, "%s %5d: %s\n", marker, l.Number, l.Text)
+	}
+	return b.String()
+}
+
+// createSourceResponse creates a SourceResponse
+func (c *Client) createSourceResponse(state *api.DebuggerState, file string, line int, lines []types.SourceLine, err error) types.SourceResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "list_source"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.SourceResponse{
+			Status:  "error",
+			Context: context,
+			File:    file,
+			Line:    line,
+		}
+	}
+
+	return types.SourceResponse{
+		Status:    "success",
+		Context:   context,
+		File:      file,
+		Line:      line,
+		StartLine: lines[0].Number,
+		EndLine:   lines[len(lines)-1].Number,
+		Lines:     lines,
+		Listing:   formatSourceListing(lines),
+	}
+}
@@ -0,0 +1,177 @@
+package debugger
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxSourceFileResults caps how many source files a single listing returns
+const maxSourceFileResults = 500
+
+// ListSources returns the source files compiled into the target whose paths match the
+// filter regex, sorted by path, with the import path of the package each belongs to. An
+// empty filter matches every file; pkg, when set, keeps only files of packages with that
+// import path prefix. Files of the standard library, and entries without a path such as
+// <autogenerated>, are left out unless includeStdlib is true. Total counts every match,
+// also when the page is cut short by limit.
+func (c *Client) ListSources(filter, pkg string, includeStdlib bool, limit, offset int) types.SourceFileListResponse {
+	if c.client == nil {
+		return c.createSourceFileListResponse(nil, nil, 0, limit, offset, fmt.Errorf("no active debug session"))
+	}
+
+	if _, err := regexp.Compile(filter); err != nil {
+		return c.createSourceFileListResponse(nil, nil, 0, limit, offset, fmt.Errorf("invalid filter %q: %v", filter, err))
+	}
+
+	if limit <= 0 || limit > maxSourceFileResults {
+		limit = maxSourceFileResults
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createSourceFileListResponse(nil, nil, 0, limit, offset, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createSourceFileListResponse(nil, nil, 0, limit, offset, fmt.Errorf("cannot list source files while the target is running; stop the target first"))
+	}
+
+	logger.Debug("Listing source files matching %q in package %q, stdlib %v, limit %d, offset %d", filter, pkg, includeStdlib, limit, offset)
+
+	paths, err := c.client.ListSources(filter)
+	if err != nil {
+		return c.createSourceFileListResponse(state, nil, 0, limit, offset, fmt.Errorf("failed to list source files: %v", err))
+	}
+
+	packages, err := c.client.ListPackagesBuildInfo("", true)
+	if err != nil {
+		logger.Debug("Warning: Failed to list packages of the target: %v", err)
+	}
+	dirPackages, stdlibDir := sourcePackages(packages)
+
+	var matched []types.SourceFile
+	for _, path := range paths {
+		file := types.SourceFile{Path: path, Package: sourcePackage(path, dirPackages, stdlibDir)}
+		if !includeStdlib && isStdlibSource(path, stdlibDir) {
+			continue
+		}
+		if pkg != "" && !strings.HasPrefix(file.Package, pkg) {
+			continue
+		}
+		matched = append(matched, file)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Path < matched[j].Path })
+
+	start, end := pageBounds(len(matched), limit, offset)
+
+	return c.createSourceFileListResponse(state, matched[start:end], len(matched), limit, offset, nil)
+}
+
+// sourcePackages maps the directories of the target's packages to their import paths, and
+// finds the source directory of the standard library from where the runtime package is.
+// Delve only knows the packages that have a compile unit of their own.
+func sourcePackages(packages []api.PackageBuildInfo) (map[string]string, string) {
+	dirPackages := make(map[string]string)
+	var stdlibDir string
+	for _, p := range packages {
+		if p.DirectoryPath == "" {
+			continue
+		}
+		dirPackages[p.DirectoryPath] = p.ImportPath
+		if p.ImportPath == "runtime" {
+			stdlibDir = filepath.Dir(p.DirectoryPath)
+		}
+	}
+	return dirPackages, stdlibDir
+}
+
+// sourcePackage returns the import path of the package a source file belongs to, from the
+// packages Delve knows of, the standard library directory or the module cache, or "" when
+// it cannot be told
+func sourcePackage(path string, dirPackages map[string]string, stdlibDir string) string {
+	dir := filepath.Dir(path)
+	if importPath, ok := dirPackages[dir]; ok {
+		return importPath
+	}
+	if stdlibDir != "" {
+		if rel, err := filepath.Rel(stdlibDir, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return moduleCachePackage(filepath.ToSlash(dir))
+}
+
+// moduleCachePackage works out the import path of a package in the module cache from its
+// directory, such as ".../pkg/mod/github.com/!burnt!sushi/toml@v1.2.0/internal", which is
+// github.com/BurntSushi/toml/internal
+func moduleCachePackage(dir string) string {
+	_, rest, ok := strings.Cut(dir, "/pkg/mod/")
+	if !ok {
+		return ""
+	}
+	modulePath, version, ok := strings.Cut(rest, "@")
+	if !ok {
+		return ""
+	}
+
+	var decoded strings.Builder
+	for i := 0; i < len(modulePath); i++ {
+		// The module cache escapes upper case letters as ! followed by the lower case one
+		if modulePath[i] == '!' && i+1 < len(modulePath) {
+			i++
+			decoded.WriteString(strings.ToUpper(modulePath[i : i+1]))
+			continue
+		}
+		decoded.WriteByte(modulePath[i])
+	}
+
+	if _, subdir, ok := strings.Cut(version, "/"); ok {
+		return decoded.String() + "/" + subdir
+	}
+	return decoded.String()
+}
+
+// isStdlibSource reports whether a source file belongs to the standard library, or is an
+// entry without a path the compiler generates
+func isStdlibSource(path, stdlibDir string) bool {
+	if !filepath.IsAbs(path) {
+		return true
+	}
+	return stdlibDir != "" && strings.HasPrefix(path, stdlibDir+string(filepath.Separator))
+}
+
+// createSourceFileListResponse creates a SourceFileListResponse
+func (c *Client) createSourceFileListResponse(state *api.DebuggerState, files []types.SourceFile, total, limit, offset int, err error) types.SourceFileListResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "list_sources"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.SourceFileListResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	if files == nil {
+		files = []types.SourceFile{}
+	}
+	return types.SourceFileListResponse{
+		Status:    "success",
+		Context:   context,
+		Files:     files,
+		Total:     total,
+		Truncated: offset+len(files) < total,
+		Limit:     limit,
+		Offset:    offset,
+	}
+}
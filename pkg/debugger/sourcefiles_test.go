@@ -0,0 +1,150 @@
+package debugger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+func TestSourcePackage(t *testing.T) {
+	dirPackages, stdlibDir := sourcePackages([]api.PackageBuildInfo{
+		{ImportPath: "main", DirectoryPath: "/home/me/app"},
+		{ImportPath: "runtime", DirectoryPath: "/usr/local/go/src/runtime"},
+		{ImportPath: "unsafe"},
+	})
+	if stdlibDir != "/usr/local/go/src" {
+		t.Fatalf("Expected the standard library in /usr/local/go/src, got %q", stdlibDir)
+	}
+
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/home/me/app/main.go", expected: "main"},
+		{path: "/usr/local/go/src/runtime/proc.go", expected: "runtime"},
+		{path: "/usr/local/go/src/net/http/server.go", expected: "net/http"},
+		{path: "/root/go/pkg/mod/github.com/mark3labs/mcp-go@v0.15.0/server/server.go", expected: "github.com/mark3labs/mcp-go/server"},
+		{path: "/root/go/pkg/mod/github.com/!burnt!sushi/toml@v1.2.0/decode.go", expected: "github.com/BurntSushi/toml"},
+		{path: "/home/me/app/handlers/users.go", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			if result := sourcePackage(tc.path, dirPackages, stdlibDir); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestIsStdlibSource(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "/usr/local/go/src/fmt/print.go", expected: true},
+		{path: "<autogenerated>", expected: true},
+		{path: "/home/me/app/main.go", expected: false},
+		{path: "/usr/local/go/srcfoo/main.go", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			if result := isStdlibSource(tc.path, "/usr/local/go/src"); result != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestListSources(t *testing.T) {
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+		"ListSources": fakeResult(rpc2.ListSourcesOut{Sources: []string{
+			"/home/me/app/main.go",
+			"/usr/local/go/src/fmt/print.go",
+			"/home/me/app/store/db.go",
+			"<autogenerated>",
+			"/home/me/app/api/users.go",
+			"/usr/local/go/src/runtime/proc.go",
+		}}),
+		"ListPackagesBuildInfo": fakeResult(rpc2.ListPackagesBuildInfoOut{List: []api.PackageBuildInfo{
+			{ImportPath: "main", DirectoryPath: "/home/me/app"},
+			{ImportPath: "example.com/app/api", DirectoryPath: "/home/me/app/api"},
+			{ImportPath: "example.com/app/store", DirectoryPath: "/home/me/app/store"},
+			{ImportPath: "runtime", DirectoryPath: "/usr/local/go/src/runtime"},
+		}}),
+	})
+
+	testCases := []struct {
+		name          string
+		pkg           string
+		includeStdlib bool
+		limit         int
+		offset        int
+		expected      []string
+		total         int
+		truncated     bool
+	}{
+		{
+			name:     "Program files, sorted by path",
+			expected: []string{"/home/me/app/api/users.go", "/home/me/app/main.go", "/home/me/app/store/db.go"},
+			total:    3,
+		},
+		{
+			name:          "With the standard library",
+			includeStdlib: true,
+			expected:      []string{"/home/me/app/api/users.go", "/home/me/app/main.go", "/home/me/app/store/db.go", "/usr/local/go/src/fmt/print.go", "/usr/local/go/src/runtime/proc.go", "<autogenerated>"},
+			total:         6,
+		},
+		{
+			name:     "Package prefix",
+			pkg:      "example.com/app",
+			expected: []string{"/home/me/app/api/users.go", "/home/me/app/store/db.go"},
+			total:    2,
+		},
+		{
+			name:      "Page",
+			limit:     1,
+			offset:    1,
+			expected:  []string{"/home/me/app/main.go"},
+			total:     3,
+			truncated: true,
+		},
+		{
+			name:   "Offset past the end",
+			offset: 5,
+			total:  3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.ListSources("", tc.pkg, tc.includeStdlib, tc.limit, tc.offset)
+			if response.Status != "success" {
+				t.Fatalf("Expected the source files, got %s", response.Context.ErrorMessage)
+			}
+			var paths []string
+			for _, file := range response.Files {
+				paths = append(paths, file.Path)
+			}
+			if !reflect.DeepEqual(paths, tc.expected) {
+				t.Errorf("Expected files %v, got %v", tc.expected, paths)
+			}
+			if response.Total != tc.total || response.Truncated != tc.truncated {
+				t.Errorf("Expected total %d, truncated %v, got %d, %v", tc.total, tc.truncated, response.Total, response.Truncated)
+			}
+		})
+	}
+
+	response := c.ListSources("", "", false, 0, 0)
+	if len(response.Files) == 0 || response.Files[0].Package != "example.com/app/api" {
+		t.Errorf("Expected each file with its package, got %+v", response.Files)
+	}
+	if response := c.ListSources("(", "", false, 0, 0); response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "invalid filter") {
+		t.Errorf("Expected an invalid filter error, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+}
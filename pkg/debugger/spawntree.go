@@ -0,0 +1,204 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// Delve reports where each goroutine was created, but not by which goroutine. The runtime
+// records that in g.parentGoid, since Go 1.21, and only for the creator itself: the tree
+// of a goroutine's descendants is put together here from every goroutine's creator.
+
+// parentGoidExpr evaluates to the ID of the goroutine that created the scope's goroutine
+const parentGoidExpr = "runtime.curg.parentGoid"
+
+// ChildGoroutines returns the goroutines parentID created, the ones those created, and so
+// on, with where each is now and the go statement that started it. The parent may have
+// exited; its descendants are still found. A goroutine whose creator has exited can't be
+// traced back past it, so descendants started through an exited goroutine are missed.
+func (c *Client) ChildGoroutines(parentID int64) types.ChildGoroutinesResponse {
+	if c.client == nil {
+		return c.createChildGoroutinesResponse(nil, parentID, nil, nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createChildGoroutinesResponse(nil, parentID, nil, nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createChildGoroutinesResponse(nil, parentID, nil, nil, nil, fmt.Errorf("cannot list goroutines while the target is running; stop the target first"))
+	}
+
+	gs, _, err := c.client.ListGoroutines(0, 0)
+	if err != nil {
+		return c.createChildGoroutinesResponse(state, parentID, nil, nil, nil, fmt.Errorf("failed to list goroutines: %v", err))
+	}
+
+	logger.Debug("Reading the creators of %d goroutines", len(gs))
+	parents, unreadable, err := c.goroutineParents(gs)
+	if err != nil {
+		return c.createChildGoroutinesResponse(state, parentID, nil, nil, nil, err)
+	}
+
+	byID := make(map[int64]*api.Goroutine, len(gs))
+	for _, g := range gs {
+		byID[g.ID] = g
+	}
+
+	var parent *types.GoroutineDetails
+	if g := byID[parentID]; g != nil {
+		details := c.convertGoroutineDetails(g)
+		parent = &details
+	}
+
+	tree := spawnTree(parentID, parents)
+	if parent == nil && len(tree) == 0 {
+		return c.createChildGoroutinesResponse(state, parentID, nil, nil, nil, fmt.Errorf("goroutine %d not found, and no live goroutine was created by it", parentID))
+	}
+
+	descendants := make([]types.SpawnedGoroutine, 0, len(tree))
+	for _, node := range tree {
+		descendants = append(descendants, types.SpawnedGoroutine{
+			GoroutineDetails: c.convertGoroutineDetails(byID[node.id]),
+			ParentID:         parents[node.id],
+			Depth:            node.depth,
+		})
+	}
+
+	var notes []string
+	if parent == nil {
+		notes = append(notes, fmt.Sprintf("goroutine %d has exited; the goroutines it created are still listed", parentID))
+	}
+	if orphans := orphanedGoroutines(parents, byID); orphans > 0 {
+		notes = append(notes, fmt.Sprintf("%d goroutines were created by goroutines that have exited, so any of them started under goroutine %d through those can't be traced to it", orphans, parentID))
+	}
+	if unreadable > 0 {
+		notes = append(notes, fmt.Sprintf("the creator of %d goroutines could not be read, so they are left out", unreadable))
+	}
+
+	return c.createChildGoroutinesResponse(state, parentID, parent, descendants, notes, nil)
+}
+
+// goroutineParents reads the ID of the goroutine that created each goroutine, keyed by
+// goroutine ID. Goroutines whose creator can't be read are counted and left out; the runtime
+// not recording creators at all is an error.
+func (c *Client) goroutineParents(gs []*api.Goroutine) (map[int64]int64, int, error) {
+	parents := make(map[int64]int64, len(gs))
+	var unreadable int
+	var lastErr error
+	for _, g := range gs {
+		if g.Unreadable != "" || getGoroutineStatus(g) == "dead" {
+			continue
+		}
+
+		v, err := c.client.EvalVariable(api.EvalScope{GoroutineID: g.ID, Frame: 0}, parentGoidExpr, api.LoadConfig{})
+		if err == nil && v.Unreadable != "" {
+			err = fmt.Errorf("%s", v.Unreadable)
+		}
+		if err != nil {
+			logger.Debug("Warning: Failed to read the creator of goroutine %d: %v", g.ID, err)
+			unreadable++
+			lastErr = err
+			continue
+		}
+
+		id, err := strconv.ParseInt(v.Value, 10, 64)
+		if err != nil {
+			unreadable++
+			lastErr = fmt.Errorf("unexpected creator ID %q", v.Value)
+			continue
+		}
+		parents[g.ID] = id
+	}
+
+	if len(parents) == 0 && lastErr != nil {
+		return nil, 0, fmt.Errorf("failed to read which goroutine created each one; the runtime records it from Go 1.21 on: %v", lastErr)
+	}
+	return parents, unreadable, nil
+}
+
+// spawnNode is a goroutine in a spawn tree, and how many go statements it is from the root
+type spawnNode struct {
+	id    int64
+	depth int
+}
+
+// spawnTree walks the goroutines created by root, transitively, in depth-first order with
+// each goroutine's children sorted by ID. parents maps each goroutine to its creator. A
+// goroutine is visited once, so bad data that forms a cycle can't loop forever.
+func spawnTree(root int64, parents map[int64]int64) []spawnNode {
+	children := make(map[int64][]int64)
+	for id, parent := range parents {
+		if id != parent {
+			children[parent] = append(children[parent], id)
+		}
+	}
+	for _, ids := range children {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+
+	var tree []spawnNode
+	visited := map[int64]bool{root: true}
+	var walk func(id int64, depth int)
+	walk = func(id int64, depth int) {
+		for _, child := range children[id] {
+			if visited[child] {
+				continue
+			}
+			visited[child] = true
+			tree = append(tree, spawnNode{id: child, depth: depth})
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 1)
+	return tree
+}
+
+// orphanedGoroutines counts the goroutines whose creator is no longer among them. The main
+// goroutine and ones the runtime starts before it have creator 0, and don't count.
+func orphanedGoroutines(parents map[int64]int64, byID map[int64]*api.Goroutine) int {
+	var orphans int
+	for _, parent := range parents {
+		if parent != 0 && byID[parent] == nil {
+			orphans++
+		}
+	}
+	return orphans
+}
+
+// createChildGoroutinesResponse creates a ChildGoroutinesResponse
+func (c *Client) createChildGoroutinesResponse(state *api.DebuggerState, parentID int64, parent *types.GoroutineDetails, descendants []types.SpawnedGoroutine, notes []string, err error) types.ChildGoroutinesResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "child_goroutines"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.ChildGoroutinesResponse{
+			Status:   "error",
+			Context:  context,
+			ParentID: parentID,
+		}
+	}
+
+	response := types.ChildGoroutinesResponse{
+		Status:      "success",
+		Context:     context,
+		ParentID:    parentID,
+		Parent:      parent,
+		Descendants: descendants,
+		Notes:       notes,
+	}
+	for _, g := range descendants {
+		if g.Depth == 1 {
+			response.Direct++
+		}
+	}
+
+	response.Summary = fmt.Sprintf("goroutine %d has %d live descendants, %d of them created by it directly", parentID, len(descendants), response.Direct)
+	return response
+}
@@ -0,0 +1,139 @@
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestSpawnTree(t *testing.T) {
+	testCases := []struct {
+		name     string
+		root     int64
+		parents  map[int64]int64
+		expected []spawnNode
+	}{
+		{
+			name:     "Nested fan-out",
+			root:     1,
+			parents:  map[int64]int64{1: 0, 7: 1, 5: 1, 9: 5, 10: 9, 12: 3},
+			expected: []spawnNode{{id: 5, depth: 1}, {id: 9, depth: 2}, {id: 10, depth: 3}, {id: 7, depth: 1}},
+		},
+		{
+			name:     "Exited root",
+			root:     4,
+			parents:  map[int64]int64{1: 0, 8: 4, 11: 8},
+			expected: []spawnNode{{id: 8, depth: 1}, {id: 11, depth: 2}},
+		},
+		{
+			name:     "Cycle",
+			root:     2,
+			parents:  map[int64]int64{2: 3, 3: 2, 4: 4},
+			expected: []spawnNode{{id: 3, depth: 1}},
+		},
+		{
+			name:    "No children",
+			root:    7,
+			parents: map[int64]int64{1: 0, 7: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := spawnTree(tc.root, tc.parents)
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected %+v, got %+v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestOrphanedGoroutines(t *testing.T) {
+	byID := map[int64]*api.Goroutine{1: {ID: 1}, 2: {ID: 2}, 6: {ID: 6}, 8: {ID: 8}}
+	parents := map[int64]int64{1: 0, 2: 1, 6: 4, 8: 4}
+	if result := orphanedGoroutines(parents, byID); result != 2 {
+		t.Errorf("Expected 2 goroutines whose creator exited, got %d", result)
+	}
+}
+
+func TestCreateChildGoroutinesResponse(t *testing.T) {
+	descendants := []types.SpawnedGoroutine{{ParentID: 1, Depth: 1}, {ParentID: 5, Depth: 2}, {ParentID: 1, Depth: 1}}
+	response := NewClient().createChildGoroutinesResponse(nil, 1, nil, descendants, nil, nil)
+	if response.Direct != 2 {
+		t.Errorf("Expected 2 direct children, got %d", response.Direct)
+	}
+	if expected := "goroutine 1 has 3 live descendants, 2 of them created by it directly"; response.Summary != expected {
+		t.Errorf("Expected summary %q, got %q", expected, response.Summary)
+	}
+}
+
+// spawningTarget returns a fake Delve whose goroutines were created by the ones in
+// parents: 1 is main, 2 and 4 were created by it and 3 by 2, 5 by goroutine 9, which has
+// exited, and 6's creator can't be read. Without parents, the runtime records no creators.
+func spawningTarget(t *testing.T, parents map[int64]string) *Client {
+	t.Helper()
+	gs := []*api.Goroutine{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}, {ID: 6}, {ID: 7, Status: proc.Gdead}}
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State":          fakeState(stoppedState(10)),
+		"ListGoroutines": fakeResult(rpc2.ListGoroutinesOut{Goroutines: gs, Nextg: -1}),
+		"Eval": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.EvalIn
+			decodeFakeArgs(t, raw, &args)
+			parent, ok := parents[args.Scope.GoroutineID]
+			if args.Expr != parentGoidExpr || !ok {
+				return nil, fmt.Errorf("runtime.g has no member parentGoid")
+			}
+			return rpc2.EvalOut{Variable: &api.Variable{Type: "uint64", Kind: reflect.Uint64, Value: parent}}, nil
+		},
+	})
+	return c
+}
+
+func TestChildGoroutines(t *testing.T) {
+	c := spawningTarget(t, map[int64]string{1: "0", 2: "1", 3: "2", 4: "1", 5: "9"})
+
+	response := c.ChildGoroutines(1)
+	if response.Status != "success" || response.Parent == nil || response.Direct != 2 {
+		t.Fatalf("Expected goroutine 1 to have created 2 goroutines directly, got %+v", response)
+	}
+	var tree []string
+	for _, g := range response.Descendants {
+		tree = append(tree, fmt.Sprintf("%d<-%d@%d", g.ID, g.ParentID, g.Depth))
+	}
+	if !reflect.DeepEqual(tree, []string{"2<-1@1", "3<-2@2", "4<-1@1"}) {
+		t.Errorf("Expected the descendants depth first, got %v", tree)
+	}
+	expectedNotes := []string{
+		"1 goroutines were created by goroutines that have exited, so any of them started under goroutine 1 through those can't be traced to it",
+		"the creator of 1 goroutines could not be read, so they are left out",
+	}
+	if !reflect.DeepEqual(response.Notes, expectedNotes) {
+		t.Errorf("Expected notes %q, got %q", expectedNotes, response.Notes)
+	}
+
+	// The children of an exited goroutine are still found
+	response = c.ChildGoroutines(9)
+	if response.Status != "success" || response.Parent != nil || len(response.Descendants) != 1 || response.Descendants[0].ID != 5 {
+		t.Errorf("Expected goroutine 5 as the only child of exited goroutine 9, got %+v", response)
+	}
+	if len(response.Notes) == 0 || !strings.Contains(response.Notes[0], "goroutine 9 has exited") {
+		t.Errorf("Expected a note that goroutine 9 exited, got %q", response.Notes)
+	}
+
+	if response := c.ChildGoroutines(42); response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "goroutine 42 not found") {
+		t.Errorf("Expected goroutine 42 not to be found, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+
+	// A runtime that doesn't record creators can't build the tree
+	response = spawningTarget(t, nil).ChildGoroutines(1)
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "from Go 1.21 on") {
+		t.Errorf("Expected the creators to need Go 1.21, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+}
@@ -0,0 +1,159 @@
+package debugger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// Backtrace returns the call stack of a goroutine, optionally with argument values.
+// A goroutineID of 0 uses the currently selected goroutine. followPointers overrides the
+// session's SetFollowPointers setting for the arguments when not nil.
+func (c *Client) Backtrace(goroutineID int64, depth int, includeArgs bool, followPointers *bool) types.BacktraceResponse {
+	if c.client == nil {
+		return c.createBacktraceResponse(nil, 0, nil, fmt.Errorf("no active debug session"))
+	}
+
+	// Use the non-blocking call so a running target is reported instead of waited on
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createBacktraceResponse(nil, goroutineID, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createBacktraceResponse(nil, goroutineID, nil, fmt.Errorf("cannot get backtrace while the target is running; stop the target first"))
+	}
+
+	if goroutineID == 0 {
+		if state.SelectedGoroutine == nil {
+			return c.createBacktraceResponse(state, 0, nil, fmt.Errorf("no goroutine selected"))
+		}
+		goroutineID = state.SelectedGoroutine.ID
+	}
+
+	// Only load variables when arguments were asked for, and keep them small
+	var cfg *api.LoadConfig
+	if includeArgs {
+		cfg = &api.LoadConfig{
+			FollowPointers:     c.followPointers(followPointers),
+			MaxVariableRecurse: 1,
+			MaxStringLen:       64,
+			MaxArrayValues:     10,
+			MaxStructFields:    -1,
+		}
+	}
+
+	logger.Debug("Getting backtrace for goroutine %d with depth %d", goroutineID, depth)
+	frames, err := c.client.Stacktrace(goroutineID, depth, 0, cfg)
+	if err != nil {
+		return c.createBacktraceResponse(state, goroutineID, nil, fmt.Errorf("failed to get stack trace for goroutine %d: %v", goroutineID, err))
+	}
+
+	stack := make([]types.StackFrame, 0, len(frames))
+	for i, frame := range frames {
+		stack = append(stack, convertStackFrame(i, frame, includeArgs))
+	}
+
+	return c.createBacktraceResponse(state, goroutineID, stack, nil)
+}
+
+// convertStackFrame converts a Delve stack frame to our type
+func convertStackFrame(index int, frame api.Stackframe, includeArgs bool) types.StackFrame {
+	function := getFunctionNameFromLocation(frame.Location)
+	stackFrame := types.StackFrame{
+		Index:     index,
+		Function:  function,
+		File:      frame.File,
+		Line:      frame.Line,
+		Location:  getFrameLocation(frame),
+		Position:  getFramePosition(frame),
+		IsRuntime: isRuntimeFunction(function),
+		Error:     frame.Err,
+	}
+
+	if includeArgs {
+		for i := range frame.Arguments {
+			arg := &frame.Arguments[i]
+			stackFrame.Arguments = append(stackFrame.Arguments, types.Variable{
+				DelveVar: arg,
+				Name:     arg.Name,
+				Value:    formatVariableValue(arg),
+				Type:     arg.Type,
+				Scope:    "argument",
+				Kind:     getVariableKind(arg),
+			})
+		}
+	}
+
+	return stackFrame
+}
+
+// getFrameLocation gets the location of a stack frame
+func getFrameLocation(frame api.Stackframe) *string {
+	return formatPosition(getFramePosition(frame))
+}
+
+// getFramePosition gets the position of a stack frame as separate fields
+func getFramePosition(frame api.Stackframe) *types.SourcePosition {
+	return &types.SourcePosition{
+		File:     frame.File,
+		Line:     frame.Line,
+		Function: getFunctionNameFromLocation(frame.Location),
+	}
+}
+
+// stdlibRoots are the first elements of the standard library's import paths, as listed by
+// go list std. A module path starting with one would shadow the standard library, so
+// packages whose path starts with any other, like a module named calculator, are user code.
+var stdlibRoots = map[string]bool{
+	"archive": true, "bufio": true, "bytes": true, "cmp": true, "compress": true,
+	"container": true, "context": true, "crypto": true, "database": true, "debug": true,
+	"embed": true, "encoding": true, "errors": true, "expvar": true, "flag": true,
+	"fmt": true, "go": true, "hash": true, "html": true, "image": true,
+	"index": true, "internal": true, "io": true, "iter": true, "log": true,
+	"maps": true, "math": true, "mime": true, "net": true, "os": true,
+	"path": true, "plugin": true, "reflect": true, "regexp": true, "runtime": true,
+	"slices": true, "sort": true, "strconv": true, "strings": true, "structs": true,
+	"sync": true, "syscall": true, "testing": true, "text": true, "time": true,
+	"unicode": true, "unique": true, "unsafe": true, "uuid": true, "vendor": true,
+	"weak": true,
+}
+
+// isRuntimeFunction reports whether a function belongs to the Go runtime or standard
+// library, or was generated by the compiler, like type:.eq.main.T
+func isRuntimeFunction(function string) bool {
+	if function == "" || function == "unknown" {
+		return false
+	}
+	if strings.HasPrefix(function, "type:") || strings.HasPrefix(function, "go:") {
+		return true
+	}
+
+	// The package of net/http.(*conn).serve is under net
+	root, _, _ := strings.Cut(functionPackage(function), "/")
+	return stdlibRoots[root]
+}
+
+// createBacktraceResponse creates a BacktraceResponse
+func (c *Client) createBacktraceResponse(state *api.DebuggerState, goroutineID int64, frames []types.StackFrame, err error) types.BacktraceResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "backtrace"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.BacktraceResponse{
+			Status:      "error",
+			Context:     context,
+			GoroutineID: goroutineID,
+		}
+	}
+
+	return types.BacktraceResponse{
+		Status:      "success",
+		Context:     context,
+		GoroutineID: goroutineID,
+		Frames:      frames,
+	}
+}
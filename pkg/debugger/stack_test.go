@@ -0,0 +1,34 @@
+package debugger
+
+import "testing"
+
+func TestIsRuntimeFunction(t *testing.T) {
+	testCases := []struct {
+		function string
+		expected bool
+	}{
+		{function: "runtime.gopark", expected: true},
+		{function: "runtime.(*mheap).alloc", expected: true},
+		{function: "sync.(*Mutex).Lock", expected: true},
+		{function: "net/http.(*conn).serve", expected: true},
+		{function: "internal/poll.(*FD).Read", expected: true},
+		{function: "vendor/golang.org/x/net/dns/dnsmessage.(*Parser).Start", expected: true},
+		{function: "type:.eq.main.Point", expected: true},
+		{function: "main.main", expected: false},
+		{function: "main.(*Server).handle.func1", expected: false},
+		{function: "main.Map[go.shape.int].Get", expected: false},
+		{function: "calculator.Add", expected: false},
+		{function: "calculator/internal/ops.Mul", expected: false},
+		{function: "command-line-arguments.TestAdd", expected: false},
+		{function: "github.com/sunfmin/mcp-go-debugger/testdata/calculator.Divide", expected: false},
+		{function: "golang.org/x/sync/errgroup.(*Group).Wait", expected: false},
+		{function: "unknown", expected: false},
+		{function: "", expected: false},
+	}
+
+	for _, tc := range testCases {
+		if got := isRuntimeFunction(tc.function); got != tc.expected {
+			t.Errorf("isRuntimeFunction(%q) = %v; expected %v", tc.function, got, tc.expected)
+		}
+	}
+}
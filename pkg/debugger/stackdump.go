@@ -0,0 +1,153 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// DumpAllStacks collects the backtrace of every goroutine, like the dump a Go program
+// prints on SIGQUIT, and groups goroutines with identical stacks and status. The
+// per-goroutine stacks are only returned when includeGoroutines is true.
+func (c *Client) DumpAllStacks(depthPerGoroutine int, includeGoroutines bool) types.StackDumpResponse {
+	if c.client == nil {
+		return c.createStackDumpResponse(nil, nil, nil, 0, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createStackDumpResponse(nil, nil, nil, 0, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createStackDumpResponse(nil, nil, nil, 0, fmt.Errorf("cannot dump stacks while the target is running; stop the target first"))
+	}
+
+	// A count of 0 asks Delve for every goroutine
+	gs, _, err := c.client.ListGoroutines(0, 0)
+	if err != nil {
+		return c.createStackDumpResponse(state, nil, nil, 0, fmt.Errorf("failed to list goroutines: %v", err))
+	}
+
+	logger.Debug("Dumping stacks of %d goroutines with depth %d", len(gs), depthPerGoroutine)
+
+	var stacks []types.GoroutineStack
+	for _, g := range gs {
+		stack := types.GoroutineStack{
+			GoroutineID: g.ID,
+			Status:      c.getGoroutineSummaryKey(g),
+		}
+
+		frames, err := c.client.Stacktrace(g.ID, depthPerGoroutine, 0, nil)
+		if err != nil {
+			stack.Error = err.Error()
+		}
+		for i, frame := range frames {
+			stack.Frames = append(stack.Frames, convertStackFrame(i, frame, false))
+		}
+
+		stack.Function = getGoroutineStartFunction(g, stack.Frames)
+		stacks = append(stacks, stack)
+	}
+
+	groups := groupGoroutineStacks(stacks)
+	if !includeGoroutines {
+		stacks = nil
+	}
+
+	return c.createStackDumpResponse(state, groups, stacks, len(gs), nil)
+}
+
+// getGoroutineStartFunction names a goroutine by the function it was started with,
+// falling back to its outermost frame
+func getGoroutineStartFunction(g *api.Goroutine, frames []types.StackFrame) string {
+	if g.StartLoc.Function != nil {
+		return g.StartLoc.Function.Name()
+	}
+	if len(frames) > 0 {
+		return frames[len(frames)-1].Function
+	}
+	return "unknown"
+}
+
+// groupGoroutineStacks groups goroutines whose stacks and status are identical, largest
+// group first
+func groupGoroutineStacks(stacks []types.GoroutineStack) []types.StackGroup {
+	var groups []types.StackGroup
+	index := make(map[string]int)
+
+	for _, stack := range stacks {
+		key := stackGroupKey(stack)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, types.StackGroup{
+				Function: stack.Function,
+				Status:   stack.Status,
+				Frames:   stack.Frames,
+			})
+		}
+		groups[i].Count++
+		groups[i].GoroutineIDs = append(groups[i].GoroutineIDs, stack.GoroutineID)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Count > groups[j].Count
+	})
+
+	for i := range groups {
+		groups[i].Summary = formatStackGroupSummary(groups[i])
+	}
+	return groups
+}
+
+// stackGroupKey identifies a stack by its status and the position of every frame
+func stackGroupKey(stack types.GoroutineStack) string {
+	var b strings.Builder
+	b.WriteString(stack.Status)
+	for _, frame := range stack.Frames {
+		fmt.Fprintf(&b, "|%s %s:%d", frame.Function, frame.File, frame.Line)
+	}
+	return b.String()
+}
+
+// formatStackGroupSummary describes a group, e.g. "42 goroutines in net/http.(*conn).serve [waiting (IO wait)]"
+func formatStackGroupSummary(group types.StackGroup) string {
+	noun := "goroutines"
+	if group.Count == 1 {
+		noun = "goroutine"
+	}
+	return fmt.Sprintf("%d %s in %s [%s]", group.Count, noun, group.Function, group.Status)
+}
+
+// createStackDumpResponse creates a StackDumpResponse
+func (c *Client) createStackDumpResponse(state *api.DebuggerState, groups []types.StackGroup, stacks []types.GoroutineStack, total int, err error) types.StackDumpResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "dump_stacks"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.StackDumpResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	summaries := make([]string, 0, len(groups))
+	for _, group := range groups {
+		summaries = append(summaries, group.Summary)
+	}
+
+	return types.StackDumpResponse{
+		Status:     "success",
+		Context:    context,
+		Total:      total,
+		Summary:    strings.Join(summaries, "\n"),
+		Groups:     groups,
+		Goroutines: stacks,
+	}
+}
@@ -0,0 +1,54 @@
+package debugger
+
+import (
+	"testing"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestGroupGoroutineStacks(t *testing.T) {
+	serveFrames := []types.StackFrame{
+		{Index: 0, Function: "internal/poll.runtime_pollWait", File: "runtime/netpoll.go", Line: 345},
+		{Index: 1, Function: "net/http.(*conn).serve", File: "net/http/server.go", Line: 2092},
+	}
+	mainFrames := []types.StackFrame{
+		{Index: 0, Function: "main.main", File: "main.go", Line: 12},
+	}
+
+	stacks := []types.GoroutineStack{
+		{GoroutineID: 1, Status: "running", Function: "runtime.main", Frames: mainFrames},
+		{GoroutineID: 7, Status: "waiting (IO wait)", Function: "net/http.(*conn).serve", Frames: serveFrames},
+		{GoroutineID: 8, Status: "waiting (IO wait)", Function: "net/http.(*conn).serve", Frames: serveFrames},
+		{GoroutineID: 9, Status: "waiting (select)", Function: "net/http.(*conn).serve", Frames: serveFrames},
+	}
+
+	groups := groupGoroutineStacks(stacks)
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %d", len(groups))
+	}
+
+	expected := []struct {
+		summary string
+		ids     []int64
+	}{
+		{summary: "2 goroutines in net/http.(*conn).serve [waiting (IO wait)]", ids: []int64{7, 8}},
+		{summary: "1 goroutine in runtime.main [running]", ids: []int64{1}},
+		{summary: "1 goroutine in net/http.(*conn).serve [waiting (select)]", ids: []int64{9}},
+	}
+
+	for i, tc := range expected {
+		if groups[i].Summary != tc.summary {
+			t.Errorf("Group %d: expected summary %q, got %q", i, tc.summary, groups[i].Summary)
+		}
+		if len(groups[i].GoroutineIDs) != len(tc.ids) {
+			t.Errorf("Group %d: expected goroutines %v, got %v", i, tc.ids, groups[i].GoroutineIDs)
+			continue
+		}
+		for j, id := range tc.ids {
+			if groups[i].GoroutineIDs[j] != id {
+				t.Errorf("Group %d: expected goroutines %v, got %v", i, tc.ids, groups[i].GoroutineIDs)
+				break
+			}
+		}
+	}
+}
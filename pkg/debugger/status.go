@@ -0,0 +1,143 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/pkg/version"
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// States of a session reported by Status
+const (
+	sessionInactive       = "no active session"
+	sessionRunning        = "running"
+	sessionStopped        = "stopped"
+	sessionExited         = "exited"
+	sessionConnectionLost = "connection lost"
+	sessionUnavailable    = "unavailable"
+)
+
+// DelveVersion returns the version of Delve the debugger is built with
+func DelveVersion() string {
+	return version.DelveVersion.String()
+}
+
+// Status sums up the session: whether a program is being debugged, what it is, whether it
+// is running, where it is stopped and how many breakpoints are set, along with the Delve
+// versions in use. It only makes cheap calls, and reports what it can't learn in the
+// response rather than failing, so it can be called at any time.
+func (c *Client) Status() types.StatusResponse {
+	response := types.StatusResponse{
+		Status:       "success",
+		Context:      types.DebugContext{Timestamp: getCurrentTimestamp(), Operation: "status"},
+		Active:       c.IsActive(),
+		DelveVersion: DelveVersion(),
+	}
+
+	if !response.Active {
+		response.State = sessionInactive
+		response.Message = "no program is being debugged; start one with debug, launch, launch_test, attach, connect_remote or open_core"
+		return response
+	}
+
+	response.Target = c.target
+	response.Pid = c.pid
+	response.Remote = c.remoteAddr
+	response.Core = c.coreFile
+	response.Backend = c.backend
+	response.ReadOnly = c.ReadOnly()
+
+	if c.ConnectionLost() {
+		response.State = sessionConnectionLost
+		if c.reconnectPolicy.Attempts > 0 {
+			response.Message = fmt.Sprintf("%v at %s; the next call tries to reconnect, up to %d times", ErrRemoteConnectionLost, c.remoteAddr, c.reconnectPolicy.Attempts)
+		} else {
+			response.Message = fmt.Sprintf("%v at %s; reconnect with connect_remote", ErrRemoteConnectionLost, c.remoteAddr)
+		}
+		return response
+	}
+
+	if exited, status := c.Exited(); exited {
+		response.State = sessionExited
+		response.ExitStatus = &status
+		response.Message = c.ExitedError().Error()
+		return response
+	}
+
+	// Delve's client has no wrapper for GetVersion
+	var v api.GetVersionOut
+	if err := c.client.CallAPI("GetVersion", api.GetVersionIn{}, &v); err == nil {
+		response.Backend = v.Backend
+		response.TargetGoVersion = v.TargetGoVersion
+		response.SupportedGoVersions = fmt.Sprintf("%s to %s", v.MinSupportedVersionOfGo, v.MaxSupportedVersionOfGo)
+	} else {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("failed to get the Delve version: %v", err))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		c.noteExit(nil, err)
+		if exited, status := c.Exited(); exited {
+			response.State = sessionExited
+			response.ExitStatus = &status
+			response.Message = c.ExitedError().Error()
+			return response
+		}
+		response.State = sessionUnavailable
+		response.Message = fmt.Sprintf("failed to get state: %v", err)
+		return response
+	}
+	c.describeStatusState(&response, state)
+
+	// Delve doesn't answer while the target runs, except for the calls above
+	if state.Running {
+		return response
+	}
+
+	bps, err := c.client.ListBreakpoints(false)
+	if err != nil {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("failed to list breakpoints: %v", err))
+		return response
+	}
+	for _, bp := range bps {
+		if bp.ID <= 0 {
+			continue
+		}
+		response.Breakpoints++
+		if bp.Disabled {
+			response.DisabledBreakpoints++
+		}
+	}
+	return response
+}
+
+// describeStatusState fills in whether the target runs and, when stopped, where and why
+func (c *Client) describeStatusState(response *types.StatusResponse, state *api.DebuggerState) {
+	response.Context.DelveState = state
+	c.noteExit(state, nil)
+
+	switch {
+	case state.Exited:
+		status := state.ExitStatus
+		response.State = sessionExited
+		response.ExitStatus = &status
+		response.Message = c.ExitedError().Error()
+		return
+	case state.Running:
+		response.State = sessionRunning
+		response.Message = "the program is running; use halt or wait_for_stop before inspecting it"
+		return
+	}
+
+	response.State = sessionStopped
+	if state.CurrentThread != nil {
+		response.Context.Position = getCurrentPosition(state)
+		response.Context.CurrentLocation = formatPosition(response.Context.Position)
+	}
+	response.Context.Stop = getStopDetail(state)
+	response.Context.StopReason = formatStopDetail(response.Context.Stop)
+	if state.SelectedGoroutine != nil {
+		response.GoroutineID = state.SelectedGoroutine.ID
+	}
+}
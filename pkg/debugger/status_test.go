@@ -0,0 +1,89 @@
+package debugger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestStatusWithoutSession(t *testing.T) {
+	response := NewClient().Status()
+	if response.Status != "success" {
+		t.Errorf("Expected status to succeed without a session, got %q", response.Context.ErrorMessage)
+	}
+	if response.Active || response.State != sessionInactive {
+		t.Errorf("Expected an inactive session, got active %v and state %q", response.Active, response.State)
+	}
+	if response.DelveVersion == "" {
+		t.Error("Expected the Delve version to be reported without a session")
+	}
+	if response.Context.Operation != "status" {
+		t.Errorf("Expected operation status, got %q", response.Context.Operation)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	stopped := stoppedState(10)
+	stopped.CurrentThread.Breakpoint = &api.Breakpoint{ID: 1, File: "main.go", Line: 10}
+	running := &api.DebuggerState{Running: true}
+	exited := &api.DebuggerState{Exited: true, ExitStatus: 3}
+
+	testCases := []struct {
+		name        string
+		state       *api.DebuggerState
+		expected    string
+		location    string
+		breakpoints int
+		disabled    int
+		exitStatus  int
+	}{
+		{name: "Stopped at a breakpoint", state: stopped, expected: sessionStopped, location: "main.go:10", breakpoints: 2, disabled: 1},
+		{name: "Running", state: running, expected: sessionRunning},
+		{name: "Exited", state: exited, expected: sessionExited, breakpoints: 2, disabled: 1, exitStatus: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bps := &fakeBreakpoints{}
+			bps.add(&api.Breakpoint{ID: 1, File: "main.go", Line: 10})
+			bps.add(&api.Breakpoint{ID: 2, File: "main.go", Line: 20, Disabled: true})
+			bps.add(&api.Breakpoint{ID: -1, Name: "unrecovered-panic"})
+			c, f := newFakeDelve(t, bps.serve(t, map[string]fakeHandler{
+				"State":      fakeState(tc.state),
+				"GetVersion": fakeResult(api.GetVersionOut{Backend: "native", TargetGoVersion: "go1.23.1", MinSupportedVersionOfGo: "1.21.0", MaxSupportedVersionOfGo: "1.24.0"}),
+			}))
+			c.target = "/src/app"
+
+			response := c.Status()
+			if response.Status != "success" || !response.Active || response.State != tc.expected {
+				t.Fatalf("Expected an active session %s, got %s and %q: %s", tc.expected, response.Status, response.State, response.Message)
+			}
+			if response.Target != "/src/app" || response.TargetGoVersion != "go1.23.1" || response.SupportedGoVersions != "1.21.0 to 1.24.0" {
+				t.Errorf("Expected the target and its Go versions, got %+v", response)
+			}
+			if location := response.Context.CurrentLocation; (location == nil) != (tc.location == "") || (location != nil && !strings.Contains(*location, tc.location)) {
+				t.Errorf("Expected location %q, got %v", tc.location, location)
+			}
+			if response.Breakpoints != tc.breakpoints || response.DisabledBreakpoints != tc.disabled {
+				t.Errorf("Expected %d breakpoints, %d disabled, got %d and %d", tc.breakpoints, tc.disabled, response.Breakpoints, response.DisabledBreakpoints)
+			}
+			if (response.ExitStatus != nil) != (tc.expected == sessionExited) || (response.ExitStatus != nil && *response.ExitStatus != tc.exitStatus) {
+				t.Errorf("Expected exit status %d, got %v", tc.exitStatus, response.ExitStatus)
+			}
+
+			// Delve doesn't answer other calls while the target runs
+			if tc.state.Running && f.called("ListBreakpoints") != 0 {
+				t.Errorf("Expected the breakpoints of a running target not to be listed")
+			}
+		})
+	}
+
+	// Once the exit is noted, status doesn't need Delve to report it
+	c, f := newFakeDelve(t, map[string]fakeHandler{"State": fakeState(exited)})
+	c.Status()
+	calls := f.called("State")
+	if response := c.Status(); response.State != sessionExited || f.called("State") != calls {
+		t.Errorf("Expected the exit to be remembered, got %q after %d more state calls", response.State, f.called("State")-calls)
+	}
+}
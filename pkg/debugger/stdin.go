@@ -0,0 +1,122 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// stdinWriteTimeout bounds how long WriteStdin waits for a program that isn't reading
+const stdinWriteTimeout = 5 * time.Second
+
+// stdinPipe is the named pipe a launched program reads its stdin from. Delve only takes
+// a path for stdin, so the program opens the pipe by name and we keep its write end.
+type stdinPipe struct {
+	dir    string   // Temporary directory holding the pipe
+	path   string   // Path of the pipe, given to Delve
+	writer *os.File // Write end, written by WriteStdin
+	reader *os.File // Read end held until the program has opened its own
+}
+
+// started drops the read end held while the program started, so that writes fail once
+// the program closes its stdin or exits instead of filling the pipe
+func (p *stdinPipe) started() {
+	if p.reader != nil {
+		_ = p.reader.Close()
+		p.reader = nil
+	}
+}
+
+// close closes the pipe, which the program reads as end of file, and removes it
+func (p *stdinPipe) close() {
+	p.started()
+	if p.writer != nil {
+		_ = p.writer.Close()
+		p.writer = nil
+	}
+	if p.dir != "" {
+		_ = os.RemoveAll(p.dir)
+		p.dir = ""
+	}
+}
+
+// WriteStdin writes data to the stdin of the program the session launched. With closeAfter
+// the pipe is closed after writing, so the program reads end of file.
+func (c *Client) WriteStdin(data string, closeAfter bool) types.WriteStdinResponse {
+	if c.client == nil {
+		return c.createWriteStdinResponse(nil, 0, false, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		// Delve reports a program that has exited as an error
+		if strings.Contains(err.Error(), "has exited") {
+			return c.createWriteStdinResponse(nil, 0, false, fmt.Errorf("cannot write to stdin: %v", err))
+		}
+		return c.createWriteStdinResponse(nil, 0, false, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Exited {
+		return c.createWriteStdinResponse(state, 0, false, fmt.Errorf("the program has exited with status %d", state.ExitStatus))
+	}
+
+	if c.stdin == nil {
+		return c.createWriteStdinResponse(state, 0, false, fmt.Errorf("stdin is only available for programs launched by this session"))
+	}
+
+	if c.stdin.writer == nil {
+		return c.createWriteStdinResponse(state, 0, false, fmt.Errorf("stdin of the program has already been closed"))
+	}
+
+	logger.Debug("Writing %d bytes to the program's stdin", len(data))
+
+	// The pipe holds a limited amount of data, so don't wait forever for a program that
+	// is stopped or not reading
+	_ = c.stdin.writer.SetWriteDeadline(time.Now().Add(stdinWriteTimeout))
+	n, err := c.stdin.writer.WriteString(data)
+	if err != nil {
+		if errors.Is(err, syscall.EPIPE) {
+			c.stdin.close()
+			return c.createWriteStdinResponse(state, n, false, fmt.Errorf("the program has closed its stdin"))
+		}
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return c.createWriteStdinResponse(state, n, false, fmt.Errorf("wrote %d of %d bytes: the program is not reading its stdin and the pipe is full; continue the program so it reads, then write the rest", n, len(data)))
+		}
+		return c.createWriteStdinResponse(state, n, false, fmt.Errorf("failed to write to stdin: %v", err))
+	}
+
+	if closeAfter {
+		c.stdin.close()
+	}
+
+	return c.createWriteStdinResponse(state, n, closeAfter, nil)
+}
+
+// createWriteStdinResponse creates a WriteStdinResponse
+func (c *Client) createWriteStdinResponse(state *api.DebuggerState, written int, closed bool, err error) types.WriteStdinResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "write_stdin"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.WriteStdinResponse{
+			Status:  "error",
+			Context: context,
+			Written: written,
+		}
+	}
+
+	return types.WriteStdinResponse{
+		Status:  "success",
+		Context: context,
+		Written: written,
+		Closed:  closed,
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+
+package debugger
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// newStdinPipe creates the named pipe a launched program reads its stdin from
+func newStdinPipe() (*stdinPipe, error) {
+	dir, err := os.MkdirTemp("", "mcp-go-debugger-stdin-")
+	if err != nil {
+		return nil, err
+	}
+	p := &stdinPipe{dir: dir, path: filepath.Join(dir, "stdin")}
+
+	if err := syscall.Mkfifo(p.path, 0600); err != nil {
+		p.close()
+		return nil, err
+	}
+
+	// Opening one end of a pipe waits for the other, so open a read end without waiting,
+	// then the write end, which the program's read end won't have to wait for
+	p.reader, err = os.OpenFile(p.path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		p.close()
+		return nil, err
+	}
+	p.writer, err = os.OpenFile(p.path, os.O_WRONLY, 0)
+	if err != nil {
+		p.close()
+		return nil, err
+	}
+	return p, nil
+}
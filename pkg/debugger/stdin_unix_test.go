@@ -0,0 +1,46 @@
+//go:build !windows
+
+package debugger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestStdinPipe(t *testing.T) {
+	p, err := newStdinPipe()
+	if err != nil {
+		t.Fatalf("Failed to create stdin pipe: %v", err)
+	}
+	defer p.close()
+
+	// Stands in for the program opening its stdin
+	program, err := os.Open(p.path)
+	if err != nil {
+		t.Fatalf("Failed to open stdin pipe: %v", err)
+	}
+	p.started()
+
+	if _, err := p.writer.WriteString("hello\n"); err != nil {
+		t.Fatalf("Failed to write to stdin pipe: %v", err)
+	}
+	buf := make([]byte, 6)
+	if _, err := io.ReadFull(program, buf); err != nil || string(buf) != "hello\n" {
+		t.Errorf("Expected to read %q, got %q (%v)", "hello\n", buf, err)
+	}
+
+	// Once the program closes its stdin, writes fail instead of filling the pipe
+	_ = program.Close()
+	if _, err := p.writer.WriteString("more\n"); !errors.Is(err, syscall.EPIPE) {
+		t.Errorf("Expected EPIPE after the program closed stdin, got %v", err)
+	}
+
+	dir := p.dir
+	p.close()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got %v", dir, err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package debugger
+
+import (
+	"fmt"
+)
+
+// newStdinPipe is not implemented on Windows yet
+func newStdinPipe() (*stdinPipe, error) {
+	return nil, fmt.Errorf("writing to the stdin of programs is not supported on windows")
+}
@@ -0,0 +1,257 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// isolatedStepMaxInstructions bounds the instructions an isolated step executes with the
+// other goroutines paused, before it lets them run to finish the call it is in
+const isolatedStepMaxInstructions = 5000
+
+// isolatedFrame is the frame of the selected goroutine an isolated step started in
+type isolatedFrame struct {
+	function string
+	line     int
+	offset   int64 // FrameOffset of the frame, which tells it apart from recursive calls
+}
+
+// isolatedRun is how an isolated step went
+type isolatedRun struct {
+	instructions int      // Instructions executed with the other goroutines paused
+	released     []string // Why the other goroutines had to run, once for each time
+}
+
+// StepIsolated steps over the current line of the selected goroutine like StepOver, but
+// with the other goroutines paused: the goroutine's thread is single-stepped instruction
+// by instruction, calls included, while Delve keeps every other thread stopped, until the
+// goroutine reaches another line of the frame or returns from it. When the goroutine blocks
+// or yields in the runtime, such as on a channel, a mutex or a sleep, or a call runs for
+// more than isolatedStepMaxInstructions, the others are let run until it returns to the
+// frame, and the response says so. A goroutine that is parked when the step starts can
+// only be stepped with the others running. Breakpoints in the code executed with the
+// others paused don't stop the step, and a system or cgo call that blocks until another
+// goroutine acts, such as a read from a pipe, waits until ctx is done. Isolation relies
+// on Delve single-stepping one thread while the others stay stopped, which all its
+// backends do; a core dump can't be stepped at all. ctx bounds the whole step.
+func (c *Client) StepIsolated(ctx context.Context) types.StepResponse {
+	if c.client == nil {
+		return c.createStepResponse(nil, "isolated", nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createStepResponse(nil, "isolated", nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createStepResponse(nil, "isolated", nil, fmt.Errorf("cannot step while the target is running; stop the target first"))
+	}
+	if state.SelectedGoroutine == nil {
+		return c.createStepResponse(state, "isolated", nil, fmt.Errorf("no goroutine selected"))
+	}
+
+	fromLocation := getCurrentLocation(state)
+	before := c.stackFunctions(state)
+	g := state.SelectedGoroutine
+
+	var run isolatedRun
+	var nextState *api.DebuggerState
+	if g.ThreadID == 0 {
+		// Only the scheduler can resume a goroutine that is not on a thread
+		run.released = append(run.released, fmt.Sprintf("goroutine %d was not running on a thread, as it was blocked or waiting to be scheduled, so it was stepped with the others running", g.ID))
+		logger.Debug("Stepping parked goroutine %d over the next line", g.ID)
+		nextState, err = c.interruptibleStep(ctx, state, c.client.Next)
+	} else {
+		nextState, err = c.stepIsolated(ctx, state, &run)
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrInterrupted) {
+			return c.createIsolatedStepResponse(nextState, fromLocation, run, err)
+		}
+		return c.createIsolatedStepResponse(nil, fromLocation, run, fmt.Errorf("isolated step failed: %v", err))
+	}
+
+	response := c.createIsolatedStepResponse(nextState, fromLocation, run, nil)
+	if response.InterruptedBy == nil {
+		response.Transition, response.TransitionNote = describeStepTransition(before, c.stackFunctions(nextState))
+	}
+	return response
+}
+
+// stepIsolated single-steps the thread of the selected goroutine until the goroutine leaves
+// the line it is at in its frame, letting the other goroutines run only when it can't make
+// progress on its own
+func (c *Client) stepIsolated(ctx context.Context, state *api.DebuggerState, run *isolatedRun) (*api.DebuggerState, error) {
+	goroutineID := state.SelectedGoroutine.ID
+	if state.CurrentThread == nil || state.CurrentThread.ID != state.SelectedGoroutine.ThreadID {
+		switched, err := c.client.SwitchThread(state.SelectedGoroutine.ThreadID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to switch to the thread of goroutine %d: %v", goroutineID, err)
+		}
+		state = switched
+	}
+
+	start, err := c.topFrame(goroutineID)
+	if err != nil {
+		return nil, err
+	}
+	start.line = state.CurrentThread.Line
+	logger.Debug("Stepping goroutine %d over %s:%d with the other goroutines paused", goroutineID, start.function, start.line)
+
+	function := start.function
+	budget := isolatedStepMaxInstructions
+	for {
+		if budget == 0 {
+			resumed, back, err := c.releaseUntilReturn(ctx, goroutineID, start, fmt.Sprintf("ran for more than %d instructions", isolatedStepMaxInstructions), run)
+			if err != nil || !back {
+				return resumed, err
+			}
+			function, budget = start.function, isolatedStepMaxInstructions
+			continue
+		}
+
+		stepped, err := c.interruptible(ctx, func() (*api.DebuggerState, error) {
+			return c.client.StepInstruction(false)
+		})
+		if err != nil {
+			if errors.Is(err, ErrInterrupted) {
+				return stepped, fmt.Errorf("step %w after %d instructions, at %s", err, run.instructions, haltedStepLocation(stepped))
+			}
+			return nil, fmt.Errorf("step instruction command failed: %v", err)
+		}
+		state = stepped
+		run.instructions++
+		budget--
+		if state.Exited {
+			return state, nil
+		}
+
+		thread := state.CurrentThread
+		if thread == nil || thread.GoroutineID != goroutineID {
+			// The goroutine gave up its thread to the scheduler, which only the others can
+			// wake it from
+			resumed, back, err := c.releaseUntilReturn(ctx, goroutineID, start, "blocked or yielded the thread", run)
+			if err != nil || !back {
+				return resumed, err
+			}
+			function, budget = start.function, isolatedStepMaxInstructions
+			continue
+		}
+
+		// The frame only needs reading where the step may end: in the frame it started in,
+		// a recursive call of it or, once a function returns, a caller
+		current := getFunctionName(thread)
+		if current != start.function && current == function {
+			continue
+		}
+		function = current
+		frame, err := c.topFrame(goroutineID)
+		if err != nil {
+			return state, err
+		}
+		switch {
+		case frame.offset > 0:
+			// Frames on the system stack, as in systemstack and morestack, are offset from
+			// its base rather than the goroutine's, and belong to a call
+			continue
+		case frame.offset > start.offset:
+			// Returned from the frame
+			return state, nil
+		case frame.offset == start.offset && current == start.function && thread.Line != start.line && thread.Line != 0:
+			return state, nil
+		}
+	}
+}
+
+// releaseUntilReturn lets every goroutine run until goroutineID returns to the frame the
+// isolated step started in from the call that, as cause says, can't go on with them
+// paused. The step then goes on with the others paused again. It reports whether the
+// goroutine got back there, rather than the program stopping elsewhere.
+func (c *Client) releaseUntilReturn(ctx context.Context, goroutineID int64, start isolatedFrame, cause string, run *isolatedRun) (*api.DebuggerState, bool, error) {
+	frames, err := c.client.Stacktrace(goroutineID, maxTransitionFrames, 0, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get stack trace of goroutine %d: %v", goroutineID, err)
+	}
+	if len(frames) > 0 && frames[0].FrameOffset == start.offset {
+		return nil, false, fmt.Errorf("line %d of %s ran for %d instructions without finishing, as a loop waiting for another goroutine may; use step_over to let the others run", start.line, start.function, run.instructions)
+	}
+
+	// The return address of the call the goroutine is in, in the frame the step started in
+	var returnPC uint64
+	var callee string
+	for i := 1; i < len(frames); i++ {
+		if frames[i].FrameOffset == start.offset && getFunctionNameFromLocation(frames[i].Location) == start.function {
+			returnPC, callee = frames[i].PC, getFunctionNameFromLocation(frames[i-1].Location)
+			break
+		}
+	}
+	if returnPC == 0 {
+		return nil, false, fmt.Errorf("the goroutine %s, but the frame of %s is not on the stack of goroutine %d to return to", cause, start.function, goroutineID)
+	}
+
+	run.released = append(run.released, fmt.Sprintf("the call to %s %s, so the other goroutines ran until it returned to %s", callee, cause, start.function))
+	logger.Debug("Letting the other goroutines run until goroutine %d returns to %#x", goroutineID, returnPC)
+	bp, err := c.client.CreateBreakpoint(&api.Breakpoint{Addr: returnPC, Cond: pinnedCondition("", goroutineID)})
+	if err != nil && !strings.Contains(err.Error(), "Breakpoint exists") {
+		return nil, false, fmt.Errorf("failed to set a breakpoint at the return to %s: %v", start.function, err)
+	}
+	state, err := c.continueExecution(ctx)
+	if bp != nil {
+		if _, clearErr := c.client.ClearBreakpoint(bp.ID); clearErr != nil {
+			logger.Debug("Warning: Failed to clear isolated step breakpoint %d: %v", bp.ID, clearErr)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, ErrInterrupted) {
+			return state, false, fmt.Errorf("step %w while goroutine %d was left to return to %s", err, goroutineID, start.function)
+		}
+		return nil, false, err
+	}
+
+	thread := state.CurrentThread
+	back := !state.Exited && thread != nil && thread.GoroutineID == goroutineID && thread.PC == returnPC
+	return state, back, nil
+}
+
+// topFrame reads the function and frame offset of the innermost frame of a goroutine
+func (c *Client) topFrame(goroutineID int64) (isolatedFrame, error) {
+	frames, err := c.client.Stacktrace(goroutineID, 0, 0, nil)
+	if err != nil {
+		return isolatedFrame{}, fmt.Errorf("failed to get stack trace of goroutine %d: %v", goroutineID, err)
+	}
+	if len(frames) == 0 {
+		return isolatedFrame{}, fmt.Errorf("goroutine %d has no stack frames", goroutineID)
+	}
+	return isolatedFrame{
+		function: getFunctionNameFromLocation(frames[0].Location),
+		line:     frames[0].Line,
+		offset:   frames[0].FrameOffset,
+	}, nil
+}
+
+// createIsolatedStepResponse creates the StepResponse of an isolated step
+func (c *Client) createIsolatedStepResponse(state *api.DebuggerState, fromLocation *string, run isolatedRun, err error) types.StepResponse {
+	response := c.createStepResponse(state, "isolated", fromLocation, err)
+	response.Instructions = run.instructions
+	response.OthersRan = len(run.released) > 0
+	response.IsolationNote = summarizeIsolation(run)
+	return response
+}
+
+// summarizeIsolation describes whether the other goroutines stayed paused during a step
+func summarizeIsolation(run isolatedRun) string {
+	if len(run.released) == 0 && run.instructions == 1 {
+		return "only the selected goroutine ran, for 1 instruction"
+	}
+	if len(run.released) == 0 {
+		return fmt.Sprintf("only the selected goroutine ran, for %d instructions", run.instructions)
+	}
+	return strings.Join(run.released, "; ")
+}
@@ -0,0 +1,176 @@
+package debugger
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+func TestSummarizeIsolation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		run      isolatedRun
+		expected string
+	}{
+		{name: "One instruction", run: isolatedRun{instructions: 1}, expected: "only the selected goroutine ran, for 1 instruction"},
+		{name: "Isolated", run: isolatedRun{instructions: 470}, expected: "only the selected goroutine ran, for 470 instructions"},
+		{
+			name: "Released twice",
+			run: isolatedRun{instructions: 900, released: []string{
+				"the call to runtime.chanrecv1 blocked or yielded the thread, so the other goroutines ran until it returned to main.main",
+				"the call to fmt.Println ran for more than 5000 instructions, so the other goroutines ran until it returned to main.main",
+			}},
+			expected: "the call to runtime.chanrecv1 blocked or yielded the thread, so the other goroutines ran until it returned to main.main; the call to fmt.Println ran for more than 5000 instructions, so the other goroutines ran until it returned to main.main",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := summarizeIsolation(tc.run); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestCreateIsolatedStepResponse(t *testing.T) {
+	run := isolatedRun{instructions: 12, released: []string{"the call to runtime.chanrecv1 blocked or yielded the thread"}}
+	response := NewClient().createIsolatedStepResponse(nil, nil, run, nil)
+	if response.StepType != "isolated" || response.Instructions != 12 || !response.OthersRan || response.IsolationNote != run.released[0] {
+		t.Errorf("Expected an isolated step that let the others run, got %+v", response)
+	}
+}
+
+// isolatedPoint is where an isolated step is after an instruction: the goroutine on the
+// thread being stepped, and the stack of the goroutine stepped
+type isolatedPoint struct {
+	goroutineID int64
+	frames      []api.Stackframe
+}
+
+// isolatedFrameAt is a frame of function at line of main.go
+func isolatedFrameAt(function string, line int, offset int64, pc uint64) api.Stackframe {
+	return api.Stackframe{Location: api.Location{PC: pc, File: "main.go", Line: line, Function: &api.Function{Name_: function}}, FrameOffset: offset}
+}
+
+// isolatedTarget returns a fake Delve whose goroutine 1 is stopped at line 10 of main.main,
+// on thread 1, and goes through steps one instruction at a time. A continue takes it to
+// continued. The names of the commands run are recorded.
+func isolatedTarget(t *testing.T, steps []isolatedPoint, continued *isolatedPoint) (*Client, *fakeBreakpoints, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	current := isolatedPoint{goroutineID: 1, frames: []api.Stackframe{isolatedFrameAt("main.main", 10, -100, 0x1000)}}
+	state := func() api.DebuggerState {
+		top := current.frames[0]
+		return api.DebuggerState{
+			CurrentThread:     &api.Thread{ID: 1, GoroutineID: current.goroutineID, PC: top.PC, File: top.File, Line: top.Line, Function: top.Function},
+			SelectedGoroutine: &api.Goroutine{ID: 1, ThreadID: 1},
+		}
+	}
+
+	bps := &fakeBreakpoints{}
+	var commands []string
+	c, _ := newFakeDelve(t, bps.serve(t, map[string]fakeHandler{
+		"State": func(json.RawMessage) (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			s := state()
+			return rpc2.StateOut{State: &s}, nil
+		},
+		"Stacktrace": func(json.RawMessage) (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return rpc2.StacktraceOut{Locations: current.frames}, nil
+		},
+		"Command": fakeCommands(t, &commands, func(command api.DebuggerCommand) api.DebuggerState {
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case command.Name == api.Continue && continued != nil:
+				current = *continued
+			case command.Name == api.StepInstruction && len(steps) > 0:
+				current, steps = steps[0], steps[1:]
+			default:
+				t.Errorf("Unexpected command %s", command.Name)
+			}
+			return state()
+		}),
+	}))
+	return c, bps, &commands
+}
+
+func TestStepIsolated(t *testing.T) {
+	mainAt := func(line int, pc uint64) []api.Stackframe {
+		return []api.Stackframe{isolatedFrameAt("main.main", line, -100, pc)}
+	}
+	// main.main calls main.add on line 10, which returns to the rest of the line
+	steps := []isolatedPoint{
+		{goroutineID: 1, frames: mainAt(10, 0x1004)},
+		{goroutineID: 1, frames: []api.Stackframe{isolatedFrameAt("main.add", 3, -160, 0x2000), isolatedFrameAt("main.main", 10, -100, 0x1008)}},
+		{goroutineID: 1, frames: []api.Stackframe{isolatedFrameAt("main.add", 4, -160, 0x2004), isolatedFrameAt("main.main", 10, -100, 0x1008)}},
+		{goroutineID: 1, frames: mainAt(10, 0x1008)},
+		{goroutineID: 1, frames: mainAt(11, 0x100c)},
+	}
+	c, _, commands := isolatedTarget(t, steps, nil)
+
+	response := c.StepIsolated(context.Background())
+	if response.Status != "success" || response.StepType != "isolated" {
+		t.Fatalf("Expected an isolated step, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if len(*commands) != 5 || response.Instructions != 5 || response.OthersRan {
+		t.Errorf("Expected 5 instructions with the others paused, got %d (%v), others ran %v", response.Instructions, *commands, response.OthersRan)
+	}
+	if response.IsolationNote != "only the selected goroutine ran, for 5 instructions" {
+		t.Errorf("Expected the others to stay paused, got %q", response.IsolationNote)
+	}
+	if position := response.Context.Position; position == nil || position.Line != 11 {
+		t.Errorf("Expected the step to end at line 11, got %+v", position)
+	}
+}
+
+func TestStepIsolatedReleased(t *testing.T) {
+	// The goroutine blocks receiving in the call on line 10, so the others must run for it
+	// to get back to main.main
+	steps := []isolatedPoint{
+		{goroutineID: 0, frames: []api.Stackframe{
+			isolatedFrameAt("runtime.chanrecv1", 100, -200, 0x3000),
+			isolatedFrameAt("main.main", 10, -100, 0x1008),
+		}},
+		{goroutineID: 1, frames: []api.Stackframe{isolatedFrameAt("main.main", 11, -100, 0x100c)}},
+	}
+	continued := &isolatedPoint{goroutineID: 1, frames: []api.Stackframe{isolatedFrameAt("main.main", 10, -100, 0x1008)}}
+	c, bps, commands := isolatedTarget(t, steps, continued)
+
+	response := c.StepIsolated(context.Background())
+	if response.Status != "success" {
+		t.Fatalf("Expected the step to finish, got %s", response.Context.ErrorMessage)
+	}
+	expected := []string{api.StepInstruction, api.Continue, api.StepInstruction}
+	if !reflect.DeepEqual(*commands, expected) {
+		t.Errorf("Expected commands %v, got %v", expected, *commands)
+	}
+	if !response.OthersRan || !strings.Contains(response.IsolationNote, "the call to runtime.chanrecv1 blocked or yielded the thread, so the other goroutines ran until it returned to main.main") {
+		t.Errorf("Expected the others let run for the receive, got %q", response.IsolationNote)
+	}
+
+	// The breakpoint set at the return address is gone again
+	if bp := bps.get(1); bp != nil {
+		t.Errorf("Expected the return breakpoint cleared, got %+v", bp)
+	}
+	if bps.lastID != 1 {
+		t.Errorf("Expected one return breakpoint set, got %d", bps.lastID)
+	}
+}
+
+func TestStepIsolatedWithoutSession(t *testing.T) {
+	response := NewClient().StepIsolated(context.Background())
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "no active debug session") {
+		t.Errorf("Expected a no active debug session error, got %q", response.Context.ErrorMessage)
+	}
+}
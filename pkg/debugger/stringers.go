@@ -0,0 +1,157 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxStringerCalls caps the String and Error methods called for one evaluation or listing,
+// as each call runs the target
+const maxStringerCalls = 32
+
+// errStoppedInCall is returned when a breakpoint inside an injected call stops it
+var errStoppedInCall = errors.New("stopped at a breakpoint inside the call")
+
+// stringerTarget is a value to render with its String or Error method, along with the
+// expression that evaluates to it
+type stringerTarget struct {
+	expr     string
+	variable *types.Variable
+}
+
+// callStringers renders each target with the String or Error method of its type, called in
+// the selected goroutine, and sets Stringer to the result, or StringerError to why the call
+// failed. Values whose type has neither method, and nil values, are left alone. It returns
+// a note when the calls could not be made, or were cut short; the values are still rendered
+// as they are either way.
+func (c *Client) callStringers(state *api.DebuggerState, frame int, targets []stringerTarget, maxStringLen int) string {
+	if c.ReadOnly() {
+		return "String and Error methods can't be called in a core dump, which has no live process"
+	}
+	if frame != 0 {
+		return "String and Error methods are only called in frame 0, the frame the goroutine is stopped in"
+	}
+	if state.SelectedGoroutine == nil {
+		return "no goroutine selected to call String and Error methods on"
+	}
+
+	// Delve can't check a type's method set, so look for the methods in the binary
+	functions, err := c.client.ListFunctions(`\.(String|Error)$`, 0)
+	if err != nil {
+		return fmt.Sprintf("failed to list String and Error methods: %v", err)
+	}
+	methods := stringerMethods(functions)
+
+	c.client.SetReturnValuesLoadConfig(&api.LoadConfig{MaxStringLen: maxStringLen})
+
+	calls := 0
+	for _, target := range targets {
+		method := methods[stringerType(target.variable.DelveVar)]
+		if method == "" {
+			continue
+		}
+		if calls == maxStringerCalls {
+			return fmt.Sprintf("only the first %d String and Error methods were called; evaluate the rest one by one", maxStringerCalls)
+		}
+		calls++
+
+		text, err := c.callStringer(state.SelectedGoroutine.ID, target.expr, method)
+		if err != nil {
+			if isFunctionCallUnsupported(err) {
+				return fmt.Sprintf("String and Error methods can't be called on this target: %v", err)
+			}
+			target.variable.StringerError = err.Error()
+			if errors.Is(err, errStoppedInCall) {
+				return "a String or Error call stopped at a breakpoint, so no more were made; continue to let it finish"
+			}
+			continue
+		}
+		target.variable.Stringer = text
+	}
+	return ""
+}
+
+// callStringer calls the String or Error method of the value of expr and returns its result
+func (c *Client) callStringer(goroutineID int64, expr, method string) (string, error) {
+	call := fmt.Sprintf("(%s).%s()", expr, method)
+	logger.Debug("Calling %s on goroutine %d", call, goroutineID)
+
+	state, err := c.client.Call(goroutineID, call, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to call %s: %v", call, err)
+	}
+
+	thread := state.CurrentThread
+	if thread == nil {
+		return "", fmt.Errorf("no current thread after calling %s", call)
+	}
+	if bp := thread.Breakpoint; bp != nil && !thread.CallReturn {
+		return "", fmt.Errorf("%s: %w, breakpoint %d", call, errStoppedInCall, bp.ID)
+	}
+
+	for i := range thread.ReturnValues {
+		v := &thread.ReturnValues[i]
+		if v.Name == "~panic" {
+			return "", fmt.Errorf("%s panicked", call)
+		}
+		if v.Kind == reflect.String {
+			text := v.Value
+			if loaded := int64(len(v.Value)); loaded < v.Len {
+				text += fmt.Sprintf(" (truncated, %d more)", v.Len-loaded)
+			}
+			return text, nil
+		}
+	}
+	return "", fmt.Errorf("%s did not return a string", call)
+}
+
+// stringerMethods maps each type with a String or Error method to the one to call, keyed by
+// package path and type name. Error is picked over String, as fmt does.
+func stringerMethods(functions []string) map[string]string {
+	methods := make(map[string]string)
+	for _, function := range functions {
+		m, ok := parseMethodName(function)
+		if !ok || (m.method != "String" && m.method != "Error") {
+			continue
+		}
+		key := m.pkg + "." + m.receiver
+		if methods[key] != "Error" {
+			methods[key] = m.method
+		}
+	}
+	return methods
+}
+
+// stringerType returns the package path and name of the type whose methods render a value:
+// the pointee's for a pointer and the dynamic type's for an interface. It returns "" for a
+// nil pointer or interface, whose methods could only fail or panic.
+func stringerType(v *api.Variable) string {
+	if v == nil || v.Unreadable != "" {
+		return ""
+	}
+
+	t := v.Type
+	switch v.Kind {
+	case reflect.Ptr:
+		if len(v.Children) > 0 && v.Children[0].Addr == 0 {
+			return ""
+		}
+	case reflect.Interface:
+		if len(v.Children) == 0 || v.Children[0].Kind == reflect.Invalid {
+			return ""
+		}
+		t = v.Children[0].Type
+	}
+
+	t = strings.TrimPrefix(t, "*")
+	if i := strings.Index(t, "["); i >= 0 {
+		t = t[:i]
+	}
+	return t
+}
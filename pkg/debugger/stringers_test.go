@@ -0,0 +1,80 @@
+package debugger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestStringerMethods(t *testing.T) {
+	functions := []string{
+		"main.Celsius.String",
+		"main.(*Celsius).String",
+		"main.(*Handler).String",
+		"main.Code.String",
+		"main.Code.Error",
+		"errors.(*errorString).Error",
+		"main.(*Handler).String-fm",
+		"main.(*Handler).Serve",
+		"main.String",
+	}
+
+	expected := map[string]string{
+		"main.Celsius":       "String",
+		"main.Handler":       "String",
+		"main.Code":          "Error",
+		"errors.errorString": "Error",
+	}
+	if result := stringerMethods(functions); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestStringerType(t *testing.T) {
+	testCases := []struct {
+		name     string
+		variable *api.Variable
+		expected string
+	}{
+		{name: "Named value", variable: &api.Variable{Type: "main.Celsius", Kind: reflect.Float64}, expected: "main.Celsius"},
+		{name: "Pointer", variable: &api.Variable{Type: "*main.Handler", Kind: reflect.Ptr, Children: []api.Variable{{Type: "main.Handler", Addr: 0xc000010000}}}, expected: "main.Handler"},
+		{name: "Nil pointer", variable: &api.Variable{Type: "*main.Handler", Kind: reflect.Ptr, Children: []api.Variable{{Type: "main.Handler"}}}},
+		{name: "Interface", variable: &api.Variable{Type: "error", Kind: reflect.Interface, Children: []api.Variable{{Type: "*errors.errorString", Kind: reflect.Ptr}}}, expected: "errors.errorString"},
+		{name: "Nil interface", variable: &api.Variable{Type: "error", Kind: reflect.Interface, Children: []api.Variable{{Kind: reflect.Invalid}}}},
+		{name: "Generic type", variable: &api.Variable{Type: "main.List[int]", Kind: reflect.Struct}, expected: "main.List"},
+		{name: "Unreadable", variable: &api.Variable{Type: "main.Celsius", Unreadable: "bad address"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := stringerType(tc.variable); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestCallStringersSkipsCalls(t *testing.T) {
+	state := &api.DebuggerState{SelectedGoroutine: &api.Goroutine{ID: 1}}
+	variable := &types.Variable{Name: "t", DelveVar: &api.Variable{Type: "main.Celsius"}}
+	targets := []stringerTarget{{expr: "t", variable: variable}}
+
+	t.Run("Core dump", func(t *testing.T) {
+		c := NewClient()
+		c.coreFile = "core.1234"
+		if note := c.callStringers(state, 0, targets, 64); !strings.Contains(note, "core dump") {
+			t.Errorf("Expected a note about the core dump, got %q", note)
+		}
+	})
+	t.Run("Outer frame", func(t *testing.T) {
+		if note := NewClient().callStringers(state, 1, targets, 64); !strings.Contains(note, "frame 0") {
+			t.Errorf("Expected a note about frame 0, got %q", note)
+		}
+	})
+	if variable.Stringer != "" || variable.StringerError != "" {
+		t.Errorf("Expected the variable to be left alone, got %q and %q", variable.Stringer, variable.StringerError)
+	}
+}
@@ -0,0 +1,272 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// tableLoadConfig loads the elements of a tabulated slice with their fields, and one more
+// level of the values in them
+var tableLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 2,
+	MaxStringLen:       64,
+	MaxArrayValues:     defaultEvalMaxElements,
+	MaxStructFields:    -1,
+}
+
+// tableMaxCellWidth is the widest a table cell is rendered, longer values being cut short
+const tableMaxCellWidth = 48
+
+// valueColumn heads the single column of a table of elements that are not structs
+const valueColumn = "value"
+
+// TabulateSlice evaluates a slice or array of structs and renders the given fields of each
+// element as a row of aligned columns, led by the element's index. A field may name a
+// field of a nested struct, as in Addr.Host, and pointers to structs are followed. Without
+// fields, every field of the element type is shown; elements that are not structs are
+// shown in a single column. Up to the eval element limit of elements are loaded, the rest
+// being counted as not shown.
+func (c *Client) TabulateSlice(expr string, fields []string, frame int) types.TableResponse {
+	if c.client == nil {
+		return c.createTableResponse(nil, expr, nil, nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createTableResponse(nil, expr, nil, nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createTableResponse(nil, expr, nil, nil, nil, fmt.Errorf("cannot tabulate while the target is running; stop the target first"))
+	}
+	if state.SelectedGoroutine == nil {
+		return c.createTableResponse(state, expr, nil, nil, nil, fmt.Errorf("no goroutine selected"))
+	}
+
+	scope := api.EvalScope{GoroutineID: state.SelectedGoroutine.ID, Frame: frame}
+	logger.Debug("Tabulating %s in frame %d", expr, frame)
+	v, err := c.client.EvalVariable(scope, expr, tableLoadConfig)
+	if err != nil {
+		if isUnresolvedSymbol(err) {
+			return c.createTableResponse(state, expr, nil, nil, nil, fmt.Errorf("could not resolve %q: %v; variables in scope: %s", expr, err, c.scopeVariableNames(scope)))
+		}
+		return c.createTableResponse(state, expr, nil, nil, nil, fmt.Errorf("failed to evaluate %q: %v", expr, err))
+	}
+	if v.Kind != reflect.Slice && v.Kind != reflect.Array {
+		return c.createTableResponse(state, expr, v, nil, nil, fmt.Errorf("%q is a %s of type %s, not a slice or array", expr, v.Kind, v.Type))
+	}
+
+	columns, rows, err := tabulate(v, fields)
+	return c.createTableResponse(state, expr, v, columns, rows, err)
+}
+
+// tabulate renders the loaded elements of a slice or array as rows of the given fields,
+// or of all the fields of the first struct element without any
+func tabulate(v *api.Variable, fields []string) ([]string, [][]string, error) {
+	var sample *api.Variable
+	for i := range v.Children {
+		if sample = derefStruct(&v.Children[i]); sample != nil {
+			break
+		}
+	}
+	// Pointers that are all nil may still point to structs
+	structElements := sample != nil || len(fields) > 0 && len(v.Children) > 0 && isNilReference(&v.Children[0])
+	if !structElements && len(v.Children) > 0 && len(fields) > 0 {
+		return nil, nil, fmt.Errorf("the elements of %s are not structs, so have no fields to show; leave fields out to show their values", v.Type)
+	}
+
+	if !structElements {
+		rows := make([][]string, 0, len(v.Children))
+		for i := range v.Children {
+			rows = append(rows, []string{strconv.Itoa(i), tableCell(&v.Children[i])})
+		}
+		return []string{"#", valueColumn}, rows, nil
+	}
+
+	if sample != nil {
+		if len(fields) == 0 {
+			fields = structFieldNames(sample)
+		}
+		for _, field := range fields {
+			if _, err := fieldValue(sample, field); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	rows := make([][]string, 0, len(v.Children))
+	for i := range v.Children {
+		row := []string{strconv.Itoa(i)}
+		for _, field := range fields {
+			cell, err := fieldValue(&v.Children[i], field)
+			if err != nil {
+				cell = "?"
+			}
+			row = append(row, cell)
+		}
+		rows = append(rows, row)
+	}
+	return append([]string{"#"}, fields...), rows, nil
+}
+
+// fieldValue renders the field at a dotted path of a struct element, or "nil" when a
+// pointer on the way is nil
+func fieldValue(element *api.Variable, path string) (string, error) {
+	v := element
+	for _, name := range strings.Split(path, ".") {
+		s := derefStruct(v)
+		if s == nil {
+			if isNilReference(v) {
+				return "nil", nil
+			}
+			return "", fmt.Errorf("cannot get field %s of %s: %s is a %s, not a struct", name, path, v.Type, v.Kind)
+		}
+		v = nil
+		for i := range s.Children {
+			if s.Children[i].Name == name {
+				v = &s.Children[i]
+				break
+			}
+		}
+		if v == nil {
+			return "", fmt.Errorf("%s has no field %s; its fields are: %s", s.Type, name, strings.Join(structFieldNames(s), ", "))
+		}
+	}
+	return tableCell(v), nil
+}
+
+// derefStruct returns the struct a value is or points to, following pointers, or nil
+func derefStruct(v *api.Variable) *api.Variable {
+	for v.Kind == reflect.Ptr || v.Kind == reflect.Interface {
+		if isNilReference(v) {
+			return nil
+		}
+		v = &v.Children[0]
+	}
+	if v.Kind != reflect.Struct {
+		return nil
+	}
+	return v
+}
+
+// isNilReference reports whether a value is a nil pointer or interface
+func isNilReference(v *api.Variable) bool {
+	switch v.Kind {
+	case reflect.Ptr:
+		return len(v.Children) == 0 || v.Children[0].Addr == 0
+	case reflect.Interface:
+		return len(v.Children) == 0 || v.Children[0].Kind == reflect.Invalid
+	default:
+		return false
+	}
+}
+
+// structFieldNames lists the field names of a loaded struct
+func structFieldNames(s *api.Variable) []string {
+	names := make([]string, 0, len(s.Children))
+	for _, field := range s.Children {
+		names = append(names, field.Name)
+	}
+	return names
+}
+
+// tableCell renders a value for a table cell: strings quoted, so empty ones show, pointers
+// as what they point to, and long values cut to tableMaxCellWidth
+func tableCell(v *api.Variable) string {
+	var cell string
+	switch {
+	case v.Kind == reflect.String:
+		cell = strconv.Quote(v.Value)
+		if int64(len(v.Value)) < v.Len {
+			cell += "..."
+		}
+	case isNilReference(v):
+		cell = "nil"
+	case v.Kind == reflect.Ptr && v.Children[0].OnlyAddr:
+		cell = formatPointerAddress(v)
+	case v.Kind == reflect.Ptr:
+		cell = "&" + formatVariableValue(&v.Children[0])
+	default:
+		cell = formatVariableValue(v)
+	}
+	cell = strings.ReplaceAll(cell, "\n", " ")
+	if runes := []rune(cell); len(runes) > tableMaxCellWidth {
+		cell = string(runes[:tableMaxCellWidth-3]) + "..."
+	}
+	return cell
+}
+
+// renderTable aligns columns and rows as text, with a line under the header
+func renderTable(columns []string, rows [][]string) string {
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = len([]rune(column))
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := len([]rune(cell)); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		for i, cell := range cells {
+			if i == len(cells)-1 {
+				b.WriteString(cell)
+				break
+			}
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-len([]rune(cell))+2))
+		}
+		b.WriteString("\n")
+	}
+	writeRow(columns)
+	rule := make([]string, len(columns))
+	for i := range columns {
+		rule[i] = strings.Repeat("-", widths[i])
+	}
+	writeRow(rule)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+// createTableResponse creates a TableResponse from the rows of a tabulated slice
+func (c *Client) createTableResponse(state *api.DebuggerState, expr string, v *api.Variable, columns []string, rows [][]string, err error) types.TableResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "tabulate_slice"
+
+	response := types.TableResponse{
+		Status:     "success",
+		Context:    context,
+		Expression: expr,
+		Columns:    columns,
+		Rows:       rows,
+	}
+	if v != nil {
+		response.Type = v.Type
+		response.Len = v.Len
+		response.Shown = len(v.Children)
+		response.Truncated = int64(len(v.Children)) < v.Len
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	response.Table = renderTable(columns, rows)
+	if response.Truncated {
+		response.Table += fmt.Sprintf("(%d more rows not shown)\n", response.Len-int64(response.Shown))
+	}
+	return response
+}
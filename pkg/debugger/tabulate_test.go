@@ -0,0 +1,216 @@
+package debugger
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+// request builds a loaded struct element of a []Request
+func request(id, path string, host *api.Variable) api.Variable {
+	return api.Variable{Type: "main.Request", Kind: reflect.Struct, Children: []api.Variable{
+		{Name: "ID", Type: "int", Kind: reflect.Int, Value: id},
+		{Name: "Path", Type: "string", Kind: reflect.String, Value: path, Len: int64(len(path))},
+		*host,
+	}}
+}
+
+func addr(host string) *api.Variable {
+	return &api.Variable{Name: "Addr", Type: "*main.Addr", Kind: reflect.Ptr, Children: []api.Variable{
+		{Type: "main.Addr", Kind: reflect.Struct, Addr: 0xc000010000, Children: []api.Variable{
+			{Name: "Host", Type: "string", Kind: reflect.String, Value: host, Len: int64(len(host))},
+		}},
+	}}
+}
+
+func TestTabulate(t *testing.T) {
+	nilAddr := &api.Variable{Name: "Addr", Type: "*main.Addr", Kind: reflect.Ptr, Children: []api.Variable{{Type: "main.Addr", Kind: reflect.Struct}}}
+	requests := &api.Variable{Type: "[]main.Request", Kind: reflect.Slice, Len: 2, Children: []api.Variable{
+		request("1", "/users", addr("example.com")),
+		request("2", "", nilAddr),
+	}}
+	ints := &api.Variable{Type: "[]int", Kind: reflect.Slice, Len: 2, Children: []api.Variable{
+		{Type: "int", Kind: reflect.Int, Value: "7"},
+		{Type: "int", Kind: reflect.Int, Value: "9"},
+	}}
+
+	testCases := []struct {
+		name            string
+		v               *api.Variable
+		fields          []string
+		expectedColumns []string
+		expectedRows    [][]string
+		expectedError   string
+	}{
+		{
+			name:            "Chosen fields",
+			v:               requests,
+			fields:          []string{"Path", "ID"},
+			expectedColumns: []string{"#", "Path", "ID"},
+			expectedRows:    [][]string{{"0", `"/users"`, "1"}, {"1", `""`, "2"}},
+		},
+		{
+			name:            "Nested field through a pointer",
+			v:               requests,
+			fields:          []string{"Addr.Host"},
+			expectedColumns: []string{"#", "Addr.Host"},
+			expectedRows:    [][]string{{"0", `"example.com"`}, {"1", "nil"}},
+		},
+		{
+			name:            "Every field",
+			v:               requests,
+			expectedColumns: []string{"#", "ID", "Path", "Addr"},
+		},
+		{
+			name:          "Unknown field",
+			v:             requests,
+			fields:        []string{"Method"},
+			expectedError: "main.Request has no field Method; its fields are: ID, Path, Addr",
+		},
+		{
+			name:          "Field of a scalar",
+			v:             requests,
+			fields:        []string{"ID.X"},
+			expectedError: "int is a int, not a struct",
+		},
+		{
+			name:            "Not structs",
+			v:               ints,
+			expectedColumns: []string{"#", "value"},
+			expectedRows:    [][]string{{"0", "7"}, {"1", "9"}},
+		},
+		{
+			name:          "Fields of elements that are not structs",
+			v:             ints,
+			fields:        []string{"ID"},
+			expectedError: "are not structs",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			columns, rows, err := tabulate(tc.v, tc.fields)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Errorf("Expected an error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(columns, tc.expectedColumns) {
+				t.Errorf("Expected columns %v, got %v", tc.expectedColumns, columns)
+			}
+			if tc.expectedRows != nil && !reflect.DeepEqual(rows, tc.expectedRows) {
+				t.Errorf("Expected rows %v, got %v", tc.expectedRows, rows)
+			}
+		})
+	}
+}
+
+func TestTableCell(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	testCases := []struct {
+		name     string
+		v        *api.Variable
+		expected string
+	}{
+		{name: "Truncated string", v: &api.Variable{Kind: reflect.String, Value: "abc", Len: 10}, expected: `"abc"...`},
+		{name: "Nil interface", v: &api.Variable{Kind: reflect.Interface, Children: []api.Variable{{Kind: reflect.Invalid}}}, expected: "nil"},
+		{name: "Pointer", v: addr("a.com"), expected: "&{Host:a.com}"},
+		{name: "Pointer not loaded", v: &api.Variable{Type: "*main.Addr", Kind: reflect.Ptr, Children: []api.Variable{{Addr: 0xc000010000, OnlyAddr: true}}}, expected: "(*main.Addr)(0xc000010000)"},
+		{name: "Long value", v: &api.Variable{Kind: reflect.Int, Value: long}, expected: long[:tableMaxCellWidth-3] + "..."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := tableCell(tc.v); result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	table := renderTable([]string{"#", "Path", "ID"}, [][]string{{"0", `"/users"`, "1"}, {"1", `""`, "2"}})
+	expected := "#  Path      ID\n-  --------  --\n0  \"/users\"  1\n1  \"\"        2\n"
+	if table != expected {
+		t.Errorf("Expected table:\n%s\ngot:\n%s", expected, table)
+	}
+}
+
+func TestCreateTableResponseTruncated(t *testing.T) {
+	v := &api.Variable{Type: "[]int", Kind: reflect.Slice, Len: 250, Children: make([]api.Variable, 100)}
+	response := NewClient().createTableResponse(nil, "xs", v, []string{"#", "value"}, nil, nil)
+	if !response.Truncated || response.Shown != 100 || !strings.HasSuffix(response.Table, "(150 more rows not shown)\n") {
+		t.Errorf("Expected 150 rows noted as not shown, got %+v", response)
+	}
+}
+
+func TestTabulateSlice(t *testing.T) {
+	requests := &api.Variable{Name: "requests", Type: "[]main.Request", Kind: reflect.Slice, Len: 2, Children: []api.Variable{
+		request("1", "/users", addr("example.com")),
+		request("2", "/health", addr("localhost")),
+	}}
+
+	var scopes []api.EvalScope
+	var configs []*api.LoadConfig
+	eval := fakeEval(t, map[string]*api.Variable{
+		"requests": requests,
+		"count":    {Name: "count", Type: "int", Kind: reflect.Int, Value: "2"},
+	})
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+		"Eval": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.EvalIn
+			decodeFakeArgs(t, raw, &args)
+			scopes, configs = append(scopes, args.Scope), append(configs, args.Cfg)
+			return eval(raw)
+		},
+	})
+
+	response := c.TabulateSlice("requests", []string{"Path", "Addr.Host"}, 1)
+	if response.Status != "success" {
+		t.Fatalf("Expected a table, got %s", response.Context.ErrorMessage)
+	}
+	expected := "#  Path       Addr.Host\n-  ---------  -------------\n0  \"/users\"   \"example.com\"\n1  \"/health\"  \"localhost\"\n"
+	if response.Table != expected {
+		t.Errorf("Expected table:\n%s\ngot:\n%s", expected, response.Table)
+	}
+	if response.Type != "[]main.Request" || response.Len != 2 || response.Shown != 2 || response.Truncated {
+		t.Errorf("Expected both elements of the []main.Request shown, got %+v", response)
+	}
+	if len(scopes) != 1 || scopes[0].GoroutineID != 1 || scopes[0].Frame != 1 || !reflect.DeepEqual(*configs[0], tableLoadConfig) {
+		t.Errorf("Expected requests evaluated in frame 1 of goroutine 1 with the table load config, got %+v %+v", scopes, configs)
+	}
+
+	testCases := []struct {
+		name     string
+		expr     string
+		fields   []string
+		expected string
+	}{
+		{name: "Not a slice", expr: "count", expected: `"count" is a int of type int, not a slice or array`},
+		{name: "Unknown field", expr: "requests", fields: []string{"Method"}, expected: "main.Request has no field Method; its fields are: ID, Path, Addr"},
+		{name: "Unresolved", expr: "missing", expected: "could not resolve"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := c.TabulateSlice(tc.expr, tc.fields, 0)
+			if response.Status != "error" || response.Context.Operation != "tabulate_slice" || !strings.Contains(response.Context.ErrorMessage, tc.expected) {
+				t.Errorf("Expected an error containing %q, got %+v", tc.expected, response.Context)
+			}
+		})
+	}
+
+	response = NewClient().TabulateSlice("xs", nil, 0)
+	if response.Status != "error" || response.Context.Operation != "tabulate_slice" {
+		t.Errorf("Expected a tabulate_slice error without a session, got %+v", response)
+	}
+}
@@ -0,0 +1,183 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// ListThreads returns the OS threads of the debugged process sorted by ID, with where each
+// one is and the goroutine it runs, if any. Threads outside Go source, such as ones in
+// cgo calls or the runtime's own, are shown at their PC.
+func (c *Client) ListThreads() types.ThreadListResponse {
+	if c.client == nil {
+		return c.createThreadListResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createThreadListResponse(nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createThreadListResponse(nil, nil, fmt.Errorf("cannot list threads while the target is running; stop the target first"))
+	}
+
+	logger.Debug("Listing threads")
+	threads, err := c.client.ListThreads()
+	if err != nil {
+		return c.createThreadListResponse(state, nil, fmt.Errorf("failed to list threads: %v", err))
+	}
+	sort.Slice(threads, func(i, j int) bool { return threads[i].ID < threads[j].ID })
+
+	var currentID int
+	if state.CurrentThread != nil {
+		currentID = state.CurrentThread.ID
+	}
+
+	converted := make([]types.Thread, 0, len(threads))
+	for _, th := range threads {
+		converted = append(converted, convertThread(th, currentID))
+	}
+
+	return c.createThreadListResponse(state, converted, nil)
+}
+
+// SwitchThread makes the given OS thread the current one, so subsequent commands inspect
+// the goroutine it runs, or the thread itself when it runs none
+func (c *Client) SwitchThread(id int) types.SwitchThreadResponse {
+	if c.client == nil {
+		return c.createSwitchThreadResponse(nil, nil, 0, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createSwitchThreadResponse(nil, nil, 0, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createSwitchThreadResponse(nil, nil, 0, fmt.Errorf("cannot switch threads while the target is running; stop the target first"))
+	}
+
+	var previousID int
+	if state.CurrentThread != nil {
+		previousID = state.CurrentThread.ID
+	}
+
+	threads, err := c.client.ListThreads()
+	if err != nil {
+		return c.createSwitchThreadResponse(state, nil, previousID, fmt.Errorf("failed to list threads: %v", err))
+	}
+	if findThread(threads, id) == nil {
+		return c.createSwitchThreadResponse(state, nil, previousID, fmt.Errorf("thread %d not found; the process has threads %s", id, threadIDs(threads)))
+	}
+
+	logger.Debug("Switching from thread %d to thread %d", previousID, id)
+	newState, err := c.client.SwitchThread(id)
+	if err != nil {
+		return c.createSwitchThreadResponse(state, nil, previousID, fmt.Errorf("failed to switch to thread %d: %v", id, err))
+	}
+
+	current := newState.CurrentThread
+	if current == nil {
+		current = findThread(threads, id)
+	}
+	thread := convertThread(current, id)
+
+	return c.createSwitchThreadResponse(newState, &thread, previousID, nil)
+}
+
+// findThread returns the thread with the given ID, or nil
+func findThread(threads []*api.Thread, id int) *api.Thread {
+	for _, th := range threads {
+		if th.ID == id {
+			return th
+		}
+	}
+	return nil
+}
+
+// threadIDs lists the IDs of threads, for errors naming the ones that exist
+func threadIDs(threads []*api.Thread) string {
+	ids := make([]int, 0, len(threads))
+	for _, th := range threads {
+		ids = append(ids, th.ID)
+	}
+	sort.Ints(ids)
+	return fmt.Sprint(ids)
+}
+
+// convertThread converts a Delve thread to our type, flagging it when it is the current one
+func convertThread(th *api.Thread, currentID int) types.Thread {
+	thread := types.Thread{
+		ID:          th.ID,
+		PC:          fmt.Sprintf("%#x", th.PC),
+		Location:    getThreadLocation(th),
+		Position:    getThreadPosition(th),
+		GoroutineID: th.GoroutineID,
+		Current:     th.ID == currentID,
+	}
+	if th.Breakpoint != nil {
+		thread.BreakpointID = th.Breakpoint.ID
+	}
+	return thread
+}
+
+// summarizeThreads sums up how many threads run goroutines
+func summarizeThreads(threads []types.Thread) string {
+	var running int
+	for _, th := range threads {
+		if th.GoroutineID != 0 {
+			running++
+		}
+	}
+	return fmt.Sprintf("%d threads, %d running goroutines, %d without one", len(threads), running, len(threads)-running)
+}
+
+// createThreadListResponse creates a ThreadListResponse
+func (c *Client) createThreadListResponse(state *api.DebuggerState, threads []types.Thread, err error) types.ThreadListResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "list_threads"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.ThreadListResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	response := types.ThreadListResponse{
+		Status:  "success",
+		Context: context,
+		Threads: threads,
+		Summary: summarizeThreads(threads),
+	}
+	for _, th := range threads {
+		if th.Current {
+			response.CurrentThreadID = th.ID
+		}
+	}
+	return response
+}
+
+// createSwitchThreadResponse creates a SwitchThreadResponse
+func (c *Client) createSwitchThreadResponse(state *api.DebuggerState, thread *types.Thread, previousID int, err error) types.SwitchThreadResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "switch_thread"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.SwitchThreadResponse{
+			Status:           "error",
+			Context:          context,
+			PreviousThreadID: previousID,
+		}
+	}
+
+	return types.SwitchThreadResponse{
+		Status:           "success",
+		Context:          context,
+		Thread:           *thread,
+		PreviousThreadID: previousID,
+	}
+}
@@ -0,0 +1,142 @@
+package debugger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestConvertThread(t *testing.T) {
+	testCases := []struct {
+		name             string
+		thread           api.Thread
+		currentID        int
+		expectedLocation string
+		expectedCurrent  bool
+		expectedBP       int
+	}{
+		{
+			name:             "Current thread at a breakpoint",
+			thread:           api.Thread{ID: 7, PC: 0x4b58e0, File: "/src/main.go", Line: 13, Function: &api.Function{Name_: "main.main"}, GoroutineID: 1, Breakpoint: &api.Breakpoint{ID: 2}},
+			currentID:        7,
+			expectedLocation: "At /src/main.go:13 in main.main",
+			expectedCurrent:  true,
+			expectedBP:       2,
+		},
+		{
+			name:             "Thread in the runtime",
+			thread:           api.Thread{ID: 8, PC: 0x47f917, File: "/go/src/runtime/sys_linux_amd64.s", Line: 135, Function: &api.Function{Name_: "runtime.usleep"}},
+			currentID:        7,
+			expectedLocation: "At /go/src/runtime/sys_linux_amd64.s:135 in runtime.usleep",
+		},
+		{
+			name:             "Thread outside Go source",
+			thread:           api.Thread{ID: 9, PC: 0x7f0012345678},
+			currentID:        7,
+			expectedLocation: "At PC 0x7f0012345678, outside Go source",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			thread := convertThread(&tc.thread, tc.currentID)
+			if thread.Location == nil || *thread.Location != tc.expectedLocation {
+				t.Errorf("Expected location %q, got %v", tc.expectedLocation, thread.Location)
+			}
+			if thread.Current != tc.expectedCurrent {
+				t.Errorf("Expected current %v, got %v", tc.expectedCurrent, thread.Current)
+			}
+			if thread.BreakpointID != tc.expectedBP {
+				t.Errorf("Expected breakpoint %d, got %d", tc.expectedBP, thread.BreakpointID)
+			}
+			if thread.GoroutineID != tc.thread.GoroutineID {
+				t.Errorf("Expected goroutine %d, got %d", tc.thread.GoroutineID, thread.GoroutineID)
+			}
+		})
+	}
+}
+
+func TestSummarizeThreads(t *testing.T) {
+	threads := []types.Thread{{ID: 1, GoroutineID: 1}, {ID: 2}, {ID: 3, GoroutineID: 5}}
+	expected := "3 threads, 2 running goroutines, 1 without one"
+	if summary := summarizeThreads(threads); summary != expected {
+		t.Errorf("Expected %q, got %q", expected, summary)
+	}
+}
+
+func TestThreadIDs(t *testing.T) {
+	threads := []*api.Thread{{ID: 12}, {ID: 3}, {ID: 7}}
+	if ids := threadIDs(threads); ids != "[3 7 12]" {
+		t.Errorf("Expected sorted IDs, got %q", ids)
+	}
+	if findThread(threads, 7) != threads[2] || findThread(threads, 4) != nil {
+		t.Error("Expected findThread to find thread 7 and not thread 4")
+	}
+}
+
+// threadedTarget returns a fake Delve whose current thread is 2, out of threads 3, 1 and 2,
+// which switches to the thread each switch_thread command names
+func threadedTarget(t *testing.T) (*Client, *[]string) {
+	t.Helper()
+	threads := []*api.Thread{
+		{ID: 3, PC: 0x47f917},
+		{ID: 1, PC: 0x4b58e0, File: "main.go", Line: 13, Function: &api.Function{Name_: "main.main"}, GoroutineID: 1},
+		{ID: 2, PC: 0x4b5900, File: "main.go", Line: 20, Function: &api.Function{Name_: "main.worker"}, GoroutineID: 6},
+	}
+	var commands []string
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State":       fakeState(&api.DebuggerState{CurrentThread: threads[2], SelectedGoroutine: &api.Goroutine{ID: 6}}),
+		"ListThreads": fakeResult(rpc2.ListThreadsOut{Threads: threads}),
+		"Command": fakeCommands(t, &commands, func(command api.DebuggerCommand) api.DebuggerState {
+			return api.DebuggerState{CurrentThread: findThread(threads, command.ThreadID)}
+		}),
+	})
+	return c, &commands
+}
+
+func TestListThreads(t *testing.T) {
+	c, _ := threadedTarget(t)
+
+	response := c.ListThreads()
+	if response.Status != "success" {
+		t.Fatalf("Expected the threads, got %s", response.Context.ErrorMessage)
+	}
+	var ids []int
+	for _, th := range response.Threads {
+		ids = append(ids, th.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Errorf("Expected the threads sorted by ID, got %v", ids)
+	}
+	if response.CurrentThreadID != 2 || !response.Threads[1].Current || response.Threads[0].Current {
+		t.Errorf("Expected only thread 2 current, got %+v", response.Threads)
+	}
+	if response.Summary != "3 threads, 2 running goroutines, 1 without one" {
+		t.Errorf("Expected a summary of the goroutines run, got %q", response.Summary)
+	}
+}
+
+func TestSwitchThread(t *testing.T) {
+	c, commands := threadedTarget(t)
+
+	response := c.SwitchThread(1)
+	if response.Status != "success" || response.Thread.ID != 1 || response.Thread.GoroutineID != 1 || response.PreviousThreadID != 2 {
+		t.Fatalf("Expected a switch from thread 2 to thread 1, got %+v", response)
+	}
+	if !reflect.DeepEqual(*commands, []string{api.SwitchThread}) {
+		t.Errorf("Expected a single switch, got %v", *commands)
+	}
+
+	// A thread that doesn't exist is refused before switching, naming the ones that do
+	response = c.SwitchThread(9)
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "thread 9 not found; the process has threads [1 2 3]") {
+		t.Errorf("Expected thread 9 not to be found, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	if len(*commands) != 1 {
+		t.Errorf("Expected no switch to a missing thread, got %v", *commands)
+	}
+}
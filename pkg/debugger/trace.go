@@ -0,0 +1,205 @@
+package debugger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxTraceHits caps how many tracepoint hits are kept, dropping the oldest first
+const maxTraceHits = 10000
+
+// traceLog keeps tracepoint hits in the order they happened. Sequence numbers start
+// at 1 and keep increasing after old hits are dropped, so they stay valid for polling.
+type traceLog struct {
+	mu      sync.Mutex
+	hits    []types.TraceHit
+	lastSeq int64 // Sequence number of the most recent hit
+	dropped int64 // Hits dropped so far
+}
+
+// record adds a hit, assigning it the next sequence number
+func (l *traceLog) record(hit types.TraceHit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastSeq++
+	hit.Seq = l.lastSeq
+	l.hits = append(l.hits, hit)
+	if len(l.hits) > maxTraceHits {
+		n := len(l.hits) - maxTraceHits
+		l.hits = append([]types.TraceHit(nil), l.hits[n:]...)
+		l.dropped += int64(n)
+	}
+}
+
+// latest returns the sequence number of the most recent hit and how many were dropped
+func (l *traceLog) latest() (int64, int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lastSeq, l.dropped
+}
+
+// since returns hits after the given sequence number, optionally for a single breakpoint
+func (l *traceLog) since(seq int64, breakpointID int) ([]types.TraceHit, int64, int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hits := make([]types.TraceHit, 0)
+	for _, hit := range l.hits {
+		if hit.Seq <= seq {
+			continue
+		}
+		if breakpointID != 0 && hit.BreakpointID != breakpointID {
+			continue
+		}
+		hits = append(hits, hit)
+	}
+	return hits, l.lastSeq, l.dropped
+}
+
+// SetTracepoint sets a breakpoint that records the given expressions on every hit
+// instead of stopping the program. Delve evaluates condition on each hit; hits where it
+// is false are neither recorded nor counted, so hitCondition, e.g. "<= 100", limits the
+// number of matching hits that are recorded.
+func (c *Client) SetTracepoint(file string, line int, condition, hitCondition string, expressions []string) types.BreakpointResponse {
+	if c.client == nil {
+		return c.createTracepointResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	if condition != "" {
+		if err := validateCondition(condition); err != nil {
+			return c.createTracepointResponse(nil, nil, err)
+		}
+	}
+
+	if hitCondition != "" {
+		var err error
+		if hitCondition, err = normalizeHitCondition(hitCondition); err != nil {
+			return c.createTracepointResponse(nil, nil, err)
+		}
+	}
+
+	logger.Debug("Setting tracepoint at %s:%d capturing %v, condition %q, hit condition %q", file, line, expressions, condition, hitCondition)
+	bp, err := c.client.CreateBreakpoint(&api.Breakpoint{
+		File:       file,
+		Line:       line,
+		Cond:       condition,
+		HitCond:    hitCondition,
+		Tracepoint: true,
+		Variables:  expressions,
+	})
+	if err != nil {
+		return c.createTracepointResponse(nil, nil, fmt.Errorf("failed to set tracepoint: %v", err))
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		logger.Debug("Warning: Failed to get state after setting tracepoint: %v", err)
+	}
+
+	return c.createTracepointResponse(state, bp, nil)
+}
+
+// ReadTrace returns the tracepoint hits recorded after sinceSeq, oldest first.
+// A breakpointID of 0 returns hits of every tracepoint.
+func (c *Client) ReadTrace(sinceSeq int64, breakpointID int) types.TraceReadResponse {
+	hits, lastSeq, dropped := c.trace.since(sinceSeq, breakpointID)
+
+	var state *api.DebuggerState
+	if c.client != nil {
+		var err error
+		state, err = c.client.GetStateNonBlocking()
+		if err != nil {
+			logger.Debug("Warning: Failed to get state while reading trace: %v", err)
+		}
+	}
+
+	context := c.createDebugContext(state)
+	context.Operation = "read_trace"
+
+	return types.TraceReadResponse{
+		Status:      "success",
+		Context:     context,
+		Hits:        hits,
+		NextSeq:     lastSeq,
+		DroppedHits: dropped,
+	}
+}
+
+// recordTraceHits adds a hit to the trace log for every thread stopped at a tracepoint,
+// except the ones LineCoverage only counts
+func (c *Client) recordTraceHits(state *api.DebuggerState) {
+	if state == nil {
+		return
+	}
+
+	for _, th := range state.Threads {
+		if th.Breakpoint == nil || !th.Breakpoint.Tracepoint || c.coverageBreakpoints[th.Breakpoint.ID] {
+			continue
+		}
+		c.trace.record(breakpointHit(th))
+	}
+}
+
+// breakpointHit describes the hit of the breakpoint a thread is stopped at, with the
+// values of its capture expressions
+func breakpointHit(th *api.Thread) types.TraceHit {
+	bp := th.Breakpoint
+	hit := types.TraceHit{
+		Timestamp:    time.Now(),
+		BreakpointID: bp.ID,
+		GoroutineID:  th.GoroutineID,
+	}
+	if th.File != "" {
+		hit.Position = &types.SourcePosition{File: th.File, Line: th.Line, Function: getFunctionName(th)}
+		hit.Location = formatPosition(hit.Position)
+	}
+
+	if th.BreakpointInfo != nil {
+		for i := range th.BreakpointInfo.Variables {
+			v := &th.BreakpointInfo.Variables[i]
+			name := v.Name
+			if i < len(bp.Variables) {
+				name = bp.Variables[i]
+			}
+			value := formatVariableValue(v)
+			if v.Unreadable != "" {
+				value = v.Unreadable
+			}
+			hit.Values = append(hit.Values, types.Variable{
+				DelveVar: v,
+				Name:     name,
+				Value:    value,
+				Type:     v.Type,
+				Scope:    "trace",
+				Kind:     getVariableKind(v),
+			})
+		}
+	}
+	return hit
+}
+
+// createTracepointResponse creates a BreakpointResponse for a tracepoint
+func (c *Client) createTracepointResponse(state *api.DebuggerState, bp *api.Breakpoint, err error) types.BreakpointResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "set_tracepoint"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.BreakpointResponse{
+			Status:  "error",
+			Context: context,
+		}
+	}
+
+	return types.BreakpointResponse{
+		Status:     "success",
+		Context:    context,
+		Breakpoint: convertBreakpoint(bp),
+	}
+}
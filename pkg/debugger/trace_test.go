@@ -0,0 +1,70 @@
+package debugger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestTraceLog(t *testing.T) {
+	log := &traceLog{}
+	log.record(types.TraceHit{BreakpointID: 1})
+	log.record(types.TraceHit{BreakpointID: 2})
+	log.record(types.TraceHit{BreakpointID: 1})
+
+	hits, lastSeq, _ := log.since(0, 0)
+	if len(hits) != 3 || lastSeq != 3 {
+		t.Fatalf("Expected 3 hits up to seq 3, got %d hits up to seq %d", len(hits), lastSeq)
+	}
+	for i, hit := range hits {
+		if hit.Seq != int64(i+1) {
+			t.Errorf("Expected hit %d to have seq %d, got %d", i, i+1, hit.Seq)
+		}
+	}
+
+	hits, _, _ = log.since(1, 1)
+	if len(hits) != 1 || hits[0].Seq != 3 {
+		t.Errorf("Expected only hit 3 for breakpoint 1 after seq 1, got %+v", hits)
+	}
+
+	for i := 0; i < maxTraceHits; i++ {
+		log.record(types.TraceHit{BreakpointID: 3})
+	}
+	hits, lastSeq, dropped := log.since(0, 0)
+	if len(hits) != maxTraceHits || dropped != 3 || hits[0].Seq != 4 || lastSeq != int64(maxTraceHits+3) {
+		t.Errorf("Expected the 3 oldest hits to be dropped, got %d hits from seq %d, %d dropped", len(hits), hits[0].Seq, dropped)
+	}
+}
+
+func TestRecordTraceHitsFalseCondition(t *testing.T) {
+	// Delve only reports a breakpoint on a thread when its condition held, and only then
+	// counts the hit, so a hit with a false condition leaves the thread without one
+	tracepoint := func(hits uint64) *api.Breakpoint {
+		return &api.Breakpoint{ID: 1, Tracepoint: true, Cond: `name != "World"`, TotalHitCount: hits}
+	}
+	states := []*api.DebuggerState{
+		{Threads: []*api.Thread{{ID: 1, GoroutineID: 7, File: "main.go", Line: 12, Breakpoint: tracepoint(1)}}},
+		{Threads: []*api.Thread{{ID: 1, GoroutineID: 8, File: "main.go", Line: 12}}},
+		{Threads: []*api.Thread{{ID: 1, GoroutineID: 9, File: "main.go", Line: 12, Breakpoint: tracepoint(2)}}},
+	}
+
+	c := NewClient()
+	now := time.Now()
+	for i, state := range states {
+		c.recordTraceHits(state)
+		c.hitTimes.record(state, now.Add(time.Duration(i)*time.Millisecond))
+	}
+
+	hits, lastSeq, _ := c.trace.since(0, 0)
+	if len(hits) != 2 || lastSeq != 2 {
+		t.Fatalf("Expected only the 2 hits with a true condition to be recorded, got %d up to seq %d", len(hits), lastSeq)
+	}
+	if hits[0].GoroutineID != 7 || hits[1].GoroutineID != 9 {
+		t.Errorf("Expected the hits of goroutines 7 and 9, got %d and %d", hits[0].GoroutineID, hits[1].GoroutineID)
+	}
+	if timed := c.hitTimes.hits[1]; len(timed) != 2 {
+		t.Errorf("Expected 2 counted hits of the tracepoint, got %d", len(timed))
+	}
+}
@@ -0,0 +1,255 @@
+package debugger
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// methodName is a function name split into the parts of a method: "main.(*Handler).Serve"
+// is method Serve of receiver Handler, by pointer, in package main
+type methodName struct {
+	pkg      string
+	receiver string // Receiver type name, without type arguments
+	pointer  bool   // Whether the receiver is a pointer
+	method   string
+}
+
+// parseMethodName splits a function name into the parts of a method, or returns ok false
+// for a plain function, a closure inside a method or a compiler-generated method value
+// wrapper such as "main.(*Handler).Serve-fm"
+func parseMethodName(function string) (methodName, bool) {
+	pkg := functionPackage(function)
+	if pkg == "" {
+		return methodName{}, false
+	}
+	rest := function[len(pkg)+1:]
+
+	var m methodName
+	m.pkg = pkg
+	if strings.HasPrefix(rest, "(*") {
+		end := strings.Index(rest, ").")
+		if end < 0 {
+			return methodName{}, false
+		}
+		m.receiver, m.pointer, m.method = rest[2:end], true, rest[end+2:]
+	} else {
+		// Type arguments may hold dots, as in List[go.shape.int]
+		depth, dot := 0, -1
+		for i, r := range rest {
+			switch r {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			case '.':
+				if depth == 0 && dot < 0 {
+					dot = i
+				}
+			}
+		}
+		if dot < 0 {
+			return methodName{}, false
+		}
+		m.receiver, m.method = rest[:dot], rest[dot+1:]
+	}
+
+	if i := strings.Index(m.receiver, "["); i >= 0 {
+		m.receiver = m.receiver[:i]
+	}
+	if !token.IsIdentifier(m.receiver) || !token.IsIdentifier(m.method) {
+		return methodName{}, false
+	}
+	return m, true
+}
+
+// parseReceiverType splits a type name such as "*main.Handler", "(*Handler)" or
+// "github.com/x/handlers.Handler" into its package, empty when not given, and name
+func parseReceiverType(typeName string) (string, string, error) {
+	t := strings.TrimSpace(typeName)
+	t = strings.TrimSuffix(strings.TrimPrefix(t, "("), ")")
+	t = strings.TrimPrefix(t, "*")
+	if i := strings.Index(t, "["); i >= 0 {
+		t = t[:i]
+	}
+
+	pkg, name := "", t
+	if dot := strings.LastIndex(t, "."); dot >= 0 {
+		pkg, name = t[:dot], t[dot+1:]
+	}
+	if !token.IsIdentifier(name) {
+		return "", "", fmt.Errorf("invalid type name %q: expected a type such as main.Handler or *main.Handler", typeName)
+	}
+	return pkg, name, nil
+}
+
+// matchesReceiver reports whether a method belongs to the type pkg.name. A package given
+// by its last path element, such as "handlers", matches the full import path.
+func (m methodName) matchesReceiver(pkg, name string) bool {
+	if m.receiver != name {
+		return false
+	}
+	return pkg == "" || m.pkg == pkg || strings.HasSuffix(m.pkg, "/"+pkg)
+}
+
+// SetBreakpointsOnType sets a breakpoint on every method of a type, with pointer and value
+// receivers alike, so calls through an interface stop whichever method they reach. A
+// non-empty methodFilter regex narrows the methods by name. Methods that could not get a
+// breakpoint are reported apart from the ones that did, and methods the compiler inlined
+// are flagged, as their breakpoint is at the inlined call sites.
+func (c *Client) SetBreakpointsOnType(typeName, methodFilter string) types.TypeBreakpointsResponse {
+	if c.client == nil {
+		return c.createTypeBreakpointsResponse(nil, typeName, nil, nil, fmt.Errorf("no active debug session"))
+	}
+
+	pkg, name, err := parseReceiverType(typeName)
+	if err != nil {
+		return c.createTypeBreakpointsResponse(nil, typeName, nil, nil, err)
+	}
+
+	var filter *regexp.Regexp
+	if methodFilter != "" {
+		if filter, err = regexp.Compile(methodFilter); err != nil {
+			return c.createTypeBreakpointsResponse(nil, typeName, nil, nil, fmt.Errorf("invalid method filter %q: %v", methodFilter, err))
+		}
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createTypeBreakpointsResponse(nil, typeName, nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createTypeBreakpointsResponse(nil, typeName, nil, nil, fmt.Errorf("cannot set breakpoints while the target is running; stop the target first"))
+	}
+
+	// Narrow down on Delve's side, then match the receiver exactly here
+	names, err := c.client.ListFunctions(regexp.QuoteMeta(name), 0)
+	if err != nil {
+		return c.createTypeBreakpointsResponse(state, typeName, nil, nil, fmt.Errorf("failed to list functions: %v", err))
+	}
+	methods := typeMethods(names, pkg, name, filter)
+	if len(methods) == 0 {
+		if methodFilter != "" {
+			return c.createTypeBreakpointsResponse(state, typeName, nil, nil, fmt.Errorf("type %s has no methods matching %q in the binary; use list_functions to see what was compiled in", typeName, methodFilter))
+		}
+		return c.createTypeBreakpointsResponse(state, typeName, nil, nil, fmt.Errorf("type %s has no methods in the binary; check the type name, or use list_functions to see what was compiled in", typeName))
+	}
+
+	logger.Debug("Setting breakpoints on %d methods of %s", len(methods), typeName)
+
+	var set, failed []types.MethodBreakpoint
+	for _, function := range methods {
+		result := types.MethodBreakpoint{Function: function}
+		bp, err := c.client.CreateBreakpoint(&api.Breakpoint{FunctionName: function})
+		if err != nil {
+			result.Error = err.Error()
+			failed = append(failed, result)
+			continue
+		}
+
+		breakpoint := convertBreakpoint(bp)
+		result.Breakpoint = &breakpoint
+		// An inlined method has no code of its own, so Delve breaks where it was inlined
+		if withoutTypeArguments(bp.FunctionName) != withoutTypeArguments(function) {
+			result.Inlined = true
+			result.InlinedSites = len(bp.Addrs)
+		}
+		set = append(set, result)
+	}
+
+	return c.createTypeBreakpointsResponse(state, typeName, set, failed, nil)
+}
+
+// typeArgumentsPattern matches the type arguments of a generic function name, which Delve
+// spells either as the shape, e.g. List[go.shape.int], or as List[...]
+var typeArgumentsPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// withoutTypeArguments removes the type arguments from a function name
+func withoutTypeArguments(function string) string {
+	return typeArgumentsPattern.ReplaceAllString(function, "")
+}
+
+// typeMethods picks the methods of the type pkg.name out of function names, sorted. When a
+// method has a value receiver, the pointer receiver wrapper the compiler generates for it
+// is left out, as breaking on the method itself catches calls through either.
+func typeMethods(functions []string, pkg, name string, filter *regexp.Regexp) []string {
+	byValue := make(map[string]bool)
+	var matched []methodName
+	var matchedNames []string
+	for _, function := range functions {
+		m, ok := parseMethodName(function)
+		if !ok || !m.matchesReceiver(pkg, name) {
+			continue
+		}
+		if filter != nil && !filter.MatchString(m.method) {
+			continue
+		}
+		if !m.pointer {
+			byValue[m.pkg+"."+m.method] = true
+		}
+		matched = append(matched, m)
+		matchedNames = append(matchedNames, function)
+	}
+
+	var methods []string
+	for i, m := range matched {
+		if m.pointer && byValue[m.pkg+"."+m.method] {
+			continue
+		}
+		methods = append(methods, matchedNames[i])
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// createTypeBreakpointsResponse creates a TypeBreakpointsResponse. Like for a batch of
+// breakpoints, Status is "partial" when some of the methods could not get one.
+func (c *Client) createTypeBreakpointsResponse(state *api.DebuggerState, typeName string, set, failed []types.MethodBreakpoint, err error) types.TypeBreakpointsResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "set_type_breakpoints"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.TypeBreakpointsResponse{
+			Status:  "error",
+			Context: context,
+			Type:    typeName,
+		}
+	}
+
+	response := types.TypeBreakpointsResponse{
+		Context: context,
+		Type:    typeName,
+		Set:     set,
+		Failed:  failed,
+	}
+	var inlined int
+	for _, m := range set {
+		if m.Inlined {
+			inlined++
+		}
+		response.Functions = append(response.Functions, m.Function)
+	}
+
+	switch {
+	case len(failed) == 0:
+		response.Status = "success"
+	case len(set) == 0:
+		response.Status = "error"
+		response.Context.ErrorMessage = fmt.Sprintf("none of the %d methods of %s could get a breakpoint", len(failed), typeName)
+	default:
+		response.Status = "partial"
+	}
+
+	response.Summary = fmt.Sprintf("%d of %d methods of %s have a breakpoint", len(set), len(set)+len(failed), typeName)
+	if inlined > 0 {
+		response.Summary += fmt.Sprintf(", %d of them at inlined call sites", inlined)
+	}
+	return response
+}
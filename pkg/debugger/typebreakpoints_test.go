@@ -0,0 +1,219 @@
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestParseMethodName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		function string
+		expected methodName
+		ok       bool
+	}{
+		{name: "Pointer receiver", function: "main.(*Handler).Serve", expected: methodName{pkg: "main", receiver: "Handler", pointer: true, method: "Serve"}, ok: true},
+		{name: "Value receiver", function: "main.Handler.String", expected: methodName{pkg: "main", receiver: "Handler", method: "String"}, ok: true},
+		{name: "Import path", function: "github.com/x/handlers.(*Handler).Serve", expected: methodName{pkg: "github.com/x/handlers", receiver: "Handler", pointer: true, method: "Serve"}, ok: true},
+		{name: "Generic pointer receiver", function: "main.(*List[go.shape.int]).Push", expected: methodName{pkg: "main", receiver: "List", pointer: true, method: "Push"}, ok: true},
+		{name: "Generic value receiver", function: "main.List[go.shape.int].Len", expected: methodName{pkg: "main", receiver: "List", method: "Len"}, ok: true},
+		{name: "Method value wrapper", function: "main.(*Handler).Serve-fm"},
+		{name: "Closure in a method", function: "main.(*Handler).Serve.func1"},
+		{name: "Plain function", function: "main.main"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, ok := parseMethodName(tc.function)
+			if ok != tc.ok {
+				t.Fatalf("Expected ok %v, got %v", tc.ok, ok)
+			}
+			if ok && result != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseReceiverType(t *testing.T) {
+	testCases := []struct {
+		name        string
+		typeName    string
+		expectedPkg string
+		expected    string
+		expectError bool
+	}{
+		{name: "Pointer type", typeName: "*main.Handler", expectedPkg: "main", expected: "Handler"},
+		{name: "Parenthesized pointer without package", typeName: "(*Handler)", expected: "Handler"},
+		{name: "Import path", typeName: "github.com/x/handlers.Handler", expectedPkg: "github.com/x/handlers", expected: "Handler"},
+		{name: "Generic type", typeName: "main.List[int]", expectedPkg: "main", expected: "List"},
+		{name: "Method instead of a type", typeName: "main.Handler.Serve()", expectError: true},
+		{name: "Empty", typeName: "", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pkg, name, err := parseReceiverType(tc.typeName)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, got %q and %q", pkg, name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if pkg != tc.expectedPkg || name != tc.expected {
+				t.Errorf("Expected %q and %q, got %q and %q", tc.expectedPkg, tc.expected, pkg, name)
+			}
+		})
+	}
+}
+
+func TestTypeMethods(t *testing.T) {
+	functions := []string{
+		"main.(*Handler).Serve",
+		"main.(*Handler).Serve-fm",
+		"main.(*Handler).Serve.func1",
+		"main.Handler.String",
+		"main.(*Handler).String",
+		"main.(*HandlerSet).Add",
+		"main.newHandler",
+		"github.com/x/handlers.(*Handler).Close",
+	}
+
+	testCases := []struct {
+		name     string
+		pkg      string
+		filter   string
+		expected []string
+	}{
+		{name: "All packages", expected: []string{"github.com/x/handlers.(*Handler).Close", "main.(*Handler).Serve", "main.Handler.String"}},
+		{name: "Package main", pkg: "main", expected: []string{"main.(*Handler).Serve", "main.Handler.String"}},
+		{name: "Last element of an import path", pkg: "handlers", expected: []string{"github.com/x/handlers.(*Handler).Close"}},
+		{name: "Method filter", pkg: "main", filter: "^S", expected: []string{"main.(*Handler).Serve", "main.Handler.String"}},
+		{name: "Method filter without matches", pkg: "main", filter: "^Close$"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var filter *regexp.Regexp
+			if tc.filter != "" {
+				filter = regexp.MustCompile(tc.filter)
+			}
+			result := typeMethods(functions, tc.pkg, "Handler", filter)
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestWithoutTypeArguments(t *testing.T) {
+	if a, b := withoutTypeArguments("main.(*List[go.shape.int]).Push"), withoutTypeArguments("main.(*List[...]).Push"); a != b {
+		t.Errorf("Expected both spellings of a generic method to match, got %q and %q", a, b)
+	}
+}
+
+func TestCreateTypeBreakpointsResponse(t *testing.T) {
+	set := types.MethodBreakpoint{Function: "main.(*Handler).Serve"}
+	inlined := types.MethodBreakpoint{Function: "main.Handler.String", Inlined: true, InlinedSites: 2}
+	failed := types.MethodBreakpoint{Function: "main.(*Handler).Close", Error: "Breakpoint exists"}
+
+	testCases := []struct {
+		name            string
+		set             []types.MethodBreakpoint
+		failed          []types.MethodBreakpoint
+		expectedStatus  string
+		expectedSummary string
+	}{
+		{name: "All set", set: []types.MethodBreakpoint{set}, expectedStatus: "success", expectedSummary: "1 of 1 methods of main.Handler have a breakpoint"},
+		{name: "Some inlined", set: []types.MethodBreakpoint{set, inlined}, expectedStatus: "success", expectedSummary: "2 of 2 methods of main.Handler have a breakpoint, 1 of them at inlined call sites"},
+		{name: "Some failed", set: []types.MethodBreakpoint{set}, failed: []types.MethodBreakpoint{failed}, expectedStatus: "partial", expectedSummary: "1 of 2 methods of main.Handler have a breakpoint"},
+		{name: "All failed", failed: []types.MethodBreakpoint{failed}, expectedStatus: "error", expectedSummary: "0 of 1 methods of main.Handler have a breakpoint"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := NewClient().createTypeBreakpointsResponse(nil, "main.Handler", tc.set, tc.failed, nil)
+			if response.Status != tc.expectedStatus {
+				t.Errorf("Expected status %q, got %q", tc.expectedStatus, response.Status)
+			}
+			if response.Summary != tc.expectedSummary {
+				t.Errorf("Expected summary %q, got %q", tc.expectedSummary, response.Summary)
+			}
+			if len(response.Functions) != len(tc.set) {
+				t.Errorf("Expected %d functions, got %v", len(tc.set), response.Functions)
+			}
+		})
+	}
+}
+
+func TestSetBreakpointsOnType(t *testing.T) {
+	var created []string
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State": fakeState(stoppedState(10)),
+		"ListFunctions": fakeResult(rpc2.ListFunctionsOut{Funcs: []string{
+			"main.(*Handler).Serve",
+			"main.Handler.Name",
+			"main.(*Handler).Name",
+			"main.(*Handler).close",
+			"main.(*HandlerPool).Get",
+			"other.(*Handler).Serve",
+		}}),
+		"CreateBreakpoint": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.CreateBreakpointIn
+			decodeFakeArgs(t, raw, &args)
+			bp := args.Breakpoint
+			created = append(created, bp.FunctionName)
+			bp.ID = len(created)
+			switch bp.FunctionName {
+			case "main.(*Handler).close":
+				return nil, fmt.Errorf("could not find function %s", bp.FunctionName)
+			case "main.Handler.Name":
+				// Inlined into its two callers
+				bp.FunctionName, bp.Addrs = "main.main", []uint64{0x1000, 0x2000}
+			}
+			return rpc2.CreateBreakpointOut{Breakpoint: bp}, nil
+		},
+	})
+
+	response := c.SetBreakpointsOnType("*main.Handler", "")
+	if response.Status != "partial" {
+		t.Fatalf("Expected some methods to get a breakpoint, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+	// The pointer wrapper of the value method Name is left out
+	if !reflect.DeepEqual(created, []string{"main.(*Handler).Serve", "main.(*Handler).close", "main.Handler.Name"}) {
+		t.Errorf("Expected breakpoints on the methods of main.Handler only, got %v", created)
+	}
+	if !reflect.DeepEqual(response.Functions, []string{"main.(*Handler).Serve", "main.Handler.Name"}) {
+		t.Errorf("Expected Serve and Name to get a breakpoint, got %v", response.Functions)
+	}
+	if len(response.Failed) != 1 || response.Failed[0].Function != "main.(*Handler).close" || !strings.Contains(response.Failed[0].Error, "could not find function") {
+		t.Errorf("Expected close to fail, got %+v", response.Failed)
+	}
+	if name := response.Set[1]; !name.Inlined || name.InlinedSites != 2 || response.Set[0].Inlined {
+		t.Errorf("Expected only Name to be inlined, at 2 sites, got %+v", response.Set)
+	}
+	if response.Summary != "2 of 3 methods of *main.Handler have a breakpoint, 1 of them at inlined call sites" {
+		t.Errorf("Unexpected summary %q", response.Summary)
+	}
+
+	created = nil
+	response = c.SetBreakpointsOnType("main.Handler", "^Serve$")
+	if response.Status != "success" || !reflect.DeepEqual(created, []string{"main.(*Handler).Serve"}) {
+		t.Errorf("Expected the filter to keep Serve only, got %s with %v", response.Status, created)
+	}
+
+	response = c.SetBreakpointsOnType("main.Handler", "^Missing$")
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, `no methods matching "^Missing$"`) {
+		t.Errorf("Expected no methods to match, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+}
@@ -0,0 +1,236 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+const (
+	defaultVariableWrites = 10  // Past writes HistoryOfVariable collects when no limit is given
+	maxVariableWrites     = 100 // Most past writes HistoryOfVariable collects
+)
+
+// variableHistoryLoadConfig keeps each value in a variable's history to a summary
+var variableHistoryLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 1,
+	MaxStringLen:       256,
+	MaxArrayValues:     16,
+	MaxStructFields:    -1,
+}
+
+// variableHistory is what HistoryOfVariable collected
+type variableHistory struct {
+	current   *api.Variable
+	writes    []types.VariableWrite // Newest first, as collected
+	truncated bool                  // The limit was reached before the start of the recording
+	restored  bool                  // The replay is back where it was before collecting
+	notes     []string
+}
+
+// HistoryOfVariable collects the past writes to expr in a session replaying an rr
+// recording: it sets a write watchpoint on the variable and runs backward from the
+// current point, recording where each write happened and the value it left, until limit
+// writes are found or the start of the recording is reached. The replay then returns to
+// where it was. Other breakpoints hit on the way are run past. A variable on the stack
+// only lives as long as its function call, so writes from before the call are to memory
+// the variable later took over.
+func (c *Client) HistoryOfVariable(ctx context.Context, expr string, limit int) types.VariableHistoryResponse {
+	if err := c.requireRecording(); err != nil {
+		return c.createVariableHistoryResponse(nil, expr, nil, err)
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createVariableHistoryResponse(nil, expr, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createVariableHistoryResponse(nil, expr, nil, fmt.Errorf("cannot collect the history of a variable while the target is running; stop the target first"))
+	}
+	if state.SelectedGoroutine == nil {
+		return c.createVariableHistoryResponse(state, expr, nil, fmt.Errorf("no goroutine selected"))
+	}
+	limit = clampVariableWrites(limit)
+
+	scope := api.EvalScope{GoroutineID: state.SelectedGoroutine.ID}
+	current, err := c.client.EvalVariable(scope, expr, variableHistoryLoadConfig)
+	if err != nil {
+		if isUnresolvedSymbol(err) {
+			return c.createVariableHistoryResponse(state, expr, nil, fmt.Errorf("could not resolve %q: %v; variables in scope: %s", expr, err, c.scopeVariableNames(scope)))
+		}
+		return c.createVariableHistoryResponse(state, expr, nil, fmt.Errorf("failed to evaluate %q: %v", expr, err))
+	}
+
+	// Mark the current point to come back to it
+	checkpoint, err := c.client.Checkpoint("history of " + expr)
+	if err != nil {
+		return c.createVariableHistoryResponse(state, expr, nil, fmt.Errorf("failed to mark the current point of the replay to return to: %v", err))
+	}
+
+	logger.Debug("Collecting up to %d past writes to %s", limit, expr)
+	wp, err := c.client.CreateWatchpoint(scope, expr, api.WatchWrite)
+	if err != nil {
+		c.clearCheckpoint(checkpoint)
+		return c.createVariableHistoryResponse(state, expr, nil, fmt.Errorf("failed to set a write watchpoint on %s: %v", expr, err))
+	}
+
+	history := &variableHistory{current: current}
+	c.collectWrites(ctx, wp, current, limit, history)
+
+	if _, err := c.client.ClearBreakpoint(wp.ID); err != nil {
+		logger.Debug("Warning: Failed to clear watchpoint %d: %v", wp.ID, err)
+	}
+	if err := c.returnToCheckpoint(checkpoint); err != nil {
+		history.notes = append(history.notes, fmt.Sprintf("the replay could not return to where it was, it stays at the oldest write found: %v", err))
+	} else {
+		history.restored = true
+	}
+	c.clearCheckpoint(checkpoint)
+
+	state, err = c.client.GetState()
+	if err != nil {
+		logger.Debug("Warning: Failed to get state after collecting variable history: %v", err)
+	}
+	return c.createVariableHistoryResponse(state, expr, history, nil)
+}
+
+// collectWrites runs backward from write to write of a watchpoint, newest first
+func (c *Client) collectWrites(ctx context.Context, wp *api.Breakpoint, current *api.Variable, limit int, history *variableHistory) {
+	// The watched memory is read directly, as expr may mean something else where it was written
+	watched := fmt.Sprintf("*(*%s)(%#x)", current.Type, wp.Addr)
+
+	for {
+		state, err := c.interruptible(ctx, c.rewindOnce)
+		if err != nil {
+			if errors.Is(err, ErrInterrupted) {
+				history.notes = append(history.notes, fmt.Sprintf("collecting was %v; older writes may be missing", err))
+			} else {
+				history.notes = append(history.notes, fmt.Sprintf("running backward failed: %v", err))
+			}
+			return
+		}
+		if len(state.WatchOutOfScope) > 0 {
+			history.notes = append(history.notes, "the variable went out of scope; older writes are to memory it did not own yet")
+			return
+		}
+
+		th := state.CurrentThread
+		if th == nil || th.Breakpoint == nil {
+			// Nothing stops a backward run at the start of the recording
+			return
+		}
+		if th.Breakpoint.ID != wp.ID {
+			logger.Debug("Running backward past breakpoint %d", th.Breakpoint.ID)
+			continue
+		}
+
+		if len(history.writes) == limit {
+			history.truncated = true
+			return
+		}
+		history.writes = append(history.writes, c.variableWrite(th, watched))
+	}
+}
+
+// rewindOnce runs backward to the closest earlier breakpoint hit or the start of the recording
+func (c *Client) rewindOnce() (*api.DebuggerState, error) {
+	var last *api.DebuggerState
+	for state := range c.client.Rewind() {
+		last = state
+	}
+	if last == nil {
+		return nil, fmt.Errorf("reverse continue command failed: no state received")
+	}
+	if last.Err != nil {
+		return last, fmt.Errorf("reverse continue command failed: %v", last.Err)
+	}
+	return last, nil
+}
+
+// variableWrite describes the write a thread stopped at a write watchpoint for, reading
+// the value it left from the watched memory
+func (c *Client) variableWrite(th *api.Thread, watched string) types.VariableWrite {
+	write := types.VariableWrite{GoroutineID: th.GoroutineID}
+	if th.File != "" {
+		write.Position = &types.SourcePosition{File: th.File, Line: th.Line, Function: getFunctionName(th)}
+		write.Location = formatPosition(write.Position)
+	}
+
+	v, err := c.client.EvalVariable(api.EvalScope{GoroutineID: th.GoroutineID}, watched, variableHistoryLoadConfig)
+	if err != nil {
+		write.Error = fmt.Sprintf("failed to read the value: %v", err)
+		return write
+	}
+	value := convertVariableTree(v, "history", 0)
+	write.Value = &value
+	return write
+}
+
+// returnToCheckpoint moves the replay back to a checkpoint
+func (c *Client) returnToCheckpoint(checkpoint int) error {
+	_, err := c.client.RestartFrom(false, fmt.Sprintf("c%d", checkpoint), false, nil, [3]string{}, false)
+	return err
+}
+
+// clearCheckpoint removes a checkpoint made to return to
+func (c *Client) clearCheckpoint(checkpoint int) {
+	if err := c.client.ClearCheckpoint(checkpoint); err != nil {
+		logger.Debug("Warning: Failed to clear checkpoint %d: %v", checkpoint, err)
+	}
+}
+
+// clampVariableWrites bounds the number of past writes to collect, defaulting when unset
+func clampVariableWrites(limit int) int {
+	if limit <= 0 {
+		return defaultVariableWrites
+	}
+	return min(limit, maxVariableWrites)
+}
+
+// createVariableHistoryResponse creates a VariableHistoryResponse, with the writes oldest first
+func (c *Client) createVariableHistoryResponse(state *api.DebuggerState, expr string, history *variableHistory, err error) types.VariableHistoryResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "history_of_variable"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.VariableHistoryResponse{
+			Status:     "error",
+			Context:    context,
+			Expression: expr,
+		}
+	}
+
+	current := convertVariableTree(history.current, "history", 0)
+	response := types.VariableHistoryResponse{
+		Status:     "success",
+		Context:    context,
+		Expression: expr,
+		Current:    &current,
+		Writes:     make([]types.VariableWrite, 0, len(history.writes)),
+		Truncated:  history.truncated,
+		Restored:   history.restored,
+		Notes:      history.notes,
+	}
+	for i := len(history.writes) - 1; i >= 0; i-- {
+		response.Writes = append(response.Writes, history.writes[i])
+	}
+
+	switch {
+	case len(response.Writes) == 0:
+		response.Summary = fmt.Sprintf("%s was not written to earlier in the recording; it is %s", expr, current.Value)
+	case history.truncated:
+		response.Summary = fmt.Sprintf("the last %d writes to %s, oldest first; there are older ones", len(response.Writes), expr)
+	default:
+		response.Summary = fmt.Sprintf("%d writes to %s since the start of the recording, oldest first", len(response.Writes), expr)
+	}
+	if n := len(response.Writes); n > 0 && response.Writes[n-1].Location != nil {
+		response.Summary += "; last written " + strings.Replace(*response.Writes[n-1].Location, "At ", "at ", 1)
+	}
+	return response
+}
@@ -0,0 +1,185 @@
+package debugger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestClampVariableWrites(t *testing.T) {
+	testCases := []struct {
+		limit    int
+		expected int
+	}{
+		{limit: 0, expected: defaultVariableWrites},
+		{limit: -1, expected: defaultVariableWrites},
+		{limit: 3, expected: 3},
+		{limit: maxVariableWrites + 1, expected: maxVariableWrites},
+	}
+
+	for _, tc := range testCases {
+		if result := clampVariableWrites(tc.limit); result != tc.expected {
+			t.Errorf("Expected %d for limit %d, got %d", tc.expected, tc.limit, result)
+		}
+	}
+}
+
+func TestCreateVariableHistoryResponse(t *testing.T) {
+	current := &api.Variable{Name: "requestCount", Type: "int", Kind: reflect.Int, Value: "3"}
+	write := func(value string, line int) types.VariableWrite {
+		position := &types.SourcePosition{File: "/tmp/main.go", Line: line, Function: "main.handle"}
+		return types.VariableWrite{
+			GoroutineID: 1,
+			Position:    position,
+			Location:    formatPosition(position),
+			Value:       &types.Variable{Value: value},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		history         *variableHistory
+		expectedValues  []string
+		expectedSummary string
+	}{
+		{
+			name:            "Never written",
+			history:         &variableHistory{current: current, restored: true},
+			expectedSummary: "requestCount was not written to earlier in the recording; it is 3",
+		},
+		{
+			name:            "From the start of the recording",
+			history:         &variableHistory{current: current, writes: []types.VariableWrite{write("3", 22), write("2", 22), write("1", 20)}, restored: true},
+			expectedValues:  []string{"1", "2", "3"},
+			expectedSummary: "3 writes to requestCount since the start of the recording, oldest first; last written at /tmp/main.go:22 in main.handle",
+		},
+		{
+			name:            "Limit reached",
+			history:         &variableHistory{current: current, writes: []types.VariableWrite{write("3", 22)}, truncated: true},
+			expectedValues:  []string{"3"},
+			expectedSummary: "the last 1 writes to requestCount, oldest first; there are older ones; last written at /tmp/main.go:22 in main.handle",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := NewClient().createVariableHistoryResponse(nil, "requestCount", tc.history, nil)
+			if response.Status != "success" {
+				t.Fatalf("Expected status success, got %q", response.Status)
+			}
+			var values []string
+			for _, w := range response.Writes {
+				values = append(values, w.Value.Value)
+			}
+			if !reflect.DeepEqual(values, tc.expectedValues) {
+				t.Errorf("Expected writes %v, got %v", tc.expectedValues, values)
+			}
+			if response.Summary != tc.expectedSummary {
+				t.Errorf("Expected summary %q, got %q", tc.expectedSummary, response.Summary)
+			}
+			if response.Restored != tc.history.restored || response.Truncated != tc.history.truncated {
+				t.Errorf("Expected restored %v and truncated %v, got %v and %v", tc.history.restored, tc.history.truncated, response.Restored, response.Truncated)
+			}
+		})
+	}
+}
+
+// replayedTarget returns a fake Delve replaying a recording in which count, now 3, was
+// written at main.go:10, leaving 1, and at main.go:20, leaving 3, with breakpoint 1 hit at
+// main.go:15 in between. Running backward stops at each of them in turn, newest first.
+// The names of the Delve calls that move the replay are recorded.
+func replayedTarget(t *testing.T) (*Client, *[]string) {
+	t.Helper()
+	const watchpointID = 5
+	at := func(line int, id int) api.DebuggerState {
+		return api.DebuggerState{
+			CurrentThread:     &api.Thread{ID: 1, File: "main.go", Line: line, Function: &api.Function{Name_: "main.main"}, GoroutineID: 1, Breakpoint: &api.Breakpoint{ID: id}},
+			SelectedGoroutine: &api.Goroutine{ID: 1},
+		}
+	}
+	stops := []api.DebuggerState{at(20, watchpointID), at(15, 1), at(10, watchpointID), {SelectedGoroutine: &api.Goroutine{ID: 1}}}
+	values := []string{"3", "3", "1", "1"}
+	rewinds := 0
+	intValue := func(value string) *api.Variable {
+		return &api.Variable{Name: "count", Type: "int", Kind: reflect.Int, Value: value}
+	}
+
+	var commands []string
+	c, _ := newFakeDelve(t, map[string]fakeHandler{
+		"State":    fakeState(stoppedState(30)),
+		"Recorded": fakeResult(rpc2.RecordedOut{Recorded: true}),
+		"Eval": func(raw json.RawMessage) (interface{}, error) {
+			var args rpc2.EvalIn
+			decodeFakeArgs(t, raw, &args)
+			switch args.Expr {
+			case "count":
+				return rpc2.EvalOut{Variable: intValue("3")}, nil
+			case "*(*int)(0xc000010000)":
+				return rpc2.EvalOut{Variable: intValue(values[rewinds-1])}, nil
+			}
+			return nil, fmt.Errorf("could not find symbol value for %s", args.Expr)
+		},
+		"Checkpoint":       fakeResult(rpc2.CheckpointOut{ID: 1}),
+		"CreateWatchpoint": fakeResult(rpc2.CreateWatchpointOut{Breakpoint: &api.Breakpoint{ID: watchpointID, Addr: 0xc000010000, WatchExpr: "count"}}),
+		"ClearBreakpoint":  fakeResult(rpc2.ClearBreakpointOut{Breakpoint: &api.Breakpoint{ID: watchpointID}}),
+		"Restart": func(json.RawMessage) (interface{}, error) {
+			commands = append(commands, "restart")
+			return rpc2.RestartOut{}, nil
+		},
+		"ClearCheckpoint": fakeResult(rpc2.ClearCheckpointOut{}),
+		"Command": fakeCommands(t, &commands, func(api.DebuggerCommand) api.DebuggerState {
+			rewinds++
+			return stops[rewinds-1]
+		}),
+	})
+	return c, &commands
+}
+
+func TestHistoryOfVariable(t *testing.T) {
+	c, commands := replayedTarget(t)
+
+	response := c.HistoryOfVariable(context.Background(), "count", 0)
+	if response.Status != "success" || !response.Restored || response.Truncated {
+		t.Fatalf("Expected the whole history, with the replay back where it was, got %+v", response)
+	}
+	var writes []string
+	for _, write := range response.Writes {
+		writes = append(writes, fmt.Sprintf("%d=%s", write.Position.Line, write.Value.Value))
+	}
+	if !reflect.DeepEqual(writes, []string{"10=1", "20=3"}) {
+		t.Errorf("Expected the writes at main.go:10 and main.go:20, oldest first, got %v", writes)
+	}
+	if !reflect.DeepEqual(*commands, []string{api.Rewind, api.Rewind, api.Rewind, api.Rewind, "restart"}) {
+		t.Errorf("Expected to run backward past breakpoint 1 to the start, then return, got %v", *commands)
+	}
+	if !strings.HasPrefix(response.Summary, "2 writes to count since the start of the recording") {
+		t.Errorf("Unexpected summary %q", response.Summary)
+	}
+
+	c, commands = replayedTarget(t)
+	response = c.HistoryOfVariable(context.Background(), "count", 1)
+	if response.Status != "success" || !response.Truncated || len(response.Writes) != 1 || response.Writes[0].Position.Line != 20 {
+		t.Errorf("Expected only the last write, at main.go:20, got %+v", response)
+	}
+	if n := len(*commands); n == 0 || (*commands)[n-1] != "restart" {
+		t.Errorf("Expected the replay to return after a limited history, got %v", *commands)
+	}
+}
+
+func TestHistoryOfVariableNeedsRecording(t *testing.T) {
+	c, _, commands := recordedTarget(t, false)
+	response := c.HistoryOfVariable(context.Background(), "count", 0)
+	if response.Status != "error" || response.Context.Operation != "history_of_variable" || !strings.Contains(response.Context.ErrorMessage, "needs the rr backend") {
+		t.Errorf("Expected history_of_variable to need a recording, got %q", response.Context.ErrorMessage)
+	}
+	if len(*commands) != 0 {
+		t.Errorf("Expected nothing run without a recording, got %v", *commands)
+	}
+}
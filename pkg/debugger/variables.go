@@ -3,18 +3,38 @@ package debugger
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
 	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
-// EvalVariable evaluates a variable expression
-func (c *Client) EvalVariable(name string, depth int) types.EvalVariableResponse {
+// Limits EvalVariable loads values with unless the caller asks for others
+const (
+	defaultEvalMaxElements  = 100  // Slice, array and map elements loaded
+	defaultEvalMaxStringLen = 1024 // Bytes of string loaded
+)
+
+// EvalVariable evaluates a variable expression, loading depth levels of nested values, at
+// most maxVariableDepth. maxElements caps the slice, array and map elements loaded and
+// maxStringLen the bytes of strings loaded; 0 or less uses the defaults. Values cut short by either limit are marked with how much was not shown.
+// With callStringers, a value whose type has a String or Error method is rendered with it
+// too, by calling it in the target. followPointers overrides the session's
+// SetFollowPointers setting when not nil. Integers are rendered in base 2, 8, 10 or 16, 0
+// meaning 10.
+func (c *Client) EvalVariable(name string, depth, maxElements, maxStringLen int, callStringers bool, followPointers *bool, base int) types.EvalVariableResponse {
 	if c.client == nil {
 		return c.createEvalVariableResponse(nil, nil, 0, fmt.Errorf("no active debug session"))
 	}
 
+	base, err := checkIntegerBase(base)
+	if err != nil {
+		return c.createEvalVariableResponse(nil, nil, 0, err)
+	}
+
 	// Get current state for context
 	state, err := c.client.GetState()
 	if err != nil {
@@ -31,12 +51,20 @@ func (c *Client) EvalVariable(name string, depth int) types.EvalVariableResponse
 		Frame:       0,
 	}
 
+	depth = clampLoadDepth(depth)
+	if maxElements <= 0 {
+		maxElements = defaultEvalMaxElements
+	}
+	if maxStringLen <= 0 {
+		maxStringLen = defaultEvalMaxStringLen
+	}
+
 	// Configure loading with proper struct field handling
 	loadConfig := api.LoadConfig{
-		FollowPointers:     true,
+		FollowPointers:     c.followPointers(followPointers),
 		MaxVariableRecurse: depth,
-		MaxStringLen:       1024,
-		MaxArrayValues:     100,
+		MaxStringLen:       maxStringLen,
+		MaxArrayValues:     maxElements,
 		MaxStructFields:    -1, // Load all struct fields
 	}
 
@@ -45,6 +73,7 @@ func (c *Client) EvalVariable(name string, depth int) types.EvalVariableResponse
 	if err != nil {
 		return c.createEvalVariableResponse(state, nil, 0, fmt.Errorf("failed to evaluate variable %s: %v", name, err))
 	}
+	rebaseIntegers(v, base)
 
 	// Convert to our type
 	variable := &types.Variable{
@@ -52,36 +81,207 @@ func (c *Client) EvalVariable(name string, depth int) types.EvalVariableResponse
 		Name:     v.Name,
 		Type:     v.Type,
 		Kind:     getVariableKind(v),
+		Value:    formatVariableValue(v),
+	}
+
+	var stringerNote string
+	if callStringers {
+		stringerNote = c.callStringers(state, 0, []stringerTarget{{expr: name, variable: variable}}, maxStringLen)
+	}
+
+	response := c.createEvalVariableResponse(state, variable, depth, nil)
+	response.StringerNote = stringerNote
+	return response
+}
+
+// SetVariable assigns a new value to a variable in the given frame of the selected goroutine
+func (c *Client) SetVariable(name string, value string, frame int) types.SetVariableResponse {
+	if c.client == nil {
+		return c.createSetVariableResponse(nil, nil, "", fmt.Errorf("no active debug session"))
+	}
+
+	// Use the non-blocking call so a running target is reported instead of waited on
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createSetVariableResponse(nil, nil, "", fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createSetVariableResponse(nil, nil, "", fmt.Errorf("cannot set variable %s while the target is running; stop the target first", name))
+	}
+
+	if state.SelectedGoroutine == nil {
+		return c.createSetVariableResponse(state, nil, "", fmt.Errorf("no goroutine selected"))
+	}
+
+	scope := api.EvalScope{
+		GoroutineID: state.SelectedGoroutine.ID,
+		Frame:       frame,
+	}
+
+	loadConfig := api.LoadConfig{
+		FollowPointers:     true,
+		MaxVariableRecurse: 1,
+		MaxStringLen:       1024,
+		MaxArrayValues:     100,
+		MaxStructFields:    -1,
+	}
+
+	// Evaluate first so we know the variable exists and what its type is
+	before, err := c.client.EvalVariable(scope, name, loadConfig)
+	if err != nil {
+		return c.createSetVariableResponse(state, nil, "", fmt.Errorf("failed to evaluate variable %s: %v", name, err))
+	}
+	previousValue := formatVariableValue(before)
+
+	logger.Debug("Setting variable %s = %s in frame %d", name, value, frame)
+	if err := c.client.SetVariable(scope, name, value); err != nil {
+		if isTypeMismatchError(err) {
+			return c.createSetVariableResponse(state, nil, previousValue, fmt.Errorf("cannot assign %s to %s: value is not compatible with type %s", value, name, before.Type))
+		}
+		return c.createSetVariableResponse(state, nil, previousValue, fmt.Errorf("failed to set variable %s: %v", name, err))
+	}
+
+	// Re-evaluate so the caller can confirm the write took effect
+	after, err := c.client.EvalVariable(scope, name, loadConfig)
+	if err != nil {
+		return c.createSetVariableResponse(state, nil, previousValue, fmt.Errorf("variable %s was set but could not be re-evaluated: %v", name, err))
+	}
+
+	variable := &types.Variable{
+		DelveVar: after,
+		Name:     after.Name,
+		Type:     after.Type,
+		Kind:     getVariableKind(after),
+		Value:    formatVariableValue(after),
 	}
 
-	// Format the value based on the variable kind
+	return c.createSetVariableResponse(state, variable, previousValue, nil)
+}
+
+// isTypeMismatchError reports whether a Delve error was caused by assigning an incompatible value
+func isTypeMismatchError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "can not convert") || strings.Contains(msg, "mismatched types")
+}
+
+// formatVariableValue renders a Delve variable as a human-readable string. Slices,
+// arrays, maps and strings that were only partly loaded end in "(N more not shown)".
+func formatVariableValue(v *api.Variable) string {
 	switch v.Kind {
 	case reflect.Struct:
 		if len(v.Children) > 0 {
 			fields := make([]string, 0, len(v.Children))
-			for _, field := range v.Children {
-				fieldStr := fmt.Sprintf("%s:%s", field.Name, field.Value)
+			for i := range v.Children {
+				field := &v.Children[i]
+				value := field.Value
+				if field.Kind == reflect.Ptr {
+					value = formatVariableValue(field)
+				}
+				fieldStr := fmt.Sprintf("%s:%s", field.Name, value)
 				fields = append(fields, fieldStr)
 			}
-			variable.Value = "{" + strings.Join(fields, ", ") + "}"
-		} else {
-			variable.Value = "{}"
+			return "{" + strings.Join(fields, ", ") + "}"
 		}
+		return "{}"
 	case reflect.Array, reflect.Slice:
+		s := "[]"
 		if len(v.Children) > 0 {
 			elements := make([]string, 0, len(v.Children))
-			for _, element := range v.Children {
-				elements = append(elements, element.Value)
+			for i := range v.Children {
+				elements = append(elements, formatElementValue(&v.Children[i]))
 			}
-			variable.Value = "[" + strings.Join(elements, ", ") + "]"
-		} else {
-			variable.Value = "[]"
+			s = "[" + strings.Join(elements, ", ") + "]"
 		}
+		return s + notShownSuffix(v.Len, int64(len(v.Children)))
+	case reflect.Map:
+		entries := sortedMapEntries(v)
+		pairs := make([]string, 0, len(entries))
+		for _, e := range entries {
+			pairs = append(pairs, fmt.Sprintf("%s:%s", e.key.Value, formatElementValue(e.value)))
+		}
+		return "map[" + strings.Join(pairs, ", ") + "]" + notShownSuffix(v.Len, int64(len(entries)))
+	case reflect.String:
+		return v.Value + notShownSuffix(v.Len, int64(len(v.Value)))
+	case reflect.Ptr:
+		if len(v.Children) > 0 && v.Children[0].OnlyAddr {
+			return formatPointerAddress(v)
+		}
+		return v.Value
 	default:
-		variable.Value = v.Value
+		return v.Value
+	}
+}
+
+// formatPointerAddress renders a pointer whose target was not loaded as its type and
+// address, as Delve does, or as nil
+func formatPointerAddress(v *api.Variable) string {
+	if v.Children[0].Addr == 0 {
+		return "nil"
+	}
+	return fmt.Sprintf("(%s)(%#x)", v.Type, v.Children[0].Addr)
+}
+
+// formatElementValue renders an element of a slice, array or map. Nested slices, maps
+// and strings are rendered in full, and pointers loaded as an address only as one; structs
+// and other values keep Delve's summary.
+func formatElementValue(v *api.Variable) string {
+	switch v.Kind {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.String, reflect.Ptr:
+		return formatVariableValue(v)
+	default:
+		return v.Value
+	}
+}
+
+// notShownSuffix marks a value of which only loaded of total elements were loaded
+func notShownSuffix(total, loaded int64) string {
+	if loaded >= total {
+		return ""
+	}
+	return fmt.Sprintf(" (%d more not shown)", total-loaded)
+}
+
+// mapEntry is a key and value of a loaded map
+type mapEntry struct {
+	key, value *api.Variable
+}
+
+// sortedMapEntries returns the loaded entries of a map sorted by key, since Delve
+// returns them in the target's iteration order. Numeric keys sort by value, others by
+// their rendering.
+func sortedMapEntries(v *api.Variable) []mapEntry {
+	// Map children alternate between keys and values
+	entries := make([]mapEntry, 0, len(v.Children)/2)
+	for i := 0; i+1 < len(v.Children); i += 2 {
+		entries = append(entries, mapEntry{key: &v.Children[i], value: &v.Children[i+1]})
 	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return mapKeyLess(entries[i].key, entries[j].key)
+	})
+	return entries
+}
 
-	return c.createEvalVariableResponse(state, variable, depth, nil)
+// mapKeyLess orders two map keys, numerically when both are numbers
+func mapKeyLess(a, b *api.Variable) bool {
+	x, errX := strconv.ParseFloat(a.Value, 64)
+	y, errY := strconv.ParseFloat(b.Value, 64)
+	if errX == nil && errY == nil && isNumericKind(a.Kind) && isNumericKind(b.Kind) && x != y {
+		return x < y
+	}
+	return a.Value < b.Value
+}
+
+// isNumericKind reports whether a kind holds an integer or floating point number
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
 }
 
 // Helper functions for variable information
@@ -223,5 +423,27 @@ func (c *Client) createEvalVariableResponse(state *api.DebuggerState, variable *
 		Status:   "success",
 		Context:  context,
 		Variable: *variable,
+		MaxDepth: depth,
+	}
+}
+
+// createSetVariableResponse creates a SetVariableResponse
+func (c *Client) createSetVariableResponse(state *api.DebuggerState, variable *types.Variable, previousValue string, err error) types.SetVariableResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "set_variable"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.SetVariableResponse{
+			Status:        "error",
+			Context:       context,
+			PreviousValue: previousValue,
+		}
+	}
+
+	return types.SetVariableResponse{
+		Status:        "success",
+		Context:       context,
+		Variable:      *variable,
+		PreviousValue: previousValue,
 	}
 }
@@ -0,0 +1,85 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestFormatVariableValue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		variable *api.Variable
+		expected string
+	}{
+		{
+			name: "Slice",
+			variable: &api.Variable{Kind: reflect.Slice, Len: 2, Children: []api.Variable{
+				{Kind: reflect.String, Value: "a", Len: 1},
+				{Kind: reflect.String, Value: "b", Len: 1},
+			}},
+			expected: "[a, b]",
+		},
+		{
+			name: "Truncated slice",
+			variable: &api.Variable{Kind: reflect.Slice, Len: 100, Children: []api.Variable{
+				{Kind: reflect.Int, Value: "1"},
+				{Kind: reflect.Int, Value: "2"},
+				{Kind: reflect.Int, Value: "3"},
+			}},
+			expected: "[1, 2, 3] (97 more not shown)",
+		},
+		{
+			name: "Map with sorted string keys",
+			variable: &api.Variable{Kind: reflect.Map, Len: 3, Children: []api.Variable{
+				{Kind: reflect.String, Value: "b", Len: 1}, {Kind: reflect.Int, Value: "2"},
+				{Kind: reflect.String, Value: "c", Len: 1}, {Kind: reflect.Int, Value: "3"},
+				{Kind: reflect.String, Value: "a", Len: 1}, {Kind: reflect.Int, Value: "1"},
+			}},
+			expected: "map[a:1, b:2, c:3]",
+		},
+		{
+			name: "Map with numeric keys",
+			variable: &api.Variable{Kind: reflect.Map, Len: 3, Children: []api.Variable{
+				{Kind: reflect.Int, Value: "10"}, {Kind: reflect.String, Value: "ten", Len: 3},
+				{Kind: reflect.Int, Value: "9"}, {Kind: reflect.String, Value: "nine", Len: 4},
+				{Kind: reflect.Int, Value: "-1"}, {Kind: reflect.String, Value: "minus one", Len: 9},
+			}},
+			expected: "map[-1:minus one, 9:nine, 10:ten]",
+		},
+		{
+			name: "Truncated map",
+			variable: &api.Variable{Kind: reflect.Map, Len: 5, Children: []api.Variable{
+				{Kind: reflect.String, Value: "x", Len: 1}, {Kind: reflect.Bool, Value: "true"},
+			}},
+			expected: "map[x:true] (4 more not shown)",
+		},
+		{
+			name:     "Empty map",
+			variable: &api.Variable{Kind: reflect.Map},
+			expected: "map[]",
+		},
+		{
+			name:     "Truncated string",
+			variable: &api.Variable{Kind: reflect.String, Value: "abc", Len: 10},
+			expected: "abc (7 more not shown)",
+		},
+		{
+			name: "Nested slices",
+			variable: &api.Variable{Kind: reflect.Slice, Len: 1, Children: []api.Variable{
+				{Kind: reflect.Slice, Len: 3, Children: []api.Variable{{Kind: reflect.Int, Value: "1"}}},
+			}},
+			expected: "[[1] (2 more not shown)]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := formatVariableValue(tc.variable)
+			if result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// whatIsLoadConfig loads just enough of a value to resolve its type: interfaces get their
+// dynamic value's header, but no strings, elements or fields are read
+var whatIsLoadConfig = api.LoadConfig{
+	FollowPointers:     false,
+	MaxVariableRecurse: 1,
+	MaxStringLen:       0,
+	MaxArrayValues:     0,
+	MaxStructFields:    0,
+}
+
+// WhatIs resolves the type of an expression in the given frame without loading its value.
+// For interfaces it also reports the concrete type of the value they hold.
+func (c *Client) WhatIs(expr string, frame int) types.WhatIsResponse {
+	if c.client == nil {
+		return c.createWhatIsResponse(nil, expr, nil, fmt.Errorf("no active debug session"))
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createWhatIsResponse(nil, expr, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	if state.Running {
+		return c.createWhatIsResponse(nil, expr, nil, fmt.Errorf("cannot resolve types while the target is running; stop the target first"))
+	}
+
+	if state.SelectedGoroutine == nil {
+		return c.createWhatIsResponse(state, expr, nil, fmt.Errorf("no goroutine selected"))
+	}
+
+	scope := api.EvalScope{
+		GoroutineID: state.SelectedGoroutine.ID,
+		Frame:       frame,
+	}
+
+	logger.Debug("Resolving type of %q in frame %d", expr, frame)
+	v, err := c.client.EvalVariable(scope, expr, whatIsLoadConfig)
+	if err != nil {
+		if isUnresolvedSymbol(err) {
+			return c.createWhatIsResponse(state, expr, nil, fmt.Errorf("could not resolve %q: %v; variables in scope: %s", expr, err, c.scopeVariableNames(scope)))
+		}
+		return c.createWhatIsResponse(state, expr, nil, fmt.Errorf("failed to resolve type of %q: %v", expr, err))
+	}
+	if v == nil {
+		return c.createWhatIsResponse(state, expr, nil, fmt.Errorf("expression %q produced no value", expr))
+	}
+
+	return c.createWhatIsResponse(state, expr, v, nil)
+}
+
+// isUnresolvedSymbol reports whether an evaluation failed because a name is not in scope
+func isUnresolvedSymbol(err error) bool {
+	return strings.Contains(err.Error(), "could not find symbol value for")
+}
+
+// scopeVariableNames lists the names of the arguments and locals visible in a scope
+func (c *Client) scopeVariableNames(scope api.EvalScope) string {
+	var names []string
+	if args, err := c.client.ListFunctionArgs(scope, whatIsLoadConfig); err == nil {
+		for _, v := range args {
+			names = append(names, v.Name)
+		}
+	}
+	if locals, err := c.client.ListLocalVariables(scope, whatIsLoadConfig); err == nil {
+		for _, v := range locals {
+			names = append(names, v.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// createWhatIsResponse creates a WhatIsResponse
+func (c *Client) createWhatIsResponse(state *api.DebuggerState, expr string, v *api.Variable, err error) types.WhatIsResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "whatis"
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		return types.WhatIsResponse{
+			Status:     "error",
+			Context:    context,
+			Expression: expr,
+		}
+	}
+
+	response := types.WhatIsResponse{
+		Status:         "success",
+		Context:        context,
+		Expression:     expr,
+		Type:           v.Type,
+		UnderlyingType: v.RealType,
+		Kind:           v.Kind.String(),
+	}
+
+	if v.Kind == reflect.Interface {
+		response.IsInterface = true
+		if len(v.Children) > 0 && v.Children[0].Kind != reflect.Invalid {
+			concrete := v.Children[0]
+			response.ConcreteType = concrete.Type
+			response.ConcreteKind = concrete.Kind.String()
+		} else {
+			response.IsNil = true
+		}
+	}
+
+	return response
+}
@@ -3,25 +3,38 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/sunfmin/mcp-go-debugger/pkg/debugger"
 	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
 type MCPDebugServer struct {
-	server      *server.MCPServer
-	debugClient *debugger.Client
-	version     string
+	server       *server.MCPServer
+	sessions     *debugger.SessionManager
+	version      string
+	outputFormat string // How tool results report locations and stop reasons
+
+	maxResponseBytes int // Size tool results are truncated to, 0 for no limit
 }
 
 func NewMCPDebugServer(version string) *MCPDebugServer {
 	s := &MCPDebugServer{
-		server:      server.NewMCPServer("Go Debugger MCP", version),
-		debugClient: debugger.NewClient(),
-		version:     version,
+		server:       server.NewMCPServer("Go Debugger MCP", version),
+		sessions:     debugger.NewSessionManager(),
+		version:      version,
+		outputFormat: outputFormatBoth,
+
+		maxResponseBytes: getMaxResponseBytes(),
 	}
 
 	s.registerTools()
@@ -33,25 +46,384 @@ func (s *MCPDebugServer) Server() *server.MCPServer {
 	return s.server
 }
 
+// DebugClient returns the client of the default session
 func (s *MCPDebugServer) DebugClient() *debugger.Client {
-	return s.debugClient
+	client, _ := s.sessions.Get(debugger.DefaultSessionID)
+	return client
+}
+
+// sessionContextKey is the context key under which addTool stores the session of a call
+type sessionContextKey struct{}
+
+// toolSession is the debug session a tool call runs against
+type toolSession struct {
+	id     string
+	client *debugger.Client
+}
+
+// session returns the session of a tool call. Handlers called without going through
+// addTool, as in tests, use the default session.
+func (s *MCPDebugServer) session(ctx context.Context) toolSession {
+	if ts, ok := ctx.Value(sessionContextKey{}).(toolSession); ok {
+		return ts
+	}
+	return toolSession{id: debugger.DefaultSessionID, client: s.DebugClient()}
+}
+
+// client returns the debug client of a tool call's session
+func (s *MCPDebugServer) client(ctx context.Context) *debugger.Client {
+	return s.session(ctx).client
+}
+
+// addTool registers a tool that runs against a debug session, chosen with the optional
+// sessionID argument. The handler is not run against a remote session that has lost its connection.
+func (s *MCPDebugServer) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	mcp.WithString("sessionID",
+		mcp.Description("Debug session to use, as created by create_session (default: the default session)"),
+	)(&tool)
+	mcp.WithNumber("maxResponseBytes",
+		mcp.Description("Largest result to return for this call, in bytes; 0 for no limit (default: as set with set_response_limit)"),
+	)(&tool)
+	handler = s.withResponseLimit(handler)
+
+	s.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var id string
+		if idVal, ok := request.Params.Arguments["sessionID"]; ok && idVal != nil {
+			id = idVal.(string)
+		}
+		if id == "" {
+			id = debugger.DefaultSessionID
+		}
+
+		client, err := s.sessions.Get(id)
+		if err != nil {
+			return newErrorResult("%v", err), nil
+		}
+		ts := toolSession{id: id, client: client}
+
+		// A dropped remote connection is re-established before the call, so the caller only
+		// sees a note that it happened
+		var reconnect *types.ReconnectResponse
+		if client.ConnectionLost() {
+			response := client.Reconnect()
+			if response.Status != "success" {
+				return s.remoteConnectionLostResult(ts, response), nil
+			}
+			reconnect = &response
+		}
+
+		if client.ReadOnly() && executionTools[tool.Name] {
+			return newErrorResult("%s is not available in a read-only core session: a core dump has no live process to run or change; close the session to debug a live program", tool.Name), nil
+		}
+
+		if exited, _ := client.Exited(); exited && !postExitTools[tool.Name] {
+			return newErrorResult("%s needs a live process: %v", tool.Name, client.ExitedError()), nil
+		}
+
+		if client.Running() && !runningTools[tool.Name] {
+			return newErrorResult("%s: target is running; use wait_for_stop or halt", tool.Name), nil
+		}
+
+		result, err := handler(context.WithValue(ctx, sessionContextKey{}, ts), request)
+		if client.ConnectionLost() {
+			// The call itself failed, or its result was lost with the connection
+			response := client.Reconnect()
+			if response.Status != "success" {
+				return s.remoteConnectionLostResult(ts, response), nil
+			}
+			response.Message += fmt.Sprintf("; the connection dropped during %s, which may not have completed, so check the state and run it again", tool.Name)
+			return s.withReconnectNote(result, response), err
+		}
+		if client.RemoteAddress() != "" {
+			client.SnapshotBreakpoints()
+		}
+		if reconnect != nil {
+			return s.withReconnectNote(result, *reconnect), err
+		}
+		return result, err
+	})
+}
+
+// withReconnectNote adds a reconnect report ahead of a tool's result
+func (s *MCPDebugServer) withReconnectNote(result *mcp.CallToolResult, reconnect types.ReconnectResponse) *mcp.CallToolResult {
+	note, _ := s.newToolResultJSON(reconnect)
+	if result == nil {
+		return note
+	}
+	result.Content = append(note.Content, result.Content...)
+	return result
+}
+
+// postExitTools are the tools that don't need a live process, which stay available once
+// the target has exited. The others are rejected until the session is restarted.
+var postExitTools = map[string]bool{
+	"create_session":      true,
+	"list_sessions":       true,
+	"close_session":       true,
+	"launch":              true,
+	"attach":              true,
+	"open_core":           true,
+	"connect_remote":      true,
+	"debug":               true,
+	"launch_test":         true,
+	"debug_test":          true,
+	"launch_recording":    true,
+	"close":               true,
+	"detach":              true,
+	"restart":             true,
+	"set_launch_args":     true,
+	"set_launch_env":      true,
+	"list_breakpoints":    true,
+	"remove_breakpoint":   true,
+	"export_breakpoints":  true,
+	"read_trace":          true,
+	"diff_capture":        true,
+	"inter_hit_timings":   true,
+	"wait_for_stop":       true,
+	"get_debugger_output": true,
+	"read_output":         true,
+	"list_source":         true,
+	"build_info":          true,
+	"list_snapshots":      true,
+	"clear_snapshots":     true,
+	"set_output_format":   true,
+	"set_response_limit":  true,
+	"set_follow_pointers": true,
+}
+
+// runningTools are the tools that don't wait for the target to stop, which stay available
+// while continue_async runs it. The others would block the server until it stops.
+var runningTools = map[string]bool{
+	"create_session":      true,
+	"list_sessions":       true,
+	"close_session":       true,
+	"status":              true,
+	"close":               true,
+	"detach":              true,
+	"wait_for_stop":       true,
+	"halt":                true,
+	"get_debugger_output": true,
+	"read_output":         true,
+	"write_stdin":         true,
+	"build_info":          true,
+	"list_snapshots":      true,
+	"set_output_format":   true,
+	"set_response_limit":  true,
+	"set_follow_pointers": true,
+}
+
+// executionTools are the tools that run, step or change the target, which a read-only core
+// session rejects
+var executionTools = map[string]bool{
+	"continue":                   true,
+	"halt":                       true,
+	"continue_async":             true,
+	"continue_to_line":           true,
+	"run_until_returns":          true,
+	"run_to_completion":          true,
+	"line_coverage":              true,
+	"watch_goroutine_count":      true,
+	"step":                       true,
+	"step_over":                  true,
+	"step_out":                   true,
+	"step_isolated":              true,
+	"reverse_step":               true,
+	"reverse_next":               true,
+	"reverse_continue":           true,
+	"history_of_variable":        true,
+	"step_instruction":           true,
+	"step_to_next_call":          true,
+	"restart":                    true,
+	"set_breakpoint":             true,
+	"set_breakpoints":            true,
+	"set_type_breakpoints":       true,
+	"break_on_error":             true,
+	"import_breakpoints":         true,
+	"reset_hit_count":            true,
+	"toggle_breakpoint":          true,
+	"set_ignore_count":           true,
+	"amend_breakpoint_condition": true,
+	"arm_breakpoint_after":       true,
+	"set_watchpoint":             true,
+	"set_tracepoint":             true,
+	"break_on_panic":             true,
+	"set_variable":               true,
+	"call_function":              true,
+	"set_register":               true,
+	"set_next_statement":         true,
+}
+
+// addServerTool registers a tool that acts on the server itself rather than on a debug session
+func (s *MCPDebugServer) addServerTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	s.server.AddTool(tool, s.withResponseLimit(handler))
+}
+
+// remoteConnectionLostResult drops a remote session that could not reconnect and reports it
+func (s *MCPDebugServer) remoteConnectionLostResult(ts toolSession, reconnect types.ReconnectResponse) *mcp.CallToolResult {
+	addr := ts.client.RemoteAddress()
+	logger.Error("Lost connection to remote dlv", "address", addr, "session", ts.id, "attempts", reconnect.Attempts)
+	s.sessions.Reset(ts.id)
+	return newErrorResult("%s; the session is no longer valid, connect again with connect_remote", reconnect.Message)
 }
 
 func (s *MCPDebugServer) registerTools() {
+	s.addCreateSessionTool()
+	s.addListSessionsTool()
+	s.addStatusTool()
+	s.addCloseSessionTool()
+	s.addShutdownTool()
 	s.addDebugSourceFileTool()
 	s.addDebugTestTool()
+	s.addLaunchTestTool()
 	s.addLaunchTool()
 	s.addAttachTool()
+	s.addConnectRemoteTool()
+	s.addOpenCoreTool()
 	s.addCloseTool()
+	s.addDetachTool()
+	s.addRestartTool()
+	s.addSetLaunchArgsTool()
+	s.addSetLaunchEnvTool()
 	s.addSetBreakpointTool()
 	s.addListBreakpointsTool()
 	s.addRemoveBreakpointTool()
+	s.addSetBreakpointsTool()
+	s.addSetTypeBreakpointsTool()
+	s.addBreakOnErrorTool()
+	s.addExportBreakpointsTool()
+	s.addImportBreakpointsTool()
+	s.addResetHitCountTool()
+	s.addToggleBreakpointTool()
+	s.addSetIgnoreCountTool()
+	s.addAmendBreakpointConditionTool()
+	s.addArmBreakpointAfterTool()
+	s.addSetWatchpointTool()
+	s.addSetTracepointTool()
+	s.addReadTraceTool()
+	s.addDiffCaptureTool()
+	s.addInterHitTimingsTool()
+	s.addBreakOnPanicTool()
 	s.addContinueTool()
+	s.addContinueAsyncTool()
+	s.addWaitForStopTool()
+	s.addHaltTool()
+	s.addContinueToLineTool()
+	s.addRunUntilReturnsTool()
+	s.addRunToCompletionTool()
+	s.addLineCoverageTool()
+	s.addWatchGoroutineCountTool()
 	s.addStepTool()
 	s.addStepOverTool()
 	s.addStepOutTool()
+	s.addStepIsolatedTool()
+	s.addLaunchRecordingTool()
+	s.addReverseStepTool()
+	s.addReverseNextTool()
+	s.addReverseContinueTool()
+	s.addHistoryOfVariableTool()
+	s.addStepInstructionTool()
+	s.addStepToNextCallTool()
 	s.addEvalVariableTool()
+	s.addListLocalsTool()
+	s.addListArgsTool()
+	s.addAllFramesLocalsTool()
+	s.addArgsWithRegistersTool()
+	s.addListPackageVariablesTool()
+	s.addFindVariablesTool()
+	s.addSetVariableTool()
+	s.addEvalExpressionTool()
+	s.addAssertTool()
+	s.addEvalGoroutinesTool()
+	s.addWhatIsTool()
+	s.addDeclarationOfTool()
+	s.addInspectInterfaceTool()
+	s.addInspectChannelTool()
+	s.addInspectMutexTool()
+	s.addGetElementTool()
+	s.addTabulateSliceTool()
+	s.addSnapshotVariableTool()
+	s.addCompareSnapshotTool()
+	s.addListSnapshotsTool()
+	s.addClearSnapshotsTool()
+	s.addFollowPointerTool()
+	s.addCallFunctionTool()
 	s.addGetDebuggerOutputTool()
+	s.addReadOutputTool()
+	s.addWriteStdinTool()
+	s.addSetOutputFormatTool()
+	s.addSetResponseLimitTool()
+	s.addSetFollowPointersTool()
+	s.addListGoroutinesTool()
+	s.addSwitchGoroutineTool()
+	s.addCurrentGoroutineTool()
+	s.addChildGoroutinesTool()
+	s.addListThreadsTool()
+	s.addSwitchThreadTool()
+	s.addDescribeTool()
+	s.addBacktraceTool()
+	s.addListDeferredTool()
+	s.addDumpStacksTool()
+	s.addDetectDeadlockTool()
+	s.addListSourceTool()
+	s.addListFunctionsTool()
+	s.addListSourcesTool()
+	s.addBuildInfoTool()
+	s.addDisassembleTool()
+	s.addExamineMemoryTool()
+	s.addAddressToSymbolTool()
+	s.addReadRegistersTool()
+	s.addSetRegisterTool()
+	s.addSetNextStatementTool()
+}
+
+func (s *MCPDebugServer) addCreateSessionTool() {
+	createSessionTool := mcp.NewTool("create_session",
+		mcp.WithDescription("Create a separate debug session, to debug several programs at once (e.g., a client and a server). Pass its ID as sessionID to the other tools"),
+		mcp.WithString("sessionID",
+			mcp.Description("ID for the new session (default: a generated one like 'session-1')"),
+		),
+	)
+
+	s.addServerTool(createSessionTool, s.CreateSession)
+}
+
+func (s *MCPDebugServer) addListSessionsTool() {
+	listSessionsTool := mcp.NewTool("list_sessions",
+		mcp.WithDescription("List the debug sessions and what each one is debugging"),
+	)
+
+	s.addServerTool(listSessionsTool, s.ListSessions)
+}
+
+func (s *MCPDebugServer) addStatusTool() {
+	statusTool := mcp.NewTool("status",
+		mcp.WithDescription("Report whether a program is being debugged and its state: the target, whether it is running or stopped and where, how many breakpoints are set, and the Delve version. Never fails, so it is safe to call first to get oriented"),
+		mcp.WithString("sessionID",
+			mcp.Description("Debug session to describe (default: the default session)"),
+		),
+	)
+
+	s.addServerTool(statusTool, s.Status)
+}
+
+func (s *MCPDebugServer) addCloseSessionTool() {
+	closeSessionTool := mcp.NewTool("close_session",
+		mcp.WithDescription("Close a debug session and the program debugged in it, leaving the other sessions alone. The default session is only reset"),
+		mcp.WithString("sessionID",
+			mcp.Required(),
+			mcp.Description("ID of the session to close"),
+		),
+	)
+
+	s.addServerTool(closeSessionTool, s.CloseSession)
+}
+
+func (s *MCPDebugServer) addShutdownTool() {
+	shutdownTool := mcp.NewTool("shutdown",
+		mcp.WithDescription("Shut the debugger down when done with it: close every session, killing the programs they launched and leaving attached processes running, and free the Delve servers and debug binaries. Every later tool call fails; restart the MCP server to debug again"),
+	)
+
+	s.addServerTool(shutdownTool, s.Shutdown)
 }
 
 func (s *MCPDebugServer) addLaunchTool() {
@@ -59,26 +431,77 @@ func (s *MCPDebugServer) addLaunchTool() {
 		mcp.WithDescription("Launch a Go application with debugging enabled"),
 		mcp.WithString("program",
 			mcp.Required(),
-			mcp.Description("Path to the Go program"),
+			mcp.Description("Path to a compiled Go program, or a package path to build first"),
 		),
 		mcp.WithArray("args",
 			mcp.Description("Arguments to pass to the program"),
 		),
+		mcp.WithArray("env",
+			mcp.Description("Environment variables to set, as KEY=VALUE strings (e.g., 'PORT=9090')"),
+		),
+		mcp.WithString("workingDir",
+			mcp.Description("Working directory for the program"),
+		),
+		mcp.WithBoolean("stopAtMain",
+			mcp.Description("Run the program to the first line of main.main, past the runtime's entry point, before returning (default: true); set to false to stop before package initialization, e.g. to break in init functions"),
+		),
 	)
 
-	s.server.AddTool(launchTool, s.Launch)
+	s.addTool(launchTool, s.Launch)
 }
 
 func (s *MCPDebugServer) addAttachTool() {
 	attachTool := mcp.NewTool("attach",
-		mcp.WithDescription("Attach to a running Go process"),
+		mcp.WithDescription("Attach to a running Go process by PID or by executable name"),
 		mcp.WithNumber("pid",
-			mcp.Required(),
 			mcp.Description("Process ID to attach to"),
 		),
+		mcp.WithString("name",
+			mcp.Description("Executable name to attach to, used when pid is not given"),
+		),
+		mcp.WithBoolean("exact",
+			mcp.Description("Require the executable name to match exactly instead of as a substring (default: false)"),
+		),
+	)
+
+	s.addTool(attachTool, s.Attach)
+}
+
+func (s *MCPDebugServer) addOpenCoreTool() {
+	openCoreTool := mcp.NewTool("open_core",
+		mcp.WithDescription("Open a core dump of a crashed Go program for post-mortem inspection. The session is read-only: goroutines, backtraces and variables can be inspected, but continue, step and other commands that run or change the program are rejected"),
+		mcp.WithString("executable",
+			mcp.Required(),
+			mcp.Description("Path to the executable that produced the core"),
+		),
+		mcp.WithString("core",
+			mcp.Required(),
+			mcp.Description("Path to the core file"),
+		),
+	)
+
+	s.addTool(openCoreTool, s.OpenCore)
+}
+
+func (s *MCPDebugServer) addConnectRemoteTool() {
+	connectRemoteTool := mcp.NewTool("connect_remote",
+		mcp.WithDescription("Connect to a Delve server started with 'dlv --headless', e.g. inside a container"),
+		mcp.WithString("address",
+			mcp.Required(),
+			mcp.Description("host:port of the headless Delve server"),
+		),
+		mcp.WithBoolean("keepTarget",
+			mcp.Description("Leave the remote program running instead of killing it when the session is closed (default: false)"),
+		),
+		mcp.WithNumber("reconnectAttempts",
+			mcp.Description("Dials to try when the connection drops before the session is given up; 0 turns reconnecting off (default: 3)"),
+		),
+		mcp.WithNumber("reconnectBackoff",
+			mcp.Description("Seconds to wait before the second dial, doubled after each failed one up to 30 (default: 0.5)"),
+		),
 	)
 
-	s.server.AddTool(attachTool, s.Attach)
+	s.addTool(connectRemoteTool, s.ConnectRemote)
 }
 
 func (s *MCPDebugServer) addCloseTool() {
@@ -86,293 +509,3494 @@ func (s *MCPDebugServer) addCloseTool() {
 		mcp.WithDescription("Close the current debugging session"),
 	)
 
-	s.server.AddTool(closeTool, s.Close)
+	s.addTool(closeTool, s.Close)
+}
+
+func (s *MCPDebugServer) addDetachTool() {
+	detachTool := mcp.NewTool("detach",
+		mcp.WithDescription("End the debug session, either killing the target or leaving it running without the debugger"),
+		mcp.WithBoolean("kill",
+			mcp.Description("Kill the target (default: true for launched programs, false for attached processes and remote targets connected with keepTarget)"),
+		),
+	)
+
+	s.addTool(detachTool, s.Detach)
+}
+
+func (s *MCPDebugServer) addRestartTool() {
+	restartTool := mcp.NewTool("restart",
+		mcp.WithDescription("Restart the debugged program, re-applying its breakpoints"),
+		mcp.WithBoolean("rebuild",
+			mcp.Description("Rebuild the binary from source before restarting (default: false)"),
+		),
+	)
+
+	s.addTool(restartTool, s.Restart)
+}
+
+func (s *MCPDebugServer) addSetLaunchArgsTool() {
+	setLaunchArgsTool := mcp.NewTool("set_launch_args",
+		mcp.WithDescription("Replace the arguments the program is launched with on the next restart, and report the launch configuration. Only for launched programs, not attached processes, core dumps or remote sessions"),
+		mcp.WithArray("args",
+			mcp.Required(),
+			mcp.Description("Arguments to pass to the program; an empty array passes none. For a test binary these replace its flags, e.g. '-test.v', '-test.run=TestX'"),
+		),
+	)
+
+	s.addTool(setLaunchArgsTool, s.SetLaunchArgs)
+}
+
+func (s *MCPDebugServer) addSetLaunchEnvTool() {
+	setLaunchEnvTool := mcp.NewTool("set_launch_env",
+		mcp.WithDescription("Replace the environment variables the program is launched with on the next restart, and report the launch configuration. Only for launched programs, not attached processes, core dumps or remote sessions"),
+		mcp.WithArray("env",
+			mcp.Required(),
+			mcp.Description("Environment variables to set, as KEY=VALUE strings (e.g., 'LOG_LEVEL=debug'); an empty array leaves the server's environment alone"),
+		),
+	)
+
+	s.addTool(setLaunchEnvTool, s.SetLaunchEnv)
 }
 
 func (s *MCPDebugServer) addSetBreakpointTool() {
 	breakpointTool := mcp.NewTool("set_breakpoint",
-		mcp.WithDescription("Set a breakpoint at a specific file location with optional condition"),
+		mcp.WithDescription("Set a breakpoint at a location or at a file and line, with optional condition. Ambiguous locations return the candidates instead of setting a breakpoint"),
+		mcp.WithString("location",
+			mcp.Description("Location as a single string: file:line (e.g., 'webserver.go:20'), package.function (e.g., 'main.helloHandler') or a line number in the current file. Used instead of file and line"),
+		),
 		mcp.WithString("file",
-			mcp.Required(),
-			mcp.Description("Path to the file"),
+			mcp.Description("Path to the file, when no location is given"),
 		),
 		mcp.WithNumber("line",
-			mcp.Required(),
-			mcp.Description("Line number"),
+			mcp.Description("Line number, when no location is given"),
 		),
 		mcp.WithString("condition",
 			mcp.Description("Optional condition expression (e.g., 'count > 5', 'username == \"admin\"')"),
 		),
+		mcp.WithString("hitCondition",
+			mcp.Description("Optional hit-count condition: stop only when the number of hits satisfies it (e.g., '== 100', '>= 5', '% 10' or '% 10 == 0' for every 10th hit)"),
+		),
+		mcp.WithString("goroutineLabel",
+			mcp.Description("Optional pprof label as key=value (e.g., 'handler=checkout'): only stop goroutines carrying it. Hits on other goroutines are continued past automatically but still count towards the hit count and hitCondition"),
+		),
+		mcp.WithNumber("ignoreCount",
+			mcp.Description("Optional number of hits to continue past before stopping (e.g., 50 to stop from the 51st hit on). Only hits that satisfy condition, hitCondition and goroutineLabel are ignored; list_breakpoints shows how many are left"),
+		),
+		mcp.WithBoolean("pinToFirstGoroutine",
+			mcp.Description("Pin the breakpoint to the goroutine of its first stop, e.g. to follow one request in a busy server (default: false). Its condition is then rewritten to also require runtime.curg.goid == <that goroutine>, so other goroutines run past it; the stop reports the goroutine under context.stop.pinnedGoroutine"),
+		),
+		mcp.WithArray("captureExprs",
+			mcp.Description("Expressions to evaluate each time the breakpoint stops (e.g., 'r.URL.Path', 'len(items)'); their values are returned with the stop under context.captured, and ones that fail under context.captureErrors"),
+		),
+		mcp.WithBoolean("strict",
+			mcp.Description("Fail on a line without code, such as a comment or blank line, instead of setting the breakpoint at the next line with code (default: false). requestedLine, actualLine and adjustment in the response tell when it was moved"),
+		),
+		mcp.WithBoolean("onReturn",
+			mcp.Description("Stop right before the function at the location returns instead of at the location, e.g. location 'main.cleanup' with onReturn true (default: false). Every return statement is covered, including the return after a deferred call recovers a panic, and each stop reports the function's results under context.returnValues"),
+		),
 	)
 
-	s.server.AddTool(breakpointTool, s.SetBreakpoint)
+	s.addTool(breakpointTool, s.SetBreakpoint)
 }
 
-func (s *MCPDebugServer) addListBreakpointsTool() {
-	listBreakpointsTool := mcp.NewTool("list_breakpoints",
-		mcp.WithDescription("List all currently set breakpoints"),
+func (s *MCPDebugServer) addSetBreakpointsTool() {
+	setBreakpointsTool := mcp.NewTool("set_breakpoints",
+		mcp.WithDescription("Set several breakpoints in one call. Each one is set on its own: the result for each says whether it was set, with its ID, or why not, and status is 'partial' when only some were set"),
+		mcp.WithArray("breakpoints",
+			mcp.Required(),
+			mcp.Description("Breakpoints to set, each with the same fields as set_breakpoint"),
+			mcp.Items(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"location":            map[string]interface{}{"type": "string", "description": "file:line, package.function or a line number in the current file"},
+					"file":                map[string]interface{}{"type": "string", "description": "Path to the file, when no location is given"},
+					"line":                map[string]interface{}{"type": "number", "description": "Line number, when no location is given"},
+					"condition":           map[string]interface{}{"type": "string", "description": "Condition expression"},
+					"hitCondition":        map[string]interface{}{"type": "string", "description": "Hit-count condition, e.g. '== 100' or '% 10'"},
+					"goroutineLabel":      map[string]interface{}{"type": "string", "description": "pprof label as key=value goroutines must carry to stop"},
+					"ignoreCount":         map[string]interface{}{"type": "number", "description": "Number of hits to continue past before stopping"},
+					"pinToFirstGoroutine": map[string]interface{}{"type": "boolean", "description": "Only stop the goroutine of the first stop from then on"},
+					"captureExprs":        map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Expressions to evaluate each time the breakpoint stops"},
+					"strict":              map[string]interface{}{"type": "boolean", "description": "Fail on a line without code instead of moving to the next line with code"},
+					"onReturn":            map[string]interface{}{"type": "boolean", "description": "Stop at the returns of the function at the location instead of at the location"},
+				},
+			}),
+		),
 	)
 
-	s.server.AddTool(listBreakpointsTool, s.ListBreakpoints)
+	s.addTool(setBreakpointsTool, s.SetBreakpoints)
 }
 
-func (s *MCPDebugServer) addRemoveBreakpointTool() {
-	removeBreakpointTool := mcp.NewTool("remove_breakpoint",
-		mcp.WithDescription("Remove a breakpoint by its ID"),
-		mcp.WithNumber("id",
+func (s *MCPDebugServer) addSetTypeBreakpointsTool() {
+	typeBreakpointsTool := mcp.NewTool("set_type_breakpoints",
+		mcp.WithDescription("Set a breakpoint on entry to every method of a type, pointer and value receivers alike, so a call through an interface stops whichever implementation it reaches. Methods that could not get a breakpoint are listed apart, and methods the compiler inlined are flagged, as they stop at their inlined call sites"),
+		mcp.WithString("type",
 			mcp.Required(),
-			mcp.Description("ID of the breakpoint to remove"),
+			mcp.Description("Type whose methods to break on, e.g. '*main.Handler', 'main.Handler' or 'handlers.Handler'"),
+		),
+		mcp.WithString("methodFilter",
+			mcp.Description("Regular expression the method names must match, e.g. '^Serve' (default: all methods)"),
 		),
 	)
 
-	s.server.AddTool(removeBreakpointTool, s.RemoveBreakpoint)
+	s.addTool(typeBreakpointsTool, s.SetTypeBreakpoints)
 }
 
-func (s *MCPDebugServer) addDebugSourceFileTool() {
-	debugTool := mcp.NewTool("debug",
-		mcp.WithDescription("Debug a Go source file directly"),
-		mcp.WithString("file",
+func (s *MCPDebugServer) addBreakOnErrorTool() {
+	breakOnErrorTool := mcp.NewTool("break_on_error",
+		mcp.WithDescription("Stop the moment a function matching a pattern returns a non-nil error, optionally only one whose message matches a regex, to find where an error starts. Sets a breakpoint on the returns of each matching function outside the runtime; on a stop, context.returnedError has the function, the error result, its type, value and message. Returns without a matching error are continued past, which slows hot functions down, and functions without an error result lose their breakpoint on their first return"),
+		mcp.WithString("functionPattern",
 			mcp.Required(),
-			mcp.Description("Absolute Path to the Go source file"),
+			mcp.Description("Regular expression the function names must match, as for list_functions, e.g. '^main\\.' or 'Load|Save'; at most 200 functions"),
 		),
-		mcp.WithArray("args",
-			mcp.Description("Arguments to pass to the program"),
+		mcp.WithString("errorMatch",
+			mcp.Description("Regular expression the error must match, e.g. 'not found'; matched against the message of the standard library's error types, or else the rendered error value (default: any non-nil error)"),
 		),
 	)
 
-	s.server.AddTool(debugTool, s.DebugSourceFile)
+	s.addTool(breakOnErrorTool, s.BreakOnError)
 }
 
-func (s *MCPDebugServer) addDebugTestTool() {
-	debugTestTool := mcp.NewTool("debug_test",
-		mcp.WithDescription("Debug a Go test function"),
-		mcp.WithString("testfile",
-			mcp.Required(),
-			mcp.Description("Absolute Path to the test file"),
+func (s *MCPDebugServer) addExportBreakpointsTool() {
+	exportBreakpointsTool := mcp.NewTool("export_breakpoints",
+		mcp.WithDescription("Save the breakpoints of the session as JSON, with their conditions, capture expressions, goroutine labels and enabled state, to set them up again later with import_breakpoints. Watchpoints are left out"),
+		mcp.WithString("file",
+			mcp.Description("File to write the breakpoints to (default: return them in the response)"),
 		),
-		mcp.WithString("testname",
-			mcp.Required(),
-			mcp.Description("Name of the test function to debug"),
+	)
+
+	s.addTool(exportBreakpointsTool, s.ExportBreakpoints)
+}
+
+func (s *MCPDebugServer) addImportBreakpointsTool() {
+	importBreakpointsTool := mcp.NewTool("import_breakpoints",
+		mcp.WithDescription("Set up the breakpoints saved by export_breakpoints, resolving them against the current program. Breakpoints whose code moved to another function or no longer exists are reported as failed"),
+		mcp.WithString("data",
+			mcp.Description("The JSON returned by export_breakpoints"),
 		),
-		mcp.WithArray("testflags",
-			mcp.Description("Optional flags to pass to go test"),
+		mcp.WithString("file",
+			mcp.Description("File written by export_breakpoints, used instead of data"),
 		),
 	)
 
-	s.server.AddTool(debugTestTool, s.DebugTest)
+	s.addTool(importBreakpointsTool, s.ImportBreakpoints)
 }
 
-func (s *MCPDebugServer) addContinueTool() {
-	continueTool := mcp.NewTool("continue",
-		mcp.WithDescription("Continue execution until next breakpoint or program end"),
+func (s *MCPDebugServer) addResetHitCountTool() {
+	resetHitCountTool := mcp.NewTool("reset_hit_count",
+		mcp.WithDescription("Reset the hit counts of a breakpoint to zero, re-arming a breakpoint with a hit-count condition. The breakpoint is re-created with the same settings and gets a new ID"),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("ID of the breakpoint"),
+		),
 	)
 
-	s.server.AddTool(continueTool, s.Continue)
+	s.addTool(resetHitCountTool, s.ResetHitCount)
 }
 
-func (s *MCPDebugServer) addStepTool() {
-	stepTool := mcp.NewTool("step",
-		mcp.WithDescription("Step into the next function call"),
+func (s *MCPDebugServer) addToggleBreakpointTool() {
+	toggleBreakpointTool := mcp.NewTool("toggle_breakpoint",
+		mcp.WithDescription("Enable or disable a breakpoint. A disabled breakpoint keeps its ID, conditions and capture expressions but does not stop the program until it is enabled again"),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("ID of the breakpoint"),
+		),
+		mcp.WithBoolean("enabled",
+			mcp.Required(),
+			mcp.Description("true to enable the breakpoint, false to disable it"),
+		),
 	)
 
-	s.server.AddTool(stepTool, s.Step)
+	s.addTool(toggleBreakpointTool, s.ToggleBreakpoint)
 }
 
-func (s *MCPDebugServer) addStepOverTool() {
-	stepOverTool := mcp.NewTool("step_over",
-		mcp.WithDescription("Step over the next function call"),
+func (s *MCPDebugServer) addSetIgnoreCountTool() {
+	setIgnoreCountTool := mcp.NewTool("set_ignore_count",
+		mcp.WithDescription("Make an existing breakpoint continue past its next hits before it stops, replacing any ignore count it had. Only hits that satisfy its conditions are ignored. The breakpoint keeps its ID and hit counts"),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("ID of the breakpoint"),
+		),
+		mcp.WithNumber("count",
+			mcp.Required(),
+			mcp.Description("Number of hits to continue past; 0 makes the breakpoint stop on its next hit again"),
+		),
 	)
 
-	s.server.AddTool(stepOverTool, s.StepOver)
+	s.addTool(setIgnoreCountTool, s.SetIgnoreCount)
 }
 
-func (s *MCPDebugServer) addStepOutTool() {
-	stepOutTool := mcp.NewTool("step_out",
-		mcp.WithDescription("Step out of the current function"),
+func (s *MCPDebugServer) addArmBreakpointAfterTool() {
+	armBreakpointAfterTool := mcp.NewTool("arm_breakpoint_after",
+		mcp.WithDescription("Set a breakpoint that stays disabled until another breakpoint, the trigger, stops the program, which then enables it: to stop at the next entry to a function only after a marker was reached, e.g. the first request handled after a cache reset. The trigger's hit reports the breakpoints it armed. The trigger only arms on hits that stop the program with continue and the tools like it, not on ones its ignore count or goroutine label continues past"),
+		mcp.WithNumber("triggerID",
+			mcp.Required(),
+			mcp.Description("ID of the breakpoint whose hit arms the new one"),
+		),
+		mcp.WithString("location",
+			mcp.Required(),
+			mcp.Description("Where to set the armed breakpoint: file:line (e.g., 'cache.go:42'), package.function (e.g., 'main.handleRequest') or a line number in the current file"),
+		),
+		mcp.WithBoolean("oneShot",
+			mcp.Description("Disable the breakpoint again after its first hit, until the trigger is hit again (default: false, it stays enabled once armed)"),
+		),
 	)
 
-	s.server.AddTool(stepOutTool, s.StepOut)
+	s.addTool(armBreakpointAfterTool, s.ArmBreakpointAfter)
 }
 
-func (s *MCPDebugServer) addEvalVariableTool() {
-	evalVarTool := mcp.NewTool("eval_variable",
-		mcp.WithDescription("Evaluate the value of a variable"),
-		mcp.WithString("name",
+func (s *MCPDebugServer) addAmendBreakpointConditionTool() {
+	amendBreakpointConditionTool := mcp.NewTool("amend_breakpoint_condition",
+		mcp.WithDescription("Replace the condition of an existing breakpoint, or clear it, in place. The breakpoint keeps its ID, hit counts and other settings"),
+		mcp.WithNumber("id",
 			mcp.Required(),
-			mcp.Description("Name of the variable to evaluate"),
+			mcp.Description("ID of the breakpoint"),
 		),
-		mcp.WithNumber("depth",
-			mcp.Description("Depth for evaluate nested structures (default: 1)"),
+		mcp.WithString("condition",
+			mcp.Required(),
+			mcp.Description("New condition expression (e.g., 'count > 5', 'username == \"admin\"'); empty to make the breakpoint unconditional"),
 		),
 	)
 
-	s.server.AddTool(evalVarTool, s.EvalVariable)
+	s.addTool(amendBreakpointConditionTool, s.AmendBreakpointCondition)
 }
 
-func (s *MCPDebugServer) addGetDebuggerOutputTool() {
-	outputTool := mcp.NewTool("get_debugger_output",
-		mcp.WithDescription("Get captured stdout and stderr from the debugged program"),
+func (s *MCPDebugServer) addListBreakpointsTool() {
+	listBreakpointsTool := mcp.NewTool("list_breakpoints",
+		mcp.WithDescription("List all currently set breakpoints sorted by ID, with their status, condition and hit counts per goroutine. Each has a kind: user for the ones set with the breakpoint tools, or what one of Delve's internal breakpoints is for"),
+		mcp.WithBoolean("includeInternal",
+			mcp.Description("Also list Delve's own breakpoints, marked internal with a kind and purpose, to understand unexpected stops (default: false): unrecovered-panic and fatal-throw have negative IDs, and the ones a step in progress uses, e.g. step-over, have ID 0. They cannot be removed"),
+		),
 	)
 
-	s.server.AddTool(outputTool, s.GetDebuggerOutput)
+	s.addTool(listBreakpointsTool, s.ListBreakpoints)
 }
 
-func newErrorResult(format string, args ...interface{}) *mcp.CallToolResult {
-	result := mcp.NewToolResultText(fmt.Sprintf("Error: "+format, args...))
-	result.IsError = true
-	return result
-}
+func (s *MCPDebugServer) addRemoveBreakpointTool() {
+	removeBreakpointTool := mcp.NewTool("remove_breakpoint",
+		mcp.WithDescription("Remove a breakpoint by its ID. Delve's internal breakpoints, with IDs of 0 or less, cannot be removed"),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("ID of the breakpoint to remove"),
+		),
+	)
 
-func (s *MCPDebugServer) Launch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received launch request")
+	s.addTool(removeBreakpointTool, s.RemoveBreakpoint)
+}
 
-	program := request.Params.Arguments["program"].(string)
+func (s *MCPDebugServer) addSetWatchpointTool() {
+	watchpointTool := mcp.NewTool("set_watchpoint",
+		mcp.WithDescription("Set a watchpoint that stops when a variable is read or written; remove it with remove_breakpoint"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Variable expression to watch (e.g., 'requestCount', 'p.Age')"),
+		),
+		mcp.WithString("type",
+			mcp.Description("Access to watch for: 'read', 'write' or 'readwrite' (default: 'write')"),
+		),
+	)
 
-	var args []string
-	if argsVal, ok := request.Params.Arguments["args"]; ok && argsVal != nil {
-		argsArray := argsVal.([]interface{})
-		args = make([]string, len(argsArray))
-		for i, arg := range argsArray {
-			args[i] = fmt.Sprintf("%v", arg)
-		}
-	}
+	s.addTool(watchpointTool, s.SetWatchpoint)
+}
 
-	response := s.debugClient.LaunchProgram(program, args)
+func (s *MCPDebugServer) addSetTracepointTool() {
+	tracepointTool := mcp.NewTool("set_tracepoint",
+		mcp.WithDescription("Set a tracepoint that records expressions on every hit without stopping the program; read the hits with read_trace"),
+		mcp.WithString("file",
+			mcp.Required(),
+			mcp.Description("Path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("Line number"),
+		),
+		mcp.WithArray("expressions",
+			mcp.Description("Expressions to evaluate on each hit (e.g., 'r.URL.Path', 'requestCount')"),
+		),
+		mcp.WithString("condition",
+			mcp.Description("Optional condition (e.g., 'name != \"World\"'); hits are only recorded when it is true, and hits where it is false don't count toward hitCondition"),
+		),
+		mcp.WithString("hitCondition",
+			mcp.Description("Optional hit-count condition on the hits matching condition, e.g. '<= 100' to record only the first 100"),
+		),
+	)
 
-	return newToolResultJSON(response)
+	s.addTool(tracepointTool, s.SetTracepoint)
 }
 
-func (s *MCPDebugServer) Attach(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received attach request")
+func (s *MCPDebugServer) addBreakOnPanicTool() {
+	breakOnPanicTool := mcp.NewTool("break_on_panic",
+		mcp.WithDescription("Stop the program where a panic starts, including panics that are recovered later, and optionally on fatal runtime errors; the stop reason shows the panic message"),
+		mcp.WithBoolean("enabled",
+			mcp.Required(),
+			mcp.Description("Whether to stop on panics; false turns the mode off"),
+		),
+		mcp.WithBoolean("fatal",
+			mcp.Description("Whether to stop on fatal runtime errors such as concurrent map writes or deadlocks (default: same as enabled)"),
+		),
+	)
 
-	pidFloat := request.Params.Arguments["pid"].(float64)
-	pid := int(pidFloat)
+	s.addTool(breakOnPanicTool, s.BreakOnPanic)
+}
 
-	response := s.debugClient.AttachToProcess(pid)
+func (s *MCPDebugServer) addReadTraceTool() {
+	readTraceTool := mcp.NewTool("read_trace",
+		mcp.WithDescription("Read tracepoint hits in the order they happened, with timestamps and captured values"),
+		mcp.WithNumber("since",
+			mcp.Description("Only return hits after this sequence number, e.g. the nextSeq of a previous read (default: 0)"),
+		),
+		mcp.WithNumber("breakpoint",
+			mcp.Description("Only return hits of this tracepoint ID (default: all tracepoints)"),
+		),
+	)
 
-	return newToolResultJSON(response)
+	s.addTool(readTraceTool, s.ReadTrace)
 }
 
-func (s *MCPDebugServer) Close(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received close request")
-
-	response, err := s.debugClient.Close()
+func (s *MCPDebugServer) addDiffCaptureTool() {
+	diffCaptureTool := mcp.NewTool("diff_capture",
+		mcp.WithDescription("Show what changed in the capture expressions of a breakpoint between its last two hits: only the values, fields, elements and map entries that differ, old against new. The values of the last 20 hits of each breakpoint are kept"),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("ID of a breakpoint set with captureExprs"),
+		),
+	)
+
+	s.addTool(diffCaptureTool, s.DiffCapture)
+}
+
+func (s *MCPDebugServer) addInterHitTimingsTool() {
+	interHitTimingsTool := mcp.NewTool("inter_hit_timings",
+		mcp.WithDescription("Time the intervals between consecutive hits of a breakpoint, in wall-clock time and in the time the program ran for, with the min, max and average. Debugger overhead inflates every interval, so use them as relative indicators of slow paths. The last 1000 hits of each breakpoint are timed"),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("ID of the breakpoint or tracepoint"),
+		),
+	)
+
+	s.addTool(interHitTimingsTool, s.InterHitTimings)
+}
+
+func (s *MCPDebugServer) addDebugSourceFileTool() {
+	debugTool := mcp.NewTool("debug",
+		mcp.WithDescription("Debug a Go source file directly"),
+		mcp.WithString("file",
+			mcp.Required(),
+			mcp.Description("Absolute Path to the Go source file"),
+		),
+		mcp.WithArray("args",
+			mcp.Description("Arguments to pass to the program"),
+		),
+	)
+
+	s.addTool(debugTool, s.DebugSourceFile)
+}
+
+func (s *MCPDebugServer) addLaunchTestTool() {
+	launchTestTool := mcp.NewTool("launch_test",
+		mcp.WithDescription("Compile the tests of a package with 'go test -c' and launch them stopped at program start, so breakpoints can be set before continuing. Compile errors are listed so the tests can be fixed first; test output is read with read_output"),
+		mcp.WithString("package",
+			mcp.Description("Package directory, import path or file of the package (default: the current directory)"),
+		),
+		mcp.WithString("test",
+			mcp.Description("Regular expression selecting the tests to run, as for 'go test -run' (e.g., '^TestParse$'); all tests run when omitted"),
+		),
+		mcp.WithArray("flags",
+			mcp.Description("Extra flags for the test binary, e.g. ['-count=1', '-short']; the 'test.' prefix is added to go test flags"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+	)
+
+	s.addTool(launchTestTool, s.LaunchTest)
+}
+
+func (s *MCPDebugServer) addDebugTestTool() {
+	debugTestTool := mcp.NewTool("debug_test",
+		mcp.WithDescription("Debug a Go test function"),
+		mcp.WithString("testfile",
+			mcp.Required(),
+			mcp.Description("Absolute Path to the test file"),
+		),
+		mcp.WithString("testname",
+			mcp.Required(),
+			mcp.Description("Name of the test function to debug"),
+		),
+		mcp.WithArray("testflags",
+			mcp.Description("Optional flags to pass to go test"),
+		),
+	)
+
+	s.addTool(debugTestTool, s.DebugTest)
+}
+
+func (s *MCPDebugServer) addContinueTool() {
+	continueTool := mcp.NewTool("continue",
+		mcp.WithDescription("Continue execution until next breakpoint or program end; the program is halted if it doesn't stop within the timeout"),
+		withTimeoutParam(),
+	)
+
+	s.addTool(continueTool, s.Continue)
+}
+
+func (s *MCPDebugServer) addContinueAsyncTool() {
+	continueAsyncTool := mcp.NewTool("continue_async",
+		mcp.WithDescription("Resume the program and return right away, without waiting for it to stop. Use wait_for_stop to learn when and why it stops, e.g. after sending the request that reaches a breakpoint. Until it stops, tools that read the target are refused; wait_for_stop, halt, status, read_output and write_stdin still work"),
+	)
+
+	s.addTool(continueAsyncTool, s.ContinueAsync)
+}
+
+func (s *MCPDebugServer) addWaitForStopTool() {
+	waitForStopTool := mcp.NewTool("wait_for_stop",
+		mcp.WithDescription("Wait for the running program to stop at a breakpoint, panic or exit and report why. Returns stopped=false if it is still running when the timeout ends; the program keeps running"),
+		mcp.WithNumber("timeout",
+			mcp.Description("Seconds to wait, 0 to only check (default: 10)"),
+		),
+	)
+
+	s.addTool(waitForStopTool, s.WaitForStop)
+}
+
+func (s *MCPDebugServer) addHaltTool() {
+	haltTool := mcp.NewTool("halt",
+		mcp.WithDescription("Interrupt the running program so it can be inspected"),
+	)
+
+	s.addTool(haltTool, s.Halt)
+}
+
+func (s *MCPDebugServer) addContinueToLineTool() {
+	continueToLineTool := mcp.NewTool("continue_to_line",
+		mcp.WithDescription("Continue execution until a given file and line is reached, using a temporary breakpoint"),
+		mcp.WithString("file",
+			mcp.Required(),
+			mcp.Description("Path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("Line number to run to"),
+		),
+		withTimeoutParam(),
+	)
+
+	s.addTool(continueToLineTool, s.ContinueToLine)
+}
+
+func (s *MCPDebugServer) addRunUntilReturnsTool() {
+	runUntilReturnsTool := mcp.NewTool("run_until_returns",
+		mcp.WithDescription("Continue until a function returns values matching a condition, checking the condition at every return"),
+		mcp.WithString("function",
+			mcp.Required(),
+			mcp.Description("Fully qualified function name (e.g., 'main.handleRequest', '(*main.Server).render')"),
+		),
+		mcp.WithString("condition",
+			mcp.Required(),
+			mcp.Description("Go boolean expression over the results; use named results by name and unnamed ones as r0, r1, ... (e.g., 'r1 != nil', 'status == 500')"),
+		),
+		mcp.WithNumber("maxEvaluations",
+			mcp.Description("Give up after checking this many returns (default: 100)"),
+		),
+		withTimeoutParam(),
+	)
+
+	s.addTool(runUntilReturnsTool, s.RunUntilReturns)
+}
+
+func (s *MCPDebugServer) addRunToCompletionTool() {
+	runToCompletionTool := mcp.NewTool("run_to_completion",
+		mcp.WithDescription("Let the program run to its exit, logging every breakpoint and tracepoint hit on the way: location, goroutine and the values of the breakpoint's capture expressions. Returns the whole hit log and the exit status, for scripted logging driven by breakpoints"),
+		mcp.WithBoolean("autoContinue",
+			mcp.Description("Continue past every breakpoint (default: true); when false, stop at the first breakpoint that isn't a tracepoint"),
+		),
+		mcp.WithNumber("maxHits",
+			mcp.Description("Keep at most this many hits; later ones are only counted (default: 1000, at most 10000)"),
+		),
+		withTimeoutParam(),
+	)
+
+	s.addTool(runToCompletionTool, s.RunToCompletion)
+}
+
+func (s *MCPDebugServer) addLineCoverageTool() {
+	lineCoverageTool := mcp.NewTool("line_coverage",
+		mcp.WithDescription("Find out which of some lines a run executes, e.g. which branch the program takes: sets a tracepoint on each line, continues the program and returns how often each line was hit, then removes the tracepoints. The run ends when the program exits, stops at another breakpoint or times out; a line that already has a breakpoint is counted too. Every hit makes Delve stop and resume the program, so lines in hot loops slow it down"),
+		mcp.WithString("file",
+			mcp.Required(),
+			mcp.Description("Source file the lines are in"),
+		),
+		mcp.WithArray("lines",
+			mcp.Required(),
+			mcp.Description("Line numbers to count the hits of, e.g. the first line of each branch"),
+			mcp.Items(map[string]interface{}{"type": "number"}),
+		),
+		withTimeoutParam(),
+	)
+
+	s.addTool(lineCoverageTool, s.LineCoverage)
+}
+
+func (s *MCPDebugServer) addWatchGoroutineCountTool() {
+	watchGoroutineCountTool := mcp.NewTool("watch_goroutine_count",
+		mcp.WithDescription("Continue, halting briefly every 250ms to count goroutines, until the count crosses a threshold or changes by a delta, to catch goroutine leaks. Returns how the count moved and the goroutines that are new since the start, by the go statement that started them"),
+		mcp.WithNumber("threshold",
+			mcp.Description("Stop once the goroutine count goes above (or below) this number"),
+		),
+		mcp.WithNumber("delta",
+			mcp.Description("Stop once the count has changed by this many from the starting count; use instead of threshold"),
+		),
+		mcp.WithString("direction",
+			mcp.Description("With threshold: 'above' (default) or 'below'. With delta: 'grow' (default), 'shrink' or 'change' for either way"),
+		),
+		mcp.WithNumber("maxWait",
+			mcp.Description("Seconds to keep the program running before giving up (default: 30, at most 300)"),
+		),
+	)
+
+	s.addTool(watchGoroutineCountTool, s.WatchGoroutineCount)
+}
+
+func (s *MCPDebugServer) addStepTool() {
+	stepTool := mcp.NewTool("step",
+		mcp.WithDescription("Step into the next function call"),
+		withStepTimeoutParam(),
+	)
+
+	s.addTool(stepTool, s.Step)
+}
+
+func (s *MCPDebugServer) addStepOverTool() {
+	stepOverTool := mcp.NewTool("step_over",
+		mcp.WithDescription("Step to the next source line of the current function without descending into calls. Reports when the step returned to the caller, or stopped in a deferred function because of a panic"),
+		withStepTimeoutParam(),
+	)
+
+	s.addTool(stepOverTool, s.StepOver)
+}
+
+func (s *MCPDebugServer) addStepIsolatedTool() {
+	stepIsolatedTool := mcp.NewTool("step_isolated",
+		mcp.WithDescription("Step over the next line of the selected goroutine with all other goroutines paused, so concurrent code can't change things mid-step. Works by single-stepping machine instructions, so it is slower than step_over. A call that blocks (channel, mutex, sleep) or runs for more than 5000 instructions lets the others run until it returns; othersRan and isolationNote say when and why. Breakpoints don't stop the paused part of the step"),
+		withStepTimeoutParam(),
+	)
+
+	s.addTool(stepIsolatedTool, s.StepIsolated)
+}
+
+func (s *MCPDebugServer) addStepOutTool() {
+	stepOutTool := mcp.NewTool("step_out",
+		mcp.WithDescription("Step out of the current function"),
+		withStepTimeoutParam(),
+	)
+
+	s.addTool(stepOutTool, s.StepOut)
+}
+
+func (s *MCPDebugServer) addLaunchRecordingTool() {
+	launchRecordingTool := mcp.NewTool("launch_recording",
+		mcp.WithDescription("Record a run of a Go program with rr and replay it, so it can be stepped and continued backward. The program runs to completion while recording, then the session starts at its beginning. Needs rr installed"),
+		mcp.WithString("program",
+			mcp.Required(),
+			mcp.Description("Path to a compiled Go program, or a package path to build first"),
+		),
+		mcp.WithArray("args",
+			mcp.Description("Arguments to pass to the program"),
+		),
+	)
+
+	s.addTool(launchRecordingTool, s.LaunchRecording)
+}
+
+func (s *MCPDebugServer) addReverseStepTool() {
+	reverseStepTool := mcp.NewTool("reverse_step",
+		mcp.WithDescription("Step backward to the previous line, entering calls made on it. Only for sessions replaying an rr recording"),
+		withTimeoutParam(),
+	)
+
+	s.addTool(reverseStepTool, s.ReverseStep)
+}
+
+func (s *MCPDebugServer) addReverseNextTool() {
+	reverseNextTool := mcp.NewTool("reverse_next",
+		mcp.WithDescription("Step backward to the previous line of the current function without entering calls. Only for sessions replaying an rr recording"),
+		withTimeoutParam(),
+	)
+
+	s.addTool(reverseNextTool, s.ReverseNext)
+}
+
+func (s *MCPDebugServer) addHistoryOfVariableTool() {
+	historyOfVariableTool := mcp.NewTool("history_of_variable",
+		mcp.WithDescription("List the past writes to a variable, oldest first, with where each happened and the value it left, by running backward with a write watchpoint. The replay then returns to where it was. Only for sessions replaying an rr recording"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Variable to collect the writes to, e.g. 'requestCount' or 's.total'"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Most recent writes to collect (default: 10, max: 100)"),
+		),
+		withTimeoutParam(),
+	)
+
+	s.addTool(historyOfVariableTool, s.HistoryOfVariable)
+}
+
+func (s *MCPDebugServer) addReverseContinueTool() {
+	reverseContinueTool := mcp.NewTool("reverse_continue",
+		mcp.WithDescription("Run backward to the most recent earlier breakpoint hit, or to the start of the recording. Only for sessions replaying an rr recording"),
+		withTimeoutParam(),
+	)
+
+	s.addTool(reverseContinueTool, s.ReverseContinue)
+}
+
+func (s *MCPDebugServer) addStepInstructionTool() {
+	stepInstructionTool := mcp.NewTool("step_instruction",
+		mcp.WithDescription("Execute a single machine instruction, entering calls, and report the instruction at the new PC, its source line and the registers it changed. Use with disassemble to follow along"),
+		mcp.WithBoolean("reverse",
+			mcp.Description("Step back one instruction instead; only for sessions replaying an rr recording (default: false)"),
+		),
+		withStepTimeoutParam(),
+	)
+
+	s.addTool(stepInstructionTool, s.StepInstruction)
+}
+
+func (s *MCPDebugServer) addStepToNextCallTool() {
+	stepToNextCallTool := mcp.NewTool("step_to_next_call",
+		mcp.WithDescription("Execute machine instructions until the next call instruction and stop before it, reporting the function it calls so you can decide whether to step in with step_instruction. A call at the current PC is stepped over first"),
+		mcp.WithNumber("maxSteps",
+			mcp.Description("Give up after this many instructions (default: 1000)"),
+		),
+		withTimeoutParam(),
+	)
+
+	s.addTool(stepToNextCallTool, s.StepToNextCall)
+}
+
+func (s *MCPDebugServer) addEvalVariableTool() {
+	evalVarTool := mcp.NewTool("eval_variable",
+		mcp.WithDescription("Evaluate the value of a variable, rendering maps with sorted keys and marking slices, maps and strings cut short by the element and string limits"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the variable to evaluate"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Same as maxDepth, which takes precedence"),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description("Levels of nested fields, elements and pointers to load (default: 1, max: 5); use 0 for just the top-level value. Pointers back to a value already shown, as in cyclic structures, name its path instead"),
+		),
+		mcp.WithNumber("maxElements",
+			mcp.Description("Maximum number of slice, array and map elements to load (default: 100); the rest are counted as not shown"),
+		),
+		mcp.WithNumber("maxStringLen",
+			mcp.Description("Maximum number of bytes of strings to load (default: 1024)"),
+		),
+		mcp.WithBoolean("callStringers",
+			mcp.Description("Also render values whose type has a String or Error method with it, by calling the method in the target (default: false). This runs code in the program, and only works in frame 0 of a live process; values are rendered as they are when a call fails"),
+		),
+		withFollowPointersParam(),
+		withBaseParam(),
+	)
+
+	s.addTool(evalVarTool, s.EvalVariable)
+}
+
+func (s *MCPDebugServer) addListLocalsTool() {
+	listLocalsTool := mcp.NewTool("list_locals",
+		mcp.WithDescription("List all local variables of a stack frame with their types and values"),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame index to read from (default: 0, the current frame)"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Same as maxDepth, which takes precedence"),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description("Levels of nested fields, elements and pointers to load (default: 1, max: 5); use 0 for just the top-level value. Pointers back to a value already shown, as in cyclic structures, name its path instead"),
+		),
+		mcp.WithBoolean("hideShadowed",
+			mcp.Description("Leave out variables shadowed by an inner declaration (default: false)"),
+		),
+		mcp.WithBoolean("hideBlank",
+			mcp.Description("Leave out blank (_) identifiers (default: false)"),
+		),
+		mcp.WithNumber("maxStringLen",
+			mcp.Description("Maximum length of string values to load (default: 256)"),
+		),
+		mcp.WithNumber("maxArrayValues",
+			mcp.Description("Maximum number of slice, array or map elements to load (default: 64)"),
+		),
+		mcp.WithBoolean("callStringers",
+			mcp.Description("Also render values whose type has a String or Error method with it, by calling the method in the target (default: false). This runs code in the program, and only works in frame 0 of a live process; values are rendered as they are when a call fails"),
+		),
+		withFollowPointersParam(),
+	)
+
+	s.addTool(listLocalsTool, s.ListLocals)
+}
+
+func (s *MCPDebugServer) addListArgsTool() {
+	listArgsTool := mcp.NewTool("list_args",
+		mcp.WithDescription("List the arguments of the function in a stack frame with their types and values"),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame index to read from (default: 0, the current frame)"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Same as maxDepth, which takes precedence"),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description("Levels of nested fields, elements and pointers to load (default: 1, max: 5); use 0 for just the top-level value. Pointers back to a value already shown, as in cyclic structures, name its path instead"),
+		),
+		mcp.WithBoolean("hideShadowed",
+			mcp.Description("Leave out variables shadowed by an inner declaration (default: false)"),
+		),
+		mcp.WithBoolean("hideBlank",
+			mcp.Description("Leave out blank (_) identifiers (default: false)"),
+		),
+		mcp.WithNumber("maxStringLen",
+			mcp.Description("Maximum length of string values to load (default: 256)"),
+		),
+		mcp.WithNumber("maxArrayValues",
+			mcp.Description("Maximum number of slice, array or map elements to load (default: 64)"),
+		),
+		mcp.WithBoolean("callStringers",
+			mcp.Description("Also render values whose type has a String or Error method with it, by calling the method in the target (default: false). This runs code in the program, and only works in frame 0 of a live process; values are rendered as they are when a call fails"),
+		),
+		withFollowPointersParam(),
+	)
+
+	s.addTool(listArgsTool, s.ListArgs)
+}
+
+func (s *MCPDebugServer) addAllFramesLocalsTool() {
+	allFramesLocalsTool := mcp.NewTool("all_frames_locals",
+		mcp.WithDescription("List the arguments and local variables of every frame of the selected goroutine's stack, innermost first, with each frame's location, to see how values flow down a call chain in one call. Runtime and standard library frames are left out unless asked for"),
+		mcp.WithNumber("frames",
+			mcp.Description("How many of the innermost frames to read (default: 10, max: 50); the response says when the stack goes deeper"),
+		),
+		mcp.WithBoolean("includeRuntime",
+			mcp.Description("Also read frames of the runtime and standard library (default: false)"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Same as maxDepth, which takes precedence"),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description("Levels of nested fields, elements and pointers to load (default: 1, max: 5); use 0 for just the top-level value"),
+		),
+		mcp.WithBoolean("hideShadowed",
+			mcp.Description("Leave out variables shadowed by an inner declaration (default: false)"),
+		),
+		mcp.WithBoolean("hideBlank",
+			mcp.Description("Leave out blank (_) identifiers (default: false)"),
+		),
+		mcp.WithNumber("maxStringLen",
+			mcp.Description("Maximum length of string values to load (default: 256)"),
+		),
+		mcp.WithNumber("maxArrayValues",
+			mcp.Description("Maximum number of slice, array or map elements to load (default: 64)"),
+		),
+		withFollowPointersParam(),
+	)
+
+	s.addTool(allFramesLocalsTool, s.AllFramesLocals)
+}
+
+func (s *MCPDebugServer) addArgsWithRegistersTool() {
+	argsWithRegistersTool := mcp.NewTool("args_with_registers",
+		mcp.WithDescription("List the arguments of a stack frame like list_args, recovering the ones Delve can't read in optimized builds from the CPU registers the debug info says they are in. Each argument says where its value came from (debug_info, register or optimized_out) and how far to trust it (exact, high, partial or none); arguments the compiler dropped are reported as optimized out"),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame index to read from (default: 0, the current frame). Only frame 0 has all the registers; in outer frames, values held in registers are lost"),
+		),
+	)
+
+	s.addTool(argsWithRegistersTool, s.ArgsWithRegisters)
+}
+
+func (s *MCPDebugServer) addAssertTool() {
+	assertTool := mcp.NewTool("assert",
+		mcp.WithDescription("Check that a boolean Go expression holds (e.g., 'requestCount >= 0', 'len(queue) < cap(queue) && !closed'). Returns passed=true or false with the actual value and, for a comparison or logical operator, the values of its two sides; an expression that can't be evaluated or isn't boolean is an error"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Boolean Go expression to check"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame index to evaluate in (default: 0, the current frame)"),
+		),
+	)
+
+	s.addTool(assertTool, s.Assert)
+}
+
+func (s *MCPDebugServer) addEvalExpressionTool() {
+	evalExprTool := mcp.NewTool("eval_expression",
+		mcp.WithDescription("Evaluate an arbitrary Go expression (e.g., 'requestCount + 1', '*ptr', 'len(items)')"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Go expression to evaluate"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame index to evaluate in (default: 0, the current frame)"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Same as maxDepth, which takes precedence"),
+		),
+		mcp.WithNumber("maxDepth",
+			mcp.Description("Levels of nested fields, elements and pointers to load (default: 1, max: 5); use 0 for just the top-level value. Pointers back to a value already shown, as in cyclic structures, name its path instead"),
+		),
+		mcp.WithBoolean("callStringers",
+			mcp.Description("Also render values whose type has a String or Error method with it, by calling the method in the target (default: false). This runs code in the program, and only works in frame 0 of a live process; values are rendered as they are when a call fails"),
+		),
+		withFollowPointersParam(),
+		withBaseParam(),
+	)
+
+	s.addTool(evalExprTool, s.EvalExpression)
+}
+
+func (s *MCPDebugServer) addEvalGoroutinesTool() {
+	evalGoroutinesTool := mcp.NewTool("eval_goroutines",
+		mcp.WithDescription("Evaluate the same Go expression in every goroutine, e.g. to find which one holds a lock or a request; each is evaluated in its topmost frame outside the runtime and standard library, and goroutines the expression is not in scope in are left out"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Go expression to evaluate"),
+		),
+		mcp.WithString("status",
+			mcp.Description("Only evaluate in goroutines with this status (e.g., 'running', 'waiting', 'syscall')"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of goroutines to evaluate in (default: 100, max: 1000); the result says when goroutines were left untried"),
+		),
+	)
+
+	s.addTool(evalGoroutinesTool, s.EvalGoroutines)
+}
+
+func (s *MCPDebugServer) addWhatIsTool() {
+	whatIsTool := mcp.NewTool("whatis",
+		mcp.WithDescription("Get the type of an expression without loading its value; for interfaces, also the concrete type they hold"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Go expression to resolve the type of"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame index to resolve in (default: 0, the current frame)"),
+		),
+	)
+
+	s.addTool(whatIsTool, s.WhatIs)
+}
+
+func (s *MCPDebugServer) addDeclarationOfTool() {
+	declarationOfTool := mcp.NewTool("declaration_of",
+		mcp.WithDescription("Find where a variable or struct field is declared (e.g., 'count', 's.items', 'pkg.Config'), returning the file:line and the declaring line of source, like jump-to-definition; a field is found in the definition of its struct type"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Variable, package variable or field selector to find the declaration of"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame index to resolve in (default: 0, the current frame)"),
+		),
+	)
+
+	s.addTool(declarationOfTool, s.DeclarationOf)
+}
+
+func (s *MCPDebugServer) addInspectInterfaceTool() {
+	inspectInterfaceTool := mcp.NewTool("inspect_interface",
+		mcp.WithDescription("Show the concrete type and fields of the value an interface holds, telling a nil interface apart from one holding a nil pointer, and give a type assertion expression for follow-up evals"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Interface expression to inspect, e.g. 'w' or 'req.Body'"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame to evaluate in (default: 0)"),
+		),
+	)
+
+	s.addTool(inspectInterfaceTool, s.InspectInterface)
+}
+
+func (s *MCPDebugServer) addInspectChannelTool() {
+	inspectChannelTool := mcp.NewTool("inspect_channel",
+		mcp.WithDescription("Show a channel's element type, capacity, length and buffered values, whether it is nil or closed, and the goroutines blocked sending to or receiving from it"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Channel expression to inspect, e.g. 'jobs' or 's.done'"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame to evaluate in (default: 0)"),
+		),
+	)
+
+	s.addTool(inspectChannelTool, s.InspectChannel)
+}
+
+func (s *MCPDebugServer) addInspectMutexTool() {
+	inspectMutexTool := mcp.NewTool("inspect_mutex",
+		mcp.WithDescription("Decode a sync.Mutex or sync.RWMutex: whether it is locked, read locked or has a writer pending, its waiter count, the goroutines blocked locking it, and the goroutines likely holding it, found from the stacks that reference it. Reports \"unknown lock layout\" for Go versions whose mutex fields are not known"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Mutex expression to inspect, e.g. 's.mu' or '&cache.lock'"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame to evaluate in (default: 0)"),
+		),
+	)
+
+	s.addTool(inspectMutexTool, s.InspectMutex)
+}
+
+func (s *MCPDebugServer) addGetElementTool() {
+	getElementTool := mcp.NewTool("get_element",
+		mcp.WithDescription("Evaluate one element of a slice, array, string or map without loading the rest, for collections too large to load whole. Gives the container's length and capacity along with it; with neither index nor key, gives just those"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Slice, array, string or map expression, e.g. 'rows' or 's.cache'"),
+		),
+		mcp.WithNumber("index",
+			mcp.Description("Index of the element of a slice, array or string"),
+		),
+		mcp.WithString("key",
+			mcp.Description("Key of the map entry, as a Go expression, e.g. '\"id\"', '42' or 'k'"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame to evaluate in (default: 0)"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Levels of nested fields, elements and pointers of the element to load (default: 1, max: 5)"),
+		),
+	)
+
+	s.addTool(getElementTool, s.GetElement)
+}
+
+func (s *MCPDebugServer) addTabulateSliceTool() {
+	tabulateSliceTool := mcp.NewTool("tabulate_slice",
+		mcp.WithDescription("Render a slice or array of structs as a table: one row per element, led by its index, with a column per chosen field, aligned as text. Far easier to scan than a nested value tree. Elements that are not structs are shown in a single column. Up to 100 elements are loaded; the response says how many more were left out"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Slice or array expression, e.g. 'requests' or 's.queue[:10]'"),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("Fields of the elements to show as columns, e.g. ['ID', 'Path', 'Addr.Host'] for nested ones (default: every field). Pointers to structs are followed"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame to evaluate in (default: 0)"),
+		),
+	)
+
+	s.addTool(tabulateSliceTool, s.TabulateSlice)
+}
+
+func (s *MCPDebugServer) addSnapshotVariableTool() {
+	snapshotVariableTool := mcp.NewTool("snapshot_variable",
+		mcp.WithDescription("Save the value of a variable or expression under a name, to check later with compare_snapshot whether and how it changed, e.g. across steps or continues. A light alternative to a watchpoint for tracking a specific value. Snapshots are kept until cleared or the session ends, restarts included"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name to save the snapshot under, e.g. 'before-flush'; a snapshot with the same name is replaced"),
+		),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Variable or expression to save the value of, e.g. 'cfg' or 's.items'"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame to evaluate in (default: 0)"),
+		),
+	)
+
+	s.addTool(snapshotVariableTool, s.SnapshotVariable)
+}
+
+func (s *MCPDebugServer) addCompareSnapshotTool() {
+	compareSnapshotTool := mcp.NewTool("compare_snapshot",
+		mcp.WithDescription("Evaluate an expression again and compare it with a snapshot saved by snapshot_variable: only the values, fields, elements and map entries that differ, old against new. A value whose type changed is compared as a whole, with typeChanged set"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the snapshot to compare with"),
+		),
+		mcp.WithString("expression",
+			mcp.Description("Expression to evaluate now (default: the expression of the snapshot)"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame to evaluate in (default: 0)"),
+		),
+	)
+
+	s.addTool(compareSnapshotTool, s.CompareSnapshot)
+}
+
+func (s *MCPDebugServer) addListSnapshotsTool() {
+	listSnapshotsTool := mcp.NewTool("list_snapshots",
+		mcp.WithDescription("List the snapshots saved by snapshot_variable, oldest first, with their values and where they were taken"),
+	)
+
+	s.addTool(listSnapshotsTool, s.ListSnapshots)
+}
+
+func (s *MCPDebugServer) addClearSnapshotsTool() {
+	clearSnapshotsTool := mcp.NewTool("clear_snapshots",
+		mcp.WithDescription("Drop a snapshot saved by snapshot_variable, or all of them"),
+		mcp.WithString("name",
+			mcp.Description("Snapshot to drop (default: every snapshot)"),
+		),
+	)
+
+	s.addTool(clearSnapshotsTool, s.ClearSnapshots)
+}
+
+func (s *MCPDebugServer) addBuildInfoTool() {
+	buildInfoTool := mcp.NewTool("build_info",
+		mcp.WithDescription("Report how a Go executable was built, from the build info embedded in it: the Go version, the main module and the version of every module it depends on, replacements included, and build settings such as -trimpath, GOOS/GOARCH and the VCS revision. Use it to check which version of a dependency is really running. Reads the file, so it works after the program exited, on the executable of a core dump, or without a session"),
+		mcp.WithString("program",
+			mcp.Description("Path of the executable to read (default: the one debugged in the session)"),
+		),
+	)
+
+	s.addTool(buildInfoTool, s.BuildInfo)
+}
+
+func (s *MCPDebugServer) addFollowPointerTool() {
+	followPointerTool := mcp.NewTool("follow_pointer",
+		mcp.WithDescription("Follow a pointer one step: show the address it holds, whether it is nil or points to unreadable memory, and one level of the value it points to. Returns next, the expression of that value (e.g. '(*r)'), and links, the pointers in it with the expression to follow each, to explore linked and recursive structures step by step"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Pointer expression to follow, e.g. 'r' or '(*node).Next'"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame to evaluate in (default: 0)"),
+		),
+	)
+
+	s.addTool(followPointerTool, s.FollowPointer)
+}
+
+func (s *MCPDebugServer) addListPackageVariablesTool() {
+	listPackageVariablesTool := mcp.NewTool("list_package_variables",
+		mcp.WithDescription("List package-level (global) variables with their fully-qualified names, types and values. The runtime's and standard library's are left out unless asked for"),
+		mcp.WithString("filter",
+			mcp.Description("Regular expression the fully-qualified name must match (e.g., 'main\\.request')"),
+		),
+		mcp.WithString("package",
+			mcp.Description("Only include variables of packages whose import path starts with this prefix (e.g., 'github.com/me/app')"),
+		),
+		mcp.WithBoolean("includeRuntime",
+			mcp.Description("Include variables of the runtime and standard library (default: false)"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Depth for expanding nested structures (default: 1, max: 5)"),
+		),
+		mcp.WithNumber("maxStringLen",
+			mcp.Description("Maximum length of string values to load (default: 256)"),
+		),
+		mcp.WithNumber("maxArrayValues",
+			mcp.Description("Maximum number of slice, array or map elements to load (default: 64)"),
+		),
+	)
+
+	s.addTool(listPackageVariablesTool, s.ListPackageVariables)
+}
+
+func (s *MCPDebugServer) addFindVariablesTool() {
+	findVariablesTool := mcp.NewTool("find_variables",
+		mcp.WithDescription("Search the locals and arguments of a frame, including nested struct fields, map entries and slice elements, for names matching a regex. Returns each match's path, e.g. req.Header[\"Content-Type\"], which can be passed to eval_variable"),
+		mcp.WithString("pattern",
+			mcp.Required(),
+			mcp.Description("Regular expression to match names against (e.g., '(?i)user', '^ID$')"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame whose variables to search (default: 0, the current frame)"),
+		),
+		mcp.WithBoolean("searchValues",
+			mcp.Description("Also match the values of strings, numbers and booleans (default: false)"),
+		),
+	)
+
+	s.addTool(findVariablesTool, s.FindVariables)
+}
+
+func (s *MCPDebugServer) addSetVariableTool() {
+	setVarTool := mcp.NewTool("set_variable",
+		mcp.WithDescription("Set the value of a variable in the stopped program and return its new value"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Variable expression to assign to (e.g., 'count', 'person.Age')"),
+		),
+		mcp.WithString("value",
+			mcp.Required(),
+			mcp.Description("New value as a Go literal (e.g., '42', '\"Alice\"', 'true')"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame index to evaluate in (default: 0, the current frame)"),
+		),
+	)
+
+	s.addTool(setVarTool, s.SetVariable)
+}
+
+func (s *MCPDebugServer) addCallFunctionTool() {
+	callFunctionTool := mcp.NewTool("call_function",
+		mcp.WithDescription("Call a function in the stopped program and return its results, e.g. a String() method or a helper"),
+		mcp.WithString("expression",
+			mcp.Required(),
+			mcp.Description("Full call expression (e.g., 'fmt.Sprintf(\"%d\", requestCount)', 'user.String()')"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame to call from; Delve only supports frame 0 (default: 0)"),
+		),
+	)
+
+	s.addTool(callFunctionTool, s.CallFunction)
+}
+
+func (s *MCPDebugServer) addGetDebuggerOutputTool() {
+	outputTool := mcp.NewTool("get_debugger_output",
+		mcp.WithDescription("Get captured stdout and stderr from the debugged program"),
+	)
+
+	s.addTool(outputTool, s.GetDebuggerOutput)
+}
+
+func (s *MCPDebugServer) addListGoroutinesTool() {
+	listGoroutinesTool := mcp.NewTool("list_goroutines",
+		mcp.WithDescription("List goroutines with their status, location and labels"),
+		mcp.WithString("status",
+			mcp.Description("Only include goroutines with this status (e.g., 'running', 'waiting', 'syscall')"),
+		),
+		mcp.WithString("function",
+			mcp.Description("Only include goroutines whose current function name contains this substring"),
+		),
+		mcp.WithString("label",
+			mcp.Description("Only include goroutines with this pprof label, as 'key=value' or 'key'"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of goroutines to return (default: 100)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of matching goroutines to skip (default: 0)"),
+		),
+	)
+
+	s.addTool(listGoroutinesTool, s.ListGoroutines)
+}
+
+func (s *MCPDebugServer) addSwitchGoroutineTool() {
+	switchGoroutineTool := mcp.NewTool("switch_goroutine",
+		mcp.WithDescription("Select a goroutine so subsequent commands inspect its stack and variables"),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("ID of the goroutine to switch to"),
+		),
+	)
+
+	s.addTool(switchGoroutineTool, s.SwitchGoroutine)
+}
+
+func (s *MCPDebugServer) addCurrentGoroutineTool() {
+	currentGoroutineTool := mcp.NewTool("current_goroutine",
+		mcp.WithDescription("Show the goroutine commands operate on: its ID, status, labels, current and user-code locations, the go statement that created it and the thread running it"),
+	)
+
+	s.addTool(currentGoroutineTool, s.CurrentGoroutine)
+}
+
+func (s *MCPDebugServer) addChildGoroutinesTool() {
+	childGoroutinesTool := mcp.NewTool("child_goroutines",
+		mcp.WithDescription("List the live goroutines a goroutine spawned, directly or through the goroutines it spawned, with each one's creator, depth, current location and go statement, to trace the fan-out of a request. Needs a target built with Go 1.21 or later; goroutines started through one that has exited can't be traced back"),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("ID of the goroutine to list the descendants of"),
+		),
+	)
+
+	s.addTool(childGoroutinesTool, s.ChildGoroutines)
+}
+
+func (s *MCPDebugServer) addListThreadsTool() {
+	listThreadsTool := mcp.NewTool("list_threads",
+		mcp.WithDescription("List the OS threads of the process with their location, the goroutine each runs, if any, and which one is current. Threads outside Go source, such as in cgo calls, are shown at their PC"),
+	)
+
+	s.addTool(listThreadsTool, s.ListThreads)
+}
+
+func (s *MCPDebugServer) addSwitchThreadTool() {
+	switchThreadTool := mcp.NewTool("switch_thread",
+		mcp.WithDescription("Make an OS thread current so subsequent commands, such as backtrace, list_locals and read_registers, inspect it and the goroutine it runs"),
+		mcp.WithNumber("id",
+			mcp.Required(),
+			mcp.Description("ID of the thread to switch to, as given by list_threads"),
+		),
+	)
+
+	s.addTool(switchThreadTool, s.SwitchThread)
+}
+
+func (s *MCPDebugServer) addDescribeTool() {
+	describeTool := mcp.NewTool("describe",
+		mcp.WithDescription("Sum up where the program is stopped in one call: the current location, the top of the stack, the source around the current line and the local variables. Each section can be turned off"),
+		mcp.WithBoolean("stack",
+			mcp.Description("Include the innermost stack frames (default: true)"),
+		),
+		mcp.WithNumber("stackDepth",
+			mcp.Description("Number of stack frames to include (default: 5)"),
+		),
+		mcp.WithBoolean("source",
+			mcp.Description("Include the source around the current line (default: true)"),
+		),
+		mcp.WithNumber("contextLines",
+			mcp.Description("Number of source lines to show before and after the current line (default: 5)"),
+		),
+		mcp.WithBoolean("locals",
+			mcp.Description("Include the local variables of the current frame (default: true)"),
+		),
+		mcp.WithNumber("localsDepth",
+			mcp.Description("Depth for expanding nested local values (default: 1, max: 5)"),
+		),
+	)
+
+	s.addTool(describeTool, s.Describe)
+}
+
+func (s *MCPDebugServer) addBacktraceTool() {
+	backtraceTool := mcp.NewTool("backtrace",
+		mcp.WithDescription("Get the call stack of a goroutine"),
+		mcp.WithNumber("goroutine",
+			mcp.Description("Goroutine ID (default: the currently selected goroutine)"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Maximum number of frames to return (default: 50)"),
+		),
+		mcp.WithBoolean("includeArgs",
+			mcp.Description("Include function argument values for each frame (default: false)"),
+		),
+		withFollowPointersParam(),
+	)
+
+	s.addTool(backtraceTool, s.Backtrace)
+}
+
+func (s *MCPDebugServer) addListDeferredTool() {
+	listDeferredTool := mcp.NewTool("list_deferred",
+		mcp.WithDescription("List the calls a stack frame has deferred, in the order they will run (most recent first), with where each was deferred and its argument values when available"),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame index to read from (default: 0, the current frame)"),
+		),
+	)
+
+	s.addTool(listDeferredTool, s.ListDeferred)
+}
+
+func (s *MCPDebugServer) addDumpStacksTool() {
+	dumpStacksTool := mcp.NewTool("dump_stacks",
+		mcp.WithDescription("Dump the stacks of all goroutines, like SIGQUIT does, grouping goroutines with identical stacks (e.g., '42 goroutines in net/http.(*conn).serve')"),
+		mcp.WithNumber("depth",
+			mcp.Description("Maximum number of frames per goroutine (default: 20)"),
+		),
+		mcp.WithBoolean("includeGoroutines",
+			mcp.Description("Also return every goroutine's stack, not just the groups (default: false)"),
+		),
+	)
+
+	s.addTool(dumpStacksTool, s.DumpStacks)
+}
+
+func (s *MCPDebugServer) addDetectDeadlockTool() {
+	detectDeadlockTool := mcp.NewTool("detect_deadlock",
+		mcp.WithDescription("Look for goroutines waiting on each other in a cycle, or for locks and channels many goroutines are blocked on"),
+	)
+
+	s.addTool(detectDeadlockTool, s.DetectDeadlock)
+}
+
+func (s *MCPDebugServer) addListSourceTool() {
+	listSourceTool := mcp.NewTool("list_source",
+		mcp.WithDescription("Show source code around the current execution position or a given file and line"),
+		mcp.WithString("file",
+			mcp.Description("Path to the source file (default: file of the current position)"),
+		),
+		mcp.WithNumber("line",
+			mcp.Description("Line to center the listing on (default: the current line)"),
+		),
+		mcp.WithNumber("context",
+			mcp.Description("Number of lines to show before and after the line (default: 5)"),
+		),
+	)
+
+	s.addTool(listSourceTool, s.ListSource)
+}
+
+func (s *MCPDebugServer) addListFunctionsTool() {
+	listFunctionsTool := mcp.NewTool("list_functions",
+		mcp.WithDescription("List the functions of the program with the file and line defining each, to find valid names for breakpoints"),
+		mcp.WithString("filter",
+			mcp.Description("Regular expression the function name must match (e.g., 'Handler$')"),
+		),
+		mcp.WithString("package",
+			mcp.Description("Only include functions of packages whose import path starts with this prefix (e.g., 'github.com/me/app')"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of functions to return (default and maximum: 200)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of matching functions to skip (default: 0)"),
+		),
+	)
+
+	s.addTool(listFunctionsTool, s.ListFunctions)
+}
+
+func (s *MCPDebugServer) addListSourcesTool() {
+	listSourcesTool := mcp.NewTool("list_sources",
+		mcp.WithDescription("List the source files compiled into the program, to find files to set breakpoints in. Standard library files are left out unless includeStdlib is set"),
+		mcp.WithString("filter",
+			mcp.Description("Regular expression the file path must match (e.g., '/handlers/' or 'server\\.go$')"),
+		),
+		mcp.WithString("package",
+			mcp.Description("Only include files of packages whose import path starts with this prefix (e.g., 'github.com/me/app')"),
+		),
+		mcp.WithBoolean("includeStdlib",
+			mcp.Description("Include standard library files (default: false)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of files to return (default and maximum: 500)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of matching files to skip (default: 0)"),
+		),
+	)
+
+	s.addTool(listSourcesTool, s.ListSources)
+}
+
+func (s *MCPDebugServer) addDisassembleTool() {
+	disassembleTool := mcp.NewTool("disassemble",
+		mcp.WithDescription("Disassemble the current function or a PC range, marking the instruction at the current PC"),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame whose function is disassembled when no PC range is given (default: 0)"),
+		),
+		mcp.WithString("startPC",
+			mcp.Description("Start address, e.g. '0x4a1b2c' (default: the function of the selected frame)"),
+		),
+		mcp.WithString("endPC",
+			mcp.Description("End address; when omitted the whole function containing startPC is disassembled"),
+		),
+		mcp.WithNumber("line",
+			mcp.Description("Only show instructions generated for this source line"),
+		),
+	)
+
+	s.addTool(disassembleTool, s.Disassemble)
+}
+
+func (s *MCPDebugServer) addExamineMemoryTool() {
+	examineMemoryTool := mcp.NewTool("examine_memory",
+		mcp.WithDescription("Dump raw bytes of the target's memory in rows labelled with their offset"),
+		mcp.WithString("address",
+			mcp.Required(),
+			mcp.Description("Start address (e.g., '0xc000012340'), or an expression such as 'buf' or '&header' whose address is used"),
+		),
+		mcp.WithNumber("length",
+			mcp.Description("Number of bytes to read (default: 64, max: 4096)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'hex', 'ascii' or 'both' side by side (default: both)"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame index to evaluate an address expression in (default: 0)"),
+		),
+	)
+
+	s.addTool(examineMemoryTool, s.ExamineMemory)
+}
+
+func (s *MCPDebugServer) addAddressToSymbolTool() {
+	addressToSymbolTool := mcp.NewTool("address_to_symbol",
+		mcp.WithDescription("Resolve an address to the function it is code of, with the offset from the function's start and the source file:line, or to the package variable it lies in; other addresses, such as heap pointers, are reported as unknown. Use it to make sense of return addresses, function pointers and register values"),
+		mcp.WithString("address",
+			mcp.Required(),
+			mcp.Description("Address (e.g., '0x4a1b2c'), or an expression yielding one, such as a function value 'handler', a pointer 'p' or a uintptr"),
+		),
+		mcp.WithNumber("frame",
+			mcp.Description("Stack frame index to evaluate an address expression in (default: 0)"),
+		),
+	)
+
+	s.addTool(addressToSymbolTool, s.AddressToSymbol)
+}
+
+func (s *MCPDebugServer) addReadRegistersTool() {
+	readRegistersTool := mcp.NewTool("read_registers",
+		mcp.WithDescription("Read the CPU registers of a thread, grouped into general-purpose and floating-point"),
+		mcp.WithNumber("thread",
+			mcp.Description("Thread ID (default: the current thread)"),
+		),
+		mcp.WithBoolean("floating",
+			mcp.Description("Include floating-point and vector registers (default: false)"),
+		),
+	)
+
+	s.addTool(readRegistersTool, s.ReadRegisters)
+}
+
+func (s *MCPDebugServer) addSetRegisterTool() {
+	setRegisterTool := mcp.NewTool("set_register",
+		mcp.WithDescription("Set a CPU register of a thread. Delve cannot write registers, so this validates the request and reports the current value"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Register name, e.g. 'Rax'"),
+		),
+		mcp.WithString("value",
+			mcp.Required(),
+			mcp.Description("New integer value, e.g. '42' or '0x2a'"),
+		),
+		mcp.WithNumber("thread",
+			mcp.Description("Thread ID (default: the current thread)"),
+		),
+	)
+
+	s.addTool(setRegisterTool, s.SetRegister)
+}
+
+func (s *MCPDebugServer) addWriteStdinTool() {
+	writeStdinTool := mcp.NewTool("write_stdin",
+		mcp.WithDescription("Write input to the stdin of the launched program, e.g. to answer a prompt of an interactive program. Continue the program so it reads the input, and use read_output for its replies"),
+		mcp.WithString("data",
+			mcp.Required(),
+			mcp.Description("Text to write"),
+		),
+		mcp.WithBoolean("newline",
+			mcp.Description("Append a newline to the text, like pressing Enter (default: false)"),
+		),
+		mcp.WithBoolean("close",
+			mcp.Description("Close stdin after writing, so the program reads end of file (default: false)"),
+		),
+	)
+
+	s.addTool(writeStdinTool, s.WriteStdin)
+}
+
+func (s *MCPDebugServer) addReadOutputTool() {
+	readOutputTool := mcp.NewTool("read_output",
+		mcp.WithDescription("Read timestamped program output written since a given offset, for polling stdout or stderr"),
+		mcp.WithString("stream",
+			mcp.Description("Stream to read: 'stdout' or 'stderr' (default: 'stdout')"),
+		),
+		mcp.WithNumber("since",
+			mcp.Description("Offset returned as nextOffset by the previous read (default: 0, all buffered output)"),
+		),
+	)
+
+	s.addTool(readOutputTool, s.ReadOutput)
+}
+
+func (s *MCPDebugServer) addSetNextStatementTool() {
+	setNextStatementTool := mcp.NewTool("set_next_statement",
+		mcp.WithDescription("Move execution to another line of the current function, to skip a line or run a block again. Jumps outside the current function are refused. Delve cannot write the program counter, so this validates the target, explains what the jump would skip or re-run, and reports that it cannot be made"),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("Target line in the current function"),
+		),
+		mcp.WithString("file",
+			mcp.Description("Source file of the target line (default: the current file)"),
+		),
+	)
+
+	s.addTool(setNextStatementTool, s.SetNextStatement)
+}
+
+func (s *MCPDebugServer) addSetOutputFormatTool() {
+	setOutputFormatTool := mcp.NewTool("set_output_format",
+		mcp.WithDescription("Choose how tool results report locations and stop reasons: as prose like 'At main.go:12 in main.main', as structured fields (file, line, function, stop kind), or both"),
+		mcp.WithString("format",
+			mcp.Required(),
+			mcp.Description("'both' (default), 'prose' or 'structured'"),
+		),
+	)
+
+	s.addServerTool(setOutputFormatTool, s.SetOutputFormat)
+}
+
+func (s *MCPDebugServer) addSetFollowPointersTool() {
+	setFollowPointersTool := mcp.NewTool("set_follow_pointers",
+		mcp.WithDescription("Choose whether evaluations, variable listings and backtrace arguments of the session load what pointers point to. Following pointers shows the values behind them but pulls in whole graphs of linked structures; turned off, each pointer renders as its type and address, or nil, which keeps pointer-heavy values compact. Those tools can override it per call with followPointers"),
+		mcp.WithBoolean("follow",
+			mcp.Required(),
+			mcp.Description("true to follow pointers (default), false to load only their addresses"),
+		),
+	)
+
+	s.addTool(setFollowPointersTool, s.SetFollowPointers)
+}
+
+func (s *MCPDebugServer) addSetResponseLimitTool() {
+	setResponseLimitTool := mcp.NewTool("set_response_limit",
+		mcp.WithDescription("Set the largest tool result returned, in bytes. Larger results drop whole items, such as stack frames, goroutines or variable children, from their longest lists, and report truncated: true with the number of items omitted from each list. A single call can override it with maxResponseBytes"),
+		mcp.WithNumber("maxBytes",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("Result size limit in bytes, or 0 for no limit (default: %d, or MCP_MAX_RESPONSE_SIZE)", defaultMaxResponseBytes)),
+		),
+	)
+
+	s.addServerTool(setResponseLimitTool, s.SetResponseLimit)
+}
+
+// defaultCommandTimeout is how long commands that resume the program wait for it to stop
+const defaultCommandTimeout = 60 * time.Second
+
+// withTimeoutParam declares the timeout argument of commands that resume the program
+func withTimeoutParam() mcp.ToolOption {
+	return mcp.WithNumber("timeout",
+		mcp.Description("Seconds to wait for the program to stop before halting it (default: 60)"),
+	)
+}
+
+// defaultStepTimeout is how long step commands wait for the step to complete. A step is
+// normally quick, but a next over a call that loops or blocks never completes.
+const defaultStepTimeout = 10 * time.Second
+
+// withStepTimeoutParam declares the timeout argument of step commands
+func withStepTimeoutParam() mcp.ToolOption {
+	return mcp.WithNumber("timeout",
+		mcp.Description("Seconds to wait for the step to complete before halting the program, e.g. when stepping over a call that loops or blocks (default: 10). The error then names where the program was halted"),
+	)
+}
+
+// withFollowPointersParam declares the followPointers override of the tools that render values
+func withFollowPointersParam() mcp.ToolOption {
+	return mcp.WithBoolean("followPointers",
+		mcp.Description("Load what pointers point to, or render each pointer as its type and address only (default: as set with set_follow_pointers, which is to follow them)"),
+	)
+}
+
+// withBaseParam declares the base integers are rendered in by the eval tools
+func withBaseParam() mcp.ToolOption {
+	return mcp.WithNumber("base",
+		mcp.Description("Base to render integers in, including those in struct fields and elements: 10, 16 (e.g. 0x1f4), 2 (0b111110100) or 8 (0o764), handy for flags and bitmasks (default: 10). Negative values keep their sign, as in -0x1f4"),
+	)
+}
+
+// withCommandTimeout derives a context that ends after the request's timeout argument
+func withCommandTimeout(ctx context.Context, request mcp.CallToolRequest) (context.Context, context.CancelFunc) {
+	return withTimeoutArgument(ctx, request, defaultCommandTimeout)
+}
+
+// withStepTimeout derives a context that ends after the request's timeout argument, for a step
+func withStepTimeout(ctx context.Context, request mcp.CallToolRequest) (context.Context, context.CancelFunc) {
+	return withTimeoutArgument(ctx, request, defaultStepTimeout)
+}
+
+// withTimeoutArgument derives a context that ends after the request's timeout argument, or
+// after timeout when it has none
+func withTimeoutArgument(ctx context.Context, request mcp.CallToolRequest, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeoutVal, ok := request.Params.Arguments["timeout"]; ok && timeoutVal != nil {
+		if seconds := timeoutVal.(float64); seconds > 0 {
+			timeout = time.Duration(seconds * float64(time.Second))
+		}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func newErrorResult(format string, args ...interface{}) *mcp.CallToolResult {
+	result := mcp.NewToolResultText(fmt.Sprintf("Error: "+format, args...))
+	result.IsError = true
+	return result
+}
+
+func (s *MCPDebugServer) CreateSession(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received create_session request")
+
+	var id string
+	if idVal, ok := request.Params.Arguments["sessionID"]; ok && idVal != nil {
+		id = idVal.(string)
+	}
+
+	info, err := s.sessions.Create(id)
+	if err != nil {
+		return newErrorResult("failed to create session: %v", err), nil
+	}
+
+	return s.newToolResultJSON(types.SessionResponse{
+		Status:  "success",
+		Context: types.DebugContext{Timestamp: time.Now(), Operation: "create_session"},
+		Session: info,
+	})
+}
+
+func (s *MCPDebugServer) ListSessions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_sessions request")
+
+	return s.newToolResultJSON(types.SessionListResponse{
+		Status:   "success",
+		Context:  types.DebugContext{Timestamp: time.Now(), Operation: "list_sessions"},
+		Sessions: s.sessions.List(),
+	})
+}
+
+func (s *MCPDebugServer) Status(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received status request")
+
+	id := debugger.DefaultSessionID
+	if idVal, ok := request.Params.Arguments["sessionID"]; ok && idVal != nil && idVal.(string) != "" {
+		id = idVal.(string)
+	}
+
+	client, err := s.sessions.Get(id)
+	if err != nil {
+		state := "no such session"
+		if errors.Is(err, debugger.ErrShutDown) {
+			state = "shut down"
+		}
+		// An unknown session is a state to report like any other
+		return s.newToolResultJSON(types.StatusResponse{
+			Status:        "success",
+			Context:       types.DebugContext{Timestamp: time.Now(), Operation: "status"},
+			SessionID:     id,
+			State:         state,
+			Message:       err.Error(),
+			DelveVersion:  debugger.DelveVersion(),
+			ServerVersion: s.version,
+		})
+	}
+
+	response := client.Status()
+	response.SessionID = id
+	response.ServerVersion = s.version
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) CloseSession(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received close_session request")
+
+	id := request.Params.Arguments["sessionID"].(string)
+
+	info, err := s.sessions.Close(id)
+	if err != nil {
+		return newErrorResult("failed to close session: %v", err), nil
+	}
+
+	return s.newToolResultJSON(types.SessionResponse{
+		Status:  "success",
+		Context: types.DebugContext{Timestamp: time.Now(), Operation: "close_session"},
+		Session: info,
+	})
+}
+
+func (s *MCPDebugServer) Shutdown(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received shutdown request")
+
+	return s.newToolResultJSON(s.CloseSessions())
+}
+
+// CloseSessions shuts every debug session down, so that no program the server launched
+// outlives it
+func (s *MCPDebugServer) CloseSessions() types.ShutdownResponse {
+	return s.sessions.Shutdown()
+}
+
+func (s *MCPDebugServer) Launch(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received launch request")
+
+	program := request.Params.Arguments["program"].(string)
+
+	var args []string
+	if argsVal, ok := request.Params.Arguments["args"]; ok && argsVal != nil {
+		argsArray := argsVal.([]interface{})
+		args = make([]string, len(argsArray))
+		for i, arg := range argsArray {
+			args[i] = fmt.Sprintf("%v", arg)
+		}
+	}
+
+	var env []string
+	if envVal, ok := request.Params.Arguments["env"]; ok && envVal != nil {
+		envArray := envVal.([]interface{})
+		env = make([]string, len(envArray))
+		for i, kv := range envArray {
+			env[i] = fmt.Sprintf("%v", kv)
+		}
+	}
+
+	var workingDir string
+	if workingDirVal, ok := request.Params.Arguments["workingDir"]; ok && workingDirVal != nil {
+		workingDir = workingDirVal.(string)
+	}
+
+	stopAtMain := true
+	if stopVal, ok := request.Params.Arguments["stopAtMain"]; ok && stopVal != nil {
+		stopAtMain = stopVal.(bool)
+	}
+
+	client := s.client(ctx)
+	response := client.Launch(program, args, env, workingDir)
+	if stopAtMain && response.Status == "success" {
+		response = client.RunLaunchToMain(ctx, response)
+	}
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) OpenCore(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received open_core request")
+
+	executable := request.Params.Arguments["executable"].(string)
+	core := request.Params.Arguments["core"].(string)
+
+	response := s.client(ctx).OpenCore(executable, core)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Attach(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received attach request")
+
+	if pidVal, ok := request.Params.Arguments["pid"]; ok && pidVal != nil {
+		pid := int(pidVal.(float64))
+
+		response := s.client(ctx).AttachToProcess(pid)
+
+		return s.newToolResultJSON(response)
+	}
+
+	nameVal, ok := request.Params.Arguments["name"]
+	if !ok || nameVal == nil {
+		return newErrorResult("either pid or name is required"), nil
+	}
+
+	var exact bool
+	if exactVal, ok := request.Params.Arguments["exact"]; ok && exactVal != nil {
+		exact = exactVal.(bool)
+	}
+
+	response := s.client(ctx).AttachByName(nameVal.(string), exact)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ConnectRemote(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received connect_remote request")
+
+	address := request.Params.Arguments["address"].(string)
+
+	var keepTarget bool
+	if keepVal, ok := request.Params.Arguments["keepTarget"]; ok && keepVal != nil {
+		keepTarget = keepVal.(bool)
+	}
+
+	policy := debugger.DefaultReconnectPolicy
+	if attemptsVal, ok := request.Params.Arguments["reconnectAttempts"]; ok && attemptsVal != nil {
+		attempts := attemptsVal.(float64)
+		if attempts < 0 {
+			return newErrorResult("reconnectAttempts must not be negative"), nil
+		}
+		policy.Attempts = int(attempts)
+	}
+	if backoffVal, ok := request.Params.Arguments["reconnectBackoff"]; ok && backoffVal != nil {
+		seconds := backoffVal.(float64)
+		if seconds < 0 {
+			return newErrorResult("reconnectBackoff must not be negative"), nil
+		}
+		policy.Backoff = time.Duration(seconds * float64(time.Second))
+	}
+
+	response := s.client(ctx).ConnectRemote(address, keepTarget, policy)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Close(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received close request")
+
+	response, err := s.client(ctx).Close()
 	if err != nil {
 		logger.Error("Failed to close debug session", "error", err)
 		return newErrorResult("failed to close debug session: %v", err), nil
 	}
 
-	s.debugClient = debugger.NewClient()
+	s.sessions.Reset(s.session(ctx).id)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Detach(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received detach request")
+
+	kill := s.client(ctx).DetachKillsByDefault()
+	if killVal, ok := request.Params.Arguments["kill"]; ok && killVal != nil {
+		kill = killVal.(bool)
+	}
+
+	response := s.client(ctx).Detach(kill)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Restart(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received restart request")
+
+	var rebuild bool
+	if rebuildVal, ok := request.Params.Arguments["rebuild"]; ok && rebuildVal != nil {
+		rebuild = rebuildVal.(bool)
+	}
+
+	response := s.client(ctx).Restart(rebuild)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SetLaunchArgs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_launch_args request")
+
+	var args []string
+	if argsVal, ok := request.Params.Arguments["args"]; ok && argsVal != nil {
+		argsArray := argsVal.([]interface{})
+		args = make([]string, len(argsArray))
+		for i, arg := range argsArray {
+			args[i] = fmt.Sprintf("%v", arg)
+		}
+	}
+
+	response := s.client(ctx).SetLaunchArgs(args)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SetLaunchEnv(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_launch_env request")
+
+	var env []string
+	if envVal, ok := request.Params.Arguments["env"]; ok && envVal != nil {
+		envArray := envVal.([]interface{})
+		env = make([]string, len(envArray))
+		for i, kv := range envArray {
+			env[i] = fmt.Sprintf("%v", kv)
+		}
+	}
+
+	response := s.client(ctx).SetLaunchEnv(env)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SetBreakpoint(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_breakpoint request")
+
+	var location string
+	if locationVal, ok := request.Params.Arguments["location"]; ok && locationVal != nil {
+		location = locationVal.(string)
+	}
+
+	var file string
+	var line int
+	if location == "" {
+		fileVal, fileOk := request.Params.Arguments["file"].(string)
+		lineVal, lineOk := request.Params.Arguments["line"].(float64)
+		if !fileOk || !lineOk {
+			return newErrorResult("either location or both file and line are required"), nil
+		}
+		file, line = fileVal, int(lineVal)
+	}
+
+	var opts debugger.BreakpointOptions
+	if condVal, ok := request.Params.Arguments["condition"]; ok && condVal != nil {
+		opts.Condition = condVal.(string)
+		if strings.TrimSpace(opts.Condition) == "" {
+			return newErrorResult("condition must be a non-empty Go boolean expression"), nil
+		}
+	}
+
+	if hitCondVal, ok := request.Params.Arguments["hitCondition"]; ok && hitCondVal != nil {
+		opts.HitCondition = hitCondVal.(string)
+	}
+
+	if labelVal, ok := request.Params.Arguments["goroutineLabel"]; ok && labelVal != nil {
+		opts.GoroutineLabel = labelVal.(string)
+	}
+
+	if ignoreVal, ok := request.Params.Arguments["ignoreCount"]; ok && ignoreVal != nil {
+		opts.IgnoreCount = int(ignoreVal.(float64))
+	}
+
+	if captureVal, ok := request.Params.Arguments["captureExprs"]; ok && captureVal != nil {
+		for _, expr := range captureVal.([]interface{}) {
+			opts.CaptureExprs = append(opts.CaptureExprs, fmt.Sprintf("%v", expr))
+		}
+	}
+
+	if pinVal, ok := request.Params.Arguments["pinToFirstGoroutine"]; ok && pinVal != nil {
+		opts.PinToFirstGoroutine = pinVal.(bool)
+	}
+
+	if strictVal, ok := request.Params.Arguments["strict"]; ok && strictVal != nil {
+		opts.Strict = strictVal.(bool)
+	}
+
+	if onReturnVal, ok := request.Params.Arguments["onReturn"]; ok && onReturnVal != nil {
+		opts.OnReturn = onReturnVal.(bool)
+	}
+
+	if location != "" {
+		return s.newToolResultJSON(s.client(ctx).SetBreakpointAtLocation(location, opts))
+	}
+
+	breakpoint := s.client(ctx).SetBreakpoint(file, line, opts)
+
+	return s.newToolResultJSON(breakpoint)
+}
+
+func (s *MCPDebugServer) SetBreakpoints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_breakpoints request")
+
+	items, ok := request.Params.Arguments["breakpoints"].([]interface{})
+	if !ok {
+		return newErrorResult("breakpoints must be an array of breakpoint objects"), nil
+	}
+
+	specs := make([]debugger.BreakpointSpec, 0, len(items))
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return newErrorResult("breakpoint %d must be an object with location or file and line", i), nil
+		}
+
+		var spec debugger.BreakpointSpec
+		if v, ok := fields["location"].(string); ok {
+			spec.Location = v
+		}
+		if v, ok := fields["file"].(string); ok {
+			spec.File = v
+		}
+		if v, ok := fields["line"].(float64); ok {
+			spec.Line = int(v)
+		}
+		if v, ok := fields["condition"].(string); ok {
+			spec.Condition = v
+		}
+		if v, ok := fields["hitCondition"].(string); ok {
+			spec.HitCondition = v
+		}
+		if v, ok := fields["goroutineLabel"].(string); ok {
+			spec.GoroutineLabel = v
+		}
+		if v, ok := fields["ignoreCount"].(float64); ok {
+			spec.IgnoreCount = int(v)
+		}
+		if v, ok := fields["pinToFirstGoroutine"].(bool); ok {
+			spec.PinToFirstGoroutine = v
+		}
+		if v, ok := fields["captureExprs"].([]interface{}); ok {
+			for _, expr := range v {
+				spec.CaptureExprs = append(spec.CaptureExprs, fmt.Sprintf("%v", expr))
+			}
+		}
+		if v, ok := fields["strict"].(bool); ok {
+			spec.Strict = v
+		}
+		if v, ok := fields["onReturn"].(bool); ok {
+			spec.OnReturn = v
+		}
+		specs = append(specs, spec)
+	}
+
+	response := s.client(ctx).SetBreakpoints(specs)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SetTypeBreakpoints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_type_breakpoints request")
+
+	typeName := request.Params.Arguments["type"].(string)
+
+	var methodFilter string
+	if filterVal, ok := request.Params.Arguments["methodFilter"]; ok && filterVal != nil {
+		methodFilter = filterVal.(string)
+	}
+
+	response := s.client(ctx).SetBreakpointsOnType(typeName, methodFilter)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) BreakOnError(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received break_on_error request")
+
+	functionPattern := request.Params.Arguments["functionPattern"].(string)
+
+	var errorMatch string
+	if matchVal, ok := request.Params.Arguments["errorMatch"]; ok && matchVal != nil {
+		errorMatch = matchVal.(string)
+	}
+
+	response := s.client(ctx).BreakOnError(functionPattern, errorMatch)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ExportBreakpoints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received export_breakpoints request")
+
+	var file string
+	if fileVal, ok := request.Params.Arguments["file"]; ok && fileVal != nil {
+		file = fileVal.(string)
+	}
+
+	response := s.client(ctx).SaveBreakpoints(file)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ImportBreakpoints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received import_breakpoints request")
+
+	var data, file string
+	if dataVal, ok := request.Params.Arguments["data"]; ok && dataVal != nil {
+		data = dataVal.(string)
+	}
+	if fileVal, ok := request.Params.Arguments["file"]; ok && fileVal != nil {
+		file = fileVal.(string)
+	}
+
+	if (data == "") == (file == "") {
+		return newErrorResult("exactly one of data or file is required"), nil
+	}
+
+	if file != "" {
+		return s.newToolResultJSON(s.client(ctx).LoadBreakpoints(file))
+	}
+
+	response := s.client(ctx).ImportBreakpoints([]byte(data))
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ToggleBreakpoint(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received toggle_breakpoint request")
+
+	id := int(request.Params.Arguments["id"].(float64))
+	enabled := request.Params.Arguments["enabled"].(bool)
+
+	var response types.BreakpointResponse
+	if enabled {
+		response = s.client(ctx).EnableBreakpoint(id)
+	} else {
+		response = s.client(ctx).DisableBreakpoint(id)
+	}
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SetIgnoreCount(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_ignore_count request")
+
+	id := int(request.Params.Arguments["id"].(float64))
+	count := int(request.Params.Arguments["count"].(float64))
+
+	response := s.client(ctx).SetIgnoreCount(id, count)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ArmBreakpointAfter(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received arm_breakpoint_after request")
+
+	triggerID := int(request.Params.Arguments["triggerID"].(float64))
+	location := request.Params.Arguments["location"].(string)
+
+	var oneShot bool
+	if oneShotVal, ok := request.Params.Arguments["oneShot"]; ok && oneShotVal != nil {
+		oneShot = oneShotVal.(bool)
+	}
+
+	response := s.client(ctx).ArmBreakpointAfter(triggerID, location, oneShot)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) AmendBreakpointCondition(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received amend_breakpoint_condition request")
+
+	id := int(request.Params.Arguments["id"].(float64))
+	condition := request.Params.Arguments["condition"].(string)
+
+	response := s.client(ctx).AmendBreakpointCondition(id, condition)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ResetHitCount(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received reset_hit_count request")
+
+	id := int(request.Params.Arguments["id"].(float64))
+
+	response := s.client(ctx).ResetHitCount(id)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ListBreakpoints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_breakpoints request")
+
+	var includeInternal bool
+	if includeVal, ok := request.Params.Arguments["includeInternal"]; ok && includeVal != nil {
+		includeInternal = includeVal.(bool)
+	}
+
+	response := s.client(ctx).ListBreakpoints(includeInternal)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) RemoveBreakpoint(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received remove_breakpoint request")
+
+	id := int(request.Params.Arguments["id"].(float64))
+
+	response := s.client(ctx).RemoveBreakpoint(id)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SetWatchpoint(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_watchpoint request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var watchType string
+	if typeVal, ok := request.Params.Arguments["type"]; ok && typeVal != nil {
+		watchType = typeVal.(string)
+	}
+
+	response := s.client(ctx).SetWatchpoint(expr, watchType)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SetTracepoint(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_tracepoint request")
+
+	file := request.Params.Arguments["file"].(string)
+	line := int(request.Params.Arguments["line"].(float64))
+
+	var expressions []string
+	if exprVal, ok := request.Params.Arguments["expressions"]; ok && exprVal != nil {
+		exprArray := exprVal.([]interface{})
+		expressions = make([]string, len(exprArray))
+		for i, expr := range exprArray {
+			expressions[i] = fmt.Sprintf("%v", expr)
+		}
+	}
+
+	var condition string
+	if condVal, ok := request.Params.Arguments["condition"]; ok && condVal != nil {
+		condition = condVal.(string)
+	}
+
+	var hitCondition string
+	if hitCondVal, ok := request.Params.Arguments["hitCondition"]; ok && hitCondVal != nil {
+		hitCondition = hitCondVal.(string)
+	}
+
+	response := s.client(ctx).SetTracepoint(file, line, condition, hitCondition, expressions)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) BreakOnPanic(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received break_on_panic request")
+
+	enabled := request.Params.Arguments["enabled"].(bool)
+
+	fatal := enabled
+	if fatalVal, ok := request.Params.Arguments["fatal"]; ok && fatalVal != nil {
+		fatal = fatalVal.(bool)
+	}
+
+	response := s.client(ctx).SetBreakOnPanic(enabled, fatal)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) DiffCapture(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received diff_capture request")
+
+	id := int(request.Params.Arguments["id"].(float64))
+
+	response := s.client(ctx).DiffCapture(id)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) InterHitTimings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received inter_hit_timings request")
+
+	id := int(request.Params.Arguments["id"].(float64))
+
+	response := s.client(ctx).InterHitTimings(id)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ReadTrace(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received read_trace request")
+
+	var since int64
+	if sinceVal, ok := request.Params.Arguments["since"]; ok && sinceVal != nil {
+		since = int64(sinceVal.(float64))
+	}
+
+	var breakpointID int
+	if bpVal, ok := request.Params.Arguments["breakpoint"]; ok && bpVal != nil {
+		breakpointID = int(bpVal.(float64))
+	}
+
+	response := s.client(ctx).ReadTrace(since, breakpointID)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) DebugSourceFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received debug_source_file request")
+
+	file := request.Params.Arguments["file"].(string)
+
+	var args []string
+	if argsVal, ok := request.Params.Arguments["args"]; ok && argsVal != nil {
+		argsArray := argsVal.([]interface{})
+		args = make([]string, len(argsArray))
+		for i, arg := range argsArray {
+			args[i] = fmt.Sprintf("%v", arg)
+		}
+	}
+
+	response := s.client(ctx).DebugSourceFile(file, args)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Continue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received continue request")
+
+	ctx, cancel := withCommandTimeout(ctx, request)
+	defer cancel()
+
+	state := s.client(ctx).Continue(ctx)
+	return s.newToolResultJSON(state)
+}
+
+func (s *MCPDebugServer) ContinueAsync(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received continue_async request")
+
+	response := s.client(ctx).ContinueAsync()
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) WaitForStop(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received wait_for_stop request")
+
+	timeout := 10 * time.Second
+	if timeoutVal, ok := request.Params.Arguments["timeout"]; ok && timeoutVal != nil {
+		seconds := timeoutVal.(float64)
+		if seconds < 0 {
+			return newErrorResult("timeout must not be negative"), nil
+		}
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+
+	response := s.client(ctx).WaitForStop(timeout)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Halt(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received halt request")
+
+	response := s.client(ctx).Halt()
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ContinueToLine(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received continue_to_line request")
+
+	file := request.Params.Arguments["file"].(string)
+	line := int(request.Params.Arguments["line"].(float64))
+
+	ctx, cancel := withCommandTimeout(ctx, request)
+	defer cancel()
+
+	response := s.client(ctx).ContinueToLine(ctx, file, line)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) RunUntilReturns(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received run_until_returns request")
+
+	function := request.Params.Arguments["function"].(string)
+	condition := request.Params.Arguments["condition"].(string)
+
+	var maxEvaluations int
+	if maxVal, ok := request.Params.Arguments["maxEvaluations"]; ok && maxVal != nil {
+		maxEvaluations = int(maxVal.(float64))
+	}
+
+	ctx, cancel := withCommandTimeout(ctx, request)
+	defer cancel()
+
+	response := s.client(ctx).RunUntilReturns(ctx, function, condition, maxEvaluations)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) RunToCompletion(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received run_to_completion request")
+
+	autoContinue := true
+	if autoVal, ok := request.Params.Arguments["autoContinue"]; ok && autoVal != nil {
+		autoContinue = autoVal.(bool)
+	}
+
+	var maxHits int
+	if maxVal, ok := request.Params.Arguments["maxHits"]; ok && maxVal != nil {
+		maxHits = int(maxVal.(float64))
+	}
+
+	ctx, cancel := withCommandTimeout(ctx, request)
+	defer cancel()
+
+	response := s.client(ctx).RunToCompletion(ctx, autoContinue, maxHits)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) LineCoverage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received line_coverage request")
+
+	file := request.Params.Arguments["file"].(string)
+
+	var lines []int
+	if linesVal, ok := request.Params.Arguments["lines"]; ok && linesVal != nil {
+		for _, line := range linesVal.([]interface{}) {
+			if n, ok := line.(float64); ok {
+				lines = append(lines, int(n))
+			}
+		}
+	}
+
+	ctx, cancel := withCommandTimeout(ctx, request)
+	defer cancel()
+
+	response := s.client(ctx).LineCoverage(ctx, file, lines)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) WatchGoroutineCount(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received watch_goroutine_count request")
+
+	var direction string
+	if directionVal, ok := request.Params.Arguments["direction"]; ok && directionVal != nil {
+		direction = directionVal.(string)
+	}
+
+	thresholdVal, hasThreshold := request.Params.Arguments["threshold"]
+	hasThreshold = hasThreshold && thresholdVal != nil
+	deltaVal, hasDelta := request.Params.Arguments["delta"]
+	hasDelta = hasDelta && deltaVal != nil
+
+	var threshold int
+	switch {
+	case hasThreshold && hasDelta:
+		return newErrorResult("give either threshold or delta, not both"), nil
+	case hasThreshold:
+		threshold = int(thresholdVal.(float64))
+		if direction == "" {
+			direction = "above"
+		}
+		if direction != "above" && direction != "below" {
+			return newErrorResult("a threshold goes with direction 'above' or 'below'; use delta for %q", direction), nil
+		}
+	case hasDelta:
+		threshold = int(deltaVal.(float64))
+		if direction == "" {
+			direction = "grow"
+		}
+		if direction != "grow" && direction != "shrink" && direction != "change" {
+			return newErrorResult("a delta goes with direction 'grow', 'shrink' or 'change'; use threshold for %q", direction), nil
+		}
+	default:
+		return newErrorResult("threshold or delta is required"), nil
+	}
+
+	var maxWait time.Duration
+	if maxWaitVal, ok := request.Params.Arguments["maxWait"]; ok && maxWaitVal != nil {
+		seconds := maxWaitVal.(float64)
+		if seconds < 0 {
+			return newErrorResult("maxWait must not be negative"), nil
+		}
+		maxWait = time.Duration(seconds * float64(time.Second))
+	}
+
+	response := s.client(ctx).WatchGoroutineCount(ctx, threshold, direction, maxWait)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Step(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received step request")
+
+	ctx, cancel := withStepTimeout(ctx, request)
+	defer cancel()
+
+	state := s.client(ctx).Step(ctx)
+
+	return s.newToolResultJSON(state)
+}
+
+func (s *MCPDebugServer) StepOver(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received step_over request")
+
+	ctx, cancel := withStepTimeout(ctx, request)
+	defer cancel()
+
+	state := s.client(ctx).StepOver(ctx)
+
+	return s.newToolResultJSON(state)
+}
+
+func (s *MCPDebugServer) StepIsolated(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received step_isolated request")
+
+	ctx, cancel := withStepTimeout(ctx, request)
+	defer cancel()
+
+	state := s.client(ctx).StepIsolated(ctx)
+
+	return s.newToolResultJSON(state)
+}
+
+func (s *MCPDebugServer) StepOut(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received step_out request")
+
+	ctx, cancel := withStepTimeout(ctx, request)
+	defer cancel()
+
+	state := s.client(ctx).StepOut(ctx)
+	return s.newToolResultJSON(state)
+}
+
+func (s *MCPDebugServer) LaunchRecording(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received launch_recording request")
+
+	program := request.Params.Arguments["program"].(string)
+
+	var args []string
+	if argsVal, ok := request.Params.Arguments["args"]; ok && argsVal != nil {
+		argsArray := argsVal.([]interface{})
+		args = make([]string, len(argsArray))
+		for i, arg := range argsArray {
+			args[i] = fmt.Sprintf("%v", arg)
+		}
+	}
+
+	response := s.client(ctx).LaunchRecording(program, args)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ReverseStep(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received reverse_step request")
+
+	ctx, cancel := withCommandTimeout(ctx, request)
+	defer cancel()
+
+	response := s.client(ctx).ReverseStep(ctx)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ReverseNext(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received reverse_next request")
+
+	ctx, cancel := withCommandTimeout(ctx, request)
+	defer cancel()
+
+	response := s.client(ctx).ReverseNext(ctx)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ReverseContinue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received reverse_continue request")
+
+	ctx, cancel := withCommandTimeout(ctx, request)
+	defer cancel()
+
+	response := s.client(ctx).ReverseContinue(ctx)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) HistoryOfVariable(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received history_of_variable request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var limit int
+	if limitVal, ok := request.Params.Arguments["limit"]; ok && limitVal != nil {
+		limit = int(limitVal.(float64))
+	}
+
+	ctx, cancel := withCommandTimeout(ctx, request)
+	defer cancel()
+
+	response := s.client(ctx).HistoryOfVariable(ctx, expr, limit)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) StepInstruction(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received step_instruction request")
+
+	var reverse bool
+	if reverseVal, ok := request.Params.Arguments["reverse"]; ok && reverseVal != nil {
+		reverse = reverseVal.(bool)
+	}
+
+	ctx, cancel := withStepTimeout(ctx, request)
+	defer cancel()
+
+	response := s.client(ctx).StepInstruction(ctx, reverse)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) StepToNextCall(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received step_to_next_call request")
+
+	var maxSteps int
+	if maxVal, ok := request.Params.Arguments["maxSteps"]; ok && maxVal != nil {
+		maxSteps = int(maxVal.(float64))
+	}
+
+	ctx, cancel := withCommandTimeout(ctx, request)
+	defer cancel()
+
+	response := s.client(ctx).StepToNextCall(ctx, maxSteps)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) EvalVariable(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received evaluate_variable request")
+
+	name := request.Params.Arguments["name"].(string)
+
+	depth := loadDepthArgument(request)
+
+	var maxElements, maxStringLen int
+	if v, ok := request.Params.Arguments["maxElements"]; ok && v != nil {
+		maxElements = int(v.(float64))
+	}
+	if v, ok := request.Params.Arguments["maxStringLen"]; ok && v != nil {
+		maxStringLen = int(v.(float64))
+	}
+
+	response := s.client(ctx).EvalVariable(name, depth, maxElements, maxStringLen, callStringersArgument(request), followPointersArgument(request), baseArgument(request))
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ListLocals(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_locals request")
+
+	frame, opts := variableListArguments(request)
+	response := s.client(ctx).ListLocals(frame, opts)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ListArgs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_args request")
+
+	frame, opts := variableListArguments(request)
+	response := s.client(ctx).ListArgs(frame, opts)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) AllFramesLocals(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received all_frames_locals request")
+
+	var frames int
+	if framesVal, ok := request.Params.Arguments["frames"]; ok && framesVal != nil {
+		frames = int(framesVal.(float64))
+	}
+
+	var includeRuntime bool
+	if v, ok := request.Params.Arguments["includeRuntime"]; ok && v != nil {
+		includeRuntime = v.(bool)
+	}
+
+	_, opts := variableListArguments(request)
+	opts.CallStringers = false
+
+	response := s.client(ctx).AllFramesLocals(frames, includeRuntime, opts)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ArgsWithRegisters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received args_with_registers request")
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).ArgsWithRegisters(frame)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ListPackageVariables(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_package_variables request")
+
+	var filter string
+	if filterVal, ok := request.Params.Arguments["filter"]; ok && filterVal != nil {
+		filter = filterVal.(string)
+	}
+
+	opts := debugger.PackageVariableOptions{Depth: 1}
+	if v, ok := request.Params.Arguments["package"]; ok && v != nil {
+		opts.Package = v.(string)
+	}
+	if v, ok := request.Params.Arguments["includeRuntime"]; ok && v != nil {
+		opts.IncludeRuntime = v.(bool)
+	}
+	if v, ok := request.Params.Arguments["depth"]; ok && v != nil {
+		opts.Depth = int(v.(float64))
+	}
+	if v, ok := request.Params.Arguments["maxStringLen"]; ok && v != nil {
+		opts.MaxStringLen = int(v.(float64))
+	}
+	if v, ok := request.Params.Arguments["maxArrayValues"]; ok && v != nil {
+		opts.MaxArrayValues = int(v.(float64))
+	}
+
+	response := s.client(ctx).ListPackageVariables(filter, opts)
+
+	return s.newToolResultJSON(response)
+}
+
+// loadDepthArgument reads how many levels of nested values to load, from maxDepth or its
+// older name depth, 1 when neither is given. The debugger caps it.
+func loadDepthArgument(request mcp.CallToolRequest) int {
+	for _, name := range []string{"maxDepth", "depth"} {
+		if v, ok := request.Params.Arguments[name]; ok && v != nil {
+			return int(v.(float64))
+		}
+	}
+	return 1
+}
+
+// variableListArguments reads the arguments shared by list_locals and list_args
+func variableListArguments(request mcp.CallToolRequest) (int, debugger.VariableListOptions) {
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	opts := debugger.VariableListOptions{Depth: loadDepthArgument(request)}
+	if v, ok := request.Params.Arguments["hideShadowed"]; ok && v != nil {
+		opts.HideShadowed = v.(bool)
+	}
+	if v, ok := request.Params.Arguments["hideBlank"]; ok && v != nil {
+		opts.HideBlank = v.(bool)
+	}
+	if v, ok := request.Params.Arguments["maxStringLen"]; ok && v != nil {
+		opts.MaxStringLen = int(v.(float64))
+	}
+	if v, ok := request.Params.Arguments["maxArrayValues"]; ok && v != nil {
+		opts.MaxArrayValues = int(v.(float64))
+	}
+	opts.CallStringers = callStringersArgument(request)
+	opts.FollowPointers = followPointersArgument(request)
+
+	return frame, opts
+}
+
+// callStringersArgument reads the callStringers flag of the eval and variable listing tools
+func callStringersArgument(request mcp.CallToolRequest) bool {
+	if v, ok := request.Params.Arguments["callStringers"]; ok && v != nil {
+		return v.(bool)
+	}
+	return false
+}
+
+// followPointersArgument reads the followPointers override of the eval and variable listing
+// tools, nil when the session's setting applies
+func followPointersArgument(request mcp.CallToolRequest) *bool {
+	if v, ok := request.Params.Arguments["followPointers"]; ok && v != nil {
+		follow := v.(bool)
+		return &follow
+	}
+	return nil
+}
+
+// baseArgument reads the base to render integers in, 0 when the request leaves it out
+func baseArgument(request mcp.CallToolRequest) int {
+	if v, ok := request.Params.Arguments["base"]; ok && v != nil {
+		return int(v.(float64))
+	}
+	return 0
+}
+
+func (s *MCPDebugServer) EvalExpression(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received eval_expression request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).Eval(expr, frame, loadDepthArgument(request), callStringersArgument(request), followPointersArgument(request), baseArgument(request))
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Assert(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received assert request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).Assert(expr, frame)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) EvalGoroutines(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received eval_goroutines request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var filter debugger.GoroutineFilter
+	if statusVal, ok := request.Params.Arguments["status"]; ok && statusVal != nil {
+		filter.Status = statusVal.(string)
+	}
+
+	var limit int
+	if limitVal, ok := request.Params.Arguments["limit"]; ok && limitVal != nil {
+		limit = int(limitVal.(float64))
+	}
+
+	response := s.client(ctx).EvalAcrossGoroutines(expr, filter, limit)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) WhatIs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received whatis request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).WhatIs(expr, frame)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) DeclarationOf(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received declaration_of request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).DeclarationOf(expr, frame)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) InspectInterface(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received inspect_interface request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).InspectInterface(expr, frame)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) InspectChannel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received inspect_channel request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).InspectChannel(expr, frame)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) InspectMutex(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received inspect_mutex request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).InspectMutex(expr, frame)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) GetElement(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received get_element request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	depth := 1
+	if depthVal, ok := request.Params.Arguments["depth"]; ok && depthVal != nil {
+		depth = int(depthVal.(float64))
+	}
+
+	indexVal, hasIndex := request.Params.Arguments["index"]
+	hasIndex = hasIndex && indexVal != nil
+	keyVal, hasKey := request.Params.Arguments["key"]
+	hasKey = hasKey && keyVal != nil && keyVal.(string) != ""
+
+	var response types.ElementResponse
+	switch {
+	case hasIndex && hasKey:
+		return newErrorResult("give either index or key, not both"), nil
+	case hasIndex:
+		response = s.client(ctx).GetElement(expr, int(indexVal.(float64)), frame, depth)
+	case hasKey:
+		response = s.client(ctx).GetMapElement(expr, keyVal.(string), frame, depth)
+	default:
+		response = s.client(ctx).GetLength(expr, frame)
+	}
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) TabulateSlice(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received tabulate_slice request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var fields []string
+	if fieldsVal, ok := request.Params.Arguments["fields"]; ok && fieldsVal != nil {
+		for _, field := range fieldsVal.([]interface{}) {
+			fields = append(fields, fmt.Sprintf("%v", field))
+		}
+	}
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).TabulateSlice(expr, fields, frame)
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SnapshotVariable(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received snapshot_variable request")
+
+	name := request.Params.Arguments["name"].(string)
+	expr := request.Params.Arguments["expression"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).SnapshotVariable(name, expr, frame)
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) CompareSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received compare_snapshot request")
+
+	name := request.Params.Arguments["name"].(string)
+
+	var expr string
+	if exprVal, ok := request.Params.Arguments["expression"]; ok && exprVal != nil {
+		expr = exprVal.(string)
+	}
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).CompareSnapshot(name, expr, frame)
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ListSnapshots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_snapshots request")
+
+	response := s.client(ctx).ListSnapshots()
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ClearSnapshots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received clear_snapshots request")
+
+	var name string
+	if nameVal, ok := request.Params.Arguments["name"]; ok && nameVal != nil {
+		name = nameVal.(string)
+	}
+
+	response := s.client(ctx).ClearSnapshots(name)
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) BuildInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received build_info request")
+
+	var program string
+	if programVal, ok := request.Params.Arguments["program"]; ok && programVal != nil {
+		program = programVal.(string)
+	}
+
+	response := s.client(ctx).BuildInfo(program)
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) FollowPointer(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received follow_pointer request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).FollowPointer(expr, frame)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) FindVariables(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received find_variables request")
+
+	pattern := request.Params.Arguments["pattern"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	var searchValues bool
+	if searchVal, ok := request.Params.Arguments["searchValues"]; ok && searchVal != nil {
+		searchValues = searchVal.(bool)
+	}
+
+	response := s.client(ctx).FindVariables(pattern, frame, searchValues)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SetVariable(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_variable request")
+
+	name := request.Params.Arguments["name"].(string)
+	value := request.Params.Arguments["value"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).SetVariable(name, value, frame)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) CallFunction(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received call_function request")
+
+	expr := request.Params.Arguments["expression"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	response := s.client(ctx).CallFunction(expr, frame)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) GetDebuggerOutput(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received get_debugger_output request")
+
+	output := s.client(ctx).GetDebuggerOutput()
+
+	return s.newToolResultJSON(output)
+}
+
+func (s *MCPDebugServer) ListGoroutines(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_goroutines request")
+
+	var filter debugger.GoroutineFilter
+	if statusVal, ok := request.Params.Arguments["status"]; ok && statusVal != nil {
+		filter.Status = statusVal.(string)
+	}
+	if functionVal, ok := request.Params.Arguments["function"]; ok && functionVal != nil {
+		filter.Function = functionVal.(string)
+	}
+	if labelVal, ok := request.Params.Arguments["label"]; ok && labelVal != nil {
+		filter.Label = labelVal.(string)
+	}
+
+	limit := 100
+	if limitVal, ok := request.Params.Arguments["limit"]; ok && limitVal != nil {
+		limit = int(limitVal.(float64))
+	}
+
+	var offset int
+	if offsetVal, ok := request.Params.Arguments["offset"]; ok && offsetVal != nil {
+		offset = int(offsetVal.(float64))
+	}
+
+	response := s.client(ctx).ListGoroutines(filter, limit, offset)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SwitchGoroutine(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received switch_goroutine request")
+
+	id := int64(request.Params.Arguments["id"].(float64))
+
+	response := s.client(ctx).SwitchGoroutine(id)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ListThreads(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_threads request")
+
+	response := s.client(ctx).ListThreads()
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SwitchThread(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received switch_thread request")
+
+	id := int(request.Params.Arguments["id"].(float64))
+
+	response := s.client(ctx).SwitchThread(id)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) CurrentGoroutine(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received current_goroutine request")
+
+	response := s.client(ctx).CurrentGoroutine()
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ChildGoroutines(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received child_goroutines request")
+
+	id := int64(request.Params.Arguments["id"].(float64))
+
+	response := s.client(ctx).ChildGoroutines(id)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Describe(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received describe request")
+
+	opts := debugger.DescribeOptions{
+		Stack:        true,
+		StackDepth:   5,
+		Source:       true,
+		ContextLines: 5,
+		Locals:       true,
+		LocalsDepth:  1,
+	}
+	if v, ok := request.Params.Arguments["stack"]; ok && v != nil {
+		opts.Stack = v.(bool)
+	}
+	if v, ok := request.Params.Arguments["stackDepth"]; ok && v != nil {
+		opts.StackDepth = int(v.(float64))
+	}
+	if v, ok := request.Params.Arguments["source"]; ok && v != nil {
+		opts.Source = v.(bool)
+	}
+	if v, ok := request.Params.Arguments["contextLines"]; ok && v != nil {
+		opts.ContextLines = int(v.(float64))
+	}
+	if v, ok := request.Params.Arguments["locals"]; ok && v != nil {
+		opts.Locals = v.(bool)
+	}
+	if v, ok := request.Params.Arguments["localsDepth"]; ok && v != nil {
+		opts.LocalsDepth = int(v.(float64))
+	}
+
+	response := s.client(ctx).Describe(opts)
 
-	return newToolResultJSON(response)
+	return s.newToolResultJSON(response)
 }
 
-func (s *MCPDebugServer) SetBreakpoint(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received set_breakpoint request")
+func (s *MCPDebugServer) Backtrace(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received backtrace request")
 
-	file := request.Params.Arguments["file"].(string)
-	line := int(request.Params.Arguments["line"].(float64))
+	var goroutineID int64
+	if goroutineVal, ok := request.Params.Arguments["goroutine"]; ok && goroutineVal != nil {
+		goroutineID = int64(goroutineVal.(float64))
+	}
 
-	var condition string
-	if condVal, ok := request.Params.Arguments["condition"]; ok && condVal != nil {
-		condition = condVal.(string)
+	depth := 50
+	if depthVal, ok := request.Params.Arguments["depth"]; ok && depthVal != nil {
+		depth = int(depthVal.(float64))
+	}
+
+	var includeArgs bool
+	if includeArgsVal, ok := request.Params.Arguments["includeArgs"]; ok && includeArgsVal != nil {
+		includeArgs = includeArgsVal.(bool)
 	}
 
-	breakpoint := s.debugClient.SetBreakpoint(file, line, condition)
+	response := s.client(ctx).Backtrace(goroutineID, depth, includeArgs, followPointersArgument(request))
 
-	return newToolResultJSON(breakpoint)
+	return s.newToolResultJSON(response)
 }
 
-func (s *MCPDebugServer) ListBreakpoints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received list_breakpoints request")
+func (s *MCPDebugServer) ListDeferred(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_deferred request")
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
 
-	response := s.debugClient.ListBreakpoints()
+	response := s.client(ctx).ListDeferred(frame)
 
-	return newToolResultJSON(response)
+	return s.newToolResultJSON(response)
 }
 
-func (s *MCPDebugServer) RemoveBreakpoint(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received remove_breakpoint request")
+func (s *MCPDebugServer) DumpStacks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received dump_stacks request")
 
-	id := int(request.Params.Arguments["id"].(float64))
+	depth := 20
+	if depthVal, ok := request.Params.Arguments["depth"]; ok && depthVal != nil {
+		depth = int(depthVal.(float64))
+	}
+
+	var includeGoroutines bool
+	if includeVal, ok := request.Params.Arguments["includeGoroutines"]; ok && includeVal != nil {
+		includeGoroutines = includeVal.(bool)
+	}
 
-	response := s.debugClient.RemoveBreakpoint(id)
+	response := s.client(ctx).DumpAllStacks(depth, includeGoroutines)
 
-	return newToolResultJSON(response)
+	return s.newToolResultJSON(response)
 }
 
-func (s *MCPDebugServer) DebugSourceFile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received debug_source_file request")
+func (s *MCPDebugServer) DetectDeadlock(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received detect_deadlock request")
 
-	file := request.Params.Arguments["file"].(string)
+	response := s.client(ctx).DetectDeadlock()
 
-	var args []string
-	if argsVal, ok := request.Params.Arguments["args"]; ok && argsVal != nil {
-		argsArray := argsVal.([]interface{})
-		args = make([]string, len(argsArray))
-		for i, arg := range argsArray {
-			args[i] = fmt.Sprintf("%v", arg)
-		}
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ListSource(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_source request")
+
+	var file string
+	if fileVal, ok := request.Params.Arguments["file"]; ok && fileVal != nil {
+		file = fileVal.(string)
+	}
+
+	var line int
+	if lineVal, ok := request.Params.Arguments["line"]; ok && lineVal != nil {
+		line = int(lineVal.(float64))
 	}
 
-	response := s.debugClient.DebugSourceFile(file, args)
+	contextLines := 5
+	if contextVal, ok := request.Params.Arguments["context"]; ok && contextVal != nil {
+		contextLines = int(contextVal.(float64))
+	}
+
+	response := s.client(ctx).ListSource(file, line, contextLines)
 
-	return newToolResultJSON(response)
+	return s.newToolResultJSON(response)
 }
 
-func (s *MCPDebugServer) Continue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received continue request")
+func (s *MCPDebugServer) ListFunctions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_functions request")
 
-	state := s.debugClient.Continue()
-	return newToolResultJSON(state)
+	var filter, pkg string
+	if filterVal, ok := request.Params.Arguments["filter"]; ok && filterVal != nil {
+		filter = filterVal.(string)
+	}
+	if pkgVal, ok := request.Params.Arguments["package"]; ok && pkgVal != nil {
+		pkg = pkgVal.(string)
+	}
+
+	var limit, offset int
+	if limitVal, ok := request.Params.Arguments["limit"]; ok && limitVal != nil {
+		limit = int(limitVal.(float64))
+	}
+	if offsetVal, ok := request.Params.Arguments["offset"]; ok && offsetVal != nil {
+		offset = int(offsetVal.(float64))
+	}
+
+	response := s.client(ctx).ListFunctions(filter, pkg, limit, offset)
+
+	return s.newToolResultJSON(response)
 }
 
-func (s *MCPDebugServer) Step(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received step request")
+func (s *MCPDebugServer) ListSources(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_sources request")
+
+	var filter, pkg string
+	if filterVal, ok := request.Params.Arguments["filter"]; ok && filterVal != nil {
+		filter = filterVal.(string)
+	}
+	if pkgVal, ok := request.Params.Arguments["package"]; ok && pkgVal != nil {
+		pkg = pkgVal.(string)
+	}
+
+	var includeStdlib bool
+	if stdlibVal, ok := request.Params.Arguments["includeStdlib"]; ok && stdlibVal != nil {
+		includeStdlib = stdlibVal.(bool)
+	}
+
+	var limit, offset int
+	if limitVal, ok := request.Params.Arguments["limit"]; ok && limitVal != nil {
+		limit = int(limitVal.(float64))
+	}
+	if offsetVal, ok := request.Params.Arguments["offset"]; ok && offsetVal != nil {
+		offset = int(offsetVal.(float64))
+	}
 
-	state := s.debugClient.Step()
+	response := s.client(ctx).ListSources(filter, pkg, includeStdlib, limit, offset)
 
-	return newToolResultJSON(state)
+	return s.newToolResultJSON(response)
 }
 
-func (s *MCPDebugServer) StepOver(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received step_over request")
+func (s *MCPDebugServer) Disassemble(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received disassemble request")
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	var startPC, endPC uint64
+	if startVal, ok := request.Params.Arguments["startPC"]; ok && startVal != nil {
+		pc, err := strconv.ParseUint(startVal.(string), 0, 64)
+		if err != nil {
+			return newErrorResult("invalid startPC %q: %v", startVal, err), nil
+		}
+		startPC = pc
+	}
+	if endVal, ok := request.Params.Arguments["endPC"]; ok && endVal != nil {
+		pc, err := strconv.ParseUint(endVal.(string), 0, 64)
+		if err != nil {
+			return newErrorResult("invalid endPC %q: %v", endVal, err), nil
+		}
+		endPC = pc
+	}
+
+	var line int
+	if lineVal, ok := request.Params.Arguments["line"]; ok && lineVal != nil {
+		line = int(lineVal.(float64))
+	}
 
-	state := s.debugClient.StepOver()
+	response := s.client(ctx).Disassemble(frame, startPC, endPC, line)
 
-	return newToolResultJSON(state)
+	return s.newToolResultJSON(response)
 }
 
-func (s *MCPDebugServer) StepOut(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received step_out request")
+func (s *MCPDebugServer) ExamineMemory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received examine_memory request")
+
+	address := request.Params.Arguments["address"].(string)
+
+	length := 64
+	if lengthVal, ok := request.Params.Arguments["length"]; ok && lengthVal != nil {
+		length = int(lengthVal.(float64))
+	}
+
+	var format string
+	if formatVal, ok := request.Params.Arguments["format"]; ok && formatVal != nil {
+		format = formatVal.(string)
+	}
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	// Anything that isn't a number is treated as an expression to take the address of
+	addr, err := strconv.ParseUint(address, 0, 64)
+	if err != nil {
+		addr, err = s.client(ctx).EvalAddress(address, frame)
+		if err != nil {
+			return newErrorResult("invalid address %q: %v", address, err), nil
+		}
+	}
+
+	response := s.client(ctx).ExamineMemory(addr, length, format)
 
-	state := s.debugClient.StepOut()
-	return newToolResultJSON(state)
+	return s.newToolResultJSON(response)
 }
 
-func (s *MCPDebugServer) EvalVariable(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received evaluate_variable request")
+func (s *MCPDebugServer) AddressToSymbol(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received address_to_symbol request")
+
+	address := request.Params.Arguments["address"].(string)
+
+	var frame int
+	if frameVal, ok := request.Params.Arguments["frame"]; ok && frameVal != nil {
+		frame = int(frameVal.(float64))
+	}
+
+	// Anything that isn't a number is treated as an expression yielding an address
+	addr, err := strconv.ParseUint(address, 0, 64)
+	if err != nil {
+		addr, err = s.client(ctx).SymbolAddress(address, frame)
+		if err != nil {
+			return newErrorResult("invalid address %q: %v", address, err), nil
+		}
+	}
+
+	response := s.client(ctx).AddressToSymbol(addr)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ReadRegisters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received read_registers request")
+
+	var threadID int
+	if threadVal, ok := request.Params.Arguments["thread"]; ok && threadVal != nil {
+		threadID = int(threadVal.(float64))
+	}
+
+	var floating bool
+	if floatingVal, ok := request.Params.Arguments["floating"]; ok && floatingVal != nil {
+		floating = floatingVal.(bool)
+	}
+
+	response := s.client(ctx).ReadRegisters(threadID, floating)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SetRegister(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_register request")
 
 	name := request.Params.Arguments["name"].(string)
+	value := request.Params.Arguments["value"].(string)
 
-	var depth int
-	if depthVal, ok := request.Params.Arguments["depth"]; ok && depthVal != nil {
-		depth = int(depthVal.(float64))
-	} else {
-		depth = 1
+	var threadID int
+	if threadVal, ok := request.Params.Arguments["thread"]; ok && threadVal != nil {
+		threadID = int(threadVal.(float64))
 	}
 
-	response := s.debugClient.EvalVariable(name, depth)
+	response := s.client(ctx).SetRegister(threadID, name, value)
 
-	return newToolResultJSON(response)
+	return s.newToolResultJSON(response)
 }
 
-func (s *MCPDebugServer) GetDebuggerOutput(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	logger.Debug("Received get_debugger_output request")
+func (s *MCPDebugServer) SetNextStatement(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_next_statement request")
+
+	line := int(request.Params.Arguments["line"].(float64))
+
+	var file string
+	if fileVal, ok := request.Params.Arguments["file"]; ok && fileVal != nil {
+		file = fileVal.(string)
+	}
+
+	response := s.client(ctx).SetNextStatement(file, line)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) WriteStdin(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received write_stdin request")
+
+	data := request.Params.Arguments["data"].(string)
+	if v, ok := request.Params.Arguments["newline"]; ok && v != nil && v.(bool) {
+		data += "\n"
+	}
+
+	var closeAfter bool
+	if v, ok := request.Params.Arguments["close"]; ok && v != nil {
+		closeAfter = v.(bool)
+	}
+
+	response := s.client(ctx).WriteStdin(data, closeAfter)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ReadOutput(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received read_output request")
+
+	var stream string
+	if streamVal, ok := request.Params.Arguments["stream"]; ok && streamVal != nil {
+		stream = streamVal.(string)
+	}
+
+	var since int64
+	if sinceVal, ok := request.Params.Arguments["since"]; ok && sinceVal != nil {
+		since = int64(sinceVal.(float64))
+	}
 
-	output := s.debugClient.GetDebuggerOutput()
+	response := s.client(ctx).ReadOutput(stream, since)
 
-	return newToolResultJSON(output)
+	return s.newToolResultJSON(response)
 }
 
 func (s *MCPDebugServer) DebugTest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -390,15 +4014,327 @@ func (s *MCPDebugServer) DebugTest(ctx context.Context, request mcp.CallToolRequ
 		}
 	}
 
-	response := s.debugClient.DebugTest(testfile, testname, testflags)
+	response := s.client(ctx).DebugTest(testfile, testname, testflags)
 
-	return newToolResultJSON(response)
+	return s.newToolResultJSON(response)
 }
 
-func newToolResultJSON(data interface{}) (*mcp.CallToolResult, error) {
+func (s *MCPDebugServer) LaunchTest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received launch_test request")
+
+	var pkg string
+	if pkgVal, ok := request.Params.Arguments["package"]; ok && pkgVal != nil {
+		pkg = pkgVal.(string)
+	}
+
+	var test string
+	if testVal, ok := request.Params.Arguments["test"]; ok && testVal != nil {
+		test = testVal.(string)
+	}
+
+	var flags []string
+	if flagsVal, ok := request.Params.Arguments["flags"]; ok && flagsVal != nil {
+		flagsArray := flagsVal.([]interface{})
+		flags = make([]string, len(flagsArray))
+		for i, flag := range flagsArray {
+			flags[i] = fmt.Sprintf("%v", flag)
+		}
+	}
+
+	response := s.client(ctx).LaunchTest(pkg, test, flags)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SetOutputFormat(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_output_format request")
+
+	format := request.Params.Arguments["format"].(string)
+	switch format {
+	case outputFormatBoth, outputFormatProse, outputFormatStructured:
+	default:
+		return newErrorResult("unknown output format %q, expected %s, %s or %s", format, outputFormatBoth, outputFormatProse, outputFormatStructured), nil
+	}
+
+	s.outputFormat = format
+
+	return s.newToolResultJSON(map[string]string{"status": "success", "outputFormat": format})
+}
+
+func (s *MCPDebugServer) SetFollowPointers(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_follow_pointers request")
+
+	follow := request.Params.Arguments["follow"].(bool)
+
+	response := s.client(ctx).SetFollowPointers(follow)
+
+	return s.newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SetResponseLimit(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received set_response_limit request")
+
+	maxBytes := int(request.Params.Arguments["maxBytes"].(float64))
+	if maxBytes < 0 {
+		return newErrorResult("invalid response limit %d, expected a number of bytes or 0 for no limit", maxBytes), nil
+	}
+
+	s.maxResponseBytes = maxBytes
+
+	return s.newToolResultJSON(map[string]interface{}{"status": "success", "maxResponseBytes": maxBytes})
+}
+
+func (s *MCPDebugServer) newToolResultJSON(data interface{}) (*mcp.CallToolResult, error) {
 	jsonBytes, err := json.Marshal(data)
 	if err != nil {
 		return newErrorResult("failed to serialize data: %v", err), nil
 	}
+
+	if s.outputFormat != outputFormatBoth {
+		var generic interface{}
+		if err := json.Unmarshal(jsonBytes, &generic); err != nil {
+			return newErrorResult("failed to serialize data: %v", err), nil
+		}
+		filterOutputFormat(generic, s.outputFormat)
+		if jsonBytes, err = json.Marshal(generic); err != nil {
+			return newErrorResult("failed to serialize data: %v", err), nil
+		}
+	}
+
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
+
+// Output formats for tool results
+const (
+	outputFormatBoth       = "both"       // Prose strings and structured fields
+	outputFormatProse      = "prose"      // Only prose strings such as "At file:line in function"
+	outputFormatStructured = "structured" // Only structured fields
+)
+
+// proseFields maps each structured JSON field to the prose fields carrying the same information
+var proseFields = map[string][]string{
+	"position": {"location", "currentLocation"},
+	"stop":     {"stopReason"},
+}
+
+// filterOutputFormat drops either the prose or the structured form of information that
+// a decoded JSON value carries in both forms
+func filterOutputFormat(v interface{}, format string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for structured, prose := range proseFields {
+			if _, ok := v[structured]; !ok {
+				continue
+			}
+			if format == outputFormatStructured {
+				for _, field := range prose {
+					delete(v, field)
+				}
+			} else {
+				delete(v, structured)
+			}
+		}
+		for _, child := range v {
+			filterOutputFormat(child, format)
+		}
+	case []interface{}:
+		for _, child := range v {
+			filterOutputFormat(child, format)
+		}
+	}
+}
+
+// defaultMaxResponseBytes is the size tool results are truncated to unless
+// MCP_MAX_RESPONSE_SIZE says otherwise
+const defaultMaxResponseBytes = 100 * 1024
+
+// maxTruncationPasses caps the lists truncateResponse shortens to fit a result in its limit
+const maxTruncationPasses = 32
+
+// getMaxResponseBytes reads the result size limit from the MCP_MAX_RESPONSE_SIZE
+// environment variable, where 0 means no limit
+func getMaxResponseBytes() int {
+	if env := os.Getenv("MCP_MAX_RESPONSE_SIZE"); env != "" {
+		size, err := strconv.Atoi(env)
+		if err == nil && size >= 0 {
+			return size
+		}
+		logger.Warn("Ignoring invalid MCP_MAX_RESPONSE_SIZE", "value", env)
+	}
+	return defaultMaxResponseBytes
+}
+
+// withResponseLimit truncates the JSON results of a tool handler that exceed the server's
+// size limit, or the call's maxResponseBytes argument
+func (s *MCPDebugServer) withResponseLimit(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		limit := s.maxResponseBytes
+		if limitVal, ok := request.Params.Arguments["maxResponseBytes"]; ok && limitVal != nil {
+			limit = int(limitVal.(float64))
+		}
+
+		result, err := handler(ctx, request)
+		if result == nil || limit <= 0 {
+			return result, err
+		}
+		for i, content := range result.Content {
+			text, ok := content.(mcp.TextContent)
+			if !ok || len(text.Text) <= limit {
+				continue
+			}
+			if truncated, ok := truncateResponse(text.Text, limit); ok {
+				text.Text = truncated
+				result.Content[i] = text
+			}
+		}
+		return result, err
+	}
+}
+
+// truncateResponse shrinks a JSON object to limit bytes by dropping whole items from the end
+// of its lists: array elements, such as stack frames, goroutines and variable children, and
+// entries of objects keyed by number, such as results by goroutine ID. Other objects and
+// strings are kept whole, so a result made of a few large values may stay over the limit.
+// The result gets truncated: true, the number of items omitted, and how many were omitted
+// from each list. It returns ok false for text that is not a JSON object.
+func truncateResponse(text string, limit int) (string, bool) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var response map[string]interface{}
+	if err := decoder.Decode(&response); err != nil {
+		return "", false
+	}
+
+	omitted := make(map[string]int)
+	var total int
+	for pass := 0; ; pass++ {
+		if total > 0 {
+			response["truncated"] = true
+			response["omittedItems"] = total
+			response["omitted"] = omitted
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return "", false
+		}
+		if len(data) <= limit || pass == maxTruncationPasses {
+			return string(data), true
+		}
+
+		list := longestList(response, len(data)-limit)
+		if list == nil {
+			return string(data), true
+		}
+		omitted[list.path] += list.omit
+		total += list.omit
+		list.truncate()
+	}
+}
+
+// truncatableList is a list in a decoded JSON value, and the items to drop from its end
+type truncatableList struct {
+	path     string
+	size     int    // Encoded bytes
+	items    []int  // Encoded bytes of each item
+	omit     int    // Items to drop from the end
+	truncate func() // Drops the last omit items
+}
+
+// truncationFields are the fields truncateResponse adds to a result
+var truncationFields = map[string]bool{"truncated": true, "omittedItems": true, "omitted": true}
+
+// longestList picks the list to drop items from to save excess bytes, and how many. Lists
+// with more than one item go first, the one with the most encoded bytes first, so a list
+// keeps one item, and the lists inside it, until no other list is left to cut.
+func longestList(response map[string]interface{}, excess int) *truncatableList {
+	var lists []*truncatableList
+	collectLists(response, "", nil, &lists)
+
+	var longest *truncatableList
+	for _, list := range lists {
+		if len(list.items) == 0 {
+			continue
+		}
+		if longest == nil || cutsBefore(list, longest) {
+			longest = list
+		}
+	}
+	if longest == nil {
+		return nil
+	}
+
+	keep := min(1, len(longest.items)-1)
+	for saved := 0; longest.omit < len(longest.items)-keep && saved < excess; longest.omit++ {
+		saved += longest.items[len(longest.items)-1-longest.omit]
+	}
+	return longest
+}
+
+// cutsBefore reports whether list a is cut before list b: lists with more than one item go
+// first, then the longer one in encoded bytes
+func cutsBefore(a, b *truncatableList) bool {
+	if (len(a.items) > 1) != (len(b.items) > 1) {
+		return len(a.items) > 1
+	}
+	return a.size > b.size
+}
+
+// collectLists gathers the lists in a decoded JSON value, and in its items. set replaces the
+// value in its parent, to truncate an array.
+func collectLists(v interface{}, path string, set func(interface{}), lists *[]*truncatableList) {
+	switch v := v.(type) {
+	case []interface{}:
+		list := &truncatableList{path: path, size: encodedSize(v)}
+		for i, item := range v {
+			list.items = append(list.items, encodedSize(item)+1)
+			collectLists(item, fmt.Sprintf("%s[%d]", path, i), func(x interface{}) { v[i] = x }, lists)
+		}
+		list.truncate = func() { set(v[:len(v)-list.omit]) }
+		*lists = append(*lists, list)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if path == "" && truncationFields[key] {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		numbered := len(keys) > 0
+		for _, key := range keys {
+			if _, err := strconv.ParseInt(key, 10, 64); err != nil {
+				numbered = false
+			}
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			collectLists(v[key], childPath, func(x interface{}) { v[key] = x }, lists)
+		}
+		if !numbered || path == "" {
+			return
+		}
+
+		// encoding/json writes the entries sorted by key as a string
+		list := &truncatableList{path: path, size: encodedSize(v)}
+		for _, key := range keys {
+			list.items = append(list.items, len(strconv.Quote(key))+1+encodedSize(v[key])+1)
+		}
+		list.truncate = func() {
+			for _, key := range keys[len(keys)-list.omit:] {
+				delete(v, key)
+			}
+		}
+		*lists = append(*lists, list)
+	}
+}
+
+// encodedSize returns the encoded length of a decoded JSON value
+func encodedSize(v interface{}) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
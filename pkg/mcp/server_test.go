@@ -289,6 +289,179 @@ func TestDebugWorkflow(t *testing.T) {
 	t.Log("TestDebugWorkflow completed successfully")
 }
 
+// callTool calls a tool through the MCP server, as a client would, so the checks made
+// before the handler runs apply too
+func callTool(t *testing.T, server *MCPDebugServer, name string, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	message, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]interface{}{"name": name, "arguments": args},
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode %s request: %v", name, err)
+	}
+
+	response, ok := server.server.HandleMessage(context.Background(), message).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("Expected a response to %s", name)
+	}
+	result, ok := response.Result.(*mcp.CallToolResult)
+	if !ok {
+		t.Fatalf("Expected a tool result from %s, got %#v", name, response.Result)
+	}
+	return result
+}
+
+func TestProcessExit(t *testing.T) {
+	// Skip test in short mode
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testFile := createComplexTestGoFile(t)
+	defer func() {
+		_ = os.RemoveAll(filepath.Dir(testFile))
+	}()
+
+	server := NewMCPDebugServer("test-version")
+
+	debugResult := callTool(t, server, "debug", map[string]interface{}{"file": testFile})
+	expectSuccess(t, debugResult, nil, &types.DebugSourceResponse{})
+
+	breakpointResponse := &types.BreakpointResponse{}
+	breakpointResult := callTool(t, server, "set_breakpoint", map[string]interface{}{
+		"file": testFile,
+		"line": float64(findLineNumber(testFile, "a := n * 2")),
+	})
+	expectSuccess(t, breakpointResult, nil, breakpointResponse)
+
+	// Stop at the breakpoint once, then run to completion without it
+	expectSuccess(t, callTool(t, server, "continue", nil), nil, &types.ContinueResponse{})
+	removeResult := callTool(t, server, "remove_breakpoint", map[string]interface{}{"id": float64(breakpointResponse.Breakpoint.ID)})
+	expectSuccess(t, removeResult, nil, &types.BreakpointResponse{})
+
+	continueResponse := &types.ContinueResponse{}
+	expectSuccess(t, callTool(t, server, "continue", nil), nil, continueResponse)
+
+	if continueResponse.Status != "success" {
+		t.Errorf("Expected exiting to end the continue successfully, got error %q", continueResponse.Context.ErrorMessage)
+	}
+	if stop := continueResponse.Context.Stop; stop == nil || stop.Kind != types.StopExited || stop.ExitStatus == nil || *stop.ExitStatus != 0 {
+		t.Fatalf("Expected an exited stop with status 0, got %+v", continueResponse.Context.Stop)
+	}
+	if continueResponse.Context.StopReason != "process exited with status 0" {
+		t.Errorf("Expected the stop reason to give the exit status, got %q", continueResponse.Context.StopReason)
+	}
+
+	// Tools needing the process say it exited
+	liveTools := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{name: "continue"},
+		{name: "step"},
+		{name: "step_over"},
+		{name: "step_out"},
+		{name: "step_isolated"},
+		{name: "halt"},
+		{name: "eval_expression", args: map[string]interface{}{"expression": "n"}},
+		{name: "list_locals"},
+		{name: "list_goroutines"},
+		{name: "backtrace"},
+		{name: "describe"},
+		{name: "set_breakpoint", args: map[string]interface{}{"file": testFile, "line": float64(findLineNumber(testFile, "b := a + 5"))}},
+	}
+	for _, tool := range liveTools {
+		t.Run(tool.name, func(t *testing.T) {
+			result := callTool(t, server, tool.name, tool.args)
+			text := getTextContent(result)
+			if !result.IsError || !strings.Contains(text, "process has exited with status 0, restart required") {
+				t.Errorf("Expected %s to report that the process exited, got %s", tool.name, text)
+			}
+		})
+	}
+
+	// Tools that don't need the process still work
+	outputResponse := &types.DebuggerOutputResponse{}
+	expectSuccess(t, callTool(t, server, "get_debugger_output", nil), nil, outputResponse)
+	if !strings.Contains(outputResponse.Stdout, "Program completed") {
+		t.Errorf("Expected the output of the whole run, got %q", outputResponse.Stdout)
+	}
+
+	listResponse := &types.BreakpointListResponse{}
+	expectSuccess(t, callTool(t, server, "list_breakpoints", nil), nil, listResponse)
+	if listResponse.Status != "success" {
+		t.Errorf("Expected breakpoints to be listed after exit, got error %q", listResponse.Context.ErrorMessage)
+	}
+
+	sessionsResponse := &types.SessionListResponse{}
+	expectSuccess(t, callTool(t, server, "list_sessions", nil), nil, sessionsResponse)
+	if len(sessionsResponse.Sessions) == 0 || !sessionsResponse.Sessions[0].Exited {
+		t.Errorf("Expected the session to be marked as exited, got %+v", sessionsResponse.Sessions)
+	}
+
+	// Restarting gives a live process again
+	restartResponse := &types.RestartResponse{}
+	expectSuccess(t, callTool(t, server, "restart", nil), nil, restartResponse)
+	if restartResponse.Status != "success" {
+		t.Fatalf("Expected restart to succeed, got error %q", restartResponse.Context.ErrorMessage)
+	}
+
+	goroutinesResult := callTool(t, server, "list_goroutines", nil)
+	if goroutinesResult.IsError {
+		t.Errorf("Expected tools to work again after restart, got %s", getTextContent(goroutinesResult))
+	}
+
+	expectSuccess(t, callTool(t, server, "close", nil), nil, &types.CloseResponse{})
+}
+
+func TestStatus(t *testing.T) {
+	// Skip test in short mode
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	server := NewMCPDebugServer("test-version")
+
+	statusResponse := &types.StatusResponse{}
+	expectSuccess(t, callTool(t, server, "status", nil), nil, statusResponse)
+	if statusResponse.Active || statusResponse.State != "no active session" || statusResponse.DelveVersion == "" {
+		t.Errorf("Expected no active session and the Delve version, got %+v", statusResponse)
+	}
+
+	unknownResponse := &types.StatusResponse{}
+	expectSuccess(t, callTool(t, server, "status", map[string]interface{}{"sessionID": "nope"}), nil, unknownResponse)
+	if unknownResponse.State != "no such session" {
+		t.Errorf("Expected an unknown session to be reported as a state, got %+v", unknownResponse)
+	}
+
+	testFile := createComplexTestGoFile(t)
+	defer func() {
+		_ = os.RemoveAll(filepath.Dir(testFile))
+	}()
+
+	expectSuccess(t, callTool(t, server, "debug", map[string]interface{}{"file": testFile}), nil, &types.DebugSourceResponse{})
+	line := findLineNumber(testFile, "a := n * 2")
+	expectSuccess(t, callTool(t, server, "set_breakpoint", map[string]interface{}{"file": testFile, "line": float64(line)}), nil, &types.BreakpointResponse{})
+	expectSuccess(t, callTool(t, server, "continue", nil), nil, &types.ContinueResponse{})
+
+	statusResponse = &types.StatusResponse{}
+	expectSuccess(t, callTool(t, server, "status", nil), nil, statusResponse)
+	if statusResponse.State != "stopped" || statusResponse.Pid == 0 || statusResponse.Breakpoints != 1 {
+		t.Errorf("Expected a stopped process with one breakpoint, got %+v", statusResponse)
+	}
+	if pos := statusResponse.Context.Position; pos == nil || pos.Line != line {
+		t.Errorf("Expected to be stopped at line %d, got %+v", line, pos)
+	}
+	if statusResponse.ServerVersion != "test-version" {
+		t.Errorf("Expected the server version, got %q", statusResponse.ServerVersion)
+	}
+
+	expectSuccess(t, callTool(t, server, "close", nil), nil, &types.CloseResponse{})
+}
+
 // Helper function to create a more complex Go file for debugging tests
 func createComplexTestGoFile(t *testing.T) string {
 	tempDir, err := os.MkdirTemp("", "go-debugger-complex-test")
@@ -630,3 +803,188 @@ func TestEvalComplexVariables(t *testing.T) {
 	closeResult, err := server.Close(ctx, closeRequest)
 	expectSuccess(t, closeResult, err, &types.CloseResponse{})
 }
+
+func TestFilterOutputFormat(t *testing.T) {
+	input := `{"context":{"currentLocation":"At main.go:12 in main.main","position":{"file":"main.go","line":12,"function":"main.main"},"stopReason":"stopped at breakpoint 1","stop":{"kind":"breakpoint","breakpointId":1}},"breakpoints":[{"id":1,"location":"At main.go:12 in main.main","position":{"file":"main.go","line":12,"function":"main.main"}},{"id":2,"location":"watching x"}]}`
+
+	testCases := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{
+			name:     "prose",
+			format:   outputFormatProse,
+			expected: `{"breakpoints":[{"id":1,"location":"At main.go:12 in main.main"},{"id":2,"location":"watching x"}],"context":{"currentLocation":"At main.go:12 in main.main","stopReason":"stopped at breakpoint 1"}}`,
+		},
+		{
+			name:     "structured",
+			format:   outputFormatStructured,
+			expected: `{"breakpoints":[{"id":1,"position":{"file":"main.go","function":"main.main","line":12}},{"id":2,"location":"watching x"}],"context":{"position":{"file":"main.go","function":"main.main","line":12},"stop":{"breakpointId":1,"kind":"breakpoint"}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var v interface{}
+			if err := json.Unmarshal([]byte(input), &v); err != nil {
+				t.Fatalf("Failed to parse input: %v", err)
+			}
+			filterOutputFormat(v, tc.format)
+
+			output, err := json.Marshal(v)
+			if err != nil {
+				t.Fatalf("Failed to serialize output: %v", err)
+			}
+			if string(output) != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, output)
+			}
+		})
+	}
+}
+
+func TestTruncateResponse(t *testing.T) {
+	frames := `[{"function":"main.a","line":1},{"function":"main.b","line":2},{"function":"main.c","line":3},{"function":"main.d","line":4}]`
+	children := `[{"name":"x","value":"1"},{"name":"y","value":"2"}]`
+
+	testCases := []struct {
+		name           string
+		input          string
+		limit          int
+		expected       string
+		expectedNotOk  bool
+		expectedFields []string
+	}{
+		{
+			name:     "Fits",
+			input:    `{"status":"success","frames":` + frames + `}`,
+			limit:    1000,
+			expected: `{"frames":` + frames + `,"status":"success"}`,
+		},
+		{
+			name:     "Whole frames dropped from the end",
+			input:    `{"status":"success","frames":` + frames + `}`,
+			limit:    140,
+			expected: `{"frames":[{"function":"main.a","line":1}],"omitted":{"frames":3},"omittedItems":3,"status":"success","truncated":true}`,
+		},
+		{
+			name:     "Longest list cut first",
+			input:    `{"frames":` + frames + `,"children":` + children + `}`,
+			limit:    170,
+			expected: `{"children":[{"name":"x","value":"1"},{"name":"y","value":"2"}],"frames":[{"function":"main.a","line":1}],"omitted":{"frames":3},"omittedItems":3,"truncated":true}`,
+		},
+		{
+			name:     "Entries keyed by goroutine ID",
+			input:    `{"results":{"1":{"value":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},"2":{"value":"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"},"3":{"value":"cccccccccccccccccccccccccccccc"}},"summary":"x"}`,
+			limit:    140,
+			expected: `{"omitted":{"results":2},"omittedItems":2,"results":{"1":{"value":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}},"summary":"x","truncated":true}`,
+		},
+		{
+			name:     "Objects and strings kept whole",
+			input:    `{"context":{"currentLocation":"At main.go:12 in main.main"},"output":"0123456789"}`,
+			limit:    10,
+			expected: `{"context":{"currentLocation":"At main.go:12 in main.main"},"output":"0123456789"}`,
+		},
+		{
+			name:          "Not a JSON object",
+			input:         `Error: no active debug session`,
+			limit:         10,
+			expectedNotOk: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, ok := truncateResponse(tc.input, tc.limit)
+			if ok == tc.expectedNotOk {
+				t.Fatalf("Expected ok %v, got %v", !tc.expectedNotOk, ok)
+			}
+			if ok && result != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestWithResponseLimit(t *testing.T) {
+	s := &MCPDebugServer{maxResponseBytes: 1000}
+	handler := s.withResponseLimit(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(`{"items":["aaaaaaaaaa","bbbbbbbbbb","cccccccccc"]}`), nil
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"maxResponseBytes": float64(40)}
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, `"truncated":true`) {
+		t.Errorf("Expected the call's limit to truncate the result, got %s", text)
+	}
+
+	request.Params.Arguments = nil
+	result, _ = handler(context.Background(), request)
+	if text := result.Content[0].(mcp.TextContent).Text; strings.Contains(text, "truncated") {
+		t.Errorf("Expected the server's limit to keep the result whole, got %s", text)
+	}
+}
+
+func TestWithStepTimeout(t *testing.T) {
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		expected  time.Duration
+	}{
+		{name: "Default", expected: defaultStepTimeout},
+		{name: "Per call", arguments: map[string]interface{}{"timeout": float64(2)}, expected: 2 * time.Second},
+		{name: "Not positive", arguments: map[string]interface{}{"timeout": float64(0)}, expected: defaultStepTimeout},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.arguments
+
+			ctx, cancel := withStepTimeout(context.Background(), request)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				t.Fatal("Expected a deadline")
+			}
+			if timeout := time.Until(deadline); timeout < tc.expected-time.Second || timeout > tc.expected {
+				t.Errorf("Expected a timeout of %v, got %v", tc.expected, timeout)
+			}
+		})
+	}
+}
+
+func TestToolSetsNameRegisteredTools(t *testing.T) {
+	message := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	response, ok := NewMCPDebugServer("test-version").server.HandleMessage(context.Background(), message).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("Expected a response to tools/list")
+	}
+	result, ok := response.Result.(mcp.ListToolsResult)
+	if !ok {
+		t.Fatalf("Expected a tool list, got %#v", response.Result)
+	}
+	registered := make(map[string]bool)
+	for _, tool := range result.Tools {
+		registered[tool.Name] = true
+	}
+
+	toolSets := map[string]map[string]bool{
+		"postExitTools":  postExitTools,
+		"executionTools": executionTools,
+		"runningTools":   runningTools,
+	}
+	for setName, tools := range toolSets {
+		for name := range tools {
+			if !registered[name] {
+				t.Errorf("%s names %s, which is not a registered tool", setName, name)
+			}
+		}
+	}
+}
@@ -12,10 +12,51 @@ type DebugContext struct {
 	Timestamp       time.Time          `json:"timestamp"`                 // Operation timestamp
 	Operation       string             `json:"operation,omitempty"`       // Last debug operation performed
 	CurrentLocation *string            `json:"currentLocation,omitempty"` // Current execution position
+	Position        *SourcePosition    `json:"position,omitempty"`        // Current execution position as separate fields
 	LocalVariables  []Variable         `json:"localVariables,omitempty"`
+	Captured        map[string]string  `json:"captured,omitempty"`      // Capture expressions of the hit breakpoint and their values
+	CaptureErrors   map[string]string  `json:"captureErrors,omitempty"` // Capture expressions that failed to evaluate, and why
+	ReturnValues    []Variable         `json:"returnValues,omitempty"`  // Results of the function, when stopped at a breakpoint on its returns
+	ReturnedError   *ReturnedError     `json:"returnedError,omitempty"` // The error the function returns, when stopped at a break-on-error breakpoint
 	// LLM-friendly additions
-	StopReason   string `json:"stopReason,omitempty"` // Why the program stopped, in human terms
-	ErrorMessage string `json:"error,omitempty"`      // Error message if any
+	StopReason   string      `json:"stopReason,omitempty"` // Why the program stopped, in human terms
+	Stop         *StopDetail `json:"stop,omitempty"`       // Why the program stopped, as separate fields
+	ErrorMessage string      `json:"error,omitempty"`      // Error message if any
+}
+
+// SourcePosition represents a code location as separate fields, the structured form of
+// "At file:line in function"
+type SourcePosition struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+}
+
+// Kinds of StopDetail
+const (
+	StopBreakpoint = "breakpoint"
+	StopWatchpoint = "watchpoint"
+	StopPanic      = "panic"
+	StopFatal      = "fatal"
+	StopInternal   = "internal"
+	StopExited     = "exited"
+	StopRunning    = "running"
+	StopStopped    = "stopped"
+	StopUnknown    = "unknown"
+)
+
+// StopDetail represents why the program is in its current state, the structured form of StopReason
+type StopDetail struct {
+	Kind            string `json:"kind"`                      // One of the Stop* kinds
+	BreakpointID    int    `json:"breakpointId,omitempty"`    // Breakpoint, watchpoint or panic breakpoint that was hit
+	WatchExpr       string `json:"watchExpr,omitempty"`       // Watched expression, for watchpoints
+	PinnedGoroutine int64  `json:"pinnedGoroutine,omitempty"` // Goroutine a breakpoint set with pinToFirstGoroutine is pinned to
+	Armed           []int  `json:"armed,omitempty"`           // Breakpoints the hit breakpoint arms, as set with arm_breakpoint_after
+	ArmedBy         int    `json:"armedBy,omitempty"`         // Breakpoint whose hit armed the hit breakpoint
+	Disarmed        bool   `json:"disarmed,omitempty"`        // Whether the hit breakpoint is one-shot and disabled itself until its trigger is hit again
+	InternalKind    string `json:"internalKind,omitempty"`    // Kind of Delve's internal breakpoint that stopped the program, e.g. step
+	Message         string `json:"message,omitempty"`         // Panic value or fatal error message
+	ExitStatus      *int   `json:"exitStatus,omitempty"`      // Exit status, once the process exited
 }
 
 // Variable represents a program variable with LLM-friendly additions
@@ -29,6 +70,12 @@ type Variable struct {
 	Type  string `json:"type"`  // Type in human-readable format
 	Scope string `json:"scope"` // Variable scope (local, global, etc)
 	Kind  string `json:"kind"`  // High-level kind description
+
+	Shadowed bool       `json:"shadowed,omitempty"` // Hidden by an inner declaration with the same name
+	Children []Variable `json:"children,omitempty"` // Expanded fields, elements or pointee
+
+	Stringer      string `json:"stringer,omitempty"`      // Result of the value's String or Error method, when asked for
+	StringerError string `json:"stringerError,omitempty"` // Why calling String or Error failed
 }
 
 // Breakpoint represents a breakpoint with LLM-friendly additions
@@ -37,13 +84,159 @@ type Breakpoint struct {
 	DelveBreakpoint *api.Breakpoint `json:"-"`
 
 	// LLM-friendly fields
-	ID          int      `json:"id"`                  // Breakpoint ID
-	Status      string   `json:"status"`              // Enabled/Disabled/etc in human terms
-	Location    *string  `json:"location"`            // Breakpoint location
-	Variables   []string `json:"variables,omitempty"` // Variables in scope
-	Condition   string   `json:"condition,omitempty"` // Human-readable condition description
-	HitCount    uint64   `json:"hitCount"`            // Number of times breakpoint was hit
-	LastHitInfo string   `json:"lastHit,omitempty"`   // Information about last hit in human terms
+	ID           int             `json:"id"`                     // Breakpoint ID
+	Status       string          `json:"status"`                 // Enabled/Disabled/etc in human terms
+	Location     *string         `json:"location"`               // Breakpoint location
+	Position     *SourcePosition `json:"position,omitempty"`     // Breakpoint location as separate fields, nil for watchpoints
+	Variables    []string        `json:"variables,omitempty"`    // Variables in scope
+	Condition    string          `json:"condition,omitempty"`    // Human-readable condition description
+	HitCondition string          `json:"hitCondition,omitempty"` // Hit-count condition, e.g. "== 100" or "% 10"
+	HitCount     uint64          `json:"hitCount"`               // Number of times breakpoint was hit
+	LastHitInfo  string          `json:"lastHit,omitempty"`      // Information about last hit in human terms
+	Watchpoint   bool            `json:"watchpoint,omitempty"`   // Triggers on memory access instead of a code location
+	WatchExpr    string          `json:"watchExpr,omitempty"`    // Watched expression, for watchpoints
+	WatchType    string          `json:"watchType,omitempty"`    // read, write or readwrite, for watchpoints
+	Tracepoint   bool            `json:"tracepoint,omitempty"`   // Records Variables on each hit instead of stopping
+	Disabled     bool            `json:"disabled,omitempty"`     // Kept with its settings but does not stop the program
+	Internal     bool            `json:"internal,omitempty"`     // Set by Delve itself, e.g. for unrecovered panics
+	Kind         string          `json:"kind"`                   // user, or what an internal breakpoint is for, e.g. step-over
+	Purpose      string          `json:"purpose,omitempty"`      // What an internal breakpoint does, in human terms
+
+	GoroutineHits map[string]uint64 `json:"goroutineHits,omitempty"` // Hit counts keyed by goroutine ID
+
+	GoroutineLabel string `json:"goroutineLabel,omitempty"` // key=value label a goroutine must carry to stop here
+	SkippedHits    uint64 `json:"skippedHits,omitempty"`    // Hits on goroutines without the label, continued past
+
+	IgnoreCount int `json:"ignoreCount,omitempty"` // Hits still to be continued past before the breakpoint stops
+
+	PinToFirstGoroutine bool  `json:"pinToFirstGoroutine,omitempty"` // Only stops the goroutine of its first hit
+	PinnedGoroutine     int64 `json:"pinnedGoroutine,omitempty"`     // That goroutine, once it has been hit
+
+	ArmedAfter int  `json:"armedAfter,omitempty"` // Breakpoint whose hits enable it, until then it is disabled
+	OneShot    bool `json:"oneShot,omitempty"`    // Disables itself after its first hit, until ArmedAfter is hit again
+	Armed      bool `json:"armed,omitempty"`      // Enabled by a hit of ArmedAfter, waiting for its own
+
+	ReturnOf    string `json:"returnOf,omitempty"`    // Function whose returns the breakpoint stops at, instead of a line
+	ReturnSites int    `json:"returnSites,omitempty"` // Return instructions of ReturnOf it is set on
+
+	OnError        bool   `json:"onError,omitempty"`        // Stops only when ReturnOf returns a non-nil error
+	ErrorMatch     string `json:"errorMatch,omitempty"`     // Regex the error must match to stop
+	SkippedReturns uint64 `json:"skippedReturns,omitempty"` // Returns without a matching error, continued past
+}
+
+// Goroutine represents a goroutine with LLM-friendly additions
+type Goroutine struct {
+	// Internal Delve goroutine - not exposed in JSON
+	DelveGoroutine *api.Goroutine `json:"-"`
+
+	// LLM-friendly fields
+	ID         int64             `json:"id"`                   // Goroutine ID
+	Status     string            `json:"status"`               // running, waiting, syscall, etc.
+	WaitReason string            `json:"waitReason,omitempty"` // Why the goroutine is blocked, if it is and the reason is known
+	Location   *string           `json:"location"`             // Current location in user code
+	Position   *SourcePosition   `json:"position,omitempty"`   // Current location as separate fields
+	ThreadID   int               `json:"threadId,omitempty"`   // Thread running this goroutine, if any
+	Labels     map[string]string `json:"labels,omitempty"`     // pprof labels
+}
+
+// GoroutineDetails is a goroutine with every location Delve knows for it
+type GoroutineDetails struct {
+	Goroutine
+	CurrentLocation     *string         `json:"currentLocation"`               // Innermost location, possibly in the runtime
+	CurrentPosition     *SourcePosition `json:"currentPosition,omitempty"`     // Innermost location as separate fields
+	GoStatementLocation *string         `json:"goStatementLocation,omitempty"` // Go statement that created the goroutine
+	GoStatementPosition *SourcePosition `json:"goStatementPosition,omitempty"` // Go statement as separate fields
+	StartLocation       *string         `json:"startLocation,omitempty"`       // Function the goroutine started in
+	StartPosition       *SourcePosition `json:"startPosition,omitempty"`       // Start function as separate fields
+}
+
+// StackFrame represents one frame of a call stack with LLM-friendly additions
+type StackFrame struct {
+	Index     int             `json:"index"`               // Frame number, 0 is the innermost frame
+	Function  string          `json:"function"`            // Function name
+	File      string          `json:"file"`                // Source file
+	Line      int             `json:"line"`                // Source line
+	Location  *string         `json:"location"`            // Frame location in human terms
+	Position  *SourcePosition `json:"position"`            // Frame location as separate fields
+	Arguments []Variable      `json:"arguments,omitempty"` // Function arguments, when requested
+	IsRuntime bool            `json:"isRuntime"`           // Frame is in the runtime or standard library
+	Error     string          `json:"error,omitempty"`     // Why the frame could not be fully read
+}
+
+// DeferredCall represents a call deferred by a stack frame
+type DeferredCall struct {
+	Index         int             `json:"index"`                   // Position in the order the deferred calls run, from 1
+	Function      string          `json:"function,omitempty"`      // Deferred function
+	Position      *SourcePosition `json:"position,omitempty"`      // Entry of the deferred function
+	DeferredAt    *string         `json:"deferredAt,omitempty"`    // Defer statement that scheduled the call, in human terms
+	DeferPosition *SourcePosition `json:"deferPosition,omitempty"` // Defer statement that scheduled the call, as separate fields
+	Arguments     []Variable      `json:"arguments,omitempty"`     // Argument values, when Delve can read them
+	Error         string          `json:"error,omitempty"`         // Why the deferred call could not be read
+}
+
+// GoroutineStack represents the backtrace of one goroutine in a stack dump
+type GoroutineStack struct {
+	GoroutineID int64        `json:"goroutineId"`     // Goroutine ID
+	Status      string       `json:"status"`          // Status and, when blocked, wait reason
+	Function    string       `json:"function"`        // Function the goroutine was started with
+	Frames      []StackFrame `json:"frames"`          // Frames from innermost to outermost
+	Error       string       `json:"error,omitempty"` // Why the stack could not be read
+}
+
+// StackGroup represents goroutines that share the same stack and status
+type StackGroup struct {
+	Summary      string       `json:"summary"`      // e.g. "42 goroutines in net/http.(*conn).serve [waiting (IO wait)]"
+	Count        int          `json:"count"`        // Number of goroutines in the group
+	Function     string       `json:"function"`     // Function the goroutines were started with
+	Status       string       `json:"status"`       // Shared status and wait reason
+	GoroutineIDs []int64      `json:"goroutineIds"` // Goroutines in the group
+	Frames       []StackFrame `json:"frames"`       // The shared stack
+}
+
+// SourceLine represents one line of a source listing
+type SourceLine struct {
+	Number  int    `json:"number"`            // 1-based line number
+	Text    string `json:"text"`              // Line content
+	Current bool   `json:"current,omitempty"` // Line is the current execution position
+}
+
+// Instruction represents one disassembled machine instruction
+type Instruction struct {
+	Address    string `json:"address"`              // Instruction address, e.g. "0x4a1b2c"
+	Opcode     string `json:"opcode"`               // Instruction bytes in hex
+	Text       string `json:"text"`                 // Assembly text
+	File       string `json:"file,omitempty"`       // Source file the instruction was generated from
+	Line       int    `json:"line,omitempty"`       // Source line the instruction was generated from
+	Function   string `json:"function"`             // Function containing the instruction
+	Current    bool   `json:"current,omitempty"`    // Instruction is at the current PC
+	Breakpoint bool   `json:"breakpoint,omitempty"` // A breakpoint is set on the instruction
+	CallTarget string `json:"callTarget,omitempty"` // Function called by CALL instructions
+}
+
+// Register represents a CPU register with LLM-friendly additions
+type Register struct {
+	Name    string `json:"name"`              // Register name, e.g. "Rip"
+	Value   string `json:"value"`             // Value as formatted by Delve
+	Hex     string `json:"hex,omitempty"`     // Integer value in hex, for plain integer registers
+	Decimal string `json:"decimal,omitempty"` // Integer value in decimal, for plain integer registers
+}
+
+// RegisterChange is a register whose value an instruction changed
+type RegisterChange struct {
+	Name   string `json:"name"`   // Register name, e.g. "Rax"
+	Before string `json:"before"` // Value before the instruction ran
+	After  string `json:"after"`  // Value after the instruction ran
+}
+
+// TraceHit represents one recorded hit of a tracepoint
+type TraceHit struct {
+	Seq          int64           `json:"seq"`                // Position in the order of all hits, starting at 1
+	Timestamp    time.Time       `json:"timestamp"`          // When the hit was recorded
+	BreakpointID int             `json:"breakpointId"`       // Tracepoint that was hit
+	GoroutineID  int64           `json:"goroutineId"`        // Goroutine that hit it
+	Location     *string         `json:"location"`           // Where the hit happened
+	Position     *SourcePosition `json:"position,omitempty"` // Where the hit happened, as separate fields
+	Values       []Variable      `json:"values"`             // Captured expressions, in the order they were requested
 }
 
 // DebuggerOutput represents captured program output with LLM-friendly additions
@@ -59,19 +252,121 @@ type DebuggerOutput struct {
 	ExitCode      int          `json:"exitCode"`      // Program exit code if available
 }
 
+// OutputLine represents one captured line of program output
+type OutputLine struct {
+	Offset    int64     `json:"offset"`    // Byte offset of the line in its stream
+	Content   string    `json:"content"`   // Line without the trailing newline
+	Timestamp time.Time `json:"timestamp"` // When the line was captured
+}
+
 // Operation-specific responses
 
 type LaunchResponse struct {
-	Context  *DebugContext `json:"context"`
-	Program  string        `json:"program"`
-	Args     []string      `json:"args"`
-	ExitCode int           `json:"exitCode"`
+	Status      string        `json:"status"`
+	Context     *DebugContext `json:"context"`
+	Program     string        `json:"program"`
+	Args        []string      `json:"args"`
+	Env         []string      `json:"env,omitempty"`         // Extra KEY=VALUE environment variables
+	WorkingDir  string        `json:"workingDir,omitempty"`  // Working directory of the target
+	Pid         int           `json:"pid"`                   // PID of the launched process
+	DebugBinary string        `json:"debugBinary,omitempty"` // Binary built from a package path
+	BuildOutput string        `json:"buildOutput,omitempty"` // Compiler output when a build was needed
+	ExitCode    int           `json:"exitCode"`
+	StoppedAt   string        `json:"stoppedAt,omitempty"` // Function the program was run to after launching, e.g. main.main
+	StartNote   string        `json:"startNote,omitempty"` // Why the program did not stop at main.main, when it was run there
 }
 
 type BreakpointResponse struct {
 	Status     string       `json:"status"`
 	Context    DebugContext `json:"context"`
 	Breakpoint Breakpoint   `json:"breakpoint"` // The affected breakpoint
+
+	// Locations an ambiguous location spec matched, to pick one from
+	Candidates []SourcePosition `json:"candidates,omitempty"`
+
+	// Line a new breakpoint was asked for and the line it was set at, which differ when the
+	// requested line had no code
+	RequestedLine int    `json:"requestedLine,omitempty"`
+	ActualLine    int    `json:"actualLine,omitempty"`
+	Adjustment    string `json:"adjustment,omitempty"` // Why the breakpoint is not on the requested line
+
+	PreviousCondition string `json:"previousCondition,omitempty"` // Condition an amended breakpoint had before
+}
+
+// BatchBreakpointResult is the outcome of one breakpoint of a batch
+type BatchBreakpointResult struct {
+	Index      int              `json:"index"`                // Position of the spec in the batch, from 0
+	Spec       string           `json:"spec"`                 // Location the spec asked for
+	Status     string           `json:"status"`               // "success" or "error"
+	Breakpoint *Breakpoint      `json:"breakpoint,omitempty"` // The new breakpoint, when it was set
+	Error      string           `json:"error,omitempty"`      // Why the breakpoint could not be set
+	Candidates []SourcePosition `json:"candidates,omitempty"` // Locations an ambiguous spec matched
+	Adjustment string           `json:"adjustment,omitempty"` // Why the breakpoint is not on the requested line
+}
+
+// SetBreakpointsResponse represents the response for setting a batch of breakpoints.
+// Status is "partial" when some of them failed.
+type SetBreakpointsResponse struct {
+	Status    string                  `json:"status"`
+	Context   DebugContext            `json:"context"`
+	Results   []BatchBreakpointResult `json:"results"`   // One result per spec, in order
+	Succeeded int                     `json:"succeeded"` // Breakpoints that were set
+	Failed    int                     `json:"failed"`    // Breakpoints that could not be set
+}
+
+// MethodBreakpoint is the outcome of breaking on one method of a type
+type MethodBreakpoint struct {
+	Function     string      `json:"function"`               // Fully qualified method name, e.g. "main.(*Handler).Serve"
+	Breakpoint   *Breakpoint `json:"breakpoint,omitempty"`   // The new breakpoint, when it was set
+	Inlined      bool        `json:"inlined,omitempty"`      // The method was inlined, so the breakpoint is at its call sites
+	InlinedSites int         `json:"inlinedSites,omitempty"` // Call sites the inlined method breaks at
+	Error        string      `json:"error,omitempty"`        // Why no breakpoint could be set
+}
+
+// TypeBreakpointsResponse represents the response for breaking on every method of a type.
+// Status is "partial" when some of them failed.
+type TypeBreakpointsResponse struct {
+	Status    string             `json:"status"`
+	Context   DebugContext       `json:"context"`
+	Type      string             `json:"type"`                // Type whose methods were broken on
+	Functions []string           `json:"functions,omitempty"` // Methods that got a breakpoint
+	Set       []MethodBreakpoint `json:"set,omitempty"`       // Methods that got a breakpoint, with it
+	Failed    []MethodBreakpoint `json:"failed,omitempty"`    // Methods that could not get one, and why
+	Summary   string             `json:"summary,omitempty"`   // How many methods got a breakpoint
+}
+
+// FunctionBreakpoint is the outcome of setting a breakpoint on one of several functions
+type FunctionBreakpoint struct {
+	Function   string      `json:"function"`             // Fully qualified function name
+	Breakpoint *Breakpoint `json:"breakpoint,omitempty"` // The new breakpoint, when it was set
+	Error      string      `json:"error,omitempty"`      // Why no breakpoint could be set
+}
+
+// ErrorBreakpointsResponse represents the response for breaking where functions return an error
+type ErrorBreakpointsResponse struct {
+	Status          string               `json:"status"`
+	Context         DebugContext         `json:"context"`
+	FunctionPattern string               `json:"functionPattern"`      // Regex the functions were picked with
+	ErrorMatch      string               `json:"errorMatch,omitempty"` // Regex the error must match to stop
+	Set             []FunctionBreakpoint `json:"set,omitempty"`        // Functions that got a breakpoint on their returns
+	Failed          []FunctionBreakpoint `json:"failed,omitempty"`     // Functions that could not get one, and why
+	Summary         string               `json:"summary,omitempty"`
+}
+
+// ReturnedError is a non-nil error a function is about to return
+type ReturnedError struct {
+	Function string `json:"function"`          // The returning function
+	Result   string `json:"result"`            // Name of the error result, e.g. err, or ~r1 when unnamed
+	Type     string `json:"type"`              // Concrete type of the error, e.g. *fmt.wrapError
+	Value    string `json:"value"`             // The error value in human terms
+	Message  string `json:"message,omitempty"` // The error's message, when its fields hold it
+}
+
+type ResetHitCountResponse struct {
+	Status     string       `json:"status"`
+	Context    DebugContext `json:"context"`
+	PreviousID int          `json:"previousId"` // ID of the breakpoint before it was re-created
+	Breakpoint Breakpoint   `json:"breakpoint"` // The re-created breakpoint with zero hits
 }
 
 type BreakpointListResponse struct {
@@ -83,15 +378,948 @@ type BreakpointListResponse struct {
 type StepResponse struct {
 	Status       string       `json:"status"`
 	Context      DebugContext `json:"context"`
-	StepType     string       `json:"stepType"`    // "into", "over", or "out"
+	StepType     string       `json:"stepType"`    // "into", "over", "out" or "isolated"
 	FromLocation *string      `json:"from"`        // Starting location
 	ChangedVars  []Variable   `json:"changedVars"` // Variables that changed during step
+	// Return values of the function that was stepped out of, when available
+	ReturnValues []Variable `json:"returnValues,omitempty"`
+	// Breakpoint hit before the step could complete
+	InterruptedBy *Breakpoint `json:"interruptedBy,omitempty"`
+	// How a step over left the frame it started in, if it did
+	Transition     string `json:"transition,omitempty"`
+	TransitionNote string `json:"transitionNote,omitempty"` // The transition in human terms
+	// Instructions an isolated step executed with the other goroutines paused
+	Instructions int `json:"instructions,omitempty"`
+	// Whether the other goroutines had to run during an isolated step
+	OthersRan     bool   `json:"othersRan,omitempty"`
+	IsolationNote string `json:"isolationNote,omitempty"` // Whether and why the others ran
 }
 
+// Kinds of StepResponse.Transition
+const (
+	StepTransitionReturned = "returned" // Stepped over a return into the caller
+	StepTransitionPanic    = "panic"    // A panic stopped the step in a deferred function
+	StepTransitionEntered  = "entered"  // Stopped in another function, e.g. a deferred call on return
+)
+
 type EvalVariableResponse struct {
 	Status   string       `json:"status"`
 	Context  DebugContext `json:"context"`
-	Variable Variable     `json:"variable"` // The evald variable
+	Variable Variable     `json:"variable"`           // The evald variable
+	MaxDepth int          `json:"maxDepth,omitempty"` // Levels of nested values loaded, after the ceiling
+
+	StringerNote string `json:"stringerNote,omitempty"` // Why String and Error methods were not called
+}
+
+type SetVariableResponse struct {
+	Status        string       `json:"status"`
+	Context       DebugContext `json:"context"`
+	Variable      Variable     `json:"variable"`                // The variable re-evaluated after the write
+	PreviousValue string       `json:"previousValue,omitempty"` // Value before the write
+}
+
+type GoroutineListResponse struct {
+	Status     string         `json:"status"`
+	Context    DebugContext   `json:"context"`
+	Goroutines []Goroutine    `json:"goroutines"` // Goroutines in the requested page
+	Total      int            `json:"total"`      // Number of goroutines matching the filters
+	Counts     map[string]int `json:"counts"`     // Matching goroutines grouped by status
+	Summary    string         `json:"summary"`    // Counts in human terms, e.g. "200 waiting (chan receive)"
+	Limit      int            `json:"limit"`
+	Offset     int            `json:"offset"`
+}
+
+type SwitchGoroutineResponse struct {
+	Status              string       `json:"status"`
+	Context             DebugContext `json:"context"`
+	Goroutine           Goroutine    `json:"goroutine"`           // The newly selected goroutine
+	PreviousGoroutineID int64        `json:"previousGoroutineId"` // Goroutine that was selected before the switch
+}
+
+// Thread represents an OS thread of the debugged process
+type Thread struct {
+	ID           int             `json:"id"`                     // OS thread ID
+	PC           string          `json:"pc"`                     // Program counter in hex
+	Location     *string         `json:"location"`               // Current location, or the PC outside Go source
+	Position     *SourcePosition `json:"position,omitempty"`     // Current location as separate fields
+	GoroutineID  int64           `json:"goroutineId,omitempty"`  // Goroutine running on the thread, if any
+	Current      bool            `json:"current,omitempty"`      // The thread commands operate on
+	BreakpointID int             `json:"breakpointId,omitempty"` // Breakpoint the thread is stopped at, if any
+}
+
+type ThreadListResponse struct {
+	Status          string       `json:"status"`
+	Context         DebugContext `json:"context"`
+	Threads         []Thread     `json:"threads"`         // Threads sorted by ID
+	CurrentThreadID int          `json:"currentThreadId"` // Thread commands operate on
+	Summary         string       `json:"summary"`         // How many threads run goroutines, in human terms
+}
+
+type SwitchThreadResponse struct {
+	Status           string       `json:"status"`
+	Context          DebugContext `json:"context"`
+	Thread           Thread       `json:"thread"`           // The newly current thread
+	PreviousThreadID int          `json:"previousThreadId"` // Thread that was current before the switch
+}
+
+// GoroutineCountSample is the goroutine count seen at one point of a goroutine count watch
+type GoroutineCountSample struct {
+	Elapsed string `json:"elapsed"` // Time since the watch started, e.g. "1.25s"
+	Count   int    `json:"count"`   // Live goroutines at that time
+}
+
+// GoroutineOrigin counts the goroutines started by one go statement
+type GoroutineOrigin struct {
+	GoStatement string `json:"goStatement"` // Location of the go statement
+	Count       int    `json:"count"`       // Goroutines it started that are still alive
+}
+
+// GoroutineWatchResponse represents the response for running until the goroutine count changes
+type GoroutineWatchResponse struct {
+	Status              string                 `json:"status"`
+	Context             DebugContext           `json:"context"`
+	Threshold           int                    `json:"threshold"`                     // Count, or change from the starting count, waited for
+	Direction           string                 `json:"direction"`                     // above, below, grow, shrink or change
+	Matched             bool                   `json:"matched"`                       // Whether the count crossed the threshold
+	StartCount          int                    `json:"startCount"`                    // Goroutines when the watch started
+	FinalCount          int                    `json:"finalCount"`                    // Goroutines when it stopped
+	MinCount            int                    `json:"minCount"`                      // Fewest goroutines seen
+	MaxCount            int                    `json:"maxCount"`                      // Most goroutines seen
+	Halts               int                    `json:"halts"`                         // Times the program was halted to count
+	Samples             []GoroutineCountSample `json:"samples"`                       // The count at the start and each time it changed
+	NewGoroutineCount   int                    `json:"newGoroutineCount"`             // Goroutines alive at the end that did not exist at the start
+	NewGoroutineOrigins []GoroutineOrigin      `json:"newGoroutineOrigins,omitempty"` // New goroutines by the go statement that started them
+	NewGoroutines       []Goroutine            `json:"newGoroutines,omitempty"`       // The first new goroutines, by ID
+	Location            *string                `json:"location,omitempty"`            // Where the program stopped
+	InterruptedBy       *Breakpoint            `json:"interruptedBy,omitempty"`       // Breakpoint that stopped the program before the count crossed
+}
+
+type CurrentGoroutineResponse struct {
+	Status    string           `json:"status"`
+	Context   DebugContext     `json:"context"`
+	Goroutine GoroutineDetails `json:"goroutine"` // The goroutine commands operate on
+}
+
+type BacktraceResponse struct {
+	Status      string       `json:"status"`
+	Context     DebugContext `json:"context"`
+	GoroutineID int64        `json:"goroutineId"` // Goroutine the stack belongs to
+	Frames      []StackFrame `json:"frames"`      // Frames from innermost to outermost
+}
+
+// DeferredCallsResponse represents the calls deferred by a stack frame
+type DeferredCallsResponse struct {
+	Status  string         `json:"status"`
+	Context DebugContext   `json:"context"`
+	Frame   int            `json:"frame"`  // Frame the deferred calls belong to
+	Defers  []DeferredCall `json:"defers"` // Deferred calls, in the order they will run
+}
+
+// StackDumpResponse represents the stacks of all goroutines, grouped by identical stacks
+type StackDumpResponse struct {
+	Status     string           `json:"status"`
+	Context    DebugContext     `json:"context"`
+	Total      int              `json:"total"`                // Number of goroutines
+	Summary    string           `json:"summary"`              // One line per group, largest first
+	Groups     []StackGroup     `json:"groups"`               // Goroutines grouped by stack
+	Goroutines []GoroutineStack `json:"goroutines,omitempty"` // Every goroutine's stack, when requested
+}
+
+type EvalExpressionResponse struct {
+	Status     string       `json:"status"`
+	Context    DebugContext `json:"context"`
+	Expression string       `json:"expression"`         // The evaluated expression
+	Variable   Variable     `json:"variable"`           // Result with type and single-line value
+	Tree       string       `json:"tree"`               // Result rendered as an indented tree
+	MaxDepth   int          `json:"maxDepth,omitempty"` // Levels of nested values loaded, after the ceiling
+
+	StringerNote string `json:"stringerNote,omitempty"` // Why String and Error methods were not called
+}
+
+// AssertOperand is an operand of an assertion's top-level comparison or logical operator
+type AssertOperand struct {
+	Expression string `json:"expression"`
+	Value      string `json:"value,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Error      string `json:"error,omitempty"` // Why the operand could not be evaluated on its own
+}
+
+// AssertResponse represents a boolean expression checked in a frame. Status is "error" only
+// when it could not be checked; whether it holds is in Passed.
+type AssertResponse struct {
+	Status     string          `json:"status"`
+	Context    DebugContext    `json:"context"`
+	Expression string          `json:"expression"`
+	Frame      int             `json:"frame"`
+	Passed     bool            `json:"passed"`             // Whether the expression is true
+	Value      string          `json:"value,omitempty"`    // What the expression evaluated to
+	Type       string          `json:"type,omitempty"`     // Its type, bool unless the expression was rejected
+	Operands   []AssertOperand `json:"operands,omitempty"` // Values of the top-level operator's operands
+	Message    string          `json:"message"`            // The outcome in human terms
+}
+
+// FollowPointersResponse represents the response for setting whether pointers are followed
+type FollowPointersResponse struct {
+	Status         string       `json:"status"`
+	Context        DebugContext `json:"context"`
+	FollowPointers bool         `json:"followPointers"` // Whether evaluations load what pointers point to
+	Message        string       `json:"message"`        // What the setting means for evaluations
+}
+
+// Declaration is where a variable or struct field is declared in the source
+type Declaration struct {
+	Kind          string `json:"kind"`                    // "argument", "local", "package variable" or "field"
+	Name          string `json:"name"`                    // Name declared; qualified with the package for package variables
+	Type          string `json:"type,omitempty"`          // Type of the variable or field
+	DeclaringType string `json:"declaringType,omitempty"` // For fields, the struct type that declares it
+	File          string `json:"file,omitempty"`
+	Line          int    `json:"line,omitempty"`
+	Location      string `json:"location,omitempty"` // File and line as "file:line"
+	Text          string `json:"text,omitempty"`     // The declaring line of source, when it is on disk
+}
+
+// DeclarationResponse represents the response for finding where a variable is declared
+type DeclarationResponse struct {
+	Status      string       `json:"status"`
+	Context     DebugContext `json:"context"`
+	Expression  string       `json:"expression"`
+	Frame       int          `json:"frame"`
+	Declaration Declaration  `json:"declaration"`
+}
+
+// GoroutineEvalResult is an expression evaluated in one goroutine
+type GoroutineEvalResult struct {
+	Frame    int             `json:"frame"`              // Frame evaluated in: the goroutine's topmost frame outside the runtime and standard library
+	Location *string         `json:"location"`           // Where that frame is
+	Position *SourcePosition `json:"position,omitempty"` // Where that frame is, as separate fields
+	Variable *Variable       `json:"variable,omitempty"` // Result with type and single-line value
+	Error    string          `json:"error,omitempty"`    // Why the expression failed, though it is in scope
+}
+
+// GoroutineEvalResponse represents an expression evaluated in every goroutine it is in scope in
+type GoroutineEvalResponse struct {
+	Status     string                        `json:"status"`
+	Context    DebugContext                  `json:"context"`
+	Expression string                        `json:"expression"` // The evaluated expression
+	Results    map[int64]GoroutineEvalResult `json:"results"`    // Keyed by goroutine ID
+	Total      int                           `json:"total"`      // Goroutines matching the filter
+	Evaluated  int                           `json:"evaluated"`  // Goroutines the expression was tried in
+	NotInScope int                           `json:"notInScope"` // Goroutines left out as a name in the expression is not in scope there
+	Truncated  bool                          `json:"truncated"`  // Whether goroutines were left untried to stay within the limit
+	Summary    string                        `json:"summary"`    // Outcome in human terms
+}
+
+// SpawnedGoroutine is a goroutine started, directly or through others, by a given goroutine
+type SpawnedGoroutine struct {
+	GoroutineDetails
+	ParentID int64 `json:"parentId"` // Goroutine whose go statement created this one
+	Depth    int   `json:"depth"`    // 1 for a goroutine the given one created itself, 2 for one those created, and so on
+}
+
+// ChildGoroutinesResponse represents the goroutines a goroutine has spawned, transitively
+type ChildGoroutinesResponse struct {
+	Status      string             `json:"status"`
+	Context     DebugContext       `json:"context"`
+	ParentID    int64              `json:"parentId"`         // The goroutine the tree is rooted at
+	Parent      *GoroutineDetails  `json:"parent,omitempty"` // The root goroutine, unless it has exited
+	Direct      int                `json:"direct"`           // Goroutines the root created itself
+	Descendants []SpawnedGoroutine `json:"descendants"`      // Every goroutine in the tree, each right after the one that created it
+	Summary     string             `json:"summary"`
+	Notes       []string           `json:"notes,omitempty"` // Why the tree may be incomplete
+}
+
+// WhatIsResponse represents the type information of an expression
+// VariableMatch is a variable or nested value found by a search
+type VariableMatch struct {
+	Path      string `json:"path"`      // Expression evaluating to the value, e.g. req.Header["Content-Type"]
+	Value     string `json:"value"`     // The value in human terms
+	Type      string `json:"type"`      // Go type of the value
+	Scope     string `json:"scope"`     // Whether the top-level variable is a local or an argument
+	MatchedOn string `json:"matchedOn"` // "name" or "value"
+}
+
+type VariableSearchResponse struct {
+	Status    string          `json:"status"`
+	Context   DebugContext    `json:"context"`
+	Pattern   string          `json:"pattern"`   // The regex searched for
+	Frame     int             `json:"frame"`     // Frame whose variables were searched
+	Matches   []VariableMatch `json:"matches"`   // Matches in the order they were found
+	Truncated bool            `json:"truncated"` // Whether the search stopped at its bounds
+}
+
+type WhatIsResponse struct {
+	Status         string       `json:"status"`
+	Context        DebugContext `json:"context"`
+	Expression     string       `json:"expression"`             // The resolved expression
+	Type           string       `json:"type"`                   // Static type of the expression
+	UnderlyingType string       `json:"underlyingType"`         // Underlying type, e.g. struct { ... } for named structs
+	Kind           string       `json:"kind"`                   // Kind of the static type, e.g. "struct" or "interface"
+	IsInterface    bool         `json:"isInterface"`            // Whether the static type is an interface
+	ConcreteType   string       `json:"concreteType,omitempty"` // Dynamic type held by an interface, usable in type assertions
+	ConcreteKind   string       `json:"concreteKind,omitempty"` // Kind of the dynamic type
+	IsNil          bool         `json:"isNil,omitempty"`        // Whether an interface holds no value
+}
+
+// ChannelWaiter is a goroutine blocked sending to or receiving from a channel
+type ChannelWaiter struct {
+	Goroutine Goroutine `json:"goroutine"` // The blocked goroutine
+	Select    bool      `json:"select"`    // Blocked in a select statement, possibly on other channels too
+}
+
+// MutexGoroutine is a goroutine blocked on an inspected mutex, or likely holding it
+type MutexGoroutine struct {
+	Goroutine Goroutine `json:"goroutine"`
+	Operation string    `json:"operation,omitempty"` // What a blocked goroutine waits to do: "lock" or "rlock"
+	Location  *string   `json:"location,omitempty"`  // User code location of the blocking call, or of the frame referencing the mutex
+
+	// Unlock deferred by the frame referencing the mutex, e.g. "sync.(*Mutex).Unlock", the
+	// strongest sign a goroutine holds it
+	DeferredUnlock string `json:"deferredUnlock,omitempty"`
+}
+
+type InspectMutexResponse struct {
+	Status        string           `json:"status"`
+	Context       DebugContext     `json:"context"`
+	Expression    string           `json:"expression"`              // The inspected expression
+	Type          string           `json:"type,omitempty"`          // "sync.Mutex" or "sync.RWMutex"
+	Address       string           `json:"address,omitempty"`       // Address of the mutex
+	Layout        string           `json:"layout,omitempty"`        // Fields the state was decoded from, or "unknown lock layout"
+	State         string           `json:"state,omitempty"`         // "unlocked", "locked", "read locked", "write locked", "write pending" or "unknown lock layout"
+	Locked        bool             `json:"locked"`                  // Whether anything holds it
+	Starving      bool             `json:"starving,omitempty"`      // In starvation mode, handed to waiters in order
+	Woken         bool             `json:"woken,omitempty"`         // A waiter has been woken to try to lock it
+	Waiters       int64            `json:"waiters"`                 // Goroutines waiting to lock, by the state word; for a RWMutex, writers only
+	Readers       int64            `json:"readers,omitempty"`       // Read locks held, for a RWMutex
+	Blocked       []MutexGoroutine `json:"blocked,omitempty"`       // Goroutines blocked locking it, found from their stacks
+	LikelyHolders []MutexGoroutine `json:"likelyHolders,omitempty"` // Other goroutines whose stacks reference it
+	Notes         []string         `json:"notes,omitempty"`         // What could not be read or searched
+	Summary       string           `json:"summary"`                 // The mutex's state in human terms
+}
+
+type InspectChannelResponse struct {
+	Status           string          `json:"status"`
+	Context          DebugContext    `json:"context"`
+	Expression       string          `json:"expression"`                 // The inspected expression
+	Type             string          `json:"type,omitempty"`             // Channel type, e.g. "chan main.job"
+	ElemType         string          `json:"elemType,omitempty"`         // Type of the values sent on it
+	Direction        string          `json:"direction,omitempty"`        // "both", "send" or "receive"
+	State            string          `json:"state,omitempty"`            // "nil", "open" or "closed"
+	Closed           bool            `json:"closed,omitempty"`           // Whether the channel is closed
+	Len              int64           `json:"len"`                        // Values in the buffer
+	Cap              int64           `json:"cap"`                        // Buffer capacity, 0 for an unbuffered channel
+	Buffered         []Variable      `json:"buffered,omitempty"`         // Values in the buffer, in the order they will be received
+	Senders          []ChannelWaiter `json:"senders,omitempty"`          // Goroutines blocked sending
+	Receivers        []ChannelWaiter `json:"receivers,omitempty"`        // Goroutines blocked receiving
+	WaitersTruncated bool            `json:"waitersTruncated,omitempty"` // Whether more goroutines are blocked than listed
+	Notes            []string        `json:"notes,omitempty"`            // Runtime internals that could not be read
+	Summary          string          `json:"summary"`                    // The channel's state in human terms
+}
+
+type InspectInterfaceResponse struct {
+	Status           string       `json:"status"`
+	Context          DebugContext `json:"context"`
+	Expression       string       `json:"expression"`                 // The inspected expression
+	InterfaceType    string       `json:"interfaceType,omitempty"`    // Static interface type, e.g. "net/http.ResponseWriter"
+	State            string       `json:"state,omitempty"`            // "nil interface", "non-nil interface, nil pointer" or "non-nil interface"
+	IsNil            bool         `json:"isNil,omitempty"`            // The interface holds no type or value and equals nil
+	HoldsNilPointer  bool         `json:"holdsNilPointer,omitempty"`  // The interface holds a typed nil pointer and does not equal nil
+	ConcreteType     string       `json:"concreteType,omitempty"`     // Dynamic type of the held value
+	ConcreteKind     string       `json:"concreteKind,omitempty"`     // Kind of the dynamic type
+	AssertExpression string       `json:"assertExpression,omitempty"` // Type assertion evaluating to the concrete value, for follow-up evals
+	Value            string       `json:"value,omitempty"`            // The held value in human terms
+	Fields           []Variable   `json:"fields,omitempty"`           // Fields of the held struct, through a pointer if needed
+	Tree             string       `json:"tree"`                       // The interface rendered as an indented tree
+}
+
+// PointerLink is a pointer among the fields or elements of a followed pointer's target
+type PointerLink struct {
+	Name       string `json:"name"`              // Field name, or index in brackets
+	Expression string `json:"expression"`        // Expression to follow it with
+	Type       string `json:"type"`              // Pointer type
+	Address    string `json:"address,omitempty"` // Address it holds, when not nil
+	IsNil      bool   `json:"isNil,omitempty"`
+}
+
+// FollowPointerResponse represents one step of following a pointer
+type FollowPointerResponse struct {
+	Status      string        `json:"status"`
+	Context     DebugContext  `json:"context"`
+	Expression  string        `json:"expression"`            // The followed pointer expression
+	Type        string        `json:"type,omitempty"`        // Pointer type, e.g. "*net/http.Request"
+	State       string        `json:"state,omitempty"`       // "nil pointer", "unreadable memory" or "pointer"
+	Address     string        `json:"address,omitempty"`     // Address the pointer holds, 0x0 when nil
+	IsNil       bool          `json:"isNil,omitempty"`       // The pointer is nil
+	Message     string        `json:"message,omitempty"`     // Why there is nothing more to show, when there isn't
+	PointeeType string        `json:"pointeeType,omitempty"` // Type of the value pointed to
+	PointeeKind string        `json:"pointeeKind,omitempty"` // Kind of the value pointed to
+	Value       string        `json:"value,omitempty"`       // The value pointed to in human terms
+	Fields      []Variable    `json:"fields,omitempty"`      // Fields or elements of the value pointed to, one level deep
+	Next        string        `json:"next,omitempty"`        // Expression of the value pointed to, e.g. "(*r)", for follow-up evals
+	Links       []PointerLink `json:"links,omitempty"`       // Pointers in the value pointed to, to follow next
+}
+
+// MemoryResponse represents a dump of the target's memory
+type MemoryResponse struct {
+	Status  string       `json:"status"`
+	Context DebugContext `json:"context"`
+	Address string       `json:"address"` // Start address in hex
+	Length  int          `json:"length"`  // Number of bytes read
+	Format  string       `json:"format"`  // hex, ascii or both
+	Dump    string       `json:"dump"`    // Rows of 16 bytes labelled with their offset from Address
+}
+
+// AddressSymbolResponse represents what an address was resolved to
+type AddressSymbolResponse struct {
+	Status        string          `json:"status"`
+	Context       DebugContext    `json:"context"`
+	Address       string          `json:"address"`                 // Address resolved, in hex
+	Known         bool            `json:"known"`                   // Whether the address is in a known symbol
+	Kind          string          `json:"kind,omitempty"`          // "function" for code, "variable" for a package variable
+	Symbol        string          `json:"symbol,omitempty"`        // Qualified name of the function or variable
+	SymbolAddress string          `json:"symbolAddress,omitempty"` // Where the symbol starts, in hex
+	Offset        uint64          `json:"offset"`                  // Bytes from the start of the symbol
+	Type          string          `json:"type,omitempty"`          // Type of a variable
+	Location      *string         `json:"location,omitempty"`      // Source line of a code address
+	Position      *SourcePosition `json:"position,omitempty"`      // Source line of a code address, as separate fields
+	Description   string          `json:"description"`             // e.g. "main.run+0x1c at /src/main.go:12", or "unknown address"
+}
+
+type FunctionInfo struct {
+	Name    string `json:"name"`           // Fully qualified name, usable as a breakpoint location
+	Package string `json:"package"`        // Import path of the defining package
+	File    string `json:"file,omitempty"` // File defining the function
+	Line    int    `json:"line,omitempty"` // Line of the function's entry point
+}
+
+type FunctionListResponse struct {
+	Status    string         `json:"status"`
+	Context   DebugContext   `json:"context"`
+	Functions []FunctionInfo `json:"functions"` // Functions in the requested page
+	Total     int            `json:"total"`     // Number of functions matching the filters
+	Truncated bool           `json:"truncated"` // Whether matches beyond this page were left out
+	Limit     int            `json:"limit"`
+	Offset    int            `json:"offset"`
+}
+
+type SourceFile struct {
+	Path    string `json:"path"`              // Absolute path, usable as a breakpoint file
+	Package string `json:"package,omitempty"` // Import path of the package the file belongs to
+}
+
+type SourceFileListResponse struct {
+	Status    string       `json:"status"`
+	Context   DebugContext `json:"context"`
+	Files     []SourceFile `json:"files"`     // Source files in the requested page
+	Total     int          `json:"total"`     // Number of files matching the filters
+	Truncated bool         `json:"truncated"` // Whether matches beyond this page were left out
+	Limit     int          `json:"limit"`
+	Offset    int          `json:"offset"`
+}
+
+type SourceResponse struct {
+	Status    string       `json:"status"`
+	Context   DebugContext `json:"context"`
+	File      string       `json:"file"`
+	Line      int          `json:"line"`      // Line the listing is centered on
+	StartLine int          `json:"startLine"` // First returned line
+	EndLine   int          `json:"endLine"`   // Last returned line
+	Lines     []SourceLine `json:"lines"`
+	Listing   string       `json:"listing"` // Lines with numbers, current line marked with "=>"
+}
+
+// WriteStdinResponse represents the response for writing to the stdin of the target
+type WriteStdinResponse struct {
+	Status  string       `json:"status"`
+	Context DebugContext `json:"context"`
+	Written int          `json:"written"`          // Bytes written
+	Closed  bool         `json:"closed,omitempty"` // Stdin was closed after writing
+}
+
+// DescribeResponse sums up where the program is stopped; sections left out by the
+// caller, or that could not be read, are empty
+type DescribeResponse struct {
+	Status        string            `json:"status"`
+	Context       DebugContext      `json:"context"`
+	Location      *string           `json:"location,omitempty"`      // Current execution position
+	Frames        []StackFrame      `json:"frames,omitempty"`        // Innermost frames of the selected goroutine
+	Source        []SourceLine      `json:"source,omitempty"`        // Lines around the current line
+	Listing       string            `json:"listing,omitempty"`       // Source lines with numbers, current line marked with "=>"
+	Locals        []Variable        `json:"locals,omitempty"`        // Local variables of the current frame
+	SectionErrors map[string]string `json:"sectionErrors,omitempty"` // Why a section could not be read, by section name
+}
+
+// RestoredBreakpoint is a breakpoint re-created after a restart
+type RestoredBreakpoint struct {
+	PreviousID int        `json:"previousId"` // ID of the breakpoint before the restart
+	Breakpoint Breakpoint `json:"breakpoint"` // The re-created breakpoint
+}
+
+// FailedBreakpoint is a breakpoint that could not be re-created after a restart
+type FailedBreakpoint struct {
+	Breakpoint Breakpoint `json:"breakpoint"` // The breakpoint as it was before the restart
+	Reason     string     `json:"reason"`     // Why it could not be re-created
+}
+
+// SavedBreakpoint is a breakpoint as written by ExportBreakpoints
+type SavedBreakpoint struct {
+	ID                  int      `json:"id"`                            // ID the breakpoint had when it was saved
+	File                string   `json:"file"`                          // Source file
+	Line                int      `json:"line"`                          // Source line
+	Function            string   `json:"function,omitempty"`            // Function containing the line, to notice code that moved
+	Condition           string   `json:"condition,omitempty"`           // Condition expression
+	HitCondition        string   `json:"hitCondition,omitempty"`        // Hit-count condition
+	GoroutineLabel      string   `json:"goroutineLabel,omitempty"`      // key=value label goroutines must carry to stop
+	IgnoreCount         int      `json:"ignoreCount,omitempty"`         // Hits to continue past before stopping, as set
+	PinToFirstGoroutine bool     `json:"pinToFirstGoroutine,omitempty"` // Pins itself to the goroutine of its first hit, in the new run
+	CaptureExprs        []string `json:"captureExprs,omitempty"`        // Expressions evaluated on each stop
+	Tracepoint          bool     `json:"tracepoint,omitempty"`          // Records hits instead of stopping
+	Disabled            bool     `json:"disabled,omitempty"`            // Set up disabled
+	OnReturn            bool     `json:"onReturn,omitempty"`            // Stops at the returns of Function rather than at the line
+	OnError             bool     `json:"onError,omitempty"`             // Stops at the returns of Function only with a non-nil error
+	ErrorMatch          string   `json:"errorMatch,omitempty"`          // Regex the error must match to stop
+}
+
+// BreakpointSet is the JSON document ExportBreakpoints writes and ImportBreakpoints reads
+type BreakpointSet struct {
+	Version     int               `json:"version"`           // Format version
+	Program     string            `json:"program,omitempty"` // Program the breakpoints were set in
+	Breakpoints []SavedBreakpoint `json:"breakpoints"`
+}
+
+// FailedImport is a saved breakpoint that could not be set up again
+type FailedImport struct {
+	Saved  SavedBreakpoint `json:"saved"`  // The breakpoint as it was saved
+	Reason string          `json:"reason"` // Why it could not be set, e.g. its location moved
+}
+
+// ExportBreakpointsResponse represents the response for saving the breakpoint set
+type ExportBreakpointsResponse struct {
+	Status  string       `json:"status"`
+	Context DebugContext `json:"context"`
+	Count   int          `json:"count"`          // Breakpoints saved
+	File    string       `json:"file,omitempty"` // File the set was written to, if any
+	Data    string       `json:"data,omitempty"` // The set as JSON, when not written to a file
+}
+
+// ImportBreakpointsResponse represents the response for restoring a saved breakpoint set.
+// Status is "partial" when some of the breakpoints could not be set.
+type ImportBreakpointsResponse struct {
+	Status   string               `json:"status"`
+	Context  DebugContext         `json:"context"`
+	Restored []RestoredBreakpoint `json:"restored,omitempty"` // Breakpoints set, with the IDs they were saved with
+	Failed   []FailedImport       `json:"failed,omitempty"`   // Breakpoints that could not be set
+}
+
+type ElementResponse struct {
+	Status            string       `json:"status"`
+	Context           DebugContext `json:"context"`
+	Expression        string       `json:"expression"`                  // The container expression
+	Type              string       `json:"type,omitempty"`              // Type of the container
+	Kind              string       `json:"kind,omitempty"`              // "slice", "array", "string" or "map"
+	Len               int64        `json:"len"`                         // Elements in the container
+	Cap               int64        `json:"cap,omitempty"`               // Capacity of a slice
+	Index             *int         `json:"index,omitempty"`             // Index of the element
+	Key               string       `json:"key,omitempty"`               // Key expression of the map entry
+	ElementExpression string       `json:"elementExpression,omitempty"` // Expression evaluating to the element, e.g. "(items)[42]"
+	Element           *Variable    `json:"element,omitempty"`           // The element, when one was asked for
+}
+
+type TableResponse struct {
+	Status     string       `json:"status"`
+	Context    DebugContext `json:"context"`
+	Expression string       `json:"expression"`        // The slice or array expression
+	Type       string       `json:"type,omitempty"`    // Type of the slice or array
+	Len        int64        `json:"len"`               // Elements in the slice or array
+	Shown      int          `json:"shown"`             // Elements loaded, one row each
+	Truncated  bool         `json:"truncated"`         // Whether elements past the element limit were left out
+	Columns    []string     `json:"columns,omitempty"` // "#" for the index, then the fields shown
+	Rows       [][]string   `json:"rows,omitempty"`    // One row of cells per element
+	Table      string       `json:"table,omitempty"`   // The rows as aligned text
+}
+
+// ModuleVersion is a module an executable was built from, as its build info records it
+type ModuleVersion struct {
+	Path    string         `json:"path"`              // Module path
+	Version string         `json:"version,omitempty"` // Module version, "(devel)" for the main module built from a checkout
+	Sum     string         `json:"sum,omitempty"`     // Checksum from go.sum
+	Replace *ModuleVersion `json:"replace,omitempty"` // Module it was replaced by, if any
+}
+
+type BuildInfoResponse struct {
+	Status     string            `json:"status"`
+	Context    DebugContext      `json:"context"`
+	Executable string            `json:"executable,omitempty"` // Executable the build info was read from
+	GoVersion  string            `json:"goVersion,omitempty"`  // Go toolchain it was built with, e.g. "go1.23.1"
+	Path       string            `json:"path,omitempty"`       // Package path of its main package
+	Main       *ModuleVersion    `json:"main,omitempty"`       // Main module, absent for builds outside of a module
+	Deps       []ModuleVersion   `json:"deps,omitempty"`       // Modules it depends on
+	Settings   map[string]string `json:"settings,omitempty"`   // Build settings, e.g. "-trimpath", "GOOS" and "vcs.revision"
+	Notes      []string          `json:"notes,omitempty"`      // What the build info means for debugging it
+}
+
+type LaunchConfigResponse struct {
+	Status     string       `json:"status"`
+	Context    DebugContext `json:"context"`
+	Program    string       `json:"program,omitempty"`    // Program the next restart launches
+	Args       []string     `json:"args"`                 // Arguments the next restart launches it with
+	Env        []string     `json:"env"`                  // Extra KEY=VALUE environment variables it is launched with
+	WorkingDir string       `json:"workingDir,omitempty"` // Working directory it is launched in
+	Note       string       `json:"note,omitempty"`
+}
+
+type RestartResponse struct {
+	Status      string               `json:"status"`
+	Context     DebugContext         `json:"context"`
+	Pid         int                  `json:"pid"`                   // PID of the new process
+	Args        []string             `json:"args,omitempty"`        // Arguments the new process was launched with
+	Env         []string             `json:"env,omitempty"`         // Extra KEY=VALUE environment variables it was launched with
+	Rebuilt     bool                 `json:"rebuilt"`               // Whether the binary was rebuilt from source
+	BuildOutput string               `json:"buildOutput,omitempty"` // Compiler output from the rebuild
+	Restored    []RestoredBreakpoint `json:"restored"`              // Breakpoints re-established in the new process
+	Failed      []FailedBreakpoint   `json:"failed,omitempty"`      // Breakpoints that could not be re-established
+}
+
+// BlockedGoroutine is a goroutine blocked on a channel or sync primitive
+type BlockedGoroutine struct {
+	ID        int64   `json:"id"`                 // Goroutine ID
+	BlockedOn string  `json:"blockedOn"`          // Operation it is blocked on, e.g. "mutex" or "chan receive"
+	Resource  string  `json:"resource"`           // Address of the channel or lock
+	Location  *string `json:"location"`           // Where in user code it is blocked
+	WaitsFor  int64   `json:"waitsFor,omitempty"` // Next goroutine in the wait cycle
+}
+
+// ContentionHotspot is a channel or lock many goroutines are blocked on
+type ContentionHotspot struct {
+	BlockedOn  string  `json:"blockedOn"`  // Operation the goroutines are blocked on
+	Resource   string  `json:"resource"`   // Address of the channel or lock
+	Location   *string `json:"location"`   // Where one of the goroutines is blocked
+	Count      int     `json:"count"`      // Number of blocked goroutines
+	Goroutines []int64 `json:"goroutines"` // IDs of the blocked goroutines
+}
+
+type DeadlockResponse struct {
+	Status       string              `json:"status"`
+	Context      DebugContext        `json:"context"`
+	Deadlocked   bool                `json:"deadlocked"`         // Whether a wait cycle was found
+	Cycle        []BlockedGoroutine  `json:"cycle,omitempty"`    // Goroutines in the suspected cycle
+	Hotspots     []ContentionHotspot `json:"hotspots,omitempty"` // Crowded primitives when there is no cycle
+	BlockedCount int                 `json:"blockedCount"`       // Goroutines blocked on channels or locks
+	Summary      string              `json:"summary"`
+}
+
+type DisassembleResponse struct {
+	Status       string        `json:"status"`
+	Context      DebugContext  `json:"context"`
+	Function     string        `json:"function,omitempty"` // Function of the first instruction
+	StartPC      string        `json:"startPC,omitempty"`  // Address of the first returned instruction
+	EndPC        string        `json:"endPC,omitempty"`    // Address of the last returned instruction
+	Instructions []Instruction `json:"instructions"`
+	Listing      string        `json:"listing"` // Instructions grouped by source line, current one marked with "=>"
+}
+
+type RegistersResponse struct {
+	Status   string       `json:"status"`
+	Context  DebugContext `json:"context"`
+	ThreadID int          `json:"threadId"`           // Thread the registers belong to
+	General  []Register   `json:"general"`            // General-purpose registers
+	Floating []Register   `json:"floating,omitempty"` // Floating-point and vector registers, when requested
+}
+
+type StepInstructionResponse struct {
+	Status           string           `json:"status"`
+	Context          DebugContext     `json:"context"`
+	Reverse          bool             `json:"reverse,omitempty"`          // The step went backward
+	FromPC           string           `json:"fromPC,omitempty"`           // PC before the step
+	PC               string           `json:"pc,omitempty"`               // PC after the step
+	Instruction      *Instruction     `json:"instruction,omitempty"`      // Instruction at the new PC, with its source line
+	Registers        []Register       `json:"registers,omitempty"`        // General-purpose registers after the step
+	ChangedRegisters []RegisterChange `json:"changedRegisters,omitempty"` // Registers the step changed
+}
+
+// StepToNextCallResponse represents the response for stepping instructions up to the next call
+type StepToNextCallResponse struct {
+	Status        string       `json:"status"`
+	Context       DebugContext `json:"context"`
+	FromPC        string       `json:"fromPC,omitempty"`        // PC before stepping
+	PC            string       `json:"pc,omitempty"`            // PC the thread stopped at
+	Steps         int          `json:"steps"`                   // Instructions executed
+	Found         bool         `json:"found"`                   // Whether the thread stopped at a call
+	Call          *Instruction `json:"call,omitempty"`          // The call instruction, not yet executed
+	Target        string       `json:"target,omitempty"`        // Function it calls, when known
+	TargetAddress string       `json:"targetAddress,omitempty"` // Address it calls, when known
+	Indirect      bool         `json:"indirect,omitempty"`      // The target is computed at run time
+	Message       string       `json:"message,omitempty"`       // Why the target is unknown
+}
+
+type SetRegisterResponse struct {
+	Status   string       `json:"status"`
+	Context  DebugContext `json:"context"`
+	ThreadID int          `json:"threadId"` // Thread whose register was targeted
+	Register Register     `json:"register"` // The register's current value
+}
+
+type SetNextStatementResponse struct {
+	Status   string          `json:"status"`
+	Context  DebugContext    `json:"context"`
+	From     *SourcePosition `json:"from,omitempty"`     // Where the goroutine is stopped
+	Target   *SourcePosition `json:"target,omitempty"`   // Where execution would continue
+	Warnings []string        `json:"warnings,omitempty"` // What the jump would skip or run again
+	Location *string         `json:"location,omitempty"` // Current location after the request
+}
+
+type CallFunctionResponse struct {
+	Status        string       `json:"status"`
+	Context       DebugContext `json:"context"`
+	Expression    string       `json:"expression"`              // The call expression
+	ReturnValues  []Variable   `json:"returnValues,omitempty"`  // Results of the call
+	Panicked      bool         `json:"panicked,omitempty"`      // Whether the called function panicked
+	Panic         *Variable    `json:"panic,omitempty"`         // Value the function panicked with
+	InterruptedBy *Breakpoint  `json:"interruptedBy,omitempty"` // Breakpoint hit inside the called function
+}
+
+type TraceReadResponse struct {
+	Status      string       `json:"status"`
+	Context     DebugContext `json:"context"`
+	Hits        []TraceHit   `json:"hits"`        // Hits after the requested sequence number, oldest first
+	NextSeq     int64        `json:"nextSeq"`     // Pass as since to read only newer hits
+	DroppedHits int64        `json:"droppedHits"` // Old hits discarded because the log was full
+}
+
+// VariableWrite is a past write to a variable, found by running a recording backward
+type VariableWrite struct {
+	GoroutineID int64           `json:"goroutineId"`        // Goroutine that wrote the variable
+	Location    *string         `json:"location,omitempty"` // Where the write happened
+	Position    *SourcePosition `json:"position,omitempty"` // Where the write happened, as separate fields
+	Value       *Variable       `json:"value,omitempty"`    // Value the write left
+	Error       string          `json:"error,omitempty"`    // Why the value could not be read
+}
+
+type VariableHistoryResponse struct {
+	Status     string          `json:"status"`
+	Context    DebugContext    `json:"context"`
+	Expression string          `json:"expression"`
+	Current    *Variable       `json:"current,omitempty"`   // Value at the point the history was collected from
+	Writes     []VariableWrite `json:"writes"`              // Past writes, oldest first
+	Truncated  bool            `json:"truncated,omitempty"` // Whether older writes were left out to keep to the limit
+	Restored   bool            `json:"restored"`            // Whether the replay returned to where it was
+	Notes      []string        `json:"notes,omitempty"`     // Why collecting stopped early, or the replay did not return
+	Summary    string          `json:"summary"`
+}
+
+// CaptureChange is a value captured by a breakpoint that differs from the previous hit
+type CaptureChange struct {
+	Path   string `json:"path"`          // Capture expression, or the field, element or map entry of it that changed, e.g. "s.items[2]"
+	Change string `json:"change"`        // "changed", "added" or "removed"
+	Old    string `json:"old,omitempty"` // Value at the previous hit
+	New    string `json:"new,omitempty"` // Value at this hit
+}
+
+type CaptureDiffResponse struct {
+	Status              string            `json:"status"`
+	Context             DebugContext      `json:"context"`
+	BreakpointID        int               `json:"breakpointId"`
+	Hit                 uint64            `json:"hit,omitempty"`                 // Hit count of the breakpoint at the last recorded hit
+	PreviousHit         uint64            `json:"previousHit,omitempty"`         // Hit count at the hit it is compared against
+	GoroutineID         int64             `json:"goroutineId,omitempty"`         // Goroutine of the last hit
+	PreviousGoroutineID int64             `json:"previousGoroutineId,omitempty"` // Goroutine of the previous hit
+	Timestamp           time.Time         `json:"timestamp,omitempty"`           // When the last hit was recorded
+	Location            *string           `json:"location,omitempty"`            // Where the last hit happened
+	Position            *SourcePosition   `json:"position,omitempty"`            // Where the last hit happened, as separate fields
+	Changes             []CaptureChange   `json:"changes,omitempty"`             // What changed since the previous hit
+	Unchanged           []string          `json:"unchanged,omitempty"`           // Capture expressions whose values did not change
+	ChangesTruncated    bool              `json:"changesTruncated,omitempty"`    // Whether more changed than is listed
+	Values              map[string]string `json:"values,omitempty"`              // Captured values, on the first hit only
+	HitsKept            int               `json:"hitsKept,omitempty"`            // Hits of the breakpoint whose values are kept
+	Summary             string            `json:"summary"`
+}
+
+// VariableSnapshot is the value of an expression saved under a name with snapshot_variable
+type VariableSnapshot struct {
+	Name        string          `json:"name"`
+	Expression  string          `json:"expression"`
+	Type        string          `json:"type"`
+	Value       string          `json:"value"`              // Value when the snapshot was taken
+	Frame       int             `json:"frame"`              // Stack frame it was evaluated in
+	GoroutineID int64           `json:"goroutineId"`        // Goroutine it was evaluated on
+	Location    *string         `json:"location,omitempty"` // Where the program was stopped when it was taken
+	Position    *SourcePosition `json:"position,omitempty"` // Where the program was stopped, as separate fields
+	Timestamp   time.Time       `json:"timestamp"`          // When it was taken
+}
+
+type SnapshotResponse struct {
+	Status   string           `json:"status"`
+	Context  DebugContext     `json:"context"`
+	Snapshot VariableSnapshot `json:"snapshot"`
+	Replaced bool             `json:"replaced,omitempty"` // Whether a snapshot with the same name was replaced
+}
+
+type SnapshotDiffResponse struct {
+	Status           string            `json:"status"`
+	Context          DebugContext      `json:"context"`
+	Name             string            `json:"name,omitempty"`             // Snapshot compared against
+	Expression       string            `json:"expression,omitempty"`       // Expression evaluated now
+	Type             string            `json:"type,omitempty"`             // Its type now
+	Value            string            `json:"value,omitempty"`            // Its value now
+	Snapshot         *VariableSnapshot `json:"snapshot,omitempty"`         // The snapshot, with its value then
+	Changed          bool              `json:"changed"`                    // Whether anything differs from the snapshot
+	TypeChanged      bool              `json:"typeChanged,omitempty"`      // Whether the type differs, so the values are compared as a whole
+	Changes          []CaptureChange   `json:"changes,omitempty"`          // What changed since the snapshot, old being its value
+	ChangesTruncated bool              `json:"changesTruncated,omitempty"` // Whether more changed than is listed
+	Summary          string            `json:"summary,omitempty"`
+}
+
+type SnapshotListResponse struct {
+	Status    string             `json:"status"`
+	Context   DebugContext       `json:"context"`
+	Snapshots []VariableSnapshot `json:"snapshots"`         // Snapshots kept, oldest first
+	Cleared   []string           `json:"cleared,omitempty"` // Names of the snapshots just cleared
+}
+
+// HitInterval is the time between two consecutive recorded hits of a breakpoint
+type HitInterval struct {
+	FromHit     uint64 `json:"fromHit"`     // Hit count of the breakpoint at the earlier hit
+	ToHit       uint64 `json:"toHit"`       // Hit count at the later hit
+	GoroutineID int64  `json:"goroutineId"` // Goroutine of the later hit
+	Wall        string `json:"wall"`        // Wall-clock time between the hits, e.g. "1.234ms"
+	Running     string `json:"running"`     // Time the target ran for between the hits, leaving out stops
+}
+
+// HitIntervalStats summarizes the intervals between hits of a breakpoint
+type HitIntervalStats struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
+	Avg string `json:"avg"`
+}
+
+// InterHitTimingResponse represents the response for timing the hits of a breakpoint
+type InterHitTimingResponse struct {
+	Status       string            `json:"status"`
+	Context      DebugContext      `json:"context"`
+	BreakpointID int               `json:"breakpointId"`
+	HitsKept     int               `json:"hitsKept"`            // Recorded hits the intervals are between
+	Intervals    []HitInterval     `json:"intervals,omitempty"` // Oldest first
+	Wall         *HitIntervalStats `json:"wall,omitempty"`      // Of the wall-clock intervals
+	Running      *HitIntervalStats `json:"running,omitempty"`   // Of the intervals of run time
+	Note         string            `json:"note,omitempty"`      // How far the times can be trusted
+	Summary      string            `json:"summary,omitempty"`
+}
+
+type RemoteConnectResponse struct {
+	Status     string       `json:"status"`
+	Context    DebugContext `json:"context"`
+	Address    string       `json:"address"`    // host:port of the remote Delve server
+	Pid        int          `json:"pid"`        // PID of the remote target
+	KeepTarget bool         `json:"keepTarget"` // Whether closing the session leaves the target running
+	Reconnect  Reconnect    `json:"reconnect"`  // How the connection is re-established if it drops
+}
+
+// Reconnect is the retry policy of a remote session
+type Reconnect struct {
+	Attempts  int `json:"attempts"`  // Dials tried before the session is given up, 0 to never reconnect
+	BackoffMs int `json:"backoffMs"` // Wait before the first retry, doubled after each failed one
+}
+
+// ReconnectResponse reports a remote session re-establishing its dropped connection
+type ReconnectResponse struct {
+	Status    string               `json:"status"`
+	Context   DebugContext         `json:"context"`
+	Address   string               `json:"address"`            // host:port of the remote Delve server
+	Attempts  int                  `json:"attempts"`           // Dials it took, or tried before giving up
+	Pid       int                  `json:"pid,omitempty"`      // PID of the remote target
+	NewTarget bool                 `json:"newTarget"`          // Whether the server has a different target than before the drop
+	Restored  []RestoredBreakpoint `json:"restored,omitempty"` // Breakpoints set again on a server that lost them
+	Failed    []FailedBreakpoint   `json:"failed,omitempty"`   // Breakpoints that could not be set again
+	Message   string               `json:"message"`            // What happened, for the caller
+}
+
+// CoreResponse represents the response for opening a core dump
+type CoreResponse struct {
+	Status     string       `json:"status"`
+	Context    DebugContext `json:"context"`
+	Executable string       `json:"executable"`       // Executable the core was produced by
+	CoreFile   string       `json:"coreFile"`         // Path of the core dump
+	Pid        int          `json:"pid,omitempty"`    // PID of the crashed process
+	Signal     int          `json:"signal,omitempty"` // Signal that produced the core, 0 when unknown
+	ReadOnly   bool         `json:"readOnly"`         // Core sessions cannot run, step or change the program
+}
+
+// VariableListResponse represents the response for listing a frame's locals or arguments
+type VariableListResponse struct {
+	Status    string       `json:"status"`
+	Context   DebugContext `json:"context"`
+	Frame     int          `json:"frame"`              // Frame the variables were read from
+	Variables []Variable   `json:"variables"`          // Variables in declaration order
+	MaxDepth  int          `json:"maxDepth,omitempty"` // Levels of nested values expanded, after the ceiling
+
+	StringerNote string `json:"stringerNote,omitempty"` // Why String and Error methods were not all called
+}
+
+// FrameVariables is a stack frame with the values of its arguments and local variables
+type FrameVariables struct {
+	StackFrame
+	Locals []Variable `json:"locals"` // Local variables in declaration order
+}
+
+// AllFramesLocalsResponse represents the variables of every frame of a goroutine's stack
+type AllFramesLocalsResponse struct {
+	Status         string           `json:"status"`
+	Context        DebugContext     `json:"context"`
+	GoroutineID    int64            `json:"goroutineId"`
+	Frames         []FrameVariables `json:"frames"`             // Innermost first, each with its index on the stack
+	SkippedRuntime int              `json:"skippedRuntime"`     // Runtime and standard library frames left out
+	MoreFrames     bool             `json:"moreFrames"`         // Whether the stack goes deeper than the frames read
+	MaxDepth       int              `json:"maxDepth,omitempty"` // Levels of nested values expanded, after the ceiling
+	Summary        string           `json:"summary"`
+}
+
+// RegisterArgument is a function argument, read through the debug info or recovered from
+// the CPU registers when that fails
+type RegisterArgument struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Value      string   `json:"value"`               // "<optimized out>" for a value, or a piece of one, that is gone
+	Source     string   `json:"source"`              // "debug_info", "register" or "optimized_out"
+	Confidence string   `json:"confidence"`          // "exact", "high", "partial" or "none"
+	Location   string   `json:"location,omitempty"`  // DWARF location expression at the frame's PC, as Delve prints it
+	Registers  []string `json:"registers,omitempty"` // Registers the value was recovered from
+	Note       string   `json:"note,omitempty"`      // Why the value was not read through the debug info
+}
+
+// RegisterArgsResponse represents the arguments of a frame, with register fallback
+type RegisterArgsResponse struct {
+	Status       string             `json:"status"`
+	Context      DebugContext       `json:"context"`
+	Frame        int                `json:"frame"`        // Frame the arguments were read from
+	Arguments    []RegisterArgument `json:"arguments"`    // Arguments in declaration order, without the results
+	Recovered    int                `json:"recovered"`    // Arguments recovered from registers, wholly or in part
+	OptimizedOut int                `json:"optimizedOut"` // Arguments with nothing left to read
+	Summary      string             `json:"summary"`
+}
+
+// PackageVariablesResponse represents the response for listing package-level variables
+type PackageVariablesResponse struct {
+	Status    string       `json:"status"`
+	Context   DebugContext `json:"context"`
+	Filter    string       `json:"filter,omitempty"`    // Regex the variable names were matched against
+	Variables []Variable   `json:"variables"`           // Variables sorted by fully-qualified name
+	Total     int          `json:"total"`               // Number of matching variables
+	Truncated bool         `json:"truncated,omitempty"` // More variables matched than were returned
+}
+
+// BreakOnPanicResponse represents the response for changing the break-on-panic mode
+type BreakOnPanicResponse struct {
+	Status      string       `json:"status"`
+	Context     DebugContext `json:"context"`
+	Panics      bool         `json:"panics"`      // Whether the target stops where panics start
+	Fatal       bool         `json:"fatal"`       // Whether the target stops on fatal runtime errors
+	Breakpoints []Breakpoint `json:"breakpoints"` // Breakpoints behind the mode, including Delve's own
+}
+
+// HaltResponse represents the response for halting the target
+type HaltResponse struct {
+	Status     string       `json:"status"`
+	Context    DebugContext `json:"context"`
+	WasRunning bool         `json:"wasRunning"` // False when the target was already stopped
 }
 
 type ContinueResponse struct {
@@ -99,6 +1327,88 @@ type ContinueResponse struct {
 	Context DebugContext `json:"context"`
 }
 
+// ContinueAsyncResponse represents the response for resuming the program without waiting
+type ContinueAsyncResponse struct {
+	Status  string       `json:"status"`
+	Context DebugContext `json:"context"`
+	Running bool         `json:"running"` // The program was resumed and is running
+}
+
+// WaitForStopResponse represents the response for waiting for the program to stop
+type WaitForStopResponse struct {
+	Status  string       `json:"status"`
+	Context DebugContext `json:"context"` // Where and why the program stopped, once it has
+	Stopped bool         `json:"stopped"` // The program stopped within the timeout
+	Running bool         `json:"running"` // The program is still running
+}
+
+type ContinueToLineResponse struct {
+	Status              string       `json:"status"`
+	Context             DebugContext `json:"context"`
+	Target              string       `json:"target"`                        // Requested file:line
+	ReachedTarget       bool         `json:"reachedTarget"`                 // Whether the program stopped at the target line
+	TemporaryBreakpoint *Breakpoint  `json:"temporaryBreakpoint,omitempty"` // Still set when the target was not reached yet
+	InterruptedBy       *Breakpoint  `json:"interruptedBy,omitempty"`       // Breakpoint hit before the target line
+}
+
+// ContinueToMainResponse represents the response for running a launched program to main.main
+type ContinueToMainResponse struct {
+	Status        string       `json:"status"`
+	Context       DebugContext `json:"context"`
+	Function      string       `json:"function"`                // Function run to: main.main, or runtime.main in a program without it
+	Reached       bool         `json:"reached"`                 // Whether the program stopped in that function
+	InterruptedBy *Breakpoint  `json:"interruptedBy,omitempty"` // Breakpoint hit before it, e.g. in an init function
+	Note          string       `json:"note,omitempty"`          // Where the program stopped instead, or why it is not main.main
+}
+
+// RunUntilReturnsResponse represents the response for running until a function returns matching values
+type RunUntilReturnsResponse struct {
+	Status        string       `json:"status"`
+	Context       DebugContext `json:"context"`
+	Function      string       `json:"function"`                // Function whose returns were checked
+	Condition     string       `json:"condition"`               // Condition over the return values
+	Matched       bool         `json:"matched"`                 // Whether a return matched the condition
+	Evaluations   int          `json:"evaluations"`             // Number of returns the condition was checked at
+	ReturnValues  []Variable   `json:"returnValues,omitempty"`  // Results of the matching return
+	Location      *string      `json:"location,omitempty"`      // Where the program stopped
+	InterruptedBy *Breakpoint  `json:"interruptedBy,omitempty"` // Breakpoint that stopped the program before a match
+}
+
+// RunToCompletionResponse represents the response for running the program to its exit
+type RunToCompletionResponse struct {
+	Status      string       `json:"status"`
+	Context     DebugContext `json:"context"`
+	Hits        []TraceHit   `json:"hits"`                  // Breakpoint and tracepoint hits, in the order they happened
+	TotalHits   int          `json:"totalHits"`             // Hits during the run, also those not kept
+	OmittedHits int          `json:"omittedHits,omitempty"` // Hits past maxHits, counted but not kept
+	MaxHits     int          `json:"maxHits"`               // Most hits kept
+	Exited      bool         `json:"exited"`                // Whether the process ran to its exit
+	ExitStatus  int          `json:"exitStatus"`            // Exit status, once exited
+	Location    *string      `json:"location,omitempty"`    // Where the program stopped, when it hasn't exited
+	Summary     string       `json:"summary"`
+}
+
+// LineHit is how often one line was hit during a line_coverage run
+type LineHit struct {
+	Line       int    `json:"line"`
+	Hit        bool   `json:"hit"`
+	HitCount   uint64 `json:"hitCount"`             // Hits during the run
+	Goroutines int    `json:"goroutines,omitempty"` // Goroutines the hits were on
+	Function   string `json:"function,omitempty"`   // Function the line is in
+	Error      string `json:"error,omitempty"`      // Why the line could not be traced, e.g. it has no code
+}
+
+type LineCoverageResponse struct {
+	Status     string       `json:"status"`
+	Context    DebugContext `json:"context"`
+	File       string       `json:"file"`
+	Lines      []LineHit    `json:"lines"`              // Requested lines, in line order
+	Exited     bool         `json:"exited"`             // Whether the process ran to its exit
+	ExitStatus int          `json:"exitStatus"`         // Exit status, once exited
+	Location   *string      `json:"location,omitempty"` // Where the program stopped, when it hasn't exited
+	Summary    string       `json:"summary"`
+}
+
 type CloseResponse struct {
 	Status   string       `json:"status"`
 	Context  DebugContext `json:"context"`
@@ -106,6 +1416,93 @@ type CloseResponse struct {
 	Summary  string       `json:"summary"`  // Session summary for LLM
 }
 
+// SessionInfo describes one of the debug sessions held by the server
+type SessionInfo struct {
+	ID      string    `json:"id"`               // Session ID to pass as sessionID
+	Active  bool      `json:"active"`           // Whether a program is being debugged in the session
+	Pid     int       `json:"pid,omitempty"`    // Process being debugged
+	Remote  string    `json:"remote,omitempty"` // Address of the remote Delve server, for remote sessions
+	Core    string    `json:"core,omitempty"`   // Core dump inspected, for read-only core sessions
+	Created time.Time `json:"created"`          // When the session was created
+
+	Exited     bool `json:"exited,omitempty"`     // Whether the process has exited; restart runs it again
+	ExitStatus *int `json:"exitStatus,omitempty"` // Exit status, once the process exited
+}
+
+// StatusResponse represents the state of a debug session, for orienting before using it
+type StatusResponse struct {
+	Status              string       `json:"status"`
+	Context             DebugContext `json:"context"`
+	SessionID           string       `json:"sessionId"`                     // Session described
+	Active              bool         `json:"active"`                        // Whether a program is being debugged
+	State               string       `json:"state"`                         // no active session, running, stopped, exited or connection lost
+	Message             string       `json:"message,omitempty"`             // What to do in this state
+	Target              string       `json:"target,omitempty"`              // Program being debugged
+	Pid                 int          `json:"pid,omitempty"`                 // Process being debugged
+	Remote              string       `json:"remote,omitempty"`              // Address of the remote Delve server, for remote sessions
+	Core                string       `json:"core,omitempty"`                // Core dump inspected, for read-only core sessions
+	ReadOnly            bool         `json:"readOnly,omitempty"`            // The session cannot run or change the target
+	Backend             string       `json:"backend,omitempty"`             // Delve backend, e.g. native or rr
+	ExitStatus          *int         `json:"exitStatus,omitempty"`          // Exit status, once the process exited
+	GoroutineID         int64        `json:"goroutineId,omitempty"`         // Selected goroutine, when stopped
+	Breakpoints         int          `json:"breakpoints"`                   // User breakpoints set, when stopped
+	DisabledBreakpoints int          `json:"disabledBreakpoints,omitempty"` // How many of them are disabled
+	DelveVersion        string       `json:"delveVersion"`                  // Version of Delve
+	ServerVersion       string       `json:"serverVersion,omitempty"`       // Version of this MCP server
+	TargetGoVersion     string       `json:"targetGoVersion,omitempty"`     // Go version the target was built with
+	SupportedGoVersions string       `json:"supportedGoVersions,omitempty"` // Go versions this Delve supports
+	Warnings            []string     `json:"warnings,omitempty"`            // Parts of the status that could not be read
+}
+
+type SessionResponse struct {
+	Status  string       `json:"status"`
+	Context DebugContext `json:"context"`
+	Session SessionInfo  `json:"session"` // The created or closed session
+}
+
+type SessionListResponse struct {
+	Status   string        `json:"status"`
+	Context  DebugContext  `json:"context"`
+	Sessions []SessionInfo `json:"sessions"` // Every session, the default one first
+}
+
+// What Shutdown did with the process of a session
+const (
+	ShutdownKilled   = "killed"   // The launched program was killed
+	ShutdownDetached = "detached" // The attached or kept process was left running
+	ShutdownClosed   = "closed"   // A core dump was closed
+	ShutdownIdle     = "idle"     // The session had nothing to debug
+)
+
+// ShutdownSession is how one session was ended by shutdown
+type ShutdownSession struct {
+	SessionID string `json:"sessionId"`
+	Pid       int    `json:"pid,omitempty"`   // Process debugged in the session
+	Action    string `json:"action"`          // One of the Shutdown* actions
+	Error     string `json:"error,omitempty"` // Why ending it failed; the session was dropped anyway
+}
+
+type ShutdownResponse struct {
+	Status            string            `json:"status"`
+	Context           DebugContext      `json:"context"`
+	Sessions          []ShutdownSession `json:"sessions"`          // Every session that was open, by ID
+	SessionsClosed    int               `json:"sessionsClosed"`    // Sessions ended
+	ProcessesKilled   int               `json:"processesKilled"`   // Launched programs killed
+	ProcessesDetached int               `json:"processesDetached"` // Attached processes left running
+	AlreadyShutDown   bool              `json:"alreadyShutDown,omitempty"`
+	Summary           string            `json:"summary"`
+}
+
+type DetachResponse struct {
+	Status   string       `json:"status"`
+	Context  DebugContext `json:"context"`
+	Pid      int          `json:"pid"`      // Process the session was debugging
+	Attached bool         `json:"attached"` // Whether the session had attached to an existing process
+	Killed   bool         `json:"killed"`   // Whether the target was killed
+	Running  bool         `json:"running"`  // Whether the target was left running
+	Summary  string       `json:"summary"`  // What happened in human terms
+}
+
 type DebuggerOutputResponse struct {
 	Status        string       `json:"status"`
 	Context       DebugContext `json:"context"`
@@ -114,12 +1511,24 @@ type DebuggerOutputResponse struct {
 	OutputSummary string       `json:"outputSummary"` // Brief summary of output for LLM
 }
 
+type OutputReadResponse struct {
+	Status       string       `json:"status"`
+	Context      DebugContext `json:"context"`
+	Stream       string       `json:"stream"`                 // "stdout" or "stderr"
+	Lines        []OutputLine `json:"lines"`                  // Lines captured since the requested offset
+	Output       string       `json:"output"`                 // Lines joined as text
+	NextOffset   int64        `json:"nextOffset"`             // Offset to pass on the next read
+	DroppedBytes int64        `json:"droppedBytes,omitempty"` // Requested bytes lost because the buffer was full
+	Note         string       `json:"note,omitempty"`
+}
+
 type AttachResponse struct {
-	Status  string        `json:"status"`
-	Context *DebugContext `json:"context"`
-	Pid     int           `json:"pid"`
-	Target  string        `json:"target"`
-	Process *Process      `json:"process"`
+	Status     string        `json:"status"`
+	Context    *DebugContext `json:"context"`
+	Pid        int           `json:"pid"`
+	Target     string        `json:"target"`
+	Process    *Process      `json:"process"`
+	Candidates []Process     `json:"candidates,omitempty"` // Matching processes when a name is ambiguous
 }
 
 type DebugSourceResponse struct {
@@ -142,6 +1551,28 @@ type DebugTestResponse struct {
 	TestFlags    []string      `json:"testFlags"`
 }
 
+type LaunchTestResponse struct {
+	Status       string        `json:"status"`
+	Context      *DebugContext `json:"context"`
+	Package      string        `json:"package"`                // Package as given
+	PackageDir   string        `json:"packageDir,omitempty"`   // Directory of the package, where the tests run
+	TestPattern  string        `json:"testPattern,omitempty"`  // -test.run pattern selecting the tests
+	Args         []string      `json:"args,omitempty"`         // Arguments passed to the test binary
+	Pid          int           `json:"pid,omitempty"`          // PID of the test process
+	DebugBinary  string        `json:"debugBinary,omitempty"`  // Compiled test binary
+	BuildCommand string        `json:"buildCommand,omitempty"` // Command that compiled the tests
+	BuildOutput  string        `json:"buildOutput,omitempty"`  // Compiler output
+	BuildErrors  []BuildError  `json:"buildErrors,omitempty"`  // Compile errors, when the tests don't build
+}
+
+// BuildError is a compile error reported by the Go toolchain
+type BuildError struct {
+	File    string `json:"file"`             // File as printed by the compiler
+	Line    int    `json:"line"`             // Line of the error
+	Column  int    `json:"column,omitempty"` // Column of the error, when reported
+	Message string `json:"message"`          // What is wrong
+}
+
 // Process represents a debugged process with LLM-friendly additions
 type Process struct {
 	Pid         int      `json:"pid"`         // Process ID